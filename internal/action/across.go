@@ -0,0 +1,135 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"sai/internal/debug"
+	"sai/internal/interfaces"
+)
+
+// defaultProviderBudget bounds each provider when no configuration is set
+const defaultProviderBudget = 5 * time.Second
+
+// providerRun is the outcome of an action run with one provider across providers
+type providerRun struct {
+	result   *interfaces.ExecutionResult
+	err      error
+	timedOut bool          // The provider exhausted its budget
+	budget   time.Duration // Deadline of the provider, retries included
+	duration time.Duration // Time the provider took, retries included
+}
+
+// providerResult reports the run of a provider, with its output and commands masked
+func providerResult(provider string, run providerRun) interfaces.ProviderResult {
+	result := interfaces.ProviderResult{
+		Provider: provider,
+		TimedOut: run.timedOut,
+		ExitCode: 1,
+		Duration: run.duration,
+	}
+	if run.err != nil {
+		result.Error = run.err.Error()
+		return result
+	}
+	if run.result != nil {
+		result.Success = run.result.ExitCode == 0
+		result.ExitCode = run.result.ExitCode
+		result.Commands = debug.MaskSecretsAll(run.result.Commands)
+		result.Output = debug.MaskSecrets(run.result.Output)
+	}
+	return result
+}
+
+// fastestProvider returns the successful provider that took the least time, nil when
+// none succeeded
+func fastestProvider(results []interfaces.ProviderResult) *interfaces.ProviderResult {
+	var fastest *interfaces.ProviderResult
+	for i := range results {
+		if results[i].Success && (fastest == nil || results[i].Duration < fastest.Duration) {
+			fastest = &results[i]
+		}
+	}
+	return fastest
+}
+
+// writeAcrossSummary writes the table of the providers of an action run across
+// providers, in the order they were run, followed by the totals
+func writeAcrossSummary(w io.Writer, results []interfaces.ProviderResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tSTATUS\tEXIT\tDURATION")
+	succeeded := 0
+	for _, result := range results {
+		status, exitCode := "failed", strconv.Itoa(result.ExitCode)
+		switch {
+		case result.Success:
+			status = "ok"
+			succeeded++
+		case result.TimedOut:
+			status, exitCode = "timed out", "-"
+		case result.Error != "":
+			exitCode = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Provider, status, exitCode, result.Duration.Round(time.Millisecond))
+	}
+	tw.Flush()
+
+	summary := fmt.Sprintf("%d of %d providers succeeded, %d failed", succeeded, len(results), len(results)-succeeded)
+	if fastest := fastestProvider(results); fastest != nil {
+		summary += fmt.Sprintf(", fastest: %s (%s)", fastest.Provider, fastest.Duration.Round(time.Millisecond))
+	}
+	fmt.Fprintln(w, summary)
+}
+
+// providerBudget returns the deadline of a provider and how many times an error is
+// retried within it
+func (am *ActionManager) providerBudget(provider string) (time.Duration, int) {
+	if am.config == nil {
+		return defaultProviderBudget, 0
+	}
+	return am.config.ProviderBudget(provider), am.config.AcrossProviders.Retries
+}
+
+// runWithBudget runs an action with a provider within its deadline budget, retrying
+// errors while the budget lasts. Each attempt runs in its own goroutine, so a provider
+// hanging past its deadline or panicking cannot block the others.
+func (am *ActionManager) runWithBudget(ctx context.Context, provider string, run func(ctx context.Context) (*interfaces.ExecutionResult, error)) providerRun {
+	budget, retries := am.providerBudget(provider)
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	var outcome providerRun
+	for attempt := 0; attempt <= retries; attempt++ {
+		done := make(chan providerRun, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					done <- providerRun{err: fmt.Errorf("crashed: %v", r)}
+				}
+			}()
+			result, err := run(ctx)
+			done <- providerRun{result: result, err: err}
+		}()
+
+		select {
+		case outcome = <-done:
+		case <-ctx.Done():
+			outcome = providerRun{err: ctx.Err()}
+		}
+		if outcome.err == nil {
+			return outcome
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return providerRun{err: fmt.Errorf("timed out after %s", budget), timedOut: true, budget: budget}
+		}
+		if ctx.Err() != nil {
+			return outcome
+		}
+	}
+	return outcome
+}
@@ -0,0 +1,45 @@
+package action
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"sai/internal/interfaces"
+)
+
+func TestProviderResults(t *testing.T) {
+	results := []interfaces.ProviderResult{
+		providerResult("apt", providerRun{result: &interfaces.ExecutionResult{ExitCode: 0, Output: "nginx 1.24"}, duration: 300 * time.Millisecond}),
+		providerResult("snap", providerRun{err: errors.New("timed out after 5s"), timedOut: true, duration: 5 * time.Second}),
+		providerResult("brew", providerRun{result: &interfaces.ExecutionResult{ExitCode: 0}, duration: 120 * time.Millisecond}),
+		providerResult("dnf", providerRun{result: &interfaces.ExecutionResult{ExitCode: 2}, duration: 50 * time.Millisecond}),
+	}
+
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "nginx 1.24", results[0].Output)
+	assert.True(t, results[1].TimedOut)
+	assert.Equal(t, "timed out after 5s", results[1].Error)
+	assert.False(t, results[3].Success)
+	assert.Equal(t, 2, results[3].ExitCode)
+
+	// The failed dnf was faster, but only successful providers count
+	fastest := fastestProvider(results)
+	if assert.NotNil(t, fastest) {
+		assert.Equal(t, "brew", fastest.Provider)
+	}
+	assert.Nil(t, fastestProvider(results[1:2]))
+
+	var out bytes.Buffer
+	writeAcrossSummary(&out, results)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{"PROVIDER", "STATUS", "EXIT", "DURATION"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"apt", "ok", "0", "300ms"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"snap", "timed", "out", "-", "5s"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"dnf", "failed", "2", "50ms"}, strings.Fields(lines[4]))
+	assert.Equal(t, "2 of 4 providers succeeded, 2 failed, fastest: brew (120ms)", lines[5])
+}
@@ -0,0 +1,171 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/lock"
+	"sai/internal/version"
+)
+
+// ExecuteBatch executes the same action on several software with a pool of workers.
+// Results are returned in the order of the requested software. Unless KeepGoing is
+// set, the first failure stops scheduling the remaining software, which are reported
+// as skipped; actions already running are left to complete. Without Yes the action
+// may prompt, so the software are processed one at a time. Software requested as
+// software@version are processed with that version, see ActionOptions.Version.
+func (am *ActionManager) ExecuteBatch(ctx context.Context, action string, software []string, options interfaces.ActionOptions) (*interfaces.BatchResult, error) {
+	startTime := time.Now()
+	batch := &interfaces.BatchResult{
+		Action:  action,
+		Results: make([]*interfaces.ActionResult, len(software)),
+	}
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = am.config.Batch.Workers
+	}
+	if !options.Yes || workers < 1 {
+		workers = 1
+	}
+	if workers > len(software) {
+		workers = len(software)
+	}
+
+	var (
+		mutex   sync.Mutex
+		stopped bool
+		wg      sync.WaitGroup
+	)
+	jobs := make(chan int)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				mutex.Lock()
+				stop := stopped
+				mutex.Unlock()
+				if stop {
+					continue
+				}
+
+				if options.Progress != nil {
+					options.Progress(index, nil)
+				}
+				name, spec := version.Split(software[index])
+				itemOptions := options
+				if spec != "" {
+					itemOptions.Version = spec
+				}
+				result, err := am.ExecuteAction(ctx, action, name, itemOptions)
+				if result == nil {
+					result = am.buildErrorResult(action, name, options.Provider, err, time.Now())
+				}
+				if options.Progress != nil {
+					options.Progress(index, result)
+				}
+
+				mutex.Lock()
+				batch.Results[index] = result
+				if err == nil && result.Success {
+					batch.Succeeded++
+				} else {
+					batch.Failed++
+					stopped = stopped || !options.KeepGoing
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for index := range software {
+		mutex.Lock()
+		stop := stopped
+		mutex.Unlock()
+		if stop || ctx.Err() != nil {
+			break
+		}
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, result := range batch.Results {
+		if result == nil {
+			batch.Skipped++
+		}
+	}
+	batch.Duration = time.Since(startTime)
+
+	if batch.Failed > 0 {
+		var failed []string
+		for _, result := range batch.Results {
+			if result != nil && !result.Success {
+				failed = append(failed, result.Software)
+			}
+		}
+		return batch, fmt.Errorf("%s failed for %d of %d software: %s", action, batch.Failed, len(software), strings.Join(failed, ", "))
+	}
+	if err := ctx.Err(); err != nil {
+		return batch, err
+	}
+	return batch, nil
+}
+
+// lockProvider serializes the actions executed by a provider and returns the
+// function releasing the lock. System-changing actions also take the lock file of the
+// provider, waiting for other sai processes acting through it with a spinner until the
+// configured lock timeout. Actions requiring root take it in the system lock directory
+// shared by all users, so that runs of different users and of root wait for each other.
+// Without a configured lock directory, such as when sai is embedded with a
+// configuration built in code, only the process lock is taken.
+func (am *ActionManager) lockProvider(ctx context.Context, provider, action string, requiresRoot bool) (func(), error) {
+	am.providerLocksMutex.Lock()
+	if am.providerLocks == nil {
+		am.providerLocks = make(map[string]*sync.Mutex)
+	}
+	mutex, exists := am.providerLocks[provider]
+	if !exists {
+		mutex = &sync.Mutex{}
+		am.providerLocks[provider] = mutex
+	}
+	am.providerLocksMutex.Unlock()
+
+	mutex.Lock()
+	if am.config == nil || am.config.Locks.Dir == "" || !am.config.IsSystemChangingAction(action) {
+		return mutex.Unlock, nil
+	}
+
+	dir, shared := am.config.Locks.Dir, false
+	if requiresRoot && am.config.Locks.SystemDir != "" {
+		dir, shared = am.config.Locks.SystemDir, true
+	}
+
+	stopSpinner := func() {}
+	held, err := lock.Acquire(ctx, dir, provider, lock.Options{
+		Timeout: am.config.Locks.Timeout,
+		Shared:  shared,
+		OnWait: func(holder *lock.Holder) {
+			stopSpinner = am.formatter.StartSpinner(fmt.Sprintf("Waiting for the %s lock held by %s", provider, holder))
+		},
+	})
+	stopSpinner()
+	if err != nil {
+		mutex.Unlock()
+		if errors.Is(err, lock.ErrTimeout) {
+			return nil, fmt.Errorf("%w, raise --lock-timeout to wait longer", err)
+		}
+		return nil, err
+	}
+	return func() {
+		held.Release()
+		mutex.Unlock()
+	}, nil
+}
@@ -0,0 +1,47 @@
+package action
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sai/internal/config"
+)
+
+func TestLockProvider(t *testing.T) {
+	cfg := &config.Config{}
+	manager := &ActionManager{config: cfg}
+
+	// Without a lock directory only the process lock is taken
+	unlock, err := manager.lockProvider(context.Background(), "apt", "install", false)
+	require.NoError(t, err)
+	unlock()
+
+	cfg.Locks.Dir = filepath.Join(t.TempDir(), "locks")
+	unlock, err = manager.lockProvider(context.Background(), "apt", "install", false)
+	require.NoError(t, err)
+	_, statErr := os.Stat(filepath.Join(cfg.Locks.Dir, "apt.lock"))
+	assert.NoError(t, statErr, "system-changing actions take the lock file of the provider")
+	unlock()
+
+	// Information actions only take the process lock
+	unlock, err = manager.lockProvider(context.Background(), "brew", "info", false)
+	require.NoError(t, err)
+	unlock()
+	_, statErr = os.Stat(filepath.Join(cfg.Locks.Dir, "brew.lock"))
+	assert.True(t, os.IsNotExist(statErr))
+
+	// Actions requiring root take the lock file in the system lock directory
+	cfg.Locks.SystemDir = filepath.Join(t.TempDir(), "system")
+	unlock, err = manager.lockProvider(context.Background(), "dnf", "install", true)
+	require.NoError(t, err)
+	unlock()
+	_, statErr = os.Stat(filepath.Join(cfg.Locks.SystemDir, "dnf.lock"))
+	assert.NoError(t, statErr)
+	_, statErr = os.Stat(filepath.Join(cfg.Locks.Dir, "dnf.lock"))
+	assert.True(t, os.IsNotExist(statErr))
+}
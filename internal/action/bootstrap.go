@@ -0,0 +1,196 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"sai/internal/audit"
+	"sai/internal/download"
+	"sai/internal/interfaces"
+	"sai/internal/privilege"
+	"sai/internal/types"
+)
+
+// bootstrapProviderIfNeeded installs a requested provider that is not available on the
+// system using the bootstrap definition from its provider YAML, after user confirmation
+func (am *ActionManager) bootstrapProviderIfNeeded(ctx context.Context, providerName string, options interfaces.ActionOptions) error {
+	if providerName == "" || am.providerManager.IsProviderAvailable(providerName) {
+		return nil
+	}
+
+	provider, err := am.providerManager.GetProvider(providerName)
+	if err != nil {
+		// Unknown providers are reported by provider selection
+		return nil
+	}
+
+	if provider.Bootstrap == nil || !provider.Bootstrap.IsValid() {
+		return nil
+	}
+
+	am.formatter.ShowWarning(fmt.Sprintf("Provider %s is not available on this system", providerName))
+	return am.bootstrapProvider(ctx, provider, false, options)
+}
+
+// BootstrapProvider installs a provider that is not available on the system using the
+// bootstrap definition from its provider YAML, after user confirmation. Installers
+// without a checksum are only downloaded when allowUnverified is set.
+func (am *ActionManager) BootstrapProvider(ctx context.Context, providerName string, allowUnverified bool, options interfaces.ActionOptions) error {
+	provider, err := am.providerManager.GetProvider(providerName)
+	if err != nil {
+		return err
+	}
+
+	if am.providerManager.IsProviderAvailable(providerName) {
+		am.formatter.ShowInfo(fmt.Sprintf("Provider %s is already available", providerName))
+		return nil
+	}
+
+	if provider.Bootstrap == nil || !provider.Bootstrap.IsValid() {
+		return fmt.Errorf("provider %s is not available and defines no bootstrap", providerName)
+	}
+
+	return am.bootstrapProvider(ctx, provider, allowUnverified, options)
+}
+
+// bootstrapProvider previews, confirms and runs the bootstrap of a provider, then
+// re-detects providers so the freshly installed one becomes available
+func (am *ActionManager) bootstrapProvider(ctx context.Context, provider *types.ProviderData, allowUnverified bool, options interfaces.ActionOptions) error {
+	providerName := provider.Provider.Name
+	bootstrap := provider.Bootstrap
+
+	description := bootstrap.Description
+	if description == "" {
+		description = fmt.Sprintf("Install provider %s", providerName)
+	}
+
+	downloads, err := bootstrap.DownloadsFor(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return fmt.Errorf("cannot bootstrap provider %s on this system: %w", providerName, err)
+	}
+
+	am.formatter.ShowInfo(description)
+	for _, download := range downloads {
+		verification := "checksum verified"
+		if !download.IsVerified() {
+			verification = "not verified, no checksum published"
+		}
+		am.formatter.ShowInfo(fmt.Sprintf("Download %s as %s (%s)", download.URL, download.Path, verification))
+	}
+	am.formatter.ShowCommandPreview(bootstrap.GetCommands(), providerName)
+
+	if unverified := bootstrap.UnverifiedDownloads(); len(unverified) > 0 && !allowUnverified {
+		return fmt.Errorf("bootstrap of provider %s downloads installers without a checksum (%s); run 'sai providers bootstrap %s --allow-unverified' to install it anyway",
+			providerName, strings.Join(unverified, ", "), providerName)
+	}
+
+	if options.DryRun {
+		am.formatter.ShowInfo(fmt.Sprintf("Dry run mode - provider %s would be bootstrapped", providerName))
+		return nil
+	}
+
+	if !options.Yes {
+		confirmed, err := am.ui.PromptForConfirmation(fmt.Sprintf("Install provider %s?", providerName))
+		if err != nil {
+			return fmt.Errorf("bootstrap confirmation failed: %w", err)
+		}
+		if !confirmed {
+			return fmt.Errorf("provider %s is not available and bootstrap was cancelled by user", providerName)
+		}
+	}
+
+	if err := am.runBootstrap(ctx, provider, downloads, options); err != nil {
+		return err
+	}
+
+	// Re-detect providers so the freshly installed one becomes available
+	if err := am.providerManager.ReloadProviders(); err != nil {
+		am.formatter.ShowDebug(fmt.Sprintf("Failed to reload providers after bootstrap: %v", err))
+	}
+
+	if !am.providerManager.IsProviderAvailable(providerName) {
+		return fmt.Errorf("provider %s is still not available after bootstrap; you may need to restart your shell to update PATH", providerName)
+	}
+
+	am.formatter.ShowSuccess(fmt.Sprintf("Provider %s bootstrapped successfully", providerName))
+	return nil
+}
+
+// runBootstrap downloads the installers of a provider into a private directory and
+// executes the bootstrap command or steps in it, so no other user can replace an
+// installer between its verification and its execution
+func (am *ActionManager) runBootstrap(ctx context.Context, provider *types.ProviderData, downloads []types.BootstrapDownload, options interfaces.ActionOptions) error {
+	bootstrap := provider.Bootstrap
+	ctx = audit.WithOrigin(ctx, audit.Origin{Provider: provider.Provider.Name, Action: "bootstrap"})
+
+	if bootstrap.RequiresRoot && !isPrivileged() {
+		tool, err := am.privileges.Escalate("bootstrap", provider.Provider.Name, provider.Provider.Name, am.confirmEscalation(options))
+		if err != nil {
+			return fmt.Errorf("bootstrapping provider %s requires root privileges; re-run sai with sudo", provider.Provider.Name)
+		}
+		ctx = privilege.WithTool(ctx, tool)
+	}
+
+	dir, err := os.MkdirTemp("", "sai-bootstrap-*")
+	if err != nil {
+		return fmt.Errorf("failed to create bootstrap directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, file := range downloads {
+		path := filepath.Join(dir, file.Path)
+		if err := download.FetchVerified(ctx, file.URL, path, file.Checksum, file.ChecksumURL); err != nil {
+			return fmt.Errorf("failed to bootstrap provider %s: %w", provider.Provider.Name, err)
+		}
+		if file.Mode != "" {
+			mode, err := strconv.ParseUint(file.Mode, 8, 32)
+			if err != nil {
+				return fmt.Errorf("invalid mode %q of download %s", file.Mode, file.URL)
+			}
+			if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+				return fmt.Errorf("failed to bootstrap provider %s: %w", provider.Provider.Name, err)
+			}
+		}
+	}
+
+	steps := bootstrap.Steps
+	if len(steps) == 0 {
+		steps = []types.Step{{Command: bootstrap.Command}}
+	}
+
+	for _, step := range steps {
+		if step.IsWindowsPrimitive() {
+			return fmt.Errorf("bootstrap of provider %s: steps must use command, Windows primitives are only supported in actions", provider.Provider.Name)
+		}
+
+		timeout := bootstrap.GetTimeout()
+		if step.Timeout > 0 {
+			timeout = time.Duration(step.Timeout) * time.Second
+		}
+
+		result, err := am.executor.ExecuteCommand(ctx, step.Command, interfaces.CommandOptions{
+			Timeout: timeout,
+			WorkDir: dir,
+			Verbose: options.Verbose,
+			Shell:   provider.Provider.GetShell(),
+		})
+		if err == nil && result.ExitCode != 0 {
+			err = fmt.Errorf("exit code %d", result.ExitCode)
+		}
+		if err != nil {
+			if step.IgnoreFailure {
+				am.formatter.ShowWarning(fmt.Sprintf("Bootstrap step failed (ignored): %v", err))
+				continue
+			}
+			return fmt.Errorf("failed to bootstrap provider %s: %w", provider.Provider.Name, err)
+		}
+	}
+
+	return nil
+}
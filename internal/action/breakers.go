@@ -0,0 +1,186 @@
+package action
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sai/internal/errors"
+	"sai/internal/interfaces"
+)
+
+// CircuitBreakerFile is the file of the cache directory circuit breakers persist to,
+// so the failures of a provider keep counting across sai invocations
+const CircuitBreakerFile = "circuit_breakers.json"
+
+// demotedPriority is subtracted from the priority of providers with an open circuit
+// breaker, ranking them after every other provider
+const demotedPriority = 10000
+
+// circuitBreakerName returns the name of the circuit breaker of a provider action
+func circuitBreakerName(provider, action string) string {
+	return fmt.Sprintf("%s_%s", provider, action)
+}
+
+// circuitBreakerPath returns the file circuit breakers persist to, "" when the
+// configuration has no cache directory
+func (am *ActionManager) circuitBreakerPath() string {
+	if am.config.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(am.config.CacheDir, CircuitBreakerFile)
+}
+
+// loadCircuitBreakers restores the circuit breakers persisted by earlier runs
+func (am *ActionManager) loadCircuitBreakers() {
+	path := am.circuitBreakerPath()
+	if path == "" {
+		return
+	}
+	if err := am.circuitBreakerManager.LoadState(path); err != nil {
+		am.formatter.ShowDebug(fmt.Sprintf("Failed to load circuit breakers from %s: %v", path, err))
+	}
+}
+
+// saveCircuitBreakers persists the circuit breakers for the next runs, except in
+// read-only mode, which writes nothing
+func (am *ActionManager) saveCircuitBreakers() {
+	path := am.circuitBreakerPath()
+	if path == "" || am.config.ReadOnly {
+		return
+	}
+	if err := am.circuitBreakerManager.SaveState(path); err != nil {
+		am.formatter.ShowDebug(fmt.Sprintf("Failed to save circuit breakers to %s: %v", path, err))
+	}
+}
+
+// openProvidersPolicy returns how providers with an open circuit breaker are selected
+func (am *ActionManager) openProvidersPolicy() string {
+	if am.config.CircuitBreaker == nil || am.config.CircuitBreaker.OpenProviders == "" {
+		return errors.OpenProvidersDemote
+	}
+	return am.config.CircuitBreaker.OpenProviders
+}
+
+// isCircuitOpen reports whether the circuit breaker of a provider action is open
+func (am *ActionManager) isCircuitOpen(provider, action string) bool {
+	return am.circuitBreakerManager.GetCircuitBreaker(circuitBreakerName(provider, action)).IsOpen()
+}
+
+// executeWithCircuitBreaker runs fn through the circuit breaker of a provider action.
+// When open breakers are ignored fn always runs, and succeeding closes the breaker.
+func (am *ActionManager) executeWithCircuitBreaker(provider, action string, fn func() error) error {
+	defer am.saveCircuitBreakers()
+
+	breaker := am.circuitBreakerManager.GetCircuitBreaker(circuitBreakerName(provider, action))
+	if am.openProvidersPolicy() != errors.OpenProvidersIgnore {
+		return breaker.Execute(fn)
+	}
+
+	if err := fn(); err != nil {
+		breaker.RecordFailure()
+		return err
+	}
+	breaker.Reset()
+	return nil
+}
+
+// GetOpenCircuitBreakers returns the actions of a provider whose circuit breaker is open
+func (am *ActionManager) GetOpenCircuitBreakers(provider string) []string {
+	providerData, err := am.providerManager.GetProvider(provider)
+	if err != nil {
+		return nil
+	}
+
+	var actions []string
+	for action := range providerData.Actions {
+		if am.isCircuitOpen(provider, action) {
+			actions = append(actions, action)
+		}
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// CircuitBreakerStatuses returns the circuit breakers with recent failures or not
+// closed, of one provider or of all when provider is empty, sorted by provider and
+// action
+func (am *ActionManager) CircuitBreakerStatuses(provider string) []interfaces.CircuitBreakerStatus {
+	statuses := []interfaces.CircuitBreakerStatus{}
+	for name, stats := range am.circuitBreakerManager.GetAllStats() {
+		breakerProvider, action := am.splitCircuitBreakerName(name)
+		if provider != "" && breakerProvider != provider {
+			continue
+		}
+		if stats.State == errors.CircuitBreakerClosed && stats.RecentFailures == 0 {
+			continue
+		}
+
+		status := interfaces.CircuitBreakerStatus{
+			Provider:       breakerProvider,
+			Action:         action,
+			State:          stats.State.String(),
+			RecentFailures: stats.RecentFailures,
+		}
+		if !stats.LastFailTime.IsZero() {
+			lastFailure := stats.LastFailTime
+			status.LastFailure = &lastFailure
+		}
+		if stats.State == errors.CircuitBreakerOpen {
+			openUntil := stats.LastFailTime.Add(stats.Config.RecoveryTimeout)
+			if time.Now().Before(openUntil) {
+				status.OpenUntil = &openUntil
+			} else {
+				// The next run tries the provider again
+				status.State = errors.CircuitBreakerHalfOpen.String()
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Provider != statuses[j].Provider {
+			return statuses[i].Provider < statuses[j].Provider
+		}
+		return statuses[i].Action < statuses[j].Action
+	})
+	return statuses
+}
+
+// ResetProviderCircuitBreakers closes the circuit breakers of a provider, of one of its
+// actions when action is set, and persists them. It returns the actions reset.
+func (am *ActionManager) ResetProviderCircuitBreakers(provider, action string) []string {
+	var reset []string
+	for name := range am.circuitBreakerManager.GetAllStats() {
+		breakerProvider, breakerAction := am.splitCircuitBreakerName(name)
+		if breakerProvider != provider || (action != "" && breakerAction != action) {
+			continue
+		}
+		if err := am.circuitBreakerManager.ResetCircuitBreaker(name); err == nil {
+			reset = append(reset, breakerAction)
+		}
+	}
+	sort.Strings(reset)
+	am.saveCircuitBreakers()
+	return reset
+}
+
+// splitCircuitBreakerName returns the provider and action of a circuit breaker. Both
+// can contain underscores, so the longest known provider prefixing the name wins.
+func (am *ActionManager) splitCircuitBreakerName(name string) (string, string) {
+	provider := ""
+	for _, providerData := range am.providerManager.GetAllProviders() {
+		candidate := providerData.Provider.Name
+		if strings.HasPrefix(name, candidate+"_") && len(candidate) > len(provider) {
+			provider = candidate
+		}
+	}
+	if provider == "" {
+		// A provider no longer loaded
+		provider, action, _ := strings.Cut(name, "_")
+		return provider, action
+	}
+	return provider, strings.TrimPrefix(name, provider+"_")
+}
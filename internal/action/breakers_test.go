@@ -0,0 +1,25 @@
+package action
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sai/internal/config"
+	"sai/internal/errors"
+)
+
+func TestSaveCircuitBreakers(t *testing.T) {
+	cfg := &config.Config{CacheDir: t.TempDir(), ReadOnly: true}
+	manager := &ActionManager{config: cfg, circuitBreakerManager: errors.NewCircuitBreakerManager(nil)}
+	manager.circuitBreakerManager.GetCircuitBreaker(circuitBreakerName("apt", "install"))
+
+	// Read-only mode writes nothing
+	manager.saveCircuitBreakers()
+	assert.NoFileExists(t, filepath.Join(cfg.CacheDir, CircuitBreakerFile))
+
+	cfg.ReadOnly = false
+	manager.saveCircuitBreakers()
+	assert.FileExists(t, filepath.Join(cfg.CacheDir, CircuitBreakerFile))
+}
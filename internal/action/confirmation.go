@@ -28,6 +28,12 @@ func NewConfirmationManager(cfg *config.Config, userInterface *ui.UserInterface,
 
 // RequiresConfirmation determines if an action requires user confirmation (Requirements 9.1, 9.2)
 func (cm *ConfirmationManager) RequiresConfirmation(action string, options interfaces.ActionOptions) bool {
+	// Destructive actions on production hosts are always confirmed by typing the action
+	// phrase, even with --yes or their confirmation disabled
+	if !options.DryRun && cm.config.RequiresTypedConfirmation(action) {
+		return true
+	}
+
 	// Skip confirmation if --yes flag is provided (Requirement 9.3)
 	if options.Yes {
 		return false
@@ -109,19 +115,9 @@ func (cm *ConfirmationManager) ConfirmProviderSelection(software string, options
 	return nil, fmt.Errorf("selected provider not found")
 }
 
-// ConfirmDestructiveAction provides extra confirmation for destructive operations
+// ConfirmDestructiveAction provides extra confirmation for actions in the destructive risk tier
 func (cm *ConfirmationManager) ConfirmDestructiveAction(action, software string, safetyResult *SafetyResult) (bool, error) {
-	destructiveActions := []string{"uninstall", "stop", "disable"}
-	
-	isDestructive := false
-	for _, destructive := range destructiveActions {
-		if action == destructive {
-			isDestructive = true
-			break
-		}
-	}
-
-	if !isDestructive {
+	if !cm.IsDestructiveAction(action) {
 		return true, nil
 	}
 
@@ -136,11 +132,28 @@ func (cm *ConfirmationManager) ConfirmDestructiveAction(action, software string,
 		}
 	}
 
-	// Require explicit confirmation
 	message := fmt.Sprintf("Are you sure you want to %s %s? This action cannot be easily undone", action, software)
+
+	// Production hosts require the full action phrase to be typed
+	if cm.config.RequiresTypedConfirmation(action) {
+		cm.formatter.ShowWarning(fmt.Sprintf("This host is marked as %s", cm.config.Environment))
+		return cm.ui.PromptForTypedConfirmation(message, cm.TypedConfirmationPhrase(action, software))
+	}
+
+	// Require explicit confirmation
 	return cm.ui.PromptForConfirmation(message)
 }
 
+// IsDestructiveAction determines if an action belongs to the destructive risk tier
+func (cm *ConfirmationManager) IsDestructiveAction(action string) bool {
+	return cm.config.IsDestructiveAction(action)
+}
+
+// TypedConfirmationPhrase returns the phrase a user must type to confirm a high-risk action
+func (cm *ConfirmationManager) TypedConfirmationPhrase(action, software string) string {
+	return fmt.Sprintf("yes, %s %s", action, software)
+}
+
 // showSafetyWarnings displays safety check results to the user
 func (cm *ConfirmationManager) showSafetyWarnings(safetyResult *SafetyResult) {
 	if !safetyResult.Safe {
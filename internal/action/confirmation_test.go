@@ -0,0 +1,29 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sai/internal/config"
+	"sai/internal/interfaces"
+)
+
+func TestRequiresConfirmation(t *testing.T) {
+	cfg := &config.Config{
+		Confirmations: config.ConfirmationConfig{Install: true},
+		RiskTiers:     map[string]string{"upgrade": config.RiskTierDestructive},
+	}
+	manager := NewConfirmationManager(cfg, nil, nil)
+
+	assert.True(t, manager.RequiresConfirmation("install", interfaces.ActionOptions{}))
+	assert.False(t, manager.RequiresConfirmation("install", interfaces.ActionOptions{Yes: true}))
+	assert.False(t, manager.RequiresConfirmation("upgrade", interfaces.ActionOptions{Yes: true}))
+
+	// --yes and disabled confirmations do not skip the typed confirmation on production hosts
+	cfg.Environment = config.EnvironmentProduction
+	assert.True(t, manager.RequiresConfirmation("upgrade", interfaces.ActionOptions{Yes: true}))
+	assert.True(t, manager.RequiresConfirmation("upgrade", interfaces.ActionOptions{}))
+	assert.False(t, manager.RequiresConfirmation("upgrade", interfaces.ActionOptions{Yes: true, DryRun: true}))
+	assert.False(t, manager.RequiresConfirmation("install", interfaces.ActionOptions{Yes: true}))
+}
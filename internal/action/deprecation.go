@@ -0,0 +1,43 @@
+package action
+
+import (
+	"fmt"
+
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// checkDeprecation warns when software being installed is deprecated, and offers to
+// install its replacement instead when the user can answer. It returns the software
+// to install, which is the requested one unless the user accepted the replacement.
+func (am *ActionManager) checkDeprecation(action, software string, saidata *types.SoftwareData, options interfaces.ActionOptions) string {
+	if action != "install" || saidata == nil || saidata.Metadata.Deprecation == nil {
+		return software
+	}
+	deprecation := saidata.Metadata.Deprecation
+	am.formatter.ShowWarning(deprecation.Notice(software))
+
+	replacement := deprecation.ReplacedBy
+	if replacement == "" || replacement == software {
+		return software
+	}
+	if am.ui == nil || options.Yes || options.JSON || options.DryRun || !am.ui.IsInteractive() {
+		am.formatter.ShowInfo(fmt.Sprintf("Installing %s as requested, run 'sai install %s' to install its replacement", software, replacement))
+		return software
+	}
+
+	confirmed, err := am.ui.PromptForConfirmation(fmt.Sprintf("Install %s instead of %s?", replacement, software))
+	if err != nil || !confirmed {
+		return software
+	}
+	return replacement
+}
+
+// deprecationOf returns the deprecation notice and replacement of software, empty
+// when it is not deprecated
+func deprecationOf(software string, saidata *types.SoftwareData) (string, string) {
+	if saidata == nil || saidata.Metadata.Deprecation == nil {
+		return "", ""
+	}
+	return saidata.Metadata.Deprecation.Notice(software), saidata.Metadata.Deprecation.ReplacedBy
+}
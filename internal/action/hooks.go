@@ -0,0 +1,88 @@
+package action
+
+import (
+	"context"
+	"fmt"
+
+	"sai/internal/debug"
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// Phases of the hooks run around an action
+const (
+	hookPre  = "pre"
+	hookPost = "post"
+)
+
+// actionHooks returns the hooks of an action phase, those of saidata followed by those
+// of the configuration
+func (am *ActionManager) actionHooks(phase, action string, saidata *types.SoftwareData) []types.Hook {
+	key := types.HookKey(phase, action)
+	var hooks []types.Hook
+	if saidata != nil {
+		hooks = append(hooks, saidata.Hooks[key]...)
+	}
+	return append(hooks, am.config.Hooks[key]...)
+}
+
+// hookFailurePolicy returns whether a failing hook aborts the action or is reported,
+// pre hooks aborting and post hooks warning unless they declare otherwise
+func hookFailurePolicy(phase string, hook types.Hook) string {
+	if hook.OnFailure != "" {
+		return hook.OnFailure
+	}
+	if phase == hookPre {
+		return types.HookAbort
+	}
+	return types.HookWarn
+}
+
+// runHooks renders and runs the hooks of an action phase in order, and returns their
+// commands. The hooks get the action, software and provider as SAI_ACTION, SAI_SOFTWARE
+// and SAI_PROVIDER environment variables. A hook failing with the abort policy stops
+// the hooks and its error is returned, other failures are shown as warnings.
+func (am *ActionManager) runHooks(ctx context.Context, phase, action, software string, saidata *types.SoftwareData, provider *types.ProviderData, options interfaces.ExecuteOptions) ([]string, error) {
+	hooks := am.actionHooks(phase, action, saidata)
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+
+	key := types.HookKey(phase, action)
+	env := map[string]string{
+		"SAI_HOOK":     key,
+		"SAI_ACTION":   action,
+		"SAI_SOFTWARE": software,
+		"SAI_PROVIDER": provider.Provider.Name,
+	}
+	for name, value := range options.Env {
+		env[name] = value
+	}
+	options.Env = env
+	options.Output = nil
+
+	var commands []string
+	for i, hook := range hooks {
+		name := hook.Name
+		if name == "" {
+			name = fmt.Sprintf("%s #%d", key, i+1)
+		}
+		options.Timeout = hook.GetTimeout()
+
+		am.formatter.ShowDebug(fmt.Sprintf("Running hook %s", name))
+		result, err := am.executor.ExecuteScript(ctx, hook.Command, software, saidata, provider, options)
+		if result != nil {
+			commands = append(commands, debug.MaskSecretsAll(result.Commands)...)
+		}
+		if err == nil {
+			continue
+		}
+
+		err = fmt.Errorf("hook %s failed: %w", name, err)
+		if hookFailurePolicy(phase, hook) == types.HookAbort {
+			return commands, err
+		}
+		am.formatter.ShowWarning(err.Error())
+	}
+	return commands, nil
+}
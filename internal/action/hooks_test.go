@@ -0,0 +1,63 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sai/internal/config"
+	"sai/internal/interfaces"
+	"sai/internal/output"
+	"sai/internal/types"
+)
+
+// hookExecutor records the hook scripts run, failing the ones calling false
+type hookExecutor struct {
+	interfaces.GenericExecutor
+	scripts []string
+	env     map[string]string
+}
+
+func (e *hookExecutor) ExecuteScript(ctx context.Context, script string, software string, saidata *types.SoftwareData, provider *types.ProviderData, options interfaces.ExecuteOptions) (*interfaces.ExecutionResult, error) {
+	e.scripts = append(e.scripts, script)
+	e.env = options.Env
+	result := &interfaces.ExecutionResult{Success: true, Commands: []string{script}}
+	if strings.Contains(script, "false") {
+		result.Success = false
+		return result, errors.New("exit status 1")
+	}
+	return result, nil
+}
+
+func TestRunHooks(t *testing.T) {
+	cfg := &config.Config{Hooks: map[string][]types.Hook{
+		"post_install": {{Name: "notify", Command: "notify-send installed"}},
+	}}
+	executor := &hookExecutor{}
+	am := &ActionManager{config: cfg, executor: executor, formatter: output.NewOutputFormatter(cfg, false, true, false)}
+	provider := &types.ProviderData{Provider: types.ProviderInfo{Name: "apt"}}
+	saidata := &types.SoftwareData{Hooks: map[string][]types.Hook{
+		"pre_uninstall": {{Command: "tar czf /var/backups/nginx.tgz /etc/nginx"}, {Command: "false"}, {Command: "echo skipped"}},
+		"post_install":  {{Command: "false", OnFailure: types.HookWarn}, {Command: "nginx -t"}},
+	}}
+
+	commands, err := am.runHooks(context.Background(), hookPre, "uninstall", "nginx", saidata, provider, interfaces.ExecuteOptions{})
+	require.Error(t, err, "pre hooks abort the action by default")
+	assert.Contains(t, err.Error(), "hook pre_uninstall #2 failed")
+	assert.Equal(t, []string{"tar czf /var/backups/nginx.tgz /etc/nginx", "false"}, commands)
+	assert.Equal(t, "nginx", executor.env["SAI_SOFTWARE"])
+	assert.Equal(t, "apt", executor.env["SAI_PROVIDER"])
+
+	executor.scripts = nil
+	commands, err = am.runHooks(context.Background(), hookPost, "install", "nginx", saidata, provider, interfaces.ExecuteOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"false", "nginx -t", "notify-send installed"}, commands, "saidata hooks run before the configuration ones")
+
+	commands, err = am.runHooks(context.Background(), hookPre, "install", "nginx", saidata, provider, interfaces.ExecuteOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, commands)
+}
@@ -0,0 +1,155 @@
+package action
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sai/internal/debug"
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// inputPrompter asks the user for input values
+type inputPrompter interface {
+	PromptForInput(message string) (string, error)
+	PromptForSecret(message string) (string, error)
+}
+
+// collectInputs resolves the inputs declared by the provider action and by saidata
+// and returns the template variables with the input values added. Values passed with
+// --var win; otherwise the user is prompted when interactive, and defaults are used
+// with --yes, --json or without a terminal. Secret values are masked in output and logs.
+func (am *ActionManager) collectInputs(action string, provider *types.ProviderData, saidata *types.SoftwareData, options interfaces.ActionOptions) (map[string]string, error) {
+	inputs := actionInputs(action, provider, saidata)
+	if len(inputs) == 0 {
+		return options.Variables, nil
+	}
+
+	var prompter inputPrompter
+	if am.ui != nil && !options.Yes && !options.JSON && !options.DryRun && am.ui.IsInteractive() {
+		prompter = am.ui
+	}
+
+	return resolveInputs(inputs, options.Variables, prompter, options.DryRun)
+}
+
+// actionInputs returns the inputs of an action, saidata inputs replacing provider
+// inputs with the same name
+func actionInputs(action string, provider *types.ProviderData, saidata *types.SoftwareData) []types.Input {
+	var inputs []types.Input
+	indexes := make(map[string]int)
+	add := func(input types.Input) {
+		if index, exists := indexes[input.Name]; exists {
+			inputs[index] = input
+			return
+		}
+		indexes[input.Name] = len(inputs)
+		inputs = append(inputs, input)
+	}
+
+	if provider != nil {
+		if providerAction, exists := provider.Actions[action]; exists {
+			for _, input := range providerAction.Inputs {
+				add(input)
+			}
+		}
+	}
+	if saidata != nil {
+		for _, input := range saidata.Inputs {
+			if input.AppliesTo(action) {
+				add(input)
+			}
+		}
+	}
+	return inputs
+}
+
+// resolveInputs returns a copy of variables with a value for every input. Without a
+// prompter, inputs lacking a variable and a default are an error, or a <name>
+// placeholder when placeholders is set for previews.
+func resolveInputs(inputs []types.Input, variables map[string]string, prompter inputPrompter, placeholders bool) (map[string]string, error) {
+	values := make(map[string]string, len(variables)+len(inputs))
+	for key, value := range variables {
+		values[key] = value
+	}
+
+	for _, input := range inputs {
+		value, found := variables[input.Name]
+
+		if !found && prompter != nil {
+			answer, err := promptInput(prompter, input)
+			if err != nil {
+				return nil, fmt.Errorf("input %s: %w", input.Name, err)
+			}
+			value, found = answer, answer != ""
+		}
+		if !found && input.Default != "" {
+			value, found = input.Default, true
+		}
+		if !found {
+			if !placeholders {
+				return nil, fmt.Errorf("input %s is required, pass it with --var %s=<value>", input.Name, input.Name)
+			}
+			values[input.Name] = "<" + input.Name + ">"
+			continue
+		}
+
+		normalized, err := normalizeInputValue(input, value)
+		if err != nil {
+			return nil, err
+		}
+		if input.Secret {
+			debug.RegisterSecret(normalized)
+		}
+		values[input.Name] = normalized
+	}
+
+	return values, nil
+}
+
+// promptInput asks the user for the value of an input, returning "" when the user
+// entered nothing
+func promptInput(prompter inputPrompter, input types.Input) (string, error) {
+	message := input.Prompt
+	if message == "" {
+		message = input.Name
+	}
+	if input.GetType() == types.InputTypeBoolean {
+		message += " (y/n)"
+	}
+	if input.Default != "" && !input.Secret {
+		message += fmt.Sprintf(" [%s]", input.Default)
+	}
+	message += ": "
+
+	if input.Secret {
+		return prompter.PromptForSecret(message)
+	}
+	answer, err := prompter.PromptForInput(message)
+	return strings.TrimSpace(answer), err
+}
+
+// normalizeInputValue checks a value against the input type, normalizing booleans to
+// true or false
+func normalizeInputValue(input types.Input, value string) (string, error) {
+	switch input.GetType() {
+	case types.InputTypeString:
+		return value, nil
+	case types.InputTypeBoolean:
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "true", "yes", "y", "1":
+			return "true", nil
+		case "false", "no", "n", "0":
+			return "false", nil
+		}
+		return "", fmt.Errorf("input %s must be a boolean (true/false, yes/no), got %q", input.Name, value)
+	case types.InputTypeNumber:
+		value = strings.TrimSpace(value)
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("input %s must be a number, got %q", input.Name, value)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("input %s has unknown type %q", input.Name, input.Type)
+}
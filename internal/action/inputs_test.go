@@ -0,0 +1,96 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/debug"
+	"sai/internal/types"
+)
+
+// stubPrompter answers prompts from a map keyed by prompt message
+type stubPrompter struct {
+	answers map[string]string
+	secrets []string
+}
+
+func (sp *stubPrompter) PromptForInput(message string) (string, error) {
+	return sp.answers[message], nil
+}
+
+func (sp *stubPrompter) PromptForSecret(message string) (string, error) {
+	sp.secrets = append(sp.secrets, message)
+	return sp.answers[message], nil
+}
+
+func TestActionInputs(t *testing.T) {
+	provider := &types.ProviderData{
+		Actions: map[string]types.Action{
+			"install": {
+				Command: "install",
+				Inputs: []types.Input{
+					{Name: "accept_license", Type: types.InputTypeBoolean},
+					{Name: "admin_password", Secret: true},
+				},
+			},
+		},
+	}
+	saidata := &types.SoftwareData{
+		Inputs: []types.Input{
+			{Name: "admin_password", Secret: true, Prompt: "Admin password"},
+			{Name: "port", Type: types.InputTypeNumber, Default: "8080", Actions: []string{"install"}},
+			{Name: "purge", Type: types.InputTypeBoolean, Actions: []string{"uninstall"}},
+		},
+	}
+
+	inputs := actionInputs("install", provider, saidata)
+
+	require.Len(t, inputs, 3)
+	assert.Equal(t, "accept_license", inputs[0].Name)
+	assert.Equal(t, "Admin password", inputs[1].Prompt, "saidata input replaces the provider input")
+	assert.Equal(t, "port", inputs[2].Name)
+}
+
+func TestResolveInputs(t *testing.T) {
+	inputs := []types.Input{
+		{Name: "accept_license", Type: types.InputTypeBoolean, Prompt: "Accept the license"},
+		{Name: "admin_password", Secret: true, Prompt: "Admin password"},
+		{Name: "port", Type: types.InputTypeNumber, Default: "8080"},
+	}
+
+	t.Run("variables win over prompts", func(t *testing.T) {
+		prompter := &stubPrompter{answers: map[string]string{"Admin password: ": "s3cr3t-input"}}
+		values, err := resolveInputs(inputs, map[string]string{"accept_license": "yes", "other": "kept"}, prompter, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, "true", values["accept_license"])
+		assert.Equal(t, "s3cr3t-input", values["admin_password"])
+		assert.Equal(t, "8080", values["port"])
+		assert.Equal(t, "kept", values["other"])
+		assert.Equal(t, []string{"Admin password: "}, prompter.secrets)
+
+		// Secret values are masked in output
+		assert.Equal(t, "--password ***REDACTED***", debug.MaskSecrets("--password s3cr3t-input"))
+	})
+
+	t.Run("missing value without prompter", func(t *testing.T) {
+		_, err := resolveInputs(inputs, map[string]string{"accept_license": "true"}, nil, false)
+		assert.EqualError(t, err, "input admin_password is required, pass it with --var admin_password=<value>")
+	})
+
+	t.Run("placeholders for previews", func(t *testing.T) {
+		values, err := resolveInputs(inputs, nil, nil, true)
+		require.NoError(t, err)
+		assert.Equal(t, "<admin_password>", values["admin_password"])
+		assert.Equal(t, "8080", values["port"])
+	})
+
+	t.Run("invalid typed values", func(t *testing.T) {
+		_, err := resolveInputs(inputs, map[string]string{"accept_license": "maybe"}, nil, true)
+		assert.ErrorContains(t, err, "input accept_license must be a boolean")
+
+		_, err = resolveInputs(inputs, map[string]string{"port": "eighty"}, nil, true)
+		assert.ErrorContains(t, err, "input port must be a number")
+	})
+}
@@ -0,0 +1,136 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/parsers"
+	"sai/internal/types"
+)
+
+// listInstalledAction is the provider action listing every installed package
+const listInstalledAction = "list_installed"
+
+// listInstalledTimeout bounds the list_installed action of each provider
+const listInstalledTimeout = 60 * time.Second
+
+// ListInstalled lists the packages installed by the available providers with a
+// list_installed action, or by a single provider when provider is not empty. The
+// output of each provider is normalized with the parser of its action, and the
+// software is deduplicated by name across providers.
+func (am *ActionManager) ListInstalled(ctx context.Context, provider string) (*interfaces.InstalledList, error) {
+	var providers []*types.ProviderData
+	if provider != "" {
+		providerData, err := am.providerManager.GetProvider(provider)
+		if err != nil {
+			return nil, err
+		}
+		if !am.providerManager.IsProviderAvailable(provider) {
+			return nil, fmt.Errorf("provider %s is not available on this system", provider)
+		}
+		if _, exists := providerData.Actions[listInstalledAction]; !exists {
+			return nil, fmt.Errorf("provider %s does not support listing installed packages", provider)
+		}
+		providers = []*types.ProviderData{providerData}
+	} else {
+		for _, providerData := range am.providerManager.GetAvailableProviders() {
+			if _, exists := providerData.Actions[listInstalledAction]; exists {
+				providers = append(providers, providerData)
+			}
+		}
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Provider.Name < providers[j].Provider.Name
+	})
+
+	list := &interfaces.InstalledList{}
+	for _, providerData := range providers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		list.Providers = append(list.Providers, am.listProviderPackages(ctx, providerData))
+	}
+
+	saidataSoftware := make(map[string]bool)
+	if names, err := am.saidataManager.GetSoftwareList(); err == nil {
+		for _, name := range names {
+			saidataSoftware[strings.ToLower(name)] = true
+		}
+	}
+	list.Software = mergeInstalledPackages(list.Providers, saidataSoftware)
+
+	return list, nil
+}
+
+// listProviderPackages runs the list_installed action of a provider and parses its output
+func (am *ActionManager) listProviderPackages(ctx context.Context, provider *types.ProviderData) *interfaces.ProviderPackages {
+	result := &interfaces.ProviderPackages{Provider: provider.Provider.Name}
+
+	parser, err := parsers.Get(provider.Actions[listInstalledAction].Parser)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	executeOptions := interfaces.ExecuteOptions{
+		DryRun:  false,
+		Verbose: false,
+		Timeout: listInstalledTimeout,
+	}
+	executionResult, err := am.executor.Execute(ctx, provider, listInstalledAction, "", &types.SoftwareData{}, executeOptions)
+	if err == nil && !executionResult.Success {
+		err = executionResult.Error
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	packages, err := parser(executionResult.Output)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Packages = make([]interfaces.InstalledPackage, 0, len(packages))
+	for _, pkg := range packages {
+		result.Packages = append(result.Packages, interfaces.InstalledPackage{Name: pkg.Name, Version: pkg.Version})
+	}
+	return result
+}
+
+// mergeInstalledPackages deduplicates the packages of several providers by name,
+// marking the software that has a saidata definition
+func mergeInstalledPackages(providers []*interfaces.ProviderPackages, saidataSoftware map[string]bool) []*interfaces.InstalledSoftware {
+	merged := make(map[string]*interfaces.InstalledSoftware)
+	for _, providerPackages := range providers {
+		for _, pkg := range providerPackages.Packages {
+			key := strings.ToLower(pkg.Name)
+			software, exists := merged[key]
+			if !exists {
+				software = &interfaces.InstalledSoftware{
+					Name:       pkg.Name,
+					Versions:   make(map[string]string),
+					HasSaidata: saidataSoftware[key],
+				}
+				merged[key] = software
+			}
+			if _, listed := software.Versions[providerPackages.Provider]; !listed {
+				software.Providers = append(software.Providers, providerPackages.Provider)
+				software.Versions[providerPackages.Provider] = pkg.Version
+			}
+		}
+	}
+
+	software := make([]*interfaces.InstalledSoftware, 0, len(merged))
+	for _, entry := range merged {
+		software = append(software, entry)
+	}
+	sort.Slice(software, func(i, j int) bool {
+		return strings.ToLower(software[i].Name) < strings.ToLower(software[j].Name)
+	})
+	return software
+}
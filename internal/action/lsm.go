@@ -0,0 +1,113 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sai/internal/executor"
+	"sai/internal/interfaces"
+	"sai/internal/lsm"
+	"sai/internal/types"
+)
+
+// relabelTimeout bounds the command restoring SELinux contexts, which walks the
+// directories declared in saidata
+const relabelTimeout = 5 * time.Minute
+
+// restoreSecurityContexts resets the SELinux contexts of the files, directories and
+// commands declared in saidata after an install or upgrade, so services can access
+// files that were not labeled by the package manager
+func (am *ActionManager) restoreSecurityContexts(ctx context.Context, action string, saidata *types.SoftwareData) {
+	if (action != "install" && action != "upgrade") || saidata == nil {
+		return
+	}
+
+	status := lsm.Detect()
+	if status.Module != lsm.SELinux {
+		return
+	}
+
+	restored, err := lsm.RestoreContexts(ctx, status, am.config.Root, securityPaths(saidata), am.runSecurityCommand)
+	if err != nil {
+		am.formatter.ShowWarning(fmt.Sprintf("Failed to restore SELinux contexts: %v", err))
+		return
+	}
+	if len(restored) > 0 {
+		am.formatter.ShowDebug(fmt.Sprintf("Restored SELinux contexts of %s", strings.Join(restored, ", ")))
+	}
+}
+
+// runSecurityCommand runs a command relabeling or checking files through the executor,
+// so it is audited, sandboxed and refused in read-only mode like the commands of the
+// action
+func (am *ActionManager) runSecurityCommand(ctx context.Context, args ...string) (string, error) {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = executor.QuoteArgument(arg, "")
+	}
+	command := strings.Join(quoted, " ")
+
+	result, err := am.executor.ExecuteCommand(ctx, command, interfaces.CommandOptions{Timeout: relabelTimeout})
+	if result == nil {
+		return "", err
+	}
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("exit code %d", result.ExitCode)
+	}
+	return result.Output, err
+}
+
+// showSecurityDenials reports SELinux/AppArmor denials logged since the action started
+// that concern the software, as they are a common cause of failing services
+func (am *ActionManager) showSecurityDenials(software string, saidata *types.SoftwareData, since time.Time) {
+	status := lsm.Detect()
+	if !status.Active() {
+		return
+	}
+
+	terms := append([]string{software}, securityPaths(saidata)...)
+	if saidata != nil {
+		for _, service := range saidata.Services {
+			terms = append(terms, service.GetServiceNameOrDefault())
+		}
+		for _, command := range saidata.Commands {
+			terms = append(terms, command.Name)
+		}
+	}
+
+	denials := lsm.FindDenials(since, terms)
+	if len(denials) == 0 {
+		return
+	}
+
+	am.formatter.ShowWarning(fmt.Sprintf("%s (%s) denied access during the action:", status.Module, status.Mode))
+	for _, denial := range denials {
+		am.formatter.ShowWarning("  " + denial.String())
+	}
+	if status.Module == lsm.SELinux {
+		am.formatter.ShowInfo("Inspect the denials with 'ausearch -m avc -ts recent' and fix labels with 'restorecon -Rv <path>'")
+	} else {
+		am.formatter.ShowInfo("Inspect the denials with 'aa-status' and adjust the profile or run 'aa-complain <profile>'")
+	}
+}
+
+// securityPaths returns the paths of files, directories and commands declared in saidata
+func securityPaths(saidata *types.SoftwareData) []string {
+	if saidata == nil {
+		return nil
+	}
+
+	var paths []string
+	for _, file := range saidata.Files {
+		paths = append(paths, file.Path)
+	}
+	for _, directory := range saidata.Directories {
+		paths = append(paths, directory.Path)
+	}
+	for _, command := range saidata.Commands {
+		paths = append(paths, command.GetPathOrDefault())
+	}
+	return paths
+}
@@ -3,15 +3,24 @@ package action
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"sai/internal/config"
+	"sai/internal/debug"
 	"sai/internal/errors"
 	"sai/internal/interfaces"
+	"sai/internal/managed"
 	"sai/internal/output"
+	"sai/internal/parsers"
+	"sai/internal/privilege"
+	"sai/internal/servicemgr"
+	"sai/internal/transaction"
 	"sai/internal/types"
 	"sai/internal/ui"
 )
@@ -30,6 +39,13 @@ type ActionManager struct {
 	recoveryManager       *errors.RecoveryManager
 	circuitBreakerManager *errors.CircuitBreakerManager
 	errorTracker          *errors.ErrorContextTracker
+	markerStore           *managed.MarkerStore
+	journal               *transaction.Journal
+	privileges            *privilege.Manager
+
+	// Package managers lock their database, actions of a provider run one at a time
+	providerLocks      map[string]*sync.Mutex
+	providerLocksMutex sync.Mutex
 }
 
 // NewActionManager creates a new action manager
@@ -61,7 +77,7 @@ func NewActionManager(
 	circuitBreakerManager := errors.NewCircuitBreakerManager(circuitBreakerConfig)
 	errorTracker := errors.NewErrorContextTracker(1000) // Keep last 1000 errors
 	
-	return &ActionManager{
+	am := &ActionManager{
 		providerManager:       providerManager,
 		saidataManager:        saidataManager,
 		executor:              executor,
@@ -74,24 +90,58 @@ func NewActionManager(
 		recoveryManager:       recoveryManager,
 		circuitBreakerManager: circuitBreakerManager,
 		errorTracker:          errorTracker,
+		markerStore:           managed.NewMarkerStore(config.ManagedDir),
+		journal:               transaction.NewJournal(config.Transactions.Dir),
+		privileges:            privilege.NewManager(config.Privilege.Escalation, config.Privilege.Tool),
 	}
+	am.loadCircuitBreakers()
+	return am
 }
 
 // ExecuteAction executes a specific action on software with full workflow orchestration
 func (am *ActionManager) ExecuteAction(ctx context.Context, action string, software string, options interfaces.ActionOptions) (*interfaces.ActionResult, error) {
 	startTime := time.Now()
 
+	// Step 0: Bootstrap an explicitly requested provider that is not installed yet
+	if err := am.bootstrapProviderIfNeeded(ctx, options.Provider, options); err != nil {
+		return am.buildErrorResult(action, software, options.Provider, err, startTime), err
+	}
+
 	// Step 1: Validate action can be performed
 	if err := am.ValidateAction(action, software); err != nil {
 		return am.buildErrorResult(action, software, "", err, startTime), err
 	}
 
+	// Services of an alternate root filesystem are not running
+	if result := am.rootServiceAction(action, software, startTime); result != nil {
+		return result, nil
+	}
+
 	// Step 2: Resolve software data (saidata or intelligent defaults)
 	saidata, err := am.ResolveSoftwareData(software)
 	if err != nil {
 		return am.buildErrorResult(action, software, "", fmt.Errorf("failed to resolve software data: %w", err), startTime), err
 	}
 
+	// Warn about deprecated software, and install its replacement when the user accepts it
+	if replacement := am.checkDeprecation(action, software, saidata, options); replacement != software {
+		software = replacement
+		saidata, err = am.ResolveSoftwareData(software)
+		if err != nil {
+			return am.buildErrorResult(action, software, "", fmt.Errorf("failed to resolve software data: %w", err), startTime), err
+		}
+	}
+
+	// Refuse to change the system from guessed package names and paths in strict mode,
+	// a dry run only shows the generated commands
+	if saidata.IsGenerated && !options.AllowGenerated && am.config.IsStrictAction(action) {
+		strictErr := errors.NewSaidataGeneratedError(action, software)
+		if !options.DryRun {
+			return am.buildErrorResult(action, software, "", strictErr, startTime), strictErr
+		}
+		am.formatter.ShowWarning(strictErr.Message)
+	}
+
 	// Step 3: Setup repositories if needed (Requirement 8.5)
 	if err := am.ManageRepositorySetup(saidata); err != nil {
 		am.formatter.ShowWarning(fmt.Sprintf("Repository setup failed: %v", err))
@@ -118,6 +168,17 @@ func (am *ActionManager) ExecuteAction(ctx context.Context, action string, softw
 		return am.executeAcrossProviders(ctx, action, software, providerOptions, options, saidata, startTime)
 	}
 
+	// Escalate the action when it requires root, or fail before anything is changed
+	ctx, err = am.checkPrivileges(ctx, action, software, selectedProvider, options)
+	if err != nil {
+		return am.buildErrorResult(action, software, selectedProvider.Provider.Name, err, startTime), err
+	}
+
+	// Refuse a requested version the provider could not check once installed
+	if err := checkVersionAction(action, selectedProvider, options.Version); err != nil {
+		return am.buildErrorResult(action, software, selectedProvider.Provider.Name, err, startTime), err
+	}
+
 	// Step 6: Perform comprehensive safety checks (Requirement 10.5)
 	safetyResult, err := am.safetyManager.CheckActionSafety(action, software, selectedProvider, saidata)
 	if err != nil {
@@ -143,24 +204,31 @@ func (am *ActionManager) ExecuteAction(ctx context.Context, action string, softw
 		am.formatter.ShowWarning(warning)
 	}
 
-	// Step 7: Get commands that will be executed
+	// Step 7: Collect action inputs and get commands that will be executed
+	variables, err := am.collectInputs(action, selectedProvider, saidata, options)
+	if err != nil {
+		return am.buildErrorResult(action, software, selectedProvider.Provider.Name, err, startTime), err
+	}
+
 	executeOptions := interfaces.ExecuteOptions{
 		DryRun:    options.DryRun,
 		Verbose:   options.Verbose,
 		Timeout:   options.Timeout,
-		Variables: options.Variables,
+		Variables: variables,
+		Workflow:  options.Workflow,
+		Version:   options.Version,
 	}
 
-	// Get preview of commands for confirmation
+	// Get preview of commands for confirmation, with secret inputs masked
 	var commands []string
 	if previewResult, err := am.executor.DryRun(ctx, selectedProvider, action, software, saidata, executeOptions); err == nil {
-		commands = previewResult.Commands
+		commands = debug.MaskSecretsAll(previewResult.Commands)
 	}
 
 	// Step 8: Handle confirmation prompts with enhanced safety information (Requirements 9.1, 9.2)
 	if am.confirmationManager.RequiresConfirmation(action, options) {
 		// Check for destructive operations first
-		if action == "uninstall" || action == "stop" || action == "disable" {
+		if am.confirmationManager.IsDestructiveAction(action) {
 			confirmed, err := am.confirmationManager.ConfirmDestructiveAction(action, software, safetyResult)
 			if err != nil {
 				return am.buildErrorResult(action, software, selectedProvider.Provider.Name, err, startTime), err
@@ -198,19 +266,43 @@ func (am *ActionManager) ExecuteAction(ctx context.Context, action string, softw
 		}
 	}
 
-	// Step 9: Execute the action with circuit breaker protection and error recovery
+	// Run the pre hooks of the saidata and configuration, which may cancel the action
+	preHookCommands, err := am.runHooks(ctx, hookPre, action, software, saidata, selectedProvider, executeOptions)
+	if err != nil {
+		return am.buildErrorResult(action, software, selectedProvider.Provider.Name, err, startTime), err
+	}
+
+	// Step 9: Execute the action with circuit breaker protection and error recovery,
+	// journaling its changes in a transaction
 	var executionResult *interfaces.ExecutionResult
+	streamed := false
+	tx := am.beginTransaction(action, software, selectedProvider.Provider.Name, options)
 	if options.DryRun {
 		am.formatter.ShowInfo("Dry run mode - showing commands that would be executed:")
 		executionResult, err = am.executor.DryRun(ctx, selectedProvider, action, software, saidata, executeOptions)
+	} else if unlock, lockErr := am.lockProvider(ctx, selectedProvider.Provider.Name, action, selectedProvider.Actions[action].RequiresRoot); lockErr != nil {
+		// Another sai process holding the provider is not a failure of the provider,
+		// so the circuit breaker and recovery are left out
+		err = lockErr
 	} else {
+		// Show the output live when streaming, for long builds
+		var stream io.WriteCloser
+		if am.streamOutput(action) {
+			stream = am.formatter.StreamWriter(selectedProvider.Provider.Name)
+			executeOptions.Output = stream
+			streamed = true
+		}
+
 		// Execute with circuit breaker protection
-		circuitBreakerName := fmt.Sprintf("%s_%s", selectedProvider.Provider.Name, action)
-		err = am.circuitBreakerManager.ExecuteWithCircuitBreaker(circuitBreakerName, func() error {
+		err = am.executeWithCircuitBreaker(selectedProvider.Provider.Name, action, func() error {
+			defer unlock()
 			var execErr error
 			executionResult, execErr = am.executor.Execute(ctx, selectedProvider, action, software, saidata, executeOptions)
 			return execErr
 		})
+		if stream != nil {
+			stream.Close()
+		}
 		
 		// If execution failed and error is recoverable, attempt recovery
 		if err != nil && errors.IsRecoverable(err) {
@@ -258,11 +350,12 @@ func (am *ActionManager) ExecuteAction(ctx context.Context, action string, softw
 		Success:              executionResult != nil && executionResult.Success,
 		Duration:             time.Since(startTime),
 		RequiredConfirmation: am.RequiresConfirmation(action),
+		Streamed:             streamed,
 	}
 
 	if executionResult != nil {
-		result.Output = executionResult.Output
-		result.Commands = executionResult.Commands
+		result.Output = debug.MaskSecrets(executionResult.Output)
+		result.Commands = debug.MaskSecretsAll(executionResult.Commands)
 		result.ExitCode = executionResult.ExitCode
 		result.Changes = executionResult.Changes
 	}
@@ -275,8 +368,59 @@ func (am *ActionManager) ExecuteAction(ctx context.Context, action string, softw
 		}
 	}
 
-	// Step 11: Show result to user
+	// Check that the installed version satisfies the one requested with software@version
+	if result.Success && !options.DryRun && options.Version != "" && (action == "install" || action == "upgrade") {
+		if versionErr := am.checkInstalledVersion(ctx, software, selectedProvider, saidata, options.Version); versionErr != nil {
+			err = versionErr
+			result.Error = versionErr
+			result.Success = false
+			result.ExitCode = 1
+		}
+	}
+
+	// Remove the resources saidata declares that the package manager left behind
+	if action == "uninstall" && result.Success && options.Purge {
+		result.Changes = append(result.Changes, am.purgeResources(ctx, software, saidata, options)...)
+	}
+
+	// Run the post hooks once the action succeeded, an aborting hook failing the action
+	if result.Success {
+		postHookCommands, hookErr := am.runHooks(ctx, hookPost, action, software, saidata, selectedProvider, executeOptions)
+		result.Commands = append(result.Commands, postHookCommands...)
+		if hookErr != nil {
+			err = hookErr
+			result.Error = hookErr
+			result.Success = false
+			result.ExitCode = 1
+		}
+	}
+	result.Commands = append(preHookCommands, result.Commands...)
+
+	if action == "install" && result.Success && !options.DryRun {
+		result.Notes = am.renderNotes(saidata, selectedProvider)
+	}
+
+	am.finishTransaction(ctx, tx, result, selectedProvider, saidata, options)
+
+	// Publish the variables registered or captured by the steps under custom names to the
+	// later actions of the workflow
+	if result.Success && !options.DryRun && executionResult.Runtime != nil {
+		options.Workflow.Publish(action, software, executionResult.Runtime.Values)
+	}
+
+	// Step 11: Record or remove "managed by sai" markers, restore SELinux contexts and
+	// clear the macOS quarantine of verified binaries
+	if result.Success && !options.DryRun {
+		am.updateManagedMarker(action, software, selectedProvider, saidata)
+		am.restoreSecurityContexts(ctx, action, saidata)
+		am.clearQuarantine(ctx, action, selectedProvider, saidata, options)
+	}
+
+	// Step 12: Show result to user, with security module denials on failure
 	am.displayActionResult(result)
+	if !result.Success && !options.DryRun {
+		am.showSecurityDenials(software, saidata, startTime)
+	}
 
 	return result, err
 }
@@ -384,35 +528,57 @@ func (am *ActionManager) GetAvailableProviders(software string, action string) (
 		return nil, fmt.Errorf("no providers support action %s", action)
 	}
 
+	saidata, err := am.ResolveSoftwareData(software)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve saidata for %s: %w", software, err)
+	}
+
 	var options []*interfaces.ProviderOption
-	for _, provider := range providers {
-		if am.providerManager.IsProviderAvailable(provider.Provider.Name) {
-			// Validate that the action can be executed with this provider
-			if saidata, err := am.ResolveSoftwareData(software); err == nil {
-				if am.executor.CanExecute(provider, action, software, saidata) {
-					option := &interfaces.ProviderOption{
-						Provider:    provider,
-						PackageName: am.getPackageName(provider, software),
-						Version:     am.getProviderVersion(provider),
-						IsInstalled: am.isPackageInstalled(provider, software),
-						Priority:    am.getProviderPriority(provider),
-					}
-					options = append(options, option)
-				} else {
-					// Log why this provider was rejected
-					am.formatter.ShowDebug(fmt.Sprintf("Provider %s rejected: action %s cannot be executed", provider.Provider.Name, action))
-				}
-			} else {
-				// Log saidata resolution failure
-				am.formatter.ShowDebug(fmt.Sprintf("Provider %s rejected: failed to resolve saidata for %s: %v", provider.Provider.Name, software, err))
+	var rejections []string
+	for _, capability := range am.providerManager.WhichProvidersCan(action, saidata) {
+		provider := capability.Provider
+		if !hasProviderAction(provider, action) {
+			continue
+		}
+		if !capability.Capable {
+			am.formatter.ShowDebug(fmt.Sprintf("Provider %s rejected: %s", provider.Provider.Name, capability.Reason))
+			// Providers missing from this host would only be noise in the error
+			if capability.Available {
+				rejections = append(rejections, fmt.Sprintf("%s: %s", provider.Provider.Name, capability.Reason))
 			}
-		} else {
-			// Log provider availability issue
-			am.formatter.ShowDebug(fmt.Sprintf("Provider %s not available on this system", provider.Provider.Name))
+			continue
+		}
+		if !am.executor.CanExecute(provider, action, software, saidata) {
+			am.formatter.ShowDebug(fmt.Sprintf("Provider %s rejected: action %s cannot be executed", provider.Provider.Name, action))
+			rejections = append(rejections, fmt.Sprintf("%s: action %s cannot be executed", provider.Provider.Name, action))
+			continue
 		}
+
+		priority := am.getProviderPriority(provider)
+		if am.isCircuitOpen(provider.Provider.Name, action) {
+			switch am.openProvidersPolicy() {
+			case errors.OpenProvidersExclude:
+				am.formatter.ShowDebug(fmt.Sprintf("Provider %s rejected: circuit breaker of action %s is open", provider.Provider.Name, action))
+				rejections = append(rejections, fmt.Sprintf("%s: circuit breaker of action %s is open", provider.Provider.Name, action))
+				continue
+			case errors.OpenProvidersDemote:
+				am.formatter.ShowDebug(fmt.Sprintf("Provider %s demoted: circuit breaker of action %s is open", provider.Provider.Name, action))
+				priority -= demotedPriority
+			}
+		}
+		options = append(options, &interfaces.ProviderOption{
+			Provider:    provider,
+			PackageName: am.getPackageName(provider, software),
+			Version:     am.getProviderVersion(provider),
+			IsInstalled: am.isPackageInstalled(provider, software),
+			Priority:    priority,
+		})
 	}
 
 	if len(options) == 0 {
+		if len(rejections) > 0 {
+			return nil, fmt.Errorf("no executable providers available for action %s on software %s (%s)", action, software, strings.Join(rejections, "; "))
+		}
 		return nil, fmt.Errorf("no executable providers available for action %s on software %s", action, software)
 	}
 
@@ -424,6 +590,21 @@ func (am *ActionManager) GetAvailableProviders(software string, action string) (
 	return options, nil
 }
 
+// rejectionReason returns why a provider cannot run an action on a software, "" when
+// it can or the reason is unknown
+func (am *ActionManager) rejectionReason(software, action, providerName string) string {
+	saidata, err := am.ResolveSoftwareData(software)
+	if err != nil {
+		return ""
+	}
+	for _, capability := range am.providerManager.WhichProvidersCan(action, saidata) {
+		if capability.Provider.Provider.Name == providerName {
+			return capability.Reason
+		}
+	}
+	return ""
+}
+
 // RequiresConfirmation checks if an action requires user confirmation
 func (am *ActionManager) RequiresConfirmation(action string) bool {
 	return am.config.RequiresConfirmation(action)
@@ -475,21 +656,58 @@ func (am *ActionManager) SearchAcrossProviders(software string) ([]*interfaces.S
 			continue
 		}
 
-		// Parse search results (simplified - in real implementation would parse provider-specific output)
-		result := &interfaces.SearchResult{
-			Software:    software,
-			Provider:    provider.Provider.Name,
-			PackageName: am.getPackageName(provider, software),
-			Version:     "available", // Would parse from output
-			Description: fmt.Sprintf("%s package from %s", software, provider.Provider.DisplayName),
-			Available:   executionResult.Success,
+		deprecated, replacedBy := deprecationOf(software, saidata)
+		for _, result := range am.parseSearchResults(provider, software, executionResult.Output) {
+			result.Deprecated, result.ReplacedBy = deprecated, replacedBy
+			results = append(results, result)
 		}
-		results = append(results, result)
 	}
 
 	return results, nil
 }
 
+// parseSearchResults turns the output of the search action of a provider into results,
+// the package of the software first. Providers whose search action sets no parser, or
+// whose output does not parse, report the package of the software without details.
+func (am *ActionManager) parseSearchResults(provider *types.ProviderData, software, output string) []*interfaces.SearchResult {
+	packageName := am.getPackageName(provider, software)
+	unparsed := []*interfaces.SearchResult{{
+		Software:    software,
+		Provider:    provider.Provider.Name,
+		PackageName: packageName,
+		Available:   true,
+	}}
+
+	parserName := provider.Actions["search"].Parser
+	if parserName == "" {
+		return unparsed
+	}
+	parser, err := parsers.GetSearch(parserName)
+	if err == nil {
+		var found []parsers.SearchResult
+		if found, err = parser(output); err == nil {
+			sort.SliceStable(found, func(i, j int) bool {
+				return found[i].Name == packageName && found[j].Name != packageName
+			})
+			results := make([]*interfaces.SearchResult, 0, len(found))
+			for _, pkg := range found {
+				results = append(results, &interfaces.SearchResult{
+					Software:    software,
+					Provider:    provider.Provider.Name,
+					PackageName: pkg.Name,
+					Version:     pkg.Version,
+					Description: pkg.Description,
+					Repository:  pkg.Repository,
+					Available:   true,
+				})
+			}
+			return results
+		}
+	}
+	am.formatter.ShowDebug(fmt.Sprintf("Failed to parse the search results of %s: %v", provider.Provider.Name, err))
+	return unparsed
+}
+
 // GetSoftwareInfo gets information about software from all providers (Requirement 2.4)
 func (am *ActionManager) GetSoftwareInfo(software string) ([]*interfaces.SoftwareInfo, error) {
 	var results []*interfaces.SoftwareInfo
@@ -740,6 +958,9 @@ func (am *ActionManager) selectProvider(software, action string, options []*inte
 				return option.Provider, nil
 			}
 		}
+		if reason := am.rejectionReason(software, action, actionOptions.Provider); reason != "" {
+			return nil, fmt.Errorf("preferred provider %s not available for action %s: %s", actionOptions.Provider, action, reason)
+		}
 		return nil, fmt.Errorf("preferred provider %s not available for action %s", actionOptions.Provider, action)
 	}
 
@@ -783,12 +1004,8 @@ func (am *ActionManager) selectProvider(software, action string, options []*inte
 			previewCommand = am.generateUninstallCommand(providerName, packageName)
 		case "upgrade":
 			previewCommand = am.generateUpgradeCommand(providerName, packageName)
-		case "start":
-			previewCommand = am.generateStartCommand(providerName, packageName)
-		case "stop":
-			previewCommand = am.generateStopCommand(providerName, packageName)
-		case "restart":
-			previewCommand = am.generateRestartCommand(providerName, packageName)
+		case "start", "stop", "restart":
+			previewCommand = am.generateServiceCommand(action, providerName, packageName)
 		default:
 			previewCommand = fmt.Sprintf("%s %s %s", providerName, action, packageName)
 		}
@@ -807,6 +1024,48 @@ func (am *ActionManager) selectProvider(software, action string, options []*inte
 	return selectedOption.Provider, nil
 }
 
+// updateManagedMarker records install/upgrade markers and removes them on uninstall
+func (am *ActionManager) updateManagedMarker(action, software string, provider *types.ProviderData, saidata *types.SoftwareData) {
+	var err error
+	switch action {
+	case "install", "upgrade":
+		// Only refresh markers on upgrade for software sai already manages
+		if action == "upgrade" && !am.markerStore.IsManaged(software) {
+			return
+		}
+		marker := &managed.Marker{
+			Software: software,
+			Provider: provider.Provider.Name,
+			Packages: am.getPackageNames(provider, saidata),
+			Version:  am.getPackageVersion(provider, saidata),
+		}
+		paths, directories := installedFiles(provider, saidata)
+		if len(paths) > 0 || len(directories) > 0 {
+			files, digestErr := managed.DigestFiles(paths, directories)
+			if digestErr != nil {
+				am.formatter.ShowWarning(fmt.Sprintf("Failed to record the installed files of %s, sai verify cannot check them: %v", software, digestErr))
+			} else {
+				marker.Files = files
+				marker.Directories = existingDirectories(directories)
+			}
+		}
+		err = am.markerStore.Record(marker)
+	case "uninstall":
+		err = am.markerStore.Remove(software)
+	default:
+		return
+	}
+
+	if err != nil {
+		am.formatter.ShowWarning(fmt.Sprintf("Failed to update managed marker for %s: %v", software, err))
+	}
+}
+
+// GetMarkerStore returns the store of "managed by sai" markers
+func (am *ActionManager) GetMarkerStore() *managed.MarkerStore {
+	return am.markerStore
+}
+
 // buildErrorResult creates an error result with consistent structure
 func (am *ActionManager) buildErrorResult(action, software, provider string, err error, startTime time.Time) *interfaces.ActionResult {
 	return &interfaces.ActionResult{
@@ -850,6 +1109,11 @@ func (am *ActionManager) displayActionResult(result *interfaces.ActionResult) {
 		}
 	} else if result.Error != nil {
 		am.formatter.ShowError(result.Error)
+		if saiErr, ok := result.Error.(*errors.SAIError); ok {
+			for _, suggestion := range saiErr.Suggestions {
+				am.formatter.ShowInfo("Suggestion: " + suggestion)
+			}
+		}
 	}
 
 	// Show execution details in verbose mode
@@ -863,6 +1127,13 @@ func (am *ActionManager) displayActionResult(result *interfaces.ActionResult) {
 		
 		if len(result.Changes) > 0 {
 			am.formatter.ShowDebug(fmt.Sprintf("Changes made: %d", len(result.Changes)))
+			for _, change := range result.Changes {
+				am.formatter.ShowDebug("  " + change.String())
+			}
+		}
+
+		if result.TransactionID != "" {
+			am.formatter.ShowDebug(fmt.Sprintf("Transaction: %s", result.TransactionID))
 		}
 	}
 }
@@ -884,6 +1155,33 @@ func (am *ActionManager) getPackageName(provider *types.ProviderData, software s
 	return software
 }
 
+// getPackageNames returns the package names for a provider, preferring provider-specific packages
+func (am *ActionManager) getPackageNames(provider *types.ProviderData, saidata *types.SoftwareData) []string {
+	packages := saidata.Packages
+	if providerConfig := saidata.GetProviderConfig(provider.Provider.Name); providerConfig != nil && len(providerConfig.Packages) > 0 {
+		packages = providerConfig.Packages
+	}
+
+	var names []string
+	for _, pkg := range packages {
+		names = append(names, pkg.GetPackageNameOrDefault())
+	}
+	return names
+}
+
+// getPackageVersion returns the known package version for a provider, if any
+func (am *ActionManager) getPackageVersion(provider *types.ProviderData, saidata *types.SoftwareData) string {
+	if providerConfig := saidata.GetProviderConfig(provider.Provider.Name); providerConfig != nil {
+		if len(providerConfig.Packages) > 0 && providerConfig.Packages[0].Version != "" {
+			return providerConfig.Packages[0].Version
+		}
+	}
+	if len(saidata.Packages) > 0 && saidata.Packages[0].Version != "" {
+		return saidata.Packages[0].Version
+	}
+	return saidata.Metadata.Version
+}
+
 // Helper methods to generate command previews for provider selection (Requirement 15.3)
 
 func (am *ActionManager) generateInstallCommand(provider, packageName string) string {
@@ -975,30 +1273,34 @@ func (am *ActionManager) generateUpgradeCommand(provider, packageName string) st
 	}
 }
 
-func (am *ActionManager) generateStartCommand(provider, packageName string) string {
-	// Service commands are typically system-level, not provider-specific
-	return fmt.Sprintf("systemctl start %s", packageName)
-}
-
-func (am *ActionManager) generateStopCommand(provider, packageName string) string {
-	return fmt.Sprintf("systemctl stop %s", packageName)
-}
-
-func (am *ActionManager) generateRestartCommand(provider, packageName string) string {
-	return fmt.Sprintf("systemctl restart %s", packageName)
+// generateServiceCommand previews a service action, which is run by the service
+// manager of the system rather than by the provider, except for brew services
+func (am *ActionManager) generateServiceCommand(action, provider, serviceName string) string {
+	manager := servicemgr.ForService(serviceName)
+	if provider == "brew" {
+		manager = servicemgr.Brew
+	}
+	command, err := manager.Command(action, serviceName)
+	if err != nil {
+		return fmt.Sprintf("%s %s", action, serviceName)
+	}
+	return command
 }
 
 // executeAcrossProviders executes an action across all available providers for information-only commands
 // This implements Requirements 15.2 and 15.4 - automatic execution without provider selection prompts
 func (am *ActionManager) executeAcrossProviders(ctx context.Context, action, software string, providerOptions []*interfaces.ProviderOption, actionOptions interfaces.ActionOptions, saidata *types.SoftwareData, startTime time.Time) (*interfaces.ActionResult, error) {
-	var allResults []*interfaces.ExecutionResult
 	var allCommands []string
 	var allOutput []string
 	var hasErrors bool
 	var lastError error
 
-	am.formatter.ShowInfo(fmt.Sprintf("Executing %s for %s across all available providers:", action, software))
-	fmt.Println()
+	// JSON output only reports the combined result, with the outcome of every provider
+	text := !am.formatter.IsJSONMode()
+	if text {
+		am.formatter.ShowInfo(fmt.Sprintf("Executing %s for %s across all available providers:", action, software))
+		fmt.Println()
+	}
 
 	executeOptions := interfaces.ExecuteOptions{
 		DryRun:    actionOptions.DryRun,
@@ -1007,61 +1309,92 @@ func (am *ActionManager) executeAcrossProviders(ctx context.Context, action, sof
 		Variables: actionOptions.Variables,
 	}
 
-	for _, option := range providerOptions {
-		provider := option.Provider
-		
-		// Show compact provider header (Requirement 15.5)
-		providerHeader := am.formatter.FormatProviderName(provider.Provider.Name)
-		fmt.Printf("%s:\n", providerHeader)
+	// Providers run concurrently, each within its own budget, so one hanging provider
+	// only delays the run by its budget
+	runs := make([]providerRun, len(providerOptions))
+	var wg sync.WaitGroup
+	for i, option := range providerOptions {
+		wg.Add(1)
+		go func(i int, provider *types.ProviderData) {
+			defer wg.Done()
+			began := time.Now()
+			runs[i] = am.runWithBudget(ctx, provider.Provider.Name, func(ctx context.Context) (*interfaces.ExecutionResult, error) {
+				if actionOptions.DryRun {
+					return am.executor.DryRun(ctx, provider, action, software, saidata, executeOptions)
+				}
+				return am.executor.Execute(ctx, provider, action, software, saidata, executeOptions)
+			})
+			runs[i].duration = time.Since(began)
+		}(i, option.Provider)
+	}
+	wg.Wait()
 
-		// Execute the action for this provider
-		var executionResult *interfaces.ExecutionResult
-		var err error
+	// Results are reported in the order of the providers, whichever finished first
+	providerResults := make([]interfaces.ProviderResult, 0, len(providerOptions))
+	for i, option := range providerOptions {
+		provider := option.Provider
+		providerResult := providerResult(provider.Provider.Name, runs[i])
+		providerResults = append(providerResults, providerResult)
 
-		if actionOptions.DryRun {
-			executionResult, err = am.executor.DryRun(ctx, provider, action, software, saidata, executeOptions)
-		} else {
-			executionResult, err = am.executor.Execute(ctx, provider, action, software, saidata, executeOptions)
+		if runs[i].err != nil {
+			hasErrors = true
+			lastError = runs[i].err
+			if runs[i].timedOut {
+				lastError = fmt.Errorf("%s %w", provider.Provider.Name, runs[i].err)
+			}
+		} else if runs[i].result != nil {
+			allCommands = append(allCommands, providerResult.Commands...)
+			if providerResult.Output != "" && !am.formatter.IsQuietMode() {
+				allOutput = append(allOutput, providerResult.Output)
+			}
+			if !providerResult.Success {
+				hasErrors = true
+			}
+		}
+		if !text {
+			continue
 		}
 
-		if err != nil {
-			hasErrors = true
-			lastError = err
-			am.formatter.ShowError(fmt.Errorf("  %s failed: %v", provider.Provider.Name, err))
-		} else if executionResult != nil {
-			allResults = append(allResults, executionResult)
-			allCommands = append(allCommands, executionResult.Commands...)
-			
+		// Show compact provider header (Requirement 15.5)
+		providerHeader := am.formatter.FormatProviderName(provider.Provider.Name)
+		fmt.Printf("%s (%s):\n", providerHeader, providerResult.Duration.Round(time.Millisecond))
+
+		if runs[i].timedOut {
+			am.formatter.ShowError(fmt.Errorf("  ✗ Timed out after %s", runs[i].budget))
+		} else if runs[i].err != nil {
+			am.formatter.ShowError(fmt.Errorf("  %s failed: %v", provider.Provider.Name, runs[i].err))
+		} else if runs[i].result != nil {
 			// Show compact output format (Requirements 15.3, 15.5)
-			if len(executionResult.Commands) > 0 {
-				for _, cmd := range executionResult.Commands {
-					fmt.Printf("  Command: %s\n", cmd)
-				}
+			for _, cmd := range providerResult.Commands {
+				fmt.Printf("  Command: %s\n", cmd)
 			}
 			
-			if executionResult.Output != "" && !am.formatter.IsQuietMode() {
+			if providerResult.Output != "" && !am.formatter.IsQuietMode() {
 				// Show output with proper formatting
-				outputLines := strings.Split(strings.TrimSpace(executionResult.Output), "\n")
+				outputLines := strings.Split(strings.TrimSpace(providerResult.Output), "\n")
 				for _, line := range outputLines {
 					if line != "" {
 						fmt.Printf("  %s\n", line)
 					}
 				}
-				allOutput = append(allOutput, executionResult.Output)
 			}
 			
 			// Show exit status
-			if executionResult.ExitCode == 0 {
+			if providerResult.Success {
 				am.formatter.ShowSuccess("  ✓ Success")
 			} else {
-				am.formatter.ShowError(fmt.Errorf("  ✗ Failed (exit code: %d)", executionResult.ExitCode))
-				hasErrors = true
+				am.formatter.ShowError(fmt.Errorf("  ✗ Failed (exit code: %d)", providerResult.ExitCode))
 			}
 		}
 		
 		fmt.Println() // Add spacing between providers
 	}
 
+	if text && !am.formatter.IsQuietMode() {
+		writeAcrossSummary(os.Stdout, providerResults)
+		fmt.Println()
+	}
+
 	// Build combined result
 	result := &interfaces.ActionResult{
 		Action:               action,
@@ -1073,6 +1406,7 @@ func (am *ActionManager) executeAcrossProviders(ctx context.Context, action, sof
 		Output:               strings.Join(allOutput, "\n"),
 		ExitCode:             0,
 		RequiredConfirmation: false, // Information-only commands don't require confirmation
+		ProviderResults:      providerResults,
 	}
 
 	if hasErrors {
@@ -1084,6 +1418,14 @@ func (am *ActionManager) executeAcrossProviders(ctx context.Context, action, sof
 	return result, lastError
 }
 
+// streamOutput reports whether the output of system-changing actions is shown live
+func (am *ActionManager) streamOutput(action string) bool {
+	if am.config == nil || !am.config.Output.Stream || am.config.IsInformationOnlyAction(action) {
+		return false
+	}
+	return !am.formatter.IsQuietMode() && !am.formatter.IsJSONMode()
+}
+
 // parseVersionOutput parses version information from provider command output
 func (am *ActionManager) parseVersionOutput(providerName, output string) string {
 	if output == "" {
@@ -1187,8 +1529,9 @@ func (am *ActionManager) isPackageInstalled(provider *types.ProviderData, softwa
 		
 		// Execute detection command
 		result, err := am.executor.ExecuteCommand(ctx, detectionCmd, interfaces.CommandOptions{
-			Timeout: 10 * time.Second,
-			Verbose: false,
+			Timeout:  10 * time.Second,
+			Verbose:  false,
+			ReadOnly: true,
 		})
 		
 		// If command succeeds (exit code 0), package is installed
@@ -1242,6 +1585,7 @@ func (am *ActionManager) GetCircuitBreakerStats() map[string]*errors.CircuitBrea
 // ResetCircuitBreakers resets all circuit breakers
 func (am *ActionManager) ResetCircuitBreakers() {
 	am.circuitBreakerManager.ResetAll()
+	am.saveCircuitBreakers()
 	am.formatter.ShowInfo("All circuit breakers have been reset")
 }
 
@@ -1251,6 +1595,7 @@ func (am *ActionManager) ResetCircuitBreaker(name string) error {
 	if err != nil {
 		return errors.WrapSAIError(errors.ErrorTypeInternal, "failed to reset circuit breaker", err)
 	}
+	am.saveCircuitBreakers()
 	am.formatter.ShowInfo(fmt.Sprintf("Circuit breaker '%s' has been reset", name))
 	return nil
 }
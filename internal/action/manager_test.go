@@ -33,6 +33,12 @@ func (m *mockProviderManager) GetAvailableProviders() []*types.ProviderData {
 	}
 	return providers
 }
+func (m *mockProviderManager) GetAllProviders() []*types.ProviderData {
+	return m.GetAvailableProviders()
+}
+func (m *mockProviderManager) GetPlatformProviders() []*types.ProviderData {
+	return m.GetAvailableProviders()
+}
 func (m *mockProviderManager) SelectProvider(software string, action string, preferredProvider string) (*types.ProviderData, error) {
 	return m.GetProvider(preferredProvider)
 }
@@ -49,9 +55,27 @@ func (m *mockProviderManager) GetProvidersForAction(action string) []*types.Prov
 	}
 	return providers
 }
+func (m *mockProviderManager) WhichProvidersCan(action string, saidata *types.SoftwareData) []interfaces.ProviderCapability {
+	var capabilities []interfaces.ProviderCapability
+	for _, provider := range m.GetProvidersForAction(action) {
+		capabilities = append(capabilities, interfaces.ProviderCapability{Provider: provider, Available: true, Capable: true})
+	}
+	return capabilities
+}
 func (m *mockProviderManager) ValidateProvider(provider *types.ProviderData) error { return nil }
 func (m *mockProviderManager) ReloadProviders() error                              { return nil }
 
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(msg string, fields ...interfaces.LogField)            {}
+func (m *mockLogger) Info(msg string, fields ...interfaces.LogField)             {}
+func (m *mockLogger) Warn(msg string, fields ...interfaces.LogField)             {}
+func (m *mockLogger) Error(msg string, err error, fields ...interfaces.LogField) {}
+func (m *mockLogger) Fatal(msg string, err error, fields ...interfaces.LogField) {}
+func (m *mockLogger) WithFields(fields ...interfaces.LogField) interfaces.Logger { return m }
+func (m *mockLogger) SetLevel(level interfaces.LogLevel)                         {}
+func (m *mockLogger) GetLevel() interfaces.LogLevel                              { return interfaces.LogLevelInfo }
+
 type mockSaidataManager struct {
 	saidata map[string]*types.SoftwareData
 }
@@ -133,6 +157,16 @@ func (m *mockExecutor) ExecuteSteps(ctx context.Context, steps []types.Step, sai
 	}, nil
 }
 
+func (m *mockExecutor) ExecuteScript(ctx context.Context, script string, software string, saidata *types.SoftwareData, provider *types.ProviderData, options interfaces.ExecuteOptions) (*interfaces.ExecutionResult, error) {
+	return &interfaces.ExecutionResult{
+		Success:  true,
+		Output:   "Mock script execution",
+		Commands: []string{script},
+		ExitCode: 0,
+		Duration: time.Millisecond * 10,
+	}, nil
+}
+
 func TestActionManager_ExecuteAction(t *testing.T) {
 	// Setup test data
 	provider := &types.ProviderData{
@@ -178,6 +212,8 @@ func TestActionManager_ExecuteAction(t *testing.T) {
 	executor := &mockExecutor{}
 	validator := validation.NewResourceValidator()
 	cfg := &config.Config{
+		ManagedDir:   t.TempDir(),
+		Transactions: config.TransactionConfig{Dir: t.TempDir()},
 		Confirmations: config.ConfirmationConfig{
 			Install:      false, // Disable confirmation for test
 			InfoCommands: false,
@@ -199,6 +235,7 @@ func TestActionManager_ExecuteAction(t *testing.T) {
 		cfg,
 		ui,
 		formatter,
+		&mockLogger{},
 	)
 
 	// Test successful action execution
@@ -258,6 +295,10 @@ func TestActionManager_SafetyChecks(t *testing.T) {
 				Description: "Install software",
 				Template:    "test-install {{.Software}}",
 			},
+			"start": {
+				Description: "Start software",
+				Template:    "test-start {{.Software}}",
+			},
 		},
 	}
 
@@ -287,6 +328,8 @@ func TestActionManager_SafetyChecks(t *testing.T) {
 	executor := &mockExecutor{}
 	validator := validation.NewResourceValidator()
 	cfg := &config.Config{
+		ManagedDir:   t.TempDir(),
+		Transactions: config.TransactionConfig{Dir: t.TempDir()},
 		Confirmations: config.ConfirmationConfig{
 			Install:      false,
 			InfoCommands: false,
@@ -308,11 +351,12 @@ func TestActionManager_SafetyChecks(t *testing.T) {
 		cfg,
 		ui,
 		formatter,
+		&mockLogger{},
 	)
 
-	// Test safety checks
+	// Test safety checks, install actions create their resources and skip them
 	safetyManager := actionManager.safetyManager
-	safetyResult, err := safetyManager.CheckActionSafety("install", "test-software", provider, saidata)
+	safetyResult, err := safetyManager.CheckActionSafety("start", "test-software", provider, saidata)
 
 	if err != nil {
 		t.Errorf("Expected no error from safety check, got: %v", err)
@@ -396,6 +440,8 @@ func TestActionManager_ProviderSelection(t *testing.T) {
 	executor := &mockExecutor{}
 	validator := validation.NewResourceValidator()
 	cfg := &config.Config{
+		ManagedDir:   t.TempDir(),
+		Transactions: config.TransactionConfig{Dir: t.TempDir()},
 		ProviderPriority: map[string]int{
 			"provider1": 10,
 			"provider2": 5,
@@ -413,6 +459,7 @@ func TestActionManager_ProviderSelection(t *testing.T) {
 		cfg,
 		ui,
 		formatter,
+		&mockLogger{},
 	)
 
 	// Test provider selection with --yes flag (should select highest priority)
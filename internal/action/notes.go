@@ -0,0 +1,64 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"sai/internal/debug"
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// SoftwareNotes returns the next steps after installing a software, rendered for the
+// provider that installed it, or for the provider install would select when sai did
+// not install it
+func (am *ActionManager) SoftwareNotes(software string) (*interfaces.SoftwareNotes, error) {
+	saidata, err := am.ResolveSoftwareData(software)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve software data: %w", err)
+	}
+	if saidata.PostInstallMessage == "" {
+		return nil, fmt.Errorf("the saidata of %s has no post_install_message", software)
+	}
+
+	notes := &interfaces.SoftwareNotes{Software: software}
+	var provider *types.ProviderData
+	if marker, err := am.markerStore.Get(software); err == nil {
+		if provider, err = am.providerManager.GetProvider(marker.Provider); err == nil {
+			notes.Installed = true
+		}
+	}
+	if provider == nil {
+		if options, err := am.GetAvailableProviders(software, "install"); err == nil && len(options) > 0 {
+			provider = options[0].Provider
+		}
+	}
+	if provider != nil {
+		notes.Provider = provider.Provider.Name
+	}
+
+	notes.Notes = am.renderNotes(saidata, provider)
+	return notes, nil
+}
+
+// renderNotes renders the post_install_message of a software, falling back to the
+// template itself when it does not render so the next steps are still shown
+func (am *ActionManager) renderNotes(saidata *types.SoftwareData, provider *types.ProviderData) string {
+	if saidata == nil || saidata.PostInstallMessage == "" {
+		return ""
+	}
+	if provider == nil {
+		provider = &types.ProviderData{}
+	}
+
+	notes := saidata.PostInstallMessage
+	if strings.Contains(notes, "{{") {
+		rendered, err := am.executor.RenderTemplate(notes, saidata, provider)
+		if err != nil {
+			am.formatter.ShowDebug(fmt.Sprintf("Failed to render the post_install_message of %s: %v", saidata.Metadata.Name, err))
+		} else {
+			notes = rendered
+		}
+	}
+	return debug.MaskSecrets(strings.TrimSpace(notes))
+}
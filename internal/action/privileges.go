@@ -0,0 +1,46 @@
+package action
+
+import (
+	"context"
+	"fmt"
+
+	"sai/internal/interfaces"
+	"sai/internal/privilege"
+	"sai/internal/types"
+)
+
+// isPrivileged returns whether sai runs as root, replaced by tests
+var isPrivileged = privilege.IsPrivileged
+
+// checkPrivileges escalates an action requiring root when sai does not run as root,
+// returning the context its commands run as root in. Without escalation it fails before
+// anything is changed, so the CLI can offer to re-run it with sudo instead of failing
+// halfway through. Dry runs change nothing and are always allowed.
+func (am *ActionManager) checkPrivileges(ctx context.Context, action, software string, provider *types.ProviderData, options interfaces.ActionOptions) (context.Context, error) {
+	if options.DryRun || isPrivileged() {
+		return ctx, nil
+	}
+
+	actionData, exists := provider.Actions[action]
+	if !exists || !actionData.RequiresRoot {
+		return ctx, nil
+	}
+
+	tool, err := am.privileges.Escalate(action, software, provider.Provider.Name, am.confirmEscalation(options))
+	if err != nil {
+		return ctx, err
+	}
+	am.formatter.ShowDebug(fmt.Sprintf("Running %s of %s as root through %s", action, software, tool.Name))
+	return privilege.WithTool(ctx, tool), nil
+}
+
+// confirmEscalation returns the confirmation of the escalation of actions, given by
+// --yes or asked to the user
+func (am *ActionManager) confirmEscalation(options interfaces.ActionOptions) func(message string) (bool, error) {
+	return func(message string) (bool, error) {
+		if options.Yes {
+			return true, nil
+		}
+		return am.ui.PromptForConfirmation(message)
+	}
+}
@@ -0,0 +1,238 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/servicemgr"
+	"sai/internal/types"
+)
+
+// purgeCommandTimeout bounds each command stopping a service or removing a container
+const purgeCommandTimeout = 2 * time.Minute
+
+// protectedPaths are system locations saidata files and directories may be inside of
+// but never are, purging them would break the system
+var protectedPaths = map[string]bool{
+	"/": true, "/bin": true, "/boot": true, "/dev": true, "/etc": true, "/home": true,
+	"/lib": true, "/lib64": true, "/opt": true, "/proc": true, "/root": true, "/run": true,
+	"/sbin": true, "/srv": true, "/sys": true, "/tmp": true, "/usr": true, "/usr/bin": true,
+	"/usr/lib": true, "/usr/local": true, "/usr/local/bin": true, "/usr/local/etc": true,
+	"/usr/local/lib": true, "/usr/sbin": true, "/usr/share": true, "/var": true,
+	"/var/cache": true, "/var/lib": true, "/var/log": true, "/var/run": true, "/var/tmp": true,
+	"/Applications": true, "/Library": true, "/System": true, "/Users": true,
+	"/opt/homebrew": true, "/etc/systemd/system": true,
+}
+
+// resourceNamePattern matches the service and container names purged, which are
+// passed to commands
+var resourceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.@-]*$`)
+
+// purgeResource is a resource declared in saidata found on the system by uninstall
+// --purge
+type purgeResource struct {
+	kind string // service, container, file or directory
+	name string // Service or container name, or path
+}
+
+func (r purgeResource) String() string {
+	return r.kind + " " + r.name
+}
+
+// purgeResources removes the services, containers, files and directories saidata
+// declares that are left after the package was uninstalled, once the user confirms.
+// Resources are detected with the resource validator; a dry run only lists them. It
+// returns the changes made, failures being shown as warnings.
+func (am *ActionManager) purgeResources(ctx context.Context, software string, saidata *types.SoftwareData, options interfaces.ActionOptions) []interfaces.Change {
+	resources := am.purgeableResources(saidata)
+	if len(resources) == 0 {
+		am.formatter.ShowInfo(fmt.Sprintf("No resources of %s left to purge", software))
+		return nil
+	}
+
+	descriptions := make([]string, len(resources))
+	for i, resource := range resources {
+		descriptions[i] = "  " + resource.String()
+	}
+	if options.DryRun {
+		am.formatter.ShowInfo(fmt.Sprintf("Would purge the resources of %s:\n%s", software, strings.Join(descriptions, "\n")))
+		return nil
+	}
+	if !am.BypassConfirmation(options) {
+		confirmed, err := am.ui.PromptForConfirmation(fmt.Sprintf("Purge the resources of %s?\n%s\n", software, strings.Join(descriptions, "\n")))
+		if err != nil || !confirmed {
+			am.formatter.ShowInfo(fmt.Sprintf("Keeping the resources of %s", software))
+			return nil
+		}
+	}
+
+	var changes []interfaces.Change
+	for _, resource := range resources {
+		resourceChanges, err := am.purgeResource(ctx, resource)
+		if err != nil {
+			am.formatter.ShowWarning(fmt.Sprintf("Failed to purge %s: %v", resource, err))
+		}
+		changes = append(changes, resourceChanges...)
+	}
+	return changes
+}
+
+// purgeableResources returns the resources saidata declares that exist on the system,
+// services and containers first so nothing holds the files removed afterwards. With
+// an alternate root, only its files and directories are purged.
+func (am *ActionManager) purgeableResources(saidata *types.SoftwareData) []purgeResource {
+	if saidata == nil || am.validator == nil {
+		return nil
+	}
+	root := am.config.Root
+
+	var resources []purgeResource
+	if root == "" {
+		for _, service := range saidata.Services {
+			if resourceNamePattern.MatchString(service.GetServiceNameOrDefault()) && am.validator.ValidateService(service) {
+				resources = append(resources, purgeResource{kind: "service", name: service.GetServiceNameOrDefault()})
+			}
+		}
+		for _, container := range saidata.Containers {
+			if resourceNamePattern.MatchString(container.Name) && am.validator.ValidateContainer(container) && am.containerExists(container.Name) {
+				resources = append(resources, purgeResource{kind: "container", name: container.Name})
+			}
+		}
+	}
+	for _, file := range saidata.Files {
+		file.Path = purgePath(root, file.Path)
+		if file.Path != "" && am.validator.ValidateFile(file) {
+			resources = append(resources, purgeResource{kind: "file", name: file.Path})
+		}
+	}
+	for _, directory := range saidata.Directories {
+		directory.Path = purgePath(root, directory.Path)
+		if directory.Path != "" && am.validator.ValidateDirectory(directory) {
+			resources = append(resources, purgeResource{kind: "directory", name: directory.Path})
+		}
+	}
+	return resources
+}
+
+// purgePath returns the path of a file or directory to purge under the alternate
+// root, empty when the path is relative or a protected system location
+func purgePath(root, path string) string {
+	if !filepath.IsAbs(path) {
+		return ""
+	}
+	path = filepath.Clean(path)
+	if protectedPaths[filepath.ToSlash(path)] || isHomeDir(path) {
+		return ""
+	}
+	if root != "" {
+		path = filepath.Join(root, path)
+	}
+	return path
+}
+
+// isHomeDir reports whether a path is the home directory of the user
+func isHomeDir(path string) bool {
+	home, err := os.UserHomeDir()
+	return err == nil && filepath.Clean(home) == path
+}
+
+// purgeResource removes a resource, returning the changes made
+func (am *ActionManager) purgeResource(ctx context.Context, resource purgeResource) ([]interfaces.Change, error) {
+	switch resource.kind {
+	case "service":
+		return am.purgeService(ctx, resource.name)
+	case "container":
+		runtime := containerRuntime()
+		if err := am.runPurgeCommand(ctx, fmt.Sprintf("%s rm -f %s", runtime, resource.name)); err != nil {
+			return nil, err
+		}
+		return []interfaces.Change{{Type: "container", Resource: resource.name, Action: "removed"}}, nil
+	case "file":
+		if err := os.Remove(resource.name); err != nil {
+			return nil, err
+		}
+		return []interfaces.Change{{Type: "file", Resource: resource.name, Action: "deleted"}}, nil
+	case "directory":
+		if err := os.RemoveAll(resource.name); err != nil {
+			return nil, err
+		}
+		return []interfaces.Change{{Type: "directory", Resource: resource.name, Action: "deleted"}}, nil
+	}
+	return nil, fmt.Errorf("unknown resource kind %s", resource.kind)
+}
+
+// purgeService stops and disables a service with the service manager of the system.
+// A systemd unit left in /etc/systemd/system, which packages do not own, is removed.
+func (am *ActionManager) purgeService(ctx context.Context, service string) ([]interfaces.Change, error) {
+	manager := servicemgr.ForService(service)
+	var changes []interfaces.Change
+	for _, step := range []struct{ action, change string }{{servicemgr.Stop, "stopped"}, {servicemgr.Disable, "disabled"}} {
+		command, err := manager.Command(step.action, service)
+		if err != nil {
+			return changes, err
+		}
+		if err := am.runPurgeCommand(ctx, command); err != nil {
+			return changes, err
+		}
+		changes = append(changes, interfaces.Change{Type: "service", Resource: service, Action: step.change})
+	}
+
+	if manager != servicemgr.Systemd {
+		return changes, nil
+	}
+	unit := filepath.Join("/etc/systemd/system", service+".service")
+	if _, err := os.Stat(unit); err != nil {
+		return changes, nil
+	}
+	if err := os.Remove(unit); err != nil {
+		return changes, err
+	}
+	changes = append(changes, interfaces.Change{Type: "file", Resource: unit, Action: "deleted"})
+	return changes, am.runPurgeCommand(ctx, "systemctl daemon-reload")
+}
+
+// containerExists reports whether a container of the given name exists
+func (am *ActionManager) containerExists(name string) bool {
+	runtime := containerRuntime()
+	if _, err := exec.LookPath(runtime); err != nil {
+		return false
+	}
+	result, err := am.executor.ExecuteCommand(context.Background(), fmt.Sprintf("%s container inspect %s", runtime, name), interfaces.CommandOptions{
+		Timeout:  purgeCommandTimeout,
+		ReadOnly: true,
+	})
+	return err == nil && result.ExitCode == 0
+}
+
+// containerRuntimes are the runtimes with a docker-compatible command line removing
+// containers, in order of preference as the alternatives of the docker provider
+var containerRuntimes = []string{"docker", "podman", "nerdctl"}
+
+// containerRuntime returns the first container runtime installed, docker when none is
+func containerRuntime() string {
+	for _, runtime := range containerRuntimes {
+		if _, err := exec.LookPath(runtime); err == nil {
+			return runtime
+		}
+	}
+	return "docker"
+}
+
+// runPurgeCommand runs a command removing a resource
+func (am *ActionManager) runPurgeCommand(ctx context.Context, command string) error {
+	result, err := am.executor.ExecuteCommand(ctx, command, interfaces.CommandOptions{Timeout: purgeCommandTimeout})
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("%s exited with code %d: %s", command, result.ExitCode, strings.TrimSpace(result.Output))
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgePath(t *testing.T) {
+	assert.Equal(t, "/etc/nginx", purgePath("", "/etc/nginx/"))
+	assert.Equal(t, "/mnt/image/var/lib/nginx", purgePath("/mnt/image", "/var/lib/nginx"))
+	assert.Empty(t, purgePath("", "/etc"), "system directories are never purged")
+	assert.Empty(t, purgePath("", "/var/lib/../lib"))
+	assert.Empty(t, purgePath("/mnt/image", "/usr"))
+	assert.Empty(t, purgePath("", "nginx.conf"), "relative paths are ignored")
+}
+
+func TestResourceNamePattern(t *testing.T) {
+	assert.True(t, resourceNamePattern.MatchString("nginx"))
+	assert.True(t, resourceNamePattern.MatchString("getty@tty1"))
+	assert.False(t, resourceNamePattern.MatchString("nginx; rm -rf /"))
+	assert.False(t, resourceNamePattern.MatchString("-f"))
+}
@@ -0,0 +1,109 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"sai/internal/checksum"
+	"sai/internal/interfaces"
+	"sai/internal/quarantine"
+	"sai/internal/types"
+)
+
+// clearQuarantine removes the macOS quarantine attribute from binaries installed by a
+// binary provider, so Gatekeeper does not block them on first run. Only binaries whose
+// checksum matches the one saidata declares for them are cleared, after the optional
+// signature and notarization checks pass and the user consents.
+func (am *ActionManager) clearQuarantine(ctx context.Context, action string, provider *types.ProviderData, saidata *types.SoftwareData, options interfaces.ActionOptions) {
+	if runtime.GOOS != "darwin" || !am.config.Gatekeeper.ClearQuarantine {
+		return
+	}
+	if (action != "install" && action != "upgrade") || provider.Provider.Type != "binary" || saidata == nil {
+		return
+	}
+
+	for _, binary := range declaredBinaries(saidata) {
+		path := binary.path
+		if !quarantine.IsQuarantined(ctx, path, am.runSecurityCommand) {
+			continue
+		}
+
+		if err := verifyBinaryChecksum(binary); err != nil {
+			am.formatter.ShowWarning(fmt.Sprintf("Keeping quarantine of %s: %v", path, err))
+			continue
+		}
+
+		if am.config.Gatekeeper.VerifySignature {
+			if err := quarantine.CheckSignature(ctx, path, am.runSecurityCommand); err != nil {
+				am.formatter.ShowWarning(fmt.Sprintf("Keeping quarantine: %v", err))
+				continue
+			}
+		}
+		if am.config.Gatekeeper.RequireNotarization {
+			if err := quarantine.CheckNotarization(ctx, path, am.runSecurityCommand); err != nil {
+				am.formatter.ShowWarning(fmt.Sprintf("Keeping quarantine: %v", err))
+				continue
+			}
+		}
+
+		if !am.BypassConfirmation(options) {
+			confirmed, err := am.ui.PromptForConfirmation(fmt.Sprintf("Remove the macOS quarantine attribute from %s (checksum verified)?", path))
+			if err != nil || !confirmed {
+				am.formatter.ShowInfo(fmt.Sprintf("Keeping quarantine of %s, clear it with 'xattr -d %s %s'", path, quarantine.Attribute, path))
+				continue
+			}
+		}
+
+		if err := quarantine.Clear(ctx, path, am.runSecurityCommand); err != nil {
+			am.formatter.ShowWarning(err.Error())
+			continue
+		}
+		am.formatter.ShowInfo(fmt.Sprintf("Removed quarantine attribute from %s", path))
+	}
+}
+
+// binary is a binary declared in saidata with the checksum of the installed file
+type binary struct {
+	path     string
+	checksum string
+}
+
+// verifyBinaryChecksum succeeds when a binary matches the checksum saidata declares
+// for it. Package checksums are those of the downloaded archives, not of the binaries
+// they contain.
+func verifyBinaryChecksum(binary binary) error {
+	if binary.checksum == "" {
+		return fmt.Errorf("saidata declares no checksum of the binary to verify it against")
+	}
+	return checksum.Verify(binary.path, binary.checksum)
+}
+
+// declaredBinaries returns the commands and binary files declared in saidata
+func declaredBinaries(saidata *types.SoftwareData) []binary {
+	var binaries []binary
+	seen := make(map[string]int)
+	add := func(path, checksum string) {
+		if path == "" {
+			return
+		}
+		if index, exists := seen[path]; exists {
+			if binaries[index].checksum == "" {
+				binaries[index].checksum = checksum
+			}
+			return
+		}
+		seen[path] = len(binaries)
+		binaries = append(binaries, binary{path: path, checksum: checksum})
+	}
+
+	for _, command := range saidata.Commands {
+		add(command.GetPathOrDefault(), command.Checksum)
+	}
+	for _, file := range saidata.Files {
+		if file.Type == "binary" {
+			add(file.Path, file.Checksum)
+		}
+	}
+	return binaries
+}
@@ -0,0 +1,43 @@
+package action
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sai/internal/interfaces"
+)
+
+// rootServiceAction handles service actions when actions run against an alternate root
+// filesystem. Services of an image cannot be started or stopped, so these actions are
+// skipped; enable and disable run with systemctl --root on systemd images and are
+// deferred to the first boot otherwise. It returns nil when the action runs as usual.
+func (am *ActionManager) rootServiceAction(action, software string, startTime time.Time) *interfaces.ActionResult {
+	root := am.config.Root
+	if root == "" {
+		return nil
+	}
+
+	var message string
+	switch action {
+	case "start", "stop", "restart", "status", "logs":
+		message = fmt.Sprintf("Skipping %s of %s: services of the alternate root %s are not running", action, software, root)
+	case "enable", "disable":
+		if info, err := os.Stat(filepath.Join(root, "etc", "systemd", "system")); err == nil && info.IsDir() {
+			return nil
+		}
+		message = fmt.Sprintf("Deferring %s of %s: %s does not use systemd, run 'sai %s %s' after booting it", action, software, root, action, software)
+	default:
+		return nil
+	}
+
+	am.formatter.ShowWarning(message)
+	return &interfaces.ActionResult{
+		Action:   action,
+		Software: software,
+		Success:  true,
+		Output:   message,
+		Duration: time.Since(startTime),
+	}
+}
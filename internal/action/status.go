@@ -0,0 +1,241 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/servicemgr"
+	"sai/internal/types"
+)
+
+const (
+	// statusCommandTimeout bounds each command querying the state of a service or container
+	statusCommandTimeout = 10 * time.Second
+	// portDialTimeout bounds the connection checking a port is listening
+	portDialTimeout = time.Second
+)
+
+// GetSoftwareStatus returns the installed packages of a software and the state of the
+// services, ports, containers, files, directories and commands of its saidata. The
+// resources of the provider sai installed the software with replace the general ones.
+func (am *ActionManager) GetSoftwareStatus(ctx context.Context, software string) (*interfaces.SoftwareStatus, error) {
+	saidata, err := am.ResolveSoftwareData(software)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve saidata for %s: %w", software, err)
+	}
+
+	status := &interfaces.SoftwareStatus{Software: software}
+	if marker, err := am.markerStore.Get(software); err == nil {
+		status.Provider = marker.Provider
+	}
+
+	// Providers failing to report a version are shown as not having it installed
+	versions, _ := am.GetSoftwareVersions(software)
+	resources := statusResources(saidata, status.Provider)
+	am.inspectResources(ctx, resources)
+
+	status.Resources = append(packageStatuses(software, versions), resourceStatuses(resources)...)
+	return status, nil
+}
+
+// statusResources returns a copy of the resources of saidata whose runtime flags can be
+// set, the ones of the provider configuration replacing the general ones
+func statusResources(saidata *types.SoftwareData, provider string) *types.SoftwareData {
+	resources := &types.SoftwareData{
+		Services:    append([]types.Service(nil), saidata.Services...),
+		Ports:       append([]types.Port(nil), saidata.Ports...),
+		Containers:  append([]types.Container(nil), saidata.Containers...),
+		Files:       append([]types.File(nil), saidata.Files...),
+		Directories: append([]types.Directory(nil), saidata.Directories...),
+		Commands:    append([]types.Command(nil), saidata.Commands...),
+	}
+	config, exists := saidata.Providers[provider]
+	if !exists {
+		return resources
+	}
+	if len(config.Services) > 0 {
+		resources.Services = append([]types.Service(nil), config.Services...)
+	}
+	if len(config.Ports) > 0 {
+		resources.Ports = append([]types.Port(nil), config.Ports...)
+	}
+	if len(config.Containers) > 0 {
+		resources.Containers = append([]types.Container(nil), config.Containers...)
+	}
+	if len(config.Files) > 0 {
+		resources.Files = append([]types.File(nil), config.Files...)
+	}
+	if len(config.Directories) > 0 {
+		resources.Directories = append([]types.Directory(nil), config.Directories...)
+	}
+	if len(config.Commands) > 0 {
+		resources.Commands = append([]types.Command(nil), config.Commands...)
+	}
+	return resources
+}
+
+// inspectResources sets the runtime validation flags of the resources of saidata from
+// the state of the system
+func (am *ActionManager) inspectResources(ctx context.Context, saidata *types.SoftwareData) {
+	for i := range saidata.Files {
+		saidata.Files[i].Exists = am.validator.ValidateFile(saidata.Files[i])
+	}
+	for i := range saidata.Directories {
+		saidata.Directories[i].Exists = am.validator.ValidateDirectory(saidata.Directories[i])
+	}
+	for i := range saidata.Commands {
+		saidata.Commands[i].Exists = am.validator.ValidateCommand(saidata.Commands[i])
+	}
+	for i := range saidata.Services {
+		service := &saidata.Services[i]
+		name := service.GetServiceNameOrDefault()
+		service.IsActive = am.serviceQuery(ctx, servicemgr.IsActive, name) == "active"
+		service.IsEnabled = am.serviceQuery(ctx, servicemgr.IsEnabled, name) == "enabled"
+		service.Exists = service.IsActive || service.IsEnabled || am.validator.ValidateService(*service)
+	}
+	for i := range saidata.Ports {
+		saidata.Ports[i].IsOpen = portListening(saidata.Ports[i])
+	}
+	for i := range saidata.Containers {
+		container := &saidata.Containers[i]
+		container.Exists, container.IsRunning = am.containerState(ctx, container.Name)
+	}
+}
+
+// serviceQuery runs the is-active or is-enabled action of the service manager of a
+// service and returns its output, "" when it cannot be queried
+func (am *ActionManager) serviceQuery(ctx context.Context, action, service string) string {
+	command, err := servicemgr.ForService(service).Command(action, service)
+	if err != nil {
+		return ""
+	}
+	result, _ := am.executor.ExecuteCommand(ctx, command, interfaces.CommandOptions{
+		Timeout:  statusCommandTimeout,
+		ReadOnly: true,
+	})
+	if result == nil {
+		return ""
+	}
+	return strings.TrimSpace(result.Output)
+}
+
+// containerState reports whether a container of the given name exists and is running
+func (am *ActionManager) containerState(ctx context.Context, name string) (bool, bool) {
+	runtime := containerRuntime()
+	if _, err := exec.LookPath(runtime); err != nil {
+		return false, false
+	}
+	result, err := am.executor.ExecuteCommand(ctx, fmt.Sprintf("%s container inspect --format {{.State.Running}} %s", runtime, name), interfaces.CommandOptions{
+		Timeout:  statusCommandTimeout,
+		ReadOnly: true,
+	})
+	if err != nil || result.ExitCode != 0 {
+		return false, false
+	}
+	return true, strings.TrimSpace(result.Output) == "true"
+}
+
+// portListening reports whether a TCP port accepts connections on the local host.
+// UDP ports are connectionless and never reported listening.
+func portListening(port types.Port) bool {
+	if port.GetProtocolOrDefault() != "tcp" {
+		return false
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", fmt.Sprint(port.Port)), portDialTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// packageStatuses returns the packages of a software installed by the providers, or a
+// single not installed package when no provider has it installed
+func packageStatuses(software string, versions []*interfaces.VersionInfo) []interfaces.ResourceStatus {
+	var statuses []interfaces.ResourceStatus
+	for _, version := range versions {
+		if !version.IsInstalled {
+			continue
+		}
+		name := version.PackageName
+		if name == "" {
+			name = software
+		}
+		statuses = append(statuses, interfaces.ResourceStatus{
+			Type:   "package",
+			Name:   name,
+			State:  "installed",
+			Detail: strings.TrimSpace(version.Provider + " " + version.Version),
+			OK:     true,
+		})
+	}
+	if len(statuses) == 0 {
+		statuses = append(statuses, interfaces.ResourceStatus{Type: "package", Name: software, State: "not installed"})
+	}
+	return statuses
+}
+
+// resourceStatuses returns the state of the resources of saidata from their runtime
+// validation flags
+func resourceStatuses(saidata *types.SoftwareData) []interfaces.ResourceStatus {
+	var statuses []interfaces.ResourceStatus
+	for _, service := range saidata.Services {
+		status := interfaces.ResourceStatus{Type: "service", Name: service.GetServiceNameOrDefault(), State: "not found"}
+		if service.Exists {
+			status.State = "inactive"
+			if service.IsActive {
+				status.State, status.OK = "active", true
+			}
+			status.Detail = "disabled"
+			if service.IsEnabled {
+				status.Detail = "enabled"
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	for _, port := range saidata.Ports {
+		protocol := port.GetProtocolOrDefault()
+		status := interfaces.ResourceStatus{Type: "port", Name: fmt.Sprintf("%d/%s", port.Port, protocol), State: "closed", Detail: port.Service}
+		switch {
+		case port.IsOpen:
+			status.State, status.OK = "listening", true
+		case protocol != "tcp":
+			status.State, status.OK = "unknown", true
+		}
+		statuses = append(statuses, status)
+	}
+	for _, container := range saidata.Containers {
+		status := interfaces.ResourceStatus{Type: "container", Name: container.Name, State: "not found", Detail: container.GetImageReference()}
+		if container.Exists {
+			status.State = "stopped"
+			if container.IsRunning {
+				status.State, status.OK = "running", true
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	for _, file := range saidata.Files {
+		statuses = append(statuses, presence("file", file.Name, file.Path, file.Exists))
+	}
+	for _, directory := range saidata.Directories {
+		statuses = append(statuses, presence("directory", directory.Name, directory.Path, directory.Exists))
+	}
+	for _, command := range saidata.Commands {
+		statuses = append(statuses, presence("command", command.Name, command.GetPathOrDefault(), command.Exists))
+	}
+	return statuses
+}
+
+// presence returns the status of a resource that is either present or missing
+func presence(kind, name, path string, exists bool) interfaces.ResourceStatus {
+	status := interfaces.ResourceStatus{Type: kind, Name: name, State: "missing", Detail: path}
+	if exists {
+		status.State, status.OK = "present", true
+	}
+	return status
+}
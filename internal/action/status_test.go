@@ -0,0 +1,77 @@
+package action
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+func TestStatusResources(t *testing.T) {
+	saidata := &types.SoftwareData{
+		Services: []types.Service{{Name: "nginx"}},
+		Files:    []types.File{{Name: "config", Path: "/etc/nginx/nginx.conf"}},
+		Providers: map[string]types.ProviderConfig{
+			"brew": {Files: []types.File{{Name: "config", Path: "/opt/homebrew/etc/nginx/nginx.conf"}}},
+		},
+	}
+
+	resources := statusResources(saidata, "brew")
+	assert.Equal(t, "/opt/homebrew/etc/nginx/nginx.conf", resources.Files[0].Path)
+	assert.Equal(t, "nginx", resources.Services[0].Name)
+
+	resources.Services[0].IsActive = true
+	assert.False(t, saidata.Services[0].IsActive, "flags are set on a copy of saidata")
+}
+
+func TestResourceStatuses(t *testing.T) {
+	saidata := &types.SoftwareData{
+		Services:   []types.Service{{Name: "nginx", Exists: true, IsActive: true, IsEnabled: true}, {Name: "nginx-debug", Exists: true}},
+		Ports:      []types.Port{{Port: 80, Service: "http", IsOpen: true}, {Port: 443}, {Port: 53, Protocol: "udp"}},
+		Containers: []types.Container{{Name: "nginx", Image: "nginx", Tag: "1.25", Exists: true}},
+		Files:      []types.File{{Name: "config", Path: "/etc/nginx/nginx.conf", Exists: true}},
+		Commands:   []types.Command{{Name: "nginx", Path: "/usr/sbin/nginx"}},
+	}
+
+	assert.Equal(t, []interfaces.ResourceStatus{
+		{Type: "service", Name: "nginx", State: "active", Detail: "enabled", OK: true},
+		{Type: "service", Name: "nginx-debug", State: "inactive", Detail: "disabled"},
+		{Type: "port", Name: "80/tcp", State: "listening", Detail: "http", OK: true},
+		{Type: "port", Name: "443/tcp", State: "closed"},
+		{Type: "port", Name: "53/udp", State: "unknown", OK: true},
+		{Type: "container", Name: "nginx", State: "stopped", Detail: "docker.io/library/nginx:1.25"},
+		{Type: "file", Name: "config", State: "present", Detail: "/etc/nginx/nginx.conf", OK: true},
+		{Type: "command", Name: "nginx", State: "missing", Detail: "/usr/sbin/nginx"},
+	}, resourceStatuses(saidata))
+}
+
+func TestPackageStatuses(t *testing.T) {
+	statuses := packageStatuses("nginx", []*interfaces.VersionInfo{
+		{Provider: "apt", PackageName: "nginx-full", Version: "1.24.0", IsInstalled: true},
+		{Provider: "snap", Version: "Not Installed"},
+	})
+	assert.Equal(t, []interfaces.ResourceStatus{
+		{Type: "package", Name: "nginx-full", State: "installed", Detail: "apt 1.24.0", OK: true},
+	}, statuses)
+
+	statuses = packageStatuses("nginx", nil)
+	assert.Equal(t, []interfaces.ResourceStatus{{Type: "package", Name: "nginx", State: "not installed"}}, statuses)
+
+	status := &interfaces.SoftwareStatus{Resources: statuses}
+	assert.False(t, status.Healthy())
+}
+
+func TestPortListening(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Skip("cannot listen on localhost")
+	}
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	assert.True(t, portListening(types.Port{Port: port}))
+	assert.False(t, portListening(types.Port{Port: port, Protocol: "udp"}))
+}
@@ -0,0 +1,202 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/transaction"
+	"sai/internal/types"
+)
+
+// inverseActions are the actions undoing the change made by a successful action
+var inverseActions = map[string]string{
+	"install":   "uninstall",
+	"uninstall": "install",
+	"start":     "stop",
+	"stop":      "start",
+	"enable":    "disable",
+	"disable":   "enable",
+}
+
+// changeTypes are the types of resource changed by actions
+var changeTypes = map[string]string{
+	"install":   "package",
+	"uninstall": "package",
+	"upgrade":   "package",
+	"start":     "service",
+	"stop":      "service",
+	"restart":   "service",
+	"enable":    "service",
+	"disable":   "service",
+}
+
+// beginTransaction opens a journal transaction for a system-changing action. Journal
+// failures never fail the action, nil is returned instead.
+func (am *ActionManager) beginTransaction(action, software, provider string, options interfaces.ActionOptions) *transaction.Transaction {
+	if options.DryRun || am.config.IsInformationOnlyAction(action) {
+		return nil
+	}
+
+	tx, err := am.journal.Begin(action, software, provider)
+	if err != nil {
+		am.formatter.ShowWarning(fmt.Sprintf("Changes will not be journaled: %v", err))
+		return nil
+	}
+	return tx
+}
+
+// finishTransaction journals the changes made by an action. The changes of a failed
+// action are rolled back when automatic rollback is enabled.
+func (am *ActionManager) finishTransaction(ctx context.Context, tx *transaction.Transaction, result *interfaces.ActionResult, provider *types.ProviderData, saidata *types.SoftwareData, options interfaces.ActionOptions) {
+	if tx == nil {
+		return
+	}
+	result.TransactionID = tx.ID
+
+	changes := journalChanges(result.Changes)
+	if result.Success {
+		changes = append(changes, am.actionChange(result.Action, result.Software, provider, saidata))
+	}
+
+	if err := am.journal.Finish(tx, changes, result.Error); err != nil {
+		am.formatter.ShowWarning(fmt.Sprintf("Failed to journal transaction %s: %v", tx.ID, err))
+		return
+	}
+
+	if result.Success || !am.config.Transactions.AutoRollback || len(tx.RollbackPlan()) == 0 {
+		return
+	}
+	am.formatter.ShowWarning(fmt.Sprintf("Rolling back the changes of transaction %s...", tx.ID))
+	if _, err := am.rollbackTransaction(ctx, tx, options); err != nil {
+		am.formatter.ShowWarning(fmt.Sprintf("Rollback of transaction %s failed: %v", tx.ID, err))
+	}
+}
+
+// Rollback undoes the changes journaled by a transaction, the most recent change
+// first. A dry run returns the transaction without undoing anything.
+func (am *ActionManager) Rollback(ctx context.Context, id string, options interfaces.ActionOptions) (*transaction.Transaction, error) {
+	tx, err := am.journal.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Status == transaction.StatusRolledBack {
+		return tx, fmt.Errorf("transaction %s was already rolled back on %s", id, tx.RolledBackAt.Format(time.RFC3339))
+	}
+	if len(tx.RollbackPlan()) == 0 {
+		return tx, fmt.Errorf("transaction %s has no reversible changes", id)
+	}
+	if options.DryRun {
+		return tx, nil
+	}
+	return am.rollbackTransaction(ctx, tx, options)
+}
+
+// GetTransactions returns the journaled transactions, the most recent first
+func (am *ActionManager) GetTransactions() ([]*transaction.Transaction, error) {
+	return am.journal.List()
+}
+
+// rollbackTransaction runs the rollback plan of a transaction. Every change is
+// attempted even when an earlier one fails; the transaction is only marked as rolled
+// back when all changes were undone.
+func (am *ActionManager) rollbackTransaction(ctx context.Context, tx *transaction.Transaction, options interfaces.ActionOptions) (*transaction.Transaction, error) {
+	shell := ""
+	if provider, err := am.providerManager.GetProvider(tx.Provider); err == nil {
+		shell = provider.Provider.GetShell()
+	}
+
+	var failures []string
+	for _, change := range tx.RollbackPlan() {
+		if err := am.undoChange(ctx, tx, change, shell, options); err != nil {
+			failures = append(failures, fmt.Sprintf("%s %s: %v", change.Type, change.Resource, err))
+		}
+	}
+	if len(failures) > 0 {
+		return tx, fmt.Errorf("failed to undo %d change(s): %s", len(failures), strings.Join(failures, "; "))
+	}
+
+	tx.Status = transaction.StatusRolledBack
+	tx.RolledBackAt = time.Now()
+	if err := am.journal.Save(tx); err != nil {
+		return tx, err
+	}
+	return tx, nil
+}
+
+// undoChange undoes a single change, with its rollback command or inverse action
+func (am *ActionManager) undoChange(ctx context.Context, tx *transaction.Transaction, change transaction.Change, shell string, options interfaces.ActionOptions) error {
+	if change.RollbackCmd != "" {
+		result, err := am.executor.ExecuteCommand(ctx, change.RollbackCmd, interfaces.CommandOptions{
+			Timeout: options.Timeout,
+			Verbose: options.Verbose,
+			Shell:   shell,
+		})
+		if err == nil && result != nil && result.ExitCode != 0 {
+			err = fmt.Errorf("command exited with code %d", result.ExitCode)
+		}
+		return err
+	}
+
+	// Rolling back is confirmed once for the whole transaction, and undoes what the
+	// action did even when it ran on generated saidata
+	inverseOptions := options
+	inverseOptions.Provider = tx.Provider
+	inverseOptions.Yes = true
+	inverseOptions.AllowGenerated = true
+	result, err := am.ExecuteAction(ctx, change.InverseAction, tx.Software, inverseOptions)
+	if err == nil && !result.Success {
+		err = result.Error
+	}
+	return err
+}
+
+// actionChange describes the change made by a successful action, undone by its
+// inverse action when it has one
+func (am *ActionManager) actionChange(action, software string, provider *types.ProviderData, saidata *types.SoftwareData) transaction.Change {
+	change := transaction.Change{
+		Type:          changeTypes[action],
+		Resource:      software,
+		Action:        action,
+		InverseAction: inverseActions[action],
+	}
+	if change.Type == "" {
+		change.Type = "action"
+	}
+	if saidata == nil {
+		return change
+	}
+
+	var resources []string
+	switch change.Type {
+	case "package":
+		resources = am.getPackageNames(provider, saidata)
+	case "service":
+		for i := range saidata.Services {
+			resources = append(resources, saidata.Services[i].GetServiceNameOrDefault())
+		}
+	}
+	if len(resources) > 0 {
+		change.Resource = strings.Join(resources, " ")
+	}
+	return change
+}
+
+// journalChanges converts the changes reported by the executor to journal changes
+func journalChanges(changes []interfaces.Change) []transaction.Change {
+	var result []transaction.Change
+	for _, change := range changes {
+		journaled := transaction.Change{
+			Type:     change.Type,
+			Resource: change.Resource,
+			Action:   change.Action,
+		}
+		if change.Reversible {
+			journaled.RollbackCmd = change.RollbackCmd
+		}
+		result = append(result, journaled)
+	}
+	return result
+}
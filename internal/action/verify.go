@@ -0,0 +1,349 @@
+package action
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/managed"
+	"sai/internal/types"
+)
+
+// verifyAction is the provider action checking the installed files of a package
+// against the package database, such as dpkg -V or rpm -V
+const verifyAction = "verify"
+
+// originAction and signatureAction are the provider actions printing the repository
+// and signature of the installed packages, checked by sai verify
+const (
+	originAction    = "origin"
+	signatureAction = "signature"
+)
+
+// verifyTimeout bounds the verify, origin and signature actions of a provider
+const verifyTimeout = 5 * time.Minute
+
+// volatilePrefixes are locations whose content changes in normal operation, such as
+// logs and data, and are not recorded in install manifests
+var volatilePrefixes = []string{"/var/", "/tmp/", "/run/"}
+
+// verifyAttributes names the attributes of the flags column of rpm -V and dpkg -V
+// output, by position
+var verifyAttributes = []string{"size", "mode", "checksum", "device", "link", "owner", "group", "mtime", "capabilities"}
+
+// VerifySoftware checks the installed files of a software installed by sai. Software
+// installed by a provider with a verify action, such as apt or dnf, is checked against
+// the package database; other installs, such as binaries, scripts and source builds,
+// against the file manifest recorded when they were installed.
+func (am *ActionManager) VerifySoftware(ctx context.Context, software string) (*interfaces.VerifyResult, error) {
+	marker, err := am.markerStore.Get(software)
+	if err != nil {
+		return nil, fmt.Errorf("%s was not installed by sai, nothing was recorded to verify it against", software)
+	}
+
+	result := &interfaces.VerifyResult{
+		Software: software,
+		Provider: marker.Provider,
+	}
+
+	provider, err := am.providerManager.GetProvider(marker.Provider)
+	if err != nil {
+		provider = nil
+	}
+	if hasProviderAction(provider, verifyAction) {
+		result.Method = interfaces.VerifyMethodProvider
+		if err := am.verifyWithProvider(ctx, provider, software, result); err != nil {
+			return result, err
+		}
+	} else {
+		if len(marker.Files) == 0 {
+			return nil, fmt.Errorf("no files were recorded when %s was installed with %s, reinstall it to record them", software, marker.Provider)
+		}
+		result.Method = interfaces.VerifyMethodManifest
+		result.FileVerification = *managed.VerifyFiles(marker.Files, marker.Directories)
+	}
+
+	result.Checks = am.runVerifyChecks(ctx, marker.Provider, provider, software)
+	return result, nil
+}
+
+// runVerifyChecks checks the binaries of a software against the package checksums of
+// its saidata, and the origin and signature of its packages with the origin and
+// signature actions of its provider, when it has them
+func (am *ActionManager) runVerifyChecks(ctx context.Context, providerName string, provider *types.ProviderData, software string) []interfaces.VerifyCheck {
+	checks := []interfaces.VerifyCheck{}
+	saidata, err := am.ResolveSoftwareData(software)
+	if err != nil {
+		return checks
+	}
+
+	checks = append(checks, checksumChecks(saidata)...)
+
+	// Packages are only expected to come from the repositories saidata declares
+	repositories := declaredRepositories(providerName, saidata)
+	if hasProviderAction(provider, originAction) && len(repositories) > 0 {
+		if output, err := am.runVerifyAction(ctx, provider, originAction, software, saidata); err != nil {
+			checks = append(checks, interfaces.VerifyCheck{Check: interfaces.VerifyCheckOrigin, Subject: software, Status: interfaces.VerifyStatusUnknown, Detail: err.Error()})
+		} else {
+			checks = append(checks, originChecks(software, output, repositories)...)
+		}
+	}
+
+	if hasProviderAction(provider, signatureAction) {
+		if output, err := am.runVerifyAction(ctx, provider, signatureAction, software, saidata); err != nil {
+			checks = append(checks, interfaces.VerifyCheck{Check: interfaces.VerifyCheckSignature, Subject: software, Status: interfaces.VerifyStatusUnknown, Detail: err.Error()})
+		} else {
+			checks = append(checks, signatureChecks(output)...)
+		}
+	}
+	return checks
+}
+
+// hasProviderAction reports whether a provider, nil when it is not loaded, has an action
+func hasProviderAction(provider *types.ProviderData, action string) bool {
+	if provider == nil {
+		return false
+	}
+	_, exists := provider.Actions[action]
+	return exists
+}
+
+// runVerifyAction runs the origin or signature action of a provider and returns its output
+func (am *ActionManager) runVerifyAction(ctx context.Context, provider *types.ProviderData, action, software string, saidata *types.SoftwareData) (string, error) {
+	executionResult, err := am.executor.Execute(ctx, provider, action, software, saidata, interfaces.ExecuteOptions{Timeout: verifyTimeout})
+	if executionResult == nil || !executionResult.Success {
+		if err == nil && executionResult != nil {
+			err = executionResult.Error
+		}
+		return "", fmt.Errorf("%s %s failed: %v", provider.Provider.Name, action, err)
+	}
+	return executionResult.Output, nil
+}
+
+// checksumChecks compares the binaries of a software found on the system with the
+// checksums its saidata declares for them
+func checksumChecks(saidata *types.SoftwareData) []interfaces.VerifyCheck {
+	var checks []interfaces.VerifyCheck
+	for _, binary := range declaredBinaries(saidata) {
+		if binary.checksum == "" {
+			continue
+		}
+		if _, err := os.Stat(binary.path); err != nil {
+			continue
+		}
+		check := interfaces.VerifyCheck{Check: interfaces.VerifyCheckChecksum, Subject: binary.path, Status: interfaces.VerifyStatusOK}
+		if err := verifyBinaryChecksum(binary); err != nil {
+			check.Status, check.Detail = interfaces.VerifyStatusDrift, err.Error()
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// declaredRepositories returns the repositories saidata declares for a provider
+func declaredRepositories(providerName string, saidata *types.SoftwareData) []types.Repository {
+	if config := saidata.GetProviderConfig(providerName); config != nil {
+		return config.Repositories
+	}
+	return nil
+}
+
+// originChecks compares the repositories the origin action of a provider printed, one
+// per line, with the repositories saidata declares, by URL or name
+func originChecks(software, output string, repositories []types.Repository) []interfaces.VerifyCheck {
+	var origins []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if origin := strings.TrimSpace(line); origin != "" && !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		return []interfaces.VerifyCheck{{Check: interfaces.VerifyCheckOrigin, Subject: software, Status: interfaces.VerifyStatusUnknown, Detail: "no repository reported"}}
+	}
+
+	names := make([]string, len(repositories))
+	for i, repository := range repositories {
+		names[i] = repository.Name
+	}
+	var checks []interfaces.VerifyCheck
+	for _, origin := range origins {
+		check := interfaces.VerifyCheck{Check: interfaces.VerifyCheckOrigin, Subject: software, Status: interfaces.VerifyStatusDrift,
+			Detail: fmt.Sprintf("installed from %s, not from %s", origin, strings.Join(names, ", "))}
+		for _, repository := range repositories {
+			url := strings.TrimSuffix(repository.URL, "/")
+			if origin == repository.Name || (url != "" && strings.Contains(origin, url)) {
+				check.Status, check.Detail = interfaces.VerifyStatusOK, fmt.Sprintf("installed from %s", repository.Name)
+				break
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// signatureChecks parses the output of the signature action of a provider, a line per
+// package with its name followed by its signature, "(none)" for unsigned packages:
+//
+//	nginx RSA/SHA256, Tue 14 Nov 2023 10:15:22 AM UTC, Key ID abf5bd827bd9bf62
+//	nginx-module-njs (none)
+func signatureChecks(output string) []interfaces.VerifyCheck {
+	var checks []interfaces.VerifyCheck
+	for _, line := range strings.Split(output, "\n") {
+		name, signature, found := strings.Cut(strings.TrimSpace(line), " ")
+		if !found {
+			continue
+		}
+		signature = strings.TrimSpace(signature)
+		check := interfaces.VerifyCheck{Check: interfaces.VerifyCheckSignature, Subject: name, Status: interfaces.VerifyStatusOK, Detail: signature}
+		if signature == "" || strings.Contains(signature, "(none)") {
+			check.Status, check.Detail = interfaces.VerifyStatusDrift, "not signed"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// verifyWithProvider runs the verify action of a provider and parses its output
+func (am *ActionManager) verifyWithProvider(ctx context.Context, provider *types.ProviderData, software string, result *interfaces.VerifyResult) error {
+	saidata, err := am.ResolveSoftwareData(software)
+	if err != nil {
+		return fmt.Errorf("failed to resolve saidata for %s: %w", software, err)
+	}
+
+	executeOptions := interfaces.ExecuteOptions{
+		DryRun:  false,
+		Verbose: false,
+		Timeout: verifyTimeout,
+	}
+	executionResult, err := am.executor.Execute(ctx, provider, verifyAction, software, saidata, executeOptions)
+	if executionResult == nil {
+		return fmt.Errorf("failed to verify %s with %s: %w", software, provider.Provider.Name, err)
+	}
+
+	// rpm -V exits with 1 when files differ, only fail when nothing could be parsed
+	verification := parseVerifyOutput(executionResult.Output)
+	if !executionResult.Success && len(verification.Modified) == 0 && len(verification.Missing) == 0 {
+		if err == nil {
+			err = executionResult.Error
+		}
+		return fmt.Errorf("failed to verify %s with %s: %w", software, provider.Provider.Name, err)
+	}
+	result.FileVerification = *verification
+	return nil
+}
+
+// parseVerifyOutput parses the output of rpm -V and dpkg -V, which print a line per
+// file that differs from the package database:
+//
+//	S.5....T.  c /etc/nginx/nginx.conf
+//	missing     /usr/sbin/nginx
+//
+// Other lines, such as warnings, are ignored.
+func parseVerifyOutput(output string) *managed.FileVerification {
+	verification := &managed.FileVerification{
+		Modified: []managed.FileChange{},
+		Missing:  []string{},
+		Extra:    []string{},
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		path := fields[len(fields)-1]
+		if !filepath.IsAbs(path) {
+			continue
+		}
+
+		flags := fields[0]
+		if flags == "missing" {
+			verification.Missing = append(verification.Missing, path)
+			continue
+		}
+		if len(flags) < 8 || strings.Trim(flags, "SM5DLUGTP.?") != "" {
+			continue
+		}
+		var changes []string
+		for i, flag := range flags {
+			if flag != '.' && flag != '?' && i < len(verifyAttributes) {
+				changes = append(changes, verifyAttributes[i])
+			}
+		}
+		if len(changes) > 0 {
+			verification.Modified = append(verification.Modified, managed.FileChange{Path: path, Changes: changes})
+		}
+	}
+	return verification
+}
+
+// installedFiles returns the files and directories of a software recorded in its
+// marker when its provider cannot verify them: its commands and files, except logs,
+// and its directories outside of the locations changing in normal operation
+func installedFiles(provider *types.ProviderData, saidata *types.SoftwareData) ([]string, []string) {
+	if saidata == nil {
+		return nil, nil
+	}
+	if _, exists := provider.Actions[verifyAction]; exists {
+		return nil, nil
+	}
+
+	commands, files, directories := saidata.Commands, saidata.Files, saidata.Directories
+	if config := saidata.GetProviderConfig(provider.Provider.Name); config != nil {
+		if len(config.Commands) > 0 {
+			commands = config.Commands
+		}
+		if len(config.Files) > 0 {
+			files = config.Files
+		}
+		if len(config.Directories) > 0 {
+			directories = config.Directories
+		}
+	}
+
+	var paths, dirs []string
+	for _, command := range commands {
+		paths = append(paths, command.GetPathOrDefault())
+	}
+	for _, file := range files {
+		if file.Type != "log" && !isVolatilePath(file.Path) {
+			paths = append(paths, file.Path)
+		}
+	}
+	for _, directory := range directories {
+		if directory.Path != "" && !isVolatilePath(directory.Path) {
+			dirs = append(dirs, directory.Path)
+		}
+	}
+	return paths, dirs
+}
+
+// existingDirectories returns the directories that exist, the ones whose extra files
+// sai verify reports
+func existingDirectories(directories []string) []string {
+	var existing []string
+	for _, dir := range directories {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			existing = append(existing, filepath.Clean(dir))
+		}
+	}
+	return existing
+}
+
+// isVolatilePath reports whether a path is under a location changing in normal operation
+func isVolatilePath(path string) bool {
+	for _, prefix := range volatilePrefixes {
+		if strings.HasPrefix(filepath.Clean(path)+"/", prefix) {
+			return true
+		}
+	}
+	return false
+}
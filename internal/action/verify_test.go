@@ -0,0 +1,108 @@
+package action
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/interfaces"
+	"sai/internal/managed"
+	"sai/internal/types"
+)
+
+func TestParseVerifyOutput(t *testing.T) {
+	output := `S.5....T.  c /etc/nginx/nginx.conf
+??5??????   /usr/sbin/nginx
+missing     /usr/share/nginx/html/index.html
+missing   c /etc/nginx/mime.types
+dpkg: warning: nginx-common: unable to open /var/lib/dpkg/info/nginx-common.md5sums
+.M.......   /usr/lib/nginx/modules
+`
+	verification := parseVerifyOutput(output)
+
+	assert.Equal(t, []managed.FileChange{
+		{Path: "/etc/nginx/nginx.conf", Changes: []string{"size", "checksum", "mtime"}},
+		{Path: "/usr/sbin/nginx", Changes: []string{"checksum"}},
+		{Path: "/usr/lib/nginx/modules", Changes: []string{"mode"}},
+	}, verification.Modified)
+	assert.Equal(t, []string{"/usr/share/nginx/html/index.html", "/etc/nginx/mime.types"}, verification.Missing)
+	assert.Empty(t, verification.Extra)
+}
+
+func TestInstalledFiles(t *testing.T) {
+	saidata := &types.SoftwareData{
+		Commands:    []types.Command{{Name: "tool"}},
+		Files:       []types.File{{Path: "/etc/tool.conf"}, {Path: "/opt/tool/tool.log", Type: "log"}, {Path: "/var/lib/tool/state"}},
+		Directories: []types.Directory{{Path: "/opt/tool"}, {Path: "/var/log/tool"}},
+	}
+
+	binary := &types.ProviderData{Provider: types.ProviderInfo{Name: "binary"}, Actions: map[string]types.Action{"install": {}}}
+	paths, directories := installedFiles(binary, saidata)
+	assert.Equal(t, []string{"/usr/bin/tool", "/etc/tool.conf"}, paths)
+	assert.Equal(t, []string{"/opt/tool"}, directories)
+
+	apt := &types.ProviderData{Provider: types.ProviderInfo{Name: "apt"}, Actions: map[string]types.Action{verifyAction: {}}}
+	paths, directories = installedFiles(apt, saidata)
+	assert.Empty(t, paths, "providers with a verify action check their own files")
+	assert.Empty(t, directories)
+}
+
+func TestOriginChecks(t *testing.T) {
+	repositories := []types.Repository{
+		{Name: "nginx-stable", URL: "http://nginx.org/packages/ubuntu/"},
+		{Name: "nginx-mainline"},
+	}
+
+	checks := originChecks("nginx", "http://nginx.org/packages/ubuntu\nnginx-mainline\nhttp://archive.ubuntu.com/ubuntu\n", repositories)
+	assert.Equal(t, []interfaces.VerifyCheck{
+		{Check: interfaces.VerifyCheckOrigin, Subject: "nginx", Status: interfaces.VerifyStatusOK, Detail: "installed from nginx-stable"},
+		{Check: interfaces.VerifyCheckOrigin, Subject: "nginx", Status: interfaces.VerifyStatusOK, Detail: "installed from nginx-mainline"},
+		{Check: interfaces.VerifyCheckOrigin, Subject: "nginx", Status: interfaces.VerifyStatusDrift,
+			Detail: "installed from http://archive.ubuntu.com/ubuntu, not from nginx-stable, nginx-mainline"},
+	}, checks)
+
+	checks = originChecks("nginx", "\n", repositories)
+	assert.Equal(t, interfaces.VerifyStatusUnknown, checks[0].Status)
+}
+
+func TestSignatureChecks(t *testing.T) {
+	checks := signatureChecks("nginx RSA/SHA256, Tue 14 Nov 2023 10:15:22 AM UTC, Key ID abf5bd827bd9bf62\nnginx-module-njs (none)\n")
+	assert.Equal(t, []interfaces.VerifyCheck{
+		{Check: interfaces.VerifyCheckSignature, Subject: "nginx", Status: interfaces.VerifyStatusOK, Detail: "RSA/SHA256, Tue 14 Nov 2023 10:15:22 AM UTC, Key ID abf5bd827bd9bf62"},
+		{Check: interfaces.VerifyCheckSignature, Subject: "nginx-module-njs", Status: interfaces.VerifyStatusDrift, Detail: "not signed"},
+	}, checks)
+
+	result := &interfaces.VerifyResult{Checks: checks}
+	assert.True(t, result.FilesIntact())
+	assert.False(t, result.Intact())
+}
+
+func TestChecksumChecks(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "tool")
+	require.NoError(t, os.WriteFile(binary, []byte("tool"), 0755))
+	digest := sha256.Sum256([]byte("tool"))
+
+	saidata := &types.SoftwareData{
+		Commands: []types.Command{
+			{Name: "tool", Path: binary, Checksum: "sha256:" + hex.EncodeToString(digest[:])},
+			{Name: "absent", Path: filepath.Join(dir, "absent"), Checksum: "sha256:" + hex.EncodeToString(digest[:])},
+		},
+		// The checksum of the archive the binary comes from does not apply to it
+		Packages: []types.Package{{Name: "tool", Checksum: "sha256:" + hex.EncodeToString(digest[:])}},
+	}
+	assert.Equal(t, []interfaces.VerifyCheck{
+		{Check: interfaces.VerifyCheckChecksum, Subject: binary, Status: interfaces.VerifyStatusOK},
+	}, checksumChecks(saidata))
+
+	require.NoError(t, os.WriteFile(binary, []byte("tampered"), 0755))
+	checks := checksumChecks(saidata)
+	assert.Equal(t, interfaces.VerifyStatusDrift, checks[0].Status)
+
+	saidata.Commands[0].Checksum = ""
+	assert.Empty(t, checksumChecks(saidata))
+}
@@ -0,0 +1,57 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/types"
+	"sai/internal/version"
+)
+
+// versionCheckTimeout bounds the version action run to check an installed version
+const versionCheckTimeout = 30 * time.Second
+
+// checkVersionAction refuses to install or upgrade to a version requested with
+// software@version with a provider without a version action, which could not check
+// the installed version afterwards
+func checkVersionAction(action string, provider *types.ProviderData, spec string) error {
+	if spec == "" || (action != "install" && action != "upgrade") {
+		return nil
+	}
+	if _, ok := provider.Actions["version"]; !ok {
+		return fmt.Errorf("cannot install version %s with provider %s: it has no version action to check the installed version", spec, provider.Provider.Name)
+	}
+	return nil
+}
+
+// checkInstalledVersion checks the version installed by a provider against the version
+// constraint requested with software@version, with the version action of the provider.
+// An installed version that cannot be checked fails like one that does not match.
+func (am *ActionManager) checkInstalledVersion(ctx context.Context, software string, provider *types.ProviderData, saidata *types.SoftwareData, spec string) error {
+	constraint, err := version.Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	if err := checkVersionAction("install", provider, spec); err != nil {
+		return err
+	}
+	executionResult, err := am.executor.Execute(ctx, provider, "version", software, saidata, interfaces.ExecuteOptions{
+		Timeout: versionCheckTimeout,
+	})
+	if err != nil || !executionResult.Success {
+		return fmt.Errorf("cannot check that %s %s was installed: the version action of %s failed", software, spec, provider.Provider.Name)
+	}
+	installed := am.parseVersionOutput(provider.Provider.Name, executionResult.Output)
+	if installed == "" {
+		return fmt.Errorf("cannot check that %s %s was installed: no version in the output of %s", software, spec, provider.Provider.Name)
+	}
+
+	if !constraint.Matches(installed) {
+		return fmt.Errorf("installed %s %s does not satisfy the requested version %s", software, installed, spec)
+	}
+	am.formatter.ShowDebug(fmt.Sprintf("Installed %s %s satisfies %s", software, installed, spec))
+	return nil
+}
@@ -0,0 +1,25 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sai/internal/types"
+)
+
+func TestCheckVersionAction(t *testing.T) {
+	withVersion := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "apt"},
+		Actions:  map[string]types.Action{"install": {}, "version": {}},
+	}
+	withoutVersion := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "script"},
+		Actions:  map[string]types.Action{"install": {}},
+	}
+
+	assert.NoError(t, checkVersionAction("install", withVersion, "1.24"))
+	assert.NoError(t, checkVersionAction("install", withoutVersion, ""))
+	assert.NoError(t, checkVersionAction("start", withoutVersion, "1.24"))
+	assert.Error(t, checkVersionAction("install", withoutVersion, "1.24"))
+	assert.Error(t, checkVersionAction("upgrade", withoutVersion, ">=1.20 <1.25"))
+}
@@ -0,0 +1,302 @@
+// Package artifacts tracks the temporary files and directories left by action runs,
+// such as extracted downloads and build directories, so they can be removed once a
+// run succeeds and kept for a while for debugging when it fails.
+package artifacts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sai/internal/fileutil"
+)
+
+// Run statuses
+const (
+	StatusRunning = "running"
+	StatusFailed  = "failed"
+)
+
+// OrphanPatterns match temporary files created by sai or its providers outside of a
+// tracked run, such as build directories and partial downloads
+var OrphanPatterns = []string{"sai-build-*", "sai-extract-*", "sai-script-*", "saidata-*.zip"}
+
+// orphanMinAge protects untracked temporary files of runs still in progress
+const orphanMinAge = time.Hour
+
+// Run records the temporary artifacts of one action run
+type Run struct {
+	ID       string    `json:"id"`
+	Action   string    `json:"action"`
+	Software string    `json:"software"`
+	Status   string    `json:"status"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished,omitempty"`
+	Paths    []string  `json:"paths"`
+}
+
+// Report lists what a cleanup removed, or would remove in a dry run
+type Report struct {
+	DryRun  bool
+	Removed []string
+	Bytes   int64
+}
+
+// Store persists run manifests in a directory and removes the artifacts they track.
+// Only paths inside the temporary directory are ever tracked.
+type Store struct {
+	dir     string
+	tempDir string
+	mutex   sync.Mutex
+}
+
+// NewStore creates a store keeping run manifests in dir for artifacts under tempDir
+func NewStore(dir, tempDir string) *Store {
+	return &Store{
+		dir:     dir,
+		tempDir: filepath.Clean(tempDir),
+	}
+}
+
+// Begin records the start of a run
+func (s *Store) Begin(action, software string) (*Run, error) {
+	now := time.Now()
+	run := &Run{
+		ID:       fmt.Sprintf("%s-%d", now.Format("20060102-150405.000000000"), os.Getpid()),
+		Action:   action,
+		Software: software,
+		Status:   StatusRunning,
+		Started:  now,
+	}
+	return run, s.save(run)
+}
+
+// Track adds paths to the artifacts of a run. Paths outside the temporary directory
+// are ignored so that cleanup never touches installed files.
+func (s *Store) Track(run *Run, paths ...string) error {
+	changed := false
+	for _, path := range paths {
+		if !s.isTemporary(path) || containsPath(run.Paths, path) {
+			continue
+		}
+		run.Paths = append(run.Paths, filepath.Clean(path))
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return s.save(run)
+}
+
+// Finish removes the artifacts of a successful run. The artifacts of a failed run are
+// kept for debugging until Prune removes them.
+func (s *Store) Finish(run *Run, success bool) (*Report, error) {
+	if success {
+		report := &Report{}
+		return report, s.remove(run, report)
+	}
+
+	run.Status = StatusFailed
+	run.Finished = time.Now()
+	return &Report{}, s.save(run)
+}
+
+// Prune removes the artifacts of failed runs that finished more than keepFailed ago,
+// of runs that never finished (sai was interrupted) and started more than keepFailed
+// ago, and untracked orphans of the same age in the temporary directory. A zero
+// keepFailed removes them all regardless of age. A dry run only reports what would
+// be removed.
+func (s *Store) Prune(keepFailed time.Duration, dryRun bool) (*Report, error) {
+	report := &Report{DryRun: dryRun}
+	now := time.Now()
+
+	runs, err := s.Runs()
+	if err != nil {
+		return report, err
+	}
+
+	var errs []string
+	for _, run := range runs {
+		since := run.Finished
+		if run.Status != StatusFailed {
+			since = run.Started
+			// Runs still in progress are never pruned by a full cleanup
+			if keepFailed == 0 && now.Sub(since) < orphanMinAge {
+				continue
+			}
+		}
+		if now.Sub(since) < keepFailed {
+			continue
+		}
+
+		if err := s.remove(run, report); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	orphanAge := keepFailed
+	if orphanAge < orphanMinAge {
+		orphanAge = orphanMinAge
+	}
+	if err := s.removeOrphans(now.Add(-orphanAge), report); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return report, fmt.Errorf("cleanup incomplete: %s", strings.Join(errs, "; "))
+	}
+	return report, nil
+}
+
+// Runs returns the recorded runs whose artifacts were not removed, oldest first
+func (s *Store) Runs() ([]*Run, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*Run
+	for _, path := range paths {
+		var run Run
+		if err := fileutil.ReadJSON(path, &run); err != nil {
+			continue
+		}
+		runs = append(runs, &run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Started.Before(runs[j].Started)
+	})
+	return runs, nil
+}
+
+// remove deletes the artifacts of a run and its manifest
+func (s *Store) remove(run *Run, report *Report) error {
+	var errs []string
+
+	for _, path := range run.Paths {
+		// Manifests may have been edited, check again before removing anything
+		if !s.isTemporary(path) {
+			continue
+		}
+		if err := report.RemoveAll(path); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if !report.DryRun {
+		s.mutex.Lock()
+		err := fileutil.RemoveWithBackup(s.manifestPath(run))
+		s.mutex.Unlock()
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to remove artifacts of run %s: %s", run.ID, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// removeOrphans deletes untracked temporary files matching OrphanPatterns that were
+// last modified before cutoff
+func (s *Store) removeOrphans(cutoff time.Time, report *Report) error {
+	var errs []string
+
+	for _, pattern := range OrphanPatterns {
+		matches, err := filepath.Glob(filepath.Join(s.tempDir, pattern))
+		if err != nil {
+			return err
+		}
+		for _, path := range matches {
+			info, err := os.Lstat(path)
+			if err != nil || info.ModTime().After(cutoff) {
+				continue
+			}
+			if err := report.RemoveAll(path); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to remove orphaned files: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// save writes the manifest of a run
+func (s *Store) save(run *Run) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create run directory: %w", err)
+	}
+	return fileutil.WriteJSONAtomic(s.manifestPath(run), run, 0644)
+}
+
+// manifestPath returns the path of the manifest of a run
+func (s *Store) manifestPath(run *Run) string {
+	return filepath.Join(s.dir, run.ID+".json")
+}
+
+// isTemporary reports whether path lies strictly inside the temporary directory
+func (s *Store) isTemporary(path string) bool {
+	if path == "" || !filepath.IsAbs(path) {
+		return false
+	}
+	relative, err := filepath.Rel(s.tempDir, filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return relative != "." && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// RemoveAll removes a path and records its size, missing paths are not an error
+func (r *Report) RemoveAll(path string) error {
+	size, err := diskUsage(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if !r.DryRun {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	r.Removed = append(r.Removed, path)
+	r.Bytes += size
+	return nil
+}
+
+// diskUsage returns the total size of the files under path
+func diskUsage(path string) (int64, error) {
+	if _, err := os.Lstat(path); err != nil {
+		return 0, err
+	}
+
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, nil
+}
+
+// containsPath reports whether paths contains path once cleaned
+func containsPath(paths []string, path string) bool {
+	path = filepath.Clean(path)
+	for _, existing := range paths {
+		if existing == path {
+			return true
+		}
+	}
+	return false
+}
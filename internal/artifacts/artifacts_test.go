@@ -0,0 +1,103 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeArtifact creates a directory with one file under dir
+func makeArtifact(t *testing.T, dir, name string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(path, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "archive.tar.gz"), []byte("partial"), 0644))
+	return path
+}
+
+func TestStore_SuccessRemovesArtifacts(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewStore(t.TempDir(), tempDir)
+
+	run, err := store.Begin("install", "nginx")
+	require.NoError(t, err)
+
+	artifact := makeArtifact(t, tempDir, "sai-nginx")
+	outside := t.TempDir()
+	require.NoError(t, store.Track(run, artifact, outside, "relative/path"))
+	assert.Equal(t, []string{artifact}, run.Paths, "only paths inside the temp directory are tracked")
+
+	report, err := store.Finish(run, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{artifact}, report.Removed)
+	assert.Equal(t, int64(len("partial")), report.Bytes)
+	assert.NoDirExists(t, artifact)
+	assert.DirExists(t, outside)
+
+	runs, err := store.Runs()
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func TestStore_FailureKeepsArtifactsUntilPruned(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewStore(t.TempDir(), tempDir)
+
+	run, err := store.Begin("install", "nginx")
+	require.NoError(t, err)
+	artifact := makeArtifact(t, tempDir, "sai-nginx")
+	require.NoError(t, store.Track(run, artifact))
+
+	_, err = store.Finish(run, false)
+	require.NoError(t, err)
+	assert.DirExists(t, artifact)
+
+	// Still within the retention period
+	report, err := store.Prune(72*time.Hour, false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Removed)
+	assert.DirExists(t, artifact)
+
+	runs, err := store.Runs()
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, StatusFailed, runs[0].Status)
+
+	// A dry run only reports
+	report, err = store.Prune(0, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{artifact}, report.Removed)
+	assert.DirExists(t, artifact)
+
+	report, err = store.Prune(0, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{artifact}, report.Removed)
+	assert.NoDirExists(t, artifact)
+
+	runs, err = store.Runs()
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func TestStore_PruneOrphans(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewStore(t.TempDir(), tempDir)
+
+	old := makeArtifact(t, tempDir, "sai-build-1234")
+	recent := makeArtifact(t, tempDir, "sai-build-5678")
+	unrelated := makeArtifact(t, tempDir, "other-build-1234")
+
+	past := time.Now().Add(-2 * orphanMinAge)
+	require.NoError(t, os.Chtimes(old, past, past))
+	require.NoError(t, os.Chtimes(unrelated, past, past))
+
+	report, err := store.Prune(0, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{old}, report.Removed)
+	assert.NoDirExists(t, old)
+	assert.DirExists(t, recent, "orphans of runs that may still be in progress are kept")
+	assert.DirExists(t, unrelated)
+}
@@ -0,0 +1,266 @@
+// Package audit keeps an append-only log of the commands sai runs, one JSON object per
+// line, recording who ran what through which provider, its exit code and duration,
+// for compliance in regulated environments. The log is rotated by size and queried by
+// 'sai history'.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"sai/internal/debug"
+	"sai/internal/lock"
+)
+
+// lockTimeout bounds the wait for another sai process writing the log
+const lockTimeout = 5 * time.Second
+
+// maxLineSize bounds the size of a log line read back, scripts included
+const maxLineSize = 4 << 20
+
+// Entry is a command recorded in the audit log
+type Entry struct {
+	Time       time.Time `json:"time"`
+	User       string    `json:"user"`
+	SudoUser   string    `json:"sudo_user,omitempty"` // User who ran sai through sudo
+	Host       string    `json:"host"`
+	Provider   string    `json:"provider,omitempty"`
+	Action     string    `json:"action,omitempty"`
+	Software   string    `json:"software,omitempty"`
+	Command    string    `json:"command"`
+	Escalation string    `json:"escalation,omitempty"` // Tool the command ran as root through, see privilege
+	ExitCode   int       `json:"exit_code"`
+	DurationMS int64     `json:"duration_ms"`
+	DryRun     bool      `json:"dry_run"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Duration returns how long the command ran
+func (e *Entry) Duration() time.Duration {
+	return time.Duration(e.DurationMS) * time.Millisecond
+}
+
+// Failed reports whether the command failed
+func (e *Entry) Failed() bool {
+	return e.ExitCode != 0 || e.Error != ""
+}
+
+// Origin is the provider action a command runs for
+type Origin struct {
+	Provider string
+	Action   string
+	Software string
+}
+
+// originKey is the context key of the Origin of commands
+type originKey struct{}
+
+// WithOrigin returns a context whose commands are recorded as run for origin
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// OriginFrom returns the origin of the commands run with ctx, empty when unknown
+func OriginFrom(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey{}).(Origin)
+	return origin
+}
+
+// Log is an audit log file rotated by size
+type Log struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	user     string
+	sudoUser string
+	host     string
+}
+
+// NewLog returns the audit log written to path. Once the file reaches maxSize bytes it
+// is renamed path.1, the previous path.1 becoming path.2 and so on, keeping maxFiles
+// rotated files. A maxSize of 0 never rotates the log.
+func NewLog(path string, maxSize int64, maxFiles int) *Log {
+	log := &Log{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+		sudoUser: os.Getenv("SUDO_USER"),
+	}
+	if current, err := user.Current(); err == nil {
+		log.user = current.Username
+	} else {
+		log.user = strconv.Itoa(os.Getuid())
+	}
+	log.host, _ = os.Hostname()
+	return log
+}
+
+// Path returns the file the log is written to
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record appends a command to the log. The time, user and host are filled in, and the
+// provider, action and software from the origin of ctx when the entry has none.
+// Secrets are masked. Recording on a nil log does nothing.
+func (l *Log) Record(ctx context.Context, entry Entry) error {
+	if l == nil {
+		return nil
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+	entry.User = l.user
+	entry.SudoUser = l.sudoUser
+	entry.Host = l.host
+	origin := OriginFrom(ctx)
+	if entry.Provider == "" && entry.Action == "" && entry.Software == "" {
+		entry.Provider, entry.Action, entry.Software = origin.Provider, origin.Action, origin.Software
+	}
+	entry.Command = debug.MaskSecrets(entry.Command)
+	entry.Error = debug.MaskSecrets(entry.Error)
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	dir := filepath.Dir(l.path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create audit log directory %s: %w", dir, err)
+	}
+	held, err := lock.Acquire(context.Background(), dir, filepath.Base(l.path), lock.Options{Timeout: lockTimeout})
+	if err != nil {
+		return err
+	}
+	defer held.Release()
+
+	if err := l.rotate(int64(len(line))); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s: %w", l.path, err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write audit log %s: %w", l.path, err)
+	}
+	return file.Close()
+}
+
+// rotate renames the log when appending size bytes would exceed maxSize, dropping the
+// oldest rotated file
+func (l *Log) rotate(size int64) error {
+	if l.maxSize <= 0 {
+		return nil
+	}
+	info, err := os.Stat(l.path)
+	if err != nil || info.Size() == 0 || info.Size()+size <= l.maxSize {
+		return nil
+	}
+
+	keep := l.maxFiles
+	if keep < 1 {
+		keep = 1
+	}
+	os.Remove(l.rotated(keep))
+	for i := keep - 1; i >= 1; i-- {
+		if err := os.Rename(l.rotated(i), l.rotated(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+	if err := os.Rename(l.path, l.rotated(1)); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return nil
+}
+
+// rotated returns the name of the i-th most recent rotated file
+func (l *Log) rotated(i int) string {
+	return l.path + "." + strconv.Itoa(i)
+}
+
+// Filter selects entries of the log
+type Filter struct {
+	Software string
+	Provider string
+	Action   string
+	Since    time.Time // Entries recorded from this time, all when zero
+	Failed   bool      // Only failed commands
+	Limit    int       // Most recent entries returned, all when 0
+}
+
+// Matches reports whether an entry is selected by the filter
+func (f Filter) Matches(entry Entry) bool {
+	switch {
+	case f.Software != "" && entry.Software != f.Software:
+		return false
+	case f.Provider != "" && entry.Provider != f.Provider:
+		return false
+	case f.Action != "" && entry.Action != f.Action:
+		return false
+	case !f.Since.IsZero() && entry.Time.Before(f.Since):
+		return false
+	case f.Failed && !entry.Failed():
+		return false
+	}
+	return true
+}
+
+// Query returns the entries of the log and of its rotated files selected by filter,
+// oldest first. Lines that cannot be decoded, such as one cut by a crash, are skipped.
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	files := []string{l.path}
+	for i := 1; ; i++ {
+		if _, err := os.Stat(l.rotated(i)); err != nil {
+			break
+		}
+		files = append([]string{l.rotated(i)}, files...)
+	}
+
+	var entries []Entry
+	for _, path := range files {
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+		for scanner.Scan() {
+			var entry Entry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				continue
+			}
+			if filter.Matches(entry) {
+				entries = append(entries, entry)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log %s: %w", path, err)
+		}
+	}
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[len(entries)-filter.Limit:]
+	}
+	return entries, nil
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/debug"
+)
+
+func TestLog_RecordAndQuery(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "audit", "audit.jsonl"), 0, 0)
+	ctx := WithOrigin(context.Background(), Origin{Provider: "apt", Action: "install", Software: "nginx"})
+
+	require.NoError(t, log.Record(ctx, Entry{Command: "apt-get install -y nginx", DurationMS: 1500}))
+	require.NoError(t, log.Record(ctx, Entry{Command: "systemctl start nginx", ExitCode: 1, Error: "exit status 1"}))
+	require.NoError(t, log.Record(context.Background(), Entry{
+		Provider: "brew", Action: "install", Software: "redis", Command: "brew install redis", DryRun: true,
+	}))
+
+	entries, err := log.Query(Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "apt", entries[0].Provider)
+	assert.Equal(t, "nginx", entries[0].Software)
+	assert.Equal(t, 1500*time.Millisecond, entries[0].Duration())
+	assert.NotEmpty(t, entries[0].User)
+	assert.False(t, entries[0].Time.IsZero())
+	assert.True(t, entries[2].DryRun)
+
+	entries, err = log.Query(Filter{Software: "nginx", Failed: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "systemctl start nginx", entries[0].Command)
+
+	entries, err = log.Query(Filter{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "redis", entries[0].Software, "the most recent entries are kept")
+
+	entries, err = log.Query(Filter{Since: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	var nilLog *Log
+	assert.NoError(t, nilLog.Record(ctx, Entry{Command: "true"}))
+}
+
+func TestLog_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := NewLog(path, 200, 2)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, log.Record(context.Background(), Entry{Command: "echo rotated"}))
+	}
+
+	assert.FileExists(t, path+".1")
+	assert.FileExists(t, path+".2")
+	assert.NoFileExists(t, path+".3", "only max_files rotated files are kept")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(200))
+
+	entries, err := log.Query(Filter{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, entries)
+	assert.Less(t, len(entries), 10, "the oldest entries were dropped")
+}
+
+func TestLog_MasksSecretsAndSkipsCorruptLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := NewLog(path, 0, 0)
+	debug.RegisterSecret("s3cr3t-token")
+
+	require.NoError(t, log.Record(context.Background(), Entry{Command: "tool login --token s3cr3t-token"}))
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"time":"2024-01-01T00:00:00Z","comm`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	entries, err := log.Query(Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.NotContains(t, entries[0].Command, "s3cr3t-token")
+}
@@ -0,0 +1,433 @@
+// Package build builds and installs software from source archives as discrete steps:
+// download, checksum, extract, configure, build and install. Every step logs its output
+// to its own file in the build directory of the source, and the steps that completed are
+// skipped when a failed build is run again, so a build resumes where it stopped.
+package build
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"sai/internal/checksum"
+	"sai/internal/download"
+	"sai/internal/fileutil"
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// DefaultCacheDir is the directory the sources are downloaded to and built in
+const DefaultCacheDir = "/var/cache/sai/builds"
+
+// DefaultPrefix is the installation prefix of sources declaring none
+const DefaultPrefix = "/usr/local"
+
+// Step is a step of the build of a source
+type Step string
+
+// Steps of a build, in the order they run
+const (
+	StepDownload  Step = "download"
+	StepChecksum  Step = "checksum"
+	StepExtract   Step = "extract"
+	StepConfigure Step = "configure"
+	StepBuild     Step = "build"
+	StepInstall   Step = "install"
+)
+
+// Steps lists the steps of a build in the order they run
+var Steps = []Step{StepDownload, StepChecksum, StepExtract, StepConfigure, StepBuild, StepInstall}
+
+// Runner runs a command in a directory with additional environment variables and
+// returns its combined output
+type Runner func(ctx context.Context, dir string, env map[string]string, args ...string) (string, error)
+
+// Pipeline builds sources in a cache directory, running the commands of the build with
+// its runner
+type Pipeline struct {
+	CacheDir        string
+	Jobs            int    // Parallel jobs of the build step, the number of CPUs when 0
+	RequireChecksum bool   // Refuse sources declaring no checksum
+	DestDir         string // Installs below this directory through DESTDIR, empty for the running system
+	Run             Runner
+	Logger          interfaces.Logger // Reports the progress of builds, nil to stay quiet
+}
+
+// Result reports the steps of a build
+type Result struct {
+	Source  string `json:"source"`
+	Dir     string `json:"dir"`
+	Ran     []Step `json:"ran"`     // Steps run by this build
+	Resumed []Step `json:"resumed"` // Steps completed by an earlier build and skipped
+}
+
+// state is the progress of the build of a source, kept in its build directory
+type state struct {
+	Fingerprint string    `json:"fingerprint"`
+	Completed   []Step    `json:"completed"`
+	Updated     time.Time `json:"updated"`
+}
+
+// stateFile is the name of the state of a build in its build directory
+const stateFile = "state.json"
+
+// NewPipeline creates a pipeline building in cacheDir, DefaultCacheDir when empty
+func NewPipeline(cacheDir string, run Runner) *Pipeline {
+	if cacheDir == "" {
+		cacheDir = DefaultCacheDir
+	}
+	return &Pipeline{CacheDir: cacheDir, Run: run}
+}
+
+// Dir returns the build directory of a source
+func (p *Pipeline) Dir(source types.Source) string {
+	name := source.Name
+	if source.Version != "" {
+		name += "-" + source.Version
+	}
+	return filepath.Join(p.CacheDir, filepath.Base(filepath.Clean("/"+name)))
+}
+
+// Build runs the steps of the build of a source that did not complete yet. The
+// download, checksum, extract, configure and build steps are skipped when an earlier
+// build of the same source completed them; the install step always runs. A changed
+// source definition starts the build over.
+func (p *Pipeline) Build(ctx context.Context, source types.Source) (*Result, error) {
+	if err := validate(source); err != nil {
+		return nil, err
+	}
+	if p.RequireChecksum && source.Checksum == "" {
+		return nil, fmt.Errorf("source %s declares no checksum", source.Name)
+	}
+
+	dir := p.Dir(source)
+	current, err := p.prepare(dir, source)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Source: source.Name, Dir: dir}
+	for _, step := range Steps {
+		if step != StepInstall && current.completed(step) {
+			result.Resumed = append(result.Resumed, step)
+			continue
+		}
+
+		p.log("Running build step", source, step)
+		if err := p.runStep(ctx, dir, source, step); err != nil {
+			if step == StepChecksum {
+				// Download the archive again on the next build rather than verifying
+				// the same corrupt file
+				os.Remove(archivePath(dir, source))
+				current.Completed = nil
+				saveState(dir, current)
+			}
+			return result, fmt.Errorf("%s step of %s failed, see %s: %w", step, source.Name, logPath(dir, step), err)
+		}
+		result.Ran = append(result.Ran, step)
+
+		if step != StepInstall {
+			current.Completed = append(current.Completed, step)
+			if err := saveState(dir, current); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// Plan describes the steps a build of a source would run, marking the ones an earlier
+// build completed
+func (p *Pipeline) Plan(source types.Source) []string {
+	dir := p.Dir(source)
+	current, _ := loadState(dir)
+	if current != nil && current.Fingerprint != fingerprint(source) {
+		current = nil
+	}
+
+	system := source.BuildSystem
+	if system == "" {
+		if src, err := sourceDir(dir); err == nil {
+			system, _ = detectSystem(src)
+		}
+	}
+
+	var plan []string
+	for _, step := range Steps {
+		description := p.describe(dir, source, system, step)
+		if step != StepInstall && current != nil && current.completed(step) {
+			description += " (done)"
+		}
+		plan = append(plan, fmt.Sprintf("%s: %s", step, description))
+	}
+	return plan
+}
+
+// describe returns what a step of the build of a source does
+func (p *Pipeline) describe(dir string, source types.Source, system string, step Step) string {
+	switch step {
+	case StepDownload:
+		return fmt.Sprintf("%s to %s", source.URL, archivePath(dir, source))
+	case StepChecksum:
+		if source.Checksum == "" {
+			return "no checksum declared"
+		}
+		return source.Checksum
+	case StepExtract:
+		return filepath.Join(dir, "src")
+	}
+
+	if system == "" {
+		return "build system detected after extraction"
+	}
+	commands := p.commands(source, system)[step]
+	if len(commands) == 0 {
+		return "nothing to do for " + system
+	}
+	var described []string
+	for _, args := range commands {
+		described = append(described, strings.Join(args, " "))
+	}
+	return strings.Join(described, " && ")
+}
+
+// runStep runs a step of the build of a source, logging its output to the log of the step
+func (p *Pipeline) runStep(ctx context.Context, dir string, source types.Source, step Step) error {
+	logFile, err := openLog(dir, step)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	switch step {
+	case StepDownload:
+		fmt.Fprintf(logFile, "Downloading %s\n", source.URL)
+		return download.Fetch(ctx, source.URL, archivePath(dir, source))
+	case StepChecksum:
+		if source.Checksum == "" {
+			fmt.Fprintln(logFile, "No checksum declared, archive not verified")
+			return nil
+		}
+		fmt.Fprintf(logFile, "Verifying %s\n", source.Checksum)
+		return checksum.Verify(archivePath(dir, source), source.Checksum)
+	case StepExtract:
+		src := filepath.Join(dir, "src")
+		if err := os.RemoveAll(src); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", src, err)
+		}
+		fmt.Fprintf(logFile, "Extracting %s to %s\n", archivePath(dir, source), src)
+		return p.extract(ctx, archivePath(dir, source), src)
+	}
+
+	src, err := sourceDir(dir)
+	if err != nil {
+		return err
+	}
+	system := source.BuildSystem
+	if system == "" {
+		if system, err = detectSystem(src); err != nil {
+			return err
+		}
+		fmt.Fprintf(logFile, "Detected build system %s\n", system)
+	}
+
+	env := map[string]string{}
+	for key, value := range source.Env {
+		env[key] = value
+	}
+	if step == StepInstall && p.DestDir != "" {
+		env["DESTDIR"] = p.DestDir
+	}
+	for _, args := range p.commands(source, system)[step] {
+		fmt.Fprintf(logFile, "$ %s\n", strings.Join(args, " "))
+		output, err := p.Run(ctx, src, env, args...)
+		logFile.WriteString(output)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commands returns the commands of the configure, build and install steps of a build
+// system
+func (p *Pipeline) commands(source types.Source, system string) map[Step][][]string {
+	prefix := source.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	jobs := p.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	parallel := fmt.Sprintf("-j%d", jobs)
+
+	switch system {
+	case "autotools":
+		return map[Step][][]string{
+			StepConfigure: {append([]string{"./configure", "--prefix=" + prefix}, source.ConfigureArgs...)},
+			StepBuild:     {append([]string{"make", parallel}, source.BuildArgs...)},
+			StepInstall:   {append([]string{"make", "install"}, source.InstallArgs...)},
+		}
+	case "cmake":
+		return map[Step][][]string{
+			StepConfigure: {append([]string{"cmake", "-S", ".", "-B", "build", "-DCMAKE_BUILD_TYPE=Release", "-DCMAKE_INSTALL_PREFIX=" + prefix}, source.ConfigureArgs...)},
+			StepBuild:     {append([]string{"cmake", "--build", "build", "--parallel", fmt.Sprint(jobs)}, source.BuildArgs...)},
+			StepInstall:   {append([]string{"cmake", "--install", "build"}, source.InstallArgs...)},
+		}
+	case "meson":
+		return map[Step][][]string{
+			StepConfigure: {append([]string{"meson", "setup", "build", "--buildtype=release", "--prefix=" + prefix}, source.ConfigureArgs...)},
+			StepBuild:     {append([]string{"meson", "compile", "-C", "build", parallel}, source.BuildArgs...)},
+			StepInstall:   {append([]string{"meson", "install", "-C", "build"}, source.InstallArgs...)},
+		}
+	default:
+		// Plain makefiles have no configure step and take the prefix as a variable
+		return map[Step][][]string{
+			StepBuild:   {append([]string{"make", parallel, "PREFIX=" + prefix}, source.BuildArgs...)},
+			StepInstall: {append([]string{"make", "install", "PREFIX=" + prefix}, source.InstallArgs...)},
+		}
+	}
+}
+
+// detectSystem returns the build system of extracted sources from the files at their
+// top level
+func detectSystem(src string) (string, error) {
+	for _, candidate := range []struct{ file, system string }{
+		{"configure", "autotools"},
+		{"CMakeLists.txt", "cmake"},
+		{"meson.build", "meson"},
+		{"Makefile", "make"},
+		{"makefile", "make"},
+		{"GNUmakefile", "make"},
+	} {
+		if _, err := os.Stat(filepath.Join(src, candidate.file)); err == nil {
+			return candidate.system, nil
+		}
+	}
+	return "", fmt.Errorf("no supported build system found in %s, set build_system", src)
+}
+
+// prepare creates the build directory of a source and returns its state. The state of
+// a build of a different definition of the source is discarded with its files.
+func (p *Pipeline) prepare(dir string, source types.Source) (*state, error) {
+	current, err := loadState(dir)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.Fingerprint == fingerprint(source) {
+		return current, nil
+	}
+
+	if current != nil {
+		p.log("Source definition changed, starting the build over", source, "")
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, fmt.Errorf("failed to remove build directory %s: %w", dir, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "logs"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create build directory %s: %w", dir, err)
+	}
+	return &state{Fingerprint: fingerprint(source)}, nil
+}
+
+// completed returns whether a step completed
+func (s *state) completed(step Step) bool {
+	for _, completed := range s.Completed {
+		if completed == step {
+			return true
+		}
+	}
+	return false
+}
+
+// loadState reads the state of a build, nil when it never ran. A corrupt state is
+// recovered from its backup, or the build starts over.
+func loadState(dir string) (*state, error) {
+	var current state
+	if err := fileutil.ReadJSON(filepath.Join(dir, stateFile), &current); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read build state: %w", err)
+	}
+	return &current, nil
+}
+
+// saveState writes the state of a build atomically, so a crash while writing it
+// does not lose the steps completed
+func saveState(dir string, current *state) error {
+	current.Updated = time.Now()
+	if err := fileutil.WriteJSONAtomic(filepath.Join(dir, stateFile), current, 0644); err != nil {
+		return fmt.Errorf("failed to write build state: %w", err)
+	}
+	return nil
+}
+
+// fingerprint identifies the definition of a source, so a changed definition is built
+// from scratch
+func fingerprint(source types.Source) string {
+	data, _ := json.Marshal(source)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// validate checks that a source can be built
+func validate(source types.Source) error {
+	if source.Name == "" {
+		return fmt.Errorf("source without name")
+	}
+	if source.URL == "" {
+		return fmt.Errorf("source %s has no url", source.Name)
+	}
+	switch source.BuildSystem {
+	case "", "autotools", "cmake", "meson", "make":
+		return nil
+	}
+	return fmt.Errorf("unsupported build system %s of source %s", source.BuildSystem, source.Name)
+}
+
+// archivePath returns where the archive of a source is downloaded to
+func archivePath(dir string, source types.Source) string {
+	name := "source"
+	if parsed, err := url.Parse(source.URL); err == nil && path.Base(parsed.Path) != "/" && path.Base(parsed.Path) != "." {
+		name = path.Base(parsed.Path)
+	}
+	return filepath.Join(dir, name)
+}
+
+// logPath returns the log of a step
+func logPath(dir string, step Step) string {
+	return filepath.Join(dir, "logs", string(step)+".log")
+}
+
+// openLog truncates the log of a step for a new run
+func openLog(dir string, step Step) (*os.File, error) {
+	file, err := os.Create(logPath(dir, step))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create build log: %w", err)
+	}
+	return file, nil
+}
+
+// log reports the progress of a build
+func (p *Pipeline) log(message string, source types.Source, step Step) {
+	if p.Logger == nil {
+		return
+	}
+	fields := []interfaces.LogField{{Key: "source", Value: source.Name}}
+	if step != "" {
+		fields = append(fields, interfaces.LogField{Key: "step", Value: step})
+	}
+	p.Logger.Info(message, fields...)
+}
@@ -0,0 +1,236 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sai/internal/types"
+)
+
+// tarball returns a gzip tarball of files by name
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// tarEntry is an entry of a tarball built by tarEntries
+type tarEntry struct {
+	name     string
+	typeflag byte
+	content  string // Content of a file, target of a link
+}
+
+// tarEntries returns a gzip tarball of the entries in order
+func tarEntries(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Typeflag: entry.typeflag, Mode: 0644}
+		switch entry.typeflag {
+		case tar.TypeReg:
+			header.Size = int64(len(entry.content))
+		case tar.TypeSymlink:
+			header.Linkname = entry.content
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if entry.typeflag == tar.TypeReg {
+			tw.Write([]byte(entry.content))
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// serve serves an archive and returns its source definition
+func serve(t *testing.T, archive []byte) types.Source {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	t.Cleanup(server.Close)
+	sum := sha256.Sum256(archive)
+	return types.Source{
+		Name:     "hello",
+		Version:  "1.0",
+		URL:      server.URL + "/hello-1.0.tar.gz",
+		Checksum: "sha256:" + hex.EncodeToString(sum[:]),
+		Prefix:   "/opt/hello",
+	}
+}
+
+func TestPipelineBuildResumes(t *testing.T) {
+	source := serve(t, tarball(t, map[string]string{
+		"hello-1.0/Makefile": "all:\n",
+		"hello-1.0/hello.c":  "int main() { return 0; }\n",
+	}))
+
+	var commands []string
+	failBuild := true
+	pipeline := NewPipeline(t.TempDir(), func(ctx context.Context, dir string, env map[string]string, args ...string) (string, error) {
+		if filepath.Base(dir) != "hello-1.0" {
+			t.Errorf("command run in %s, want the top directory of the sources", dir)
+		}
+		commands = append(commands, strings.Join(args, " "))
+		if args[1] != "install" && failBuild {
+			return "hello.c: error\n", errors.New("exit status 2")
+		}
+		return "ok\n", nil
+	})
+	pipeline.Jobs = 2
+
+	result, err := pipeline.Build(context.Background(), source)
+	if err == nil || !strings.Contains(err.Error(), "build step of hello failed") {
+		t.Fatalf("failing build returned %v", err)
+	}
+	if !reflect.DeepEqual(result.Ran, []Step{StepDownload, StepChecksum, StepExtract, StepConfigure}) {
+		t.Errorf("ran %v before the failure", result.Ran)
+	}
+	log, _ := os.ReadFile(logPath(pipeline.Dir(source), StepBuild))
+	if !strings.Contains(string(log), "$ make -j2 PREFIX=/opt/hello") || !strings.Contains(string(log), "hello.c: error") {
+		t.Errorf("build log = %q", log)
+	}
+
+	failBuild = false
+	commands = nil
+	result, err = pipeline.Build(context.Background(), source)
+	if err != nil {
+		t.Fatalf("resumed build failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Resumed, []Step{StepDownload, StepChecksum, StepExtract, StepConfigure}) {
+		t.Errorf("resumed %v", result.Resumed)
+	}
+	expected := []string{"make -j2 PREFIX=/opt/hello", "make install PREFIX=/opt/hello"}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("commands = %v, want %v", commands, expected)
+	}
+
+	// A built source is only installed again
+	commands = nil
+	if result, err = pipeline.Build(context.Background(), source); err != nil || !reflect.DeepEqual(result.Ran, []Step{StepInstall}) {
+		t.Errorf("rebuild ran %v, %v", result.Ran, err)
+	}
+
+	// A changed definition starts over
+	source.ConfigureArgs = []string{"--enable-static"}
+	source.BuildSystem = "autotools"
+	commands = nil
+	if result, err = pipeline.Build(context.Background(), source); err != nil || len(result.Resumed) != 0 {
+		t.Fatalf("changed source resumed %v, %v", result.Resumed, err)
+	}
+	if commands[0] != "./configure --prefix=/opt/hello --enable-static" {
+		t.Errorf("configure command = %s", commands[0])
+	}
+}
+
+func TestPipelineBuildChecksumMismatch(t *testing.T) {
+	source := serve(t, tarball(t, map[string]string{"hello-1.0/Makefile": "all:\n"}))
+	source.Checksum = "sha256:" + strings.Repeat("0", 64)
+
+	pipeline := NewPipeline(t.TempDir(), func(ctx context.Context, dir string, env map[string]string, args ...string) (string, error) {
+		t.Errorf("command %v run for an unverified archive", args)
+		return "", nil
+	})
+	if _, err := pipeline.Build(context.Background(), source); err == nil || !strings.Contains(err.Error(), "checksum step") {
+		t.Fatalf("mismatching checksum returned %v", err)
+	}
+	if _, err := os.Stat(archivePath(pipeline.Dir(source), source)); !os.IsNotExist(err) {
+		t.Errorf("unverified archive kept: %v", err)
+	}
+	if current, _ := loadState(pipeline.Dir(source)); current == nil || len(current.Completed) != 0 {
+		t.Errorf("download not reset after the checksum failure: %+v", current)
+	}
+
+	source.Checksum = ""
+	pipeline.RequireChecksum = true
+	if _, err := pipeline.Build(context.Background(), source); err == nil {
+		t.Error("source without checksum built with checksums required")
+	}
+}
+
+func TestExtractRefusesTraversal(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.tar.gz")
+	os.WriteFile(archive, tarball(t, map[string]string{"../evil": "x"}), 0644)
+
+	pipeline := NewPipeline(dir, nil)
+	if err := pipeline.extract(context.Background(), archive, filepath.Join(dir, "src")); err == nil {
+		t.Error("entry outside the archive extracted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil")); !os.IsNotExist(err) {
+		t.Error("evil written outside the destination")
+	}
+}
+
+func TestExtractRefusesChainedLinks(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "build", "src")
+	archive := filepath.Join(dir, "chained.tar.gz")
+
+	// Each link points inside the archive on its own, chained they lead above dest
+	os.WriteFile(archive, tarEntries(t,
+		tarEntry{"sub/", tar.TypeDir, ""},
+		tarEntry{"sub/a", tar.TypeSymlink, ".."},
+		tarEntry{"sub/a/b", tar.TypeSymlink, ".."},
+		tarEntry{"sub/a/b/evil", tar.TypeReg, "x"},
+	), 0644)
+
+	pipeline := NewPipeline(dir, nil)
+	if err := pipeline.extract(context.Background(), archive, dest); err == nil || !strings.Contains(err.Error(), "inside the link") {
+		t.Errorf("chained links extracted: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "build", "b")); !os.IsNotExist(err) {
+		t.Error("link created outside the destination")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "build", "evil")); !os.IsNotExist(err) {
+		t.Error("evil written outside the destination")
+	}
+}
+
+func TestExtractReplacesLinks(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "replaced.tar.gz")
+	os.WriteFile(archive, tarEntries(t,
+		tarEntry{"config", tar.TypeReg, "original"},
+		tarEntry{"link", tar.TypeSymlink, "config"},
+		tarEntry{"link", tar.TypeReg, "replaced"},
+	), 0644)
+
+	dest := filepath.Join(dir, "src")
+	if err := NewPipeline(dir, nil).extract(context.Background(), archive, dest); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dest, "config")); string(content) != "original" {
+		t.Errorf("file written through a link: %q", content)
+	}
+	if info, err := os.Lstat(filepath.Join(dest, "link")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("link not replaced by the file: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package build
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extract unpacks an archive to dest. Gzip, bzip2 and uncompressed tarballs and zip
+// archives are unpacked natively, xz tarballs with tar.
+func (p *Pipeline) extract(ctx context.Context, archive, dest string) error {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dest, err)
+	}
+
+	name := strings.ToLower(filepath.Base(archive))
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return extractTar(archive, dest, func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) })
+	case strings.HasSuffix(name, ".tar.bz2"), strings.HasSuffix(name, ".tbz2"):
+		return extractTar(archive, dest, func(r io.Reader) (io.Reader, error) { return bzip2.NewReader(r), nil })
+	case strings.HasSuffix(name, ".tar"):
+		return extractTar(archive, dest, func(r io.Reader) (io.Reader, error) { return r, nil })
+	case strings.HasSuffix(name, ".zip"):
+		return extractZip(archive, dest)
+	case strings.HasSuffix(name, ".tar.xz"), strings.HasSuffix(name, ".txz"):
+		_, err := p.Run(ctx, dest, nil, "tar", "-xJf", archive)
+		return err
+	}
+	return fmt.Errorf("unsupported archive %s", filepath.Base(archive))
+}
+
+// extractTar unpacks a tarball decompressed by decompress to dest
+func extractTar(archive, dest string, decompress func(io.Reader) (io.Reader, error)) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", archive, err)
+	}
+	defer file.Close()
+
+	reader, err := decompress(file)
+	if err != nil {
+		return fmt.Errorf("failed to decompress %s: %w", archive, err)
+	}
+
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archive, err)
+		}
+
+		target, err := entryPath(dest, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+		case tar.TypeReg:
+			if err := writeEntry(target, os.FileMode(header.Mode).Perm(), tr); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			// Links may only point inside the sources
+			if _, err := entryPath(dest, filepath.Join(filepath.Dir(header.Name), header.Linkname)); err != nil || filepath.IsAbs(header.Linkname) {
+				return fmt.Errorf("link %s of %s points outside the archive", header.Name, filepath.Base(archive))
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(target), err)
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return fmt.Errorf("failed to create link %s: %w", target, err)
+			}
+		}
+	}
+}
+
+// extractZip unpacks a zip archive to dest
+func extractZip(archive, dest string) error {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", archive, err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		target, err := entryPath(dest, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+			continue
+		}
+
+		content, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		err = writeEntry(target, file.Mode().Perm(), content)
+		content.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entryPath returns where an archive entry is unpacked, refusing entries outside dest
+// and entries below a link unpacked earlier: links are only checked lexically, so a
+// chain of them could lead anywhere
+func entryPath(dest, name string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid archive entry %s", name)
+	}
+
+	parent := dest
+	parts := strings.Split(cleaned, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		parent = filepath.Join(parent, part)
+		if info, err := os.Lstat(parent); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("invalid archive entry %s inside the link %s", name, part)
+		}
+	}
+	return filepath.Join(dest, cleaned), nil
+}
+
+// writeEntry writes the content of an archive entry to a file. A link unpacked
+// earlier at the same path is replaced rather than written through.
+func writeEntry(target string, mode os.FileMode, content io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(target), err)
+	}
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(target); err != nil {
+			return fmt.Errorf("failed to replace link %s: %w", target, err)
+		}
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0200)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return file.Close()
+}
+
+// sourceDir returns the top directory of the extracted sources of a build. Archives
+// usually hold a single directory named after the release, which is the top directory
+// then.
+func sourceDir(dir string) (string, error) {
+	src := filepath.Join(dir, "src")
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return "", fmt.Errorf("sources are not extracted: %w", err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(src, entries[0].Name()), nil
+	}
+	return src, nil
+}
@@ -0,0 +1,274 @@
+// Package bundle packages saidata, provider definitions and mirrored downloads into a
+// gzipped tarball, so sai can be used on systems without internet access.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FormatVersion is the version of the bundle layout written by Export
+const FormatVersion = 1
+
+// Top-level entries of a bundle
+const (
+	manifestName = "manifest.json"
+	saidataDir   = "saidata"
+	providersDir = "providers"
+	downloadsDir = "downloads"
+)
+
+// Manifest describes the contents of a bundle, it is the first entry of the tarball
+type Manifest struct {
+	Version       int       `json:"version"`
+	Created       time.Time `json:"created"`
+	SaidataFiles  int       `json:"saidata_files"`
+	ProviderFiles int       `json:"provider_files"`
+	Downloads     int       `json:"downloads"`
+}
+
+// Contents are the directories exported into a bundle, empty ones are skipped
+type Contents struct {
+	SaidataDir   string   // Saidata repository, without its git metadata
+	ProviderDirs []string // Provider definitions in increasing precedence, later files replace earlier ones with the same name
+	DownloadsDir string   // Downloads mirror, see download.Mirror
+}
+
+// Targets are the directories a bundle is imported into, empty ones skip that part
+type Targets struct {
+	SaidataDir   string // Receives the saidata repository, it should be empty
+	ProvidersDir string // Receives the provider definitions, replacing those with the same name
+	DownloadsDir string // Receives the mirrored downloads
+}
+
+// entry is a file exported into a bundle
+type entry struct {
+	name string // Slash-separated name in the bundle
+	path string // File on disk
+}
+
+// Export writes a bundle of contents to w and returns its manifest
+func Export(w io.Writer, contents Contents) (*Manifest, error) {
+	var saidata, providers, downloads []entry
+	var err error
+	if contents.SaidataDir != "" {
+		if saidata, err = collectTree(contents.SaidataDir, saidataDir); err != nil {
+			return nil, err
+		}
+	}
+	if providers, err = collectProviders(contents.ProviderDirs); err != nil {
+		return nil, err
+	}
+	if contents.DownloadsDir != "" {
+		if downloads, err = collectTree(contents.DownloadsDir, downloadsDir); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := &Manifest{
+		Version:       FormatVersion,
+		Created:       time.Now().UTC(),
+		SaidataFiles:  len(saidata),
+		ProviderFiles: len(providers),
+		Downloads:     len(downloads),
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bundle manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: manifestName, Mode: 0644, Size: int64(len(data)), ModTime: manifest.Created}); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	for _, files := range [][]entry{saidata, providers, downloads} {
+		for _, file := range files {
+			if err := writeEntry(tw, file); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return manifest, nil
+}
+
+// Import extracts a bundle read from r into targets and returns its manifest. Entries
+// escaping their target directory are refused.
+func Import(r io.Reader, targets Targets) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a sai bundle: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != manifestName {
+		return nil, fmt.Errorf("not a sai bundle: %s is missing", manifestName)
+	}
+	var manifest Manifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	if manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("bundle version %d is newer than the supported version %d, upgrade sai", manifest.Version, FormatVersion)
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("invalid bundle entry %s", header.Name)
+		}
+		top, rest, _ := strings.Cut(name, "/")
+		var target string
+		switch top {
+		case saidataDir:
+			target = targets.SaidataDir
+		case providersDir:
+			target = targets.ProvidersDir
+		case downloadsDir:
+			target = targets.DownloadsDir
+		default:
+			return nil, fmt.Errorf("unknown bundle entry %s", header.Name)
+		}
+		if target == "" || rest == "" {
+			continue
+		}
+		if err := extractFile(tr, filepath.Join(target, filepath.FromSlash(rest)), header.FileInfo().Mode().Perm()); err != nil {
+			return nil, err
+		}
+	}
+	return &manifest, nil
+}
+
+// collectTree returns the regular files under dir, named under prefix. Git metadata
+// is left out.
+func collectTree(dir, prefix string) ([]entry, error) {
+	var files []entry
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, entry{name: path.Join(prefix, filepath.ToSlash(rel)), path: p})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// collectProviders returns the provider definitions of dirs, a file replacing those
+// with the same name in earlier directories. Missing directories are skipped.
+func collectProviders(dirs []string) ([]entry, error) {
+	byName := make(map[string]entry)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read provider directory %s: %w", dir, err)
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if !e.Type().IsRegular() || (!strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml")) {
+				continue
+			}
+			byName[name] = entry{name: path.Join(providersDir, name), path: filepath.Join(dir, name)}
+		}
+	}
+
+	files := make([]entry, 0, len(byName))
+	for _, file := range byName {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	return files, nil
+}
+
+// writeEntry copies a file into the tarball
+func writeEntry(tw *tar.Writer, file entry) error {
+	f, err := os.Open(file.path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file.path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file.path, err)
+	}
+
+	header := &tar.Header{
+		Name:    file.name,
+		Mode:    int64(info.Mode().Perm()),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("failed to write %s to the bundle: %w", file.path, err)
+	}
+	return nil
+}
+
+// extractFile writes a bundle entry to dest
+func extractFile(r io.Reader, dest string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dest, err)
+	}
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to extract %s: %w", dest, err)
+	}
+	return f.Close()
+}
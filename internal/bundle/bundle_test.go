@@ -0,0 +1,99 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestExportImport(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "saidata", "software", "ng", "nginx", "default.yaml"), "version: \"0.2\"\n")
+	writeFile(t, filepath.Join(src, "saidata", ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(src, "providers", "apt.yaml"), "bundled apt\n")
+	writeFile(t, filepath.Join(src, "providers", "README.md"), "not a provider\n")
+	writeFile(t, filepath.Join(src, "providers.d", "apt.yaml"), "custom apt\n")
+	writeFile(t, filepath.Join(src, "downloads", "0011-tool.tar.gz"), "binary\n")
+
+	var buf bytes.Buffer
+	manifest, err := Export(&buf, Contents{
+		SaidataDir:   filepath.Join(src, "saidata"),
+		ProviderDirs: []string{filepath.Join(src, "providers"), filepath.Join(src, "providers.d"), filepath.Join(src, "missing")},
+		DownloadsDir: filepath.Join(src, "downloads"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Manifest{Version: FormatVersion, Created: manifest.Created, SaidataFiles: 1, ProviderFiles: 1, Downloads: 1}, manifest)
+
+	dest := t.TempDir()
+	imported, err := Import(&buf, Targets{
+		SaidataDir:   filepath.Join(dest, "saidata"),
+		ProvidersDir: filepath.Join(dest, "providers.d"),
+		DownloadsDir: filepath.Join(dest, "downloads"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, manifest.SaidataFiles, imported.SaidataFiles)
+
+	assert.FileExists(t, filepath.Join(dest, "saidata", "software", "ng", "nginx", "default.yaml"))
+	assert.NoDirExists(t, filepath.Join(dest, "saidata", ".git"))
+	data, err := os.ReadFile(filepath.Join(dest, "providers.d", "apt.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "custom apt\n", string(data), "later provider directories take precedence")
+	assert.FileExists(t, filepath.Join(dest, "downloads", "0011-tool.tar.gz"))
+}
+
+func TestImport_SkipsEmptyTargets(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "apt.yaml"), "apt\n")
+
+	var buf bytes.Buffer
+	_, err := Export(&buf, Contents{ProviderDirs: []string{src}})
+	require.NoError(t, err)
+
+	dest := t.TempDir()
+	_, err = Import(&buf, Targets{SaidataDir: filepath.Join(dest, "saidata")})
+	require.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(dest, "saidata"))
+}
+
+func TestImport_Invalid(t *testing.T) {
+	_, err := Import(bytes.NewReader([]byte("not gzip")), Targets{})
+	assert.ErrorContains(t, err, "not a sai bundle")
+
+	tarball := func(names ...string) *bytes.Buffer {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		for _, name := range names {
+			content := []byte("{}")
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+			_, err := tw.Write(content)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+		return &buf
+	}
+
+	_, err = Import(tarball("saidata/a.yaml"), Targets{})
+	assert.ErrorContains(t, err, "manifest.json is missing")
+
+	dest := t.TempDir()
+	_, err = Import(tarball(manifestName, "saidata/../../escape.yaml"), Targets{SaidataDir: filepath.Join(dest, "saidata")})
+	assert.ErrorContains(t, err, "invalid bundle entry")
+	assert.NoFileExists(t, filepath.Join(dest, "escape.yaml"))
+
+	_, err = Import(tarball(manifestName, "other/file"), Targets{})
+	assert.ErrorContains(t, err, "unknown bundle entry")
+}
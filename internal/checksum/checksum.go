@@ -0,0 +1,124 @@
+// Package checksum parses the checksums saidata and checksum files declare, written as
+// "<algorithm>:<hex>" or as bare hex whose length selects the algorithm, and verifies
+// files against them.
+package checksum
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// algorithms are the supported hash algorithms and their constructors
+var algorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Secure are the algorithms accepted to verify downloaded files
+var Secure = []string{"sha256", "sha512"}
+
+// All are the algorithms supported, including those only fit to detect corruption
+var All = []string{"md5", "sha1", "sha256", "sha512"}
+
+// Checksum is a parsed checksum
+type Checksum struct {
+	Algorithm string
+	Digest    string
+}
+
+// Parse parses a checksum restricted to the given algorithms and returns it with a
+// lower-case algorithm and digest
+func Parse(checksum string, allowed []string) (Checksum, error) {
+	algorithm, digest, found := strings.Cut(strings.TrimSpace(checksum), ":")
+	if !found {
+		algorithm, digest = "", algorithm
+	}
+	algorithm, digest = strings.ToLower(algorithm), strings.ToLower(digest)
+
+	if algorithm == "" {
+		for _, name := range allowed {
+			if len(digest) == algorithms[name]().Size()*2 {
+				algorithm = name
+				break
+			}
+		}
+		if algorithm == "" {
+			return Checksum{}, fmt.Errorf("cannot tell the algorithm of checksum %q, expected %s", checksum, expected(allowed))
+		}
+	}
+
+	newHash, ok := algorithms[algorithm]
+	if !ok || !contains(allowed, algorithm) {
+		return Checksum{}, fmt.Errorf("unsupported checksum %q, expected %s", checksum, expected(allowed))
+	}
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != newHash().Size()*2 {
+		return Checksum{}, fmt.Errorf("invalid %s checksum %q", algorithm, checksum)
+	}
+	return Checksum{Algorithm: algorithm, Digest: digest}, nil
+}
+
+// File returns the lower-case hex digest of a file
+func File(path, algorithm string) (string, error) {
+	newHash, ok := algorithms[strings.ToLower(algorithm)]
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm %q, expected %s", algorithm, strings.Join(All, ", "))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	digest := newHash()
+	if _, err := io.Copy(digest, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// Verify compares the digest of a file with an expected sha256 or sha512 checksum
+func Verify(path, checksum string) error {
+	parsed, err := Parse(checksum, Secure)
+	if err != nil {
+		return err
+	}
+
+	actual, err := File(path, parsed.Algorithm)
+	if err != nil {
+		return err
+	}
+	if actual != parsed.Digest {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, parsed.Digest, actual)
+	}
+	return nil
+}
+
+// expected describes the accepted checksum forms for error messages
+func expected(allowed []string) string {
+	forms := make([]string, len(allowed))
+	for i, algorithm := range allowed {
+		forms[i] = algorithm + ":<hex>"
+	}
+	return strings.Join(forms, " or ")
+}
+
+// contains reports whether names includes name
+func contains(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
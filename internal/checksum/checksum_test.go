@@ -0,0 +1,79 @@
+package checksum
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool")
+	require.NoError(t, os.WriteFile(path, []byte("hello\n"), 0755))
+
+	const sha256Hello = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	const sha512Hello = "e7c22b994c59d9cf2b48e549b1e24666636045930d3da7c1acb299d1c3b7f931f94aae41edda2c2b207a36e10f8bcb8d45223e54878f5b316e7ce3b6bc019629"
+
+	tests := []struct {
+		name     string
+		checksum string
+		valid    bool
+	}{
+		{"sha256 prefix", "sha256:" + sha256Hello, true},
+		{"sha512 prefix", "sha512:" + sha512Hello, true},
+		{"bare sha256", sha256Hello, true},
+		{"upper case", "SHA256:" + "5891B5B522D5DF086D0FF0B110FBD9D21BB4FC7163AF34D08286A2E846F6BE03", true},
+		{"mismatch", "sha256:" + sha256Hello[:63] + "0", false},
+		{"unsupported algorithm", "md5:b1946ac92492d2347c6235b4d2611184", false},
+		{"wrong length", "sha256:abcd", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(path, tt.checksum)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestVerifyMissingFile(t *testing.T) {
+	err := Verify(filepath.Join(t.TempDir(), "missing"), "sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03")
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		checksum  string
+		allowed   []string
+		algorithm string
+		wantErr   bool
+	}{
+		{"bare sha1 among all", "F572D396FAE9206628714FB2CE00F72E94F2258F", All, "sha1", false},
+		{"bare md5 among all", "b1946ac92492d2347c6235b4d2611184", All, "md5", false},
+		{"md5 refused when secure", "md5:b1946ac92492d2347c6235b4d2611184", Secure, "", true},
+		{"bare md5 refused when secure", "b1946ac92492d2347c6235b4d2611184", Secure, "", true},
+		{"unknown length", "abc", All, "", true},
+		{"not hex", "sha1:zz72d396fae9206628714fb2ce00f72e94f2258f", All, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := Parse(tt.checksum, tt.allowed)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.algorithm, parsed.Algorithm)
+			assert.Equal(t, strings.ToLower(strings.TrimPrefix(tt.checksum, tt.algorithm+":")), parsed.Digest)
+		})
+	}
+}
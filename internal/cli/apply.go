@@ -12,7 +12,9 @@ import (
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
+	"sai/internal/debug"
 	"sai/internal/interfaces"
+	"sai/internal/manifest"
 	"sai/internal/output"
 )
 
@@ -27,7 +29,22 @@ This command supports batch installation, service management, and other operatio
 defined in the action file. Each action is executed in sequence with proper
 error handling and rollback capabilities.
 
+The software section of the file declares the desired state of software instead:
+its state (present, latest or absent), version, provider and service state. sai
+compares it with the installed software, shows the plan as a diff and runs only the
+actions it lists, before the actions of the file:
+
+  software:
+    - name: nginx
+      version: "1.24"
+      service: running
+      enabled: true
+    - name: telnet
+      state: absent
+
 Examples:
+  sai apply manifest.yaml              # Show the plan of a manifest
+  sai apply manifest.yaml --yes        # Apply the plan of a manifest
   sai apply actions.yaml               # Execute actions from YAML file
   sai apply actions.json               # Execute actions from JSON file
   sai apply actions.yaml --dry-run     # Show what would be executed
@@ -48,6 +65,7 @@ type ApplyData struct {
 	Version     string        `yaml:"version" json:"version"`
 	Metadata    ApplyMetadata `yaml:"metadata" json:"metadata"`
 	Actions     []ApplyAction `yaml:"actions" json:"actions"`
+	Software    []manifest.Software `yaml:"software,omitempty" json:"software,omitempty"` // Desired state, planned before the actions
 	Variables   map[string]string `yaml:"variables,omitempty" json:"variables,omitempty"`
 	Rollback    RollbackConfig    `yaml:"rollback,omitempty" json:"rollback,omitempty"`
 }
@@ -90,6 +108,8 @@ type ApplyResult struct {
 	Failed        int                     `json:"failed"`
 	Skipped       int                     `json:"skipped"`
 	ActionResults []ApplyActionResult     `json:"action_results"`
+	Plan          []manifest.Change       `json:"plan,omitempty"`
+	Workflow      []interfaces.WorkflowVariable `json:"workflow,omitempty"` // Variables registered by the actions, in publication order
 	Duration      string                  `json:"duration"`
 	Error         string                  `json:"error,omitempty"`
 }
@@ -106,6 +126,8 @@ type ApplyActionResult struct {
 	Error       string `json:"error,omitempty"`
 	Duration    string `json:"duration"`
 	ExitCode    int    `json:"exit_code"`
+	Changes     []interfaces.Change `json:"changes,omitempty"`
+	Notes       string `json:"notes,omitempty"` // Next steps after an install
 }
 
 // executeApplyCommand implements the apply command functionality (Requirement 6.1)
@@ -143,6 +165,27 @@ func executeApplyCommand(actionFile string) error {
 		return err
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	// Plan the software section against the installed software
+	var plan []manifest.Change
+	if len(applyData.Software) > 0 {
+		plan, err = planManifest(ctx, applyData, actionManager)
+		if err != nil {
+			formatter.ShowError(err)
+			return err
+		}
+		if !flags.Quiet && !flags.JSONOutput {
+			displayPlan(plan)
+		}
+		if !manifest.HasChanges(plan) && len(applyData.Actions) == 0 {
+			formatter.ShowSuccess("Nothing to apply, the installed software matches the manifest")
+			return nil
+		}
+		applyData.Actions = append(planActions(plan), applyData.Actions...)
+	}
+
 	// Show apply file information
 	if !flags.Quiet {
 		formatter.ShowInfo(fmt.Sprintf("Applying: %s", applyData.Metadata.Name))
@@ -167,10 +210,10 @@ func executeApplyCommand(actionFile string) error {
 	}
 
 	// Execute actions
-	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
-	defer cancel()
-
 	result, err := executeApplyActions(ctx, applyData, actionManager, flags, formatter)
+	if result != nil {
+		result.Plan = plan
+	}
 	if err != nil {
 		formatter.ShowError(fmt.Errorf("apply execution failed: %w", err))
 		return err
@@ -239,8 +282,12 @@ func validateApplyData(applyData *ApplyData) error {
 		return fmt.Errorf("metadata.name is required")
 	}
 
-	if len(applyData.Actions) == 0 {
-		return fmt.Errorf("at least one action is required")
+	if len(applyData.Actions) == 0 && len(applyData.Software) == 0 {
+		return fmt.Errorf("at least one action or software is required")
+	}
+
+	if err := manifest.Validate(applyData.Software); err != nil {
+		return err
 	}
 
 	// Validate each action
@@ -286,6 +333,15 @@ func executeApplyActions(ctx context.Context, applyData *ApplyData, actionManage
 
 	startTime := time.Now()
 
+	// Variables registered by an action are available to the templates of the later ones
+	workflow := interfaces.NewWorkflow()
+	defer func() {
+		result.Workflow = workflow.Variables()
+		for i := range result.Workflow {
+			result.Workflow[i].Value = debug.MaskSecrets(result.Workflow[i].Value)
+		}
+	}()
+
 	var executedActions []ApplyActionResult
 	
 	for i, action := range applyData.Actions {
@@ -321,7 +377,8 @@ func executeApplyActions(ctx context.Context, applyData *ApplyData, actionManage
 			Quiet:     flags.Quiet,
 			Yes:       flags.Yes,
 			JSON:      flags.JSONOutput,
-			Variables: mergeVariables(applyData.Variables, action.Variables),
+			Variables: mergeVariables(mergeVariables(applyData.Variables, action.Variables), flags.Variables),
+			Workflow:  workflow,
 		}
 
 		// Set timeout if specified
@@ -352,6 +409,7 @@ func executeApplyActions(ctx context.Context, applyData *ApplyData, actionManage
 				actionResult.ExitCode = execResult.ExitCode
 				actionResult.Output = execResult.Output
 				actionResult.Provider = execResult.Provider
+				actionResult.Changes = execResult.Changes
 			}
 			result.Failed++
 
@@ -386,6 +444,8 @@ func executeApplyActions(ctx context.Context, applyData *ApplyData, actionManage
 				actionResult.Output = execResult.Output
 				actionResult.Provider = execResult.Provider
 				actionResult.ExitCode = execResult.ExitCode
+				actionResult.Changes = execResult.Changes
+				actionResult.Notes = execResult.Notes
 			}
 			result.Successful++
 
@@ -405,6 +465,62 @@ func executeApplyActions(ctx context.Context, applyData *ApplyData, actionManage
 	return result, nil
 }
 
+// planManifest compares the software section of an apply file with the software
+// installed by the available providers
+func planManifest(ctx context.Context, applyData *ApplyData, actionManager interfaces.ActionManager) ([]manifest.Change, error) {
+	installed, err := actionManager.ListInstalled(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed software: %w", err)
+	}
+
+	// The installed software is listed under its package names
+	packages := func(software string) []string {
+		saidata, err := actionManager.ResolveSoftwareData(software)
+		if err != nil {
+			return nil
+		}
+		var names []string
+		for _, pkg := range saidata.Packages {
+			names = append(names, pkg.GetPackageNameOrDefault())
+		}
+		return names
+	}
+	return manifest.Plan(applyData.Software, installed, packages), nil
+}
+
+// planActions returns the apply actions of the changes of a plan
+func planActions(plan []manifest.Change) []ApplyAction {
+	var actions []ApplyAction
+	for _, change := range plan {
+		if change.Action == "" {
+			continue
+		}
+		actions = append(actions, ApplyAction{
+			Name:        change.Action + " " + change.Software,
+			Action:      change.Action,
+			Software:    change.Software,
+			Provider:    change.Provider,
+			Variables:   change.Variables,
+			OnFailure:   "stop",
+			Description: change.Reason,
+		})
+	}
+	return actions
+}
+
+// displayPlan displays the changes of a plan as a diff, followed by their count
+func displayPlan(plan []manifest.Change) {
+	counts := make(map[string]int)
+	fmt.Println("Plan:")
+	for _, change := range plan {
+		fmt.Printf("  %s\n", change)
+		counts[change.Kind]++
+	}
+	fmt.Printf("\n%d to install, %d to upgrade, %d to remove, %d service actions, %d unchanged\n\n",
+		counts[manifest.KindAdd], counts[manifest.KindChange], counts[manifest.KindRemove],
+		counts[manifest.KindService], counts[manifest.KindUnchanged])
+}
+
 // displayApplyResults displays the results of the apply operation
 func displayApplyResults(result *ApplyResult, formatter *output.OutputFormatter, verbose bool) {
 	fmt.Println("Apply Results:")
@@ -422,6 +538,13 @@ func displayApplyResults(result *ApplyResult, formatter *output.OutputFormatter,
 		formatter.ShowError(fmt.Errorf("some actions failed"))
 	}
 
+	if verbose && len(result.Workflow) > 0 {
+		fmt.Println("\nWorkflow Variables:")
+		for _, variable := range result.Workflow {
+			fmt.Printf("  %s = %s (%s %s)\n", variable.Name, variable.Value, variable.Action, variable.Software)
+		}
+	}
+
 	if verbose && len(result.ActionResults) > 0 {
 		fmt.Println("\nAction Details:")
 		for _, actionResult := range result.ActionResults {
@@ -442,6 +565,15 @@ func displayApplyResults(result *ApplyResult, formatter *output.OutputFormatter,
 			if actionResult.Error != "" {
 				fmt.Printf("    Error: %s\n", actionResult.Error)
 			}
+			for _, change := range actionResult.Changes {
+				fmt.Printf("    Changed: %s\n", change)
+			}
+			if actionResult.Notes != "" {
+				fmt.Println("    Next steps:")
+				for _, line := range strings.Split(actionResult.Notes, "\n") {
+					fmt.Printf("      %s\n", line)
+				}
+			}
 			if verbose && actionResult.Output != "" {
 				fmt.Printf("    Output: %s\n", strings.TrimSpace(actionResult.Output))
 			}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sai/internal/interfaces"
+	"sai/internal/output"
+	"sai/internal/ui"
+)
+
+var (
+	batchKeepGoingFlag bool
+	batchJobsFlag      int
+)
+
+// addBatchFlags adds the flags of commands accepting several software
+func addBatchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&batchKeepGoingFlag, "keep-going", false, "Continue with the remaining software after a failure")
+	cmd.Flags().IntVarP(&batchJobsFlag, "jobs", "j", 0, "Software processed concurrently (default from batch.workers)")
+}
+
+// executeBatchCommand executes an action on several software, confirming once for all
+func executeBatchCommand(action string, software []string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+
+	// The progress monitor of the terminal UI replaces the output of each action
+	monitored := flags.Interactive && !flags.Quiet && ui.TUIAvailable()
+	managerFormatter := formatter
+	if monitored {
+		managerFormatter = output.NewOutputFormatter(config, false, true, false)
+	}
+
+	actionManager, userInterface, err := createManagers(config, managerFormatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize managers: %w", err))
+		return err
+	}
+
+	// Let the user drop software from the batch
+	if flags.Interactive {
+		software, err = userInterface.SelectSoftware(fmt.Sprintf("Select the software to %s", action), software)
+		if err != nil {
+			formatter.ShowError(fmt.Errorf("selection failed: %w", err))
+			return err
+		}
+		if len(software) == 0 {
+			formatter.ShowInfo("No software selected")
+			return nil
+		}
+	}
+
+	if !flags.Yes && !flags.DryRun {
+		confirmed, err := userInterface.PromptForConfirmation(fmt.Sprintf("%s %s?", action, strings.Join(software, ", ")))
+		if err != nil {
+			formatter.ShowError(fmt.Errorf("confirmation failed: %w", err))
+			return err
+		}
+		if !confirmed {
+			formatter.ShowInfo("Operation cancelled by user")
+			return nil
+		}
+	}
+
+	// Confirmed once for all software, the highest priority provider is used for each
+	options := interfaces.ActionOptions{
+		Provider:  flags.Provider,
+		DryRun:    flags.DryRun,
+		Verbose:   flags.Verbose,
+		Quiet:     flags.Quiet,
+		Yes:       true,
+		JSON:      flags.JSONOutput,
+		Config:    flags.Config,
+		Variables: flags.Variables,
+		Timeout:   config.Timeout,
+		Workers:   batchJobsFlag,
+		KeepGoing: batchKeepGoingFlag,
+		Purge:     action == "uninstall" && uninstallPurge,
+	}
+
+	// Interrupting stops scheduling the remaining software
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Follow the software being processed in the terminal UI
+	var monitor *ui.ProgressMonitor
+	if monitored {
+		monitor = userInterface.MonitorProgress(fmt.Sprintf("%s %d software", action, len(software)), software)
+		options.Progress = func(index int, result *interfaces.ActionResult) {
+			switch {
+			case result == nil:
+				monitor.Start(index)
+			case result.Success:
+				monitor.Finish(index, true, fmt.Sprintf("ok (%s, %s)", result.Provider, result.Duration.Round(time.Millisecond)))
+			default:
+				monitor.Finish(index, false, fmt.Sprintf("failed: %v", result.Error))
+			}
+		}
+	}
+
+	batch, batchErr := actionManager.ExecuteBatch(ctx, action, software, options)
+	monitor.Stop()
+
+	// Nothing was changed when every action was refused for lack of root privileges
+	if batch.Succeeded == 0 {
+		for _, result := range batch.Results {
+			if result != nil && !result.Success {
+				offerSudoReexec(result.Error, userInterface, formatter)
+				break
+			}
+		}
+	}
+
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(batch))
+	} else if !flags.Quiet {
+		for i, result := range batch.Results {
+			// The monitor already showed the result of each processed software
+			if monitor != nil && result != nil {
+				continue
+			}
+			switch {
+			case result == nil:
+				fmt.Printf("%-20s skipped\n", software[i])
+			case result.Success:
+				fmt.Printf("%-20s ok (%s, %s)\n", software[i], result.Provider, result.Duration.Round(time.Millisecond))
+			default:
+				fmt.Printf("%-20s failed: %v\n", software[i], result.Error)
+			}
+		}
+		summary := fmt.Sprintf("%d succeeded, %d failed, %d skipped in %s", batch.Succeeded, batch.Failed, batch.Skipped, batch.Duration.Round(time.Millisecond))
+		if batchErr != nil {
+			formatter.ShowError(fmt.Errorf("%s", summary))
+		} else {
+			formatter.ShowSuccess(summary)
+		}
+		for _, result := range batch.Results {
+			if result != nil && result.Success {
+				displayNotes(result.Software, result.Notes)
+			}
+		}
+	}
+
+	if batchErr != nil {
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sai/internal/output"
+)
+
+var breakerResetAll bool
+
+// breakerCmd represents the breaker command
+var breakerCmd = &cobra.Command{
+	Use:     "breaker",
+	Aliases: []string{"breakers"},
+	Short:   "Show and reset the circuit breakers of providers",
+	Long: `Show and reset the circuit breakers of provider actions.
+
+A circuit breaker opens when an action of a provider keeps failing, such as installs
+through a broken repository mirror. Breakers persist in circuit_breakers.json of the
+cache directory, so an open breaker stays open across sai runs until its cooldown
+(circuit_breaker.recovery_timeout) passes. The provider is then tried again and closes
+the breaker once it succeeds.`,
+}
+
+// breakerStatusCmd represents the breaker status command
+var breakerStatusCmd = &cobra.Command{
+	Use:   "status [provider]",
+	Short: "Show the circuit breakers with recent failures",
+	Long: `Show the circuit breakers with recent failures or not closed, of all providers or of
+one provider, with the end of the cooldown of open ones.
+
+Examples:
+  sai breaker status         # All providers
+  sai breaker status apt     # The actions of apt
+  sai breaker status --json  # For monitoring`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider := ""
+		if len(args) == 1 {
+			provider = args[0]
+		}
+		return executeBreakerStatusCommand(provider)
+	},
+}
+
+// breakerResetCmd represents the breaker reset command
+var breakerResetCmd = &cobra.Command{
+	Use:   "reset [provider [action]]",
+	Short: "Close circuit breakers",
+	Long: `Close the circuit breakers of a provider, of one of its actions, or of all providers
+with --all, once the cause of the failures is fixed.
+
+Examples:
+  sai breaker reset apt            # All actions of apt
+  sai breaker reset apt install    # Only apt install
+  sai breaker reset --all          # Every provider`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if breakerResetAll == (len(args) > 0) {
+			return fmt.Errorf("specify either a provider or --all")
+		}
+		provider, action := "", ""
+		if len(args) > 0 {
+			provider = args[0]
+		}
+		if len(args) > 1 {
+			action = args[1]
+		}
+		return executeBreakerResetCommand(provider, action)
+	},
+}
+
+func init() {
+	breakerResetCmd.Flags().BoolVar(&breakerResetAll, "all", false, "reset the circuit breakers of every provider")
+
+	breakerCmd.AddCommand(breakerStatusCmd)
+	breakerCmd.AddCommand(breakerResetCmd)
+	rootCmd.AddCommand(breakerCmd)
+}
+
+// executeBreakerStatusCommand shows the circuit breakers with recent failures
+func executeBreakerStatusCommand(provider string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, _, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	statuses := actionManager.CircuitBreakerStatuses(provider)
+	if flags.JSONOutput {
+		jsonData, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal circuit breakers to JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if len(statuses) == 0 {
+		formatter.ShowInfo("No circuit breaker has recent failures")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tACTION\tSTATE\tFAILURES\tLAST FAILURE\tOPEN UNTIL")
+	for _, status := range statuses {
+		lastFailure, openUntil := "-", "-"
+		if status.LastFailure != nil {
+			lastFailure = status.LastFailure.Local().Format(time.DateTime)
+		}
+		if status.OpenUntil != nil {
+			openUntil = fmt.Sprintf("%s (%s)", status.OpenUntil.Local().Format(time.DateTime),
+				time.Until(*status.OpenUntil).Round(time.Second))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", status.Provider, status.Action, status.State,
+			status.RecentFailures, lastFailure, openUntil)
+	}
+	return w.Flush()
+}
+
+// executeBreakerResetCommand closes the circuit breakers of a provider, or all of them
+func executeBreakerResetCommand(provider, action string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, _, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	if provider == "" {
+		actionManager.ResetCircuitBreakers()
+		return nil
+	}
+
+	reset := actionManager.ResetProviderCircuitBreakers(provider, action)
+	if flags.JSONOutput {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"provider": provider,
+			"reset":    reset,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal reset circuit breakers to JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if len(reset) == 0 {
+		formatter.ShowInfo(fmt.Sprintf("No circuit breaker of %s to reset", provider))
+		return nil
+	}
+	formatter.ShowSuccess(fmt.Sprintf("Reset the circuit breakers of %s: %s", provider, strings.Join(reset, ", ")))
+	return nil
+}
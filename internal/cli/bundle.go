@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"sai/internal/bundle"
+	"sai/internal/config"
+	"sai/internal/download"
+	"sai/internal/provider"
+	"sai/internal/saidata"
+)
+
+// downloadsMirrorDir is the directory of the cache holding the downloads imported
+// from bundles, served instead of fetching them
+const downloadsMirrorDir = "downloads"
+
+var (
+	bundleDownloads []string
+	bundleNoSaidata bool
+)
+
+// bundleCmd represents the bundle command
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Export and import bundles for systems without internet access",
+	Long: `Export and import bundles for air-gapped systems.
+
+A bundle is a tarball of the saidata repository, the provider definitions and
+optionally downloads such as binaries and installers. Export a bundle on a connected
+system, copy it over and import it, then run sai with --offline (or
+repository.offline_mode) so it never tries to reach the network: saidata updates
+are refused and action downloads are served from the imported ones.`,
+}
+
+// bundleExportCmd represents the bundle export command
+var bundleExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export saidata, providers and downloads into a bundle",
+	Long: `Export the saidata repository, the provider definitions and the downloads mirror
+into a gzipped tarball.
+
+Each --download URL is fetched into the downloads mirror first, so the actions of the
+target system find it there. Add the checksum file and signature URLs of a download as
+well when its saidata verifies it against them.
+
+Examples:
+  sai bundle export sai-bundle.tar.gz
+  sai bundle export sai-bundle.tar.gz --download https://example.com/tool-1.0-linux-amd64.tar.gz
+  sai bundle export providers.tar.gz --no-saidata`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBundleExport,
+}
+
+// bundleImportCmd represents the bundle import command
+var bundleImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a bundle",
+	Long: `Import a bundle exported by 'sai bundle export'.
+
+The saidata of the bundle replaces the local repository once validated, its provider
+definitions are added to the providers directory of the user (system-wide when
+running as root) and its downloads to the downloads mirror. Saidata imported from a
+bundle is not signature verified: only import bundles from a trusted source.
+
+Examples:
+  sai bundle import sai-bundle.tar.gz
+  sai bundle import sai-bundle.tar.gz --offline --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBundleImport,
+}
+
+func init() {
+	bundleExportCmd.Flags().StringArrayVar(&bundleDownloads, "download", nil,
+		"URL to fetch into the bundle (repeatable)")
+	bundleExportCmd.Flags().BoolVar(&bundleNoSaidata, "no-saidata", false,
+		"leave the saidata repository out of the bundle")
+
+	bundleCmd.AddCommand(bundleExportCmd)
+	bundleCmd.AddCommand(bundleImportCmd)
+	rootCmd.AddCommand(bundleCmd)
+}
+
+func runBundleExport(cmd *cobra.Command, args []string) error {
+	cfg := GetGlobalConfig()
+	flags := GetGlobalFlags()
+	mirrorDir := downloadsMirror(cfg)
+
+	if len(bundleDownloads) > 0 && cfg.Repository.OfflineMode {
+		return fmt.Errorf("%w: cannot fetch --download URLs", download.ErrOffline)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	for _, url := range bundleDownloads {
+		if !flags.Quiet && !flags.JSONOutput {
+			fmt.Printf("🔄 Downloading %s\n", url)
+		}
+		if _, err := download.Mirror(ctx, mirrorDir, url); err != nil {
+			return err
+		}
+	}
+
+	contents := bundle.Contents{
+		ProviderDirs: append([]string{"providers"}, provider.DefaultPluginDirectories()...),
+		DownloadsDir: mirrorDir,
+	}
+	if !bundleNoSaidata {
+		contents.SaidataDir = saidata.GetSaidataPath()
+		// For development/testing, the samples are the saidata in use
+		if _, err := os.Stat("docs/saidata_samples"); err == nil {
+			contents.SaidataDir = "docs/saidata_samples"
+		}
+	}
+
+	file, err := os.Create(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	manifest, err := bundle.Export(file, contents)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write bundle: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(args[0])
+		return err
+	}
+
+	return showBundleManifest(args[0], manifest, "Exported")
+}
+
+func runBundleImport(cmd *cobra.Command, args []string) error {
+	cfg := GetGlobalConfig()
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer file.Close()
+
+	repoManager := newRepositoryManager(cfg)
+	staging := repoManager.StagingPath()
+	os.RemoveAll(staging)
+	defer os.RemoveAll(staging)
+
+	manifest, err := bundle.Import(file, bundle.Targets{
+		SaidataDir:   staging,
+		ProvidersDir: bundleProvidersDir(),
+		DownloadsDir: downloadsMirror(cfg),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import bundle: %w", err)
+	}
+	if manifest.SaidataFiles > 0 {
+		if err := repoManager.ImportRepository(staging); err != nil {
+			return err
+		}
+	}
+
+	return showBundleManifest(args[0], manifest, "Imported")
+}
+
+// showBundleManifest reports the contents of an exported or imported bundle
+func showBundleManifest(path string, manifest *bundle.Manifest, verb string) error {
+	flags := GetGlobalFlags()
+	if flags.JSONOutput {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"path":     path,
+			"manifest": manifest,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal bundle report to JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+	} else if !flags.Quiet {
+		fmt.Printf("✅ %s %s: %d saidata files, %d provider definitions, %d downloads\n",
+			verb, path, manifest.SaidataFiles, manifest.ProviderFiles, manifest.Downloads)
+	}
+	return nil
+}
+
+// downloadsMirror returns the directory of the downloads served instead of fetching them
+func downloadsMirror(cfg *config.Config) string {
+	return filepath.Join(cfg.CacheDir, downloadsMirrorDir)
+}
+
+// bundleProvidersDir returns the provider directory bundles are imported into: the
+// system-wide one as root, the user's otherwise
+func bundleProvidersDir() string {
+	dirs := provider.DefaultPluginDirectories()
+	if saidata.IsRunningAsRoot() {
+		return dirs[0]
+	}
+	return dirs[len(dirs)-1]
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"sai/internal/saidata"
+)
+
+// saidataCacheDir is the directory of the cache holding the resolved saidata
+const saidataCacheDir = "saidata_cache"
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the saidata cache",
+	Long: `Manage the on-disk cache of resolved saidata.
+
+Saidata resolved from the repository, with its OS and architecture overrides applied,
+is cached in the cache directory so later runs skip parsing and validating it again.
+Entries are keyed by the content of the saidata files, expire after saidata_cache.ttl
+(7 days by default) and are cleared when the repository is updated.
+
+Use 'sai clean' to also remove temporary files and the rest of the cache.`,
+}
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove the cached saidata",
+	Long: `Remove the cached saidata, so it is resolved again from the repository.
+
+Examples:
+  sai cache clear                      # Remove the cached saidata
+  sai cache clear --json               # Output the number of removed entries in JSON format`,
+	Args: cobra.NoArgs,
+	RunE: runCacheClear,
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	cfg := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	dir := filepath.Join(cfg.CacheDir, saidataCacheDir)
+	removed, err := saidata.NewDiskCache(dir, cfg.SaidataCache.TTL).Clear()
+	if err != nil {
+		return fmt.Errorf("failed to clear the saidata cache: %w", err)
+	}
+
+	if flags.JSONOutput {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"path":    dir,
+			"removed": removed,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal cache report to JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+	} else if !flags.Quiet {
+		fmt.Printf("✅ Removed %d cached saidata entries from %s\n", removed, dir)
+	}
+	return nil
+}
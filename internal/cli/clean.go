@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sai/internal/action"
+	"sai/internal/artifacts"
+	"sai/internal/config"
+	"sai/internal/errors"
+)
+
+// runsDir is the directory of the cache holding the manifests of tracked runs
+const runsDir = "runs"
+
+var keepCacheFlag bool
+
+// cleanCmd represents the clean command
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove temporary files of past runs and evict the cache",
+	Long: `Remove the temporary files left by past runs and evict the cache in one command.
+
+Temporary files, such as extracted downloads and build directories, are removed
+automatically when a run succeeds. The files of a failed run are kept for debugging
+for cleanup.keep_failed_days days (3 by default) and pruned by later runs.
+
+This command removes:
+  1. The temporary files of every failed or interrupted run, regardless of age
+  2. Orphaned build directories, scripts and partial downloads in the temp directory
+  3. The contents of the cache directory, except the state kept across runs: the
+     saidata repositories, run manifests, imported downloads, action durations,
+     circuit breakers and cached saidata
+
+Use 'sai saidata clean' to remove the saidata repository and 'sai cache clear' to
+clear the cached saidata. Cleaning is refused in read-only mode.
+
+Examples:
+  sai clean                            # Remove temporary files and evict the cache
+  sai clean --keep-cache               # Remove temporary files only
+  sai clean --dry-run                  # Show what would be removed`,
+	RunE: runClean,
+}
+
+func init() {
+	cleanCmd.Flags().BoolVar(&keepCacheFlag, "keep-cache", false, "Only remove temporary files, keep the cache")
+	rootCmd.AddCommand(cleanCmd)
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	cfg := GetGlobalConfig()
+	flags := GetGlobalFlags()
+	if cfg.ReadOnly {
+		return errors.NewReadOnlyViolationError("clean")
+	}
+
+	report, err := newArtifactStore(cfg).Prune(0, flags.DryRun)
+	if err == nil && !keepCacheFlag {
+		err = evictCache(cfg, report)
+	}
+
+	if flags.JSONOutput {
+		jsonData, jsonErr := json.MarshalIndent(map[string]interface{}{
+			"dry_run": report.DryRun,
+			"removed": report.Removed,
+			"bytes":   report.Bytes,
+		}, "", "  ")
+		if jsonErr != nil {
+			return fmt.Errorf("failed to marshal cleanup report to JSON: %w", jsonErr)
+		}
+		fmt.Println(string(jsonData))
+	} else if !flags.Quiet {
+		verb := "Removed"
+		if report.DryRun {
+			verb = "Would remove"
+		}
+		if flags.Verbose || report.DryRun {
+			for _, path := range report.Removed {
+				fmt.Printf("  %s\n", path)
+			}
+		}
+		fmt.Printf("✅ %s %d paths (%.2f MB)\n", verb, len(report.Removed), float64(report.Bytes)/(1024*1024))
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to clean: %w", err)
+	}
+	return nil
+}
+
+// evictCache removes the contents of the cache directory, keeping the state sai keeps
+// across runs in it
+func evictCache(cfg *config.Config, report *artifacts.Report) error {
+	entries, err := os.ReadDir(cfg.CacheDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	kept := persistentCachePaths(cfg)
+	for _, entry := range entries {
+		path := filepath.Join(cfg.CacheDir, entry.Name())
+		if keepCachePath(path, kept) {
+			continue
+		}
+		if err := report.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to evict %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// persistentCachePaths returns the paths of the cache directory holding state that is
+// no cache: the run manifests, the downloads imported from bundles, the saidata
+// repositories, the durations of past actions, the circuit breakers and the cached
+// saidata, cleared by 'sai cache clear'
+func persistentCachePaths(cfg *config.Config) []string {
+	paths := []string{
+		filepath.Join(cfg.CacheDir, runsDir),
+		downloadsMirror(cfg),
+		filepath.Clean(cfg.Repository.LocalPath),
+		filepath.Join(cfg.CacheDir, "repositories"),
+		filepath.Join(cfg.CacheDir, historyDir),
+		filepath.Join(cfg.CacheDir, action.CircuitBreakerFile),
+		filepath.Join(cfg.CacheDir, saidataCacheDir),
+	}
+	for _, repository := range cfg.Repositories {
+		paths = append(paths, filepath.Clean(repository.Dir(cfg.CacheDir)))
+	}
+	return paths
+}
+
+// keepCachePath reports whether an entry of the cache directory is kept: a persistent
+// path, a directory containing one, or the backup of a persistent state file
+func keepCachePath(path string, kept []string) bool {
+	for _, keep := range kept {
+		if path == keep || strings.HasPrefix(keep, path+string(filepath.Separator)) || strings.HasPrefix(path, keep+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// newArtifactStore creates the store tracking the temporary files of runs
+func newArtifactStore(cfg *config.Config) *artifacts.Store {
+	return artifacts.NewStore(filepath.Join(cfg.CacheDir, runsDir), os.TempDir())
+}
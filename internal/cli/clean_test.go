@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/config"
+)
+
+func TestRunClean(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	cacheDir := t.TempDir()
+	globalConfig = &config.Config{
+		CacheDir:   cacheDir,
+		Repository: config.RepositoryConfig{LocalPath: filepath.Join(cacheDir, "saidata")},
+		Repositories: []config.SaidataRepositoryConfig{
+			{Name: "internal", GitURL: "https://git.example.com/saidata.git", Priority: 10},
+			{Name: "local", Path: filepath.Join(cacheDir, "local", "saidata"), Priority: 20},
+		},
+	}
+	dryRun, jsonOutput, quiet, keepCacheFlag = false, false, true, false
+
+	kept := []string{
+		"saidata/software/ng/nginx/default.yaml",
+		"repositories/internal/software/ng/nginx/default.yaml",
+		"local/saidata/software/ng/nginx/default.yaml",
+		"history/durations.json",
+		"circuit_breakers.json",
+		"circuit_breakers.json.bak",
+		"saidata_cache/nginx.json",
+		"downloads/nginx-1.27.0.tar.gz",
+	}
+	evicted := []string{"nginx-1.27.0.tar.gz", "build/nginx/Makefile"}
+	for _, path := range append(append([]string{}, kept...), evicted...) {
+		path = filepath.Join(cacheDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	}
+
+	// Cleaning is refused in read-only mode
+	globalConfig.ReadOnly = true
+	assert.Error(t, runClean(cleanCmd, nil))
+	for _, path := range evicted {
+		assert.FileExists(t, filepath.Join(cacheDir, path))
+	}
+
+	// The cache is evicted, the state kept across runs is not
+	globalConfig.ReadOnly = false
+	require.NoError(t, runClean(cleanCmd, nil))
+	for _, path := range kept {
+		assert.FileExists(t, filepath.Join(cacheDir, path))
+	}
+	for _, path := range evicted {
+		assert.NoFileExists(t, filepath.Join(cacheDir, path))
+	}
+}
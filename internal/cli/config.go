@@ -1,9 +1,17 @@
 package cli
 
 import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
 	"github.com/spf13/cobra"
+	"sai/internal/output"
 )
 
+var configShowOrigin bool
+
 // configCmd represents the config command
 var configCmd = &cobra.Command{
 	Use:   "config [software]",
@@ -18,13 +26,100 @@ Examples:
   sai config nginx                     # Show nginx configuration files
   sai config nginx --verbose           # Show detailed configuration information
   sai config nginx --json              # Output configuration in JSON format
-  sai config nginx --provider systemd  # Use specific provider for configuration management`,
+  sai config nginx --provider systemd  # Use specific provider for configuration management
+  sai config show --origin             # Show the configuration of sai itself`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return executeServiceCommand("config", args[0])
 	},
 }
 
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show [key]",
+	Short: "Show the effective configuration of sai",
+	Long: `Show the effective configuration of sai, or the values under a key such as output.
+
+The configuration is resolved from layers, each overriding the values set by the
+previous ones:
+
+  default  built-in defaults
+  system   /etc/sai/config.yaml (or /usr/local/etc/sai/config.yaml)
+  user     ~/.sai/config.yaml (or ~/.config/sai/config.yaml)
+  project  ./sai.yaml (or ./.sai/config.yaml)
+  env      SAI_* environment variables
+  flag     command line flags
+
+Only the first existing file of each layer is read. --config reads the given file in
+place of the system, user and project files.
+
+Examples:
+  sai config show                    # All values
+  sai config show --origin           # With the layer that set each value
+  sai config show output --origin    # The output settings
+  sai config show --json             # For scripts`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := ""
+		if len(args) == 1 {
+			key = args[0]
+		}
+		return executeConfigShowCommand(key, configShowOrigin)
+	},
+}
+
 func init() {
+	configShowCmd.Flags().BoolVar(&configShowOrigin, "origin", false, "show the layer and the file, variable or flag that set each value")
+
+	configCmd.AddCommand(configShowCmd)
 	rootCmd.AddCommand(configCmd)
-}
\ No newline at end of file
+}
+
+// executeConfigShowCommand shows the effective configuration, the values under key
+// when given
+func executeConfigShowCommand(key string, origin bool) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	values, err := config.Values()
+	if err != nil {
+		formatter.ShowError(err)
+		return err
+	}
+
+	if key != "" {
+		filtered := values[:0]
+		for _, value := range values {
+			if value.Key == key || strings.HasPrefix(value.Key, key+".") {
+				filtered = append(filtered, value)
+			}
+		}
+		if len(filtered) == 0 {
+			err := fmt.Errorf("unknown configuration key %s", key)
+			formatter.ShowError(err)
+			return err
+		}
+		values = filtered
+	}
+
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(values))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if origin {
+		fmt.Fprintln(w, "KEY\tVALUE\tORIGIN")
+	} else {
+		fmt.Fprintln(w, "KEY\tVALUE")
+	}
+	for _, value := range values {
+		if origin {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", value.Key, value.Value, value.Origin)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\n", value.Key, value.Value)
+		}
+	}
+	return w.Flush()
+}
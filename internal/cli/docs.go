@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sai/internal/docserver"
+	"sai/internal/output"
+)
+
+var docsListenFlag string
+
+// docsCmd represents the docs command
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Explore the sai data model",
+	Long:  `Explore the template functions, providers and saidata that sai works with.`,
+}
+
+// docsServeCmd represents the docs serve command
+var docsServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a local documentation web UI",
+	Long: `Serve a local web UI documenting the sai data model:
+
+  • The template functions available to provider templates
+  • A reference of the loaded providers, their actions and YAML
+  • The effective saidata of any software, after merging overrides
+
+The same information is served as JSON under /api. The server listens on the
+loopback interface by default and runs until interrupted.
+
+Examples:
+  sai docs serve                       # Serve on http://127.0.0.1:8808
+  sai docs serve --listen :9000        # Serve on another address`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeDocsServeCommand(docsListenFlag)
+	},
+}
+
+func init() {
+	docsServeCmd.Flags().StringVar(&docsListenFlag, "listen", "127.0.0.1:8808", "Address to listen on")
+	docsCmd.AddCommand(docsServeCmd)
+	rootCmd.AddCommand(docsCmd)
+}
+
+// executeDocsServeCommand serves the documentation until interrupted
+func executeDocsServeCommand(address string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, _, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	docs := docserver.NewServer(actionManager.GetProviderManager(), actionManager.ResolveSoftwareData)
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to listen on %s: %w", address, err))
+		return err
+	}
+
+	server := &http.Server{
+		Handler:           docs.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	formatter.ShowSuccess(fmt.Sprintf("Serving documentation on http://%s (press Ctrl+C to stop)", listener.Addr()))
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("documentation server failed: %w", err)
+	}
+	return nil
+}
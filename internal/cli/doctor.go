@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sai/internal/config"
+	"sai/internal/doctor"
+	"sai/internal/interfaces"
+	"sai/internal/output"
+	"sai/internal/provider"
+	"sai/internal/saidata"
+	"sai/internal/transaction"
+	"sai/internal/types"
+	"sai/internal/validation"
+)
+
+// doctorFailureWindow is how far back failed transactions are reported
+const doctorFailureWindow = 24 * time.Hour
+
+var doctorSkipNetwork bool
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the environment sai runs in",
+	Long: `Diagnose the environment sai runs in and print how to fix each problem found:
+
+  providers    provider detection, and whether the default provider is available
+  saidata      health and age of the saidata repository, and schema validation of the
+               definitions modified locally
+  environment  PATH entries that let other users substitute provider commands
+  permissions  write access to the cache, managed, transaction and lock directories
+  network      reachability of the saidata repository (skipped in offline mode)
+  failures     open circuit breakers and actions that failed in the last 24 hours
+
+The exit code is 1 when a check fails.
+
+Examples:
+  sai doctor                  # Run all checks
+  sai doctor --skip-network   # Without the network checks
+  sai doctor --json           # For monitoring`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeDoctorCommand()
+	},
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorSkipNetwork, "skip-network", false, "skip the network reachability checks")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// executeDoctorCommand runs the checks and prints the report
+func executeDoctorCommand() error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, _, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	report := &doctor.Report{}
+	checkProviders(report, actionManager.GetProviderManager(), config)
+	checkSaidata(report, config)
+	doctor.CheckPath(report, os.Getenv("PATH"))
+	doctor.CheckWritable(report, "cache directory", config.CacheDir)
+	doctor.CheckWritable(report, "managed directory", config.ManagedDir)
+	doctor.CheckWritable(report, "transaction directory", config.Transactions.Dir)
+	doctor.CheckWritable(report, "lock directory", config.Locks.Dir)
+	if !doctorSkipNetwork && !config.Repository.OfflineMode {
+		doctor.CheckReachable(ctx, report, "saidata repository", config.Repository.GitURL)
+		doctor.CheckReachable(ctx, report, "saidata zip fallback", config.Repository.ZipFallbackURL)
+	}
+	checkFailures(report, actionManager)
+
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(report))
+	} else {
+		displayDoctorReport(report, formatter)
+	}
+
+	if !report.Healthy() {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// checkProviders reports the provider detection statistics of the host
+func checkProviders(report *doctor.Report, providerManager interfaces.ProviderManager, config *config.Config) {
+	const category = "providers"
+
+	available := providerManager.GetAvailableProviders()
+	names := make([]string, 0, len(available))
+	for _, p := range available {
+		names = append(names, p.Provider.Name)
+	}
+	sort.Strings(names)
+
+	total := len(providerManager.GetAllProviders())
+	if detector, ok := providerManager.(interface {
+		GetDetectionStats() *provider.DetectionStats
+	}); ok {
+		stats := detector.GetDetectionStats()
+		total = stats.TotalProviders
+		if stats.PlatformCompatible > 0 {
+			total = stats.PlatformCompatible
+		}
+	}
+
+	if len(names) == 0 {
+		report.Fail(category, "detection", fmt.Sprintf("no provider is available out of %d", total),
+			"Run 'sai providers bootstrap' to install the package managers of the platform")
+	} else {
+		report.OK(category, "detection", fmt.Sprintf("%d of %d platform providers available: %s", len(names), total, strings.Join(names, ", ")))
+	}
+
+	if config.DefaultProvider != "" && !providerManager.IsProviderAvailable(config.DefaultProvider) {
+		report.Fail(category, "default provider", fmt.Sprintf("%s is not available", config.DefaultProvider),
+			fmt.Sprintf("Run 'sai providers bootstrap %s' or change default_provider in the configuration", config.DefaultProvider))
+	}
+}
+
+// checkSaidata reports the health of the saidata repository and validates the
+// definitions modified locally
+func checkSaidata(report *doctor.Report, config *config.Config) {
+	const category = "saidata"
+
+	status, err := newRepositoryManager(config).GetRepositoryStatus()
+	if err != nil || !status.IsHealthy {
+		report.Fail(category, "repository", "no valid saidata repository found",
+			"Run 'sai saidata init' to download it, or 'sai saidata clean' and 'sai saidata init' if it is damaged")
+		return
+	}
+
+	message := fmt.Sprintf("%s (%s, %d files)", status.LocalPath, status.Type, status.FileCount)
+	age := time.Since(status.LastUpdate)
+	if interval := config.Repository.UpdateInterval; interval > 0 && age > interval && !config.Repository.OfflineMode {
+		report.Warn(category, "repository", fmt.Sprintf("%s, last updated %s ago", message, age.Round(time.Hour)),
+			"Run 'sai saidata update'")
+	} else {
+		report.OK(category, "repository", message)
+	}
+
+	changed, err := saidata.LocalChanges(status.LocalPath)
+	if err != nil {
+		report.Warn(category, "local overrides", fmt.Sprintf("local changes could not be listed: %v", err), "")
+		return
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	validator, _ := validation.NewSaidataValidator("schemas/saidata-0.2-schema.json")
+	var invalid []string
+	for _, path := range changed {
+		if err := validateSaidataFile(filepath.Join(status.LocalPath, path), validator); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+	if len(invalid) > 0 {
+		report.Fail(category, "local overrides", fmt.Sprintf("%d of %d locally modified definitions are invalid:\n%s", len(invalid), len(changed), strings.Join(invalid, "\n")),
+			"Fix the definitions, or discard the changes with 'git checkout' in "+status.LocalPath)
+		return
+	}
+	report.OK(category, "local overrides", fmt.Sprintf("%d locally modified definitions are valid", len(changed)))
+}
+
+// validateSaidataFile validates a default.yaml against the schema, when a validator is
+// available, and checks that other definitions such as OS overrides parse
+func validateSaidataFile(path string, validator *validation.SaidataValidator) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if filepath.Base(path) == "default.yaml" && validator != nil {
+		return validator.ValidateSaidataYAML(data)
+	}
+	_, err = types.LoadSoftwareDataFromYAML(data)
+	return err
+}
+
+// checkFailures reports the open circuit breakers and the recently failed actions
+func checkFailures(report *doctor.Report, actionManager interfaces.ActionManager) {
+	const category = "failures"
+
+	open := 0
+	for _, status := range actionManager.CircuitBreakerStatuses("") {
+		if status.State != "open" {
+			continue
+		}
+		open++
+		report.Warn(category, "circuit breaker", fmt.Sprintf("%s %s is skipped after %d recent failures", status.Provider, status.Action, status.RecentFailures),
+			fmt.Sprintf("Fix the cause (see 'sai logs'), then run 'sai breaker reset %s %s'", status.Provider, status.Action))
+	}
+
+	failed := 0
+	if transactions, err := actionManager.GetTransactions(); err == nil {
+		for _, tx := range transactions {
+			if tx.Status != transaction.StatusFailed || time.Since(tx.StartedAt) > doctorFailureWindow {
+				continue
+			}
+			failed++
+			report.Warn(category, "failed action", fmt.Sprintf("%s %s with %s failed at %s: %s", tx.Action, tx.Software, tx.Provider, tx.StartedAt.Local().Format(time.DateTime), tx.Error),
+				fmt.Sprintf("Run 'sai rollback %s' to undo its changes once the cause is fixed", tx.ID))
+		}
+	}
+
+	if open == 0 && failed == 0 {
+		report.OK(category, "recent failures", "no open circuit breaker nor failed action in the last 24 hours")
+	}
+}
+
+// displayDoctorReport prints the checks grouped by category with their remediation
+func displayDoctorReport(report *doctor.Report, formatter *output.OutputFormatter) {
+	icons := map[string]string{
+		doctor.StatusOK:      "✓",
+		doctor.StatusWarning: "⚠",
+		doctor.StatusError:   "✗",
+	}
+
+	category := ""
+	for _, check := range report.Checks {
+		if check.Category != category {
+			category = check.Category
+			fmt.Printf("\n%s\n", strings.ToUpper(category[:1])+category[1:])
+		}
+		lines := strings.Split(check.Message, "\n")
+		fmt.Printf("  %s %s: %s\n", icons[check.Status], check.Name, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Printf("      %s\n", line)
+		}
+		if check.Remediation != "" {
+			fmt.Printf("      → %s\n", check.Remediation)
+		}
+	}
+	fmt.Println()
+
+	errors, warnings := report.Count(doctor.StatusError), report.Count(doctor.StatusWarning)
+	switch {
+	case errors > 0:
+		formatter.ShowError(fmt.Errorf("%d problems and %d warnings found", errors, warnings))
+	case warnings > 0:
+		formatter.ShowWarning(fmt.Sprintf("No problem found, %d warnings", warnings))
+	default:
+		formatter.ShowSuccess("No problem found")
+	}
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sai/internal/output"
+)
+
+var formatFlag string
+
+// addFormatFlag adds the --format flag of list commands
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&formatFlag, "format", "", "Format each result with a Go template, e.g. '{{.Provider}}:{{.Version}}'")
+}
+
+// printFormatted prints results formatted with the --format template
+func printFormatted(results interface{}) error {
+	formatted, err := output.FormatTemplate(formatFlag, results)
+	if err != nil {
+		return err
+	}
+	fmt.Print(formatted)
+	return nil
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sai/internal/audit"
+	"sai/internal/config"
+	"sai/internal/output"
+	"sai/internal/ui"
+)
+
+// historyCommandWidth is the width commands are cut to in the history table
+const historyCommandWidth = 60
+
+var (
+	historyAction string
+	historySince  string
+	historyFailed bool
+	historyLimit  int
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [software]",
+	Short: "Show the commands recorded in the audit log",
+	Long: `Show the commands sai ran, from the audit log: every command executed by an action
+or a provider bootstrap, and every command a dry run showed, with the user who ran it,
+its provider, exit code and duration.
+
+The log is an append-only JSON Lines file (audit.path, /var/log/sai/audit.jsonl as
+root, ~/.sai/audit.jsonl otherwise) rotated by size (audit.max_size, audit.max_files),
+for compliance in regulated environments. Secrets are masked before they are recorded.
+
+--since takes a duration back from now (24h, 7d) or a date (2024-01-31). --provider
+selects the commands of one provider. Without --limit the 50 most recent commands are
+shown, --limit 0 shows all.
+
+Examples:
+  sai history                          # The 50 most recent commands
+  sai history nginx                    # Commands run for nginx
+  sai history --action install --since 7d --failed
+  sai history --provider apt --limit 0 --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		software := ""
+		if len(args) == 1 {
+			software = args[0]
+		}
+		return executeHistoryCommand(software)
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyAction, "action", "", "only show the commands of this action")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "only show the commands from this long ago (24h, 7d) or this date (2024-01-31)")
+	historyCmd.Flags().BoolVar(&historyFailed, "failed", false, "only show the commands that failed")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 50, "number of most recent commands shown, 0 for all")
+	rootCmd.AddCommand(historyCmd)
+}
+
+// auditLog returns the audit log of the configuration, nil when disabled
+func auditLog(cfg *config.Config) *audit.Log {
+	if !cfg.Audit.Enabled {
+		return nil
+	}
+	return audit.NewLog(cfg.Audit.Path, cfg.Audit.MaxSize, cfg.Audit.MaxFiles)
+}
+
+// executeHistoryCommand shows the commands of the audit log selected by the flags
+func executeHistoryCommand(software string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+
+	since, err := parseSince(historySince, time.Now())
+	if err != nil {
+		return err
+	}
+	if historyLimit < 0 {
+		return fmt.Errorf("--limit cannot be negative")
+	}
+
+	// The log is read even when recording was disabled since
+	log := audit.NewLog(config.Audit.Path, config.Audit.MaxSize, config.Audit.MaxFiles)
+	entries, err := log.Query(audit.Filter{
+		Software: software,
+		Provider: flags.Provider,
+		Action:   historyAction,
+		Since:    since,
+		Failed:   historyFailed,
+		Limit:    historyLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if flags.JSONOutput {
+		if entries == nil {
+			entries = []audit.Entry{}
+		}
+		fmt.Println(formatter.FormatJSON(entries))
+		return nil
+	}
+	if len(entries) == 0 {
+		if !config.Audit.Enabled {
+			formatter.ShowInfo(fmt.Sprintf("No commands recorded in %s, the audit log is disabled (audit.enabled)", log.Path()))
+		} else {
+			formatter.ShowInfo(fmt.Sprintf("No commands recorded in %s", log.Path()))
+		}
+		return nil
+	}
+
+	var rows [][]string
+	for _, entry := range entries {
+		user := entry.User
+		if entry.SudoUser != "" {
+			user = fmt.Sprintf("%s (sudo %s)", entry.User, entry.SudoUser)
+		}
+		exit := strconv.Itoa(entry.ExitCode)
+		if entry.DryRun {
+			exit = "dry-run"
+		}
+		command := strings.Join(strings.Fields(entry.Command), " ")
+		if !flags.Verbose && len(command) > historyCommandWidth {
+			command = command[:historyCommandWidth-3] + "..."
+		}
+		rows = append(rows, []string{
+			entry.Time.Local().Format("2006-01-02 15:04:05"),
+			user,
+			entry.Provider,
+			entry.Action,
+			entry.Software,
+			exit,
+			entry.Duration().Round(time.Millisecond).String(),
+			command,
+		})
+	}
+	ui.NewUserInterface(config, formatter).ShowTable(
+		[]string{"Time", "User", "Provider", "Action", "Software", "Exit", "Duration", "Command"}, rows)
+	return nil
+}
+
+// parseSince parses --since, a duration back from now with days allowed (7d) or a
+// date, returning the zero time when empty
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q, use a duration such as 24h or 7d, or a date such as 2024-01-31", value)
+}
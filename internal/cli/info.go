@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"sai/internal/interfaces"
@@ -22,9 +23,13 @@ The info command will query all available providers and display:
 - License information
 - Dependencies (if available)
 
+Providers reporting the same package name and version are shown once, with the list
+of these providers, unless --per-provider is given or output.merge_results is disabled.
+
 Examples:
   sai info nginx                       # Get info about nginx from all providers
   sai info nginx --provider apt        # Get info about nginx only from apt
+  sai info nginx --per-provider        # Show the info of every provider separately
   sai info nginx --json                # Output info in JSON format`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -32,6 +37,15 @@ Examples:
 	},
 }
 
+var perProviderFlag bool
+
+// mergedSoftwareInfo is software information reported by one or more providers with
+// the same package name and version
+type mergedSoftwareInfo struct {
+	*interfaces.SoftwareInfo
+	Providers []string
+}
+
 func executeInfoCommand(software string) error {
 	// Get global configuration and flags
 	config := GetGlobalConfig()
@@ -86,12 +100,21 @@ func executeInfoCommand(software string) error {
 		formatter.ShowInfo(fmt.Sprintf("Information for '%s' from %d provider(s):", software, len(infoResults)))
 		fmt.Println()
 
-		for i, info := range infoResults {
+		mergedResults := mergeSoftwareInfo(infoResults, perProviderFlag || !config.Output.MergeResults)
+		for i, info := range mergedResults {
 			if i > 0 {
 				fmt.Println("---")
 			}
 
-			fmt.Printf("Provider: %s\n", formatter.FormatProviderName(info.Provider))
+			if len(info.Providers) > 1 {
+				var providers []string
+				for _, provider := range info.Providers {
+					providers = append(providers, formatter.FormatProviderName(provider))
+				}
+				fmt.Printf("Providers: %s\n", strings.Join(providers, ", "))
+			} else {
+				fmt.Printf("Provider: %s\n", formatter.FormatProviderName(info.Provider))
+			}
 			fmt.Printf("Package:  %s\n", info.PackageName)
 			
 			if info.Version != "" && info.Version != "unknown" {
@@ -121,6 +144,43 @@ func executeInfoCommand(software string) error {
 	return nil
 }
 
+// mergeSoftwareInfo collapses the results of providers reporting the same package name
+// and version, in the order the first of them was reported. Details missing from the
+// first result are taken from the others. With perProvider, every result is kept.
+func mergeSoftwareInfo(infoResults []*interfaces.SoftwareInfo, perProvider bool) []*mergedSoftwareInfo {
+	var merged []*mergedSoftwareInfo
+	byKey := make(map[string]*mergedSoftwareInfo)
+
+	for _, info := range infoResults {
+		key := info.PackageName + "\x00" + info.Version
+		existing, exists := byKey[key]
+		if perProvider || !exists {
+			copied := *info
+			entry := &mergedSoftwareInfo{SoftwareInfo: &copied, Providers: []string{info.Provider}}
+			merged = append(merged, entry)
+			byKey[key] = entry
+			continue
+		}
+
+		existing.Providers = append(existing.Providers, info.Provider)
+		if existing.Description == "" {
+			existing.Description = info.Description
+		}
+		if existing.Homepage == "" {
+			existing.Homepage = info.Homepage
+		}
+		if existing.License == "" || existing.License == "unknown" {
+			existing.License = info.License
+		}
+		if len(existing.Dependencies) == 0 {
+			existing.Dependencies = info.Dependencies
+		}
+	}
+
+	return merged
+}
+
 func init() {
+	infoCmd.Flags().BoolVar(&perProviderFlag, "per-provider", false, "Show the info of every provider separately instead of merging identical results")
 	rootCmd.AddCommand(infoCmd)
 }
\ No newline at end of file
@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"sai/internal/action"
 	"sai/internal/config"
+	"sai/internal/history"
 	"sai/internal/interfaces"
 	"sai/internal/output"
 	"sai/internal/provider"
@@ -16,11 +20,15 @@ import (
 	"sai/internal/template"
 	"sai/internal/validation"
 	"sai/internal/ui"
+	"sai/internal/version"
 )
 
+// historyDir is the directory of the cache holding the durations of past actions
+const historyDir = "history"
+
 // installCmd represents the install command
 var installCmd = &cobra.Command{
-	Use:   "install [software]",
+	Use:   "install [software...]",
 	Short: "Install software packages",
 	Long: `Install software packages using the appropriate provider.
 The system will detect the best provider automatically or use the one specified with --provider.
@@ -32,14 +40,51 @@ Examples:
   sai install nginx                    # Install nginx using best available provider
   sai install nginx --provider apt     # Install nginx using apt provider
   sai install nginx --yes              # Install nginx without confirmation prompts
-  sai install nginx --dry-run          # Show what would be executed without installing`,
-	Args: cobra.ExactArgs(1),
+  sai install nginx --dry-run          # Show what would be executed without installing
+  sai install nginx redis curl --yes   # Install several software concurrently
+  sai install nginx redis --keep-going # Continue with the others when one fails
+  sai install nginx@1.24               # Install nginx 1.24.x
+  sai install 'nginx@>=1.20 <1.25'     # Install a version satisfying a constraint
+
+A version requested with software@version is either pinned, such as 1.24 or 1.24.x
+matching any 1.24 release, or a constraint: terms with >=, >, <=, < or != all
+satisfied, ~1.24.1 for 1.24.1 or a later 1.24 release, ^1.2 for 1.2 or a later 1.x
+release. Package managers installing exact versions only refuse constraints. The
+installed version is checked afterwards with the version action of the provider, the
+install failing when it does not satisfy the request or cannot be checked.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, arg := range args {
+			if _, _, err := splitVersionArg(arg); err != nil {
+				return err
+			}
+		}
+		if len(args) > 1 {
+			return executeBatchCommand("install", args)
+		}
 		return executeInstallCommand(args[0])
 	},
 }
 
-func executeInstallCommand(software string) error {
+// splitVersionArg splits a software@version argument into the software and the
+// requested version, empty when none, checking that the version can be parsed
+func splitVersionArg(arg string) (string, string, error) {
+	software, spec := version.Split(arg)
+	if spec == "" {
+		return software, "", nil
+	}
+	if _, err := version.Parse(spec); err != nil {
+		return "", "", fmt.Errorf("%s: %w", software, err)
+	}
+	return software, spec, nil
+}
+
+func executeInstallCommand(arg string) error {
+	software, requestedVersion, err := splitVersionArg(arg)
+	if err != nil {
+		return err
+	}
+
 	// Get global configuration and flags
 	config := GetGlobalConfig()
 	flags := GetGlobalFlags()
@@ -63,8 +108,9 @@ func executeInstallCommand(software string) error {
 		Yes:       flags.Yes,
 		JSON:      flags.JSONOutput,
 		Config:    flags.Config,
-		Variables: make(map[string]string),
+		Variables: flags.Variables,
 		Timeout:   config.Timeout,
+		Version:   requestedVersion,
 	}
 
 	// Provider selection is now handled by the Action Manager (Requirements 15.1, 15.3, 15.4)
@@ -73,9 +119,9 @@ func executeInstallCommand(software string) error {
 	// Show progress
 	if !flags.Quiet {
 		if flags.DryRun {
-			formatter.ShowProgress(fmt.Sprintf("Dry run: Installing %s...", software))
+			formatter.ShowProgress(fmt.Sprintf("Dry run: Installing %s...", arg))
 		} else {
-			formatter.ShowProgress(fmt.Sprintf("Installing %s...", software))
+			formatter.ShowProgress(fmt.Sprintf("Installing %s...", arg))
 		}
 	}
 
@@ -85,6 +131,7 @@ func executeInstallCommand(software string) error {
 
 	result, err := actionManager.ExecuteAction(ctx, "install", software, options)
 	if err != nil {
+		offerSudoReexec(err, userInterface, formatter)
 		formatter.ShowError(fmt.Errorf("installation failed: %w", err))
 		os.Exit(result.ExitCode)
 		return err
@@ -121,14 +168,17 @@ func executeInstallCommand(software string) error {
 			if flags.DryRun {
 				formatter.ShowSuccess(fmt.Sprintf("Dry run completed for %s", software))
 			} else {
-				formatter.ShowSuccess(fmt.Sprintf("Successfully installed %s using %s", software, result.Provider))
+				formatter.ShowSuccess(fmt.Sprintf("Successfully installed %s using %s", result.Software, result.Provider))
+				if !flags.Quiet {
+					displayNotes(result.Software, result.Notes)
+				}
 			}
 		} else {
 			formatter.ShowError(fmt.Errorf("failed to install %s: %s", software, result.Error))
 		}
 
-		// Show command output if verbose
-		if flags.Verbose && result.Output != "" {
+		// Show command output if verbose, unless it was streamed as it ran
+		if flags.Verbose && result.Output != "" && !result.Streamed {
 			fmt.Println("\nCommand output:")
 			fmt.Println(result.Output)
 		}
@@ -142,20 +192,31 @@ func executeInstallCommand(software string) error {
 	return nil
 }
 
-// createManagers creates and initializes all required managers
-func createManagers(cfg *config.Config, formatter *output.OutputFormatter) (interfaces.ActionManager, *ui.UserInterface, error) {
-	// Create provider manager
-	providerConfig := &provider.ManagerConfig{
+// newProviderManager creates the provider manager loading the providers and plugins
+func newProviderManager(cfg *config.Config) (*provider.ProviderManager, error) {
+	providerManager, err := provider.NewProviderManager(&provider.ManagerConfig{
 		ProviderDirectory: "providers",
+		PluginDirectories: provider.DefaultPluginDirectories(),
 		SchemaPath:        "schemas/providerdata-0.1-schema.json",
 		DefaultProvider:   cfg.DefaultProvider,
 		ProviderPriority:  cfg.ProviderPriority,
 		EnableWatching:    false,
+		WSLPrefer:         cfg.WSLPrefer,
+		VerifyExecutables: cfg.VerifyExecutables,
+		Executables:       cfg.ExecutableOverrides(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider manager: %w", err)
 	}
+	return providerManager, nil
+}
 
-	providerManager, err := provider.NewProviderManager(providerConfig)
+// createManagers creates and initializes all required managers
+func createManagers(cfg *config.Config, formatter *output.OutputFormatter) (interfaces.ActionManager, *ui.UserInterface, error) {
+	// Create provider manager
+	providerManager, err := newProviderManager(cfg)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create provider manager: %w", err)
+		return nil, nil, err
 	}
 
 	// Create saidata manager with automatic bootstrap
@@ -163,13 +224,28 @@ func createManagers(cfg *config.Config, formatter *output.OutputFormatter) (inte
 	
 	// For development/testing, check if docs/saidata_samples exists and use it
 	if _, err := os.Stat("docs/saidata_samples"); err == nil {
-		saidataManager = saidata.NewManager("docs/saidata_samples")
+		manager := newSaidataManager(cfg, "docs/saidata_samples")
+		closers = append(closers, manager.Close)
+		saidataManager = manager
 	} else {
-		// Use bootstrap system for production
-		manager, err := saidata.NewManagerWithBootstrap(cfg.Repository.GitURL, cfg.Repository.ZipFallbackURL)
+		// Use bootstrap system for production, interrupting cancels the first download
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		manager, err := saidata.NewManagerWithBootstrapContext(ctx, saidata.BootstrapOptions{
+			GitURL:         cfg.Repository.GitURL,
+			ZipFallbackURL: cfg.Repository.ZipFallbackURL,
+			Sparse:         sparseFilter(cfg),
+			Verification:   signatureVerification(cfg),
+			Disabled:       noBootstrap || cfg.Repository.OfflineMode,
+			Progress:       bootstrapProgress(),
+		})
+		stop()
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to initialize saidata manager: %w", err)
 		}
+		manager.SetDiskCache(saidataCache(cfg))
+		manager.SetOffline(cfg.Repository.OfflineMode)
+		manager.SetRepositories(saidataRepositories(cfg))
+		closers = append(closers, manager.Close)
 		saidataManager = manager
 	}
 
@@ -181,6 +257,8 @@ func createManagers(cfg *config.Config, formatter *output.OutputFormatter) (inte
 
 	// Create command executor
 	commandExecutor := executor.NewCommandExecutor(logger, resourceValidator)
+	commandExecutor.SetAuditLog(auditLog(cfg))
+	commandExecutor.SetSandbox(commandSandbox(cfg))
 
 	// Create template engine with real implementation
 	templateEngine := template.NewTemplateEngine(nil, nil)
@@ -192,9 +270,35 @@ func createManagers(cfg *config.Config, formatter *output.OutputFormatter) (inte
 		logger,
 		resourceValidator,
 	)
+	genericExecutor.SetReadOnly(cfg.ReadOnly, cfg.IsInformationOnlyAction)
+	genericExecutor.SetExecutableResolver(providerManager.GetExecutablePath)
+	genericExecutor.SetRequireChecksums(cfg.Security.RequireChecksums)
+	genericExecutor.SetBuildCache(cfg.Builds.CacheDir, cfg.Builds.Jobs)
+	if cfg.Checkpoints.Enabled {
+		genericExecutor.SetCheckpointDir(cfg.Checkpoints.Dir)
+	}
+	genericExecutor.SetRoot(cfg.Root)
+	genericExecutor.SetHost(providerManager.GetOSInfo(), providerManager.DefaultPackageManager)
+	genericExecutor.SetExtraArgs(cfg.ExtraArgs())
+	if cfg.AdaptiveTimeout.Enabled {
+		genericExecutor.SetAdaptiveTimeouts(executor.NewAdaptiveTimeouts(
+			history.NewDurationStore(filepath.Join(cfg.CacheDir, historyDir)),
+			cfg.AdaptiveTimeout.Ceiling,
+			cfg.AdaptiveTimeout.StallTimeout,
+			cfg.AdaptiveTimeout.MinThroughput,
+		))
+	}
+	// Track temporary files of runs, pruning those kept from old failures unless the
+	// run must not change anything
+	artifactStore := newArtifactStore(cfg)
+	if !GetGlobalFlags().DryRun && !cfg.ReadOnly {
+		artifactStore.Prune(cfg.Cleanup.KeepFailed(), false)
+	}
+	genericExecutor.SetArtifactStore(artifactStore)
 
 	// Create UI using the provided formatter
 	userInterface := ui.NewUserInterface(cfg, formatter)
+	userInterface.SetTUI(GetGlobalFlags().Interactive)
 
 	// Create action manager
 	actionManager := action.NewActionManager(
@@ -211,6 +315,23 @@ func createManagers(cfg *config.Config, formatter *output.OutputFormatter) (inte
 	return actionManager, userInterface, nil
 }
 
+// commandSandbox returns the restrictions of the commands rendered from provider
+// templates selected by the security configuration
+func commandSandbox(cfg *config.Config) executor.Sandbox {
+	sandbox := cfg.Security.Sandbox
+	return executor.Sandbox{
+		User:            sandbox.User,
+		RestrictEnv:     sandbox.RestrictEnv,
+		AllowedEnv:      sandbox.AllowedEnv,
+		Isolation:       sandbox.Isolation,
+		Namespaces:      sandbox.Namespaces,
+		Target:          sandbox.Target,
+		AllowedCommands: sandbox.AllowedCommands,
+		AllowScripts:    sandbox.AllowScripts,
+	}
+}
+
 func init() {
+	addBatchFlags(installCmd)
 	rootCmd.AddCommand(installCmd)
 }
\ No newline at end of file
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sai/internal/managed"
+	"sai/internal/output"
+	"sai/internal/ui"
+)
+
+// inventoryCmd represents the inventory command
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory [software]",
+	Short: "List software managed by sai",
+	Long: `List software that was installed and is managed by sai.
+SAI records a marker for every successful install (provider, packages, version and
+timestamp) and removes it on uninstall, so administrators can tell which software
+sai manages versus what was installed manually.
+
+This is an information-only command that executes without confirmation prompts.
+
+Examples:
+  sai inventory                        # List all software managed by sai
+  sai inventory nginx                  # Show the marker recorded for nginx
+  sai inventory --provider apt         # List software managed through apt
+  sai inventory --json                 # Output inventory in JSON format
+  sai inventory --format '{{.Software}} {{.Version}}'   # Custom output`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		software := ""
+		if len(args) > 0 {
+			software = args[0]
+		}
+		return executeInventoryCommand(software)
+	},
+}
+
+func init() {
+	addFormatFlag(inventoryCmd)
+	rootCmd.AddCommand(inventoryCmd)
+}
+
+// executeInventoryCommand displays the "managed by sai" markers recorded on this system
+func executeInventoryCommand(software string) error {
+	// Get global configuration and flags
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	// Create output formatter
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	userInterface := ui.NewUserInterface(config, formatter)
+
+	markerStore := managed.NewMarkerStore(config.ManagedDir)
+
+	var markers []*managed.Marker
+	if software != "" {
+		marker, err := markerStore.Get(software)
+		if err != nil {
+			formatter.ShowError(fmt.Errorf("%s is not managed by sai", software))
+			return err
+		}
+		markers = []*managed.Marker{marker}
+	} else {
+		var err error
+		markers, err = markerStore.List()
+		if err != nil {
+			formatter.ShowError(fmt.Errorf("failed to read inventory: %w", err))
+			return err
+		}
+	}
+
+	// Filter results by provider if specified
+	if flags.Provider != "" {
+		var filtered []*managed.Marker
+		for _, marker := range markers {
+			if marker.Provider == flags.Provider {
+				filtered = append(filtered, marker)
+			}
+		}
+		markers = filtered
+	}
+
+	// Display results
+	if formatFlag != "" {
+		return printFormatted(markers)
+	}
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(map[string]interface{}{
+			"type":        "managed_inventory",
+			"managed_dir": markerStore.Dir(),
+			"software":    markers,
+			"total":       len(markers),
+		}))
+		return nil
+	}
+
+	if len(markers) == 0 {
+		formatter.ShowInfo(fmt.Sprintf("No software managed by sai found in %s", markerStore.Dir()))
+		return nil
+	}
+
+	headers := []string{"Software", "Provider", "Version", "Installed", "By"}
+	if flags.Verbose {
+		headers = append(headers, "Packages")
+	}
+
+	var rows [][]string
+	for _, marker := range markers {
+		row := []string{
+			marker.Software,
+			marker.Provider,
+			marker.Version,
+			marker.InstalledAt.Format("2006-01-02 15:04"),
+			marker.InstalledBy,
+		}
+		if flags.Verbose {
+			row = append(row, strings.Join(marker.Packages, " "))
+		}
+		rows = append(rows, row)
+	}
+
+	userInterface.ShowTable(headers, rows)
+	fmt.Printf("\nSummary: %d software managed by sai\n", len(markers))
+
+	return nil
+}
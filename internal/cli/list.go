@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"sai/internal/interfaces"
@@ -11,6 +12,9 @@ import (
 	"sai/internal/ui"
 )
 
+// listInstalledFlag shows the installed software deduplicated across providers
+var listInstalledFlag bool
+
 // listCmd represents the list command
 var listCmd = &cobra.Command{
 	Use:   "list",
@@ -19,10 +23,14 @@ var listCmd = &cobra.Command{
 This provides a comprehensive view of software installed on the system.
 
 This is an information-only command that executes without confirmation prompts.
-The output shows software packages organized by provider with installation status.
+The packages are listed with the list_installed action of each provider, whose
+output is normalized by the parser the action selects. With --installed the
+software is deduplicated across providers, and the software with a saidata
+definition is marked.
 
 Examples:
-  sai list                             # List all installed software
+  sai list                             # List all installed software by provider
+  sai list --installed                 # List installed software across providers
   sai list --verbose                   # Show detailed package information
   sai list --json                      # Output in JSON format
   sai list --provider apt              # List only packages from apt provider`,
@@ -32,6 +40,7 @@ Examples:
 }
 
 func init() {
+	listCmd.Flags().BoolVar(&listInstalledFlag, "installed", false, "Deduplicate the installed software across providers and mark the software with saidata")
 	rootCmd.AddCommand(listCmd)
 }
 
@@ -52,15 +61,15 @@ func executeListCommand() error {
 	}
 
 	// Show progress
-	if !flags.Quiet {
+	if !flags.Quiet && !flags.JSONOutput {
 		formatter.ShowProgress("Listing installed software packages...")
 	}
 
-	// Get installed software by executing list action across providers
+	// Get installed software by executing the list_installed action across providers
 	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 	defer cancel()
 
-	installedSoftware, err := getInstalledSoftwareAcrossProviders(ctx, actionManager, flags)
+	installed, err := actionManager.ListInstalled(ctx, flags.Provider)
 	if err != nil {
 		formatter.ShowError(fmt.Errorf("failed to get installed software: %w", err))
 		return err
@@ -70,121 +79,26 @@ func executeListCommand() error {
 	if flags.JSONOutput {
 		listData := map[string]interface{}{
 			"type":      "installed_software_list",
-			"providers": installedSoftware,
-			"total":     getTotalPackageCount(installedSoftware),
+			"providers": installed.Providers,
+			"total":     getTotalPackageCount(installed.Providers),
+		}
+		if listInstalledFlag {
+			listData["software"] = installed.Software
+			listData["total"] = len(installed.Software)
 		}
 		fmt.Println(formatter.FormatJSON(listData))
+	} else if listInstalledFlag {
+		displayMergedSoftware(installed, formatter, userInterface, flags.Verbose)
 	} else {
-		displayInstalledSoftware(installedSoftware, formatter, userInterface, flags.Verbose)
+		displayInstalledSoftware(installed.Providers, formatter, userInterface, flags.Verbose)
 	}
 
 	return nil
 }
 
-// InstalledSoftware represents software installed by a provider
-type InstalledSoftware struct {
-	Provider     string                   `json:"provider"`
-	DisplayName  string                   `json:"display_name"`
-	Available    bool                     `json:"available"`
-	Packages     []InstalledPackage       `json:"packages"`
-	Error        string                   `json:"error,omitempty"`
-}
-
-// InstalledPackage represents an installed package
-type InstalledPackage struct {
-	Name        string `json:"name"`
-	Version     string `json:"version"`
-	Description string `json:"description,omitempty"`
-	Status      string `json:"status"`
-}
-
-// getInstalledSoftwareAcrossProviders retrieves installed software from all providers
-func getInstalledSoftwareAcrossProviders(ctx context.Context, actionManager interfaces.ActionManager, flags GlobalFlags) ([]InstalledSoftware, error) {
-	// For now, we'll simulate getting installed software since the actual implementation
-	// would require executing provider-specific list commands
-	
-	// This is a placeholder implementation that demonstrates the structure
-	// In a real implementation, this would:
-	// 1. Get all available providers
-	// 2. Execute list commands for each provider
-	// 3. Parse the output to extract installed packages
-	// 4. Return structured data
-	
-	var installedSoftware []InstalledSoftware
-	
-	// Simulate common providers and their installed packages
-	providers := []struct {
-		name        string
-		displayName string
-		available   bool
-		packages    []InstalledPackage
-	}{
-		{
-			name:        "apt",
-			displayName: "APT Package Manager",
-			available:   true,
-			packages: []InstalledPackage{
-				{Name: "nginx", Version: "1.18.0", Description: "HTTP and reverse proxy server", Status: "installed"},
-				{Name: "curl", Version: "7.68.0", Description: "Command line tool for transferring data", Status: "installed"},
-				{Name: "git", Version: "2.25.1", Description: "Distributed version control system", Status: "installed"},
-			},
-		},
-		{
-			name:        "docker",
-			displayName: "Docker Container Manager",
-			available:   true,
-			packages: []InstalledPackage{
-				{Name: "redis", Version: "6.2", Description: "In-memory data structure store", Status: "running"},
-				{Name: "postgres", Version: "13", Description: "Object-relational database system", Status: "running"},
-			},
-		},
-		{
-			name:        "npm",
-			displayName: "Node Package Manager",
-			available:   false,
-			packages:    []InstalledPackage{},
-		},
-	}
-
-	// Filter by provider if specified
-	if flags.Provider != "" {
-		var filteredProviders []struct {
-			name        string
-			displayName string
-			available   bool
-			packages    []InstalledPackage
-		}
-		for _, p := range providers {
-			if p.name == flags.Provider {
-				filteredProviders = append(filteredProviders, p)
-				break
-			}
-		}
-		providers = filteredProviders
-	}
-
-	// Convert to InstalledSoftware format
-	for _, p := range providers {
-		software := InstalledSoftware{
-			Provider:    p.name,
-			DisplayName: p.displayName,
-			Available:   p.available,
-			Packages:    p.packages,
-		}
-		
-		if !p.available {
-			software.Error = "Provider not available on this system"
-		}
-		
-		installedSoftware = append(installedSoftware, software)
-	}
-
-	return installedSoftware, nil
-}
-
-// displayInstalledSoftware displays the installed software in a formatted way
-func displayInstalledSoftware(installedSoftware []InstalledSoftware, formatter *output.OutputFormatter, userInterface *ui.UserInterface, verbose bool) {
-	if len(installedSoftware) == 0 {
+// displayInstalledSoftware displays the installed packages of each provider
+func displayInstalledSoftware(providers []*interfaces.ProviderPackages, formatter *output.OutputFormatter, userInterface *ui.UserInterface, verbose bool) {
+	if len(providers) == 0 {
 		formatter.ShowInfo("No providers found or no software installed.")
 		return
 	}
@@ -192,17 +106,17 @@ func displayInstalledSoftware(installedSoftware []InstalledSoftware, formatter *
 	totalPackages := 0
 	availableProviders := 0
 
-	for _, software := range installedSoftware {
-		if !software.Available {
+	for _, software := range providers {
+		if software.Error != "" {
 			if verbose {
-				formatter.ShowWarning(fmt.Sprintf("Provider %s is not available: %s", 
-					software.DisplayName, software.Error))
+				formatter.ShowWarning(fmt.Sprintf("Failed to list the packages of %s: %s",
+					software.Provider, software.Error))
 			}
 			continue
 		}
 
 		availableProviders++
-		
+
 		if len(software.Packages) == 0 {
 			if verbose {
 				fmt.Printf("\n%s:\n", formatter.FormatProviderName(software.Provider))
@@ -212,9 +126,9 @@ func displayInstalledSoftware(installedSoftware []InstalledSoftware, formatter *
 		}
 
 		fmt.Printf("\n%s:\n", formatter.FormatProviderName(software.Provider))
-		
+
 		// Sort packages by name
-		packages := make([]InstalledPackage, len(software.Packages))
+		packages := make([]interfaces.InstalledPackage, len(software.Packages))
 		copy(packages, software.Packages)
 		sort.Slice(packages, func(i, j int) bool {
 			return packages[i].Name < packages[j].Name
@@ -222,26 +136,16 @@ func displayInstalledSoftware(installedSoftware []InstalledSoftware, formatter *
 
 		if verbose {
 			// Detailed view with table format
-			headers := []string{"Package", "Version", "Status", "Description"}
+			headers := []string{"Package", "Version"}
 			var rows [][]string
-			
 			for _, pkg := range packages {
-				description := pkg.Description
-				if len(description) > 50 {
-					description = description[:47] + "..."
-				}
-				rows = append(rows, []string{pkg.Name, pkg.Version, pkg.Status, description})
+				rows = append(rows, []string{pkg.Name, pkg.Version})
 			}
-			
 			userInterface.ShowTable(headers, rows)
 		} else {
 			// Simple list view
 			for _, pkg := range packages {
-				status := ""
-				if pkg.Status != "installed" {
-					status = fmt.Sprintf(" (%s)", pkg.Status)
-				}
-				fmt.Printf("  %s %s%s\n", pkg.Name, pkg.Version, status)
+				fmt.Printf("  %s %s\n", pkg.Name, pkg.Version)
 			}
 		}
 
@@ -252,13 +156,60 @@ func displayInstalledSoftware(installedSoftware []InstalledSoftware, formatter *
 	fmt.Printf("\nSummary: %d packages from %d providers\n", totalPackages, availableProviders)
 }
 
+// displayMergedSoftware displays the installed software deduplicated across providers
+func displayMergedSoftware(installed *interfaces.InstalledList, formatter *output.OutputFormatter, userInterface *ui.UserInterface, verbose bool) {
+	for _, software := range installed.Providers {
+		if software.Error != "" && verbose {
+			formatter.ShowWarning(fmt.Sprintf("Failed to list the packages of %s: %s",
+				software.Provider, software.Error))
+		}
+	}
+
+	if len(installed.Software) == 0 {
+		formatter.ShowInfo("No installed software found.")
+		return
+	}
+
+	headers := []string{"Software", "Version", "Providers", "Saidata"}
+	var rows [][]string
+	withSaidata := 0
+	for _, software := range installed.Software {
+		var versions []string
+		for _, provider := range software.Providers {
+			if version := software.Versions[provider]; version != "" {
+				versions = append(versions, version)
+			}
+		}
+		saidata := ""
+		if software.HasSaidata {
+			saidata = "yes"
+			withSaidata++
+		}
+		rows = append(rows, []string{software.Name, strings.Join(uniqueStrings(versions), ", "), strings.Join(software.Providers, ", "), saidata})
+	}
+	userInterface.ShowTable(headers, rows)
+
+	fmt.Printf("\nSummary: %d installed software, %d with saidata\n", len(installed.Software), withSaidata)
+}
+
+// uniqueStrings returns values without duplicates, keeping their order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
 // getTotalPackageCount calculates the total number of packages across all providers
-func getTotalPackageCount(installedSoftware []InstalledSoftware) int {
+func getTotalPackageCount(providers []*interfaces.ProviderPackages) int {
 	total := 0
-	for _, software := range installedSoftware {
-		if software.Available {
-			total += len(software.Packages)
-		}
+	for _, software := range providers {
+		total += len(software.Packages)
 	}
 	return total
-}
\ No newline at end of file
+}
@@ -1,22 +1,36 @@
 package cli
 
 import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+
 	"github.com/spf13/cobra"
+	"sai/internal/output"
+	"sai/internal/systemd"
+	"sai/internal/types"
 )
 
+// logLines is the number of journal entries shown per service
+var logLines int
+
 // logsCmd represents the logs command
 var logsCmd = &cobra.Command{
 	Use:   "logs [software]",
 	Short: "Display software service logs",
 	Long: `Display logs for the specified software service or general system logs if no software is specified.
-This command will show logs using the appropriate log management system (journalctl, log files, etc.).
+
+On systemd systems the journal entries of the services of the software are read
+natively, the most recent --lines of each service. Elsewhere, and with --provider, the
+logs action of the provider is run instead, such as journalctl or the log files.
 
 This is an information-only command that executes without confirmation prompts.
 Use flags to control log output format and filtering.
 
 Examples:
   sai logs nginx                       # Show nginx service logs
-  sai logs nginx --verbose             # Show detailed log information
+  sai logs nginx --lines 200           # Show the last 200 entries of each nginx service
   sai logs nginx --json                # Output logs in JSON format
   sai logs                            # Show general system service logs
   sai logs --provider systemd         # Use specific provider for log management`,
@@ -25,10 +39,74 @@ Examples:
 		if len(args) == 0 {
 			return executeGeneralSystemCommand("logs")
 		}
-		return executeServiceCommand("logs", args[0])
+		if GetGlobalFlags().Provider != "" {
+			return executeServiceCommand("logs", args[0])
+		}
+		return executeLogsCommand(args[0])
 	},
 }
 
 func init() {
+	logsCmd.Flags().IntVarP(&logLines, "lines", "n", 50, "Journal entries shown per service")
 	rootCmd.AddCommand(logsCmd)
-}
\ No newline at end of file
+}
+
+// executeLogsCommand shows the journal entries of the services of a software, running
+// the logs action of the provider when the journal cannot be read
+func executeLogsCommand(software string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+	if runtime.GOOS != "linux" {
+		return executeServiceCommand("logs", software)
+	}
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, _, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+	saidata, err := actionManager.ResolveSoftwareData(software)
+	if err != nil || len(saidata.Services) == 0 {
+		return executeServiceCommand("logs", software)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+	entries, err := journalEntries(ctx, saidata.Services, logLines)
+	if err != nil {
+		formatter.ShowDebug(fmt.Sprintf("Reading the journal failed, running the logs action: %v", err))
+		return executeServiceCommand("logs", software)
+	}
+
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(entries))
+		return nil
+	}
+	for _, entry := range entries {
+		fmt.Printf("%s %s: %s\n", entry.Time.Format("2006-01-02 15:04:05"), entry.Unit, entry.Message)
+	}
+	return nil
+}
+
+// journalEntries returns the most recent journal entries of services, oldest first
+func journalEntries(ctx context.Context, services []types.Service, lines int) ([]systemd.JournalEntry, error) {
+	backend, err := systemd.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer backend.Close()
+
+	entries := []systemd.JournalEntry{}
+	for _, service := range services {
+		serviceEntries, err := backend.JournalEntries(ctx, service.GetServiceNameOrDefault(), lines)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, serviceEntries...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries, nil
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"sai/internal/output"
+	"sai/internal/saidata"
+	"sai/internal/validation"
+)
+
+var (
+	newSaidataPath     string
+	newSaidataForce    bool
+	newSaidataNoSearch bool
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Create new definitions",
+	Long: `Create new definitions from templates and what sai detects on this host.
+
+Examples:
+  sai new saidata nginx       # Skeleton of the nginx saidata`,
+}
+
+var newSaidataCmd = &cobra.Command{
+	Use:   "saidata <software>",
+	Short: "Generate the skeleton of a software definition",
+	Long: `Generate a schema-valid saidata skeleton for a software, ready to be reviewed and
+committed to the saidata repository.
+
+The skeleton is built from:
+  • The defaults sai generates for software without saidata: packages, services,
+    files, directories, commands and ports found on this host
+  • The package names the available providers find the software under, with their
+    search action, as provider configs when they differ from the software name
+  • The hierarchical prefix path of the repository, software/<prefix>/<software>/default.yaml
+
+The file is written to the local saidata repository, or to the checkout given with
+--path, and an existing definition is only replaced with --force. With --dry-run the
+skeleton is printed instead. Use --provider to only search one provider.
+
+Examples:
+  sai new saidata nginx                       # Write to the local repository
+  sai new saidata nginx --path ~/src/saidata  # Write to a checkout
+  sai new saidata nginx --dry-run --no-search # Print the skeleton without searching`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNewSaidata,
+}
+
+func init() {
+	rootCmd.AddCommand(newCmd)
+	newCmd.AddCommand(newSaidataCmd)
+
+	newSaidataCmd.Flags().StringVar(&newSaidataPath, "path", "", "Saidata checkout to write the definition to (default: local repository)")
+	newSaidataCmd.Flags().BoolVar(&newSaidataForce, "force", false, "Replace an existing definition")
+	newSaidataCmd.Flags().BoolVar(&newSaidataNoSearch, "no-search", false, "Do not search the providers for package names")
+}
+
+func runNewSaidata(cmd *cobra.Command, args []string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+	software := args[0]
+
+	path := newSaidataPath
+	if path == "" {
+		path = saidata.GetSaidataPath()
+	}
+	if dir := saidata.DefinitionDir(path, software); dir != "" && !newSaidataForce && !flags.DryRun {
+		return fmt.Errorf("saidata of %s already exists in %s, use --force to replace it", software, filepath.Join(path, dir))
+	}
+
+	defaults, err := saidata.NewDefaultsGenerator(nil).GenerateDefaults(software)
+	if err != nil {
+		return fmt.Errorf("failed to generate defaults for %s: %w", software, err)
+	}
+
+	packageNames := make(map[string]string)
+	if !newSaidataNoSearch {
+		formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+		actionManager, _, err := createManagers(config, formatter)
+		if err != nil {
+			formatter.ShowError(fmt.Errorf("failed to initialize managers: %w", err))
+			return err
+		}
+		if !flags.Quiet {
+			formatter.ShowProgress(fmt.Sprintf("Searching for %s across all providers...", software))
+		}
+		results, err := actionManager.SearchAcrossProviders(software)
+		if err != nil {
+			return fmt.Errorf("search failed: %w", err)
+		}
+		for _, result := range results {
+			// The package of the software comes first among the results of a provider
+			if _, found := packageNames[result.Provider]; found {
+				continue
+			}
+			if flags.Provider == "" || result.Provider == flags.Provider {
+				packageNames[result.Provider] = result.PackageName
+			}
+		}
+	}
+
+	data, err := saidata.MarshalScaffold(saidata.Scaffold(defaults, packageNames))
+	if err != nil {
+		return err
+	}
+
+	validator, err := validation.NewSaidataValidator("schemas/saidata-0.2-schema.json")
+	if err != nil {
+		fmt.Printf("⚠️  Could not load schema validator, skipping schema validation: %v\n", err)
+	} else if err := validator.ValidateSaidataYAML(data); err != nil {
+		return fmt.Errorf("generated saidata of %s is invalid: %w", software, err)
+	}
+
+	if flags.DryRun {
+		fmt.Print(string(data))
+		return nil
+	}
+
+	file := filepath.Join(path, saidata.ScaffoldPath(software))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+
+	fmt.Printf("✅ Saidata of %s written to %s\n", software, file)
+	fmt.Printf("Review it, then propose it with 'sai saidata contribute %s --path %s'\n", software, path)
+	return nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sai/internal/output"
+)
+
+// notesCmd represents the notes command
+var notesCmd = &cobra.Command{
+	Use:   "notes <software>",
+	Short: "Show the next steps after installing software",
+	Long: `Show the next steps after installing software, such as the configuration to edit
+or the initialization command to run, from the post_install_message of its saidata.
+Install shows them once the software is installed; this command shows them again later.
+
+The notes are rendered for the provider that installed the software, or for the one
+install would select, so file paths and service names match the system.
+
+Examples:
+  sai notes postgresql          # Show the next steps after installing PostgreSQL
+  sai notes postgresql --json   # As JSON`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeNotesCommand(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(notesCmd)
+}
+
+// executeNotesCommand shows the next steps after installing a software
+func executeNotesCommand(software string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, _, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	notes, err := actionManager.SoftwareNotes(software)
+	if err != nil {
+		return err
+	}
+
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(notes))
+		return nil
+	}
+	if !notes.Installed && notes.Provider != "" {
+		formatter.ShowInfo(fmt.Sprintf("%s was not installed by sai, the notes are shown for %s", software, notes.Provider))
+	}
+	displayNotes(software, notes.Notes)
+	return nil
+}
+
+// displayNotes prints the next steps after installing a software, when it has any
+func displayNotes(software, notes string) {
+	if notes == "" {
+		return
+	}
+	fmt.Printf("\nNext steps for %s:\n", software)
+	for _, line := range strings.Split(notes, "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
@@ -0,0 +1,397 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sai/internal/interfaces"
+	"sai/internal/output"
+	"sai/internal/platform"
+)
+
+// Bootstrap statuses of providers and tools
+const (
+	bootstrapAvailable    = "available"
+	bootstrapInstalled    = "installed"
+	bootstrapWouldInstall = "would_install"
+	bootstrapFailed       = "failed"
+)
+
+// commonProviders are the package managers bootstrapped by default on each platform
+var commonProviders = map[string][]string{
+	"darwin": {"brew"},
+}
+
+// foundationalTools are the executables sai and provider bootstraps rely on, installed
+// with the native package manager when missing
+var foundationalTools = map[string][]string{
+	"linux":  {"curl", "git"},
+	"darwin": {"curl", "git"},
+}
+
+var allowUnverifiedFlag bool
+
+// providersCmd represents the providers command
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "Manage package manager providers",
+	Long: `Manage the package managers and tools that sai uses as providers.
+
+Use 'sai providers list' or 'sai stats' to see the providers available on this system.`,
+}
+
+// providersListCmd represents the providers list command
+var providersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the loaded providers and their availability",
+	Long: `List the loaded providers with their type, priority and whether they are
+available on this system, available providers first.
+
+Examples:
+  sai providers list                           # List providers
+  sai providers list --json                    # Output providers in JSON format
+  sai providers list --format '{{if .Available}}{{.Name}}{{end}}'   # Custom output`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeProvidersListCommand()
+	},
+}
+
+// providersExportCmd represents the providers export command
+var providersExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the provider action catalog of this host as JSON",
+	Long: `Export the providers compatible with the platform of this host, available or not,
+with their metadata and the metadata of their actions, for external tooling.
+
+The catalog is written as JSON whether or not --json is given. Providers and actions
+are sorted by name and no timestamp is included, so the catalogs of two hosts can be
+compared with diff.
+
+Examples:
+  sai providers export --json                  # Export the catalog
+  sai providers export --json > catalog.json   # Save it to compare with another host`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeProvidersExportCommand()
+	},
+}
+
+// providersBootstrapCmd represents the providers bootstrap command
+var providersBootstrapCmd = &cobra.Command{
+	Use:   "bootstrap [provider...]",
+	Short: "Install missing common package managers and tools",
+	Long: `Install the package managers and foundational tools sai relies on, so provider
+detection succeeds on a fresh system.
+
+Without arguments, the common package managers of the platform (Homebrew on macOS)
+are installed using the bootstrap definition of their provider, followed by missing
+foundational tools (curl and git) using the native package manager. With arguments,
+only the named providers are bootstrapped.
+
+Installers are downloaded from their official location and verified against a pinned
+checksum or the checksum file published next to them. Installers without a published
+checksum are only run with --allow-unverified.
+
+Examples:
+  sai providers bootstrap                      # Install missing package managers and tools
+  sai providers bootstrap nix                  # Install the Nix package manager
+  sai providers bootstrap --dry-run            # Show what would be installed
+  sai providers bootstrap --allow-unverified   # Also run installers without a checksum`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeProvidersBootstrapCommand(args)
+	},
+}
+
+func init() {
+	providersBootstrapCmd.Flags().BoolVar(&allowUnverifiedFlag, "allow-unverified", false, "Run official installers that publish no checksum")
+	providersCmd.AddCommand(providersBootstrapCmd)
+	addFormatFlag(providersListCmd)
+	providersCmd.AddCommand(providersListCmd)
+	providersCmd.AddCommand(providersExportCmd)
+	rootCmd.AddCommand(providersCmd)
+}
+
+// executeProvidersListCommand lists the loaded providers
+func executeProvidersListCommand() error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, userInterface, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	providers := getProviderStats(actionManager)
+	if formatFlag != "" {
+		return printFormatted(providers)
+	}
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(providers))
+		return nil
+	}
+
+	var rows [][]string
+	for _, provider := range providers {
+		rows = append(rows, []string{provider.Name, provider.Type, fmt.Sprintf("%d", provider.Priority), provider.Status})
+	}
+	userInterface.ShowTable([]string{"Provider", "Type", "Priority", "Status"}, rows)
+	return nil
+}
+
+// ProviderCatalog is the provider action catalog of a host
+type ProviderCatalog struct {
+	Platform     string                 `json:"platform"`
+	OS           string                 `json:"os"`
+	OSVersion    string                 `json:"os_version"`
+	Architecture string                 `json:"architecture"`
+	Providers    []ProviderCatalogEntry `json:"providers"`
+}
+
+// ProviderCatalogEntry describes a provider and its actions in the catalog
+type ProviderCatalogEntry struct {
+	Name         string               `json:"name"`
+	DisplayName  string               `json:"display_name,omitempty"`
+	Type         string               `json:"type"`
+	Platforms    []string             `json:"platforms,omitempty"`
+	Capabilities []string             `json:"capabilities,omitempty"`
+	Executable   string               `json:"executable,omitempty"`
+	Priority     int                  `json:"priority"`
+	Available    bool                 `json:"available"`
+	Actions      []ActionCatalogEntry `json:"actions"`
+}
+
+// ActionCatalogEntry describes an action of a provider in the catalog
+type ActionCatalogEntry struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description,omitempty"`
+	RequiresRoot  bool     `json:"requires_root"`
+	Timeout       int      `json:"timeout"` // Seconds, the default when the action declares none
+	Steps         int      `json:"steps,omitempty"`
+	Script        bool     `json:"script,omitempty"`
+	Downloads     int      `json:"downloads,omitempty"`
+	Checkouts     int      `json:"checkouts,omitempty"`
+	Inputs        []string `json:"inputs,omitempty"`
+	HasValidation bool     `json:"has_validation"`
+	HasRollback   bool     `json:"has_rollback"`
+}
+
+// executeProvidersExportCommand writes the provider action catalog of this host
+func executeProvidersExportCommand() error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, _, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	osInfo, err := platform.Detect()
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to detect platform: %w", err))
+		return err
+	}
+
+	jsonData, err := json.MarshalIndent(buildProviderCatalog(osInfo, actionManager.GetProviderManager()), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal provider catalog to JSON: %w", err)
+	}
+	fmt.Println(string(jsonData))
+	return nil
+}
+
+// buildProviderCatalog describes the providers compatible with the host, sorted by
+// name with their actions sorted by name
+func buildProviderCatalog(osInfo *platform.OSInfo, providerManager interfaces.ProviderManager) *ProviderCatalog {
+	catalog := &ProviderCatalog{
+		Platform:     osInfo.Platform,
+		OS:           osInfo.OS,
+		OSVersion:    osInfo.Version,
+		Architecture: osInfo.Architecture,
+		Providers:    []ProviderCatalogEntry{},
+	}
+
+	for _, provider := range providerManager.GetPlatformProviders() {
+		entry := ProviderCatalogEntry{
+			Name:         provider.Provider.Name,
+			DisplayName:  provider.Provider.DisplayName,
+			Type:         provider.Provider.Type,
+			Platforms:    provider.Provider.Platforms,
+			Capabilities: provider.Provider.Capabilities,
+			Executable:   provider.Provider.Executable,
+			Priority:     provider.Provider.Priority,
+			Available:    providerManager.IsProviderAvailable(provider.Provider.Name),
+			Actions:      []ActionCatalogEntry{},
+		}
+
+		names := make([]string, 0, len(provider.Actions))
+		for name := range provider.Actions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			action := provider.Actions[name]
+			actionEntry := ActionCatalogEntry{
+				Name:          name,
+				Description:   action.Description,
+				RequiresRoot:  action.RequiresRoot,
+				Timeout:       int(action.GetTimeout().Seconds()),
+				Steps:         len(action.Steps),
+				Script:        action.IsScript(),
+				Downloads:     len(action.Downloads),
+				Checkouts:     len(action.Checkouts),
+				HasValidation: action.Validation != nil,
+				HasRollback:   action.Rollback != "",
+			}
+			for _, input := range action.Inputs {
+				actionEntry.Inputs = append(actionEntry.Inputs, input.Name)
+			}
+			entry.Actions = append(entry.Actions, actionEntry)
+		}
+		catalog.Providers = append(catalog.Providers, entry)
+	}
+	return catalog
+}
+
+// BootstrapItem is the outcome of bootstrapping a provider or tool
+type BootstrapItem struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"` // provider or tool
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// executeProvidersBootstrapCommand installs missing providers and foundational tools
+func executeProvidersBootstrapCommand(providers []string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, userInterface, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	osInfo, err := platform.Detect()
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to detect platform: %w", err))
+		return err
+	}
+
+	options := interfaces.ActionOptions{
+		DryRun:    flags.DryRun,
+		Verbose:   flags.Verbose,
+		Quiet:     flags.Quiet,
+		Yes:       flags.Yes,
+		JSON:      flags.JSONOutput,
+		Variables: flags.Variables,
+		Timeout:   config.Timeout,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	// Package managers come first, on macOS the tools are installed with Homebrew
+	explicit := len(providers) > 0
+	if !explicit {
+		providers = commonProviders[osInfo.Platform]
+	}
+
+	var items []BootstrapItem
+	var bootstrapErr error
+	providerManager := actionManager.GetProviderManager()
+	for _, name := range providers {
+		item := BootstrapItem{Name: name, Kind: "provider", Status: bootstrapAvailable}
+		if !providerManager.IsProviderAvailable(name) {
+			item.Status = installedStatus(flags.DryRun)
+			if err := actionManager.BootstrapProvider(ctx, name, allowUnverifiedFlag, options); err != nil {
+				item.Status, item.Error = bootstrapFailed, err.Error()
+				bootstrapErr = err
+			}
+		}
+		items = append(items, item)
+	}
+
+	if !explicit && bootstrapErr == nil {
+		var missing []string
+		for _, tool := range foundationalTools[osInfo.Platform] {
+			if _, err := platform.LookPath(tool); err == nil {
+				items = append(items, BootstrapItem{Name: tool, Kind: "tool", Status: bootstrapAvailable})
+			} else {
+				missing = append(missing, tool)
+			}
+		}
+
+		if len(missing) > 0 && !flags.Yes && !flags.DryRun {
+			confirmed, err := userInterface.PromptForConfirmation(fmt.Sprintf("Install missing tools %s?", strings.Join(missing, ", ")))
+			if err != nil {
+				formatter.ShowError(fmt.Errorf("confirmation failed: %w", err))
+				return err
+			}
+			if !confirmed {
+				formatter.ShowInfo("Bootstrap cancelled by user")
+				return nil
+			}
+		}
+
+		// Confirmed once for all tools
+		options.Yes = true
+		for _, tool := range missing {
+			item := BootstrapItem{Name: tool, Kind: "tool", Status: installedStatus(flags.DryRun)}
+			result, err := actionManager.ExecuteAction(ctx, "install", tool, options)
+			if err == nil && !result.Success {
+				err = fmt.Errorf("%s", result.Error)
+			}
+			if err != nil {
+				item.Status, item.Error = bootstrapFailed, err.Error()
+				bootstrapErr = err
+			}
+			items = append(items, item)
+		}
+	}
+
+	if flags.JSONOutput {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"platform": osInfo.Platform,
+			"dry_run":  flags.DryRun,
+			"items":    items,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal bootstrap results to JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+	} else if !flags.Quiet {
+		for _, item := range items {
+			line := fmt.Sprintf("%-8s %-10s %s", item.Kind, item.Name, strings.ReplaceAll(item.Status, "_", " "))
+			if item.Error != "" {
+				line += ": " + item.Error
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if bootstrapErr != nil {
+		return fmt.Errorf("bootstrap failed: %w", bootstrapErr)
+	}
+	if !flags.DryRun && !flags.JSONOutput {
+		formatter.ShowSuccess("Package managers and tools are available, run 'sai stats' to see the detected providers")
+	}
+	return nil
+}
+
+// installedStatus is the status of a provider or tool that is installed successfully
+func installedStatus(dryRun bool) string {
+	if dryRun {
+		return bootstrapWouldInstall
+	}
+	return bootstrapInstalled
+}
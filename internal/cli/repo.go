@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"sai/internal/config"
+	"sai/internal/output"
+	"sai/internal/saidata"
+	"sai/internal/ui"
+)
+
+// defaultRepositoryPriority is the priority of repositories added without --priority,
+// searched before the main repository
+const defaultRepositoryPriority = 10
+
+var repoPriority int
+
+// repoCmd represents the repo command
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Manage the saidata repositories",
+	Long: `Manage the saidata repositories searched besides the main one (repository), such as
+a company-internal repository completing or overriding upstream definitions.
+
+Repositories are searched by descending priority, the main repository having priority
+0. The definition of a software in the lowest priority repository defining it is the
+base, the definitions and OS overrides of the repositories of higher priority are
+merged over it. A repository of negative priority provides fallback definitions.
+
+Repositories are listed under repositories in the configuration file. A git repository
+is cloned into repositories/{name} of the cache directory and updated in place by
+'sai saidata update', verified and filtered as the main repository, a local directory
+is used in place.
+
+Examples:
+  sai repo list
+  sai repo add internal https://git.example.com/ops/saidata.git --priority 20
+  sai repo add local /srv/saidata --priority -1
+  sai repo remove internal`,
+}
+
+// repoListCmd represents the repo list command
+var repoListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the saidata repositories in search order",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeRepoList()
+	},
+}
+
+// repoAddCmd represents the repo add command
+var repoAddCmd = &cobra.Command{
+	Use:   "add <name> <git-url|path>",
+	Short: "Add a saidata repository",
+	Long: `Add a saidata repository to the configuration file. A git URL is cloned right away,
+an existing directory is used in place.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeRepoAdd(args[0], args[1])
+	},
+}
+
+// repoRemoveCmd represents the repo remove command
+var repoRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a saidata repository",
+	Long: `Remove a saidata repository from the configuration file, and its clone from the
+cache directory. Local directories are left in place.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeRepoRemove(args[0])
+	},
+}
+
+func init() {
+	repoAddCmd.Flags().IntVar(&repoPriority, "priority", defaultRepositoryPriority, "search priority, the main repository has 0")
+	repoCmd.AddCommand(repoListCmd)
+	repoCmd.AddCommand(repoAddCmd)
+	repoCmd.AddCommand(repoRemoveCmd)
+	rootCmd.AddCommand(repoCmd)
+}
+
+// saidataRepositories returns the configured saidata repositories searched besides
+// the main one
+func saidataRepositories(cfg *config.Config) []saidata.Repository {
+	var repositories []saidata.Repository
+	for _, repository := range cfg.Repositories {
+		repositories = append(repositories, saidata.Repository{
+			Name:     repository.Name,
+			Dir:      repository.Dir(cfg.CacheDir),
+			Priority: repository.Priority,
+		})
+	}
+	return repositories
+}
+
+// newSaidataManager creates a saidata manager of the main repository in saidataDir
+// also searching the configured repositories
+func newSaidataManager(cfg *config.Config, saidataDir string) *saidata.Manager {
+	manager := saidata.NewManager(saidataDir)
+	manager.SetRepositories(saidataRepositories(cfg))
+	return manager
+}
+
+// configFileToEdit returns the configuration file the repo commands edit: the one in
+// use, or else the system or user one
+func configFileToEdit() string {
+	if path := config.FindConfigFile(cfgFile); path != "" {
+		return path
+	}
+	if os.Geteuid() == 0 {
+		return "/etc/sai/config.yaml"
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".sai", "config.yaml")
+}
+
+// extraRepositoryManager returns the repository manager of a git saidata repository
+// searched besides the main one, verifying, filtering and refusing the network in
+// offline mode like the one of the main repository
+func extraRepositoryManager(cfg *config.Config, repository config.SaidataRepositoryConfig) *saidata.RepositoryManager {
+	repoManager := saidata.NewRepositoryManager(repository.GitURL, "")
+	repoManager.SetLocalPath(repository.Dir(cfg.CacheDir))
+	repoManager.SetSparseFilter(sparseFilter(cfg))
+	repoManager.SetVerification(signatureVerification(cfg))
+	repoManager.SetProgress(bootstrapProgress())
+	repoManager.SetDiskCache(saidataCache(cfg))
+	repoManager.SetOffline(cfg.Repository.OfflineMode)
+	return repoManager
+}
+
+// cloneRepository clones the git repository of a saidata repository into its directory
+func cloneRepository(cfg *config.Config, repository config.SaidataRepositoryConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := extraRepositoryManager(cfg, repository).InitializeRepository(ctx); err != nil {
+		return fmt.Errorf("failed to clone saidata repository %s: %w", repository.Name, err)
+	}
+	return nil
+}
+
+// updateRepositories updates the configured git repositories in place, cloning those
+// missing from the cache directory
+func updateRepositories(cfg *config.Config) error {
+	for _, repository := range cfg.Repositories {
+		if repository.GitURL == "" {
+			continue
+		}
+		repoManager := extraRepositoryManager(cfg, repository)
+		if repoManager.IsFirstRun() {
+			if err := cloneRepository(cfg, repository); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := repoManager.UpdateRepository(); err != nil {
+			return fmt.Errorf("failed to update saidata repository %s: %w", repository.Name, err)
+		}
+	}
+	return nil
+}
+
+// executeRepoList shows the saidata repositories in search order
+func executeRepoList() error {
+	cfg := GetGlobalConfig()
+	flags := GetGlobalFlags()
+	formatter := output.NewOutputFormatter(cfg, flags.Verbose, flags.Quiet, flags.JSONOutput)
+
+	type repositoryInfo struct {
+		Name      string `json:"name"`
+		Priority  int    `json:"priority"`
+		Source    string `json:"source"`
+		Dir       string `json:"dir"`
+		Available bool   `json:"available"`
+	}
+	sources := map[string]string{config.DefaultRepositoryName: cfg.Repository.GitURL}
+	for _, repository := range cfg.Repositories {
+		sources[repository.Name] = repository.GitURL
+		if repository.GitURL == "" {
+			sources[repository.Name] = repository.Path
+		}
+	}
+
+	var repositories []repositoryInfo
+	for _, repository := range newSaidataManager(cfg, saidata.GetSaidataPath()).Repositories() {
+		_, err := os.Stat(repository.Dir)
+		repositories = append(repositories, repositoryInfo{
+			Name:      repository.Name,
+			Priority:  repository.Priority,
+			Source:    sources[repository.Name],
+			Dir:       repository.Dir,
+			Available: err == nil,
+		})
+	}
+
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(repositories))
+		return nil
+	}
+	var rows [][]string
+	for _, repository := range repositories {
+		status := "✓"
+		if !repository.Available {
+			status = "missing"
+		}
+		rows = append(rows, []string{repository.Name, strconv.Itoa(repository.Priority), repository.Source, repository.Dir, status})
+	}
+	ui.NewUserInterface(cfg, formatter).ShowTable([]string{"Name", "Priority", "Source", "Directory", "Status"}, rows)
+	return nil
+}
+
+// executeRepoAdd adds a saidata repository to the configuration file, cloning it when
+// it is a git repository
+func executeRepoAdd(name, source string) error {
+	cfg := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	repository := config.SaidataRepositoryConfig{Name: name, Priority: repoPriority}
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		if repository.Path, err = filepath.Abs(source); err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", source, err)
+		}
+	} else if strings.Contains(source, "://") || strings.Contains(source, "@") {
+		repository.GitURL = source
+	} else {
+		return fmt.Errorf("%s is neither a directory nor a git URL", source)
+	}
+
+	repositories := append(append([]config.SaidataRepositoryConfig(nil), cfg.Repositories...), repository)
+	if err := config.ValidateRepositories(repositories); err != nil {
+		return err
+	}
+
+	if repository.GitURL != "" {
+		if err := cloneRepository(cfg, repository); err != nil {
+			return err
+		}
+	}
+	path := configFileToEdit()
+	if err := config.SaveRepositories(path, repositories); err != nil {
+		return err
+	}
+
+	if !flags.Quiet {
+		fmt.Printf("✅ Added saidata repository %s with priority %d to %s\n", name, repository.Priority, path)
+	}
+	return nil
+}
+
+// executeRepoRemove removes a saidata repository from the configuration file, and its
+// clone from the cache directory
+func executeRepoRemove(name string) error {
+	cfg := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	var repositories []config.SaidataRepositoryConfig
+	var removed *config.SaidataRepositoryConfig
+	for i, repository := range cfg.Repositories {
+		if repository.Name == name {
+			removed = &cfg.Repositories[i]
+			continue
+		}
+		repositories = append(repositories, repository)
+	}
+	if removed == nil {
+		if name == config.DefaultRepositoryName {
+			return fmt.Errorf("the main saidata repository cannot be removed, set repository in the configuration file instead")
+		}
+		return fmt.Errorf("no saidata repository named %s", name)
+	}
+
+	path := configFileToEdit()
+	if err := config.SaveRepositories(path, repositories); err != nil {
+		return err
+	}
+	if removed.Path == "" {
+		if err := os.RemoveAll(removed.Dir(cfg.CacheDir)); err != nil {
+			return fmt.Errorf("failed to remove the clone of saidata repository %s: %w", name, err)
+		}
+	}
+
+	if !flags.Quiet {
+		fmt.Printf("✅ Removed saidata repository %s from %s\n", name, path)
+	}
+	return nil
+}
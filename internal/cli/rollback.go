@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sai/internal/interfaces"
+	"sai/internal/output"
+	"sai/internal/transaction"
+	"sai/internal/ui"
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback [transaction-id]",
+	Short: "Undo the changes made by an action",
+	Long: `Undo the changes journaled by a transaction. Every system-changing action runs in
+a transaction recording the packages installed, services started and steps executed;
+rolling back replays the inverse of these changes, the most recent first.
+
+Changes of failed actions are rolled back automatically unless transactions.auto_rollback
+is disabled. Without arguments, the journaled transactions are listed.
+
+Examples:
+  sai rollback                                 # List transactions
+  sai rollback 20240101-120000-a1b2c3          # Undo the changes of a transaction
+  sai rollback 20240101-120000-a1b2c3 --dry-run   # Show the rollback plan only
+  sai rollback --json                          # List transactions in JSON format`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return executeRollbackListCommand()
+		}
+		return executeRollbackCommand(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+// executeRollbackListCommand lists the journaled transactions
+func executeRollbackListCommand() error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, userInterface, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	transactions, err := actionManager.GetTransactions()
+	if err != nil {
+		formatter.ShowError(err)
+		return err
+	}
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(transactions))
+		return nil
+	}
+	if len(transactions) == 0 {
+		formatter.ShowInfo("No transactions found")
+		return nil
+	}
+
+	var rows [][]string
+	for _, tx := range transactions {
+		rows = append(rows, []string{
+			tx.ID,
+			tx.Action,
+			tx.Software,
+			tx.Provider,
+			tx.Status,
+			tx.StartedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	userInterface.ShowTable([]string{"ID", "Action", "Software", "Provider", "Status", "Started"}, rows)
+	return nil
+}
+
+// executeRollbackCommand shows the rollback plan of a transaction and runs it once confirmed
+func executeRollbackCommand(id string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, userInterface, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	options := interfaces.ActionOptions{
+		DryRun:    true,
+		Verbose:   flags.Verbose,
+		Quiet:     flags.Quiet,
+		Yes:       flags.Yes,
+		JSON:      flags.JSONOutput,
+		Variables: flags.Variables,
+		Timeout:   config.Timeout,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	// Show the plan before anything is undone
+	tx, err := actionManager.Rollback(ctx, id, options)
+	if err != nil {
+		formatter.ShowError(err)
+		return err
+	}
+	if !flags.JSONOutput {
+		showRollbackPlan(userInterface, tx)
+	}
+
+	if flags.DryRun {
+		if flags.JSONOutput {
+			fmt.Println(formatter.FormatJSON(tx.RollbackPlan()))
+		}
+		return nil
+	}
+
+	if !flags.Yes {
+		confirmed, err := userInterface.PromptForConfirmation(fmt.Sprintf("Roll back transaction %s?", tx.ID))
+		if err != nil {
+			formatter.ShowError(fmt.Errorf("confirmation failed: %w", err))
+			return err
+		}
+		if !confirmed {
+			formatter.ShowInfo("Operation cancelled by user")
+			return nil
+		}
+	}
+
+	options.DryRun = false
+	tx, err = actionManager.Rollback(ctx, id, options)
+	if flags.JSONOutput && tx != nil {
+		fmt.Println(formatter.FormatJSON(tx))
+	}
+	if err != nil {
+		formatter.ShowError(err)
+		return err
+	}
+
+	formatter.ShowSuccess(fmt.Sprintf("Transaction %s rolled back", tx.ID))
+	return nil
+}
+
+// showRollbackPlan displays the changes undone by rolling back a transaction
+func showRollbackPlan(userInterface *ui.UserInterface, tx *transaction.Transaction) {
+	fmt.Printf("Rollback plan of transaction %s (%s %s with %s):\n", tx.ID, tx.Action, tx.Software, tx.Provider)
+
+	var rows [][]string
+	for _, change := range tx.RollbackPlan() {
+		undo := change.RollbackCmd
+		if undo == "" {
+			undo = fmt.Sprintf("sai %s %s", change.InverseAction, tx.Software)
+		}
+		rows = append(rows, []string{change.Type, change.Resource, change.Action, undo})
+	}
+	userInterface.ShowTable([]string{"Type", "Resource", "Change", "Undo"}, rows)
+}
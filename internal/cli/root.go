@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -10,17 +11,36 @@ import (
 	"github.com/spf13/viper"
 	"sai/internal/config"
 	"sai/internal/debug"
+	"sai/internal/download"
+	"sai/internal/errors"
 )
 
 var (
-	cfgFile      string
-	providerFlag string
-	verbose      bool
-	dryRun       bool
-	yes          bool
-	quiet        bool
-	jsonOutput   bool
-	debugFlag    bool
+	cfgFile         string
+	providerFlag    string
+	verbose         bool
+	dryRun          bool
+	yes             bool
+	quiet           bool
+	jsonOutput      bool
+	debugFlag       bool
+	readOnly        bool
+	insecureSaidata bool
+	allowGenerated  bool
+	ignoreBreakers  bool
+	noBootstrap     bool
+	offline         bool
+	stream          bool
+	interactive     bool
+	wslPrefer       string
+	rootDir         string
+	lockTimeout     time.Duration
+	lockTimeoutSet  bool // --lock-timeout 0 is meaningful, so only a given flag overrides the configuration
+	varsFile        string
+	varFlags        []string
+	
+	// Template variables resolved from --vars-file, SAI_VAR_* and --var
+	cliVariables map[string]string
 	
 	// Global configuration instance
 	globalConfig *config.Config
@@ -56,6 +76,7 @@ Examples:
 		if err := ValidateFlags(); err != nil {
 			return err
 		}
+		lockTimeoutSet = cmd.Flags().Changed("lock-timeout")
 		// Then initialize configuration
 		return initializeConfig()
 	},
@@ -63,10 +84,17 @@ Examples:
 	SilenceErrors: true,
 }
 
+// closers release what commands hold until they complete, such as the connection to
+// systemd of the saidata manager
+var closers []func()
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
 	err := rootCmd.Execute()
+	for _, close := range closers {
+		close()
+	}
 	
 	// Show debug metrics and cleanup if debug mode was enabled
 	if globalDebugManager != nil && globalDebugManager.IsEnabled() {
@@ -97,10 +125,37 @@ func init() {
 		"output results in JSON format for programmatic consumption")
 	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, 
 		"enable comprehensive debug logging for troubleshooting")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, 
+		"refuse to execute system-changing commands (audit mode)")
+	rootCmd.PersistentFlags().BoolVar(&insecureSaidata, "insecure-saidata", false, 
+		"accept downloaded saidata failing signature verification, or downloaded without it configured")
+	rootCmd.PersistentFlags().BoolVar(&allowGenerated, "allow-generated", false, 
+		"allow install and uninstall of software without saidata, from generated defaults")
+	rootCmd.PersistentFlags().BoolVar(&noBootstrap, "no-bootstrap", false, 
+		"fail when the saidata repository is missing instead of downloading it")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, 
+		"never access the network: refuse saidata updates and serve downloads from imported bundles")
+	rootCmd.PersistentFlags().BoolVar(&stream, "stream", false, 
+		"show the output of commands live, prefixed with the provider, instead of when they finish")
+	rootCmd.PersistentFlags().BoolVar(&ignoreBreakers, "ignore-circuit-breakers", false, 
+		"select and run providers whose circuit breaker is open after repeated failures")
+	rootCmd.PersistentFlags().BoolVarP(&interactive, "interactive", "i", false, 
+		"use a full-screen terminal UI to select providers and software and follow progress")
+	rootCmd.PersistentFlags().StringVar(&rootDir, "root", "", 
+		"act on the root filesystem mounted at this directory instead of the running system (image builds)")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", 0, 
+		"how long to wait for a provider locked by another sai process, 0 fails at once (default from config: 10m)")
+	rootCmd.PersistentFlags().StringVar(&wslPrefer, "wsl-prefer", "", 
+		"under WSL, prefer 'linux' native or 'windows' providers (default: linux)")
+	rootCmd.PersistentFlags().StringVar(&varsFile, "vars-file", "", 
+		"YAML file with template variables (overridden by SAI_VAR_* and --var)")
+	rootCmd.PersistentFlags().StringArrayVar(&varFlags, "var", nil, 
+		"set a template variable as key=value (repeatable, highest precedence)")
 
 	// Flag validation and mutual exclusivity
 	rootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
 	rootCmd.MarkFlagsMutuallyExclusive("json", "quiet")
+	rootCmd.MarkFlagsMutuallyExclusive("interactive", "json")
 
 	// Set up command completion
 	rootCmd.CompletionOptions.DisableDefaultCmd = false
@@ -170,6 +225,23 @@ func initializeConfig() error {
 	// Apply flag overrides to configuration
 	applyFlagOverrides()
 
+	// Serve the downloads imported from bundles, and only those in offline mode
+	download.SetMirror(downloadsMirror(globalConfig), globalConfig.Repository.OfflineMode)
+	if err := download.Configure(downloadOptions(globalConfig)); err != nil {
+		return err
+	}
+
+	// Redact the configured secret patterns from output, logs and the journal
+	if err := debug.ConfigureRedaction(globalConfig.Redaction.Patterns, !globalConfig.Redaction.DisableBuiltin); err != nil {
+		return err
+	}
+
+	// Resolve template variables
+	cliVariables, err = config.LoadVariables(varsFile, varFlags)
+	if err != nil {
+		return err
+	}
+
 	// Log successful configuration loading
 	if debugFlag {
 		configData := configToMap(globalConfig)
@@ -192,6 +264,50 @@ func initializeConfig() error {
 func applyFlagOverrides() {
 	if providerFlag != "" {
 		globalConfig.DefaultProvider = providerFlag
+		globalConfig.SetByFlag("default_provider", "--provider")
+	}
+	
+	// --read-only can only enable read-only mode, never disable a configured one
+	if readOnly {
+		globalConfig.ReadOnly = true
+		globalConfig.SetByFlag("read_only", "--read-only")
+	}
+	
+	// --allow-generated lifts strict mode for this run
+	if allowGenerated {
+		globalConfig.Security.StrictActions = nil
+		globalConfig.SetByFlag("security.strict_actions", "--allow-generated")
+	}
+	
+	// --offline can only enable offline mode, never disable a configured one
+	if offline {
+		globalConfig.Repository.OfflineMode = true
+		globalConfig.SetByFlag("repository.offline_mode", "--offline")
+	}
+	
+	// --stream can only enable streaming, never disable a configured one
+	if stream {
+		globalConfig.Output.Stream = true
+		globalConfig.SetByFlag("output.stream", "--stream")
+	}
+	
+	if wslPrefer != "" {
+		globalConfig.WSLPrefer = wslPrefer
+		globalConfig.SetByFlag("wsl_prefer", "--wsl-prefer")
+	}
+	
+	if rootDir != "" {
+		globalConfig.Root = rootDir
+	}
+	
+	if lockTimeoutSet {
+		globalConfig.Locks.Timeout = lockTimeout
+		globalConfig.SetByFlag("locks.timeout", "--lock-timeout")
+	}
+	
+	if ignoreBreakers && globalConfig.CircuitBreaker != nil {
+		globalConfig.CircuitBreaker.OpenProviders = errors.OpenProvidersIgnore
+		globalConfig.SetByFlag("circuit_breaker.open_providers", "--ignore-circuit-breakers")
 	}
 	
 	// Override confirmation settings based on --yes flag
@@ -201,16 +317,41 @@ func applyFlagOverrides() {
 		globalConfig.Confirmations.Upgrade = false
 		globalConfig.Confirmations.SystemChanges = false
 		globalConfig.Confirmations.ServiceOps = false
+		for _, key := range []string{"install", "uninstall", "upgrade", "system_changes", "service_ops"} {
+			globalConfig.SetByFlag("confirmations."+key, "--yes")
+		}
 	}
 	
 	// Override output settings based on flags
 	if quiet {
 		globalConfig.Output.ShowCommands = false
 		globalConfig.Output.ShowExitCodes = false
+		globalConfig.SetByFlag("output.show_commands", "--quiet")
+		globalConfig.SetByFlag("output.show_exit_codes", "--quiet")
 	} else if verbose {
 		globalConfig.Output.ShowCommands = true
 		globalConfig.Output.ShowExitCodes = true
+		globalConfig.SetByFlag("output.show_commands", "--verbose")
+		globalConfig.SetByFlag("output.show_exit_codes", "--verbose")
+	}
+}
+
+// downloadOptions returns the download settings of the configuration. Progress bars
+// are drawn on stderr when it is a terminal, unless output is quiet or JSON.
+func downloadOptions(cfg *config.Config) download.Options {
+	options := download.Options{
+		Proxy:          cfg.Downloads.Proxy,
+		Retries:        cfg.Downloads.Retries,
+		Backoff:        cfg.Downloads.Backoff,
+		Connections:    cfg.Downloads.Connections,
+		ChunkThreshold: cfg.Downloads.ChunkThreshold,
+	}
+	if cfg.Downloads.Progress && !quiet && !jsonOutput {
+		if info, err := os.Stderr.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			options.Progress = os.Stderr
+		}
 	}
+	return options
 }
 
 // GetGlobalConfig returns the global configuration instance
@@ -226,27 +367,37 @@ func GetGlobalDebugManager() *debug.DebugManager {
 // GetGlobalFlags returns the current global flag values
 func GetGlobalFlags() GlobalFlags {
 	return GlobalFlags{
-		Config:     cfgFile,
-		Provider:   providerFlag,
-		Verbose:    verbose,
-		DryRun:     dryRun,
-		Yes:        yes,
-		Quiet:      quiet,
-		JSONOutput: jsonOutput,
-		Debug:      debugFlag,
+		Config:      cfgFile,
+		Provider:    providerFlag,
+		Verbose:     verbose,
+		DryRun:      dryRun,
+		Yes:         yes,
+		Quiet:       quiet,
+		JSONOutput:  jsonOutput,
+		Debug:       debugFlag,
+		ReadOnly:    readOnly,
+		Interactive: interactive,
+		Root:        rootDir,
+		VarsFile:    varsFile,
+		Variables:   mergeVariables(nil, cliVariables),
 	}
 }
 
 // GlobalFlags represents the global command-line flags
 type GlobalFlags struct {
-	Config     string
-	Provider   string
-	Verbose    bool
-	DryRun     bool
-	Yes        bool
-	Quiet      bool
-	JSONOutput bool
-	Debug      bool
+	Config      string
+	Provider    string
+	Verbose     bool
+	DryRun      bool
+	Yes         bool
+	Quiet       bool
+	JSONOutput  bool
+	Debug       bool
+	ReadOnly    bool
+	Interactive bool // Full-screen terminal UI instead of line-based prompts
+	Root        string // Alternate root filesystem actions act on
+	VarsFile    string
+	Variables   map[string]string
 }
 
 // ValidateFlags performs validation on flag combinations and values
@@ -255,8 +406,8 @@ func ValidateFlags() error {
 	if providerFlag != "" {
 		validProviders := []string{
 			"apt", "brew", "dnf", "yum", "pacman", "zypper", "apk",
-			"docker", "helm", "npm", "pip", "cargo", "go", "gem",
-			"choco", "winget", "scoop", "flatpak", "snap",
+			"docker", "containerd", "kubernetes", "helm", "npm", "pip", "cargo", "go", "gem",
+			"choco", "winget", "scoop", "flatpak", "snap", "nix", "source",
 		}
 		
 		isValid := false
@@ -273,6 +424,17 @@ func ValidateFlags() error {
 		}
 	}
 
+	// --format replaces the output that --json would produce
+	if formatFlag != "" && jsonOutput {
+		return fmt.Errorf("--format and --json cannot be used together")
+	}
+
+	// Validate WSL provider preference
+	if wslPrefer != "" && wslPrefer != config.WSLPreferLinux && wslPrefer != config.WSLPreferWindows {
+		return fmt.Errorf("invalid --wsl-prefer '%s'. Valid values: %s, %s", 
+			wslPrefer, config.WSLPreferLinux, config.WSLPreferWindows)
+	}
+
 	// Validate config file exists if specified
 	if cfgFile != "" {
 		if _, err := os.Stat(cfgFile); os.IsNotExist(err) {
@@ -280,6 +442,39 @@ func ValidateFlags() error {
 		}
 	}
 
+	// Validate the alternate root, made absolute as commands run from other directories
+	if rootDir != "" {
+		absolute, err := filepath.Abs(rootDir)
+		if err != nil {
+			return fmt.Errorf("invalid --root '%s': %w", rootDir, err)
+		}
+		if info, err := os.Stat(absolute); err != nil || !info.IsDir() {
+			return fmt.Errorf("--root '%s' is not a directory", rootDir)
+		}
+		if strings.ContainsAny(absolute, " \t\n") {
+			return fmt.Errorf("--root '%s' cannot contain whitespace", rootDir)
+		}
+		rootDir = absolute
+	}
+
+	if lockTimeout < 0 {
+		return fmt.Errorf("--lock-timeout cannot be negative, got: %v", lockTimeout)
+	}
+
+	// Validate variables file exists if specified
+	if varsFile != "" {
+		if _, err := os.Stat(varsFile); os.IsNotExist(err) {
+			return fmt.Errorf("variables file '%s' does not exist", varsFile)
+		}
+	}
+
+	// Validate variable assignments
+	for _, assignment := range varFlags {
+		if _, _, err := config.ParseVariable(assignment); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -296,6 +491,8 @@ func SetupCompletion() {
 			"zypper\topenSUSE package manager",
 			"apk\tAlpine Linux package manager",
 			"docker\tDocker container manager",
+			"containerd\tcontainerd container runtime",
+			"kubernetes\tKubernetes deployments with kubectl",
 			"helm\tKubernetes package manager",
 			"npm\tNode.js package manager",
 			"pip\tPython package manager",
@@ -307,6 +504,7 @@ func SetupCompletion() {
 			"scoop\tWindows Scoop",
 			"flatpak\tLinux application distribution",
 			"snap\tUniversal Linux packages",
+			"nix\tNix functional package manager",
 		}
 		return providers, cobra.ShellCompDirectiveNoFileComp
 	})
@@ -323,6 +521,8 @@ func getEnvOverrides() map[string]string {
 	envVars := []string{
 		"SAI_SAIDATA_REPOSITORY", "SAI_DEFAULT_PROVIDER", "SAI_LOG_LEVEL",
 		"SAI_CACHE_DIR", "SAI_TIMEOUT", "SAI_OFFLINE_MODE", "SAI_AUTO_SETUP",
+		"SAI_ENVIRONMENT", "SAI_MANAGED_DIR", "SAI_READ_ONLY",
+		"SAI_WSL_PREFER", "SAI_AUDIT_PATH",
 	}
 	
 	for _, envVar := range envVars {
@@ -342,8 +542,13 @@ func configToMap(cfg *config.Config) map[string]interface{} {
 		"provider_priority":  cfg.ProviderPriority,
 		"timeout":            cfg.Timeout.String(),
 		"cache_dir":          cfg.CacheDir,
+		"managed_dir":        cfg.ManagedDir,
 		"log_level":          cfg.LogLevel,
+		"environment":        cfg.Environment,
+		"read_only":          cfg.ReadOnly,
+		"wsl_prefer":         cfg.WSLPrefer,
 		"confirmations":      cfg.Confirmations,
+		"risk_tiers":         cfg.RiskTiers,
 		"output":             cfg.Output,
 		"repository":         cfg.Repository,
 	}
@@ -1,12 +1,20 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"sai/internal/config"
 	"sai/internal/saidata"
+	"sai/internal/validation"
 )
 
 var saidataCmd = &cobra.Command{
@@ -22,13 +30,19 @@ across different operating systems and package managers. This command allows you
   • Synchronize with remote repository
   • Initialize or reinitialize the repository
   • Clean and reset the local repository
+  • Report coverage and quality statistics
+  • Propose local definitions upstream as pull requests
+  • Show where the values of the effective saidata come from
 
 Examples:
   sai saidata status          # Show repository status
   sai saidata update          # Update repository from remote
   sai saidata sync            # Synchronize with remote (alias for update)
   sai saidata init            # Initialize or reinitialize repository
-  sai saidata clean           # Remove local repository`,
+  sai saidata clean           # Remove local repository
+  sai saidata stats           # Show repository statistics
+  sai saidata contribute nginx  # Open a pull request with the local nginx definition
+  sai saidata show nginx --merged --diff  # Effective nginx saidata and the fields its OS override changed`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Default action is to show status
 		return runSaidataStatus(cmd, args)
@@ -58,6 +72,7 @@ var saidataUpdateCmd = &cobra.Command{
 
 For git-based repositories, this performs a 'git pull' to fetch the latest changes.
 For zip-based repositories, this re-downloads and extracts the latest archive.
+The git repositories added with 'sai repo add' are cloned again.
 
 The update process:
   1. Validates the current repository
@@ -122,6 +137,59 @@ Warning: This will remove the entire local repository and any local modification
 	RunE: runSaidataClean,
 }
 
+var saidataStatsCmd = &cobra.Command{
+	Use:   "stats [path]",
+	Short: "Show saidata repository statistics",
+	Long: `Report statistics about the software definitions of the saidata repository:
+
+  • Number of software definitions
+  • Coverage per provider (software with a provider specific configuration)
+  • Most common categories
+  • Definitions missing a description, license or URLs
+  • Largest definitions
+
+The statistics help maintainers track the quality of the repository and contributors
+choose what to improve next. Pass the path of a saidata checkout to analyze it instead
+of the local repository, and use --json for dashboards.
+
+Examples:
+  sai saidata stats                    # Statistics of the local repository
+  sai saidata stats ~/src/saidata      # Statistics of a checkout
+  sai saidata stats --top 20 --json    # Top 20 categories and definitions as JSON`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSaidataStats,
+}
+
+var saidataContributeCmd = &cobra.Command{
+	Use:   "contribute <software>",
+	Short: "Open a pull request with a local software definition",
+	Long: `Propose the local definition of a software to the upstream saidata repository.
+
+The contribution process:
+  1. Validates default.yaml against the saidata schema and parses the overrides
+  2. Formats the YAML files with 2 space indentation
+  3. Commits the definition on a new branch of a fresh clone of the repository
+  4. Pushes the branch to your fork, creating the fork if needed
+  5. Opens a pull request with a templated description
+
+GitHub is accessed through the gh CLI when installed, otherwise with the token of the
+GITHUB_TOKEN environment variable. The local repository is not changed. With --dry-run
+the definition is only validated and formatted.
+
+Examples:
+  sai saidata contribute nginx                      # Definition of the local repository
+  sai saidata contribute nginx --path ~/src/saidata # Definition of a checkout
+  sai saidata contribute nginx --dry-run            # Validate only`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSaidataContribute,
+}
+
+var (
+	saidataStatsTop        int
+	saidataContributePath  string
+	saidataContributeTitle string
+)
+
 func init() {
 	// Add saidata command to root
 	rootCmd.AddCommand(saidataCmd)
@@ -132,6 +200,12 @@ func init() {
 	saidataCmd.AddCommand(saidataSyncCmd)
 	saidataCmd.AddCommand(saidataInitCmd)
 	saidataCmd.AddCommand(saidataCleanCmd)
+	saidataCmd.AddCommand(saidataStatsCmd)
+	saidataCmd.AddCommand(saidataContributeCmd)
+
+	saidataStatsCmd.Flags().IntVar(&saidataStatsTop, "top", 10, "Number of categories and largest definitions to show")
+	saidataContributeCmd.Flags().StringVar(&saidataContributePath, "path", "", "Saidata checkout containing the definition (default: local repository)")
+	saidataContributeCmd.Flags().StringVar(&saidataContributeTitle, "title", "", "Pull request title (default: Add or Update <software> saidata)")
 }
 
 func runSaidataStatus(cmd *cobra.Command, args []string) error {
@@ -139,7 +213,7 @@ func runSaidataStatus(cmd *cobra.Command, args []string) error {
 	flags := GetGlobalFlags()
 	
 	// Create repository manager
-	repoManager := saidata.NewRepositoryManager(cfg.Repository.GitURL, cfg.Repository.ZipFallbackURL)
+	repoManager := newRepositoryManager(cfg)
 	
 	// Get repository status
 	status, err := repoManager.GetRepositoryStatus()
@@ -199,21 +273,22 @@ func runSaidataUpdate(cmd *cobra.Command, args []string) error {
 	cfg := GetGlobalConfig()
 	
 	// Create repository manager
-	repoManager := saidata.NewRepositoryManager(cfg.Repository.GitURL, cfg.Repository.ZipFallbackURL)
+	repoManager := newRepositoryManager(cfg)
 	
 	// Update repository
 	if err := repoManager.UpdateRepository(); err != nil {
 		return fmt.Errorf("failed to update repository: %w", err)
 	}
 	
-	return nil
+	// Then the repositories searched besides it
+	return updateRepositories(cfg)
 }
 
 func runSaidataSync(cmd *cobra.Command, args []string) error {
 	cfg := GetGlobalConfig()
 	
 	// Create repository manager
-	repoManager := saidata.NewRepositoryManager(cfg.Repository.GitURL, cfg.Repository.ZipFallbackURL)
+	repoManager := newRepositoryManager(cfg)
 	
 	// Synchronize repository
 	if err := repoManager.SynchronizeRepository(); err != nil {
@@ -228,7 +303,7 @@ func runSaidataInit(cmd *cobra.Command, args []string) error {
 	flags := GetGlobalFlags()
 	
 	// Create repository manager
-	repoManager := saidata.NewRepositoryManager(cfg.Repository.GitURL, cfg.Repository.ZipFallbackURL)
+	repoManager := newRepositoryManager(cfg)
 	
 	// Check if repository already exists
 	status, err := repoManager.GetRepositoryStatus()
@@ -252,8 +327,10 @@ func runSaidataInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to show welcome message: %w", err)
 	}
 	
-	// Initialize repository
-	if err := repoManager.InitializeRepository(); err != nil {
+	// Initialize repository, interrupting cancels the download
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := repoManager.InitializeRepository(ctx); err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
 	
@@ -265,7 +342,7 @@ func runSaidataClean(cmd *cobra.Command, args []string) error {
 	flags := GetGlobalFlags()
 	
 	// Create repository manager
-	repoManager := saidata.NewRepositoryManager(cfg.Repository.GitURL, cfg.Repository.ZipFallbackURL)
+	repoManager := newRepositoryManager(cfg)
 	
 	// Get current status
 	status, err := repoManager.GetRepositoryStatus()
@@ -296,4 +373,188 @@ func runSaidataClean(cmd *cobra.Command, args []string) error {
 	
 	fmt.Println("ℹ️  SAI will automatically re-download the repository on next use.")
 	return nil
-}
\ No newline at end of file
+}
+
+func runSaidataStats(cmd *cobra.Command, args []string) error {
+	flags := GetGlobalFlags()
+
+	path := saidata.GetSaidataPath()
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return fmt.Errorf("saidata repository not found at %s, run 'sai saidata init' first", path)
+	}
+
+	stats, err := saidata.CollectStats(os.DirFS(path), saidataStatsTop)
+	if err != nil {
+		return err
+	}
+
+	if flags.JSONOutput {
+		jsonData, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal statistics to JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	fmt.Println("📊 Saidata Repository Statistics")
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Printf("Path:          %s\n", path)
+	fmt.Printf("Software:      %d\n", stats.Software)
+	if len(stats.Invalid) > 0 {
+		fmt.Printf("Invalid:       %d\n", len(stats.Invalid))
+	}
+
+	fmt.Println("\nProvider coverage:")
+	for _, coverage := range stats.Providers {
+		fmt.Printf("  %-12s %5d  %5.1f%%\n", coverage.Provider, coverage.Software, coverage.Percent)
+	}
+
+	fmt.Println("\nTop categories:")
+	for _, category := range stats.Categories {
+		fmt.Printf("  %-24s %5d\n", category.Category, category.Software)
+	}
+
+	fmt.Println("\nMissing metadata:")
+	missing := []struct {
+		label    string
+		software []string
+	}{
+		{"description", stats.MissingDescription},
+		{"license", stats.MissingLicense},
+		{"urls", stats.MissingURLs},
+	}
+	for _, item := range missing {
+		fmt.Printf("  %-12s %5d", item.label, len(item.software))
+		if flags.Verbose && len(item.software) > 0 {
+			fmt.Printf("  %s", strings.Join(item.software, ", "))
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("\nLargest definitions:")
+	for _, definition := range stats.Largest {
+		fmt.Printf("  %-24s %7d bytes  %3d resources\n", definition.Software, definition.Bytes, definition.Resources)
+	}
+
+	for _, path := range stats.Oversized {
+		fmt.Printf("\n⚠️  Large definition (over %d KB): %s", saidata.LargeDefinitionSize/1024, path)
+	}
+	if flags.Verbose {
+		for _, path := range stats.Invalid {
+			fmt.Printf("\n⚠️  Invalid definition: %s", path)
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+func runSaidataContribute(cmd *cobra.Command, args []string) error {
+	cfg := GetGlobalConfig()
+	flags := GetGlobalFlags()
+	software := args[0]
+
+	path := saidataContributePath
+	if path == "" {
+		path = saidata.GetSaidataPath()
+	}
+
+	validator, err := validation.NewSaidataValidator("schemas/saidata-0.2-schema.json")
+	if err != nil {
+		fmt.Printf("⚠️  Could not load schema validator, skipping schema validation: %v\n", err)
+	}
+
+	contribution, err := saidata.PrepareContribution(path, software, validator)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Definition of %s is valid\n", software)
+	for _, name := range contribution.FileNames() {
+		fmt.Printf("  %s\n", name)
+	}
+
+	if flags.DryRun {
+		fmt.Printf("Dry run: a pull request would be opened against %s\n", cfg.Repository.GitURL)
+		return nil
+	}
+
+	if !flags.Yes {
+		fmt.Printf("Open a pull request against %s? (y/N): ", cfg.Repository.GitURL)
+
+		var response string
+		fmt.Scanln(&response)
+
+		if response != "y" && response != "Y" && response != "yes" && response != "Yes" {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+	}
+
+	url, err := saidata.OpenPullRequest(contribution, saidata.ContributeOptions{
+		GitURL: cfg.Repository.GitURL,
+		Title:  saidataContributeTitle,
+		Token:  os.Getenv("GITHUB_TOKEN"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to contribute %s: %w", software, err)
+	}
+
+	fmt.Printf("🚀 Pull request opened: %s\n", url)
+	return nil
+}
+
+// newRepositoryManager creates a repository manager syncing the configured saidata subset
+func newRepositoryManager(cfg *config.Config) *saidata.RepositoryManager {
+	repoManager := saidata.NewRepositoryManager(cfg.Repository.GitURL, cfg.Repository.ZipFallbackURL)
+	repoManager.SetSparseFilter(sparseFilter(cfg))
+	repoManager.SetVerification(signatureVerification(cfg))
+	repoManager.SetProgress(bootstrapProgress())
+	repoManager.SetDiskCache(saidataCache(cfg))
+	repoManager.SetOffline(cfg.Repository.OfflineMode)
+	return repoManager
+}
+
+// saidataCache returns the on-disk cache of resolved saidata, nil when disabled
+func saidataCache(cfg *config.Config) *saidata.DiskCache {
+	if !cfg.SaidataCache.Enabled {
+		return nil
+	}
+	return saidata.NewDiskCache(filepath.Join(cfg.CacheDir, saidataCacheDir), cfg.SaidataCache.TTL)
+}
+
+// bootstrapProgress returns where saidata downloads report progress: nowhere in quiet
+// mode, and stderr with JSON output to keep stdout parseable
+func bootstrapProgress() io.Writer {
+	flags := GetGlobalFlags()
+	if flags.Quiet {
+		return nil
+	}
+	if flags.JSONOutput {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// sparseFilter returns the saidata subset selected by the repository configuration
+func sparseFilter(cfg *config.Config) saidata.SparseFilter {
+	return saidata.SparseFilter{
+		Software:   cfg.Repository.Sparse.Software,
+		Categories: cfg.Repository.Sparse.Categories,
+	}
+}
+
+// signatureVerification returns how downloaded saidata is verified, accepting
+// unverified saidata with --insecure-saidata
+func signatureVerification(cfg *config.Config) saidata.Verification {
+	return saidata.Verification{
+		Method:   cfg.Repository.Signature.Method,
+		Keyring:  cfg.Repository.Signature.Keyring,
+		Identity: cfg.Repository.Signature.Identity,
+		Issuer:   cfg.Repository.Signature.Issuer,
+		Insecure: insecureSaidata,
+	}
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"sai/internal/platform"
+	"sai/internal/saidata"
+)
+
+var (
+	saidataShowMerged    bool
+	saidataShowDiff      bool
+	saidataShowOS        string
+	saidataShowOSVersion string
+)
+
+var saidataShowCmd = &cobra.Command{
+	Use:   "show <software>",
+	Short: "Show the saidata of a software and where its values come from",
+	Long: `Show the saidata of a software: its base definition by default, the effective
+saidata after merging the platform, distribution family, OS and architecture overrides
+with --merged, and the fields the overrides or the architecture changed with --diff.
+
+The merged saidata is commented with the source of each field not coming from the
+base definition. --diff lists the base, override and merged value of each changed
+field: + for fields the overrides add, ~ for changed values and - for fields dropped
+from the effective saidata. Use --os and --os-version to inspect the overrides of
+another OS than the detected one.
+
+Examples:
+  sai saidata show nginx                         # Base definition
+  sai saidata show nginx --merged                # Effective saidata with field sources
+  sai saidata show nginx --merged --diff         # Effective saidata and changed fields
+  sai saidata show nginx --diff --os ubuntu --os-version 22.04
+  sai saidata show nginx --json                  # Layers and per-field provenance`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSaidataShow,
+}
+
+func init() {
+	saidataShowCmd.Flags().BoolVar(&saidataShowMerged, "merged", false, "show the effective saidata after merging the overrides")
+	saidataShowCmd.Flags().BoolVar(&saidataShowDiff, "diff", false, "show the fields changed by the overrides and the architecture")
+	saidataShowCmd.Flags().StringVar(&saidataShowOS, "os", "", "OS whose overrides are merged (detected by default)")
+	saidataShowCmd.Flags().StringVar(&saidataShowOSVersion, "os-version", "", "OS version whose overrides are merged (detected by default)")
+
+	saidataCmd.AddCommand(saidataShowCmd)
+}
+
+func runSaidataShow(cmd *cobra.Command, args []string) error {
+	flags := GetGlobalFlags()
+	software := args[0]
+
+	osInfo, err := platform.Detect()
+	if err != nil {
+		return fmt.Errorf("failed to detect the OS: %w", err)
+	}
+	target := *osInfo
+	if saidataShowOS != "" {
+		target.OS = strings.ToLower(saidataShowOS)
+		target.Version = saidataShowOSVersion
+		target.Platform = platform.PlatformForOS(target.OS)
+		target.IDLike = nil // The family of the OS is known, the parents of the host are not its own
+	} else if saidataShowOSVersion != "" {
+		target.Version = saidataShowOSVersion
+	}
+
+	layers, err := newSaidataManager(GetGlobalConfig(), saidata.GetSaidataPath()).LoadSoftwareLayers(software, &target)
+	if err != nil {
+		return err
+	}
+
+	if flags.JSONOutput {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"software":       layers.Software,
+			"os":             target.OS,
+			"os_version":     target.Version,
+			"architecture":   layers.Architecture,
+			"base_path":      layers.BasePath,
+			"override_path":  layers.OverridePath,
+			"override_label": layers.OverrideLabel,
+			"overrides":      layers.Overrides,
+			"merged":         layers.Merged,
+			"fields":         layers.Provenance(),
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal saidata to JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	fmt.Printf("# Base:     %s\n", layers.BasePath)
+	for _, override := range layers.Overrides {
+		fmt.Printf("# Override: %s (%s)\n", override.Path, override.Label)
+	}
+	if len(layers.Overrides) == 0 {
+		fmt.Printf("# Override: none for %s %s\n", target.OS, target.Version)
+	}
+	fmt.Println()
+
+	switch {
+	case saidataShowMerged:
+		data, err := layers.AnnotatedYAML()
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+	case !saidataShowDiff:
+		data, err := yaml.Marshal(layers.Base)
+		if err == nil {
+			data, err = saidata.FormatSaidataYAML(data)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode saidata: %w", err)
+		}
+		fmt.Print(string(data))
+	}
+
+	if saidataShowDiff {
+		if saidataShowMerged {
+			fmt.Println()
+		}
+		displayProvenanceDiff(layers.Provenance())
+	}
+	return nil
+}
+
+// displayProvenanceDiff lists the base, override and merged values of the fields whose
+// effective value is not the base one
+func displayProvenanceDiff(fields []saidata.FieldProvenance) {
+	value := func(v *string) string {
+		if v == nil {
+			return "<absent>"
+		}
+		return *v
+	}
+
+	changed := 0
+	for _, field := range fields {
+		if !field.Changed() {
+			continue
+		}
+		changed++
+
+		marker := "~"
+		if field.Source == saidata.SourceDropped {
+			marker = "-"
+		} else if field.Base == nil {
+			marker = "+"
+		}
+		fmt.Printf("%s %s\n", marker, field.Path)
+		fmt.Printf("    base:     %s\n", value(field.Base))
+		fmt.Printf("    override: %s\n", value(field.Override))
+		fmt.Printf("    merged:   %s (%s)\n", value(field.Merged), field.Source)
+	}
+	if changed == 0 {
+		fmt.Println("No field differs from the base definition")
+	}
+}
@@ -19,12 +19,14 @@ The search will query all available providers and display results showing:
 - Provider name
 - Package name
 - Available version
+- Repository, tap or origin of the package (if the provider tells)
 - Description (if available)
 
 Examples:
   sai search nginx                     # Search for nginx across all providers
   sai search nginx --provider apt      # Search for nginx only in apt repositories
-  sai search nginx --json              # Output search results in JSON format`,
+  sai search nginx --json              # Output search results in JSON format
+  sai search nginx --format '{{.Provider}} {{.PackageName}} {{.Version}}'`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return executeSearchCommand(args[0])
@@ -70,6 +72,9 @@ func executeSearchCommand(software string) error {
 	}
 
 	// Display results
+	if formatFlag != "" {
+		return printFormatted(searchResults)
+	}
 	if flags.JSONOutput {
 		fmt.Println(formatter.FormatJSON(map[string]interface{}{
 			"software": software,
@@ -83,10 +88,13 @@ func executeSearchCommand(software string) error {
 		}
 
 		formatter.ShowInfo(fmt.Sprintf("Found %d package(s) for '%s':", len(searchResults), software))
+		if notice := searchResults[0].Deprecated; notice != "" {
+			formatter.ShowWarning(notice)
+		}
 		fmt.Println()
 
 		// Display results in table format
-		headers := []string{"Provider", "Package", "Version", "Available", "Description"}
+		headers := []string{"Provider", "Package", "Version", "Repository", "Available", "Description"}
 		var rows [][]string
 
 		for _, result := range searchResults {
@@ -99,6 +107,7 @@ func executeSearchCommand(software string) error {
 				formatter.FormatProviderName(result.Provider),
 				result.PackageName,
 				result.Version,
+				result.Repository,
 				availability,
 				result.Description,
 			}
@@ -137,5 +146,6 @@ func executeSearchCommand(software string) error {
 }
 
 func init() {
+	addFormatFlag(searchCmd)
 	rootCmd.AddCommand(searchCmd)
 }
\ No newline at end of file
@@ -35,7 +35,7 @@ func executeServiceCommand(action string, software string) error {
 		Yes:       flags.Yes,
 		JSON:      flags.JSONOutput,
 		Config:    flags.Config,
-		Variables: make(map[string]string),
+		Variables: flags.Variables,
 		Timeout:   config.Timeout,
 	}
 
@@ -63,6 +63,7 @@ func executeServiceCommand(action string, software string) error {
 
 	result, err := actionManager.ExecuteAction(ctx, action, software, options)
 	if err != nil {
+		offerSudoReexec(err, userInterface, formatter)
 		formatter.ShowError(fmt.Errorf("%s failed: %w", action, err))
 		if result != nil {
 			os.Exit(result.ExitCode)
@@ -109,8 +110,9 @@ func executeServiceCommand(action string, software string) error {
 			formatter.ShowError(fmt.Errorf("failed to %s %s service: %s", action, software, result.Error))
 		}
 
-		// Show command output if verbose or for information-only commands
-		if (flags.Verbose || config.IsInformationOnlyAction(action)) && result.Output != "" {
+		// Show command output if verbose or for information-only commands, unless it was
+		// streamed as it ran
+		if (flags.Verbose || config.IsInformationOnlyAction(action)) && result.Output != "" && !result.Streamed {
 			if !config.IsInformationOnlyAction(action) {
 				fmt.Println("\nCommand output:")
 			}
@@ -151,7 +153,7 @@ func executeGeneralSystemCommand(action string) error {
 		Yes:       flags.Yes,
 		JSON:      flags.JSONOutput,
 		Config:    flags.Config,
-		Variables: make(map[string]string),
+		Variables: flags.Variables,
 		Timeout:   config.Timeout,
 	}
 
@@ -39,17 +39,17 @@ func TestServiceCommandProperties(t *testing.T) {
 	// Test start command properties
 	assert.Equal(t, "start [software]", startCmd.Use)
 	assert.Equal(t, "Start software service", startCmd.Short)
-	assert.Equal(t, 1, startCmd.Args(nil, []string{"nginx"})) // ExactArgs(1)
+	assert.NoError(t, startCmd.Args(nil, []string{"nginx"})) // ExactArgs(1)
 	
 	// Test stop command properties
 	assert.Equal(t, "stop [software]", stopCmd.Use)
 	assert.Equal(t, "Stop software service", stopCmd.Short)
-	assert.Equal(t, 1, stopCmd.Args(nil, []string{"nginx"})) // ExactArgs(1)
+	assert.NoError(t, stopCmd.Args(nil, []string{"nginx"})) // ExactArgs(1)
 	
 	// Test status command properties (information-only)
 	assert.Equal(t, "status [software]", statusCmd.Use)
-	assert.Equal(t, "Check software service status", statusCmd.Short)
-	assert.Equal(t, 1, statusCmd.Args(nil, []string{"nginx"})) // ExactArgs(1)
+	assert.Equal(t, "Show the status of software and its resources", statusCmd.Short)
+	assert.NoError(t, statusCmd.Args(nil, []string{"nginx"})) // ExactArgs(1)
 	
 	// Test logs command properties (can work with or without software parameter)
 	assert.Equal(t, "logs [software]", logsCmd.Use)
@@ -89,7 +89,7 @@ func TestServiceCommandExamples(t *testing.T) {
 	
 	assert.Contains(t, statusCmd.Long, "sai status nginx")
 	assert.Contains(t, statusCmd.Long, "sai status nginx --json")
-	assert.Contains(t, statusCmd.Long, "sai status nginx --verbose")
+	assert.Contains(t, statusCmd.Long, "sai status nginx --provider systemd")
 	
 	assert.Contains(t, logsCmd.Long, "sai logs nginx")
 	assert.Contains(t, logsCmd.Long, "sai logs")
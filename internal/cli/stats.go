@@ -67,6 +67,8 @@ type ProviderStats struct {
 	Executable   string   `json:"executable,omitempty"`
 	Status       string   `json:"status"`
 	Error        string   `json:"error,omitempty"`
+	// OpenCircuitBreakers are the actions whose circuit breaker is open after repeated failures
+	OpenCircuitBreakers []string `json:"open_circuit_breakers,omitempty"`
 }
 
 // ActionStats represents statistics about available actions
@@ -245,8 +247,12 @@ func getProviderStats(actionManager interfaces.ActionManager) []ProviderStats {
 		// Determine status and error message
 		status := "not available"
 		errorMsg := ""
+		openBreakers := actionManager.GetOpenCircuitBreakers(provider.Provider.Name)
 		if available {
 			status = "available"
+			if len(openBreakers) > 0 {
+				status = fmt.Sprintf("available (circuit open: %s)", strings.Join(openBreakers, ", "))
+			}
 		} else {
 			// Fallback error messages based on provider configuration
 			if provider.Provider.Executable != "" {
@@ -276,6 +282,7 @@ func getProviderStats(actionManager interfaces.ActionManager) []ProviderStats {
 			Executable:   provider.Provider.Executable,
 			Status:       status,
 			Error:        errorMsg,
+			OpenCircuitBreakers: openBreakers,
 		}
 		
 		stats = append(stats, stat)
@@ -1,30 +1,87 @@
 package cli
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
 	"github.com/spf13/cobra"
+	"sai/internal/interfaces"
+	"sai/internal/output"
 )
 
 // statusCmd represents the status command
 var statusCmd = &cobra.Command{
 	Use:   "status [software]",
-	Short: "Check software service status",
-	Long: `Check the current status of the service for the specified software.
-This command will display service status using the appropriate service manager (systemd, launchd, etc.).
+	Short: "Show the status of software and its resources",
+	Long: `Show the status of the specified software in one table: the packages installed by
+each provider with their version, whether its services are active and enabled, its
+ports listening, its containers running and its files, directories and commands present,
+as declared in its saidata.
 
 This is an information-only command that executes without confirmation prompts.
-The status includes whether the service is running, enabled, and other relevant information.
+With --provider, the status action of the provider is run instead, such as
+systemctl status for systemd.
+
+The exit code is 1 when a resource is not in its expected state.
 
 Examples:
-  sai status nginx                     # Check nginx service status
+  sai status nginx                     # Show the status of nginx
   sai status nginx --json              # Output status in JSON format
-  sai status nginx --provider systemd  # Use specific provider for service management
-  sai status nginx --verbose           # Show detailed status information`,
+  sai status nginx --provider systemd  # Run the status action of systemd`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return executeServiceCommand("status", args[0])
+		if GetGlobalFlags().Provider != "" {
+			return executeServiceCommand("status", args[0])
+		}
+		return executeStatusCommand(args[0])
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(statusCmd)
-}
\ No newline at end of file
+}
+
+// executeStatusCommand shows the consolidated status of a software
+func executeStatusCommand(software string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, _, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	status, err := actionManager.GetSoftwareStatus(context.Background(), software)
+	if err != nil {
+		return err
+	}
+
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(status))
+	} else if err := displayStatus(status); err != nil {
+		return err
+	}
+
+	if !status.Healthy() {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// displayStatus shows the packages and resources of a software as a table
+func displayStatus(status *interfaces.SoftwareStatus) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAME\tSTATE\tDETAIL")
+	for _, resource := range status.Resources {
+		detail := resource.Detail
+		if detail == "" {
+			detail = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", resource.Type, resource.Name, resource.State, detail)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sai/internal/config"
+	saierrors "sai/internal/errors"
+	"sai/internal/output"
+	"sai/internal/privilege"
+	"sai/internal/ui"
+)
+
+// offerSudoReexec offers to re-run sai with sudo when an action was refused because it
+// requires root and privilege.escalation is reexec. Nothing was changed by the refused
+// action, so the same command line is re-run as is. It only returns when sai was not
+// re-run, otherwise sai exits with the exit code of the re-run.
+func offerSudoReexec(err error, userInterface *ui.UserInterface, formatter *output.OutputFormatter) {
+	var saiErr *saierrors.SAIError
+	if !errors.As(err, &saiErr) || saiErr.Type != saierrors.ErrorTypeRootRequired {
+		return
+	}
+	if cfg := GetGlobalConfig(); cfg != nil && cfg.Privilege.Escalation != privilege.EscalationReexec {
+		return
+	}
+
+	sudo, lookErr := exec.LookPath("sudo")
+	if lookErr != nil {
+		return
+	}
+
+	flags := GetGlobalFlags()
+	if !flags.Yes {
+		confirmed, promptErr := userInterface.PromptForConfirmation(fmt.Sprintf("%s, re-run with sudo?", saiErr.Message))
+		if promptErr != nil || !confirmed {
+			return
+		}
+	}
+
+	executable, exeErr := os.Executable()
+	if exeErr != nil {
+		formatter.ShowError(fmt.Errorf("failed to re-run with sudo: %w", exeErr))
+		return
+	}
+
+	// Without --config, pass the configuration file found for the user: root has
+	// another home directory
+	configFile := ""
+	if flags.Config == "" {
+		configFile = config.FindConfigFile("")
+	}
+
+	cmd := exec.Command(sudo, sudoArgs(executable, os.Args, os.Environ(), configFile)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	runErr := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if runErr != nil {
+		formatter.ShowError(fmt.Errorf("failed to re-run with sudo: %w", runErr))
+		return
+	}
+	os.Exit(0)
+}
+
+// sudoArgs returns the sudo arguments re-running sai with the same arguments, keeping
+// the SAI_* environment variables that sudo resets otherwise
+func sudoArgs(executable string, argv []string, environ []string, configFile string) []string {
+	var preserve []string
+	for _, entry := range environ {
+		name := strings.SplitN(entry, "=", 2)[0]
+		if strings.HasPrefix(name, "SAI_") {
+			preserve = append(preserve, name)
+		}
+	}
+	sort.Strings(preserve)
+
+	var args []string
+	if len(preserve) > 0 {
+		args = append(args, "--preserve-env="+strings.Join(preserve, ","))
+	}
+	args = append(args, executable)
+	if configFile != "" {
+		if absolute, err := filepath.Abs(configFile); err == nil {
+			configFile = absolute
+		}
+		args = append(args, "--config", configFile)
+	}
+	if len(argv) > 1 {
+		args = append(args, argv[1:]...)
+	}
+	return args
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sai/internal/saidata"
+	"sai/internal/upgradetest"
+)
+
+var (
+	upgradeTestFrom    string
+	upgradeTestTo      string
+	upgradeTestImage   string
+	upgradeTestRuntime string
+	upgradeTestBinary  string
+	upgradeTestKeep    bool
+)
+
+// testCmd represents the test command
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Test saidata and provider actions",
+	Long: `Test saidata and provider actions in throwaway containers, without changing the
+system sai runs on.`,
+}
+
+// testUpgradeCmd represents the test upgrade command
+var testUpgradeCmd = &cobra.Command{
+	Use:   "upgrade <software>",
+	Short: "Test the upgrade path of software in a container",
+	Long: `Test the upgrade path of software in a new container: install the --from version,
+verify it is installed, run the upgrade action to the --to version and verify the
+upgraded version.
+
+The versions are passed to the install and upgrade actions as the version variable,
+so the action templates must pin it, for example with {{with .Variables.version}}.
+Versions may use wildcards, 1.x matches any 1 version. The sai binary, the saidata
+repository and the providers and schemas directories of the current directory are
+mounted read-only into the container. The container is removed afterwards unless
+--keep is set.
+
+Requires docker or podman. On other systems than Linux, --binary must point to a
+Linux sai executable.
+
+Examples:
+  sai test upgrade nginx --from 1.18.x --to 1.24                  # Test with the detected provider
+  sai test upgrade nginx --from 1.x --to 2.x --provider apt       # Test the apt upgrade path
+  sai test upgrade redis --from 6.x --to 7.x --image debian:12   # Test in another image
+  sai test upgrade nginx --from 1.18.x --to 1.24 --keep --json   # Keep the container, report in JSON`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTestUpgrade,
+}
+
+func init() {
+	testUpgradeCmd.Flags().StringVar(&upgradeTestFrom, "from", "", "version installed before the upgrade")
+	testUpgradeCmd.Flags().StringVar(&upgradeTestTo, "to", "", "version expected after the upgrade")
+	testUpgradeCmd.Flags().StringVar(&upgradeTestImage, "image", "ubuntu:24.04", "container image the test runs in")
+	testUpgradeCmd.Flags().StringVar(&upgradeTestRuntime, "runtime", "", "container runtime, docker or podman (detected by default)")
+	testUpgradeCmd.Flags().StringVar(&upgradeTestBinary, "binary", "", "Linux sai executable mounted into the container (this executable by default)")
+	testUpgradeCmd.Flags().BoolVar(&upgradeTestKeep, "keep", false, "leave the container running after the test")
+	testUpgradeCmd.MarkFlagRequired("from")
+	testUpgradeCmd.MarkFlagRequired("to")
+
+	testCmd.AddCommand(testUpgradeCmd)
+	rootCmd.AddCommand(testCmd)
+}
+
+func runTestUpgrade(cmd *cobra.Command, args []string) error {
+	flags := GetGlobalFlags()
+	software := args[0]
+
+	binary := upgradeTestBinary
+	if binary == "" {
+		if runtime.GOOS != "linux" {
+			return fmt.Errorf("--binary is required on %s, the container needs a Linux sai executable", runtime.GOOS)
+		}
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the sai executable: %w", err)
+		}
+		binary = executable
+	}
+	binary, err := filepath.Abs(binary)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", binary, err)
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get the current directory: %w", err)
+	}
+
+	saidataDir := saidata.GetSaidataPath()
+	opts := upgradetest.Options{
+		Software:   software,
+		From:       upgradeTestFrom,
+		To:         upgradeTestTo,
+		Provider:   flags.Provider,
+		Image:      upgradeTestImage,
+		Runtime:    upgradeTestRuntime,
+		Binary:     binary,
+		SaidataDir: saidataDir,
+		WorkDir:    workDir,
+		Keep:       upgradeTestKeep,
+		Output:     os.Stdout,
+	}
+	if flags.Quiet || flags.JSONOutput {
+		opts.Output = io.Discard
+	}
+
+	// The installed software is listed under its package names
+	if sd, err := saidata.NewManager(saidataDir).LoadSoftware(software); err == nil {
+		for _, pkg := range sd.Packages {
+			opts.Packages = append(opts.Packages, pkg.GetPackageNameOrDefault())
+		}
+	}
+
+	report, runErr := upgradetest.Run(context.Background(), opts)
+	if report == nil {
+		return runErr
+	}
+
+	if flags.JSONOutput {
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal upgrade test report to JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+	} else if !flags.Quiet {
+		fmt.Println()
+		for _, phase := range report.Phases {
+			status := "ok"
+			if !phase.Success {
+				status = "failed"
+			}
+			fmt.Printf("%-15s %-7s %s\n", phase.Name, status, phase.Duration.Round(time.Second))
+		}
+		if upgradeTestKeep {
+			fmt.Printf("\nContainer %s kept, remove it with '%s rm -f %s'\n", report.Container, strings.Fields(report.Phases[0].Command)[0], report.Container)
+		}
+		if runErr == nil {
+			fmt.Printf("\n✅ %s upgraded from %s to %s\n", software, report.InstalledVersion, report.UpgradedVersion)
+		}
+	}
+	return runErr
+}
@@ -10,9 +10,11 @@ import (
 	"sai/internal/output"
 )
 
+var uninstallPurge bool
+
 // uninstallCmd represents the uninstall command
 var uninstallCmd = &cobra.Command{
-	Use:   "uninstall [software]",
+	Use:   "uninstall [software...]",
 	Short: "Uninstall software packages",
 	Long: `Uninstall software packages using the appropriate provider.
 If software is installed using different providers, SAI will provide a list for user selection.
@@ -21,9 +23,19 @@ Examples:
   sai uninstall nginx                    # Uninstall nginx using detected provider
   sai uninstall nginx --provider apt     # Uninstall nginx using apt provider
   sai uninstall nginx --yes              # Uninstall nginx without confirmation prompts
-  sai uninstall nginx --dry-run          # Show what would be executed without uninstalling`,
-	Args: cobra.ExactArgs(1),
+  sai uninstall nginx --dry-run          # Show what would be executed without uninstalling
+  sai uninstall nginx redis curl --yes   # Uninstall several software concurrently
+  sai uninstall nginx redis --keep-going # Continue with the others when one fails
+  sai uninstall nginx --purge            # Also remove the configuration, data and service of nginx
+
+With --purge, the services, containers, files and directories saidata declares that
+are left after the package removal are listed and, once confirmed, stopped and removed.
+System directories such as /etc or /var/lib are never removed.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return executeBatchCommand("uninstall", args)
+		}
 		return executeUninstallCommand(args[0])
 	},
 }
@@ -52,8 +64,9 @@ func executeUninstallCommand(software string) error {
 		Yes:       flags.Yes,
 		JSON:      flags.JSONOutput,
 		Config:    flags.Config,
-		Variables: make(map[string]string),
+		Variables: flags.Variables,
 		Timeout:   config.Timeout,
+		Purge:     uninstallPurge,
 	}
 
 	// Provider selection is now handled by the Action Manager (Requirements 15.1, 15.3, 15.4)
@@ -74,6 +87,7 @@ func executeUninstallCommand(software string) error {
 
 	result, err := actionManager.ExecuteAction(ctx, "uninstall", software, options)
 	if err != nil {
+		offerSudoReexec(err, userInterface, formatter)
 		formatter.ShowError(fmt.Errorf("uninstallation failed: %w", err))
 		os.Exit(result.ExitCode)
 		return err
@@ -116,8 +130,8 @@ func executeUninstallCommand(software string) error {
 			formatter.ShowError(fmt.Errorf("failed to uninstall %s: %s", software, result.Error))
 		}
 
-		// Show command output if verbose
-		if flags.Verbose && result.Output != "" {
+		// Show command output if verbose, unless it was streamed as it ran
+		if flags.Verbose && result.Output != "" && !result.Streamed {
 			fmt.Println("\nCommand output:")
 			fmt.Println(result.Output)
 		}
@@ -132,5 +146,7 @@ func executeUninstallCommand(software string) error {
 }
 
 func init() {
+	addBatchFlags(uninstallCmd)
+	uninstallCmd.Flags().BoolVar(&uninstallPurge, "purge", false, "also remove the services, containers, files and directories declared in saidata")
 	rootCmd.AddCommand(uninstallCmd)
 }
\ No newline at end of file
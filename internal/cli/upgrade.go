@@ -12,7 +12,7 @@ import (
 
 // upgradeCmd represents the upgrade command
 var upgradeCmd = &cobra.Command{
-	Use:   "upgrade [software]",
+	Use:   "upgrade [software...]",
 	Short: "Upgrade software packages to latest version",
 	Long: `Upgrade software packages to their latest version using the appropriate provider.
 The system will detect which provider was used to install the software and use that for upgrading.
@@ -21,9 +21,14 @@ Examples:
   sai upgrade nginx                    # Upgrade nginx using detected provider
   sai upgrade nginx --provider apt     # Upgrade nginx using apt provider
   sai upgrade nginx --yes              # Upgrade nginx without confirmation prompts
-  sai upgrade nginx --dry-run          # Show what would be executed without upgrading`,
-	Args: cobra.ExactArgs(1),
+  sai upgrade nginx --dry-run          # Show what would be executed without upgrading
+  sai upgrade nginx redis curl --yes   # Upgrade several software concurrently
+  sai upgrade nginx redis --keep-going # Continue with the others when one fails`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return executeBatchCommand("upgrade", args)
+		}
 		return executeUpgradeCommand(args[0])
 	},
 }
@@ -52,7 +57,7 @@ func executeUpgradeCommand(software string) error {
 		Yes:       flags.Yes,
 		JSON:      flags.JSONOutput,
 		Config:    flags.Config,
-		Variables: make(map[string]string),
+		Variables: flags.Variables,
 		Timeout:   config.Timeout,
 	}
 
@@ -74,6 +79,7 @@ func executeUpgradeCommand(software string) error {
 
 	result, err := actionManager.ExecuteAction(ctx, "upgrade", software, options)
 	if err != nil {
+		offerSudoReexec(err, userInterface, formatter)
 		formatter.ShowError(fmt.Errorf("upgrade failed: %w", err))
 		os.Exit(result.ExitCode)
 		return err
@@ -116,8 +122,8 @@ func executeUpgradeCommand(software string) error {
 			formatter.ShowError(fmt.Errorf("failed to upgrade %s: %s", software, result.Error))
 		}
 
-		// Show command output if verbose
-		if flags.Verbose && result.Output != "" {
+		// Show command output if verbose, unless it was streamed as it ran
+		if flags.Verbose && result.Output != "" && !result.Streamed {
 			fmt.Println("\nCommand output:")
 			fmt.Println(result.Output)
 		}
@@ -132,5 +138,6 @@ func executeUpgradeCommand(software string) error {
 }
 
 func init() {
+	addBatchFlags(upgradeCmd)
 	rootCmd.AddCommand(upgradeCmd)
 }
\ No newline at end of file
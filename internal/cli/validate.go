@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sai/internal/output"
+	"sai/internal/saidata"
+	"sai/internal/validation"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate <path|software>",
+	Short: "Check saidata for errors before publishing it",
+	Long: `Check a saidata file the way sai uses it, a linter for saidata authors:
+
+  • Validates the file against the saidata schema
+  • Renders the templates of every action of every provider with the saidata, in
+    safety mode and without running anything, and reports the values left
+    unresolved and the packages, services, files and other resources missing
+  • Checks the compatibility matrix: providers exist, support the platforms the
+    matrix claims, architectures are known and entries do not contradict each other
+
+Problems are reported with their line in the file. Template problems are errors when
+they break the install with a provider the saidata is written for, one it has a
+section for or that the compatibility matrix supports, and warnings otherwise.
+
+Pass the path of a saidata file, or of the directory holding its default.yaml, or the
+name of a software of the saidata repository. --provider renders the templates of one
+provider only. The exit code is 1 when an error is found.
+
+Examples:
+  sai validate software/ng/nginx/default.yaml   # Check a file being written
+  sai validate nginx                            # Check the repository definition
+  sai validate nginx --provider apt             # Only render the apt templates
+  sai validate nginx --json                     # For CI`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeValidateCommand(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+// executeValidateCommand checks a saidata file
+func executeValidateCommand(target string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+
+	path, err := saidataFile(target)
+	if err != nil {
+		return err
+	}
+
+	schema, err := validation.NewSaidataValidator("schemas/saidata-0.2-schema.json")
+	if err != nil {
+		formatter.ShowWarning(fmt.Sprintf("Could not load the saidata schema, skipping schema validation: %v", err))
+		schema = nil
+	}
+
+	providerManager, err := newProviderManager(config)
+	if err != nil {
+		return err
+	}
+	linter := validation.NewLinter(schema, providerManager.GetAllProviders())
+	if flags.Provider != "" {
+		if _, err := providerManager.GetProvider(flags.Provider); err != nil {
+			return fmt.Errorf("unknown provider %s", flags.Provider)
+		}
+		linter.RenderOnly([]string{flags.Provider})
+	}
+
+	result, err := linter.LintFile(path)
+	if err != nil {
+		return err
+	}
+
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(result))
+	} else {
+		displayLintResult(result, formatter)
+	}
+
+	if result.Errors > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// saidataFile returns the saidata file target names: a file, a directory holding a
+// default.yaml, or a software of the saidata repository
+func saidataFile(target string) (string, error) {
+	if info, err := os.Stat(target); err == nil {
+		if info.IsDir() {
+			return filepath.Join(target, "default.yaml"), nil
+		}
+		return target, nil
+	}
+
+	saidataDir := saidata.GetSaidataPath()
+	dir := saidata.DefinitionDir(saidataDir, target)
+	if dir == "" {
+		return "", fmt.Errorf("%s is neither a saidata file nor a software of the saidata repository in %s", target, saidataDir)
+	}
+	return filepath.Join(saidataDir, dir, "default.yaml"), nil
+}
+
+// displayLintResult shows the findings of a saidata file as file:line: severity: message
+func displayLintResult(result *validation.LintResult, formatter *output.OutputFormatter) {
+	for _, finding := range result.Findings {
+		location := result.File
+		if finding.Line > 0 {
+			location = fmt.Sprintf("%s:%d", result.File, finding.Line)
+		}
+		message := finding.Message
+		if finding.Field != "" && !strings.Contains(message, finding.Field) {
+			message = finding.Field + ": " + message
+		}
+		fmt.Printf("%s: %s: %s (%s)\n", location, finding.Severity, message, finding.Category)
+		if len(finding.UsedBy) > 0 {
+			fmt.Printf("  used by %s\n", usedBy(finding.UsedBy))
+		}
+	}
+
+	summary := fmt.Sprintf("%s: %d errors, %d warnings, %d provider templates rendered",
+		result.File, result.Errors, result.Warnings, result.Templates)
+	switch {
+	case result.Errors > 0:
+		fmt.Println(summary)
+	case result.Warnings > 0:
+		formatter.ShowWarning(summary)
+	default:
+		formatter.ShowSuccess(fmt.Sprintf("%s is valid, %d provider templates rendered", result.File, result.Templates))
+	}
+}
+
+// usedBy lists the first provider actions reporting a problem
+func usedBy(actions []string) string {
+	const shown = 3
+	if len(actions) <= shown {
+		return strings.Join(actions, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(actions[:shown], ", "), len(actions)-shown)
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sai/internal/interfaces"
+	"sai/internal/output"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify <software>",
+	Short: "Check the installed files of software for changes",
+	Long: `Check that the installed files of software installed by sai were not modified,
+removed or added to since the install, to detect tampering or corruption.
+
+Software installed by a package manager that verifies packages, such as apt (dpkg -V)
+or dnf, yum and zypper (rpm -V), is checked against the package database. Binary,
+script and source installs are checked against the checksums of their commands, files
+and directories recorded when sai installed them; files added to these directories are
+reported as extra files.
+
+When the saidata declares package checksums, the binaries of the software are checked
+against them. Providers with an origin action, such as apt (apt-cache policy) or dnf,
+report the repository of the installed packages, which must be one of the repositories
+the saidata declares for the provider. Providers with a signature action, such as dnf,
+yum and zypper, report unsigned packages.
+
+The exit code is 1 when a file differs or a check drifts.
+
+Examples:
+  sai verify nginx          # Check the files of nginx
+  sai verify nginx --json   # For monitoring`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeVerifyCommand(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+// executeVerifyCommand checks the installed files of a software
+func executeVerifyCommand(software string) error {
+	config := GetGlobalConfig()
+	flags := GetGlobalFlags()
+
+	formatter := output.NewOutputFormatter(config, flags.Verbose, flags.Quiet, flags.JSONOutput)
+	actionManager, _, err := createManagers(config, formatter)
+	if err != nil {
+		formatter.ShowError(fmt.Errorf("failed to initialize: %w", err))
+		return err
+	}
+
+	result, err := actionManager.VerifySoftware(context.Background(), software)
+	if err != nil {
+		return err
+	}
+
+	if flags.JSONOutput {
+		fmt.Println(formatter.FormatJSON(result))
+	} else {
+		displayVerifyResult(result, formatter)
+	}
+
+	if !result.Intact() {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// displayVerifyResult shows the files that differ from the install and the checks
+func displayVerifyResult(result *interfaces.VerifyResult, formatter *output.OutputFormatter) {
+	method := "the package database of " + result.Provider
+	if result.Method == interfaces.VerifyMethodManifest {
+		method = fmt.Sprintf("the files recorded at install (%d checked)", result.Checked)
+	}
+
+	if result.FilesIntact() {
+		formatter.ShowSuccess(fmt.Sprintf("The files of %s match %s", result.Software, method))
+	} else {
+		formatter.ShowWarning(fmt.Sprintf("The files of %s differ from %s", result.Software, method))
+		for _, change := range result.Modified {
+			fmt.Printf("  modified  %s (%s)\n", change.Path, strings.Join(change.Changes, ", "))
+		}
+		for _, path := range result.Missing {
+			fmt.Printf("  missing   %s\n", path)
+		}
+		for _, path := range result.Extra {
+			fmt.Printf("  extra     %s\n", path)
+		}
+	}
+
+	for _, check := range result.Checks {
+		detail := ""
+		if check.Detail != "" {
+			detail = " (" + check.Detail + ")"
+		}
+		fmt.Printf("  %-9s %-9s %s%s\n", check.Status, check.Check, check.Subject, detail)
+	}
+}
@@ -22,7 +22,8 @@ Note: To show SAI CLI version, use 'sai --version' instead.
 Examples:
   sai version nginx                    # Show nginx version info from all providers
   sai version nginx --provider apt     # Show nginx version info from apt only
-  sai version nginx --json             # Output version info in JSON format`,
+  sai version nginx --json             # Output version info in JSON format
+  sai version nginx --format '{{.Provider}}:{{.Version}}'   # Custom output`,
 	Args: cobra.ExactArgs(1), // Require exactly one argument (software name)
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return executeVersionCommand(args[0])
@@ -68,6 +69,9 @@ func executeVersionCommand(software string) error {
 	}
 
 	// Display results
+	if formatFlag != "" {
+		return printFormatted(versionResults)
+	}
 	if flags.JSONOutput {
 		fmt.Println(formatter.FormatJSON(map[string]interface{}{
 			"software": software,
@@ -211,5 +215,6 @@ func executeVersionCommand(software string) error {
 }
 
 func init() {
+	addFormatFlag(versionCmd)
 	rootCmd.AddCommand(versionCmd)
 }
\ No newline at end of file
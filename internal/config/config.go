@@ -2,14 +2,18 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 	"sai/internal/errors"
+	"sai/internal/fileutil"
+	"sai/internal/types"
 )
 
 // Config represents the application configuration
@@ -19,22 +23,154 @@ type Config struct {
 	ProviderPriority  map[string]int                `yaml:"provider_priority"`
 	Timeout           time.Duration                 `yaml:"timeout"`
 	CacheDir          string                        `yaml:"cache_dir"`
+	ManagedDir        string                        `yaml:"managed_dir"`
 	LogLevel          string                        `yaml:"log_level"`
+	Environment       string                        `yaml:"environment"`
+	ReadOnly          bool                          `yaml:"read_only"`
+	WSLPrefer         string                        `yaml:"wsl_prefer"`
+	Root              string                        `yaml:"-"` // Alternate root filesystem set by --root
+	VerifyExecutables bool                          `yaml:"verify_executables"`
+	Providers         map[string]ProviderOverride   `yaml:"providers"`
 	Confirmations     ConfirmationConfig            `yaml:"confirmations"`
+	Gatekeeper        GatekeeperConfig              `yaml:"gatekeeper"`
+	Security          SecurityConfig                `yaml:"security"`
+	Privilege         PrivilegeConfig               `yaml:"privilege"`
+	Downloads         DownloadConfig                `yaml:"downloads"`
+	Audit             AuditConfig                   `yaml:"audit"`
+	AdaptiveTimeout   AdaptiveTimeoutConfig         `yaml:"adaptive_timeout"`
+	Cleanup           CleanupConfig                 `yaml:"cleanup"`
+	Builds            BuildConfig                   `yaml:"builds"`
+	Batch             BatchConfig                   `yaml:"batch"`
+	Transactions      TransactionConfig             `yaml:"transactions"`
+	Checkpoints       CheckpointConfig              `yaml:"checkpoints"`
+	Locks             LockConfig                    `yaml:"locks"`
+	AcrossProviders   AcrossProvidersConfig         `yaml:"across_providers"`
+	Redaction         RedactionConfig               `yaml:"redaction"`
+	RiskTiers         map[string]string             `yaml:"risk_tiers"`
 	Output            OutputConfig                  `yaml:"output"`
 	Repository        RepositoryConfig              `yaml:"repository"`
+	Repositories      []SaidataRepositoryConfig     `yaml:"repositories"` // Searched besides the main repository, by priority
+	SaidataCache      SaidataCacheConfig            `yaml:"saidata_cache"`
 	Recovery          *errors.RecoveryConfig        `yaml:"recovery,omitempty"`
 	CircuitBreaker    *errors.CircuitBreakerConfig  `yaml:"circuit_breaker,omitempty"`
+	Hooks             map[string][]types.Hook       `yaml:"hooks,omitempty"` // Run around the actions of every software, after the hooks of its saidata
+
+	origins map[string]Origin // Layers that set values, by dotted key, see Origin
+}
+
+// ProviderOverride adapts a provider to the host, such as a package manager renamed or
+// wrapped by apt-fast or a corporate wrapper
+type ProviderOverride struct {
+	Executable string              `yaml:"executable"` // Name or absolute path replacing the provider executable
+	Budget     time.Duration       `yaml:"budget"`     // Replaces across_providers.budget for this provider
+	Args       map[string][]string `yaml:"args"`       // Extra arguments of the provider commands by action, overriding saidata ones
+}
+
+// ProviderBudget returns how long an information action may run with a provider when
+// executed across all providers, retries included
+func (c *Config) ProviderBudget(provider string) time.Duration {
+	if budget := c.Providers[provider].Budget; budget > 0 {
+		return budget
+	}
+	return c.AcrossProviders.Budget
+}
+
+// ExtraArgs returns the extra arguments of provider commands by provider then action
+func (c *Config) ExtraArgs() map[string]map[string][]string {
+	args := make(map[string]map[string][]string)
+	for name, override := range c.Providers {
+		if len(override.Args) > 0 {
+			args[name] = override.Args
+		}
+	}
+	return args
+}
+
+// ExecutableOverrides returns the overridden executables by provider name
+func (c *Config) ExecutableOverrides() map[string]string {
+	overrides := make(map[string]string)
+	for name, override := range c.Providers {
+		if override.Executable != "" {
+			overrides[name] = override.Executable
+		}
+	}
+	return overrides
 }
 
 // RepositoryConfig handles Git-based management with zip fallback (Requirement 8.4)
 type RepositoryConfig struct {
-	GitURL          string        `yaml:"git_url"`
-	ZipFallbackURL  string        `yaml:"zip_fallback_url"`
-	LocalPath       string        `yaml:"local_path"`
-	UpdateInterval  time.Duration `yaml:"update_interval"`
-	OfflineMode     bool          `yaml:"offline_mode"`
-	AutoSetup       bool          `yaml:"auto_setup"`
+	GitURL          string          `yaml:"git_url"`
+	ZipFallbackURL  string          `yaml:"zip_fallback_url"`
+	LocalPath       string          `yaml:"local_path"`
+	UpdateInterval  time.Duration   `yaml:"update_interval"`
+	OfflineMode     bool            `yaml:"offline_mode"`
+	AutoSetup       bool            `yaml:"auto_setup"`
+	Sparse          SparseConfig    `yaml:"sparse"`
+	Signature       SignatureConfig `yaml:"signature"`
+}
+
+// DefaultRepositoryName is the name of the main saidata repository, set by repository
+const DefaultRepositoryName = "default"
+
+// repositoryNamePattern matches the names of saidata repositories
+var repositoryNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// SaidataRepositoryConfig is a saidata repository searched besides the main one, such
+// as a company-internal repository overriding upstream definitions. The definitions of
+// a repository are merged over those of the repositories of lower priority.
+type SaidataRepositoryConfig struct {
+	Name     string `yaml:"name"`
+	GitURL   string `yaml:"git_url,omitempty"` // Cloned into path, or repositories/{name} of the cache directory
+	Path     string `yaml:"path,omitempty"`    // Directory of the definitions
+	Priority int    `yaml:"priority"`          // The main repository has priority 0
+}
+
+// Dir returns the directory holding the definitions of the repository
+func (r SaidataRepositoryConfig) Dir(cacheDir string) string {
+	if r.Path != "" {
+		return r.Path
+	}
+	return filepath.Join(cacheDir, "repositories", r.Name)
+}
+
+// ValidateRepositories checks that saidata repositories have a source and unique names
+func ValidateRepositories(repositories []SaidataRepositoryConfig) error {
+	seen := map[string]bool{DefaultRepositoryName: true}
+	for _, repository := range repositories {
+		if !repositoryNamePattern.MatchString(repository.Name) {
+			return fmt.Errorf("invalid saidata repository name '%s', use lowercase letters, digits, '.', '_' and '-'", repository.Name)
+		}
+		if seen[repository.Name] {
+			return fmt.Errorf("saidata repository name '%s' is already used", repository.Name)
+		}
+		seen[repository.Name] = true
+		if repository.GitURL == "" && repository.Path == "" {
+			return fmt.Errorf("saidata repository %s needs a git_url or a path", repository.Name)
+		}
+	}
+	return nil
+}
+
+// SignatureConfig sets how downloaded saidata is verified. Saidata not signed by a
+// trusted key or identity is refused unless --insecure-saidata is given.
+type SignatureConfig struct {
+	Method   string `yaml:"method"`   // gpg or sigstore
+	Keyring  string `yaml:"keyring"`  // gpg: keyring of the trusted keys, required to verify with gpgv
+	Identity string `yaml:"identity"` // sigstore: certificate identity of the signer
+	Issuer   string `yaml:"issuer"`   // sigstore: OIDC issuer of the signer identity
+}
+
+// SparseConfig selects the subset of the saidata repository to sync. When both lists
+// are empty the whole repository is synced.
+type SparseConfig struct {
+	Software   []string `yaml:"software"`   // Software names to sync, e.g. nginx
+	Categories []string `yaml:"categories"` // Metadata categories to sync, e.g. web-server
+}
+
+// SaidataCacheConfig contains the settings of the on-disk cache of resolved saidata
+type SaidataCacheConfig struct {
+	Enabled bool          `yaml:"enabled"` // Persist resolved saidata in the cache directory
+	TTL     time.Duration `yaml:"ttl"`     // Time after which cached saidata is resolved again
 }
 
 // ConfirmationConfig controls confirmation prompts (Requirements 9.1, 9.2, 9.3, 9.4)
@@ -47,6 +183,138 @@ type ConfirmationConfig struct {
 	InfoCommands  bool `yaml:"info_commands"` // Info commands execute without confirmation (default: false)
 }
 
+// GatekeeperConfig controls the handling of binaries downloaded by binary providers on macOS
+type GatekeeperConfig struct {
+	ClearQuarantine     bool `yaml:"clear_quarantine"`     // Offer to remove com.apple.quarantine from checksum-verified binaries
+	VerifySignature     bool `yaml:"verify_signature"`     // Require a valid code signature (codesign) before clearing
+	RequireNotarization bool `yaml:"require_notarization"` // Require Gatekeeper acceptance (spctl) before clearing
+}
+
+// SecurityConfig controls the verification of files downloaded by actions, and the
+// actions refused on saidata generated from guesses
+type SecurityConfig struct {
+	RequireChecksums bool          `yaml:"require_checksums"` // Refuse action downloads that declare no checksum
+	StrictActions    []string      `yaml:"strict_actions"`    // Actions refused on generated saidata unless --allow-generated
+	Sandbox          SandboxConfig `yaml:"sandbox"`           // Restrictions of the commands rendered from provider templates
+}
+
+// SandboxConfig restricts how the commands rendered from provider templates run, for
+// those who do not trust generated shell strings with the privileges of sai
+type SandboxConfig struct {
+	User            string   `yaml:"user"`             // Run commands as this user, sai must run as root
+	RestrictEnv     bool     `yaml:"restrict_env"`     // Only pass PATH, HOME, LANG, LC_ALL, TERM, TMPDIR and allowed_env to commands
+	AllowedEnv      []string `yaml:"allowed_env"`      // Variables passed to commands with restrict_env, such as HTTPS_PROXY
+	Isolation       string   `yaml:"isolation"`        // "unshare" to run commands in new namespaces, "nsenter" in those of target
+	Namespaces      []string `yaml:"namespaces"`       // Namespaces isolated: mount, uts, ipc, net, pid, user, cgroup (default mount, uts, ipc)
+	Target          int      `yaml:"target"`           // PID whose namespaces nsenter enters
+	AllowedCommands []string `yaml:"allowed_commands"` // Executables commands may run, by name or absolute path, empty allows any
+	AllowScripts    bool     `yaml:"allow_scripts"`    // Run script actions despite allowed_commands, which cannot check what they run
+}
+
+// PrivilegeConfig controls the actions requiring root when sai runs as another user
+type PrivilegeConfig struct {
+	Escalation string `yaml:"escalation"` // reexec (offer to re-run sai with sudo), prepend, prompt (ask once) or fail
+	Tool       string `yaml:"tool"`       // sudo, doas, pkexec or runas, the first one installed when empty
+}
+
+// DownloadConfig controls how the files of actions and bootstraps are downloaded
+type DownloadConfig struct {
+	Proxy          string        `yaml:"proxy"`           // HTTP(S) proxy URL, HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used when empty
+	Retries        int           `yaml:"retries"`         // Attempts repeated after a network error or a server error
+	Backoff        time.Duration `yaml:"backoff"`         // Wait before the first retry, doubled after each attempt
+	Connections    int           `yaml:"connections"`     // Parallel range requests of large downloads, 1 disables
+	ChunkThreshold int64         `yaml:"chunk_threshold"` // Size in bytes from which downloads are split across connections
+	Progress       bool          `yaml:"progress"`        // Show a progress bar on terminals
+}
+
+// AuditConfig controls the append-only log of the commands run by actions, queried
+// with 'sai history'
+type AuditConfig struct {
+	Enabled  bool   `yaml:"enabled"`   // Record every command executed and every command a dry run shows
+	Path     string `yaml:"path"`      // JSON Lines file of the log
+	MaxSize  int64  `yaml:"max_size"`  // Size in bytes from which the log is rotated, 0 never rotates it
+	MaxFiles int    `yaml:"max_files"` // Rotated files kept, named path.1 (the most recent), path.2...
+}
+
+// AdaptiveTimeoutConfig controls timeouts adapted to download sizes and past durations
+type AdaptiveTimeoutConfig struct {
+	Enabled       bool          `yaml:"enabled"`        // Stretch action timeouts for large downloads and slow past runs
+	Ceiling       time.Duration `yaml:"ceiling"`        // Hard upper limit of adapted timeouts
+	StallTimeout  time.Duration `yaml:"stall_timeout"`  // Abort commands producing no output for this long, 0 disables
+	MinThroughput int64         `yaml:"min_throughput"` // Bytes per second assumed until throughput was observed
+}
+
+// CleanupConfig controls the removal of temporary files left by action runs
+type CleanupConfig struct {
+	KeepFailedDays int `yaml:"keep_failed_days"` // Days the temporary files of failed runs are kept for debugging
+}
+
+// KeepFailed returns how long the temporary files of failed runs are kept
+func (c CleanupConfig) KeepFailed() time.Duration {
+	return time.Duration(c.KeepFailedDays) * 24 * time.Hour
+}
+
+// BuildConfig controls the builds of saidata sources by actions setting build
+type BuildConfig struct {
+	CacheDir string `yaml:"cache_dir"` // Directory sources are downloaded to and built in, kept to resume failed builds
+	Jobs     int    `yaml:"jobs"`      // Parallel jobs of the build step, 0 for the number of CPUs
+}
+
+// BatchConfig controls actions run on several software at once
+type BatchConfig struct {
+	Workers int `yaml:"workers"` // Software processed concurrently
+}
+
+// TransactionConfig controls the journal of the changes made by actions
+type TransactionConfig struct {
+	Dir          string `yaml:"dir"`           // Directory of the transaction journal
+	AutoRollback bool   `yaml:"auto_rollback"` // Undo the journaled changes of a failed action
+}
+
+// CheckpointConfig controls the checkpoints of multi-step actions, recording the steps
+// completed by a failed run so running the action again resumes at the failed step
+type CheckpointConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir"` // Directory of the checkpoint files, removed once the action succeeds
+}
+
+// LockConfig controls the locks serializing sai processes acting through the same provider
+type LockConfig struct {
+	Dir       string        `yaml:"dir"`        // Directory of the provider lock files
+	SystemDir string        `yaml:"system_dir"` // Directory of the lock files of actions requiring root, shared by all users, Dir when empty
+	Timeout   time.Duration `yaml:"timeout"`    // How long to wait for a provider locked by another sai process, 0 fails at once
+}
+
+// AcrossProvidersConfig bounds information actions run with every available provider,
+// such as 'sai version', so a hanging provider cannot delay the others
+type AcrossProvidersConfig struct {
+	Budget  time.Duration `yaml:"budget"`  // Deadline of each provider, retries included
+	Retries int           `yaml:"retries"` // Attempts repeated after an error while the budget lasts
+}
+
+// RedactionConfig controls the secrets masked in console output, logs, the debug log
+// and the transaction journal
+type RedactionConfig struct {
+	Patterns       []string `yaml:"patterns"`        // Regular expressions redacted, only their group named secret when they have one
+	DisableBuiltin bool     `yaml:"disable_builtin"` // Skip the built-in patterns (AWS keys, bearer tokens, passwords in URLs)
+}
+
+// Risk tiers used to classify actions by their potential impact on the system
+const (
+	RiskTierInfo        = "info"        // Read-only actions
+	RiskTierSafe        = "safe"        // System-changing actions that are easily reverted
+	RiskTierDestructive = "destructive" // Actions that can break running software or remove data
+)
+
+// EnvironmentProduction marks a host as production, enabling typed confirmations
+const EnvironmentProduction = "production"
+
+// Provider preferences under Windows Subsystem for Linux
+const (
+	WSLPreferLinux   = "linux"   // Prefer Linux-native providers (apt, dnf, ...)
+	WSLPreferWindows = "windows" // Prefer Windows providers reached through interop (winget, choco, ...)
+)
+
 // OutputConfig controls output formatting (Requirements 7.2, 7.5, 7.6, 10.1, 10.2, 10.3)
 type OutputConfig struct {
 	ProviderColor    string `yaml:"provider_color"`
@@ -55,27 +323,30 @@ type OutputConfig struct {
 	ErrorColor       string `yaml:"error_color"`
 	ShowCommands     bool   `yaml:"show_commands"`
 	ShowExitCodes    bool   `yaml:"show_exit_codes"`
+	MergeResults     bool   `yaml:"merge_results"` // Collapse identical results of several providers, see --per-provider
+	Stream           bool   `yaml:"stream"`        // Show command output live, prefixed with the provider, see --stream
 }
 
-// LoadConfig loads configuration with file discovery, environment variables, and validation
+// LoadConfig loads the configuration layers: the defaults overridden by the system,
+// user and project files that exist, or only the file at configPath when given, then by
+// environment variables. Flags are applied by the command line.
 func LoadConfig(configPath string) (*Config, error) {
 	config := getDefaultConfig()
 
-	// Discover configuration file if not explicitly provided
-	if configPath == "" {
-		var err error
-		configPath, err = discoverConfigFile()
-		if err != nil {
-			// No config file found, use defaults
-			return applyEnvironmentVariables(config), nil
-		}
-	}
-
-	// Load configuration from file
 	if configPath != "" {
-		if err := loadConfigFromFile(config, configPath); err != nil {
+		if err := loadConfigFromFile(config, configPath, LayerFile); err != nil {
 			return nil, fmt.Errorf("failed to load config from %s: %w", configPath, err)
 		}
+	} else {
+		for _, layer := range fileLayers() {
+			path := layer.find()
+			if path == "" {
+				continue
+			}
+			if err := loadConfigFromFile(config, path, layer.name); err != nil {
+				return nil, fmt.Errorf("failed to load config from %s: %w", path, err)
+			}
+		}
 	}
 
 	// Apply environment variable overrides
@@ -94,13 +365,31 @@ func getDefaultConfig() *Config {
 	homeDir, _ := os.UserHomeDir()
 	cacheDir := filepath.Join(homeDir, ".sai", "cache")
 	
+	// System-wide markers and transactions when running as root, per-user otherwise
+	managedDir := "/var/lib/sai/managed"
+	transactionDir := "/var/lib/sai/transactions"
+	checkpointDir := "/var/lib/sai/checkpoints"
+	lockDir := "/var/lock/sai"
+	auditPath := "/var/log/sai/audit.jsonl"
+	if os.Geteuid() != 0 {
+		managedDir = filepath.Join(homeDir, ".sai", "managed")
+		transactionDir = filepath.Join(homeDir, ".sai", "transactions")
+		checkpointDir = filepath.Join(homeDir, ".sai", "checkpoints")
+		lockDir = filepath.Join(homeDir, ".sai", "locks")
+		auditPath = filepath.Join(homeDir, ".sai", "audit.jsonl")
+	}
+	
 	return &Config{
 		SaidataRepository: "https://github.com/example42/saidata.git",
 		DefaultProvider:   "",
 		ProviderPriority:  make(map[string]int),
 		Timeout:           30 * time.Second,
 		CacheDir:          cacheDir,
+		ManagedDir:        managedDir,
 		LogLevel:          "info",
+		Environment:       "",
+		WSLPrefer:         WSLPreferLinux,
+		RiskTiers:         defaultRiskTiers(),
 		Recovery:          errors.DefaultRecoveryConfig(),
 		CircuitBreaker:    errors.DefaultCircuitBreakerConfig(),
 		Confirmations: ConfirmationConfig{
@@ -111,6 +400,63 @@ func getDefaultConfig() *Config {
 			ServiceOps:    true,  // Require confirmation for service operations
 			InfoCommands:  false, // Info commands execute without confirmation
 		},
+		Gatekeeper: GatekeeperConfig{
+			ClearQuarantine:     true,
+			VerifySignature:     false,
+			RequireNotarization: false,
+		},
+		Security: SecurityConfig{
+			RequireChecksums: true,
+			StrictActions:    []string{"install", "uninstall"},
+		},
+		Privilege: PrivilegeConfig{
+			Escalation: "reexec",
+		},
+		Downloads: DownloadConfig{
+			Retries:        3,
+			Backoff:        time.Second,
+			Connections:    4,
+			ChunkThreshold: 16 * 1024 * 1024,
+			Progress:       true,
+		},
+		Audit: AuditConfig{
+			Enabled:  true,
+			Path:     auditPath,
+			MaxSize:  10 * 1024 * 1024,
+			MaxFiles: 5,
+		},
+		AdaptiveTimeout: AdaptiveTimeoutConfig{
+			Enabled:       true,
+			Ceiling:       2 * time.Hour,
+			StallTimeout:  15 * time.Minute,
+			MinThroughput: 256 * 1024,
+		},
+		Cleanup: CleanupConfig{
+			KeepFailedDays: 3,
+		},
+		Builds: BuildConfig{
+			CacheDir: "/var/cache/sai/builds",
+		},
+		Batch: BatchConfig{
+			Workers: 4,
+		},
+		Transactions: TransactionConfig{
+			Dir:          transactionDir,
+			AutoRollback: true,
+		},
+		Checkpoints: CheckpointConfig{
+			Enabled: true,
+			Dir:     checkpointDir,
+		},
+		AcrossProviders: AcrossProvidersConfig{
+			Budget:  5 * time.Second,
+			Retries: 1,
+		},
+		Locks: LockConfig{
+			Dir:       lockDir,
+			SystemDir: "/var/lock/sai",
+			Timeout:   10 * time.Minute,
+		},
 		Output: OutputConfig{
 			ProviderColor: "blue",
 			CommandStyle:  "bold",
@@ -118,6 +464,7 @@ func getDefaultConfig() *Config {
 			ErrorColor:    "red",
 			ShowCommands:  true,
 			ShowExitCodes: true,
+			MergeResults:  true,
 		},
 		Repository: RepositoryConfig{
 			GitURL:         "https://github.com/example42/saidata.git",
@@ -126,43 +473,36 @@ func getDefaultConfig() *Config {
 			UpdateInterval: 24 * time.Hour,
 			OfflineMode:    false,
 			AutoSetup:      true,
+			Signature: SignatureConfig{
+				Method: "gpg",
+			},
+		},
+		SaidataCache: SaidataCacheConfig{
+			Enabled: true,
+			TTL:     7 * 24 * time.Hour,
 		},
 	}
 }
 
-// discoverConfigFile searches for configuration files in standard locations
-func discoverConfigFile() (string, error) {
-	// Configuration file search order (user home, system, current directory)
-	searchPaths := []string{
-		"./sai.yaml",           // Current directory
-		"./sai.yml",            // Current directory (alternative extension)
-		"./.sai/config.yaml",   // Current directory .sai folder
-		"./.sai/config.yml",    // Current directory .sai folder (alternative extension)
-	}
-
-	// Add user home directory paths
-	if homeDir, err := os.UserHomeDir(); err == nil {
-		homePaths := []string{
-			filepath.Join(homeDir, ".sai", "config.yaml"),
-			filepath.Join(homeDir, ".sai", "config.yml"),
-			filepath.Join(homeDir, ".config", "sai", "config.yaml"),
-			filepath.Join(homeDir, ".config", "sai", "config.yml"),
-		}
-		searchPaths = append(searchPaths, homePaths...)
-	}
-
-	// Add system-wide paths
-	systemPaths := []string{
-		"/etc/sai/config.yaml",
-		"/etc/sai/config.yml",
-		"/usr/local/etc/sai/config.yaml",
-		"/usr/local/etc/sai/config.yml",
+// defaultRiskTiers returns the built-in risk tier classification for actions
+func defaultRiskTiers() map[string]string {
+	return map[string]string{
+		"install":   RiskTierSafe,
+		"start":     RiskTierSafe,
+		"restart":   RiskTierSafe,
+		"enable":    RiskTierSafe,
+		"upgrade":   RiskTierDestructive,
+		"uninstall": RiskTierDestructive,
+		"stop":      RiskTierDestructive,
+		"disable":   RiskTierDestructive,
 	}
-	searchPaths = append(searchPaths, systemPaths...)
+}
 
-	// Search for the first existing configuration file
-	for _, path := range searchPaths {
-		if _, err := os.Stat(path); err == nil {
+// discoverConfigFile returns the existing configuration file of the highest precedence
+func discoverConfigFile() (string, error) {
+	layers := fileLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		if path := layers[i].find(); path != "" {
 			return path, nil
 		}
 	}
@@ -170,8 +510,9 @@ func discoverConfigFile() (string, error) {
 	return "", fmt.Errorf("no configuration file found in standard locations")
 }
 
-// loadConfigFromFile loads configuration from a YAML file
-func loadConfigFromFile(config *Config, path string) error {
+// loadConfigFromFile loads configuration from a YAML file of a layer over the values
+// already loaded
+func loadConfigFromFile(config *Config, path, layer string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read config file: %w", err)
@@ -181,7 +522,7 @@ func loadConfigFromFile(config *Config, path string) error {
 		return fmt.Errorf("failed to parse YAML config: %w", err)
 	}
 
-	return nil
+	return config.recordOrigins(data, Origin{Layer: layer, Source: path})
 }
 
 // applyEnvironmentVariables applies environment variable overrides
@@ -189,38 +530,105 @@ func applyEnvironmentVariables(config *Config) *Config {
 	// SAI_SAIDATA_REPOSITORY
 	if repo := os.Getenv("SAI_SAIDATA_REPOSITORY"); repo != "" {
 		config.SaidataRepository = repo
+		config.setOrigin("saidata_repository", Origin{Layer: LayerEnv, Source: "SAI_SAIDATA_REPOSITORY"})
 	}
 
 	// SAI_DEFAULT_PROVIDER
 	if provider := os.Getenv("SAI_DEFAULT_PROVIDER"); provider != "" {
 		config.DefaultProvider = provider
+		config.setOrigin("default_provider", Origin{Layer: LayerEnv, Source: "SAI_DEFAULT_PROVIDER"})
 	}
 
 	// SAI_LOG_LEVEL
 	if level := os.Getenv("SAI_LOG_LEVEL"); level != "" {
 		config.LogLevel = level
+		config.setOrigin("log_level", Origin{Layer: LayerEnv, Source: "SAI_LOG_LEVEL"})
 	}
 
 	// SAI_CACHE_DIR
 	if cacheDir := os.Getenv("SAI_CACHE_DIR"); cacheDir != "" {
 		config.CacheDir = cacheDir
+		config.setOrigin("cache_dir", Origin{Layer: LayerEnv, Source: "SAI_CACHE_DIR"})
+	}
+
+	// SAI_MANAGED_DIR
+	if managedDir := os.Getenv("SAI_MANAGED_DIR"); managedDir != "" {
+		config.ManagedDir = managedDir
+		config.setOrigin("managed_dir", Origin{Layer: LayerEnv, Source: "SAI_MANAGED_DIR"})
+	}
+
+	// SAI_AUDIT_PATH
+	if auditPath := os.Getenv("SAI_AUDIT_PATH"); auditPath != "" {
+		config.Audit.Path = auditPath
+		config.setOrigin("audit.path", Origin{Layer: LayerEnv, Source: "SAI_AUDIT_PATH"})
+	}
+
+	// SAI_TRANSACTION_DIR
+	if transactionDir := os.Getenv("SAI_TRANSACTION_DIR"); transactionDir != "" {
+		config.Transactions.Dir = transactionDir
+		config.setOrigin("transactions.dir", Origin{Layer: LayerEnv, Source: "SAI_TRANSACTION_DIR"})
+	}
+
+	// SAI_LOCK_DIR
+	if lockDir := os.Getenv("SAI_LOCK_DIR"); lockDir != "" {
+		config.Locks.Dir = lockDir
+		config.setOrigin("locks.dir", Origin{Layer: LayerEnv, Source: "SAI_LOCK_DIR"})
 	}
 
 	// SAI_TIMEOUT
 	if timeout := os.Getenv("SAI_TIMEOUT"); timeout != "" {
 		if duration, err := time.ParseDuration(timeout); err == nil {
 			config.Timeout = duration
+			config.setOrigin("timeout", Origin{Layer: LayerEnv, Source: "SAI_TIMEOUT"})
 		}
 	}
 
+	// SAI_ENVIRONMENT
+	if environment := os.Getenv("SAI_ENVIRONMENT"); environment != "" {
+		config.Environment = environment
+		config.setOrigin("environment", Origin{Layer: LayerEnv, Source: "SAI_ENVIRONMENT"})
+	}
+
+	// SAI_READ_ONLY
+	if readOnly := os.Getenv("SAI_READ_ONLY"); readOnly != "" {
+		config.ReadOnly = strings.ToLower(readOnly) == "true"
+		config.setOrigin("read_only", Origin{Layer: LayerEnv, Source: "SAI_READ_ONLY"})
+	}
+
+	// SAI_WSL_PREFER
+	if wslPrefer := os.Getenv("SAI_WSL_PREFER"); wslPrefer != "" {
+		config.WSLPrefer = strings.ToLower(wslPrefer)
+		config.setOrigin("wsl_prefer", Origin{Layer: LayerEnv, Source: "SAI_WSL_PREFER"})
+	}
+
+	// SAI_CIRCUIT_BREAKER_OPEN_PROVIDERS
+	if openProviders := os.Getenv("SAI_CIRCUIT_BREAKER_OPEN_PROVIDERS"); openProviders != "" && config.CircuitBreaker != nil {
+		config.CircuitBreaker.OpenProviders = strings.ToLower(openProviders)
+		config.setOrigin("circuit_breaker.open_providers", Origin{Layer: LayerEnv, Source: "SAI_CIRCUIT_BREAKER_OPEN_PROVIDERS"})
+	}
+
+	// SAI_VERIFY_EXECUTABLES
+	if verify := os.Getenv("SAI_VERIFY_EXECUTABLES"); verify != "" {
+		config.VerifyExecutables = strings.ToLower(verify) == "true"
+		config.setOrigin("verify_executables", Origin{Layer: LayerEnv, Source: "SAI_VERIFY_EXECUTABLES"})
+	}
+
 	// SAI_OFFLINE_MODE
 	if offline := os.Getenv("SAI_OFFLINE_MODE"); offline != "" {
 		config.Repository.OfflineMode = strings.ToLower(offline) == "true"
+		config.setOrigin("repository.offline_mode", Origin{Layer: LayerEnv, Source: "SAI_OFFLINE_MODE"})
+	}
+
+	// SAI_SAIDATA_CACHE
+	if saidataCache := os.Getenv("SAI_SAIDATA_CACHE"); saidataCache != "" {
+		config.SaidataCache.Enabled = strings.ToLower(saidataCache) == "true"
+		config.setOrigin("saidata_cache.enabled", Origin{Layer: LayerEnv, Source: "SAI_SAIDATA_CACHE"})
 	}
 
 	// SAI_AUTO_SETUP
 	if autoSetup := os.Getenv("SAI_AUTO_SETUP"); autoSetup != "" {
 		config.Repository.AutoSetup = strings.ToLower(autoSetup) == "true"
+		config.setOrigin("repository.auto_setup", Origin{Layer: LayerEnv, Source: "SAI_AUTO_SETUP"})
 	}
 
 	return config
@@ -245,6 +653,58 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("cache directory cannot be empty")
 	}
 
+	// Validate managed markers directory
+	if config.ManagedDir == "" {
+		return fmt.Errorf("managed directory cannot be empty")
+	}
+
+	// Validate transaction journal directory
+	if config.Transactions.Dir == "" {
+		return fmt.Errorf("transactions dir cannot be empty")
+	}
+
+	// Validate step checkpoints
+	if config.Checkpoints.Enabled && config.Checkpoints.Dir == "" {
+		return fmt.Errorf("checkpoints dir cannot be empty when checkpoints are enabled")
+	}
+
+	// Validate provider locks
+	if config.Locks.Dir == "" {
+		return fmt.Errorf("locks dir cannot be empty")
+	}
+	if config.Locks.Timeout < 0 {
+		return fmt.Errorf("locks timeout cannot be negative, got: %v", config.Locks.Timeout)
+	}
+
+	// Validate the budgets of actions across providers
+	if config.AcrossProviders.Budget <= 0 {
+		return fmt.Errorf("across_providers budget must be positive, got: %v", config.AcrossProviders.Budget)
+	}
+	if config.AcrossProviders.Retries < 0 {
+		return fmt.Errorf("across_providers retries cannot be negative, got: %d", config.AcrossProviders.Retries)
+	}
+
+	// Validate redaction patterns
+	for _, pattern := range config.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redaction pattern '%s': %w", pattern, err)
+		}
+	}
+
+	for name, override := range config.Providers {
+		if override.Budget < 0 {
+			return fmt.Errorf("invalid budget %v of provider %s, it cannot be negative", override.Budget, name)
+		}
+		if strings.ContainsAny(override.Executable, " \t\n") {
+			return fmt.Errorf("invalid executable '%s' of provider %s, it cannot contain whitespace", override.Executable, name)
+		}
+	}
+
+	// Validate the sandbox of commands
+	if err := validateSandbox(config.Security.Sandbox); err != nil {
+		return err
+	}
+
 	// Validate repository configuration
 	if config.Repository.GitURL == "" && config.Repository.ZipFallbackURL == "" {
 		return fmt.Errorf("either git_url or zip_fallback_url must be specified")
@@ -258,6 +718,132 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("repository update_interval must be positive, got: %v", config.Repository.UpdateInterval)
 	}
 
+	for _, name := range config.Repository.Sparse.Software {
+		if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+			return fmt.Errorf("invalid software name '%s' in repository sparse selection", name)
+		}
+	}
+	for _, category := range config.Repository.Sparse.Categories {
+		if strings.TrimSpace(category) == "" {
+			return fmt.Errorf("repository sparse categories cannot contain empty names")
+		}
+	}
+
+	switch config.Repository.Signature.Method {
+	case "gpg":
+	case "sigstore":
+		if config.Repository.Signature.Identity == "" || config.Repository.Signature.Issuer == "" {
+			return fmt.Errorf("repository signature identity and issuer are required with sigstore")
+		}
+	default:
+		return fmt.Errorf("invalid repository signature method: %s (must be gpg or sigstore)", config.Repository.Signature.Method)
+	}
+
+	if err := ValidateRepositories(config.Repositories); err != nil {
+		return err
+	}
+
+	// Validate the saidata cache
+	if config.SaidataCache.Enabled && config.SaidataCache.TTL <= 0 {
+		return fmt.Errorf("saidata_cache ttl must be positive, got: %v", config.SaidataCache.TTL)
+	}
+
+	// Validate downloads
+	if config.Downloads.Proxy != "" {
+		if proxy, err := url.Parse(config.Downloads.Proxy); err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return fmt.Errorf("invalid downloads proxy '%s', must be a URL such as http://proxy:3128", config.Downloads.Proxy)
+		}
+	}
+	if config.Downloads.Retries < 0 {
+		return fmt.Errorf("downloads retries cannot be negative, got: %d", config.Downloads.Retries)
+	}
+	if config.Downloads.Backoff < 0 {
+		return fmt.Errorf("downloads backoff cannot be negative, got: %v", config.Downloads.Backoff)
+	}
+	if config.Downloads.Connections < 1 {
+		return fmt.Errorf("downloads connections must be at least 1, got: %d", config.Downloads.Connections)
+	}
+
+	// Validate the audit log
+	if config.Audit.Enabled && config.Audit.Path == "" {
+		return fmt.Errorf("audit path cannot be empty")
+	}
+	if config.Audit.MaxSize < 0 {
+		return fmt.Errorf("audit max_size cannot be negative, got: %d", config.Audit.MaxSize)
+	}
+	if config.Audit.MaxSize > 0 && config.Audit.MaxFiles < 1 {
+		return fmt.Errorf("audit max_files must be at least 1 when the log is rotated, got: %d", config.Audit.MaxFiles)
+	}
+
+	// Validate adaptive timeouts
+	if config.AdaptiveTimeout.Enabled {
+		if config.AdaptiveTimeout.Ceiling <= 0 {
+			return fmt.Errorf("adaptive_timeout ceiling must be positive, got: %v", config.AdaptiveTimeout.Ceiling)
+		}
+		if config.AdaptiveTimeout.StallTimeout < 0 {
+			return fmt.Errorf("adaptive_timeout stall_timeout cannot be negative, got: %v", config.AdaptiveTimeout.StallTimeout)
+		}
+		if config.AdaptiveTimeout.MinThroughput <= 0 {
+			return fmt.Errorf("adaptive_timeout min_throughput must be positive, got: %d", config.AdaptiveTimeout.MinThroughput)
+		}
+	}
+
+	// Validate the selection of providers with an open circuit breaker
+	if config.CircuitBreaker != nil {
+		switch config.CircuitBreaker.OpenProviders {
+		case errors.OpenProvidersDemote, errors.OpenProvidersExclude, errors.OpenProvidersIgnore:
+		default:
+			return fmt.Errorf("invalid circuit_breaker open_providers '%s', must be one of: demote, exclude, ignore", config.CircuitBreaker.OpenProviders)
+		}
+	}
+
+	// Validate cleanup retention
+	if config.Cleanup.KeepFailedDays < 0 {
+		return fmt.Errorf("cleanup keep_failed_days cannot be negative, got: %d", config.Cleanup.KeepFailedDays)
+	}
+
+	// Validate privilege escalation
+	switch config.Privilege.Escalation {
+	case "reexec", "prepend", "prompt", "fail":
+	default:
+		return fmt.Errorf("invalid privilege escalation '%s', must be one of: reexec, prepend, prompt, fail", config.Privilege.Escalation)
+	}
+	switch config.Privilege.Tool {
+	case "", "sudo", "doas", "pkexec", "runas":
+	default:
+		return fmt.Errorf("invalid privilege tool '%s', must be one of: sudo, doas, pkexec, runas", config.Privilege.Tool)
+	}
+
+	// Validate build jobs
+	if config.Builds.Jobs < 0 {
+		return fmt.Errorf("builds jobs cannot be negative, got: %d", config.Builds.Jobs)
+	}
+
+	// Validate batch workers
+	if config.Batch.Workers < 1 {
+		return fmt.Errorf("batch workers must be at least 1, got: %d", config.Batch.Workers)
+	}
+
+	// Validate risk tiers
+	validRiskTiers := []string{RiskTierInfo, RiskTierSafe, RiskTierDestructive}
+	for action, tier := range config.RiskTiers {
+		if !contains(validRiskTiers, tier) {
+			return fmt.Errorf("invalid risk tier '%s' for action '%s', must be one of: %s",
+				tier, action, strings.Join(validRiskTiers, ", "))
+		}
+	}
+
+	if err := types.ValidateHooks(config.Hooks); err != nil {
+		return err
+	}
+
+	// Validate WSL provider preference
+	validWSLPreferences := []string{WSLPreferLinux, WSLPreferWindows}
+	if !contains(validWSLPreferences, config.WSLPrefer) {
+		return fmt.Errorf("invalid wsl_prefer '%s', must be one of: %s",
+			config.WSLPrefer, strings.Join(validWSLPreferences, ", "))
+	}
+
 	// Validate output colors
 	validColors := []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
 	if !contains(validColors, config.Output.ProviderColor) {
@@ -278,6 +864,31 @@ func validateConfig(config *Config) error {
 	return nil
 }
 
+// sandboxNamespaces lists the namespaces the sandbox of commands can isolate them in
+var sandboxNamespaces = []string{"mount", "uts", "ipc", "net", "pid", "user", "cgroup"}
+
+// validateSandbox checks the isolation, namespaces and users of the sandbox of commands
+func validateSandbox(sandbox SandboxConfig) error {
+	switch sandbox.Isolation {
+	case "", "unshare":
+	case "nsenter":
+		if sandbox.Target <= 0 {
+			return fmt.Errorf("sandbox isolation nsenter requires the target pid whose namespaces are entered")
+		}
+	default:
+		return fmt.Errorf("invalid sandbox isolation '%s', must be unshare or nsenter", sandbox.Isolation)
+	}
+	for _, namespace := range sandbox.Namespaces {
+		if !contains(sandboxNamespaces, namespace) {
+			return fmt.Errorf("invalid sandbox namespace '%s', must be one of: %s", namespace, strings.Join(sandboxNamespaces, ", "))
+		}
+	}
+	if strings.ContainsAny(sandbox.User, " \t\n") {
+		return fmt.Errorf("invalid sandbox user '%s', it cannot contain whitespace", sandbox.User)
+	}
+	return nil
+}
+
 // contains checks if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -301,56 +912,94 @@ func SetupLogging(level string) {
 	})
 }
 
-// GetConfigPaths returns all possible configuration file paths in search order
-func GetConfigPaths() []string {
-	paths := []string{
-		"./sai.yaml",
-		"./sai.yml",
-		"./.sai/config.yaml",
-		"./.sai/config.yml",
-	}
-
-	if homeDir, err := os.UserHomeDir(); err == nil {
-		homePaths := []string{
-			filepath.Join(homeDir, ".sai", "config.yaml"),
-			filepath.Join(homeDir, ".sai", "config.yml"),
-			filepath.Join(homeDir, ".config", "sai", "config.yaml"),
-			filepath.Join(homeDir, ".config", "sai", "config.yml"),
-		}
-		paths = append(paths, homePaths...)
+// FindConfigFile returns the configuration file LoadConfig reads for the given path,
+// empty when it uses the defaults
+func FindConfigFile(configPath string) string {
+	if configPath != "" {
+		return configPath
 	}
-
-	systemPaths := []string{
-		"/etc/sai/config.yaml",
-		"/etc/sai/config.yml",
-		"/usr/local/etc/sai/config.yaml",
-		"/usr/local/etc/sai/config.yml",
+	path, err := discoverConfigFile()
+	if err != nil {
+		return ""
 	}
-	paths = append(paths, systemPaths...)
+	return path
+}
 
+// GetConfigPaths returns all possible configuration file paths, from the highest
+// precedence to the lowest
+func GetConfigPaths() []string {
+	var paths []string
+	layers := fileLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		paths = append(paths, layers[i].candidates...)
+	}
 	return paths
 }
 
 // SaveConfig saves the configuration to a YAML file
 func SaveConfig(config *Config, path string) error {
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-
 	data, err := yaml.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config to YAML: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	// Write atomically so an interrupted save never leaves a truncated config
+	if err := fileutil.WriteFileAtomic(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
+// SaveRepositories replaces the saidata repositories of the configuration file at path,
+// creating the file when missing. The rest of the file, comments included, is kept.
+func SaveRepositories(path string, repositories []SaidataRepositoryConfig) error {
+	var document yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("failed to parse YAML config: %w", err)
+	}
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to parse YAML config: %s is not a mapping", path)
+	}
+
+	var value yaml.Node
+	if err := value.Encode(repositories); err != nil {
+		return fmt.Errorf("failed to marshal repositories to YAML: %w", err)
+	}
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "repositories" {
+			root.Content[i+1] = &value
+			replaced = true
+		}
+	}
+	if !replaced {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "repositories"}, &value)
+	}
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return fmt.Errorf("failed to marshal config to YAML: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(path, []byte(out.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
 // RequiresConfirmation checks if an action requires user confirmation
 func (c *Config) RequiresConfirmation(action string) bool {
 	switch action {
@@ -362,13 +1011,42 @@ func (c *Config) RequiresConfirmation(action string) bool {
 		return c.Confirmations.Upgrade
 	case "start", "stop", "restart", "enable", "disable":
 		return c.Confirmations.ServiceOps
-	case "search", "info", "version", "status", "logs", "config", "check", "cpu", "memory", "io", "list", "stats":
+	case "search", "info", "version", "status", "logs", "config", "check", "cpu", "memory", "io", "list", "list_installed", "stats", "inventory", "verify", "origin", "signature":
 		return c.Confirmations.InfoCommands
 	default:
 		return c.Confirmations.SystemChanges
 	}
 }
 
+// GetRiskTier returns the configured risk tier for an action
+func (c *Config) GetRiskTier(action string) string {
+	if tier, exists := c.RiskTiers[action]; exists {
+		return tier
+	}
+	if tier, exists := defaultRiskTiers()[action]; exists {
+		return tier
+	}
+	if c.IsInformationOnlyAction(action) {
+		return RiskTierInfo
+	}
+	return RiskTierSafe
+}
+
+// IsDestructiveAction determines if an action is classified in the destructive risk tier
+func (c *Config) IsDestructiveAction(action string) bool {
+	return c.GetRiskTier(action) == RiskTierDestructive
+}
+
+// IsProduction determines if the host is marked as a production environment
+func (c *Config) IsProduction() bool {
+	return strings.EqualFold(c.Environment, EnvironmentProduction)
+}
+
+// RequiresTypedConfirmation checks if an action must be confirmed by typing the full action phrase
+func (c *Config) RequiresTypedConfirmation(action string) bool {
+	return c.IsProduction() && c.IsDestructiveAction(action)
+}
+
 // IsSystemChangingAction determines if an action changes system state
 func (c *Config) IsSystemChangingAction(action string) bool {
 	systemChangingActions := []string{
@@ -385,12 +1063,27 @@ func (c *Config) IsSystemChangingAction(action string) bool {
 	return false
 }
 
+// IsStrictAction reports whether an action is refused on saidata generated from
+// defaults. Actions that only display information are never refused.
+func (c *Config) IsStrictAction(action string) bool {
+	if c.IsInformationOnlyAction(action) {
+		return false
+	}
+	for _, strict := range c.Security.StrictActions {
+		if action == strict {
+			return true
+		}
+	}
+	return false
+}
+
 // IsInformationOnlyAction determines if an action only displays information
 func (c *Config) IsInformationOnlyAction(action string) bool {
 	infoOnlyActions := []string{
 		"search", "info", "version", "status",
 		"logs", "config", "check", "cpu", "memory", "io",
-		"list", "stats", "saidata",
+		"list", "list_installed", "stats", "saidata", "inventory", "verify",
+		"origin", "signature",
 	}
 	
 	for _, infoAction := range infoOnlyActions {
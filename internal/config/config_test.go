@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -33,6 +34,14 @@ func TestGetDefaultConfig(t *testing.T) {
 	if config.Output.ProviderColor != "blue" {
 		t.Errorf("Expected default provider color to be 'blue', got '%s'", config.Output.ProviderColor)
 	}
+
+	if !config.Gatekeeper.ClearQuarantine || config.Gatekeeper.VerifySignature || config.Gatekeeper.RequireNotarization {
+		t.Errorf("Expected quarantine clearing without signature checks by default, got %+v", config.Gatekeeper)
+	}
+
+	if !config.AdaptiveTimeout.Enabled || config.AdaptiveTimeout.Ceiling != 2*time.Hour {
+		t.Errorf("Expected adaptive timeouts enabled with a 2h ceiling by default, got %+v", config.AdaptiveTimeout)
+	}
 }
 
 func TestValidateConfig(t *testing.T) {
@@ -73,6 +82,105 @@ func TestValidateConfig(t *testing.T) {
 			}(),
 			wantErr: true,
 		},
+		{
+			name: "invalid redaction pattern",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Redaction.Patterns = []string{"token=(["}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid wsl preference",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.WSLPrefer = "macos"
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "zero adaptive timeout ceiling",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.AdaptiveTimeout.Ceiling = 0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "zero saidata cache ttl",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.SaidataCache.TTL = 0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "sparse software path",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Repository.Sparse.Software = []string{"nginx", "../etc"}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "sigstore signature without identity",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Repository.Signature.Method = "sigstore"
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "unknown signature method",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Repository.Signature.Method = "md5"
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "negative cleanup retention",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Cleanup.KeepFailedDays = -1
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "no batch workers",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Batch.Workers = 0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "empty transactions dir",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Transactions.Dir = ""
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "negative lock timeout",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Locks.Timeout = -time.Second
+				return c
+			}(),
+			wantErr: true,
+		},
 		{
 			name: "invalid provider color",
 			config: func() *Config {
@@ -82,6 +190,99 @@ func TestValidateConfig(t *testing.T) {
 			}(),
 			wantErr: true,
 		},
+		{
+			name: "invalid open circuit breaker policy",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.CircuitBreaker.OpenProviders = "skip"
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "provider executable with whitespace",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Providers = map[string]ProviderOverride{"apt": {Executable: "/opt/my tools/apt-fast"}}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "zero across providers budget",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.AcrossProviders.Budget = 0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "negative provider budget",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Providers = map[string]ProviderOverride{"dnf": {Budget: -time.Second}}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "downloads proxy without scheme",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Downloads.Proxy = "proxy.example.com:3128"
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "rotated audit log without files kept",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Audit.MaxFiles = 0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "no download connection",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Downloads.Connections = 0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "saidata repositories",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Repositories = []SaidataRepositoryConfig{
+					{Name: "internal", GitURL: "https://git.example.com/saidata.git", Priority: 10},
+					{Name: "local", Path: "/srv/saidata", Priority: -1},
+				}
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "saidata repository named as the main one",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Repositories = []SaidataRepositoryConfig{{Name: DefaultRepositoryName, Path: "/srv/saidata"}}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "saidata repository without source",
+			config: func() *Config {
+				c := getDefaultConfig()
+				c.Repositories = []SaidataRepositoryConfig{{Name: "internal"}}
+				return c
+			}(),
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -144,6 +345,10 @@ confirmations:
 output:
   provider_color: "red"
   success_color: "yellow"
+providers:
+  apt:
+    executable: "/usr/local/bin/apt-fast"
+  dnf: {}
 `
 
 	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
@@ -151,7 +356,7 @@ output:
 	}
 
 	config := getDefaultConfig()
-	if err := loadConfigFromFile(config, configPath); err != nil {
+	if err := loadConfigFromFile(config, configPath, LayerFile); err != nil {
 		t.Fatalf("Failed to load config from file: %v", err)
 	}
 
@@ -182,6 +387,23 @@ output:
 	if config.Output.ProviderColor != "red" {
 		t.Errorf("Expected provider color to be loaded from file")
 	}
+
+	overrides := config.ExecutableOverrides()
+	if len(overrides) != 1 || overrides["apt"] != "/usr/local/bin/apt-fast" {
+		t.Errorf("Expected the apt executable override only, got %v", overrides)
+	}
+}
+
+func TestProviderBudget(t *testing.T) {
+	config := getDefaultConfig()
+	config.Providers = map[string]ProviderOverride{"dnf": {Budget: 20 * time.Second}}
+
+	if budget := config.ProviderBudget("dnf"); budget != 20*time.Second {
+		t.Errorf("ProviderBudget(dnf) = %v, want the provider budget", budget)
+	}
+	if budget := config.ProviderBudget("apt"); budget != config.AcrossProviders.Budget {
+		t.Errorf("ProviderBudget(apt) = %v, want the default budget", budget)
+	}
 }
 
 func TestRequiresConfirmation(t *testing.T) {
@@ -218,6 +440,59 @@ func TestRequiresConfirmation(t *testing.T) {
 	}
 }
 
+func TestGetRiskTier(t *testing.T) {
+	config := getDefaultConfig()
+
+	tests := []struct {
+		action   string
+		expected string
+	}{
+		{"install", RiskTierSafe},
+		{"upgrade", RiskTierDestructive},
+		{"uninstall", RiskTierDestructive},
+		{"restart", RiskTierSafe},
+		{"search", RiskTierInfo},
+		{"status", RiskTierInfo},
+		{"custom", RiskTierSafe},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			result := config.GetRiskTier(tt.action)
+			if result != tt.expected {
+				t.Errorf("GetRiskTier(%s) = %v, expected %v", tt.action, result, tt.expected)
+			}
+		})
+	}
+
+	config.RiskTiers["upgrade"] = RiskTierSafe
+	if config.IsDestructiveAction("upgrade") {
+		t.Error("Expected upgrade tier to be overridden by configuration")
+	}
+}
+
+func TestRequiresTypedConfirmation(t *testing.T) {
+	config := getDefaultConfig()
+
+	if config.RequiresTypedConfirmation("upgrade") {
+		t.Error("Expected typed confirmation to be disabled outside production")
+	}
+
+	config.Environment = "production"
+	if !config.RequiresTypedConfirmation("upgrade") {
+		t.Error("Expected typed confirmation for upgrade on production hosts")
+	}
+
+	if config.RequiresTypedConfirmation("install") {
+		t.Error("Expected no typed confirmation for safe actions on production hosts")
+	}
+
+	config.RiskTiers["upgrade"] = "critical"
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected validation error for unknown risk tier")
+	}
+}
+
 func TestIsSystemChangingAction(t *testing.T) {
 	config := getDefaultConfig()
 
@@ -290,6 +565,62 @@ func TestIsInformationOnlyAction(t *testing.T) {
 	}
 }
 
+func TestIsStrictAction(t *testing.T) {
+	config := getDefaultConfig()
+
+	tests := []struct {
+		action   string
+		expected bool
+	}{
+		{"install", true},
+		{"uninstall", true},
+		{"upgrade", false},
+		{"info", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			result := config.IsStrictAction(tt.action)
+			if result != tt.expected {
+				t.Errorf("IsStrictAction(%s) = %v, expected %v", tt.action, result, tt.expected)
+			}
+		})
+	}
+
+	// Information actions stay allowed even when listed
+	config.Security.StrictActions = []string{"info", "upgrade"}
+	if config.IsStrictAction("info") || !config.IsStrictAction("upgrade") {
+		t.Errorf("IsStrictAction() with strict_actions %v", config.Security.StrictActions)
+	}
+}
+
+func TestValidateSandbox(t *testing.T) {
+	tests := []struct {
+		name    string
+		sandbox SandboxConfig
+		valid   bool
+	}{
+		{"disabled", SandboxConfig{}, true},
+		{"unshare", SandboxConfig{Isolation: "unshare", Namespaces: []string{"mount", "net"}}, true},
+		{"nsenter", SandboxConfig{Isolation: "nsenter", Target: 1}, true},
+		{"nsenter without target", SandboxConfig{Isolation: "nsenter"}, false},
+		{"unknown isolation", SandboxConfig{Isolation: "chroot"}, false},
+		{"unknown namespace", SandboxConfig{Isolation: "unshare", Namespaces: []string{"time"}}, false},
+		{"user with whitespace", SandboxConfig{User: "sai builder"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := getDefaultConfig()
+			config.Security.Sandbox = tt.sandbox
+			err := validateConfig(config)
+			if (err == nil) != tt.valid {
+				t.Errorf("validateConfig() with sandbox %+v = %v, valid %v", tt.sandbox, err, tt.valid)
+			}
+		})
+	}
+}
+
 func TestSaveConfig(t *testing.T) {
 	config := getDefaultConfig()
 	config.DefaultProvider = "test-provider"
@@ -309,7 +640,7 @@ func TestSaveConfig(t *testing.T) {
 
 	// Load the saved config and verify
 	loadedConfig := getDefaultConfig()
-	if err := loadConfigFromFile(loadedConfig, configPath); err != nil {
+	if err := loadConfigFromFile(loadedConfig, configPath, LayerFile); err != nil {
 		t.Fatalf("Failed to load saved config: %v", err)
 	}
 
@@ -320,4 +651,142 @@ func TestSaveConfig(t *testing.T) {
 	if loadedConfig.LogLevel != "debug" {
 		t.Errorf("Expected saved log level to be 'debug', got '%s'", loadedConfig.LogLevel)
 	}
-}
\ No newline at end of file
+}
+
+func TestSaveRepositories(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("# Site settings\nlog_level: debug\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	repositories := []SaidataRepositoryConfig{{Name: "internal", GitURL: "https://git.example.com/saidata.git", Priority: 10}}
+	if err := SaveRepositories(configPath, repositories); err != nil {
+		t.Fatalf("Failed to save repositories: %v", err)
+	}
+	if err := SaveRepositories(configPath, repositories); err != nil {
+		t.Fatalf("Failed to save repositories again: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# Site settings\nlog_level: debug\n") {
+		t.Errorf("Expected the rest of the file to be kept, got:\n%s", data)
+	}
+	if strings.Count(string(data), "repositories:") != 1 {
+		t.Errorf("Expected the repositories to be replaced, got:\n%s", data)
+	}
+
+	loadedConfig := getDefaultConfig()
+	if err := loadConfigFromFile(loadedConfig, configPath, LayerFile); err != nil {
+		t.Fatalf("Failed to load saved config: %v", err)
+	}
+	if len(loadedConfig.Repositories) != 1 || loadedConfig.Repositories[0] != repositories[0] {
+		t.Errorf("Expected saved repositories %v, got %v", repositories, loadedConfig.Repositories)
+	}
+
+	newPath := filepath.Join(t.TempDir(), "sai", "config.yaml")
+	if err := SaveRepositories(newPath, nil); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	if path := FindConfigFile("/custom/sai.yaml"); path != "/custom/sai.yaml" {
+		t.Errorf("Expected explicit config path to be returned, got '%s'", path)
+	}
+
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "sai.yaml"), []byte("log_level: debug\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+	if path := FindConfigFile(""); path != "./sai.yaml" {
+		t.Errorf("Expected './sai.yaml' to be found, got '%s'", path)
+	}
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	tempDir := t.TempDir()
+	homeDir := filepath.Join(tempDir, "home")
+	projectDir := filepath.Join(tempDir, "project")
+	userConfig := filepath.Join(homeDir, ".config", "sai", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(userConfig), 0755); err != nil {
+		t.Fatalf("Failed to create user config directory: %v", err)
+	}
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project directory: %v", err)
+	}
+	if err := os.WriteFile(userConfig, []byte("log_level: debug\ntimeout: 1m\noutput:\n  stream: true\n"), 0644); err != nil {
+		t.Fatalf("Failed to create user config file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "sai.yaml"), []byte("timeout: 2m\n"), 0644); err != nil {
+		t.Fatalf("Failed to create project config file: %v", err)
+	}
+
+	t.Setenv("HOME", homeDir)
+	t.Setenv("SAI_CACHE_DIR", filepath.Join(tempDir, "cache"))
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(projectDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	config, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	config.DefaultProvider = "apt"
+	config.SetByFlag("default_provider", "--provider")
+
+	if config.LogLevel != "debug" || !config.Output.Stream || config.Timeout != 2*time.Minute {
+		t.Errorf("Layers not merged: log_level %s, output.stream %v, timeout %s", config.LogLevel, config.Output.Stream, config.Timeout)
+	}
+
+	expected := map[string]Origin{
+		"log_level":            {Layer: LayerUser, Source: userConfig},
+		"output.stream":        {Layer: LayerUser, Source: userConfig},
+		"output.show_commands": {Layer: LayerDefault},
+		"timeout":              {Layer: LayerProject, Source: "./sai.yaml"},
+		"cache_dir":            {Layer: LayerEnv, Source: "SAI_CACHE_DIR"},
+		"default_provider":     {Layer: LayerFlag, Source: "--provider"},
+	}
+	for key, origin := range expected {
+		if got := config.Origin(key); got != origin {
+			t.Errorf("Origin(%s) = %v, want %v", key, got, origin)
+		}
+	}
+
+	values, err := config.Values()
+	if err != nil {
+		t.Fatalf("Values failed: %v", err)
+	}
+	found := false
+	for _, value := range values {
+		if value.Key == "timeout" {
+			found = true
+			if value.Value != "2m0s" || value.Origin.Layer != LayerProject {
+				t.Errorf("Unexpected timeout value %+v", value)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Values misses timeout")
+	}
+
+	// An explicit file replaces the file layers
+	config, err = LoadConfig(userConfig)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if config.Timeout != time.Minute || config.Origin("timeout").Layer != LayerFile {
+		t.Errorf("Explicit file not read alone: timeout %s from %v", config.Timeout, config.Origin("timeout"))
+	}
+}
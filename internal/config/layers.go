@@ -0,0 +1,195 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layers of the configuration, from the lowest precedence to the highest. Every
+// existing file layer is read, each overriding the values the previous ones set.
+const (
+	LayerDefault = "default" // Built-in defaults
+	LayerSystem  = "system"  // /etc/sai/config.yaml
+	LayerUser    = "user"    // ~/.config/sai/config.yaml
+	LayerProject = "project" // ./sai.yaml
+	LayerFile    = "file"    // The file given with --config, read in place of the other files
+	LayerEnv     = "env"     // SAI_* environment variables
+	LayerFlag    = "flag"    // Command line flags
+)
+
+// Origin is the layer that set a configuration value, with the file, variable or
+// flag it was set by
+type Origin struct {
+	Layer  string `json:"layer"`
+	Source string `json:"source,omitempty"`
+}
+
+// String returns the layer followed by its source
+func (o Origin) String() string {
+	if o.Source == "" {
+		return o.Layer
+	}
+	return fmt.Sprintf("%s (%s)", o.Layer, o.Source)
+}
+
+// Value is a configuration value with the layer that set it, keys of nested settings
+// being joined with dots
+type Value struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Origin Origin `json:"origin"`
+}
+
+// fileLayer is a layer read from the first existing file of its candidates
+type fileLayer struct {
+	name       string
+	candidates []string
+}
+
+// fileLayers returns the file layers from the lowest precedence to the highest
+func fileLayers() []fileLayer {
+	layers := []fileLayer{{
+		name: LayerSystem,
+		candidates: []string{
+			"/etc/sai/config.yaml",
+			"/etc/sai/config.yml",
+			"/usr/local/etc/sai/config.yaml",
+			"/usr/local/etc/sai/config.yml",
+		},
+	}}
+
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		layers = append(layers, fileLayer{
+			name: LayerUser,
+			candidates: []string{
+				filepath.Join(homeDir, ".sai", "config.yaml"),
+				filepath.Join(homeDir, ".sai", "config.yml"),
+				filepath.Join(homeDir, ".config", "sai", "config.yaml"),
+				filepath.Join(homeDir, ".config", "sai", "config.yml"),
+			},
+		})
+	}
+
+	return append(layers, fileLayer{
+		name: LayerProject,
+		candidates: []string{
+			"./sai.yaml",
+			"./sai.yml",
+			"./.sai/config.yaml",
+			"./.sai/config.yml",
+		},
+	})
+}
+
+// find returns the file of the layer, empty when none exists
+func (l fileLayer) find() string {
+	for _, path := range l.candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// Origin returns the layer that set a value, or one of its parents, by key
+func (c *Config) Origin(key string) Origin {
+	for {
+		if origin, exists := c.origins[key]; exists {
+			return origin
+		}
+		dot := strings.LastIndex(key, ".")
+		if dot < 0 {
+			return Origin{Layer: LayerDefault}
+		}
+		key = key[:dot]
+	}
+}
+
+// SetByFlag records that a command line flag set a value
+func (c *Config) SetByFlag(key, flag string) {
+	c.setOrigin(key, Origin{Layer: LayerFlag, Source: flag})
+}
+
+// setOrigin records the layer that set a value, replacing the origins of the values
+// below it
+func (c *Config) setOrigin(key string, origin Origin) {
+	if c.origins == nil {
+		c.origins = make(map[string]Origin)
+	}
+	for existing := range c.origins {
+		if strings.HasPrefix(existing, key+".") {
+			delete(c.origins, existing)
+		}
+	}
+	c.origins[key] = origin
+}
+
+// recordOrigins records a file as the origin of the values it sets
+func (c *Config) recordOrigins(data []byte, origin Origin) error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	if len(document.Content) == 0 {
+		return nil
+	}
+	walkLeaves(document.Content[0], "", func(key string, _ *yaml.Node) {
+		c.setOrigin(key, origin)
+	})
+	return nil
+}
+
+// Values returns the effective configuration with the origin of every value, sorted
+// by key
+func (c *Config) Values() ([]Value, error) {
+	var document yaml.Node
+	if err := document.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	var values []Value
+	var encodeErr error
+	walkLeaves(&document, "", func(key string, node *yaml.Node) {
+		value := node.Value
+		if node.Kind != yaml.ScalarNode {
+			node.Style = yaml.FlowStyle
+			data, err := yaml.Marshal(node)
+			if err != nil {
+				encodeErr = err
+			}
+			value = strings.TrimSpace(string(data))
+		}
+		values = append(values, Value{Key: key, Value: value, Origin: c.Origin(key)})
+	})
+	if encodeErr != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", encodeErr)
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+	return values, nil
+}
+
+// walkLeaves calls visit with the dotted key of every value of a YAML mapping that is
+// not itself a non-empty mapping
+func walkLeaves(node *yaml.Node, prefix string, visit func(key string, node *yaml.Node)) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		value := node.Content[i+1]
+		if value.Kind == yaml.MappingNode && len(value.Content) > 0 {
+			walkLeaves(value, key, visit)
+			continue
+		}
+		visit(key, value)
+	}
+}
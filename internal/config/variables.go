@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VariableEnvPrefix is the prefix of environment variables picked up as template variables
+const VariableEnvPrefix = "SAI_VAR_"
+
+// LoadVariables resolves template variables from a variables file, SAI_VAR_*
+// environment variables and key=value overrides. Later sources take precedence:
+//
+//	vars file < SAI_VAR_* environment < --var overrides
+func LoadVariables(varsFile string, overrides []string) (map[string]string, error) {
+	variables := make(map[string]string)
+
+	if varsFile != "" {
+		fileVars, err := LoadVariablesFile(varsFile)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fileVars {
+			variables[key] = value
+		}
+	}
+
+	for key, value := range EnvironmentVariables(os.Environ()) {
+		variables[key] = value
+	}
+
+	for _, override := range overrides {
+		key, value, err := ParseVariable(override)
+		if err != nil {
+			return nil, err
+		}
+		variables[key] = value
+	}
+
+	return variables, nil
+}
+
+// LoadVariablesFile reads a YAML (or JSON) file of variables. The file is either a flat
+// map or has the variables under a top-level "variables" key. Scalar values are kept
+// as written, so "1.10" is not turned into "1.1".
+func LoadVariablesFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables file %s: %w", path, err)
+	}
+
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse variables file %s: %w", path, err)
+	}
+
+	if nested, ok := raw["variables"]; ok && len(raw) == 1 && nested.Kind == yaml.MappingNode {
+		raw = nil
+		if err := nested.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("failed to parse variables file %s: %w", path, err)
+		}
+	}
+
+	variables := make(map[string]string, len(raw))
+	for key, node := range raw {
+		if node.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("variable %s in %s must be a scalar value", key, path)
+		}
+		if node.Tag == "!!null" {
+			variables[key] = ""
+			continue
+		}
+		variables[key] = node.Value
+	}
+
+	return variables, nil
+}
+
+// EnvironmentVariables extracts SAI_VAR_* entries from an environment list. The
+// prefix is stripped and names are lower-cased, so SAI_VAR_GOPATH sets "gopath".
+func EnvironmentVariables(environ []string) map[string]string {
+	variables := make(map[string]string)
+	for _, entry := range environ {
+		if !strings.HasPrefix(entry, VariableEnvPrefix) {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(entry, VariableEnvPrefix), "=")
+		if !found || key == "" {
+			continue
+		}
+		variables[strings.ToLower(key)] = value
+	}
+	return variables
+}
+
+// ParseVariable parses a key=value variable assignment
+func ParseVariable(assignment string) (string, string, error) {
+	key, value, found := strings.Cut(assignment, "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return "", "", fmt.Errorf("invalid variable '%s': expected key=value", assignment)
+	}
+	return key, value, nil
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadVariablesPrecedence(t *testing.T) {
+	varsFile := filepath.Join(t.TempDir(), "vars.yaml")
+	content := "version: 1.10\nport: 8080\nregion: eu\n"
+	if err := os.WriteFile(varsFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write variables file: %v", err)
+	}
+
+	t.Setenv("SAI_VAR_PORT", "9090")
+	t.Setenv("SAI_VAR_REGION", "us")
+
+	variables, err := LoadVariables(varsFile, []string{"region=ap", "empty="})
+	if err != nil {
+		t.Fatalf("LoadVariables failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"version": "1.10", // from file, kept as written
+		"port":    "9090", // environment overrides file
+		"region":  "ap",   // --var overrides environment
+		"empty":   "",
+	}
+	for key, value := range expected {
+		if variables[key] != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, variables[key])
+		}
+	}
+}
+
+func TestLoadVariablesFile(t *testing.T) {
+	dir := t.TempDir()
+
+	nested := filepath.Join(dir, "nested.yaml")
+	os.WriteFile(nested, []byte("variables:\n  gopath: /opt/go\n"), 0644)
+	variables, err := LoadVariablesFile(nested)
+	if err != nil {
+		t.Fatalf("LoadVariablesFile failed: %v", err)
+	}
+	if variables["gopath"] != "/opt/go" {
+		t.Errorf("Expected gopath from nested variables key, got %v", variables)
+	}
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	os.WriteFile(invalid, []byte("list: [a, b]\n"), 0644)
+	if _, err := LoadVariablesFile(invalid); err == nil {
+		t.Error("Expected error for non-scalar variable")
+	}
+
+	if _, err := LoadVariablesFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Expected error for missing variables file")
+	}
+}
+
+func TestEnvironmentVariables(t *testing.T) {
+	variables := EnvironmentVariables([]string{
+		"SAI_VAR_GOPATH=/opt/go",
+		"SAI_VAR_WITH_EQUALS=a=b",
+		"SAI_VAR_=ignored",
+		"SAI_TIMEOUT=30s",
+		"PATH=/usr/bin",
+	})
+
+	if len(variables) != 2 {
+		t.Fatalf("Expected 2 variables, got %v", variables)
+	}
+	if variables["gopath"] != "/opt/go" {
+		t.Errorf("Expected gopath=/opt/go, got %q", variables["gopath"])
+	}
+	if variables["with_equals"] != "a=b" {
+		t.Errorf("Expected with_equals=a=b, got %q", variables["with_equals"])
+	}
+}
+
+func TestParseVariable(t *testing.T) {
+	if key, value, err := ParseVariable("version=1.2=3"); err != nil || key != "version" || value != "1.2=3" {
+		t.Errorf("Unexpected result: %q %q %v", key, value, err)
+	}
+	if _, _, err := ParseVariable("novalue"); err == nil {
+		t.Error("Expected error for missing '='")
+	}
+	if _, _, err := ParseVariable("=value"); err == nil {
+		t.Error("Expected error for empty key")
+	}
+}
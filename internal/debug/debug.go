@@ -62,6 +62,8 @@ func NewDebugManager(enabled bool) *DebugManager {
 			logrus.FieldKeyMsg:   "message",
 		},
 	})
+	// Redact before the console hook and the debug file see an entry
+	logger.AddHook(&RedactionHook{})
 
 	sessionID := fmt.Sprintf("sai-debug-%d", time.Now().Unix())
 
@@ -560,7 +562,7 @@ func filterSensitiveEnvVars(env []string) []string {
 		}
 		
 		if isSensitive {
-			filtered = append(filtered, parts[0]+"=***REDACTED***")
+			filtered = append(filtered, parts[0]+"="+redactedValue)
 		} else {
 			filtered = append(filtered, envVar)
 		}
@@ -585,7 +587,7 @@ func filterSensitiveConfigData(configData map[string]interface{}) map[string]int
 		}
 		
 		if isSensitive {
-			filtered[key] = "***REDACTED***"
+			filtered[key] = redactedValue
 		} else {
 			filtered[key] = value
 		}
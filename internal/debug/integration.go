@@ -44,7 +44,7 @@ func LogTemplateResolutionGlobal(template string, variables map[string]interface
 // LogCommandExecutionGlobal logs command execution using the global debug manager
 func LogCommandExecutionGlobal(command string, provider string, args []string, env []string, workingDir string, exitCode int, output string, stderr string, duration time.Duration) {
 	if globalDebugManager != nil {
-		globalDebugManager.LogCommandExecution(command, provider, args, env, workingDir, exitCode, output, stderr, duration)
+		globalDebugManager.LogCommandExecution(MaskSecrets(command), provider, MaskSecretsAll(args), env, workingDir, exitCode, MaskSecrets(output), MaskSecrets(stderr), duration)
 	}
 }
 
@@ -0,0 +1,57 @@
+package debug
+
+import (
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces sensitive values in logs and output
+const redactedValue = "***REDACTED***"
+
+// secretValues holds values, such as secret action inputs, masked wherever they appear
+var secretValues struct {
+	mutex  sync.RWMutex
+	values []string
+}
+
+// RegisterSecret marks a value as sensitive so MaskSecrets and debug logging hide it
+func RegisterSecret(value string) {
+	if value == "" {
+		return
+	}
+
+	secretValues.mutex.Lock()
+	defer secretValues.mutex.Unlock()
+
+	for _, existing := range secretValues.values {
+		if existing == value {
+			return
+		}
+	}
+	secretValues.values = append(secretValues.values, value)
+}
+
+// MaskSecrets replaces every registered secret value in text, then the secrets matched
+// by the redaction filters
+func MaskSecrets(text string) string {
+	secretValues.mutex.RLock()
+	for _, value := range secretValues.values {
+		text = strings.ReplaceAll(text, value, redactedValue)
+	}
+	secretValues.mutex.RUnlock()
+
+	return applyRedactionFilters(text)
+}
+
+// MaskSecretsAll replaces every registered secret value in each text
+func MaskSecretsAll(texts []string) []string {
+	if texts == nil {
+		return nil
+	}
+
+	masked := make([]string, len(texts))
+	for i, text := range texts {
+		masked[i] = MaskSecrets(text)
+	}
+	return masked
+}
@@ -0,0 +1,99 @@
+package docserver
+
+// pageTemplates are the HTML pages of the documentation server, sharing a header
+// and footer
+const pageTemplates = `
+{{define "header"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 72em; padding: 0 1em; color: #222; }
+nav a { margin-right: 1.5em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.4em; text-align: left; vertical-align: top; }
+code, pre { background: #f5f5f5; font-size: 0.9em; }
+pre { padding: 1em; overflow-x: auto; }
+.error { color: #b00; }
+</style>
+</head>
+<body>
+<nav><a href="/">Home</a><a href="/functions">Template functions</a><a href="/providers">Providers</a><a href="/saidata">Saidata</a></nav>
+<h1>{{.Title}}</h1>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "index"}}{{template "header" .}}
+<ul>
+<li><a href="/functions">Template functions</a>: the {{.Functions}} functions available to provider templates</li>
+<li><a href="/providers">Providers</a>: reference of the {{.Providers}} loaded providers and their actions</li>
+<li><a href="/saidata">Saidata</a>: the effective saidata of software, after merging overrides</li>
+</ul>
+<p>Every page is also available as JSON under <code>/api</code>, for example <code>/api/providers</code>.</p>
+{{template "footer" .}}{{end}}
+
+{{define "functions"}}{{template "header" .}}
+<table>
+<tr><th>Function</th><th>Category</th><th>Usage</th><th>Description</th></tr>
+{{range .Functions}}<tr id="{{.Name}}">
+<td><code>{{.Name}}</code></td>
+<td>{{.Category}}</td>
+<td>{{range .Usage}}<code>{{"{{"}}{{.}}{{"}}"}}</code><br>{{end}}</td>
+<td>{{.Description}}</td>
+</tr>
+{{end}}</table>
+{{template "footer" .}}{{end}}
+
+{{define "providers"}}{{template "header" .}}
+<table>
+<tr><th>Provider</th><th>Type</th><th>Platforms</th><th>Priority</th><th>Available</th><th>Actions</th></tr>
+{{range .Providers}}<tr>
+<td><a href="/providers/{{.Name}}">{{.Name}}</a>{{if .DisplayName}}<br>{{.DisplayName}}{{end}}</td>
+<td>{{.Type}}</td>
+<td>{{range $i, $p := .Platforms}}{{if $i}}, {{end}}{{$p}}{{end}}</td>
+<td>{{.Priority}}</td>
+<td>{{if .Available}}yes{{else}}no{{end}}</td>
+<td>{{range $i, $a := .Actions}}{{if $i}}, {{end}}{{$a}}{{end}}</td>
+</tr>
+{{end}}</table>
+{{template "footer" .}}{{end}}
+
+{{define "provider"}}{{template "header" .}}
+{{with .Provider.Provider}}<p>{{.Description}}</p>
+<table>
+<tr><th>Type</th><td>{{.Type}}</td></tr>
+<tr><th>Platforms</th><td>{{range $i, $p := .Platforms}}{{if $i}}, {{end}}{{$p}}{{end}}</td></tr>
+<tr><th>Executable</th><td><code>{{.Executable}}</code></td></tr>
+<tr><th>Priority</th><td>{{.Priority}}</td></tr>
+<tr><th>Shell</th><td>{{.GetShell}}</td></tr>{{end}}
+<tr><th>Available</th><td>{{if .Available}}yes{{else}}no{{end}}</td></tr>
+</table>
+<h2>Actions</h2>
+<table>
+<tr><th>Action</th><th>Commands</th><th>Timeout</th><th>Rollback</th></tr>
+{{range .Actions}}<tr id="{{.Name}}">
+<td>{{.Name}}{{if .Description}}<br>{{.Description}}{{end}}</td>
+<td>{{range .Commands}}<code>{{.}}</code><br>{{end}}</td>
+<td>{{if .Timeout}}{{.Timeout}}s{{end}}</td>
+<td>{{if .Rollback}}<code>{{.Rollback}}</code>{{end}}</td>
+</tr>
+{{end}}</table>
+<h2>YAML</h2>
+<pre>{{.YAML}}</pre>
+{{template "footer" .}}{{end}}
+
+{{define "saidata"}}{{template "header" .}}
+<form action="/saidata" method="get">
+<input name="software" value="{{.Software}}" placeholder="nginx" autofocus>
+<button type="submit">Resolve</button>
+</form>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{if .YAML}}<h2>{{.Software}}</h2>
+<p>JSON: <a href="/api/saidata/{{.Software}}">/api/saidata/{{.Software}}</a></p>
+<pre>{{.YAML}}</pre>{{end}}
+{{template "footer" .}}{{end}}
+`
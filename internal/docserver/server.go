@@ -0,0 +1,247 @@
+// Package docserver serves a local web UI documenting the template functions, the
+// loaded providers and the effective saidata of software, for contributors exploring
+// the data model.
+package docserver
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	saitemplate "sai/internal/template"
+	"sai/internal/types"
+)
+
+// ProviderSource provides the loaded providers
+type ProviderSource interface {
+	GetAllProviders() []*types.ProviderData
+	IsProviderAvailable(name string) bool
+}
+
+// SaidataResolver returns the effective saidata of software, after merging overrides
+// or generating defaults
+type SaidataResolver func(software string) (*types.SoftwareData, error)
+
+// Server renders the documentation pages and their JSON counterparts under /api
+type Server struct {
+	providers ProviderSource
+	resolve   SaidataResolver
+	pages     *template.Template
+}
+
+// ProviderSummary is a row of the provider list
+type ProviderSummary struct {
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name,omitempty"`
+	Type        string   `json:"type"`
+	Platforms   []string `json:"platforms,omitempty"`
+	Priority    int      `json:"priority"`
+	Available   bool     `json:"available"`
+	Actions     []string `json:"actions"`
+}
+
+// ActionReference documents an action of a provider
+type ActionReference struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Commands    []string `json:"commands"`
+	Timeout     int      `json:"timeout,omitempty"`
+	Rollback    string   `json:"rollback,omitempty"`
+}
+
+// NewServer creates a documentation server
+func NewServer(providers ProviderSource, resolve SaidataResolver) *Server {
+	return &Server{
+		providers: providers,
+		resolve:   resolve,
+		pages:     template.Must(template.New("pages").Parse(pageTemplates)),
+	}
+}
+
+// Handler returns the HTTP handler of the documentation server
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /functions", s.handleFunctions)
+	mux.HandleFunc("GET /providers", s.handleProviders)
+	mux.HandleFunc("GET /providers/{name}", s.handleProvider)
+	mux.HandleFunc("GET /saidata", s.handleSaidata)
+
+	mux.HandleFunc("GET /api/functions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, saitemplate.Functions())
+	})
+	mux.HandleFunc("GET /api/providers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.providerSummaries())
+	})
+	mux.HandleFunc("GET /api/providers/{name}", func(w http.ResponseWriter, r *http.Request) {
+		provider := s.findProvider(r.PathValue("name"))
+		if provider == nil {
+			http.Error(w, "provider not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, provider)
+	})
+	mux.HandleFunc("GET /api/saidata/{software}", func(w http.ResponseWriter, r *http.Request) {
+		saidata, err := s.resolve(r.PathValue("software"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, saidata)
+	})
+	return mux
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	s.render(w, "index", map[string]interface{}{
+		"Title":     "SAI documentation",
+		"Functions": len(saitemplate.Functions()),
+		"Providers": len(s.providers.GetAllProviders()),
+	})
+}
+
+func (s *Server) handleFunctions(w http.ResponseWriter, r *http.Request) {
+	s.render(w, "functions", map[string]interface{}{
+		"Title":     "Template functions",
+		"Functions": saitemplate.Functions(),
+	})
+}
+
+func (s *Server) handleProviders(w http.ResponseWriter, r *http.Request) {
+	s.render(w, "providers", map[string]interface{}{
+		"Title":     "Providers",
+		"Providers": s.providerSummaries(),
+	})
+}
+
+func (s *Server) handleProvider(w http.ResponseWriter, r *http.Request) {
+	provider := s.findProvider(r.PathValue("name"))
+	if provider == nil {
+		http.Error(w, "provider not found", http.StatusNotFound)
+		return
+	}
+
+	data, err := yaml.Marshal(provider)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.render(w, "provider", map[string]interface{}{
+		"Title":     "Provider " + provider.Provider.Name,
+		"Provider":  provider,
+		"Available": s.providers.IsProviderAvailable(provider.Provider.Name),
+		"Actions":   actionReferences(provider),
+		"YAML":      string(data),
+	})
+}
+
+func (s *Server) handleSaidata(w http.ResponseWriter, r *http.Request) {
+	software := strings.TrimSpace(r.URL.Query().Get("software"))
+	page := map[string]interface{}{
+		"Title":    "Effective saidata",
+		"Software": software,
+	}
+
+	if software != "" {
+		saidata, err := s.resolve(software)
+		if err != nil {
+			page["Error"] = err.Error()
+		} else if data, err := yaml.Marshal(saidata); err != nil {
+			page["Error"] = err.Error()
+		} else {
+			page["YAML"] = string(data)
+		}
+	}
+	s.render(w, "saidata", page)
+}
+
+// render executes a page template
+func (s *Server) render(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.pages.ExecuteTemplate(w, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// providerSummaries lists the loaded providers sorted by name
+func (s *Server) providerSummaries() []ProviderSummary {
+	var summaries []ProviderSummary
+	for _, provider := range s.providers.GetAllProviders() {
+		actions := make([]string, 0, len(provider.Actions))
+		for name := range provider.Actions {
+			actions = append(actions, name)
+		}
+		sort.Strings(actions)
+
+		summaries = append(summaries, ProviderSummary{
+			Name:        provider.Provider.Name,
+			DisplayName: provider.Provider.DisplayName,
+			Type:        provider.Provider.Type,
+			Platforms:   provider.Provider.Platforms,
+			Priority:    provider.Provider.Priority,
+			Available:   s.providers.IsProviderAvailable(provider.Provider.Name),
+			Actions:     actions,
+		})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// findProvider returns the loaded provider with the name, or nil
+func (s *Server) findProvider(name string) *types.ProviderData {
+	for _, provider := range s.providers.GetAllProviders() {
+		if provider.Provider.Name == name {
+			return provider
+		}
+	}
+	return nil
+}
+
+// actionReferences documents the actions of a provider sorted by name
+func actionReferences(provider *types.ProviderData) []ActionReference {
+	references := make([]ActionReference, 0, len(provider.Actions))
+	for name, action := range provider.Actions {
+		reference := ActionReference{
+			Name:        name,
+			Description: action.Description,
+			Timeout:     action.Timeout,
+			Rollback:    action.Rollback,
+		}
+
+		switch {
+		case len(action.Steps) > 0:
+			for _, step := range action.Steps {
+				reference.Commands = append(reference.Commands, step.Command)
+			}
+		case action.Template != "":
+			reference.Commands = []string{action.Template}
+		case action.Command != "":
+			reference.Commands = []string{action.Command}
+		case action.Script != "":
+			reference.Commands = []string{action.Script}
+		}
+		references = append(references, reference)
+	}
+
+	sort.Slice(references, func(i, j int) bool {
+		return references[i].Name < references[j].Name
+	})
+	return references
+}
+
+// writeJSON writes a value as indented JSON
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
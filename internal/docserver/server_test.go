@@ -0,0 +1,120 @@
+package docserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/types"
+)
+
+type fakeProviders struct {
+	providers []*types.ProviderData
+	available map[string]bool
+}
+
+func (f *fakeProviders) GetAllProviders() []*types.ProviderData { return f.providers }
+func (f *fakeProviders) IsProviderAvailable(name string) bool   { return f.available[name] }
+
+func newTestServer(t *testing.T) *httptest.Server {
+	providers := &fakeProviders{
+		providers: []*types.ProviderData{
+			{
+				Provider: types.ProviderInfo{Name: "apt", Type: "package_manager", Platforms: []string{"debian"}, Executable: "apt-get"},
+				Actions: map[string]types.Action{
+					"install": {Description: "Install packages", Template: "apt-get install -y {{sai_packages}}", Rollback: "apt-get remove -y {{sai_packages}}"},
+					"start":   {Steps: []types.Step{{Command: "systemctl start {{sai_service(0, 'service_name')}}"}}},
+				},
+			},
+			{
+				Provider: types.ProviderInfo{Name: "brew", Type: "package_manager", Platforms: []string{"macos"}},
+			},
+		},
+		available: map[string]bool{"apt": true},
+	}
+
+	resolve := func(software string) (*types.SoftwareData, error) {
+		if software != "nginx" {
+			return nil, fmt.Errorf("no saidata for %s", software)
+		}
+		return &types.SoftwareData{
+			Version:  "0.2",
+			Metadata: types.Metadata{Name: "nginx"},
+			Packages: []types.Package{{Name: "nginx"}},
+		}, nil
+	}
+
+	server := httptest.NewServer(NewServer(providers, resolve).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, url string) (int, string) {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestServer_Pages(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		path     string
+		status   int
+		contains []string
+	}{
+		{path: "/", status: http.StatusOK, contains: []string{"2 loaded providers"}},
+		{path: "/functions", status: http.StatusOK, contains: []string{"sai_package", "default_log_path"}},
+		{path: "/providers", status: http.StatusOK, contains: []string{`href="/providers/apt"`, "install, start"}},
+		{path: "/providers/apt", status: http.StatusOK, contains: []string{"apt-get install -y", "systemctl start", "Rollback", "executable: apt-get"}},
+		{path: "/providers/missing", status: http.StatusNotFound},
+		{path: "/saidata?software=nginx", status: http.StatusOK, contains: []string{"name: nginx", "/api/saidata/nginx"}},
+		{path: "/saidata?software=unknown", status: http.StatusOK, contains: []string{"no saidata for unknown"}},
+		{path: "/nothing", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			status, body := get(t, server.URL+tt.path)
+			assert.Equal(t, tt.status, status)
+			for _, text := range tt.contains {
+				assert.Contains(t, body, text)
+			}
+		})
+	}
+}
+
+func TestServer_API(t *testing.T) {
+	server := newTestServer(t)
+
+	status, body := get(t, server.URL+"/api/providers")
+	require.Equal(t, http.StatusOK, status)
+	var summaries []ProviderSummary
+	require.NoError(t, json.Unmarshal([]byte(body), &summaries))
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "apt", summaries[0].Name)
+	assert.True(t, summaries[0].Available)
+	assert.Equal(t, []string{"install", "start"}, summaries[0].Actions)
+	assert.False(t, summaries[1].Available)
+
+	status, body = get(t, server.URL+"/api/saidata/nginx")
+	require.Equal(t, http.StatusOK, status)
+	var saidata types.SoftwareData
+	require.NoError(t, json.Unmarshal([]byte(body), &saidata))
+	assert.Equal(t, "nginx", saidata.Metadata.Name)
+
+	status, _ = get(t, server.URL+"/api/saidata/unknown")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, body = get(t, server.URL+"/api/functions")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"name": "sai_require"`)
+}
@@ -0,0 +1,190 @@
+// Package doctor diagnoses the environment sai runs in: each check reports whether a
+// part of the environment works, and how to fix it when it does not, so that a broken
+// host can be repaired without reading the debug logs of a failed action.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Statuses of checks
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusError   = "error"
+)
+
+// reachabilityTimeout bounds each network reachability check
+const reachabilityTimeout = 10 * time.Second
+
+// Check is the result of one diagnostic
+type Check struct {
+	Category    string `json:"category"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"` // What to do when the check did not pass
+}
+
+// Report collects the checks of a diagnosis in the order they ran
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+// OK records a passing check
+func (r *Report) OK(category, name, message string) {
+	r.add(category, name, StatusOK, message, "")
+}
+
+// Warn records a check that passed with a problem worth fixing
+func (r *Report) Warn(category, name, message, remediation string) {
+	r.add(category, name, StatusWarning, message, remediation)
+}
+
+// Fail records a failing check
+func (r *Report) Fail(category, name, message, remediation string) {
+	r.add(category, name, StatusError, message, remediation)
+}
+
+func (r *Report) add(category, name, status, message, remediation string) {
+	r.Checks = append(r.Checks, Check{
+		Category:    category,
+		Name:        name,
+		Status:      status,
+		Message:     message,
+		Remediation: remediation,
+	})
+}
+
+// Count returns the number of checks with a status
+func (r *Report) Count(status string) int {
+	count := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Healthy reports whether no check failed
+func (r *Report) Healthy() bool {
+	return r.Count(StatusError) == 0
+}
+
+// CheckPath checks the directories of a PATH value: relative entries and directories
+// writable by every user let another user or the current directory substitute the
+// commands sai runs, missing directories are only noted
+func CheckPath(r *Report, path string) {
+	const category, name = "environment", "PATH"
+	if path == "" {
+		r.Fail(category, name, "PATH is empty, provider commands cannot be found",
+			"Set PATH, for example to /usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin")
+		return
+	}
+
+	var relative, writable, missing []string
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" || !filepath.IsAbs(dir) {
+			relative = append(relative, fmt.Sprintf("%q", dir))
+			continue
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			missing = append(missing, dir)
+			continue
+		}
+		if info.Mode().Perm()&0002 != 0 && info.Mode()&os.ModeSticky == 0 {
+			writable = append(writable, dir)
+		}
+	}
+
+	switch {
+	case len(relative) > 0:
+		r.Fail(category, name, "PATH has relative entries: "+strings.Join(relative, ", "),
+			"Remove the relative entries from PATH, files of the current directory could run instead of provider commands")
+	case len(writable) > 0:
+		r.Fail(category, name, "PATH has directories writable by every user: "+strings.Join(writable, ", "),
+			"Run 'chmod o-w "+strings.Join(writable, " ")+"' or remove them from PATH")
+	case len(missing) > 0:
+		r.Warn(category, name, "PATH has directories that do not exist: "+strings.Join(missing, ", "),
+			"Remove them from PATH")
+	default:
+		r.OK(category, name, fmt.Sprintf("%d directories", len(filepath.SplitList(path))))
+	}
+}
+
+// CheckWritable checks that sai can write to a directory it keeps state in, creating
+// it when missing as sai does on first use
+func CheckWritable(r *Report, name, dir string) {
+	const category = "permissions"
+	if dir == "" {
+		r.Warn(category, name, "not configured", "")
+		return
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		r.Fail(category, name, fmt.Sprintf("%s cannot be created: %v", dir, err),
+			fmt.Sprintf("Create %s writable by this user, or run sai with sudo", dir))
+		return
+	}
+	probe, err := os.CreateTemp(dir, ".sai-doctor-*")
+	if err != nil {
+		r.Fail(category, name, fmt.Sprintf("%s is not writable: %v", dir, err),
+			fmt.Sprintf("Run 'sudo chown -R %s %s', or run sai with sudo", currentUser(), dir))
+		return
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	r.OK(category, name, dir)
+}
+
+// CheckReachable checks that an HTTP(S) URL answers, any status but a server error
+// meaning the network path works
+func CheckReachable(ctx context.Context, r *Report, name, url string) {
+	const category = "network"
+	if url == "" {
+		return
+	}
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		r.Warn(category, name, fmt.Sprintf("%s is not an HTTP(S) URL and was not checked", url), "")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reachabilityTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		r.Fail(category, name, fmt.Sprintf("invalid URL %s: %v", url, err), "Fix the URL in the configuration")
+		return
+	}
+
+	start := time.Now()
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		r.Fail(category, name, fmt.Sprintf("%s is not reachable: %v", url, err),
+			"Check the DNS resolution, the firewall and the HTTPS_PROXY variable, or set repository.offline_mode")
+		return
+	}
+	response.Body.Close()
+	if response.StatusCode >= 500 {
+		r.Warn(category, name, fmt.Sprintf("%s answered %s", url, response.Status), "Retry later, the server has a problem")
+		return
+	}
+	r.OK(category, name, fmt.Sprintf("%s (%s)", url, time.Since(start).Round(time.Millisecond)))
+}
+
+// currentUser returns the name of the user running sai, for remediation commands
+func currentUser() string {
+	for _, variable := range []string{"SUDO_USER", "USER", "USERNAME"} {
+		if user := os.Getenv(variable); user != "" {
+			return user
+		}
+	}
+	return "$USER"
+}
@@ -0,0 +1,91 @@
+package doctor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPath(t *testing.T) {
+	safe := t.TempDir()
+	writable := t.TempDir()
+	require.NoError(t, os.Chmod(writable, 0777))
+	missing := filepath.Join(safe, "missing")
+
+	tests := []struct {
+		name   string
+		path   string
+		status string
+	}{
+		{"safe directories", safe, StatusOK},
+		{"empty", "", StatusError},
+		{"relative entry", safe + string(os.PathListSeparator) + ".", StatusError},
+		{"empty entry", safe + string(os.PathListSeparator), StatusError},
+		{"world-writable directory", writable, StatusError},
+		{"missing directory", safe + string(os.PathListSeparator) + missing, StatusWarning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &Report{}
+			CheckPath(report, tt.path)
+			require.Len(t, report.Checks, 1)
+			assert.Equal(t, tt.status, report.Checks[0].Status, report.Checks[0].Message)
+			if tt.status != StatusOK {
+				assert.NotEmpty(t, report.Checks[0].Remediation)
+			}
+		})
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	report := &Report{}
+	dir := filepath.Join(t.TempDir(), "cache")
+	CheckWritable(report, "cache directory", dir)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, StatusOK, report.Checks[0].Status)
+	assert.DirExists(t, dir)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the probe file is removed")
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	CheckWritable(report, "managed directory", filepath.Join(file, "managed"))
+	assert.Equal(t, StatusError, report.Checks[1].Status)
+	assert.False(t, report.Healthy())
+}
+
+func TestCheckReachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/broken") {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	report := &Report{}
+	CheckReachable(context.Background(), report, "repository", server.URL+"/saidata.git")
+	CheckReachable(context.Background(), report, "mirror", server.URL+"/broken")
+	CheckReachable(context.Background(), report, "ssh", "git@github.com:example42/saidata.git")
+	CheckReachable(context.Background(), report, "unset", "")
+
+	require.Len(t, report.Checks, 3)
+	assert.Equal(t, StatusOK, report.Checks[0].Status, "any answer but a server error means the host is reachable")
+	assert.Equal(t, StatusWarning, report.Checks[1].Status)
+	assert.Equal(t, StatusWarning, report.Checks[2].Status)
+
+	server.Close()
+	CheckReachable(context.Background(), report, "repository", server.URL)
+	assert.Equal(t, StatusError, report.Checks[3].Status)
+	assert.Equal(t, 1, report.Count(StatusError))
+}
@@ -0,0 +1,235 @@
+// Package download fetches installers over HTTP and verifies them against a pinned
+// checksum or a checksum file published next to the installer. Downloads can be served
+// from a local mirror on systems without internet access.
+package download
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"sai/internal/checksum"
+)
+
+// maxChecksumFileSize bounds the size of a downloaded checksum file
+const maxChecksumFileSize = 1 << 20
+
+// Fetch downloads a URL to a file. The file is written under a temporary name and
+// only renamed into place once the download completed. Downloads over HTTP are retried
+// and resumed after network errors, and fetched in parallel chunks when large, see
+// Configure.
+func Fetch(ctx context.Context, url, dest string) error {
+	mirrored, err := openMirrored(url)
+	if err != nil {
+		return err
+	}
+	if mirrored != nil {
+		defer mirrored.Close()
+		return writeFile(mirrored, url, dest)
+	}
+	return writeTemp(dest, func(file *os.File) error {
+		return fetchHTTP(ctx, url, file)
+	})
+}
+
+// writeFile writes the download of url read from body to dest through a temporary file
+func writeFile(body io.Reader, url, dest string) error {
+	return writeTemp(dest, func(file *os.File) error {
+		if _, err := io.Copy(file, body); err != nil {
+			return fmt.Errorf("failed to download %s: %w", url, err)
+		}
+		return nil
+	})
+}
+
+// writeTemp writes dest with write through a temporary file renamed into place once
+// write succeeded
+func writeTemp(dest string, write func(file *os.File) error) error {
+	dir := filepath.Dir(dest)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(dest)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if err := write(tmpFile); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), dest); err != nil {
+		return fmt.Errorf("failed to move download to %s: %w", dest, err)
+	}
+	return nil
+}
+
+// FetchVerified downloads a URL to a file and verifies it against the expected checksum,
+// or against the checksum for the file listed at checksumURL when expected is empty. A
+// file that fails verification is removed. Without any checksum the download is
+// not verified.
+func FetchVerified(ctx context.Context, url, dest, expected, checksumURL string) error {
+	if expected == "" && checksumURL != "" {
+		resolved, err := ResolveChecksum(ctx, checksumURL, url)
+		if err != nil {
+			return err
+		}
+		expected = resolved
+	}
+
+	if err := Fetch(ctx, url, dest); err != nil {
+		return err
+	}
+
+	if expected == "" {
+		return nil
+	}
+	if err := checksum.Verify(dest, expected); err != nil {
+		os.Remove(dest)
+		return err
+	}
+	return nil
+}
+
+// VerifySignature downloads the detached GPG signature at signatureURL and verifies the
+// file against it with the keys of the gpg default keyring
+func VerifySignature(ctx context.Context, path, signatureURL string) error {
+	signature := path + ".asc"
+	if err := Fetch(ctx, signatureURL, signature); err != nil {
+		return err
+	}
+	defer os.Remove(signature)
+
+	output, err := exec.CommandContext(ctx, "gpg", "--batch", "--no-tty", "--verify", signature, path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("signature of %s is not valid: %w: %s", filepath.Base(path), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Install moves a verified file to dest with the given permissions. The file is copied
+// next to dest first when both are on different file systems, so dest only ever holds
+// the complete file.
+func Install(src, dest string, mode os.FileMode) error {
+	if err := os.Chmod(src, mode); err != nil {
+		return fmt.Errorf("failed to set permissions of %s: %w", src, err)
+	}
+	dir := filepath.Dir(dest)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	if err := os.Rename(src, dest); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(dest)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := io.Copy(tmpFile, in); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	if err := os.Chmod(tmpFile.Name(), mode); err != nil {
+		return fmt.Errorf("failed to set permissions of %s: %w", dest, err)
+	}
+	if err := os.Rename(tmpFile.Name(), dest); err != nil {
+		return fmt.Errorf("failed to move download to %s: %w", dest, err)
+	}
+	return nil
+}
+
+// ResolveChecksum downloads a checksum file and returns the checksum of the file
+// named by url. Both a bare digest and the "<digest>  <file>" lines written by
+// sha256sum are accepted.
+func ResolveChecksum(ctx context.Context, checksumURL, url string) (string, error) {
+	body, err := get(ctx, checksumURL)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxChecksumFileSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", checksumURL, err)
+	}
+
+	checksum := parseChecksumFile(data, path.Base(url))
+	if checksum == "" {
+		return "", fmt.Errorf("checksum file %s lists no checksum for %s", checksumURL, path.Base(url))
+	}
+	return checksum, nil
+}
+
+// parseChecksumFile returns the digest listed for name, or the only digest of a
+// file holding a bare digest
+func parseChecksumFile(data []byte, name string) string {
+	var digests []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			digests = append(digests, fields[0])
+		default:
+			// The file name is prefixed with '*' in binary mode
+			if path.Base(strings.TrimPrefix(fields[1], "*")) == name {
+				return fields[0]
+			}
+		}
+	}
+
+	if len(digests) == 1 {
+		return digests[0]
+	}
+	return ""
+}
+
+// get returns the mirrored download of url, or issues a GET request, retried after
+// network errors and server errors, failing on responses other than 200 OK
+func get(ctx context.Context, url string) (io.ReadCloser, error) {
+	if mirrored, err := openMirrored(url); mirrored != nil || err != nil {
+		return mirrored, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid download URL %s: %w", url, err)
+	}
+
+	var resp *http.Response
+	if err := retry(ctx, func() error {
+		resp, err = send(ctx, req, false, 0, -1, "")
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,140 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const installer = "#!/bin/sh\necho installed\n"
+
+func installerDigest() string {
+	sum := sha256.Sum256([]byte(installer))
+	return hex.EncodeToString(sum[:])
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/install.sh", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(installer))
+	})
+	mux.HandleFunc("/install.sh.sha256", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(installerDigest() + "\n"))
+	})
+	mux.HandleFunc("/SHA256SUMS", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0000000000000000000000000000000000000000000000000000000000000000  other.sh\n" +
+			installerDigest() + " *install.sh\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchVerified(t *testing.T) {
+	server := newServer(t)
+	url := server.URL + "/install.sh"
+
+	tests := []struct {
+		name        string
+		checksum    string
+		checksumURL string
+	}{
+		{name: "pinned checksum", checksum: "sha256:" + installerDigest()},
+		{name: "bare digest file", checksumURL: server.URL + "/install.sh.sha256"},
+		{name: "sha256sum file", checksumURL: server.URL + "/SHA256SUMS"},
+		{name: "unverified"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "install.sh")
+			require.NoError(t, FetchVerified(context.Background(), url, dest, tt.checksum, tt.checksumURL))
+
+			data, err := os.ReadFile(dest)
+			require.NoError(t, err)
+			assert.Equal(t, installer, string(data))
+		})
+	}
+}
+
+func TestFetchVerified_Mismatch(t *testing.T) {
+	server := newServer(t)
+	dest := filepath.Join(t.TempDir(), "install.sh")
+
+	err := FetchVerified(context.Background(), server.URL+"/install.sh", dest,
+		"sha256:0000000000000000000000000000000000000000000000000000000000000000", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+	assert.NoFileExists(t, dest, "a file failing verification is removed")
+}
+
+func TestFetchVerified_Errors(t *testing.T) {
+	server := newServer(t)
+	dest := filepath.Join(t.TempDir(), "install.sh")
+
+	err := FetchVerified(context.Background(), server.URL+"/missing.sh", dest, "", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+
+	err = FetchVerified(context.Background(), server.URL+"/unknown.sh", dest, "", server.URL+"/SHA256SUMS")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lists no checksum for unknown.sh")
+	assert.NoFileExists(t, dest)
+}
+
+func TestParseChecksumFile(t *testing.T) {
+	digest := installerDigest()
+
+	assert.Equal(t, digest, parseChecksumFile([]byte(digest+"\n"), "install.sh"))
+	assert.Equal(t, digest, parseChecksumFile([]byte(digest+"  dist/install.sh\n"), "install.sh"))
+	assert.Empty(t, parseChecksumFile([]byte(digest+"  other.sh\n"), "install.sh"))
+	assert.Empty(t, parseChecksumFile([]byte(digest+"\n"+digest+"\n"), "install.sh"), "ambiguous bare digests")
+	assert.Empty(t, parseChecksumFile(nil, "install.sh"))
+}
+
+func TestInstall(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "install.sh")
+	require.NoError(t, os.WriteFile(src, []byte(installer), 0600))
+
+	dest := filepath.Join(t.TempDir(), "bin", "install.sh")
+	require.NoError(t, Install(src, dest, 0755))
+
+	info, err := os.Stat(dest)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	assert.NoFileExists(t, src)
+}
+
+func TestMirror(t *testing.T) {
+	server := newServer(t)
+	url := server.URL + "/install.sh"
+	checksumURL := server.URL + "/SHA256SUMS"
+	dir := t.TempDir()
+
+	for _, mirrored := range []string{url, checksumURL} {
+		path, err := Mirror(context.Background(), dir, mirrored)
+		require.NoError(t, err)
+		assert.Equal(t, MirrorPath(dir, mirrored), path)
+	}
+	server.Close()
+
+	SetMirror(dir, true)
+	t.Cleanup(func() { SetMirror("", false) })
+
+	dest := filepath.Join(t.TempDir(), "install.sh")
+	require.NoError(t, FetchVerified(context.Background(), url, dest, "", checksumURL), "served from the mirror")
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, installer, string(data))
+
+	err = Fetch(context.Background(), server.URL+"/other.sh", dest)
+	assert.ErrorIs(t, err, ErrOffline)
+}
@@ -0,0 +1,346 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitProtocols are the transports clones may use, for the repository and its
+// submodules. Transports such as ext:: run commands and are never allowed.
+const gitProtocols = "https:http:ssh:git:file"
+
+var (
+	// gitURLPattern matches the repository URLs accepted by Clone: URLs of the allowed
+	// transports, scp-like ssh addresses such as git@github.com:nginx/nginx.git, and
+	// absolute local paths
+	gitURLPattern = regexp.MustCompile(`^((https?|ssh|git|file)://[^\s]+|[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[^\s]+|/[^\s]*)$`)
+	// gitRefPattern matches the tags, branches and commits accepted by Clone
+	gitRefPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._/+-]*$`)
+	// gitCommitPattern matches commit hashes, abbreviated or not
+	gitCommitPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+)
+
+// gitNetworkErrors are git error messages meaning the repository could not be reached
+var gitNetworkErrors = []string{
+	"Could not resolve host",
+	"Could not resolve hostname",
+	"Failed to connect",
+	"Connection refused",
+	"Connection timed out",
+	"Network is unreachable",
+	"Could not read from remote repository",
+}
+
+// gitRefErrors are git error messages meaning the ref does not exist in the repository
+var gitRefErrors = []string{
+	"not found in upstream",
+	"did not match any",
+	"couldn't find remote ref",
+	"not our ref",
+	"unable to read tree",
+}
+
+// Checkout is a git repository cloned at a ref
+type Checkout struct {
+	Repository  string // URL of the repository
+	Path        string // Directory the repository is cloned to
+	Ref         string // Tag, branch or commit checked out, the default branch when empty
+	Submodules  bool   // Also clone the submodules, recursively
+	FullHistory bool   // Fetch the whole history instead of the checked out commit only
+}
+
+// Clone clones a git repository into the path of the checkout and checks out its ref.
+// Clones are shallow unless the full history is requested. With a mirror configured,
+// see SetMirror, repositories are cloned from a copy kept in the mirror directory,
+// refreshed from the network when not offline, so repositories cloned once can be
+// cloned again offline and exported with 'sai bundle export'.
+//
+// A path holding an earlier checkout of the repository is replaced, any other non-empty
+// path is refused.
+func Clone(ctx context.Context, checkout Checkout) error {
+	if err := validateCheckout(checkout); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is required to clone %s but was not found in PATH, install it with 'sai install git'", checkout.Repository)
+	}
+	if err := prepareCheckoutPath(ctx, checkout.Path, checkout.Repository); err != nil {
+		return err
+	}
+
+	source, err := gitSource(ctx, checkout.Repository)
+	if err != nil {
+		return err
+	}
+
+	if err := cloneRef(ctx, source, checkout); err != nil {
+		os.RemoveAll(checkout.Path)
+		return err
+	}
+	return nil
+}
+
+// validateCheckout refuses repositories and refs git could take for options or that
+// use a transport other than gitProtocols
+func validateCheckout(checkout Checkout) error {
+	if checkout.Repository == "" || checkout.Path == "" {
+		return fmt.Errorf("checkout %q to %q: repository and path cannot be empty", checkout.Repository, checkout.Path)
+	}
+	if !gitURLPattern.MatchString(checkout.Repository) {
+		return fmt.Errorf("invalid repository %q: use an https://, ssh://, git:// or file:// URL, a user@host:path address or an absolute path", checkout.Repository)
+	}
+	if checkout.Ref != "" && (!gitRefPattern.MatchString(checkout.Ref) || strings.Contains(checkout.Ref, "..") || strings.HasSuffix(checkout.Ref, ".lock")) {
+		return fmt.Errorf("invalid ref %q of %s: use a tag, branch or commit name", checkout.Ref, checkout.Repository)
+	}
+	return nil
+}
+
+// prepareCheckoutPath removes an earlier checkout of repository at path, left by a failed
+// run, and refuses to clone over anything else, including checkouts of other repositories
+func prepareCheckoutPath(ctx context.Context, path, repository string) error {
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) || (err == nil && len(entries) == 0) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot clone to %s: %w", path, err)
+	}
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		return fmt.Errorf("cannot clone to %s: the directory is not empty", path)
+	}
+	origin, err := checkoutOrigin(ctx, path)
+	if err != nil {
+		return fmt.Errorf("cannot clone to %s: %w", path, err)
+	}
+	if !sameOrigin(origin, repository) {
+		return fmt.Errorf("cannot clone %s to %s: the directory holds a checkout of %s", repository, path, origin)
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove the earlier checkout in %s: %w", path, err)
+	}
+	return nil
+}
+
+// checkoutOrigin returns the origin URL of the checkout at path. The configuration file
+// is read directly, so that nothing in the checkout can make git run commands.
+func checkoutOrigin(ctx context.Context, path string) (string, error) {
+	config := filepath.Join(path, ".git", "config")
+	out, err := runGit(ctx, path, "config", "--file", config, "--get", "remote.origin.url")
+	if err != nil {
+		return "", fmt.Errorf("the directory holds a checkout without origin: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// sameOrigin reports whether a checkout with origin was cloned from repository, directly
+// or through its copy in the mirror, see cloneRef
+func sameOrigin(origin, repository string) bool {
+	if origin == repository || origin == "file://"+repository {
+		return true
+	}
+	return mirror.dir != "" && origin == "file://"+GitMirrorPath(mirror.dir, repository)
+}
+
+// GitMirrorPath returns the bare repository holding the copy of repository in the
+// mirror dir
+func GitMirrorPath(dir, repository string) string {
+	return strings.TrimSuffix(MirrorPath(dir, repository), ".git") + ".git"
+}
+
+// gitSource returns the URL to clone repository from: the copy in the mirror, cloned
+// or refreshed first unless offline, or the repository itself without a mirror
+func gitSource(ctx context.Context, repository string) (string, error) {
+	if mirror.dir == "" {
+		if mirror.offline {
+			return "", fmt.Errorf("%w: %s is not mirrored", ErrOffline, repository)
+		}
+		if strings.HasPrefix(repository, "/") {
+			// git ignores --depth for local paths
+			return "file://" + repository, nil
+		}
+		return repository, nil
+	}
+
+	cache := GitMirrorPath(mirror.dir, repository)
+	if _, err := os.Stat(cache); err == nil {
+		// Bundles only hold files, git needs the refs directory even when it is empty
+		if err := os.MkdirAll(filepath.Join(cache, "refs"), 0755); err != nil {
+			return "", fmt.Errorf("failed to repair mirror of %s: %w", repository, err)
+		}
+		if !mirror.offline {
+			// A stale copy still serves the refs it holds, a missing ref fails the clone
+			if _, err := runGit(ctx, repository, "--git-dir", cache, "fetch", "--prune", "--tags", "origin"); err != nil && !errors.Is(err, errGitNetwork) {
+				return "", err
+			}
+		}
+		return "file://" + cache, nil
+	}
+	if mirror.offline {
+		return "", fmt.Errorf("%w: %s is not mirrored, clone it once online or import a bundle holding it with 'sai bundle import'", ErrOffline, repository)
+	}
+
+	if err := os.MkdirAll(mirror.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create mirror directory %s: %w", mirror.dir, err)
+	}
+	tmp, err := os.MkdirTemp(mirror.dir, "."+filepath.Base(cache)+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create mirror of %s: %w", repository, err)
+	}
+	defer os.RemoveAll(tmp)
+	if _, err := runGit(ctx, repository, "clone", "--quiet", "--mirror", "--", repository, tmp); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, cache); err != nil {
+		return "", fmt.Errorf("failed to move mirror of %s to %s: %w", repository, cache, err)
+	}
+	return "file://" + cache, nil
+}
+
+// cloneRef clones source into the checkout path and checks out the ref. Branches and
+// tags are cloned directly, commits are fetched by hash, falling back to the full
+// history for abbreviated hashes and servers refusing to serve commits by hash.
+func cloneRef(ctx context.Context, source string, checkout Checkout) error {
+	repository, path := checkout.Repository, checkout.Path
+	depth := []string{"--depth", "1"}
+	if checkout.FullHistory {
+		depth = nil
+	}
+
+	switch {
+	case checkout.Ref == "":
+		args := append([]string{"clone", "--quiet"}, depth...)
+		if _, err := runGit(ctx, repository, append(args, "--", source, path)...); err != nil {
+			return err
+		}
+	case gitCommitPattern.MatchString(checkout.Ref):
+		if err := fetchCommit(ctx, source, checkout, depth); err != nil {
+			return err
+		}
+	default:
+		args := append([]string{"clone", "--quiet", "--branch", checkout.Ref}, depth...)
+		if depth != nil {
+			args = append(args, "--single-branch")
+		}
+		if _, err := runGit(ctx, repository, append(args, "--", source, path)...); err != nil {
+			return refError(err, checkout)
+		}
+	}
+
+	// Submodules with relative URLs resolve against origin, the repository rather than
+	// its mirror
+	if source != repository {
+		if _, err := runGit(ctx, repository, "-C", path, "remote", "set-url", "origin", repository); err != nil {
+			return err
+		}
+	}
+	if checkout.Submodules {
+		args := []string{"-C", path, "submodule", "--quiet", "update", "--init", "--recursive"}
+		if depth != nil {
+			args = append(args, "--depth", "1")
+		}
+		if _, err := runGit(ctx, repository, args...); err != nil {
+			return fmt.Errorf("failed to clone the submodules of %s: %w", repository, err)
+		}
+	}
+	return nil
+}
+
+// fetchCommit checks out a commit of source in the checkout path
+func fetchCommit(ctx context.Context, source string, checkout Checkout, depth []string) error {
+	repository, path := checkout.Repository, checkout.Path
+	if _, err := runGit(ctx, repository, "init", "--quiet", "--", path); err != nil {
+		return err
+	}
+	if _, err := runGit(ctx, repository, "-C", path, "remote", "add", "origin", source); err != nil {
+		return err
+	}
+
+	fetched := false
+	if len(checkout.Ref) == 40 {
+		args := append([]string{"-C", path, "fetch", "--quiet"}, depth...)
+		_, err := runGit(ctx, repository, append(args, "origin", checkout.Ref)...)
+		if errors.Is(err, errGitNetwork) {
+			return err
+		}
+		fetched = err == nil
+	}
+	if !fetched {
+		if _, err := runGit(ctx, repository, "-C", path, "fetch", "--quiet", "--tags", "origin"); err != nil {
+			return err
+		}
+	}
+	if _, err := runGit(ctx, repository, "-C", path, "checkout", "--quiet", "--detach", checkout.Ref); err != nil {
+		return refError(err, checkout)
+	}
+	return nil
+}
+
+// errGitNetwork wraps the git errors meaning the repository could not be reached
+var errGitNetwork = errors.New("repository not reachable")
+
+// runGit runs git without prompting for credentials, restricted to the allowed
+// transports, and turns its error output into an error naming repository
+func runGit(ctx context.Context, repository string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ALLOW_PROTOCOL="+gitProtocols,
+		"GIT_ASKPASS=",
+		"SSH_ASKPASS=",
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("git %s of %s interrupted: %w", gitCommand(args), repository, ctx.Err())
+		}
+		for _, pattern := range gitNetworkErrors {
+			if strings.Contains(message, pattern) {
+				return "", fmt.Errorf("%w: cannot reach %s, check the network and the proxy settings, or clone it once online so it is mirrored: %s", errGitNetwork, repository, lastLine(message))
+			}
+		}
+		return "", fmt.Errorf("git %s of %s failed: %s", gitCommand(args), repository, lastLine(message))
+	}
+	return stdout.String(), nil
+}
+
+// gitCommand returns the git command run by args, skipping the global options
+func gitCommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-C", "--git-dir":
+			i++
+		default:
+			return args[i]
+		}
+	}
+	return ""
+}
+
+// refError explains a failed checkout of a ref missing from the repository
+func refError(err error, checkout Checkout) error {
+	for _, pattern := range gitRefErrors {
+		if strings.Contains(err.Error(), pattern) {
+			return fmt.Errorf("ref %s not found in %s", checkout.Ref, checkout.Repository)
+		}
+	}
+	return err
+}
+
+// lastLine returns the last line of a git error output, the one stating the error
+func lastLine(message string) string {
+	lines := strings.Split(message, "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,152 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGitRepository creates a repository with a v1.0 tag and a later commit on main,
+// and returns its path and the hash of the tagged commit
+func newGitRepository(t *testing.T) (string, string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=sai", "GIT_AUTHOR_EMAIL=sai@example.com",
+			"GIT_COMMITTER_NAME=sai", "GIT_COMMITTER_EMAIL=sai@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	git("init", "--quiet", "--initial-branch", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "VERSION"), []byte("1.0\n"), 0644))
+	git("add", "VERSION")
+	git("commit", "--quiet", "-m", "Release 1.0")
+	git("tag", "v1.0")
+	tagged := git("rev-parse", "HEAD")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "VERSION"), []byte("2.0-dev\n"), 0644))
+	git("commit", "--quiet", "-am", "Start 2.0")
+	return dir, tagged
+}
+
+func readVersion(t *testing.T, dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "VERSION"))
+	require.NoError(t, err)
+	return strings.TrimSpace(string(data))
+}
+
+func TestClone(t *testing.T) {
+	repository, tagged := newGitRepository(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected string
+	}{
+		{name: "default branch", expected: "2.0-dev"},
+		{name: "tag", ref: "v1.0", expected: "1.0"},
+		{name: "branch", ref: "main", expected: "2.0-dev"},
+		{name: "commit", ref: tagged, expected: "1.0"},
+		{name: "abbreviated commit", ref: tagged[:10], expected: "1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "src")
+			require.NoError(t, Clone(ctx, Checkout{Repository: repository, Path: path, Ref: tt.ref}))
+			assert.Equal(t, tt.expected, readVersion(t, path))
+		})
+	}
+
+	t.Run("missing ref", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "src")
+		err := Clone(ctx, Checkout{Repository: repository, Path: path, Ref: "v9.9"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ref v9.9 not found")
+		assert.NoDirExists(t, path)
+	})
+
+	t.Run("earlier checkout is replaced", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "src")
+		require.NoError(t, Clone(ctx, Checkout{Repository: repository, Path: path}))
+		require.NoError(t, Clone(ctx, Checkout{Repository: repository, Path: path, Ref: "v1.0"}))
+		assert.Equal(t, "1.0", readVersion(t, path))
+	})
+
+	t.Run("checkout of another repository is kept", func(t *testing.T) {
+		other, _ := newGitRepository(t)
+		path := filepath.Join(t.TempDir(), "src")
+		require.NoError(t, Clone(ctx, Checkout{Repository: other, Path: path}))
+		err := Clone(ctx, Checkout{Repository: repository, Path: path})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "holds a checkout of "+other)
+		assert.FileExists(t, filepath.Join(path, "VERSION"))
+	})
+
+	t.Run("other content is kept", func(t *testing.T) {
+		path := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(path, "notes.txt"), nil, 0644))
+		assert.Error(t, Clone(ctx, Checkout{Repository: repository, Path: path}))
+		assert.FileExists(t, filepath.Join(path, "notes.txt"))
+	})
+}
+
+func TestCloneValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		ref        string
+	}{
+		{name: "option as repository", repository: "--upload-pack=touch /tmp/pwned"},
+		{name: "ext transport", repository: "ext::sh -c touch% /tmp/pwned"},
+		{name: "relative path", repository: "../repository"},
+		{name: "option as ref", repository: "https://github.com/nginx/nginx.git", ref: "--output=/etc/passwd"},
+		{name: "ref range", repository: "https://github.com/nginx/nginx.git", ref: "v1..v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Clone(context.Background(), Checkout{Repository: tt.repository, Path: t.TempDir(), Ref: tt.ref})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid")
+		})
+	}
+}
+
+func TestCloneMirror(t *testing.T) {
+	repository, _ := newGitRepository(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	t.Cleanup(func() { SetMirror("", false) })
+
+	SetMirror(dir, true)
+	err := Clone(ctx, Checkout{Repository: repository, Path: filepath.Join(t.TempDir(), "src")})
+	assert.True(t, errors.Is(err, ErrOffline))
+
+	// Cloning online keeps a copy in the mirror, which serves later clones offline
+	SetMirror(dir, false)
+	require.NoError(t, Clone(ctx, Checkout{Repository: repository, Path: filepath.Join(t.TempDir(), "src")}))
+	assert.DirExists(t, GitMirrorPath(dir, repository))
+
+	SetMirror(dir, true)
+	path := filepath.Join(t.TempDir(), "src")
+	require.NoError(t, Clone(ctx, Checkout{Repository: repository, Path: path, Ref: "v1.0"}))
+	assert.Equal(t, "1.0", readVersion(t, path))
+
+	// The checkout refers to the repository, not to its mirror
+	out, err := exec.Command("git", "-C", path, "remote", "get-url", "origin").Output()
+	require.NoError(t, err)
+	assert.Equal(t, repository, strings.TrimSpace(string(out)))
+}
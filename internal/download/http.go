@@ -0,0 +1,341 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options control how downloads are fetched over HTTP, see Configure
+type Options struct {
+	Proxy          string        // Proxy URL, HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used when empty
+	Retries        int           // Attempts repeated after a network error or a server error
+	Backoff        time.Duration // Wait before the first retry, doubled after each attempt
+	Connections    int           // Parallel range requests of downloads of at least ChunkThreshold bytes
+	ChunkThreshold int64         // Size in bytes from which downloads are split across connections
+	Progress       io.Writer     // Where progress bars are drawn, nil disables them
+}
+
+// DefaultOptions returns the options used until Configure is called
+func DefaultOptions() Options {
+	return Options{
+		Retries:        3,
+		Backoff:        time.Second,
+		Connections:    4,
+		ChunkThreshold: 16 * 1024 * 1024,
+	}
+}
+
+// settings are the options of downloads and the client applying them, see Configure
+var settings = struct {
+	options Options
+	client  *http.Client
+}{
+	options: DefaultOptions(),
+	client:  &http.Client{Transport: newTransport(http.ProxyFromEnvironment)},
+}
+
+// Configure sets the proxy, retries, parallel connections and progress bars of the
+// downloads fetched over HTTP
+func Configure(options Options) error {
+	proxy := http.ProxyFromEnvironment
+	if options.Proxy != "" {
+		proxyURL, err := url.Parse(options.Proxy)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return fmt.Errorf("invalid download proxy %q", options.Proxy)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	if options.Connections < 1 {
+		options.Connections = 1
+	}
+	settings.options = options
+	settings.client = &http.Client{Transport: newTransport(proxy)}
+	return nil
+}
+
+// newTransport returns a transport with the defaults of net/http and the given proxy
+func newTransport(proxy func(*http.Request) (*url.URL, error)) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	return transport
+}
+
+// statusError is a response with an unexpected status
+type statusError struct {
+	url    string
+	status string
+	code   int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("failed to download %s: %s", e.url, e.status)
+}
+
+// permanentError is an error retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// temporary reports whether a failed attempt is worth retrying: network errors,
+// interrupted transfers and server errors are, client errors are not
+func temporary(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.code >= 500 || status.code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// retry runs attempt until it succeeds, fails for good or the retries are exhausted,
+// waiting settings.options.Backoff before the first retry and twice as long after each
+func retry(ctx context.Context, attempt func() error) error {
+	backoff := settings.options.Backoff
+	for i := 0; ; i++ {
+		err := attempt()
+		if err == nil || i >= settings.options.Retries || !temporary(ctx, err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send issues a request of the bytes of url from start to end, of the whole file when
+// ranged is false. validator, the ETag or Last-Modified of the first response, makes
+// the server answer with the whole file rather than a range of a file that changed.
+func send(ctx context.Context, req *http.Request, ranged bool, start, end int64, validator string) (*http.Response, error) {
+	req = req.Clone(ctx)
+	expected := http.StatusOK
+	if ranged {
+		byteRange := fmt.Sprintf("bytes=%d-", start)
+		if end >= 0 {
+			byteRange += strconv.FormatInt(end, 10)
+		}
+		req.Header.Set("Range", byteRange)
+		if validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+		expected = http.StatusPartialContent
+	}
+
+	resp, err := settings.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", req.URL, err)
+	}
+	if resp.StatusCode != expected && resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &statusError{url: req.URL.String(), status: resp.Status, code: resp.StatusCode}
+	}
+	if resp.StatusCode == http.StatusPartialContent && !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", start)) {
+		resp.Body.Close()
+		return nil, &permanentError{fmt.Errorf("failed to download %s: unexpected range %q", req.URL, resp.Header.Get("Content-Range"))}
+	}
+	return resp, nil
+}
+
+// contentLengthTimeout bounds the HEAD request of ContentLength
+const contentLengthTimeout = 5 * time.Second
+
+// ContentLength asks the server for the size of the download of url with a HEAD request
+// through the configured proxy. It returns 0 when the size is unknown, and when url is
+// served from the mirror or offline mode forbids contacting the server.
+func ContentLength(ctx context.Context, url string) int64 {
+	if mirror.offline {
+		return 0
+	}
+	if mirror.dir != "" {
+		if _, err := os.Stat(MirrorPath(mirror.dir, url)); err == nil {
+			return 0
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contentLengthTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0
+	}
+	resp, err := settings.client.Do(req)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.ContentLength < 0 {
+		return 0
+	}
+	return resp.ContentLength
+}
+
+// httpDownload is a file being fetched over HTTP, whole or in chunks
+type httpDownload struct {
+	req       *http.Request
+	file      *os.File
+	size      int64  // Size announced by the server, -1 when unknown
+	validator string // ETag or Last-Modified of the first response
+	progress  *progressBar
+}
+
+// fetchHTTP downloads url into file, resuming interrupted transfers while the retries
+// last, and splitting large downloads across parallel range requests when the server
+// supports them
+func fetchHTTP(ctx context.Context, url string, file *os.File) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid download URL %s: %w", url, err)
+	}
+
+	var first *http.Response
+	if err := retry(ctx, func() error {
+		first, err = send(ctx, req, false, 0, -1, "")
+		return err
+	}); err != nil {
+		return err
+	}
+
+	d := &httpDownload{
+		req:       req,
+		file:      file,
+		size:      first.ContentLength,
+		validator: first.Header.Get("ETag"),
+		progress:  newProgressBar(settings.options.Progress, path.Base(req.URL.Path), first.ContentLength),
+	}
+	if d.validator == "" || strings.HasPrefix(d.validator, "W/") {
+		d.validator = first.Header.Get("Last-Modified")
+	}
+	defer d.progress.finish()
+
+	connections := int64(settings.options.Connections)
+	if connections > 1 && d.size > 0 && d.size >= settings.options.ChunkThreshold &&
+		first.Header.Get("Accept-Ranges") == "bytes" {
+		return d.fetchChunks(ctx, first, connections)
+	}
+	return d.fetchRange(ctx, first, 0, d.size-1)
+}
+
+// fetchChunks preallocates the file and downloads it in chunks fetched in parallel,
+// the first one from the response already received
+func (d *httpDownload) fetchChunks(ctx context.Context, first *http.Response, connections int64) error {
+	if err := d.file.Truncate(d.size); err != nil {
+		first.Body.Close()
+		return fmt.Errorf("failed to allocate %s: %w", d.file.Name(), err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunk := (d.size + connections - 1) / connections
+	errs := make([]error, connections)
+	var wg sync.WaitGroup
+	for i := int64(0); i < connections; i++ {
+		start, end := i*chunk, min((i+1)*chunk, d.size)-1
+		if start > end {
+			break
+		}
+		resp := first
+		if i > 0 {
+			resp = nil
+		}
+		wg.Add(1)
+		go func(i int64) {
+			defer wg.Done()
+			if errs[i] = d.fetchRange(ctx, resp, start, end); errs[i] != nil {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// The chunks canceled by the first failure report the cancellation, not the cause
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fetchRange writes the bytes from start to end of the file, to its end when end is
+// negative, read from resp or from a new request when resp is nil. An interrupted
+// transfer is resumed from the last byte received. When the server ignores the range,
+// a whole file restarts from its first byte and a chunk fails.
+func (d *httpDownload) fetchRange(ctx context.Context, resp *http.Response, start, end int64) error {
+	offset := start
+	whole := start == 0 && end == d.size-1
+	return retry(ctx, func() error {
+		if resp == nil {
+			var err error
+			resp, err = send(ctx, d.req, !whole || offset > 0, offset, end, d.validator)
+			if err != nil {
+				return err
+			}
+			if resp.StatusCode == http.StatusOK && offset > 0 {
+				if !whole {
+					resp.Body.Close()
+					return &permanentError{fmt.Errorf("failed to download %s: the server ignored a range request", d.req.URL)}
+				}
+				d.progress.add(-offset)
+				offset = 0
+				if err := d.file.Truncate(0); err != nil {
+					resp.Body.Close()
+					return &permanentError{fmt.Errorf("failed to write %s: %w", d.file.Name(), err)}
+				}
+			}
+		}
+		defer func() {
+			resp.Body.Close()
+			resp = nil
+		}()
+
+		body := io.Reader(resp.Body)
+		if end >= 0 {
+			body = io.LimitReader(body, end+1-offset)
+		}
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := body.Read(buf)
+			if n > 0 {
+				if _, err := d.file.WriteAt(buf[:n], offset); err != nil {
+					return &permanentError{fmt.Errorf("failed to write %s: %w", d.file.Name(), err)}
+				}
+				offset += int64(n)
+				d.progress.add(int64(n))
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to download %s: %w", d.req.URL, err)
+			}
+		}
+		if end >= 0 && offset <= end {
+			return fmt.Errorf("failed to download %s: %w", d.req.URL, io.ErrUnexpectedEOF)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,193 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// payload is a download large enough to be split in chunks
+var payload = bytes.Repeat([]byte("0123456789"), 100)
+
+// configure applies download options for the duration of a test
+func configure(t *testing.T, options Options) {
+	require.NoError(t, Configure(options))
+	t.Cleanup(func() { require.NoError(t, Configure(DefaultOptions())) })
+}
+
+// requestLog records the Range headers of the requests a test server received
+type requestLog struct {
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (l *requestLog) add(r *http.Request) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ranges = append(l.ranges, r.Header.Get("Range"))
+	return len(l.ranges)
+}
+
+// fetched downloads url and returns the content of the file
+func fetched(t *testing.T, url string) []byte {
+	dest := filepath.Join(t.TempDir(), "tool")
+	require.NoError(t, Fetch(context.Background(), url, dest))
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	return data
+}
+
+func TestFetch_Retries(t *testing.T) {
+	configure(t, Options{Retries: 2, Backoff: time.Millisecond, Connections: 1})
+
+	var log requestLog
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			log.add(r)
+			http.NotFound(w, r)
+			return
+		}
+		if log.add(r) < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	assert.Equal(t, payload, fetched(t, server.URL+"/tool"))
+	assert.Len(t, log.ranges, 3)
+
+	err := Fetch(context.Background(), server.URL+"/missing", filepath.Join(t.TempDir(), "tool"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+	assert.Len(t, log.ranges, 4, "client errors are not retried")
+
+	configure(t, Options{Retries: 1, Backoff: time.Millisecond, Connections: 1})
+	log.ranges = nil
+	err = Fetch(context.Background(), server.URL+"/tool", filepath.Join(t.TempDir(), "tool"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestFetch_Resume(t *testing.T) {
+	configure(t, Options{Retries: 1, Backoff: time.Millisecond, Connections: 1})
+
+	tests := []struct {
+		name        string
+		ignoreRange bool
+	}{
+		{name: "range honored"},
+		{name: "range ignored", ignoreRange: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log requestLog
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if log.add(r) == 1 {
+					// Drop the connection halfway through the first transfer
+					w.Header().Set("Content-Length", "1000")
+					w.Header().Set("ETag", `"v1"`)
+					w.Write(payload[:400])
+					w.(http.Flusher).Flush()
+					panic(http.ErrAbortHandler)
+				}
+				if tt.ignoreRange {
+					w.Write(payload)
+					return
+				}
+				w.Header().Set("ETag", `"v1"`)
+				http.ServeContent(w, r, "tool", time.Time{}, bytes.NewReader(payload))
+			}))
+			defer server.Close()
+
+			assert.Equal(t, payload, fetched(t, server.URL+"/tool"))
+			assert.Equal(t, []string{"", "bytes=400-999"}, log.ranges)
+		})
+	}
+}
+
+func TestFetch_Chunks(t *testing.T) {
+	var log requestLog
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.add(r)
+		http.ServeContent(w, r, "tool", time.Time{}, bytes.NewReader(payload))
+	}))
+	defer server.Close()
+
+	configure(t, Options{Connections: 4, ChunkThreshold: 100})
+	assert.Equal(t, payload, fetched(t, server.URL+"/tool"))
+	assert.ElementsMatch(t, []string{"", "bytes=250-499", "bytes=500-749", "bytes=750-999"}, log.ranges,
+		"the first chunk is read from the first response")
+
+	log.ranges = nil
+	configure(t, Options{Connections: 4, ChunkThreshold: 2000})
+	assert.Equal(t, payload, fetched(t, server.URL+"/tool"))
+	assert.Equal(t, []string{""}, log.ranges, "small downloads are not split")
+}
+
+func TestFetch_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write(payload)
+	}))
+	defer proxy.Close()
+
+	configure(t, Options{Proxy: proxy.URL, Connections: 1})
+	assert.Equal(t, payload, fetched(t, "http://downloads.example.com/tool"))
+	assert.Equal(t, "http://downloads.example.com/tool", proxied)
+
+	assert.Error(t, Configure(Options{Proxy: "proxy.example.com:3128"}))
+}
+
+func TestContentLength(t *testing.T) {
+	var methods []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Header().Set("Content-Length", "1000")
+	}))
+	defer proxy.Close()
+
+	configure(t, Options{Proxy: proxy.URL})
+	url := "http://downloads.example.com/tool"
+	assert.Equal(t, int64(1000), ContentLength(context.Background(), url), "asked through the proxy")
+	assert.Equal(t, []string{http.MethodHead}, methods)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(MirrorPath(dir, url), payload, 0644))
+	SetMirror(dir, false)
+	t.Cleanup(func() { SetMirror("", false) })
+	assert.Zero(t, ContentLength(context.Background(), url), "served from the mirror")
+
+	SetMirror("", true)
+	assert.Zero(t, ContentLength(context.Background(), url), "offline")
+	assert.Len(t, methods, 1)
+}
+
+func TestProgressBar(t *testing.T) {
+	var out strings.Builder
+	bar := newProgressBar(&out, "tool", 2048)
+	bar.add(512)
+	assert.Empty(t, out.String(), "short downloads show no bar")
+
+	bar.started = time.Now().Add(-time.Second)
+	bar.add(512)
+	bar.finish()
+	assert.Contains(t, out.String(), "tool [===============               ]  50% 1.0 KiB of 2.0 KiB\n")
+
+	var disabled *progressBar
+	disabled.add(1)
+	disabled.finish()
+}
@@ -0,0 +1,65 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ErrOffline is returned when a download is not mirrored and offline mode forbids
+// fetching it from the network
+var ErrOffline = errors.New("download refused in offline mode")
+
+// mirror is the directory downloads are served from before the network, see SetMirror
+var mirror struct {
+	dir     string
+	offline bool
+}
+
+// SetMirror serves the downloads mirrored in dir, imported from a bundle or stored by
+// Mirror, instead of fetching them. In offline mode downloads missing from dir fail
+// with ErrOffline. An empty dir disables the mirror.
+func SetMirror(dir string, offline bool) {
+	mirror.dir = dir
+	mirror.offline = offline
+}
+
+// MirrorPath returns the file holding the download of url in the mirror dir. The name
+// is prefixed with a digest of the URL so files with the same name never collide.
+func MirrorPath(dir, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+"-"+path.Base(url))
+}
+
+// Mirror downloads url into the mirror dir, where SetMirror serves it from
+func Mirror(ctx context.Context, dir, url string) (string, error) {
+	dest := MirrorPath(dir, url)
+	if err := Fetch(ctx, url, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// openMirrored opens the mirrored download of url. It returns nil when url is not
+// mirrored and may be fetched, and ErrOffline when it may not.
+func openMirrored(url string) (io.ReadCloser, error) {
+	if mirror.dir != "" {
+		file, err := os.Open(MirrorPath(mirror.dir, url))
+		if err == nil {
+			return file, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to open mirrored download of %s: %w", url, err)
+		}
+	}
+	if mirror.offline {
+		return nil, fmt.Errorf("%w: %s is not mirrored, import a bundle holding it with 'sai bundle import'", ErrOffline, url)
+	}
+	return nil, nil
+}
@@ -0,0 +1,99 @@
+package download
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// progressInterval is the minimum time between two redraws of a progress bar, and how
+// long a download runs before its bar is drawn, so small files show none
+const progressInterval = 500 * time.Millisecond
+
+// progressWidth is the number of characters of a progress bar
+const progressWidth = 30
+
+// progressBar draws the progress of a download on a terminal. The chunks of a download
+// fetched in parallel report to the same bar. A nil bar draws nothing.
+type progressBar struct {
+	mu       sync.Mutex
+	out      io.Writer
+	name     string
+	total    int64 // Expected size, -1 when the server did not announce it
+	received int64
+	started  time.Time
+	drawn    time.Time
+}
+
+// newProgressBar returns the progress bar of the download of name, or nil when
+// progress bars are disabled
+func newProgressBar(out io.Writer, name string, total int64) *progressBar {
+	if out == nil {
+		return nil
+	}
+	return &progressBar{out: out, name: name, total: total, started: time.Now()}
+}
+
+// add counts received bytes, negative when a download restarts, and redraws the bar
+// at most every progressInterval
+func (b *progressBar) add(n int64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.received += n
+	if time.Since(b.started) >= progressInterval && time.Since(b.drawn) >= progressInterval {
+		b.draw()
+	}
+}
+
+// finish draws the final state of a bar that was shown
+func (b *progressBar) finish() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.drawn.IsZero() {
+		b.draw()
+		fmt.Fprintln(b.out)
+	}
+}
+
+// draw overwrites the progress line with the received size
+func (b *progressBar) draw() {
+	b.drawn = time.Now()
+	if b.total <= 0 {
+		fmt.Fprintf(b.out, "\r📥 %s %s", b.name, formatSize(b.received))
+		return
+	}
+	filled := int(b.received * progressWidth / b.total)
+	if filled > progressWidth {
+		filled = progressWidth
+	}
+	bar := make([]byte, progressWidth)
+	for i := range bar {
+		bar[i] = ' '
+		if i < filled {
+			bar[i] = '='
+		}
+	}
+	fmt.Fprintf(b.out, "\r📥 %s [%s] %3d%% %s of %s", b.name, bar, b.received*100/b.total,
+		formatSize(b.received), formatSize(b.total))
+}
+
+// formatSize formats a size in bytes with a binary unit
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
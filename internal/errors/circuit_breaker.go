@@ -35,15 +35,24 @@ func (s CircuitBreakerState) String() string {
 // CircuitBreakerConfig defines circuit breaker configuration
 type CircuitBreakerConfig struct {
 	// FailureThreshold is the number of failures that will open the circuit
-	FailureThreshold int
+	FailureThreshold int `yaml:"failure_threshold"`
 	// RecoveryTimeout is how long to wait before attempting recovery
-	RecoveryTimeout time.Duration
+	RecoveryTimeout time.Duration `yaml:"recovery_timeout"`
 	// SuccessThreshold is the number of successes needed to close the circuit from half-open
-	SuccessThreshold int
+	SuccessThreshold int `yaml:"success_threshold"`
 	// TimeWindow is the time window for counting failures
-	TimeWindow time.Duration
+	TimeWindow time.Duration `yaml:"time_window"`
+	// OpenProviders sets how providers with an open circuit breaker are selected
+	OpenProviders string `yaml:"open_providers"`
 }
 
+// Selection policies of providers with an open circuit breaker
+const (
+	OpenProvidersDemote  = "demote"  // Select them only when no other provider is available
+	OpenProvidersExclude = "exclude" // Never select them until the breaker recovers
+	OpenProvidersIgnore  = "ignore"  // Select and run them as if the breaker was closed
+)
+
 // DefaultCircuitBreakerConfig returns default circuit breaker configuration
 func DefaultCircuitBreakerConfig() *CircuitBreakerConfig {
 	return &CircuitBreakerConfig{
@@ -51,6 +60,7 @@ func DefaultCircuitBreakerConfig() *CircuitBreakerConfig {
 		RecoveryTimeout:  30 * time.Second,
 		SuccessThreshold: 2,
 		TimeWindow:       1 * time.Minute,
+		OpenProviders:    OpenProvidersDemote,
 	}
 }
 
@@ -207,6 +217,14 @@ func (cb *CircuitBreaker) clearOldFailures() {
 	}
 }
 
+// IsOpen reports whether the circuit breaker rejects requests, without moving an open
+// breaker whose recovery timeout passed to half-open
+func (cb *CircuitBreaker) IsOpen() bool {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+	return cb.state == CircuitBreakerOpen && time.Since(cb.lastFailTime) < cb.config.RecoveryTimeout
+}
+
 // GetState returns the current state of the circuit breaker
 func (cb *CircuitBreaker) GetState() CircuitBreakerState {
 	cb.mutex.RLock()
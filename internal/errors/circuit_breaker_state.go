@@ -0,0 +1,90 @@
+package errors
+
+import (
+	stderrors "errors"
+	"os"
+	"time"
+
+	"sai/internal/fileutil"
+)
+
+// circuitBreakerSnapshot is the persisted state of a circuit breaker
+type circuitBreakerSnapshot struct {
+	State        string      `json:"state"`
+	Successes    int         `json:"successes,omitempty"`
+	Failures     []time.Time `json:"failures,omitempty"`
+	LastFailTime time.Time   `json:"last_failure"`
+	LastSuccTime time.Time   `json:"last_success"`
+}
+
+// LoadState restores the circuit breakers saved by SaveState, so the failures of
+// earlier runs keep counting. A missing state file leaves every breaker closed.
+func (cbm *CircuitBreakerManager) LoadState(path string) error {
+	var snapshots map[string]*circuitBreakerSnapshot
+	if err := fileutil.ReadJSON(path, &snapshots); err != nil {
+		if stderrors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	for name, snapshot := range snapshots {
+		if snapshot != nil {
+			cbm.GetCircuitBreaker(name).restore(snapshot)
+		}
+	}
+	return nil
+}
+
+// SaveState persists the circuit breakers that are not closed or have recent failures
+func (cbm *CircuitBreakerManager) SaveState(path string) error {
+	cbm.mutex.RLock()
+	snapshots := make(map[string]*circuitBreakerSnapshot)
+	for name, breaker := range cbm.breakers {
+		if snapshot := breaker.snapshot(); snapshot != nil {
+			snapshots[name] = snapshot
+		}
+	}
+	cbm.mutex.RUnlock()
+
+	return fileutil.WriteJSONAtomic(path, snapshots, 0644)
+}
+
+// snapshot returns the state of the breaker to persist, nil for a closed breaker
+// without recent failures
+func (cb *CircuitBreaker) snapshot() *circuitBreakerSnapshot {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.clearOldFailures()
+	if cb.state == CircuitBreakerClosed && len(cb.failureHistory) == 0 {
+		return nil
+	}
+	return &circuitBreakerSnapshot{
+		State:        cb.state.String(),
+		Successes:    cb.successes,
+		Failures:     append([]time.Time(nil), cb.failureHistory...),
+		LastFailTime: cb.lastFailTime,
+		LastSuccTime: cb.lastSuccTime,
+	}
+}
+
+// restore sets the breaker to a persisted state
+func (cb *CircuitBreaker) restore(snapshot *circuitBreakerSnapshot) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	switch snapshot.State {
+	case CircuitBreakerOpen.String():
+		cb.state = CircuitBreakerOpen
+	case CircuitBreakerHalfOpen.String():
+		cb.state = CircuitBreakerHalfOpen
+	default:
+		cb.state = CircuitBreakerClosed
+	}
+	cb.successes = snapshot.Successes
+	cb.failureHistory = append(cb.failureHistory[:0], snapshot.Failures...)
+	cb.lastFailTime = snapshot.LastFailTime
+	cb.lastSuccTime = snapshot.LastSuccTime
+	cb.clearOldFailures()
+}
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -414,4 +415,52 @@ func TestCircuitBreakerConstants(t *testing.T) {
 	assert.Equal(t, "provider", CircuitBreakerProvider)
 	assert.Equal(t, "network", CircuitBreakerNetwork)
 	assert.Equal(t, "command", CircuitBreakerCommand)
-}
\ No newline at end of file
+}
+
+func TestCircuitBreakerIsOpen(t *testing.T) {
+	cb := NewCircuitBreaker("test", &CircuitBreakerConfig{FailureThreshold: 1, RecoveryTimeout: time.Hour, SuccessThreshold: 1, TimeWindow: time.Hour})
+	assert.False(t, cb.IsOpen())
+	cb.RecordFailure()
+	assert.True(t, cb.IsOpen())
+
+	// An open breaker whose recovery timeout passed no longer rejects the provider
+	cb = NewCircuitBreaker("test", &CircuitBreakerConfig{FailureThreshold: 1, RecoveryTimeout: time.Millisecond, SuccessThreshold: 1, TimeWindow: time.Hour})
+	cb.RecordFailure()
+	time.Sleep(5 * time.Millisecond)
+	assert.False(t, cb.IsOpen())
+	assert.Equal(t, CircuitBreakerOpen, cb.GetState())
+}
+
+func TestCircuitBreakerManagerState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "circuit_breakers.json")
+	config := &CircuitBreakerConfig{
+		FailureThreshold: 2,
+		RecoveryTimeout:  time.Hour,
+		SuccessThreshold: 1,
+		TimeWindow:       time.Hour,
+		OpenProviders:    OpenProvidersDemote,
+	}
+
+	t.Run("Missing state file", func(t *testing.T) {
+		cbm := NewCircuitBreakerManager(config)
+		assert.NoError(t, cbm.LoadState(path))
+		assert.Empty(t, cbm.GetAllStats())
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		cbm := NewCircuitBreakerManager(config)
+		failing := cbm.GetCircuitBreaker("apt_install")
+		failing.RecordFailure()
+		failing.RecordFailure()
+		assert.True(t, failing.IsOpen())
+		cbm.GetCircuitBreaker("brew_install").RecordSuccess()
+		assert.NoError(t, cbm.SaveState(path))
+
+		restored := NewCircuitBreakerManager(config)
+		assert.NoError(t, restored.LoadState(path))
+		stats := restored.GetAllStats()
+		assert.Len(t, stats, 1)
+		assert.True(t, restored.GetCircuitBreaker("apt_install").IsOpen())
+		assert.Equal(t, 2, stats["apt_install"].RecentFailures)
+	})
+}
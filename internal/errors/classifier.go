@@ -0,0 +1,190 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// errorPattern recognizes a failure in the output of a package manager command. The
+// first submatch of Pattern, when present, names the package the failure is about.
+type errorPattern struct {
+	Providers   []string // Providers printing this error, empty for any provider
+	Pattern     *regexp.Regexp
+	Type        ErrorType
+	Message     string // Error message, %s is replaced by the package name
+	Suggestions []string
+}
+
+// Suggestions shared by several patterns
+var (
+	lockSuggestions = []string{
+		"Wait for the other package manager process (such as automatic updates) to finish, sai retries automatically",
+		"Check which process holds the lock with 'ps aux'",
+	}
+	rootSuggestions = []string{
+		"Run sai with sudo or as root",
+	}
+)
+
+// errorPatterns are checked in order, permission problems come before lock problems
+// because package managers report a lock file they cannot open as a lock failure
+var errorPatterns = []errorPattern{
+	// apt
+	{
+		Providers: []string{"apt"},
+		Pattern:   regexp.MustCompile(`(?i)are you root\?|Could not open lock file .*Permission denied`),
+		Type:      ErrorTypeCommandPermission, Message: "apt requires root privileges",
+		Suggestions: rootSuggestions,
+	},
+	{
+		Providers: []string{"apt"},
+		Pattern:   regexp.MustCompile(`(?i)Could not get lock|Unable to acquire the dpkg frontend lock|dpkg was interrupted`),
+		Type:      ErrorTypePackageManagerLocked, Message: "the apt package database is locked by another process",
+		Suggestions: append(lockSuggestions, "If no other process is running, repair dpkg with 'sudo dpkg --configure -a'"),
+	},
+	{
+		Providers: []string{"apt"},
+		Pattern:   regexp.MustCompile(`(?i)Unable to locate package (\S+)|Package '?([^'\s]+)'? has no installation candidate`),
+		Type:      ErrorTypePackageNotFound, Message: "package %s not found in the apt package index",
+		Suggestions: []string{
+			"Refresh the package index with 'sudo apt update'",
+			"Enable the repository providing the package",
+		},
+	},
+
+	// dnf and yum
+	{
+		Providers: []string{"dnf", "yum"},
+		Pattern:   regexp.MustCompile(`(?i)has to be run with superuser privileges|You need to be root`),
+		Type:      ErrorTypeCommandPermission, Message: "the package manager requires root privileges",
+		Suggestions: rootSuggestions,
+	},
+	{
+		Providers: []string{"dnf", "yum"},
+		Pattern:   regexp.MustCompile(`(?i)Waiting for process with pid \d+ to finish|is currently holding the yum lock|Existing lock /var/run/yum\.pid`),
+		Type:      ErrorTypePackageManagerLocked, Message: "the rpm database is locked by another process",
+		Suggestions: lockSuggestions,
+	},
+	{
+		Providers: []string{"dnf", "yum"},
+		Pattern:   regexp.MustCompile(`(?i)No match for argument:?\s*(\S+)|No package (\S+) available`),
+		Type:      ErrorTypePackageNotFound, Message: "package %s not found in the enabled repositories",
+		Suggestions: []string{
+			"Refresh the metadata with 'sudo dnf makecache'",
+			"Enable the repository providing the package, such as EPEL",
+		},
+	},
+
+	// brew
+	{
+		Providers: []string{"brew"},
+		Pattern:   regexp.MustCompile(`(?i)Another active Homebrew \w+ process is already in progress|has already locked`),
+		Type:      ErrorTypePackageManagerLocked, Message: "another Homebrew process is running",
+		Suggestions: lockSuggestions,
+	},
+	{
+		Providers: []string{"brew"},
+		Pattern:   regexp.MustCompile(`(?i)No available (?:formula|cask) with the name "([^"]+)"|No formulae or casks found for "?([^"\s.]+)`),
+		Type:      ErrorTypePackageNotFound, Message: "formula %s not found",
+		Suggestions: []string{
+			"Update Homebrew with 'brew update'",
+			"Tap the repository providing the formula with 'brew tap'",
+		},
+	},
+
+	// pacman, zypper and apk
+	{
+		Providers: []string{"pacman", "zypper", "apk"},
+		Pattern:   regexp.MustCompile(`(?i)unable to lock database|System management is locked`),
+		Type:      ErrorTypePackageManagerLocked, Message: "the package database is locked by another process",
+		Suggestions: lockSuggestions,
+	},
+	{
+		Providers: []string{"pacman", "zypper", "apk"},
+		Pattern:   regexp.MustCompile(`(?i)target not found: (\S+)|No provider of '([^']+)' found|unable to select packages:\s*(\S+)`),
+		Type:      ErrorTypePackageNotFound, Message: "package %s not found",
+		Suggestions: []string{
+			"Refresh the package database",
+		},
+	},
+
+	// Any provider
+	{
+		Pattern: regexp.MustCompile(`(?i)No space left on device`),
+		Type:    ErrorTypeDiskFull, Message: "no space left on device",
+		Suggestions: []string{
+			"Free disk space, 'sai clean' removes temporary files and the sai cache",
+			"Check free space with 'df -h'",
+		},
+	},
+	{
+		Pattern: regexp.MustCompile(`(?i)403 Forbidden|status(?: code)?:? 403|HTTP/[\d.]+ 403|npm ERR! code E403|denied: requested access to the resource is denied`),
+		Type:    ErrorTypeNetworkPermission, Message: "the registry denied access (HTTP 403)",
+		Suggestions: []string{
+			"Check the registry credentials, for example with 'docker login' or 'npm login'",
+			"Check whether the package or repository requires a subscription or has moved",
+		},
+	},
+	{
+		Pattern: regexp.MustCompile(`(?i)Temporary failure resolving|Could not resolve host|Name or service not known|Network is unreachable|Failed to download metadata`),
+		Type:    ErrorTypeNetworkUnavailable, Message: "the package repository is unreachable",
+		Suggestions: []string{
+			"Check network connectivity and DNS resolution",
+			"Configure the proxy of the package manager if a proxy is required",
+		},
+	},
+}
+
+// ClassifyCommandError recognizes common package manager failures in the output of a
+// failed command and returns a typed error with targeted suggestions, or nil when the
+// output matches no known failure
+func ClassifyCommandError(provider, command string, exitCode int, output string) *SAIError {
+	for _, pattern := range errorPatterns {
+		if len(pattern.Providers) > 0 && !containsString(pattern.Providers, provider) {
+			continue
+		}
+
+		match := pattern.Pattern.FindStringSubmatch(output)
+		if match == nil {
+			continue
+		}
+
+		message := pattern.Message
+		if strings.Contains(message, "%s") {
+			message = fmt.Sprintf(message, firstSubmatch(match))
+		}
+
+		err := NewSAIError(pattern.Type, message).
+			WithContext("provider", provider).
+			WithContext("command", command).
+			WithContext("exit_code", exitCode).
+			WithContext("matched_output", strings.TrimSpace(match[0]))
+		for _, suggestion := range pattern.Suggestions {
+			err.WithSuggestion(suggestion)
+		}
+		return err
+	}
+	return nil
+}
+
+// firstSubmatch returns the first non-empty submatch, or a placeholder when the
+// pattern did not capture the package name
+func firstSubmatch(match []string) string {
+	for _, submatch := range match[1:] {
+		if submatch != "" {
+			return strings.Trim(submatch, `"'.`)
+		}
+	}
+	return "(unknown)"
+}
+
+// containsString checks if a slice contains a string
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
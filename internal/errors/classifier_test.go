@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyCommandError(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		output   string
+		expected ErrorType
+		message  string
+	}{
+		{
+			name:     "apt package not found",
+			provider: "apt",
+			output:   "Reading package lists...\nE: Unable to locate package nginxx\n",
+			expected: ErrorTypePackageNotFound,
+			message:  "package nginxx not found in the apt package index",
+		},
+		{
+			name:     "apt lock held",
+			provider: "apt",
+			output:   "E: Could not get lock /var/lib/dpkg/lock-frontend. It is held by process 1234 (unattended-upgr)\n",
+			expected: ErrorTypePackageManagerLocked,
+		},
+		{
+			name:     "apt lock without root",
+			provider: "apt",
+			output: "E: Could not open lock file /var/lib/dpkg/lock-frontend - open (13: Permission denied)\n" +
+				"E: Unable to acquire the dpkg frontend lock (/var/lib/dpkg/lock-frontend), are you root?\n",
+			expected: ErrorTypeCommandPermission,
+		},
+		{
+			name:     "dnf package not found",
+			provider: "dnf",
+			output:   "No match for argument: nginxx\nError: Unable to find a match: nginxx\n",
+			expected: ErrorTypePackageNotFound,
+			message:  "package nginxx not found in the enabled repositories",
+		},
+		{
+			name:     "brew formula not found",
+			provider: "brew",
+			output:   `Error: No available formula with the name "nginxx". Did you mean nginx?`,
+			expected: ErrorTypePackageNotFound,
+			message:  "formula nginxx not found",
+		},
+		{
+			name:     "disk full for any provider",
+			provider: "npm",
+			output:   "npm ERR! nospc ENOSPC: No space left on device, write\n",
+			expected: ErrorTypeDiskFull,
+		},
+		{
+			name:     "registry forbidden",
+			provider: "docker",
+			output:   "Error response from daemon: pull access denied for private/app, repository does not exist or may require 'docker login': denied: requested access to the resource is denied\n",
+			expected: ErrorTypeNetworkPermission,
+		},
+		{
+			name:     "repository unreachable",
+			provider: "apt",
+			output:   "W: Failed to fetch http://deb.debian.org/debian/dists/bookworm/InRelease  Temporary failure resolving 'deb.debian.org'\n",
+			expected: ErrorTypeNetworkUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ClassifyCommandError(tt.provider, "install", 100, tt.output)
+			require.NotNil(t, err)
+			assert.Equal(t, tt.expected, err.Type)
+			assert.NotEmpty(t, err.Suggestions)
+			assert.Equal(t, tt.provider, err.Context["provider"])
+			if tt.message != "" {
+				assert.Equal(t, tt.message, err.Message)
+			}
+		})
+	}
+}
+
+func TestClassifyCommandError_ProviderSpecific(t *testing.T) {
+	// apt messages are not recognized in the output of other providers
+	assert.Nil(t, ClassifyCommandError("brew", "install", 1, "E: Unable to locate package nginx"))
+	assert.Nil(t, ClassifyCommandError("apt", "install", 1, "E: Sub-process /usr/bin/dpkg returned an error code (1)"))
+}
+
+func TestClassifiedRecoveryStrategy(t *testing.T) {
+	rm := &RecoveryManager{}
+
+	locked := ClassifyCommandError("apt", "install", 100, "E: Could not get lock /var/lib/dpkg/lock")
+	assert.True(t, locked.Recoverable)
+	assert.Equal(t, "retry", rm.determineRecoveryStrategy(locked))
+
+	notFound := ClassifyCommandError("apt", "install", 100, "E: Unable to locate package nginxx")
+	assert.True(t, notFound.Recoverable)
+	assert.Equal(t, "alternative_provider", rm.determineRecoveryStrategy(notFound))
+
+	diskFull := ClassifyCommandError("apt", "install", 100, "No space left on device")
+	assert.False(t, diskFull.Recoverable)
+	assert.Equal(t, "none", rm.determineRecoveryStrategy(diskFull))
+}
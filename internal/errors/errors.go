@@ -20,6 +20,7 @@ const (
 	ErrorTypeSaidataInvalid       ErrorType = "saidata_invalid"
 	ErrorTypeSaidataLoadFailed    ErrorType = "saidata_load_failed"
 	ErrorTypeSaidataValidation    ErrorType = "saidata_validation"
+	ErrorTypeSaidataGenerated     ErrorType = "saidata_generated"
 	
 	// Action-related errors
 	ErrorTypeActionNotSupported   ErrorType = "action_not_supported"
@@ -34,6 +35,11 @@ const (
 	ErrorTypeCommandNotFound      ErrorType = "command_not_found"
 	ErrorTypeCommandPermission    ErrorType = "command_permission"
 	
+	// Package manager errors
+	ErrorTypePackageNotFound      ErrorType = "package_not_found"
+	ErrorTypePackageManagerLocked ErrorType = "package_manager_locked"
+	ErrorTypeDiskFull             ErrorType = "disk_full"
+	
 	// Resource validation errors
 	ErrorTypeResourceMissing      ErrorType = "resource_missing"
 	ErrorTypeResourceInvalid      ErrorType = "resource_invalid"
@@ -60,6 +66,8 @@ const (
 	ErrorTypeSystemRequirement    ErrorType = "system_requirement"
 	ErrorTypeSystemPermission     ErrorType = "system_permission"
 	ErrorTypeSystemUnsupported    ErrorType = "system_unsupported"
+	ErrorTypeReadOnlyViolation    ErrorType = "read_only_violation"
+	ErrorTypeRootRequired         ErrorType = "root_required"
 	
 	// Network errors
 	ErrorTypeNetworkTimeout       ErrorType = "network_timeout"
@@ -174,6 +182,10 @@ func isRecoverable(errorType ErrorType) bool {
 		return true // Can retry
 	case ErrorTypeNetworkTimeout, ErrorTypeNetworkUnavailable:
 		return true // Can retry
+	case ErrorTypePackageManagerLocked:
+		return true // Can retry once the lock is released
+	case ErrorTypePackageNotFound:
+		return true // Can try different provider
 	case ErrorTypeResourceMissing:
 		return true // Can create or use alternatives
 	case ErrorTypeConfigNotFound:
@@ -216,6 +228,15 @@ func NewSaidataNotFoundError(software string) *SAIError {
 		WithSuggestion("Update saidata repository with 'sai saidata update'")
 }
 
+func NewSaidataGeneratedError(action string, software string) *SAIError {
+	return NewSAIError(ErrorTypeSaidataGenerated, fmt.Sprintf("refusing to %s %s from generated defaults: no saidata was found, so its package names and paths are guesses (pass --allow-generated to proceed)", action, software)).
+		WithContext("action", action).
+		WithContext("software", software).
+		WithSuggestion("Run with --dry-run to see the generated commands").
+		WithSuggestion("Run with --allow-generated to proceed with the defaults").
+		WithSuggestion("Update saidata repository with 'sai saidata update', or contribute saidata with 'sai saidata contribute'")
+}
+
 func NewSaidataInvalidError(software string, validationError error) *SAIError {
 	return WrapSAIError(ErrorTypeSaidataInvalid, fmt.Sprintf("saidata for '%s' is invalid", software), validationError).
 		WithContext("software", software).
@@ -318,6 +339,22 @@ func NewSystemRequirementError(requirement string, current string, minimum strin
 		WithSuggestion("Check system requirements")
 }
 
+func NewReadOnlyViolationError(operation string) *SAIError {
+	return NewSAIError(ErrorTypeReadOnlyViolation, fmt.Sprintf("refusing to run '%s' in read-only mode", operation)).
+		WithContext("operation", operation).
+		WithSuggestion("Run with --dry-run to see what would be executed").
+		WithSuggestion("Disable read-only mode (--read-only flag or read_only config) to make changes")
+}
+
+func NewRootRequiredError(action string, software string, provider string) *SAIError {
+	return NewSAIError(ErrorTypeRootRequired, fmt.Sprintf("%s of %s with %s requires root privileges", action, software, provider)).
+		WithContext("action", action).
+		WithContext("software", software).
+		WithContext("provider", provider).
+		WithSuggestion("Run sai with sudo or as root").
+		WithSuggestion("Run with --dry-run to see what would be executed")
+}
+
 func NewSystemUnsupportedError(platform string, architecture string) *SAIError {
 	return NewSAIError(ErrorTypeSystemUnsupported, fmt.Sprintf("unsupported platform: %s/%s", platform, architecture)).
 		WithContext("platform", platform).
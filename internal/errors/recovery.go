@@ -129,9 +129,9 @@ func (rm *RecoveryManager) AttemptRecovery(ctx context.Context, recoveryCtx *Rec
 func (rm *RecoveryManager) determineRecoveryStrategy(err error) string {
 	if saiErr, ok := err.(*SAIError); ok {
 		switch saiErr.Type {
-		case ErrorTypeActionTimeout, ErrorTypeNetworkTimeout, ErrorTypeNetworkUnavailable:
+		case ErrorTypeActionTimeout, ErrorTypeNetworkTimeout, ErrorTypeNetworkUnavailable, ErrorTypePackageManagerLocked:
 			return "retry"
-		case ErrorTypeProviderNotFound, ErrorTypeProviderUnavailable:
+		case ErrorTypeProviderNotFound, ErrorTypeProviderUnavailable, ErrorTypePackageNotFound:
 			return "alternative_provider"
 		case ErrorTypeResourceMissing:
 			return "resource_creation"
@@ -250,6 +250,9 @@ func (rm *RecoveryManager) tryAlternativeProvider(ctx context.Context, recoveryC
 		result.FinalError = NewProviderUnavailableError("all", "no alternative providers available").
 			WithSuggestion("Install additional package managers").
 			WithSuggestion("Check provider availability")
+		if packageErr := packageNotFoundError(recoveryCtx.OriginalError); packageErr != nil {
+			result.FinalError = packageErr
+		}
 		result.Duration = time.Since(result.StartTime)
 		return result, result.FinalError
 	}
@@ -304,11 +307,23 @@ func (rm *RecoveryManager) tryAlternativeProvider(ctx context.Context, recoveryC
 		WithContext("providers_tried", len(alternativeProviders)+1).
 		WithSuggestion("Check system requirements").
 		WithSuggestion("Verify software availability")
+	if packageErr := packageNotFoundError(recoveryCtx.OriginalError); packageErr != nil {
+		result.FinalError = packageErr
+	}
 	
 	result.Duration = time.Since(result.StartTime)
 	return result, result.FinalError
 }
 
+// packageNotFoundError returns err when it reports a package missing from the
+// provider, which explains a failed recovery better than the recovery failure itself
+func packageNotFoundError(err error) *SAIError {
+	if saiErr, ok := err.(*SAIError); ok && saiErr.Type == ErrorTypePackageNotFound {
+		return saiErr
+	}
+	return nil
+}
+
 // executeRollback executes rollback commands to undo partial changes
 func (rm *RecoveryManager) executeRollback(ctx context.Context, recoveryCtx *RecoveryContext, result *RecoveryResult) (*RecoveryResult, error) {
 	if !rm.config.EnableRollback {
@@ -57,6 +57,11 @@ func (m *MockExecutor) ExecuteSteps(ctx context.Context, steps []types.Step, sai
 	return args.Get(0).(*interfaces.ExecutionResult), args.Error(1)
 }
 
+func (m *MockExecutor) ExecuteScript(ctx context.Context, script string, software string, saidata *types.SoftwareData, provider *types.ProviderData, options interfaces.ExecuteOptions) (*interfaces.ExecutionResult, error) {
+	args := m.Called(ctx, script, software, saidata, provider, options)
+	return args.Get(0).(*interfaces.ExecutionResult), args.Error(1)
+}
+
 type MockProviderManager struct {
 	mock.Mock
 }
@@ -76,6 +81,11 @@ func (m *MockProviderManager) GetAvailableProviders() []*types.ProviderData {
 	return args.Get(0).([]*types.ProviderData)
 }
 
+func (m *MockProviderManager) WhichProvidersCan(action string, saidata *types.SoftwareData) []interfaces.ProviderCapability {
+	args := m.Called(action, saidata)
+	return args.Get(0).([]interfaces.ProviderCapability)
+}
+
 func (m *MockProviderManager) GetProvider(name string) (*types.ProviderData, error) {
 	args := m.Called(name)
 	return args.Get(0).(*types.ProviderData), args.Error(1)
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"sai/internal/download"
+	"sai/internal/history"
+)
+
+// timeoutHeadroom multiplies estimated durations so normal variance does not time out
+const timeoutHeadroom = 2
+
+// AdaptiveTimeouts stretches action timeouts for large downloads and for actions that
+// took long before, up to a hard ceiling. Commands producing no output for
+// StallTimeout are aborted regardless of the wall-clock timeout.
+type AdaptiveTimeouts struct {
+	History       *history.DurationStore
+	Ceiling       time.Duration // Hard upper limit, 0 for none
+	StallTimeout  time.Duration // Abort commands without output for this long, 0 disables
+	MinThroughput int64         // Bytes per second assumed before any throughput was observed
+
+	// contentLength returns the size of a download, or 0 when unknown
+	contentLength func(ctx context.Context, url string) int64
+}
+
+// NewAdaptiveTimeouts creates adaptive timeouts backed by a duration history
+func NewAdaptiveTimeouts(store *history.DurationStore, ceiling, stallTimeout time.Duration, minThroughput int64) *AdaptiveTimeouts {
+	return &AdaptiveTimeouts{
+		History:       store,
+		Ceiling:       ceiling,
+		StallTimeout:  stallTimeout,
+		MinThroughput: minThroughput,
+		contentLength: download.ContentLength,
+	}
+}
+
+// HistoryKey identifies an action on a software for the duration history
+func HistoryKey(provider, action, software string) string {
+	return provider + "/" + action + "/" + software
+}
+
+// Timeout returns the timeout of an action: the longest of its base timeout, twice the
+// longest recent duration and twice the expected download time of url, capped at the
+// ceiling. It also returns the download size, 0 when unknown. An empty url skips the
+// download size.
+func (a *AdaptiveTimeouts) Timeout(ctx context.Context, key string, base time.Duration, url string) (time.Duration, int64) {
+	timeout := base
+
+	if a.History != nil {
+		if longest, found := a.History.LongestDuration(key); found && longest*timeoutHeadroom > timeout {
+			timeout = longest * timeoutHeadroom
+		}
+	}
+
+	var size int64
+	if a.contentLength != nil && isHTTPURL(url) {
+		size = a.contentLength(ctx, url)
+	}
+	if size > 0 {
+		if download := base + a.downloadTime(size)*timeoutHeadroom; download > timeout {
+			timeout = download
+		}
+	}
+
+	if a.Ceiling > 0 && timeout > a.Ceiling {
+		timeout = a.Ceiling
+	}
+	return timeout, size
+}
+
+// Record stores the duration of a successful action and, when the download size is
+// known, the throughput it implies. Install time is included, so the recorded
+// throughput is conservative.
+func (a *AdaptiveTimeouts) Record(key string, duration time.Duration, size int64) {
+	if a.History == nil || duration <= 0 {
+		return
+	}
+
+	a.History.RecordDuration(key, duration)
+	if size > 0 {
+		a.History.RecordThroughput(float64(size) / duration.Seconds())
+	}
+}
+
+// downloadTime estimates how long downloading size bytes takes
+func (a *AdaptiveTimeouts) downloadTime(size int64) time.Duration {
+	throughput := float64(a.MinThroughput)
+	if a.History != nil {
+		if observed, found := a.History.Throughput(); found {
+			throughput = observed
+		}
+	}
+	if throughput <= 0 {
+		return 0
+	}
+	return time.Duration(float64(size) / throughput * float64(time.Second))
+}
+
+// isHTTPURL reports whether url is an http or https URL
+func isHTTPURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
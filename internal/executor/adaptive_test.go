@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/history"
+	"sai/internal/interfaces"
+)
+
+func TestAdaptiveTimeouts_Timeout(t *testing.T) {
+	const mib = 1024 * 1024
+	key := HistoryKey("binary", "install", "terraform")
+
+	newAdaptive := func(size int64) *AdaptiveTimeouts {
+		adaptive := NewAdaptiveTimeouts(history.NewDurationStore(t.TempDir()), 2*time.Hour, 0, mib)
+		adaptive.contentLength = func(ctx context.Context, url string) int64 { return size }
+		return adaptive
+	}
+
+	t.Run("base timeout without history or download", func(t *testing.T) {
+		timeout, size := newAdaptive(0).Timeout(context.Background(), key, 5*time.Minute, "https://example.com/terraform.zip")
+		assert.Equal(t, 5*time.Minute, timeout)
+		assert.Zero(t, size)
+	})
+
+	t.Run("large download stretches the timeout", func(t *testing.T) {
+		// 600 MiB at the 1 MiB/s minimum throughput, with headroom
+		timeout, size := newAdaptive(600*mib).Timeout(context.Background(), key, 5*time.Minute, "https://example.com/terraform.zip")
+		assert.Equal(t, 5*time.Minute+20*time.Minute, timeout)
+		assert.Equal(t, int64(600*mib), size)
+	})
+
+	t.Run("download size is only requested for http urls", func(t *testing.T) {
+		timeout, size := newAdaptive(600*mib).Timeout(context.Background(), key, 5*time.Minute, "/tmp/terraform.zip")
+		assert.Equal(t, 5*time.Minute, timeout)
+		assert.Zero(t, size)
+	})
+
+	t.Run("observed throughput replaces the minimum", func(t *testing.T) {
+		adaptive := newAdaptive(600 * mib)
+		require.NoError(t, adaptive.History.RecordThroughput(10*mib))
+		timeout, _ := adaptive.Timeout(context.Background(), key, 5*time.Minute, "https://example.com/terraform.zip")
+		assert.Equal(t, 5*time.Minute+2*time.Minute, timeout)
+	})
+
+	t.Run("slow past runs stretch the timeout", func(t *testing.T) {
+		adaptive := newAdaptive(0)
+		adaptive.Record(key, 4*time.Minute, 0)
+		timeout, _ := adaptive.Timeout(context.Background(), key, 5*time.Minute, "")
+		assert.Equal(t, 8*time.Minute, timeout)
+	})
+
+	t.Run("ceiling caps the timeout", func(t *testing.T) {
+		timeout, _ := newAdaptive(100*1024*mib).Timeout(context.Background(), key, 5*time.Minute, "https://example.com/huge.iso")
+		assert.Equal(t, 2*time.Hour, timeout)
+	})
+}
+
+func TestAdaptiveTimeouts_Record(t *testing.T) {
+	store := history.NewDurationStore(t.TempDir())
+	adaptive := NewAdaptiveTimeouts(store, time.Hour, 0, 1024)
+
+	adaptive.Record("apt/install/nginx", 10*time.Second, 50*1024*1024)
+
+	longest, found := store.LongestDuration("apt/install/nginx")
+	assert.True(t, found)
+	assert.Equal(t, 10*time.Second, longest)
+
+	throughput, found := store.Throughput()
+	assert.True(t, found)
+	assert.Equal(t, float64(5*1024*1024), throughput)
+}
+
+func TestExecuteCommand_StallTimeout(t *testing.T) {
+	executor := NewCommandExecutor(&MockLogger{}, &MockResourceValidator{})
+
+	result, err := executor.ExecuteCommand(context.Background(), "sleep 10", interfaces.CommandOptions{
+		Timeout:      time.Minute,
+		StallTimeout: 200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.Error(t, result.Error)
+	assert.Contains(t, result.Error.Error(), "command stalled")
+	assert.NotEqual(t, 0, result.ExitCode)
+	assert.Less(t, result.Duration, 5*time.Second)
+
+	result, err = executor.ExecuteCommand(context.Background(), "echo ready", interfaces.CommandOptions{
+		Timeout:      time.Minute,
+		StallTimeout: time.Second,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, result.Error)
+	assert.Equal(t, "ready\n", result.Output)
+}
@@ -0,0 +1,143 @@
+package executor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sai/internal/types"
+)
+
+// packagesReference matches the templates of the commands acting on the packages of
+// the software, the commands extra arguments are added to
+var packagesReference = regexp.MustCompile(`\bsai_(package(s|_list)?|versioned)\b`)
+
+// SetExtraArgs sets the arguments the configuration adds to the commands of actions,
+// keyed by provider then action. They override the arguments of the same flags set by
+// saidata.
+func (ge *GenericExecutor) SetExtraArgs(args map[string]map[string][]string) {
+	ge.extraArgs = args
+}
+
+// actionArgs returns the extra arguments of an action: those of the saidata of the
+// software for the provider, then those of the configuration replacing the saidata
+// ones for the same flags
+func (ge *GenericExecutor) actionArgs(provider *types.ProviderData, action string, saidata *types.SoftwareData) ([]string, error) {
+	var fromSaidata []string
+	if saidata != nil {
+		if config, exists := saidata.Providers[provider.Provider.Name]; exists {
+			fromSaidata = config.Args[action]
+		}
+	}
+	fromConfig := ge.extraArgs[provider.Provider.Name][action]
+
+	if err := checkArgConflicts(fromSaidata); err != nil {
+		return nil, fmt.Errorf("saidata args of %s %s: %w", provider.Provider.Name, action, err)
+	}
+	if err := checkArgConflicts(fromConfig); err != nil {
+		return nil, fmt.Errorf("configured args of %s %s: %w", provider.Provider.Name, action, err)
+	}
+
+	var merged []string
+	for _, arg := range fromSaidata {
+		if _, overridden := findConflict(arg, fromConfig); !overridden {
+			merged = append(merged, arg)
+		}
+	}
+	return dedupeArgs(append(merged, fromConfig...)), nil
+}
+
+// InjectArgs adds extra arguments to a rendered command run by executable, after the
+// executable and its subcommand: 'apt-get install -y nginx' becomes
+// 'apt-get install --no-install-recommends -y nginx'. Arguments already in the command
+// are skipped, and an argument setting a flag of the command to another value or
+// negating it is reported as a conflict instead of being added.
+func InjectArgs(command string, names []string, args []string) (string, error) {
+	fields := strings.Fields(command)
+	if len(args) == 0 || len(fields) == 0 || !matchesExecutable(fields[0], names) {
+		return command, nil
+	}
+
+	var added []string
+	for _, arg := range dedupeArgs(args) {
+		if conflicting, found := findConflict(arg, fields[1:]); found {
+			if conflicting == arg {
+				continue
+			}
+			return "", fmt.Errorf("extra argument %s conflicts with %s of the command '%s'", arg, conflicting, command)
+		}
+		added = append(added, arg)
+	}
+	if len(added) == 0 {
+		return command, nil
+	}
+
+	// The injection point follows the subcommand, the first word that is not a flag
+	point := 1
+	if len(fields) > 1 && !strings.HasPrefix(fields[1], "-") {
+		point = 2
+	}
+	injected := append(append(append([]string{}, fields[:point]...), added...), fields[point:]...)
+	return strings.Join(injected, " "), nil
+}
+
+// matchesExecutable reports whether the executable of a command is one of names
+func matchesExecutable(executable string, names []string) bool {
+	for _, name := range names {
+		if name != "" && filepath.Base(executable) == filepath.Base(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkArgConflicts reports arguments of one source setting the same flag differently
+func checkArgConflicts(args []string) error {
+	for i, arg := range args {
+		if conflicting, found := findConflict(arg, args[i+1:]); found && conflicting != arg {
+			return fmt.Errorf("%s conflicts with %s", arg, conflicting)
+		}
+	}
+	return nil
+}
+
+// findConflict returns the argument of args setting the same long flag as arg, or its
+// negation, such as --foo=b or --no-foo for --foo=a. Arguments that are not long flags
+// never conflict.
+func findConflict(arg string, args []string) (string, bool) {
+	name, ok := flagName(arg)
+	if !ok {
+		return "", false
+	}
+	for _, other := range args {
+		if otherName, ok := flagName(other); ok && otherName == name {
+			return other, true
+		}
+	}
+	return "", false
+}
+
+// flagName returns the name of a long flag without its value and its no- prefix
+func flagName(arg string) (string, bool) {
+	if !strings.HasPrefix(arg, "--") || len(arg) == 2 {
+		return "", false
+	}
+	name, _, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+	return strings.TrimPrefix(name, "no-"), true
+}
+
+// dedupeArgs removes repeated long flags, keeping the first occurrence. Other arguments
+// are kept as they are, '-o A -o B' repeats -o on purpose.
+func dedupeArgs(args []string) []string {
+	seen := make(map[string]bool, len(args))
+	var unique []string
+	for _, arg := range args {
+		if _, isFlag := flagName(arg); isFlag && seen[arg] {
+			continue
+		}
+		seen[arg] = true
+		unique = append(unique, arg)
+	}
+	return unique
+}
@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+func TestInjectArgs(t *testing.T) {
+	names := []string{"apt-get", "/usr/local/bin/apt-fast"}
+	tests := []struct {
+		command string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"apt-get install -y nginx", []string{"--no-install-recommends"}, "apt-get install --no-install-recommends -y nginx", false},
+		{"/usr/local/bin/apt-fast install -y nginx", []string{"-o", "Dpkg::Options::=--force-confold"}, "/usr/local/bin/apt-fast install -o Dpkg::Options::=--force-confold -y nginx", false},
+		{"apt-get -y install nginx", []string{"-q"}, "apt-get -q -y install nginx", false},
+		{"apt-get install --no-install-recommends nginx", []string{"--no-install-recommends"}, "apt-get install --no-install-recommends nginx", false},
+		{"dpkg -l nginx", []string{"--no-install-recommends"}, "dpkg -l nginx", false},
+		{"apt-get install --install-recommends nginx", []string{"--no-install-recommends"}, "", true},
+		{"apt-get install --target-release=stable nginx", []string{"--target-release=testing"}, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := InjectArgs(tt.command, names, tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("InjectArgs(%q, %v) error = %v, wantErr %v", tt.command, tt.args, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("InjectArgs(%q, %v) = %q, want %q", tt.command, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestActionArgs(t *testing.T) {
+	executor := NewGenericExecutor(nil, nil, &MockLogger{}, nil)
+	provider := &types.ProviderData{Provider: types.ProviderInfo{Name: "apt"}}
+	saidata := &types.SoftwareData{
+		Providers: map[string]types.ProviderConfig{
+			"apt": {Args: map[string][]string{"install": {"--no-install-recommends", "--target-release=stable"}}},
+		},
+	}
+
+	args, err := executor.actionArgs(provider, "install", saidata)
+	if err != nil {
+		t.Fatalf("actionArgs() error = %v", err)
+	}
+	if want := []string{"--no-install-recommends", "--target-release=stable"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("actionArgs() = %v, want the saidata args %v", args, want)
+	}
+
+	// The configuration overrides the saidata args of the same flags
+	executor.SetExtraArgs(map[string]map[string][]string{"apt": {"install": {"--install-recommends", "-q"}}})
+	args, err = executor.actionArgs(provider, "install", saidata)
+	if err != nil {
+		t.Fatalf("actionArgs() error = %v", err)
+	}
+	if want := []string{"--target-release=stable", "--install-recommends", "-q"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("actionArgs() = %v, want %v", args, want)
+	}
+
+	// Conflicts within one source are reported
+	executor.SetExtraArgs(map[string]map[string][]string{"apt": {"install": {"--install-recommends", "--no-install-recommends"}}})
+	if _, err := executor.actionArgs(provider, "install", saidata); err == nil || !strings.Contains(err.Error(), "conflicts with") {
+		t.Errorf("actionArgs() error = %v, want a conflict", err)
+	}
+}
+
+func TestDryRun_ExtraArgs(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return strings.ReplaceAll(template, "{{sai_package('*', 'package_name', 'apt')}}", "nginx"), nil
+		},
+	}
+	executor := NewGenericExecutor(NewCommandExecutor(logger, validator), templateEngine, logger, validator)
+	executor.SetExtraArgs(map[string]map[string][]string{"apt": {"install": {"--no-install-recommends"}}})
+
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "apt", Executable: "apt-get"},
+		Actions: map[string]types.Action{
+			"install": {Steps: []types.Step{
+				{Command: "apt-get update"},
+				{Command: "apt-get install -y {{sai_package('*', 'package_name', 'apt')}}"},
+			}},
+		},
+	}
+
+	result, err := executor.DryRun(context.Background(), provider, "install", "nginx", nil, interfaces.ExecuteOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []string{"apt-get update", "apt-get install --no-install-recommends -y nginx"}
+	if !reflect.DeepEqual(result.Commands, want) {
+		t.Errorf("Expected %v, got %v", want, result.Commands)
+	}
+}
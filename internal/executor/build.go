@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sai/internal/build"
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// SetBuildCache sets the directory the sources of actions setting build are built in,
+// build.DefaultCacheDir when empty, and the parallel jobs of their builds
+func (ge *GenericExecutor) SetBuildCache(dir string, jobs int) {
+	ge.buildCacheDir = dir
+	ge.buildJobs = jobs
+}
+
+// buildPipeline returns the pipeline building the sources of an action. Its commands
+// run through the command executor, so they are audited and sandboxed like rendered
+// ones.
+func (ge *GenericExecutor) buildPipeline(timeout time.Duration, options interfaces.ExecuteOptions) *build.Pipeline {
+	pipeline := build.NewPipeline(ge.buildCacheDir, func(ctx context.Context, dir string, env map[string]string, args ...string) (string, error) {
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = QuoteArgument(arg, "")
+		}
+		result, err := ge.commandExecutor.ExecuteCommand(ctx, strings.Join(quoted, " "), interfaces.CommandOptions{
+			Timeout:      timeout,
+			WorkDir:      dir,
+			Env:          env,
+			Verbose:      options.Verbose,
+			StallTimeout: options.StallTimeout,
+		})
+		if result == nil {
+			return "", err
+		}
+		return result.Output, err
+	})
+	pipeline.Jobs = ge.buildJobs
+	pipeline.RequireChecksum = ge.requireChecksums
+	pipeline.DestDir = ge.root
+	pipeline.Logger = ge.logger
+	return pipeline
+}
+
+// buildSources builds and installs the sources of the saidata in order, returning a
+// summary of the steps each build ran and resumed
+func (ge *GenericExecutor) buildSources(
+	ctx context.Context,
+	providerAction *types.Action,
+	saidata *types.SoftwareData,
+	options interfaces.ExecuteOptions,
+) (string, error) {
+	if len(saidata.Sources) == 0 {
+		return "", fmt.Errorf("%s declares no sources to build", saidata.Metadata.Name)
+	}
+
+	pipeline := ge.buildPipeline(providerAction.GetTimeout(), options)
+	var output strings.Builder
+	for _, source := range saidata.Sources {
+		result, err := pipeline.Build(ctx, source)
+		if result != nil {
+			output.WriteString(describeBuild(result))
+		}
+		if err != nil {
+			return output.String(), err
+		}
+	}
+	return output.String(), nil
+}
+
+// describeBuild summarizes the steps of a build
+func describeBuild(result *build.Result) string {
+	var description strings.Builder
+	fmt.Fprintf(&description, "Build of %s in %s\n", result.Source, result.Dir)
+	if len(result.Resumed) > 0 {
+		fmt.Fprintf(&description, "  resumed after: %s\n", joinSteps(result.Resumed))
+	}
+	fmt.Fprintf(&description, "  ran: %s\n", joinSteps(result.Ran))
+	return description.String()
+}
+
+// planBuilds describes the builds of the sources of the saidata for dry runs
+func (ge *GenericExecutor) planBuilds(saidata *types.SoftwareData, options interfaces.ExecuteOptions) string {
+	pipeline := ge.buildPipeline(0, options)
+	var plan strings.Builder
+	for _, source := range saidata.Sources {
+		fmt.Fprintf(&plan, "Build %s in %s:\n", source.Name, pipeline.Dir(source))
+		for _, step := range pipeline.Plan(source) {
+			fmt.Fprintf(&plan, "  %s\n", step)
+		}
+	}
+	return plan.String()
+}
+
+// joinSteps joins the names of build steps
+func joinSteps(steps []build.Step) string {
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = string(step)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,345 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/servicemgr"
+	"sai/internal/types"
+)
+
+// Types and actions of the changes detected around an action
+const (
+	ChangePackage = "package"
+	ChangeFile    = "file"
+	ChangeService = "service"
+
+	PackageInstalled = "installed"
+	PackageRemoved   = "removed"
+	PackageUpgraded  = "upgraded"
+	FileCreated      = "created"
+	FileDeleted      = "deleted"
+	ServiceStarted   = "started"
+	ServiceStopped   = "stopped"
+)
+
+// serviceStateTimeout bounds each query of the state of a service
+const serviceStateTimeout = 10 * time.Second
+
+// serviceChangingActions are the actions whose service state transitions are
+// detected, packages often starting their services on install and stopping them on
+// removal
+var serviceChangingActions = map[string]bool{
+	"install":   true,
+	"uninstall": true,
+	"upgrade":   true,
+	"start":     true,
+	"stop":      true,
+	"restart":   true,
+}
+
+// packageLine matches a line of package manager output naming one package change:
+// the groups are the name, then optionally the version, then the other version of
+// an upgrade
+type packageLine struct {
+	pattern  *regexp.Regexp
+	action   string
+	nevra    bool // The name group holds name-version-release.arch, as rpm prints it
+	oldFirst bool // The old version of an upgrade comes before the new one
+}
+
+var packageLines = []packageLine{
+	// dpkg (apt): "Unpacking nginx (1.24.0-2) over (1.24.0-1) ..." precedes "Setting up"
+	{regexp.MustCompile(`^Unpacking (\S+) \(([^)]+)\) over \(([^)]+)\)`), PackageUpgraded, false, false},
+	{regexp.MustCompile(`^Setting up (\S+) \(([^)]+)\)`), PackageInstalled, false, false},
+	{regexp.MustCompile(`^Removing (\S+) \(([^)]+)\)`), PackageRemoved, false, false},
+	// apk: "(1/3) Installing pcre (8.45-r3)", "(1/1) Upgrading nginx (1.24-r1 -> 1.26-r0)"
+	{regexp.MustCompile(`^\(\d+/\d+\) Installing (\S+) \(([^)]+)\)`), PackageInstalled, false, false},
+	{regexp.MustCompile(`^\(\d+/\d+\) (?:Purging|Deleting) (\S+) \(([^)]+)\)`), PackageRemoved, false, false},
+	{regexp.MustCompile(`^\(\d+/\d+\) Upgrading (\S+) \(([^)]+) -> ([^)]+)\)`), PackageUpgraded, false, true},
+	// zypper: "(1/1) Installing: nginx-1.21.5-150400.3.3.1.x86_64 ...[done]"
+	{regexp.MustCompile(`^\(\d+/\d+\) Installing: (\S+)`), PackageInstalled, true, false},
+	{regexp.MustCompile(`^\(\d+/\d+\) Removing:? (\S+)`), PackageRemoved, true, false},
+	// pacman: "(1/1) installing nginx   [####] 100%"
+	{regexp.MustCompile(`^\(\s*\d+/\d+\) (?:installing|reinstalling) (\S+)`), PackageInstalled, false, false},
+	{regexp.MustCompile(`^\(\s*\d+/\d+\) removing (\S+)`), PackageRemoved, false, false},
+	{regexp.MustCompile(`^\(\s*\d+/\d+\) upgrading (\S+)`), PackageUpgraded, false, false},
+	// Homebrew: "==> Pouring nginx--1.25.3.arm64_sonoma.bottle.tar.gz",
+	// "Uninstalling /opt/homebrew/Cellar/nginx/1.25.3... (26 files, 2.4MB)"
+	{regexp.MustCompile(`^==> Pouring (\S+?)--(\d[^-]*?)\.[a-z0-9_]+\.bottle`), PackageInstalled, false, false},
+	{regexp.MustCompile(`^Uninstalling /\S*/Cellar/([^/]+)/([^.][^/]*?)\.\.\.`), PackageRemoved, false, false},
+	{regexp.MustCompile(`^==> Upgrading (\S+)$`), PackageUpgraded, false, false},
+	// cargo: "Installed package `ripgrep v14.0.3` (executable `rg`)"
+	{regexp.MustCompile("^Installed package `(\\S+) v(\\S+)`"), PackageInstalled, false, false},
+}
+
+// packageSections are the headers of the dnf and yum transaction summaries, followed
+// by indented lines of name-version-release.arch
+var packageSections = map[string]string{
+	"Installed:":            PackageInstalled,
+	"Dependency Installed:": PackageInstalled,
+	"Removed:":              PackageRemoved,
+	"Erased:":               PackageRemoved,
+	"Dependency Removed:":   PackageRemoved,
+	"Upgraded:":             PackageUpgraded,
+	"Updated:":              PackageUpgraded,
+}
+
+// successLine matches the summaries of pip and gem: "Successfully installed a-1.0 b-2.0"
+var successLine = regexp.MustCompile(`^Successfully (installed|uninstalled) (.+)$`)
+
+// rpmArch matches the architecture suffix of an rpm package
+var rpmArch = regexp.MustCompile(`\.(x86_64|i[3-6]86|aarch64|arm64|armv7hl|ppc64le|s390x|noarch|src)$`)
+
+// ParsePackageChanges returns the packages an action installed, removed or upgraded,
+// parsed from the output of the package managers that print them: dpkg (apt), dnf,
+// yum, zypper, apk, pacman, Homebrew, pip, gem and cargo. A package is reported once,
+// with its last change.
+func ParsePackageChanges(output string) []interfaces.Change {
+	var changes []interfaces.Change
+	index := make(map[string]int)
+	add := func(action, name, oldVersion, newVersion string) {
+		name = strings.TrimSuffix(name, ":")
+		if i := strings.IndexByte(name, ':'); i > 0 {
+			name = name[:i] // Architecture qualifier of dpkg, nginx:amd64
+		}
+		if name == "" {
+			return
+		}
+		change := interfaces.Change{Type: ChangePackage, Resource: name, Action: action, OldValue: oldVersion, NewValue: newVersion}
+		if i, seen := index[name]; seen {
+			// dpkg sets up the packages it upgraded, keep the upgrade
+			if changes[i].Action == PackageUpgraded && action == PackageInstalled {
+				return
+			}
+			changes[i] = change
+			return
+		}
+		index[name] = len(changes)
+		changes = append(changes, change)
+	}
+
+	section := ""
+	for _, raw := range strings.Split(output, "\n") {
+		line := strings.TrimSpace(raw)
+		if section != "" {
+			// The packages of a section are indented, several per line with yum
+			if line != "" && (raw[0] == ' ' || raw[0] == '\t') {
+				for _, field := range strings.Fields(line) {
+					name, version := splitNEVRA(field)
+					add(section, name, "", version)
+				}
+				continue
+			}
+			section = ""
+		}
+		if action, exists := packageSections[line]; exists {
+			section = action
+			continue
+		}
+
+		if match := successLine.FindStringSubmatch(line); match != nil {
+			for _, field := range strings.Fields(match[2]) {
+				name, version := splitLast(field, "-")
+				if match[1] == "uninstalled" {
+					add(PackageRemoved, name, version, "")
+				} else {
+					add(PackageInstalled, name, "", version)
+				}
+			}
+			continue
+		}
+
+		for _, candidate := range packageLines {
+			match := candidate.pattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			name, version, other := match[1], "", ""
+			if len(match) > 2 {
+				version = match[2]
+			}
+			if len(match) > 3 {
+				other = match[3]
+			}
+			if candidate.nevra {
+				name, version = splitNEVRA(name)
+			}
+			switch {
+			case other != "" && candidate.oldFirst:
+				add(candidate.action, name, version, other)
+			case other != "":
+				add(candidate.action, name, other, version)
+			case candidate.action == PackageRemoved:
+				add(candidate.action, name, version, "")
+			default:
+				add(candidate.action, name, "", version)
+			}
+			break
+		}
+	}
+	return changes
+}
+
+// splitNEVRA splits an rpm name-[epoch:]version-release.arch into the name and the
+// version-release
+func splitNEVRA(nevra string) (string, string) {
+	nevr := rpmArch.ReplaceAllString(nevra, "")
+	name, release := splitLast(nevr, "-")
+	name, version := splitLast(name, "-")
+	if version == "" {
+		return nevr, ""
+	}
+	return name, version + "-" + release
+}
+
+// splitLast splits s around the last separator
+func splitLast(s, separator string) (string, string) {
+	i := strings.LastIndex(s, separator)
+	if i <= 0 {
+		return s, ""
+	}
+	return s[:i], s[i+len(separator):]
+}
+
+// resourceSnapshot is the state of the files and services of a software before an
+// action, compared with their state after it to detect the changes it made
+type resourceSnapshot struct {
+	paths    map[string]bool   // Whether each file, directory and command exists
+	services map[string]string // State of each service, active or inactive
+}
+
+// snapshotResources records whether the files, directories and commands of the
+// saidata exist, and the state of its services for the actions changing them
+func (ge *GenericExecutor) snapshotResources(ctx context.Context, action string, provider *types.ProviderData, saidata *types.SoftwareData) *resourceSnapshot {
+	snapshot := &resourceSnapshot{
+		paths:    make(map[string]bool),
+		services: make(map[string]string),
+	}
+	if saidata == nil {
+		return snapshot
+	}
+
+	paths, services := resourcesOf(provider, saidata)
+	for _, path := range paths {
+		path = filepath.Join(ge.root, path)
+		_, err := os.Lstat(path)
+		snapshot.paths[path] = err == nil
+	}
+	// Services of an alternate root filesystem are not running
+	if ge.root == "" && serviceChangingActions[action] {
+		for _, service := range services {
+			snapshot.services[service] = ge.serviceState(ctx, service)
+		}
+	}
+	return snapshot
+}
+
+// detectChanges returns the files created or deleted and the services started or
+// stopped since the snapshot was taken
+func (ge *GenericExecutor) detectChanges(ctx context.Context, before *resourceSnapshot) []interfaces.Change {
+	var changes []interfaces.Change
+	for _, path := range sortedKeys(before.paths) {
+		_, err := os.Lstat(path)
+		exists := err == nil
+		switch {
+		case exists && !before.paths[path]:
+			changes = append(changes, interfaces.Change{Type: ChangeFile, Resource: path, Action: FileCreated})
+		case !exists && before.paths[path]:
+			changes = append(changes, interfaces.Change{Type: ChangeFile, Resource: path, Action: FileDeleted})
+		}
+	}
+	for _, service := range sortedKeys(before.services) {
+		state := ge.serviceState(ctx, service)
+		if state == before.services[service] {
+			continue
+		}
+		action := ServiceStopped
+		if state == "active" {
+			action = ServiceStarted
+		}
+		changes = append(changes, interfaces.Change{
+			Type:     ChangeService,
+			Resource: service,
+			Action:   action,
+			OldValue: before.services[service],
+			NewValue: state,
+		})
+	}
+	return changes
+}
+
+// serviceState returns "active" for a running service and "inactive" otherwise,
+// including when its state cannot be queried
+func (ge *GenericExecutor) serviceState(ctx context.Context, service string) string {
+	command, err := servicemgr.ForService(service).Command(servicemgr.IsActive, service)
+	if err != nil {
+		return "inactive"
+	}
+	result, _ := ge.commandExecutor.ExecuteCommand(ctx, command, interfaces.CommandOptions{
+		Timeout:  serviceStateTimeout,
+		ReadOnly: true,
+	})
+	if result != nil && strings.TrimSpace(result.Output) == "active" {
+		return "active"
+	}
+	return "inactive"
+}
+
+// resourcesOf returns the paths of the files, directories and commands of a software
+// and the names of its services, the ones of the provider configuration replacing the
+// general ones
+func resourcesOf(provider *types.ProviderData, saidata *types.SoftwareData) ([]string, []string) {
+	commands, files, directories, services := saidata.Commands, saidata.Files, saidata.Directories, saidata.Services
+	if provider != nil {
+		if config := saidata.GetProviderConfig(provider.Provider.Name); config != nil {
+			if len(config.Commands) > 0 {
+				commands = config.Commands
+			}
+			if len(config.Files) > 0 {
+				files = config.Files
+			}
+			if len(config.Directories) > 0 {
+				directories = config.Directories
+			}
+			if len(config.Services) > 0 {
+				services = config.Services
+			}
+		}
+	}
+
+	var paths, names []string
+	for i := range commands {
+		paths = append(paths, commands[i].GetPathOrDefault())
+	}
+	for _, file := range files {
+		if file.Path != "" {
+			paths = append(paths, file.Path)
+		}
+	}
+	for _, directory := range directories {
+		if directory.Path != "" {
+			paths = append(paths, directory.Path)
+		}
+	}
+	for i := range services {
+		names = append(names, services[i].GetServiceNameOrDefault())
+	}
+	return paths, names
+}
+
+// sortedKeys returns the keys of a map in order, for stable change lists
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+func TestParsePackageChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []string
+	}{
+		{
+			name: "apt",
+			output: `Unpacking libssl3:amd64 (3.0.2-0ubuntu1.15) over (3.0.2-0ubuntu1.14) ...
+Selecting previously unselected package nginx.
+Unpacking nginx (1.18.0-6ubuntu14.4) ...
+Setting up libssl3:amd64 (3.0.2-0ubuntu1.15) ...
+Setting up nginx (1.18.0-6ubuntu14.4) ...
+Processing triggers for man-db (2.10.2-1) ...`,
+			expected: []string{
+				"package libssl3 upgraded (3.0.2-0ubuntu1.14 -> 3.0.2-0ubuntu1.15)",
+				"package nginx installed (1.18.0-6ubuntu14.4)",
+			},
+		},
+		{
+			name:     "apt remove",
+			output:   "Removing nginx (1.18.0-6ubuntu14.4) ...\nPurging configuration files for nginx (1.18.0-6ubuntu14.4) ...",
+			expected: []string{"package nginx removed (1.18.0-6ubuntu14.4)"},
+		},
+		{
+			name: "dnf",
+			output: `Upgraded:
+  openssl-libs-1:3.0.7-25.el9.x86_64
+Installed:
+  nginx-1:1.20.1-14.el9.x86_64     nginx-filesystem-1:1.20.1-14.el9.noarch
+
+Complete!`,
+			expected: []string{
+				"package openssl-libs upgraded (1:3.0.7-25.el9)",
+				"package nginx installed (1:1.20.1-14.el9)",
+				"package nginx-filesystem installed (1:1.20.1-14.el9)",
+			},
+		},
+		{
+			name:     "zypper",
+			output:   "(1/1) Installing: nginx-1.21.5-150400.3.3.1.x86_64 ..............[done]",
+			expected: []string{"package nginx installed (1.21.5-150400.3.3.1)"},
+		},
+		{
+			name:     "apk",
+			output:   "(1/2) Installing pcre (8.45-r3)\n(2/2) Upgrading nginx (1.24.0-r1 -> 1.26.1-r0)\nOK: 10 MiB in 20 packages",
+			expected: []string{"package pcre installed (8.45-r3)", "package nginx upgraded (1.24.0-r1 -> 1.26.1-r0)"},
+		},
+		{
+			name:     "pacman",
+			output:   "(1/1) installing nginx                               [######################] 100%",
+			expected: []string{"package nginx installed"},
+		},
+		{
+			name:     "brew",
+			output:   "==> Pouring nginx--1.25.3.arm64_sonoma.bottle.tar.gz\nUninstalling /opt/homebrew/Cellar/pcre/8.45... (204 files, 4.6MB)",
+			expected: []string{"package nginx installed (1.25.3)", "package pcre removed (8.45)"},
+		},
+		{
+			name:     "pip",
+			output:   "Successfully installed python-dateutil-2.8.2 six-1.16.0",
+			expected: []string{"package python-dateutil installed (2.8.2)", "package six installed (1.16.0)"},
+		},
+		{
+			name:     "no package changes",
+			output:   "Reading package lists...\nnginx is already the newest version (1.18.0-6ubuntu14.4).",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var described []string
+			for _, change := range ParsePackageChanges(tt.output) {
+				assert.Equal(t, ChangePackage, change.Type)
+				described = append(described, change.String())
+			}
+			assert.Equal(t, tt.expected, described)
+		})
+	}
+}
+
+func TestDetectChanges(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "nginx.conf")
+	old := filepath.Join(dir, "old.conf")
+	require.NoError(t, os.WriteFile(old, []byte("old"), 0644))
+
+	executor := NewGenericExecutor(NewCommandExecutor(&MockLogger{}, &MockResourceValidator{}), &MockTemplateEngine{}, &MockLogger{}, &MockResourceValidator{})
+	provider := &types.ProviderData{Provider: types.ProviderInfo{Name: "apt"}}
+	saidata := &types.SoftwareData{
+		Metadata: types.Metadata{Name: "nginx"},
+		Files: []types.File{
+			{Name: "config", Path: config},
+			{Name: "old", Path: old},
+		},
+	}
+
+	snapshot := executor.snapshotResources(context.Background(), "configure", provider, saidata)
+	require.NoError(t, os.WriteFile(config, []byte("server {}"), 0644))
+	require.NoError(t, os.Remove(old))
+
+	assert.Equal(t, []interfaces.Change{
+		{Type: ChangeFile, Resource: config, Action: FileCreated},
+		{Type: ChangeFile, Resource: old, Action: FileDeleted},
+	}, executor.detectChanges(context.Background(), snapshot))
+	assert.Empty(t, executor.detectChanges(context.Background(), executor.snapshotResources(context.Background(), "configure", provider, saidata)))
+}
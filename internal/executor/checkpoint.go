@@ -0,0 +1,159 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sai/internal/fileutil"
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// stepCheckpoint records the steps of an action completed by a failed run, so running
+// the action again skips them and resumes at the step that failed. Steps are keyed by
+// their idempotency key, or by their position, name and rendered command: a step whose
+// command changed runs again, and identical steps are recorded apart. The checkpoint is
+// removed once every step succeeded.
+//
+// Steps declaring a rollback, and Windows primitives, always run again, since the
+// automatic rollback of the failed run may have undone them.
+type stepCheckpoint struct {
+	path  string
+	mutex sync.Mutex
+
+	Steps map[string]completedStep `json:"steps"`
+}
+
+// completedStep is a step completed by an earlier run
+type completedStep struct {
+	Name      string    `json:"name,omitempty"`
+	Output    string    `json:"output,omitempty"` // Trimmed output, restored for capture and register
+	Completed time.Time `json:"completed"`
+}
+
+// SetCheckpointDir sets the directory of the checkpoints of multi-step actions,
+// empty to run every step of an action again after a failure
+func (ge *GenericExecutor) SetCheckpointDir(dir string) {
+	ge.checkpointDir = dir
+}
+
+// checkpointPath returns the checkpoint file of the steps of an action, empty when
+// checkpoints are disabled
+func (ge *GenericExecutor) checkpointPath(provider *types.ProviderData, action, software string) string {
+	if ge.checkpointDir == "" {
+		return ""
+	}
+	name := strings.Join([]string{provider.Provider.Name, action, software}, "-")
+	return filepath.Join(ge.checkpointDir, strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(name)+".json")
+}
+
+// loadCheckpoint returns the checkpoint of the steps of options, nil for dry runs and
+// when checkpoints are disabled. An unreadable checkpoint is started over.
+func (ge *GenericExecutor) loadCheckpoint(options interfaces.ExecuteOptions) *stepCheckpoint {
+	if options.Checkpoint == "" || options.DryRun {
+		return nil
+	}
+
+	checkpoint := &stepCheckpoint{path: options.Checkpoint, Steps: map[string]completedStep{}}
+	data, err := os.ReadFile(options.Checkpoint)
+	if err == nil {
+		err = json.Unmarshal(data, checkpoint)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		ge.logger.Warn("Ignoring unreadable step checkpoint",
+			interfaces.LogField{Key: "path", Value: options.Checkpoint},
+			interfaces.LogField{Key: "error", Value: err},
+		)
+		checkpoint.Steps = map[string]completedStep{}
+	}
+	if checkpoint.Steps == nil {
+		checkpoint.Steps = map[string]completedStep{}
+	}
+	return checkpoint
+}
+
+// completed returns the earlier completion of the step with a key
+func (c *stepCheckpoint) completed(key string) (completedStep, bool) {
+	if c == nil {
+		return completedStep{}, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	step, found := c.Steps[key]
+	return step, found
+}
+
+// complete records the completion of the step with a key
+func (c *stepCheckpoint) complete(key string, step completedStep) error {
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.Steps[key] = step
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(c.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the checkpoint once every step succeeded
+func (c *stepCheckpoint) remove() error {
+	if c == nil {
+		return nil
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// checkpointed reports whether a step is recorded in checkpoints
+func checkpointed(step types.Step) bool {
+	return step.Rollback == "" && !step.IsWindowsPrimitive()
+}
+
+// stepKey returns the key a step is recorded under: its rendered idempotency key, or
+// a digest of its index, name and rendered command
+func (ge *GenericExecutor) stepKey(
+	index int,
+	step types.Step,
+	rendered string,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+) (string, error) {
+	if step.IdempotencyKey != "" {
+		key, err := ge.renderTemplate(step.IdempotencyKey, "", saidata, provider, options)
+		if err != nil {
+			return "", fmt.Errorf("failed to render idempotency key: %w", err)
+		}
+		return "key:" + key, nil
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s\x00%s", index, step.Name, rendered)))
+	return "command:" + hex.EncodeToString(sum[:]), nil
+}
+
+// finishCheckpoint removes the checkpoint of steps that all succeeded
+func (ge *GenericExecutor) finishCheckpoint(checkpoint *stepCheckpoint, result *interfaces.ExecutionResult) {
+	if result == nil || !result.Success {
+		return
+	}
+	if err := checkpoint.remove(); err != nil {
+		ge.logger.Warn("Failed to remove step checkpoint",
+			interfaces.LogField{Key: "error", Value: err},
+		)
+	}
+}
@@ -1,16 +1,23 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"sai/internal/audit"
 	"sai/internal/debug"
 	"sai/internal/interfaces"
+	"sai/internal/privilege"
 	"sai/internal/types"
 )
 
@@ -20,6 +27,12 @@ type CommandExecutor struct {
 	validator interfaces.ResourceValidator
 	dryRun    bool
 	timeout   time.Duration
+
+	// Log of the commands run, nil to record none
+	auditLog *audit.Log
+
+	// Restrictions of the commands run
+	sandbox Sandbox
 }
 
 // NewCommandExecutor creates a new command executor
@@ -31,15 +44,56 @@ func NewCommandExecutor(logger interfaces.Logger, validator interfaces.ResourceV
 	}
 }
 
+// SetAuditLog records every command executed, and every command a dry run shows, in
+// the audit log. Passing nil stops recording.
+func (ce *CommandExecutor) SetAuditLog(log *audit.Log) {
+	ce.auditLog = log
+}
+
 // ExecuteCommand executes a single command with proper error handling
 func (ce *CommandExecutor) ExecuteCommand(ctx context.Context, command string, options interfaces.CommandOptions) (*interfaces.CommandResult, error) {
+	result, err := ce.execute(ctx, command, options, false)
+	ce.audit(ctx, command, result, err, ce.dryRun || options.Timeout == 0)
+	return result, err
+}
+
+// audit records a command and its result in the audit log. A failure to record is
+// logged and does not fail the command.
+func (ce *CommandExecutor) audit(ctx context.Context, command string, result *interfaces.CommandResult, err error, dryRun bool) {
+	if ce.auditLog == nil {
+		return
+	}
+	entry := audit.Entry{Command: command, DryRun: dryRun}
+	if tool := privilege.ToolFrom(ctx); tool != nil {
+		entry.Escalation = tool.Name
+	}
+	if result != nil {
+		entry.ExitCode = result.ExitCode
+		entry.DurationMS = result.Duration.Milliseconds()
+		if err == nil && result.Error != nil {
+			err = result.Error
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if auditErr := ce.auditLog.Record(ctx, entry); auditErr != nil {
+		ce.logger.Warn("Failed to record command in the audit log",
+			interfaces.LogField{Key: "error", Value: auditErr},
+		)
+	}
+}
+
+// execute runs a single command, see ExecuteCommand. The command of a script runs
+// its interpreter, which the allowed commands of the sandbox are not checked against.
+func (ce *CommandExecutor) execute(ctx context.Context, command string, options interfaces.CommandOptions, script bool) (*interfaces.CommandResult, error) {
 	startTime := time.Now()
 	
 	// Log command execution
 	ce.logger.Debug("Executing command", interfaces.LogField{Key: "command", Value: command})
 	
 	// Validate command before execution
-	if err := ce.validateCommand(command); err != nil {
+	if err := ce.validateShellCommand(command, options.Shell, options.WorkDir); err != nil {
 		return &interfaces.CommandResult{
 			Command:  command,
 			Error:    err,
@@ -47,6 +101,18 @@ func (ce *CommandExecutor) ExecuteCommand(ctx context.Context, command string, o
 			Duration: time.Since(startTime),
 		}, err
 	}
+
+	// Refuse commands the sandbox does not allow, dry runs included
+	if !script {
+		if err := ce.sandbox.checkAllowed(shellCommandArgs(command, options.Shell)[0]); err != nil {
+			return &interfaces.CommandResult{
+				Command:  command,
+				Error:    err,
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, err
+		}
+	}
 	
 	// Handle dry-run mode
 	if ce.dryRun || options.Timeout == 0 {
@@ -68,8 +134,8 @@ func (ce *CommandExecutor) ExecuteCommand(ctx context.Context, command string, o
 	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	
-	// Parse command and arguments
-	parts := strings.Fields(command)
+	// Parse command and arguments, wrapping it in the provider shell if needed
+	parts := shellCommandArgs(command, options.Shell)
 	if len(parts) == 0 {
 		err := fmt.Errorf("empty command")
 		return &interfaces.CommandResult{
@@ -80,6 +146,22 @@ func (ce *CommandExecutor) ExecuteCommand(ctx context.Context, command string, o
 		}, err
 	}
 	
+	// Run the command in the namespaces of the sandbox
+	parts, err := ce.sandbox.wrap(parts)
+	if err != nil {
+		return &interfaces.CommandResult{
+			Command:  command,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, err
+	}
+	
+	// Run the command as root through the escalation tool of the action requiring root
+	if tool := privilege.ToolFrom(ctx); tool != nil {
+		parts = tool.Wrap(parts, options.Env)
+	}
+	
 	// Create command
 	cmd := exec.CommandContext(cmdCtx, parts[0], parts[1:]...)
 	
@@ -88,13 +170,14 @@ func (ce *CommandExecutor) ExecuteCommand(ctx context.Context, command string, o
 		cmd.Dir = options.WorkDir
 	}
 	
-	// Set environment variables
-	if len(options.Env) > 0 {
-		env := os.Environ()
-		for key, value := range options.Env {
-			env = append(env, fmt.Sprintf("%s=%s", key, value))
-		}
-		cmd.Env = env
+	// Set environment variables and the user of the sandbox
+	if err := ce.sandbox.apply(cmd, options.Env); err != nil {
+		return &interfaces.CommandResult{
+			Command:  command,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, err
 	}
 	
 	// Set input if provided
@@ -112,8 +195,8 @@ func (ce *CommandExecutor) ExecuteCommand(ctx context.Context, command string, o
 		}, err
 	}
 	
-	// Execute command and capture output
-	output, err := cmd.CombinedOutput()
+	// Execute command and capture output, aborting it when it stalls
+	output, err := runWithStallDetection(cmd, cancel, options.StallTimeout, options.Output)
 	duration := time.Since(startTime)
 	
 	// Get exit code
@@ -198,6 +281,124 @@ func (ce *CommandExecutor) ExecuteCommand(ctx context.Context, command string, o
 	return result, nil
 }
 
+// streamCaptureSize bounds the output kept for the result of a streamed command, whose
+// full output was already shown as it ran
+const streamCaptureSize = 1 << 20
+
+// activityWriter collects command output, forwards it to stream when set and remembers
+// when output was last written
+type activityWriter struct {
+	mutex      sync.Mutex
+	output     io.Writer
+	stream     io.Writer
+	lastActive time.Time
+}
+
+// newActivityWriter returns a writer capturing all the output, or only its tail when
+// it is streamed
+func newActivityWriter(stream io.Writer) *activityWriter {
+	aw := &activityWriter{output: &bytes.Buffer{}, stream: stream, lastActive: time.Now()}
+	if stream != nil {
+		aw.output = newRingBuffer(streamCaptureSize)
+	}
+	return aw
+}
+
+func (aw *activityWriter) Write(p []byte) (int, error) {
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	aw.lastActive = time.Now()
+	if aw.stream != nil {
+		// A failing terminal must not fail the command
+		aw.stream.Write(p)
+	}
+	return aw.output.Write(p)
+}
+
+// bytes returns a copy of the captured output
+func (aw *activityWriter) bytes() []byte {
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	if ring, ok := aw.output.(*ringBuffer); ok {
+		return ring.Bytes()
+	}
+	return append([]byte(nil), aw.output.(*bytes.Buffer).Bytes()...)
+}
+
+// idle returns how long the command has produced no output
+func (aw *activityWriter) idle() time.Duration {
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	return time.Since(aw.lastActive)
+}
+
+// runWithStallDetection runs a command and returns its combined output. When
+// stallTimeout is positive, the command is cancelled once it produces no output for
+// that long, which catches hung downloads long before the wall-clock timeout. When
+// stream is set, the output is also written to it as the command produces it.
+func runWithStallDetection(cmd *exec.Cmd, cancel context.CancelFunc, stallTimeout time.Duration, stream io.Writer) ([]byte, error) {
+	if stallTimeout <= 0 && stream == nil {
+		return cmd.CombinedOutput()
+	}
+
+	writer := newActivityWriter(stream)
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	// Do not wait forever for children that inherited the output pipes
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	var stalled atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		if stallTimeout <= 0 {
+			return
+		}
+		ticker := time.NewTicker(stallCheckInterval(stallTimeout))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if writer.idle() >= stallTimeout {
+					stalled.Store(true)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	close(done)
+
+	output := writer.bytes()
+
+	if stalled.Load() {
+		return output, fmt.Errorf("command stalled: no output for %s", stallTimeout)
+	}
+	return output, err
+}
+
+// stallCheckInterval returns how often to check a command for output
+func stallCheckInterval(stallTimeout time.Duration) time.Duration {
+	interval := stallTimeout / 10
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	if interval > 5*time.Second {
+		interval = 5 * time.Second
+	}
+	return interval
+}
+
 // ExecuteWithRetry executes a command with retry logic
 func (ce *CommandExecutor) ExecuteWithRetry(ctx context.Context, command string, options interfaces.CommandOptions, retryConfig *types.RetryConfig) (*interfaces.CommandResult, error) {
 	if retryConfig == nil {
@@ -263,17 +464,18 @@ func (ce *CommandExecutor) ExecuteWithRetry(ctx context.Context, command string,
 
 // ValidateCommand validates that a command can be executed
 func (ce *CommandExecutor) ValidateCommand(command string) error {
-	return ce.validateCommand(command)
+	return ce.validateCommand(command, "")
 }
 
-// validateCommand performs safety validation on a command
-func (ce *CommandExecutor) validateCommand(command string) error {
+// validateCommand performs safety validation on a command run in the working
+// directory dir
+func (ce *CommandExecutor) validateCommand(command, dir string) error {
 	if strings.TrimSpace(command) == "" {
 		return fmt.Errorf("command cannot be empty")
 	}
 	
 	// Parse command to get executable
-	parts := strings.Fields(command)
+	parts := splitWords(command)
 	if len(parts) == 0 {
 		return fmt.Errorf("invalid command format")
 	}
@@ -281,7 +483,7 @@ func (ce *CommandExecutor) validateCommand(command string) error {
 	executable := parts[0]
 	
 	// Check if executable exists and is executable
-	if !ce.isExecutableAvailable(executable) {
+	if !ce.isExecutableAvailable(executable, dir) {
 		return fmt.Errorf("executable not found or not executable: %s", executable)
 	}
 	
@@ -293,8 +495,36 @@ func (ce *CommandExecutor) validateCommand(command string) error {
 	return nil
 }
 
-// isExecutableAvailable checks if an executable is available in PATH or as absolute path
-func (ce *CommandExecutor) isExecutableAvailable(executable string) bool {
+// validateShellCommand validates a command for its shell dialect. Commands for
+// non-POSIX shells may use builtins, so only the shell interpreter is checked.
+func (ce *CommandExecutor) validateShellCommand(command, shell, dir string) error {
+	if normalizeShell(shell) == types.ShellPOSIX {
+		return ce.validateCommand(command, dir)
+	}
+
+	if strings.TrimSpace(command) == "" {
+		return fmt.Errorf("command cannot be empty")
+	}
+
+	interpreter := shellCommandArgs(command, shell)[0]
+	if !ce.isExecutableAvailable(interpreter, "") {
+		return fmt.Errorf("shell not found or not executable: %s", interpreter)
+	}
+
+	if err := ce.performSafetyChecks(command); err != nil {
+		return fmt.Errorf("safety check failed: %w", err)
+	}
+
+	return nil
+}
+
+// isExecutableAvailable checks if an executable is available in PATH, as absolute path
+// or as a path relative to the working directory dir, such as ./install.sh
+func (ce *CommandExecutor) isExecutableAvailable(executable, dir string) bool {
+	if dir != "" && strings.Contains(executable, "/") && !filepath.IsAbs(executable) {
+		executable = filepath.Join(dir, executable)
+	}
+	
 	// If it's an absolute path, check if it exists and is executable
 	if strings.HasPrefix(executable, "/") {
 		info, err := os.Stat(executable)
@@ -352,7 +582,7 @@ func (ce *CommandExecutor) GetTimeout() time.Duration {
 
 // IsCommandAvailable checks if a command is available for execution
 func (ce *CommandExecutor) IsCommandAvailable(command string) bool {
-	return ce.validateCommand(command) == nil
+	return ce.validateCommand(command, "") == nil
 }
 
 // validateCommandBeforeExecution performs final validation before executing a command
@@ -366,7 +596,7 @@ func (ce *CommandExecutor) validateCommandBeforeExecution(cmd *exec.Cmd) error {
 	}
 	
 	// Check if the resolved executable exists and is executable
-	if !ce.isExecutableAvailable(cmd.Path) {
+	if !ce.isExecutableAvailable(cmd.Path, cmd.Dir) {
 		// Provide detailed error information
 		ce.logger.Error("Executable validation failed", fmt.Errorf("executable not found or not executable"),
 			interfaces.LogField{Key: "path", Value: cmd.Path},
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -349,4 +350,39 @@ func TestTemplateEngineWithDockerProvider(t *testing.T) {
 			t.Logf("Docker action %s rendered successfully: %s", tt.action, renderedCommand)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestRenderTemplate_Concurrent(t *testing.T) {
+	templateEngine := template.NewTemplateEngine(&MockTemplateResourceValidator{}, &MockDefaultsGenerator{})
+	genericExecutor := NewGenericExecutor(NewCommandExecutor(&MockLogger{}, &MockResourceValidator{}), templateEngine, &MockLogger{}, &MockResourceValidator{})
+	provider := &types.ProviderData{Provider: types.ProviderInfo{Name: "apt"}}
+
+	// Actions of a batch render concurrently, each against its own saidata
+	software := []string{"nginx", "redis", "curl", "git"}
+	errs := make(chan error, len(software)*10)
+	done := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		for _, name := range software {
+			go func(name string) {
+				defer func() { done <- struct{}{} }()
+				saidata := &types.SoftwareData{
+					Metadata: types.Metadata{Name: name},
+					Packages: []types.Package{{Name: name + "-pkg"}},
+				}
+				rendered, err := genericExecutor.RenderTemplate("apt-get install -y {{sai_packages}}", saidata, provider)
+				if err != nil {
+					errs <- err
+				} else if rendered != "apt-get install -y "+name+"-pkg" {
+					errs <- fmt.Errorf("rendered %q for %s", rendered, name)
+				}
+			}(name)
+		}
+	}
+	for i := 0; i < len(software)*10; i++ {
+		<-done
+	}
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -2,7 +2,10 @@ package executor
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -10,8 +13,9 @@ import (
 	"sai/internal/types"
 )
 
-// MockLogger implements interfaces.Logger for testing
+// MockLogger implements interfaces.Logger for testing, safe for steps running in parallel
 type MockLogger struct {
+	mutex      sync.Mutex
 	debugCalls []string
 	infoCalls  []string
 	warnCalls  []string
@@ -19,22 +23,32 @@ type MockLogger struct {
 }
 
 func (m *MockLogger) Debug(msg string, fields ...interfaces.LogField) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	m.debugCalls = append(m.debugCalls, msg)
 }
 
 func (m *MockLogger) Info(msg string, fields ...interfaces.LogField) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	m.infoCalls = append(m.infoCalls, msg)
 }
 
 func (m *MockLogger) Warn(msg string, fields ...interfaces.LogField) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	m.warnCalls = append(m.warnCalls, msg)
 }
 
 func (m *MockLogger) Error(msg string, err error, fields ...interfaces.LogField) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	m.errorCalls = append(m.errorCalls, msg)
 }
 
 func (m *MockLogger) Fatal(msg string, err error, fields ...interfaces.LogField) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	m.errorCalls = append(m.errorCalls, msg)
 }
 
@@ -197,6 +211,30 @@ func TestExecuteCommand_NonExistentExecutable(t *testing.T) {
 	}
 }
 
+func TestExecuteCommand_RelativeExecutable(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	executor := NewCommandExecutor(logger, validator)
+	
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "install.sh"), []byte("#!/bin/sh\necho installed\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	
+	// Relative executables are looked up in the working directory
+	result, err := executor.ExecuteCommand(context.Background(), "./install.sh", interfaces.CommandOptions{Timeout: 10 * time.Second, WorkDir: dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(result.Output, "installed") {
+		t.Errorf("Expected output to contain 'installed', got '%s'", result.Output)
+	}
+	
+	if _, err := executor.ExecuteCommand(context.Background(), "./install.sh", interfaces.CommandOptions{Timeout: 10 * time.Second, WorkDir: t.TempDir()}); err == nil {
+		t.Error("Expected error for an executable missing from the working directory")
+	}
+}
+
 func TestExecuteWithRetry_Success(t *testing.T) {
 	logger := &MockLogger{}
 	validator := &MockResourceValidator{}
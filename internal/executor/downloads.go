@@ -0,0 +1,215 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sai/internal/download"
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// defaultDownloadMode is the permission of downloads declaring no mode
+const defaultDownloadMode os.FileMode = 0644
+
+// SetRequireChecksums refuses action downloads that declare no checksum, so binaries
+// and installer scripts only run once verified
+func (ge *GenericExecutor) SetRequireChecksums(require bool) {
+	ge.requireChecksums = require
+}
+
+// renderDownloads renders the templates of the downloads of an action and checks that
+// each declares a checksum when checksums are required
+func (ge *GenericExecutor) renderDownloads(
+	downloads []types.ActionDownload,
+	software string,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+) ([]types.ActionDownload, error) {
+	context := &interfaces.TemplateContext{
+		Software:  software,
+		Provider:  provider.Provider.Name,
+		Saidata:   saidata,
+		Variables: options.Variables,
+		Runtime:   options.Runtime,
+		Root:      ge.root,
+		Version:   options.Version,
+	}
+
+	var err error
+	render := func(value string) string {
+		if err != nil || !strings.Contains(value, "{{") {
+			return value
+		}
+		var rendered string
+		rendered, err = ge.render(value, context)
+		return strings.TrimSpace(rendered)
+	}
+
+	rendered := make([]types.ActionDownload, 0, len(downloads))
+	for _, file := range downloads {
+		file.URL = render(file.URL)
+		file.Path = rootPath(ge.root, render(file.Path))
+		file.Checksum = render(file.Checksum)
+		file.ChecksumURL = render(file.ChecksumURL)
+		file.Signature = render(file.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render download %s: %w", file.URL, err)
+		}
+		if file.URL == "" || file.Path == "" {
+			return nil, fmt.Errorf("download %q to %q: url and path cannot be empty", file.URL, file.Path)
+		}
+		if ge.requireChecksums && file.Checksum == "" && file.ChecksumURL == "" {
+			return nil, fmt.Errorf("refusing to download %s: no checksum is declared and security.require_checksums is enabled", file.URL)
+		}
+		rendered = append(rendered, file)
+	}
+	return rendered, nil
+}
+
+// fetchDownloads downloads the files of an action to a temporary directory, verifies
+// their checksum and signature, and only then moves them to their path. A file that
+// fails verification never reaches its path, so commands cannot run it.
+func (ge *GenericExecutor) fetchDownloads(ctx context.Context, downloads []types.ActionDownload) error {
+	if len(downloads) == 0 {
+		return nil
+	}
+
+	dir, err := os.MkdirTemp("", "sai-download-*")
+	if err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, file := range downloads {
+		mode := defaultDownloadMode
+		if file.Mode != "" {
+			parsed, err := strconv.ParseUint(file.Mode, 8, 32)
+			if err != nil {
+				return fmt.Errorf("invalid mode %q of download %s", file.Mode, file.URL)
+			}
+			mode = os.FileMode(parsed)
+		}
+
+		ge.logger.Info("Downloading file",
+			interfaces.LogField{Key: "url", Value: file.URL},
+			interfaces.LogField{Key: "path", Value: file.Path},
+		)
+
+		tmpPath := filepath.Join(dir, fmt.Sprintf("%d-%s", i, filepath.Base(file.Path)))
+		if err := download.FetchVerified(ctx, file.URL, tmpPath, file.Checksum, file.ChecksumURL); err != nil {
+			return err
+		}
+		if file.Signature != "" {
+			if err := download.VerifySignature(ctx, tmpPath, file.Signature); err != nil {
+				return err
+			}
+		}
+		if err := download.Install(tmpPath, file.Path, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderCheckouts renders the templates of the git checkouts of an action
+func (ge *GenericExecutor) renderCheckouts(
+	checkouts []types.ActionCheckout,
+	software string,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+) ([]types.ActionCheckout, error) {
+	context := &interfaces.TemplateContext{
+		Software:  software,
+		Provider:  provider.Provider.Name,
+		Saidata:   saidata,
+		Variables: options.Variables,
+		Runtime:   options.Runtime,
+		Root:      ge.root,
+		Version:   options.Version,
+	}
+
+	var err error
+	render := func(value string) string {
+		if err != nil || !strings.Contains(value, "{{") {
+			return value
+		}
+		var rendered string
+		rendered, err = ge.render(value, context)
+		return strings.TrimSpace(rendered)
+	}
+
+	rendered := make([]types.ActionCheckout, 0, len(checkouts))
+	for _, checkout := range checkouts {
+		checkout.Repository = render(checkout.Repository)
+		checkout.Path = rootPath(ge.root, render(checkout.Path))
+		checkout.Ref = render(checkout.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render checkout %s: %w", checkout.Repository, err)
+		}
+		if checkout.Repository == "" || checkout.Path == "" {
+			return nil, fmt.Errorf("checkout %q to %q: repository and path cannot be empty", checkout.Repository, checkout.Path)
+		}
+		rendered = append(rendered, checkout)
+	}
+	return rendered, nil
+}
+
+// fetchCheckouts clones the git repositories of an action at their ref
+func (ge *GenericExecutor) fetchCheckouts(ctx context.Context, checkouts []types.ActionCheckout) error {
+	for _, checkout := range checkouts {
+		ge.logger.Info("Cloning repository",
+			interfaces.LogField{Key: "repository", Value: checkout.Repository},
+			interfaces.LogField{Key: "ref", Value: checkout.Ref},
+			interfaces.LogField{Key: "path", Value: checkout.Path},
+		)
+		if err := download.Clone(ctx, download.Checkout{
+			Repository:  checkout.Repository,
+			Path:        checkout.Path,
+			Ref:         checkout.Ref,
+			Submodules:  checkout.Submodules,
+			FullHistory: checkout.FullHistory,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describeCheckout returns the dry run description of a git checkout
+func describeCheckout(checkout types.ActionCheckout) string {
+	ref := checkout.Ref
+	if ref == "" {
+		ref = "default branch"
+	}
+	var details []string
+	if !checkout.FullHistory {
+		details = append(details, "shallow")
+	}
+	if checkout.Submodules {
+		details = append(details, "with submodules")
+	}
+	description := fmt.Sprintf("Clone: %s (%s) to %s", checkout.Repository, ref, checkout.Path)
+	if len(details) > 0 {
+		description += " (" + strings.Join(details, ", ") + ")"
+	}
+	return description
+}
+
+// describeDownload returns the dry run description of a download
+func describeDownload(file types.ActionDownload) string {
+	verification := "checksum verified"
+	if file.Checksum == "" && file.ChecksumURL == "" {
+		verification = "not verified, no checksum declared"
+	}
+	if file.Signature != "" {
+		verification += ", signature verified"
+	}
+	return fmt.Sprintf("Download: %s to %s (%s)", file.URL, file.Path, verification)
+}
@@ -3,9 +3,15 @@ package executor
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"sai/internal/artifacts"
+	"sai/internal/audit"
+	"sai/internal/errors"
 	"sai/internal/interfaces"
 	"sai/internal/types"
 )
@@ -16,6 +22,44 @@ type GenericExecutor struct {
 	templateEngine  interfaces.TemplateEngine
 	logger          interfaces.Logger
 	validator       interfaces.ResourceValidator
+
+	// Read-only mode: only actions accepted by readOnlyAllowed are executed
+	readOnly        bool
+	readOnlyAllowed func(action string) bool
+
+	// Adaptive timeouts, nil to use the provider timeouts as they are
+	adaptive *AdaptiveTimeouts
+
+	// Temporary artifact tracking, nil to leave temporary files alone
+	artifacts *artifacts.Store
+
+	// Resolves the absolute path commands of a provider executable are pinned to, nil
+	// to run them as found in PATH
+	executablePath func(provider *types.ProviderData) string
+
+	// Refuse action downloads that declare no checksum
+	requireChecksums bool
+
+	// Build directory and parallel jobs of the sources of actions setting build, see
+	// SetBuildCache
+	buildCacheDir string
+	buildJobs     int
+
+	// Directory of the checkpoints of multi-step actions, see SetCheckpointDir
+	checkpointDir string
+
+	// Alternate root filesystem commands act on, empty for the running system
+	root string
+
+	// Operating system and package manager exposed to templates, see SetHost
+	host *host
+
+	// Arguments added to the commands of actions by provider then action, see SetExtraArgs
+	extraArgs map[string]map[string][]string
+
+	// Guards the safety mode of the template engine, switched off while an action is
+	// validated. Renderings otherwise run concurrently, each against its own context.
+	templateMutex sync.RWMutex
 }
 
 // NewGenericExecutor creates a new generic executor
@@ -33,6 +77,57 @@ func NewGenericExecutor(
 	}
 }
 
+// SetReadOnly enables or disables read-only mode. In read-only mode only actions for
+// which allowed returns true are executed; every other action is refused unless it
+// is a dry run, regardless of which command requested it.
+func (ge *GenericExecutor) SetReadOnly(readOnly bool, allowed func(action string) bool) {
+	ge.readOnly = readOnly
+	ge.readOnlyAllowed = allowed
+}
+
+// SetAdaptiveTimeouts enables timeouts adapted to download sizes and to the durations
+// of earlier executions. Passing nil restores the provider timeouts.
+func (ge *GenericExecutor) SetAdaptiveTimeouts(adaptive *AdaptiveTimeouts) {
+	ge.adaptive = adaptive
+}
+
+// SetArtifactStore enables tracking of the temporary files of every execution. They are
+// removed when the execution succeeds and kept for debugging when it fails.
+func (ge *GenericExecutor) SetArtifactStore(store *artifacts.Store) {
+	ge.artifacts = store
+}
+
+// SetExecutableResolver pins the provider executable of rendered commands to the
+// absolute path returned by resolve, so an executable shadowing it in PATH or the
+// current directory is never run. The path is also exposed to templates as .Executable.
+func (ge *GenericExecutor) SetExecutableResolver(resolve func(provider *types.ProviderData) string) {
+	ge.executablePath = resolve
+}
+
+// IsReadOnly returns whether read-only mode is enabled
+func (ge *GenericExecutor) IsReadOnly() bool {
+	return ge.readOnly
+}
+
+// checkReadOnly returns an error if an action may not be executed in read-only mode
+func (ge *GenericExecutor) checkReadOnly(action string, software string) error {
+	if !ge.readOnly || (ge.readOnlyAllowed != nil && ge.readOnlyAllowed(action)) {
+		return nil
+	}
+
+	ge.logger.Warn("Refusing system-changing action in read-only mode",
+		interfaces.LogField{Key: "action", Value: action},
+		interfaces.LogField{Key: "software", Value: software},
+	)
+	return errors.NewReadOnlyViolationError(strings.TrimSpace(action + " " + software))
+}
+
+// systemChanging reports whether action may change the system: never in read-only mode,
+// where only the allowed actions run, otherwise unless read-only mode would allow it
+func (ge *GenericExecutor) systemChanging(action string) bool {
+	return !ge.readOnly && (ge.readOnlyAllowed == nil || !ge.readOnlyAllowed(action))
+}
+
 // Execute runs a provider action with the given options
 func (ge *GenericExecutor) Execute(
 	ctx context.Context,
@@ -44,6 +139,9 @@ func (ge *GenericExecutor) Execute(
 ) (*interfaces.ExecutionResult, error) {
 	startTime := time.Now()
 	
+	// Record the commands of the action under its provider, name and software
+	ctx = audit.WithOrigin(ctx, audit.Origin{Provider: provider.Provider.Name, Action: action, Software: software})
+	
 	ge.logger.Info("Executing provider action",
 		interfaces.LogField{Key: "provider", Value: provider.Provider.Name},
 		interfaces.LogField{Key: "action", Value: action},
@@ -73,24 +171,210 @@ func (ge *GenericExecutor) Execute(
 		}, err
 	}
 	
+	// Share one runtime context between the steps of this execution
+	if options.Runtime == nil {
+		options.Runtime = NewRuntimeContext(provider, saidata)
+		options.Runtime.Workflow = options.Workflow.Values()
+	}
+	
+	// Resolve the extra arguments of the action, refusing conflicting ones
+	extraArgs, err := ge.actionArgs(provider, action, saidata)
+	if err != nil {
+		return &interfaces.ExecutionResult{
+			Success:  false,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+			Provider: provider.Provider.Name,
+		}, err
+	}
+	options.ExtraArgs = extraArgs
+	
 	// Handle dry-run mode
 	if options.DryRun {
 		return ge.DryRun(ctx, provider, action, software, saidata, options)
 	}
 	
+	// Enforce read-only mode for everything that would actually run
+	if err := ge.checkReadOnly(action, software); err != nil {
+		return &interfaces.ExecutionResult{
+			Success:  false,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+			Provider: provider.Provider.Name,
+		}, err
+	}
+	
+	// Extract downloads into a directory private to this execution: a fresh one no other
+	// user can plant files in. An untracked one is removed once the execution ends.
+	var run *artifacts.Run
+	var extractDir string
+	tracked := false
+	if options.Runtime.ExtractDir == "" {
+		extractDir, err = os.MkdirTemp("", ExtractDirPattern)
+		if err != nil {
+			err = fmt.Errorf("failed to create extraction directory: %w", err)
+			return &interfaces.ExecutionResult{
+				Success:  false,
+				Error:    err,
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+				Provider: provider.Provider.Name,
+			}, err
+		}
+		options.Runtime.ExtractDir = extractDir
+		defer func() {
+			if !tracked {
+				os.RemoveAll(extractDir)
+			}
+		}()
+	}
+	
+	// Stretch the timeout for large downloads and actions that were slow before. Only
+	// system-changing actions download, so only they ask the server for the size.
+	historyKey := HistoryKey(provider.Provider.Name, action, software)
+	var downloadSize int64
+	if ge.adaptive != nil {
+		sizeURL := ""
+		if ge.systemChanging(action) {
+			sizeURL = options.Runtime.ResolvedURL
+		}
+		var timeout time.Duration
+		timeout, downloadSize = ge.adaptive.Timeout(ctx, historyKey, providerAction.GetTimeout(), sizeURL)
+		if timeout > providerAction.GetTimeout() {
+			ge.logger.Debug("Using adaptive timeout",
+				interfaces.LogField{Key: "action", Value: action},
+				interfaces.LogField{Key: "timeout", Value: timeout},
+				interfaces.LogField{Key: "download_size", Value: downloadSize},
+			)
+		}
+		providerAction.Timeout = int((timeout + time.Second - 1) / time.Second)
+		if options.Timeout > 0 && options.Timeout < timeout {
+			options.Timeout = timeout
+		}
+		options.StallTimeout = ge.adaptive.StallTimeout
+	}
+	
+	// Download and verify files, and clone repositories, before any command can run
+	// them. Nothing changed yet, so a failure is returned without rolling back.
+	downloads, err := ge.renderDownloads(providerAction.Downloads, software, saidata, provider, options)
+	var checkouts []types.ActionCheckout
+	if err == nil {
+		checkouts, err = ge.renderCheckouts(providerAction.Checkouts, software, saidata, provider, options)
+	}
+	if err == nil {
+		downloadCtx, cancel := context.WithTimeout(ctx, providerAction.GetTimeout())
+		err = ge.fetchDownloads(downloadCtx, downloads)
+		if err == nil {
+			err = ge.fetchCheckouts(downloadCtx, checkouts)
+		}
+		cancel()
+	}
+	if err != nil {
+		return &interfaces.ExecutionResult{
+			Success:  false,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+			Provider: provider.Provider.Name,
+		}, err
+	}
+	
+	// Track the temporary files this execution creates, so the extraction directory of
+	// a failed run is kept until it is pruned. Read-only mode writes no run manifest.
+	if ge.artifacts != nil && !ge.readOnly {
+		var err error
+		if run, err = ge.artifacts.Begin(action, software); err == nil {
+			err = ge.artifacts.Track(run, extractDir)
+		}
+		// The store ignores directories outside of its temporary directory
+		tracked = err == nil && extractDir != "" && len(run.Paths) > 0
+		if err != nil {
+			ge.logger.Warn("Failed to track temporary files",
+				interfaces.LogField{Key: "error", Value: err},
+			)
+			run = nil
+		}
+	}
+	
+	// Record the state of the files and services of the software to report the changes
+	// of the action
+	snapshot := ge.snapshotResources(ctx, action, provider, saidata)
+	
 	// Execute the action
 	var result *interfaces.ExecutionResult
-	var err error
 	
-	if providerAction.HasSteps() {
+	// Build the sources first, so the commands of the action can rely on the installed
+	// build
+	var buildOutput string
+	builtFirst := false
+	if providerAction.Build {
+		buildOutput, err = ge.buildSources(ctx, &providerAction, saidata, options)
+		builtFirst = err == nil && providerAction.HasCommands()
+		if err != nil {
+			result = &interfaces.ExecutionResult{
+				Success:  false,
+				Output:   buildOutput,
+				Error:    err,
+				ExitCode: 1,
+			}
+		} else if !providerAction.HasCommands() {
+			result = &interfaces.ExecutionResult{
+				Success:  true,
+				Output:   buildOutput,
+				ExitCode: 0,
+			}
+		}
+	}
+	
+	switch {
+	case result != nil:
+		// The build failed or was the whole action
+	case providerAction.HasSteps():
+		if providerAction.MaxParallel > 0 {
+			options.MaxParallelSteps = providerAction.MaxParallel
+		}
+		if options.Checkpoint == "" {
+			options.Checkpoint = ge.checkpointPath(provider, action, software)
+		}
 		result, err = ge.ExecuteSteps(ctx, providerAction.Steps, saidata, provider, options)
-	} else {
+	case providerAction.IsScript():
+		result, err = ge.executeScriptAction(ctx, &providerAction, software, saidata, provider, options)
+	default:
 		result, err = ge.executeSingleAction(ctx, &providerAction, software, saidata, provider, options)
 	}
 	
+	if result != nil && builtFirst {
+		result.Output = buildOutput + result.Output
+	}
 	if result != nil {
 		result.Duration = time.Since(startTime)
 		result.Provider = provider.Provider.Name
+		result.Runtime = options.Runtime
+		result.Changes = append(result.Changes, ParsePackageChanges(result.Output)...)
+		result.Changes = append(result.Changes, ge.detectChanges(ctx, snapshot)...)
+	}
+	
+	// Turn known package manager failures into typed errors, so that suggestions are
+	// targeted and the recovery strategy fits the failure
+	if result != nil && !result.Success {
+		if classified := classifyFailure(provider, &providerAction, result); classified != nil {
+			result.Error = classified
+			err = classified
+		}
+	}
+	
+	if err == nil && ge.adaptive != nil && !ge.readOnly && result != nil && result.Success {
+		ge.adaptive.Record(historyKey, time.Since(startTime), downloadSize)
+	}
+	
+	if run != nil {
+		if _, cleanupErr := ge.artifacts.Finish(run, err == nil && result != nil && result.Success); cleanupErr != nil {
+			ge.logger.Warn("Failed to clean up temporary files",
+				interfaces.LogField{Key: "error", Value: cleanupErr},
+			)
+		}
 	}
 	
 	// Handle rollback on failure
@@ -114,6 +398,31 @@ func (ge *GenericExecutor) Execute(
 	return result, err
 }
 
+// classifyFailure recognizes known package manager failures in the output of a failed
+// execution, or returns nil. Failures already reported as typed errors are kept.
+func classifyFailure(provider *types.ProviderData, action *types.Action, result *interfaces.ExecutionResult) *errors.SAIError {
+	if _, typed := result.Error.(*errors.SAIError); typed {
+		return nil
+	}
+
+	command := strings.Join(result.Commands, " && ")
+	classified := errors.ClassifyCommandError(provider.Provider.Name, command, result.ExitCode, result.Output)
+	if classified == nil && result.Error != nil {
+		classified = errors.ClassifyCommandError(provider.Provider.Name, command, result.ExitCode, result.Error.Error())
+	}
+	if classified == nil {
+		return nil
+	}
+
+	if action.Rollback != "" {
+		classified.WithContext("rollback_available", true)
+	}
+	if result.Error != nil {
+		classified.Cause = result.Error
+	}
+	return classified
+}
+
 // ValidateAction validates that an action can be executed
 func (ge *GenericExecutor) ValidateAction(
 	provider *types.ProviderData,
@@ -145,6 +454,13 @@ func (ge *GenericExecutor) ValidateAction(
 		return fmt.Errorf("action %s has no valid execution method", action)
 	}
 	
+	// Validate step dependencies
+	if hasStepDependencies(providerAction.Steps) {
+		if _, err := stepDependencies(providerAction.Steps); err != nil {
+			return fmt.Errorf("invalid steps in action %s: %w", action, err)
+		}
+	}
+	
 	// Validate template if present
 	if providerAction.Template != "" {
 		if err := ge.templateEngine.ValidateTemplate(providerAction.Template); err != nil {
@@ -159,21 +475,27 @@ func (ge *GenericExecutor) ValidateAction(
 	
 	// Try to render the template to see if it resolves correctly
 	if providerAction.Template != "" {
-		context := &interfaces.TemplateContext{
-			Software:  software,
-			Provider:  provider.Provider.Name,
-			Saidata:   saidata,
-		}
-		
-		// Set saidata context in template engine
-		ge.templateEngine.SetSaidata(saidata)
+		context := ge.withHost(&interfaces.TemplateContext{
+			Software: software,
+			Provider: provider.Provider.Name,
+			Saidata:  saidata,
+			Root:     ge.root,
+		})
 		
-		// First try with safety mode disabled to check basic template syntax
+		// First try with safety mode disabled to check basic template syntax, then with
+		// safety mode enabled to catch function errors
+		ge.templateMutex.Lock()
 		ge.templateEngine.SetSafetyMode(false)
 		rendered, err := ge.templateEngine.Render(providerAction.Template, context)
+		var safetyErr error
+		if err == nil {
+			ge.templateEngine.SetSafetyMode(true)
+			_, safetyErr = ge.templateEngine.Render(providerAction.Template, context)
+		}
+		ge.templateEngine.SetSafetyMode(true)
+		ge.templateMutex.Unlock()
 		
 		if err != nil {
-			ge.templateEngine.SetSafetyMode(true) // Re-enable safety mode
 			ge.logger.Debug("Template rendering failed during validation", 
 				interfaces.LogField{Key: "action", Value: action},
 				interfaces.LogField{Key: "provider", Value: provider.Provider.Name},
@@ -195,10 +517,6 @@ func (ge *GenericExecutor) ValidateAction(
 			return fmt.Errorf("template rendering failed for action %s: %w", action, err)
 		}
 		
-		// Now try with safety mode enabled to catch function errors
-		ge.templateEngine.SetSafetyMode(true)
-		_, safetyErr := ge.templateEngine.Render(providerAction.Template, context)
-		
 		if safetyErr != nil {
 			ge.logger.Debug("Template safety validation failed",
 				interfaces.LogField{Key: "action", Value: action},
@@ -210,18 +528,6 @@ func (ge *GenericExecutor) ValidateAction(
 			return fmt.Errorf("template safety validation failed for action %s: %w", action, safetyErr)
 		}
 		
-		// Check if the rendered template contains error indicators
-		if strings.Contains(rendered, "error:") {
-			ge.logger.Debug("Template contains error indicators",
-				interfaces.LogField{Key: "action", Value: action},
-				interfaces.LogField{Key: "provider", Value: provider.Provider.Name},
-				interfaces.LogField{Key: "software", Value: software},
-				interfaces.LogField{Key: "template", Value: providerAction.Template},
-				interfaces.LogField{Key: "rendered", Value: rendered},
-			)
-			return fmt.Errorf("template resolution failed for action %s: %s", action, rendered)
-		}
-		
 		ge.logger.Debug("Template rendered successfully during validation",
 			interfaces.LogField{Key: "action", Value: action},
 			interfaces.LogField{Key: "provider", Value: provider.Provider.Name},
@@ -290,6 +596,7 @@ func (ge *GenericExecutor) DryRun(
 	options interfaces.ExecuteOptions,
 ) (*interfaces.ExecutionResult, error) {
 	startTime := time.Now()
+	ctx = audit.WithOrigin(ctx, audit.Origin{Provider: provider.Provider.Name, Action: action, Software: software})
 	
 	ge.logger.Info("DRY RUN: Showing what would be executed",
 		interfaces.LogField{Key: "provider", Value: provider.Provider.Name},
@@ -297,14 +604,68 @@ func (ge *GenericExecutor) DryRun(
 		interfaces.LogField{Key: "software", Value: software},
 	)
 	
+	if options.Runtime == nil {
+		options.Runtime = NewRuntimeContext(provider, saidata)
+		options.Runtime.Workflow = options.Workflow.Values()
+	}
+	// Executions create their extraction directory when they run
+	if options.Runtime.ExtractDir == "" {
+		options.Runtime.ExtractDir = filepath.Join(os.TempDir(), ExtractDirPattern)
+	}
+	if options.ExtraArgs == nil {
+		extraArgs, err := ge.actionArgs(provider, action, saidata)
+		if err != nil {
+			return &interfaces.ExecutionResult{
+				Success:  false,
+				Error:    err,
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+				Provider: provider.Provider.Name,
+			}, err
+		}
+		options.ExtraArgs = extraArgs
+	}
+	
 	providerAction := provider.Actions[action]
 	var commands []string
 	var output strings.Builder
 	
-	if providerAction.HasSteps() {
+	downloads, err := ge.renderDownloads(providerAction.Downloads, software, saidata, provider, options)
+	if err != nil {
+		return &interfaces.ExecutionResult{
+			Success:  false,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+			Provider: provider.Provider.Name,
+		}, err
+	}
+	for _, file := range downloads {
+		output.WriteString(describeDownload(file) + "\n")
+	}
+	checkouts, err := ge.renderCheckouts(providerAction.Checkouts, software, saidata, provider, options)
+	if err != nil {
+		return &interfaces.ExecutionResult{
+			Success:  false,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+			Provider: provider.Provider.Name,
+		}, err
+	}
+	for _, checkout := range checkouts {
+		output.WriteString(describeCheckout(checkout) + "\n")
+	}
+	if providerAction.Build {
+		output.WriteString(ge.planBuilds(saidata, options))
+	}
+	
+	if !providerAction.HasCommands() {
+		// The action only builds sources
+	} else if providerAction.HasSteps() {
 		// Render each step
 		for i, step := range providerAction.Steps {
-			rendered, err := ge.renderCommand(step.Command, software, saidata, provider, options)
+			rendered, err := ge.renderStepPreview(step, software, saidata, provider, options)
 			if err != nil {
 				return &interfaces.ExecutionResult{
 					Success:  false,
@@ -320,7 +681,11 @@ func (ge *GenericExecutor) DryRun(
 	} else {
 		// Render single command
 		command := providerAction.GetCommand()
-		rendered, err := ge.renderCommand(command, software, saidata, provider, options)
+		render := ge.renderCommand
+		if providerAction.IsScript() {
+			render = ge.renderTemplate
+		}
+		rendered, err := render(command, software, saidata, provider, options)
 		if err != nil {
 			return &interfaces.ExecutionResult{
 				Success:  false,
@@ -331,7 +696,16 @@ func (ge *GenericExecutor) DryRun(
 			}, err
 		}
 		commands = append(commands, rendered)
-		output.WriteString(fmt.Sprintf("Command: %s\n", rendered))
+		if providerAction.IsScript() {
+			script, _ := BuildScript(rendered, providerAction.Interpreter)
+			output.WriteString(fmt.Sprintf("Script:\n%s\n", script))
+		} else {
+			output.WriteString(fmt.Sprintf("Command: %s\n", rendered))
+		}
+	}
+	
+	for _, command := range commands {
+		ge.commandExecutor.audit(ctx, command, nil, nil, true)
 	}
 	
 	return &interfaces.ExecutionResult{
@@ -341,6 +715,7 @@ func (ge *GenericExecutor) DryRun(
 		Duration: time.Since(startTime),
 		Commands: commands,
 		Provider: provider.Provider.Name,
+		Runtime:  options.Runtime,
 	}, nil
 }
 
@@ -380,9 +755,17 @@ func (ge *GenericExecutor) RenderTemplate(
 		Software: "", // Will be set by caller if needed
 		Provider: provider.Provider.Name,
 		Saidata:  saidata,
+		Root:     ge.root,
 	}
 	
-	return ge.templateEngine.Render(templateStr, context)
+	return ge.render(templateStr, context)
+}
+
+// render renders a template against the saidata of its context and the host
+func (ge *GenericExecutor) render(templateStr string, context *interfaces.TemplateContext) (string, error) {
+	ge.templateMutex.RLock()
+	defer ge.templateMutex.RUnlock()
+	return ge.templateEngine.Render(templateStr, ge.withHost(context))
 }
 
 // ExecuteCommand executes a single command with proper error handling
@@ -391,10 +774,22 @@ func (ge *GenericExecutor) ExecuteCommand(
 	command string,
 	options interfaces.CommandOptions,
 ) (*interfaces.CommandResult, error) {
+	// Ad-hoc commands are only allowed in read-only mode when marked as read-only
+	if ge.readOnly && !options.ReadOnly {
+		err := errors.NewReadOnlyViolationError(command)
+		return &interfaces.CommandResult{
+			Command:  command,
+			Error:    err,
+			ExitCode: 1,
+		}, err
+	}
+	
 	return ge.commandExecutor.ExecuteCommand(ctx, command, options)
 }
 
-// ExecuteSteps executes multiple steps in sequence
+// ExecuteSteps executes multiple steps in sequence. When any step declares depends_on,
+// the steps run as a dependency graph instead, independent steps in parallel. Steps
+// completed by an earlier failed run are skipped when options set a checkpoint.
 func (ge *GenericExecutor) ExecuteSteps(
 	ctx context.Context,
 	steps []types.Step,
@@ -407,100 +802,46 @@ func (ge *GenericExecutor) ExecuteSteps(
 	var allCommands []string
 	var changes []interfaces.Change
 	
+	if options.Runtime == nil {
+		options.Runtime = NewRuntimeContext(provider, saidata)
+		options.Runtime.Workflow = options.Workflow.Values()
+	}
+	
+	checkpoint := ge.loadCheckpoint(options)
+	if hasStepDependencies(steps) {
+		result, err := ge.executeStepGraph(ctx, steps, saidata, provider, options, checkpoint)
+		ge.finishCheckpoint(checkpoint, result)
+		return result, err
+	}
+	
+	var runtimeMutex sync.RWMutex
 	for i, step := range steps {
-		ge.logger.Debug("Executing step",
-			interfaces.LogField{Key: "step", Value: i + 1},
-			interfaces.LogField{Key: "name", Value: step.Name},
-		)
-		
-		// Check step condition if present
-		if step.Condition != "" {
-			shouldExecute, err := ge.evaluateCondition(step.Condition, saidata, provider)
-			if err != nil {
-				ge.logger.Warn("Failed to evaluate step condition",
-					interfaces.LogField{Key: "step", Value: i + 1},
-					interfaces.LogField{Key: "condition", Value: step.Condition},
-					interfaces.LogField{Key: "error", Value: err},
-				)
-			}
-			if !shouldExecute {
-				ge.logger.Debug("Skipping step due to condition",
-					interfaces.LogField{Key: "step", Value: i + 1},
-				)
-				continue
-			}
-		}
-		
-		// Render step command
-		rendered, err := ge.renderCommand(step.Command, "", saidata, provider, options)
-		if err != nil {
-			if step.IgnoreFailure {
-				ge.logger.Warn("Step command rendering failed, ignoring",
-					interfaces.LogField{Key: "step", Value: i + 1},
-					interfaces.LogField{Key: "error", Value: err},
-				)
-				continue
-			}
-			return &interfaces.ExecutionResult{
-				Success:  false,
-				Output:   allOutput.String(),
-				Error:    fmt.Errorf("failed to render step %d command: %w", i+1, err),
-				ExitCode: 1,
-				Duration: time.Since(startTime),
-				Commands: allCommands,
-				Provider: provider.Provider.Name,
-				Changes:  changes,
-			}, err
-		}
-		
-		allCommands = append(allCommands, rendered)
-		
-		// Execute step command
-		stepTimeout := options.Timeout
-		if step.Timeout > 0 {
-			stepTimeout = time.Duration(step.Timeout) * time.Second
+		outcome := ge.runStep(ctx, i, step, saidata, provider, options, &runtimeMutex, checkpoint)
+		changes = append(changes, outcome.changes...)
+		if outcome.command != "" {
+			allCommands = append(allCommands, outcome.command)
 		}
-		
-		cmdOptions := interfaces.CommandOptions{
-			Timeout: stepTimeout,
-			WorkDir: options.WorkDir,
-			Env:     options.Env,
-			Verbose: options.Verbose,
-		}
-		
-		result, err := ge.commandExecutor.ExecuteCommand(ctx, rendered, cmdOptions)
-		if result != nil {
-			allOutput.WriteString(result.Output)
+		if outcome.ran {
+			allOutput.WriteString(outcome.output)
 			allOutput.WriteString("\n")
 		}
 		
-		if err != nil || (result != nil && result.ExitCode != 0) {
-			if step.IgnoreFailure {
-				ge.logger.Warn("Step failed, ignoring",
-					interfaces.LogField{Key: "step", Value: i + 1},
-					interfaces.LogField{Key: "error", Value: err},
-				)
-				continue
-			}
-			
+		if outcome.failure != nil {
 			return &interfaces.ExecutionResult{
 				Success:  false,
 				Output:   allOutput.String(),
-				Error:    fmt.Errorf("step %d failed: %w", i+1, err),
-				ExitCode: result.ExitCode,
+				Error:    outcome.failure,
+				ExitCode: outcome.exitCode,
 				Duration: time.Since(startTime),
 				Commands: allCommands,
 				Provider: provider.Provider.Name,
 				Changes:  changes,
-			}, err
+				Runtime:  options.Runtime,
+			}, outcome.err
 		}
-		
-		ge.logger.Debug("Step completed successfully",
-			interfaces.LogField{Key: "step", Value: i + 1},
-		)
 	}
 	
-	return &interfaces.ExecutionResult{
+	result := &interfaces.ExecutionResult{
 		Success:  true,
 		Output:   allOutput.String(),
 		ExitCode: 0,
@@ -508,7 +849,83 @@ func (ge *GenericExecutor) ExecuteSteps(
 		Commands: allCommands,
 		Provider: provider.Provider.Name,
 		Changes:  changes,
-	}, nil
+		Runtime:  options.Runtime,
+	}
+	ge.finishCheckpoint(checkpoint, result)
+	return result, nil
+}
+
+// ExecuteScript renders a script template for a software and runs it with the shell,
+// such as the hooks run around actions. A dry run only renders it.
+func (ge *GenericExecutor) ExecuteScript(
+	ctx context.Context,
+	script string,
+	software string,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+) (*interfaces.ExecutionResult, error) {
+	startTime := time.Now()
+
+	rendered, err := ge.renderTemplate(script, software, saidata, provider, options)
+	if err != nil {
+		err = fmt.Errorf("failed to render script: %w", err)
+		return &interfaces.ExecutionResult{
+			Success:  false,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+			Provider: provider.Provider.Name,
+		}, err
+	}
+
+	if options.DryRun {
+		return &interfaces.ExecutionResult{
+			Success:  true,
+			Output:   fmt.Sprintf("DRY RUN: %s", rendered),
+			Commands: []string{rendered},
+			Duration: time.Since(startTime),
+			Provider: provider.Provider.Name,
+		}, nil
+	}
+
+	if ge.readOnly {
+		err := errors.NewReadOnlyViolationError(rendered)
+		return &interfaces.ExecutionResult{
+			Success:  false,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+			Commands: []string{rendered},
+			Provider: provider.Provider.Name,
+		}, err
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = (&types.Action{}).GetTimeout()
+	}
+	result, err := ge.commandExecutor.ExecuteScript(ctx, rendered, "", interfaces.CommandOptions{
+		Timeout:      timeout,
+		WorkDir:      options.WorkDir,
+		Env:          options.Env,
+		Verbose:      options.Verbose,
+		StallTimeout: options.StallTimeout,
+		Output:       options.Output,
+	})
+	if err == nil && result.ExitCode != 0 {
+		err = result.Error
+	}
+
+	return &interfaces.ExecutionResult{
+		Success:  err == nil,
+		Output:   result.Output,
+		Error:    err,
+		ExitCode: result.ExitCode,
+		Duration: time.Since(startTime),
+		Commands: []string{rendered},
+		Provider: provider.Provider.Name,
+	}, err
 }
 
 // executeSingleAction executes a single action (non-step based)
@@ -548,10 +965,13 @@ func (ge *GenericExecutor) executeSingleAction(
 	
 	// Set up command options
 	cmdOptions := interfaces.CommandOptions{
-		Timeout: action.GetTimeout(),
-		WorkDir: options.WorkDir,
-		Env:     options.Env,
-		Verbose: options.Verbose,
+		Timeout:      action.GetTimeout(),
+		WorkDir:      options.WorkDir,
+		Env:          options.Env,
+		Verbose:      options.Verbose,
+		Shell:        provider.Provider.GetShell(),
+		StallTimeout: options.StallTimeout,
+		Output:       options.Output,
 	}
 	
 	// Log command execution attempt
@@ -591,7 +1011,7 @@ func (ge *GenericExecutor) executeSingleAction(
 	
 	// Validate result if validation is configured
 	if err == nil && action.Validation != nil {
-		if validationErr := ge.validateActionResult(result, action.Validation); validationErr != nil {
+		if validationErr := ge.validateActionResult(result, action.Validation, saidata, provider, options.Runtime); validationErr != nil {
 			err = fmt.Errorf("action validation failed: %w", validationErr)
 		}
 	}
@@ -609,7 +1029,102 @@ func (ge *GenericExecutor) executeSingleAction(
 	return executionResult, err
 }
 
-// renderCommand renders a command template with the current context
+// executeScriptAction executes a multi-line script action from a temporary script file
+func (ge *GenericExecutor) executeScriptAction(
+	ctx context.Context,
+	action *types.Action,
+	software string,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+) (*interfaces.ExecutionResult, error) {
+	startTime := time.Now()
+	
+	if ge.root != "" && !usesRoot(action.Script) {
+		err := fmt.Errorf("script actions cannot act on the alternate root %s unless they use {{.Root}}", ge.root)
+		return &interfaces.ExecutionResult{
+			Success:  false,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+			Provider: provider.Provider.Name,
+		}, err
+	}
+	
+	rendered, err := ge.renderTemplate(action.Script, software, saidata, provider, options)
+	if err != nil {
+		return &interfaces.ExecutionResult{
+			Success:  false,
+			Error:    fmt.Errorf("failed to render script: %w", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+			Provider: provider.Provider.Name,
+		}, err
+	}
+	
+	workDir := options.WorkDir
+	if action.WorkDir != "" {
+		workDir, err = ge.renderTemplate(action.WorkDir, software, saidata, provider, options)
+		if err != nil {
+			return &interfaces.ExecutionResult{
+				Success:  false,
+				Error:    fmt.Errorf("failed to render script working directory: %w", err),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+				Provider: provider.Provider.Name,
+			}, err
+		}
+	}
+	
+	cmdOptions := interfaces.CommandOptions{
+		Timeout:      action.GetTimeout(),
+		WorkDir:      strings.TrimSpace(workDir),
+		Env:          options.Env,
+		Verbose:      options.Verbose,
+		Shell:        provider.Provider.GetShell(),
+		StallTimeout: options.StallTimeout,
+		Output:       options.Output,
+	}
+	
+	ge.logger.Info("Executing script",
+		interfaces.LogField{Key: "software", Value: software},
+		interfaces.LogField{Key: "provider", Value: provider.Provider.Name},
+		interfaces.LogField{Key: "workdir", Value: cmdOptions.WorkDir},
+	)
+	
+	result, err := ge.commandExecutor.ExecuteScript(ctx, rendered, action.Interpreter, cmdOptions)
+	if err == nil && result.ExitCode != 0 {
+		err = result.Error
+	}
+	
+	if err != nil {
+		ge.logger.Error("Script execution failed", err,
+			interfaces.LogField{Key: "software", Value: software},
+			interfaces.LogField{Key: "provider", Value: provider.Provider.Name},
+		)
+	}
+	
+	// Validate result if validation is configured
+	if err == nil && action.Validation != nil {
+		if validationErr := ge.validateActionResult(result, action.Validation, saidata, provider, options.Runtime); validationErr != nil {
+			err = fmt.Errorf("action validation failed: %w", validationErr)
+		}
+	}
+	
+	return &interfaces.ExecutionResult{
+		Success:  err == nil,
+		Output:   result.Output,
+		Error:    err,
+		ExitCode: result.ExitCode,
+		Duration: time.Since(startTime),
+		Commands: []string{result.Command},
+		Provider: provider.Provider.Name,
+	}, err
+}
+
+// renderCommand renders a command template with the current context, adding the extra
+// arguments of the action to the commands acting on packages and pointing it at the
+// alternate root when one is set
 func (ge *GenericExecutor) renderCommand(
 	command string,
 	software string,
@@ -617,23 +1132,60 @@ func (ge *GenericExecutor) renderCommand(
 	provider *types.ProviderData,
 	options interfaces.ExecuteOptions,
 ) (string, error) {
+	rendered, err := ge.renderTemplate(command, software, saidata, provider, options)
+	if err == nil && len(options.ExtraArgs) > 0 && packagesReference.MatchString(command) {
+		executable := ""
+		if ge.executablePath != nil {
+			executable = ge.executablePath(provider)
+		}
+		rendered, err = InjectArgs(rendered, []string{providerExecutable(provider), executable}, options.ExtraArgs)
+	}
+	if err != nil || ge.root == "" || usesRoot(command) {
+		return rendered, err
+	}
+	return ApplyRoot(rendered, ge.root)
+}
+
+// providerExecutable returns the executable a provider is detected by, its name when
+// it declares none
+func providerExecutable(provider *types.ProviderData) string {
+	if provider.Provider.Executable != "" {
+		return provider.Provider.Executable
+	}
+	return provider.Provider.Name
+}
+
+// renderTemplate renders a command or script template with the current context
+func (ge *GenericExecutor) renderTemplate(
+	command string,
+	software string,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+) (string, error) {
+	executable := ""
+	if ge.executablePath != nil {
+		executable = ge.executablePath(provider)
+	}
+
 	context := &interfaces.TemplateContext{
-		Software:  software,
-		Provider:  provider.Provider.Name,
-		Saidata:   saidata,
-		Variables: options.Variables,
+		Software:   software,
+		Provider:   provider.Provider.Name,
+		Executable: executable,
+		Saidata:    saidata,
+		Variables:  options.Variables,
+		Runtime:    options.Runtime,
+		Root:       ge.root,
+		Version:    options.Version,
 	}
 	
-	// Set saidata context in template engine
-	ge.templateEngine.SetSaidata(saidata)
-	
 	ge.logger.Debug("Rendering command template",
 		interfaces.LogField{Key: "template", Value: command},
 		interfaces.LogField{Key: "software", Value: software},
 		interfaces.LogField{Key: "provider", Value: provider.Provider.Name},
 	)
 	
-	rendered, err := ge.templateEngine.Render(command, context)
+	rendered, err := ge.render(command, context)
 	if err != nil {
 		ge.logger.Error("Template rendering failed", err,
 			interfaces.LogField{Key: "template", Value: command},
@@ -648,12 +1200,23 @@ func (ge *GenericExecutor) renderCommand(
 				interfaces.LogField{Key: "services_count", Value: len(saidata.Services)},
 				interfaces.LogField{Key: "providers_count", Value: len(saidata.Providers)},
 				interfaces.LogField{Key: "is_generated", Value: saidata.IsGenerated},
+				interfaces.LogField{Key: "absent_resources", Value: saidata.AbsentResources},
 			)
 		}
 		
 		return "", fmt.Errorf("failed to render template '%s': %w", command, err)
 	}
 	
+	// Translate POSIX-style templates to the provider shell dialect
+	rendered = ApplyShellDialect(rendered, provider.Provider.Shell)
+	if normalizeShell(provider.Provider.Shell) == types.ShellPOSIX {
+		rendered = PinExecutable(rendered, executable)
+		// An executable overridden in the configuration replaces the provider one
+		if declared := providerExecutable(provider); declared != filepath.Base(executable) {
+			rendered = ReplaceExecutable(rendered, declared, executable)
+		}
+	}
+	
 	ge.logger.Debug("Template rendered successfully",
 		interfaces.LogField{Key: "template", Value: command},
 		interfaces.LogField{Key: "rendered", Value: rendered},
@@ -664,11 +1227,32 @@ func (ge *GenericExecutor) renderCommand(
 	return rendered, nil
 }
 
+// renderStepPreview renders the command a step would run, the generated PowerShell
+// command for Windows primitives
+func (ge *GenericExecutor) renderStepPreview(
+	step types.Step,
+	software string,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+) (string, error) {
+	if !step.IsWindowsPrimitive() {
+		return ge.renderCommand(step.Command, software, saidata, provider, options)
+	}
+
+	rendered, err := ge.renderWindowsStep(step, saidata, provider, options)
+	if err != nil {
+		return "", err
+	}
+	return windowsStepCommand(rendered)
+}
+
 // evaluateCondition evaluates a step condition
 func (ge *GenericExecutor) evaluateCondition(
 	condition string,
 	saidata *types.SoftwareData,
 	provider *types.ProviderData,
+	runtime *interfaces.RuntimeContext,
 ) (bool, error) {
 	// For now, implement basic condition evaluation
 	// This could be extended to support more complex expressions
@@ -677,9 +1261,11 @@ func (ge *GenericExecutor) evaluateCondition(
 	context := &interfaces.TemplateContext{
 		Provider: provider.Provider.Name,
 		Saidata:  saidata,
+		Runtime:  runtime,
+		Root:     ge.root,
 	}
 	
-	rendered, err := ge.templateEngine.Render(condition, context)
+	rendered, err := ge.render(condition, context)
 	if err != nil {
 		return false, err
 	}
@@ -699,6 +1285,9 @@ func (ge *GenericExecutor) evaluateCondition(
 func (ge *GenericExecutor) validateActionResult(
 	result *interfaces.CommandResult,
 	validation *types.Validation,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	runtime *interfaces.RuntimeContext,
 ) error {
 	// Check exit code
 	if validation.ExpectedExitCode != 0 && result.ExitCode != validation.ExpectedExitCode {
@@ -712,6 +1301,17 @@ func (ge *GenericExecutor) validateActionResult(
 		}
 	}
 	
+	// Check the condition, such as a checksum_matches of the installed file
+	if validation.Condition != "" {
+		satisfied, err := ge.evaluateCondition(validation.Condition, saidata, provider, runtime)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate condition: %w", err)
+		}
+		if !satisfied {
+			return fmt.Errorf("condition not met: %s", validation.Condition)
+		}
+	}
+	
 	return nil
 }
 
@@ -724,6 +1324,8 @@ func (ge *GenericExecutor) executeRollback(
 	provider *types.ProviderData,
 	options interfaces.ExecuteOptions,
 ) error {
+	// The extra arguments are those of the action, not of the command undoing it
+	options.ExtraArgs = nil
 	rendered, err := ge.renderCommand(rollbackCommand, software, saidata, provider, options)
 	if err != nil {
 		return fmt.Errorf("failed to render rollback command: %w", err)
@@ -734,6 +1336,8 @@ func (ge *GenericExecutor) executeRollback(
 		WorkDir: options.WorkDir,
 		Env:     options.Env,
 		Verbose: options.Verbose,
+		Shell:   provider.Provider.GetShell(),
+		Output:  options.Output,
 	}
 	
 	result, err := ge.commandExecutor.ExecuteCommand(ctx, rendered, cmdOptions)
@@ -2,9 +2,23 @@ package executor
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"sai/internal/artifacts"
+	"sai/internal/audit"
+	"sai/internal/errors"
+	"sai/internal/history"
 	"sai/internal/interfaces"
+	"sai/internal/osinfo"
 	"sai/internal/types"
 )
 
@@ -187,6 +201,30 @@ func TestDryRun(t *testing.T) {
 	}
 }
 
+func TestDryRun_ExecutableOverride(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	executor := NewGenericExecutor(NewCommandExecutor(logger, validator), &MockTemplateEngine{}, logger, validator)
+	executor.SetExecutableResolver(func(provider *types.ProviderData) string {
+		return "/usr/local/bin/apt-fast"
+	})
+
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "apt", Executable: "apt-get"},
+		Actions: map[string]types.Action{
+			"install": {Command: "apt-get install -y nginx"},
+		},
+	}
+
+	result, err := executor.DryRun(context.Background(), provider, "install", "nginx", nil, interfaces.ExecuteOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if expected := "/usr/local/bin/apt-fast install -y nginx"; result.Commands[0] != expected {
+		t.Errorf("expected %q, got %q", expected, result.Commands[0])
+	}
+}
+
 func TestDryRun_MultipleSteps(t *testing.T) {
 	logger := &MockLogger{}
 	validator := &MockResourceValidator{}
@@ -284,6 +322,197 @@ func TestExecute_SingleCommand(t *testing.T) {
 	}
 }
 
+func TestExecute_AuditLog(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	log := audit.NewLog(filepath.Join(t.TempDir(), "audit.jsonl"), 0, 0)
+	commandExecutor.SetAuditLog(log)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return "echo hello", nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name: "test-provider",
+		},
+		Actions: map[string]types.Action{
+			"install": {
+				Command: "echo install {{.Software}}",
+			},
+		},
+	}
+	
+	ctx := context.Background()
+	if _, err := executor.Execute(ctx, provider, "install", "test-software", nil, interfaces.ExecuteOptions{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := executor.Execute(ctx, provider, "install", "test-software", nil, interfaces.ExecuteOptions{DryRun: true}); err != nil {
+		t.Fatalf("Expected no error in dry run, got %v", err)
+	}
+	
+	entries, err := log.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("Expected no error querying the audit log, got %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 audit entries, got %d: %+v", len(entries), entries)
+	}
+	
+	executed := entries[0]
+	if executed.Provider != "test-provider" || executed.Action != "install" || executed.Software != "test-software" {
+		t.Errorf("Expected the command recorded for test-provider install test-software, got %+v", executed)
+	}
+	if executed.Command != "echo hello" || executed.ExitCode != 0 || executed.DryRun {
+		t.Errorf("Expected 'echo hello' executed with exit code 0, got %+v", executed)
+	}
+	if !entries[1].DryRun || entries[1].Command != "echo hello" {
+		t.Errorf("Expected the dry run command recorded as such, got %+v", entries[1])
+	}
+}
+
+func TestExecute_ValidationCondition(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+
+	for _, condition := range []string{"true", "false"} {
+		templateEngine := &MockTemplateEngine{
+			renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+				if template == "{{checksum_matches}}" {
+					return condition, nil
+				}
+				return "echo hello", nil
+			},
+		}
+		executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+
+		provider := &types.ProviderData{
+			Provider: types.ProviderInfo{Name: "test-provider"},
+			Actions: map[string]types.Action{
+				"install": {
+					Command:    "echo install {{.Software}}",
+					Validation: &types.Validation{Condition: "{{checksum_matches}}"},
+				},
+			},
+		}
+
+		result, err := executor.Execute(context.Background(), provider, "install", "test-software", nil, interfaces.ExecuteOptions{})
+		if condition == "true" && (err != nil || !result.Success) {
+			t.Errorf("Expected execution to succeed when the condition holds, got %v", err)
+		}
+		if condition == "false" && (err == nil || !strings.Contains(err.Error(), "condition not met")) {
+			t.Errorf("Expected a validation error when the condition fails, got %v", err)
+		}
+	}
+}
+
+func TestExecute_Downloads(t *testing.T) {
+	const script = "#!/bin/sh\necho verified install\n"
+	sum := sha256.Sum256([]byte(script))
+	digest := hex.EncodeToString(sum[:])
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(script))
+	}))
+	defer server.Close()
+
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	executor := NewGenericExecutor(NewCommandExecutor(logger, validator), &MockTemplateEngine{}, logger, validator)
+	executor.SetRequireChecksums(true)
+
+	run := func(checksum string) (string, *interfaces.ExecutionResult, error) {
+		path := filepath.Join(t.TempDir(), "bin", "install.sh")
+		provider := &types.ProviderData{
+			Provider: types.ProviderInfo{Name: "test-provider", Type: "binary"},
+			Actions: map[string]types.Action{
+				"install": {
+					Command:   "sh " + path,
+					Downloads: []types.ActionDownload{{URL: server.URL + "/install.sh", Path: path, Checksum: checksum, Mode: "0755"}},
+				},
+			},
+		}
+		result, err := executor.Execute(context.Background(), provider, "install", "test-software", nil, interfaces.ExecuteOptions{})
+		return path, result, err
+	}
+
+	path, result, err := run("sha256:" + digest)
+	if err != nil || !strings.Contains(result.Output, "verified install") {
+		t.Fatalf("Expected the verified script to run, got %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("Expected the download at %s with mode 0755, got %v", path, err)
+	}
+
+	// A file failing verification never reaches its path
+	path, _, err = run("sha256:" + strings.Repeat("0", 64))
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+	if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+		t.Errorf("Expected no file at %s after a checksum mismatch", path)
+	}
+
+	// Downloads without checksum are refused before anything is fetched
+	_, _, err = run("")
+	if err == nil || !strings.Contains(err.Error(), "security.require_checksums") {
+		t.Errorf("Expected the unverified download to be refused, got %v", err)
+	}
+	executor.SetRequireChecksums(false)
+	if _, _, err = run(""); err != nil {
+		t.Errorf("Expected the unverified download to run when checksums are not required, got %v", err)
+	}
+}
+
+func TestExecute_Script(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name: "test-provider",
+		},
+		Actions: map[string]types.Action{
+			"install": {
+				Script:  "echo downloading\nfalse\necho installed",
+				Timeout: 10,
+			},
+		},
+	}
+	
+	ctx := context.Background()
+	options := interfaces.ExecuteOptions{}
+	
+	result, err := executor.Execute(ctx, provider, "install", "test-software", nil, options)
+	
+	if err == nil {
+		t.Fatal("Expected script to fail")
+	}
+	
+	if result.Success {
+		t.Error("Expected execution to fail")
+	}
+	
+	scriptErr, ok := err.(*ScriptError)
+	if !ok {
+		t.Fatalf("Expected *ScriptError, got %T: %v", err, err)
+	}
+	
+	if scriptErr.Line != 2 || scriptErr.Command != "false" {
+		t.Errorf("Expected failure at line 2 (false), got line %d (%s)", scriptErr.Line, scriptErr.Command)
+	}
+	
+	if result.Output != "downloading\n" {
+		t.Errorf("Expected output of the first line only, got %q", result.Output)
+	}
+}
+
 func TestExecute_NonExistentAction(t *testing.T) {
 	logger := &MockLogger{}
 	validator := &MockResourceValidator{}
@@ -316,6 +545,81 @@ func TestExecute_NonExistentAction(t *testing.T) {
 	}
 }
 
+func TestExecute_ReadOnly(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return "echo hello", nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	executor.SetReadOnly(true, func(action string) bool {
+		return action == "status"
+	})
+	runsDir := filepath.Join(t.TempDir(), "runs")
+	executor.SetArtifactStore(artifacts.NewStore(runsDir, t.TempDir()))
+	store := history.NewDurationStore(t.TempDir())
+	adaptive := NewAdaptiveTimeouts(store, time.Hour, 0, 1024)
+	probes := 0
+	adaptive.contentLength = func(ctx context.Context, url string) int64 {
+		probes++
+		return 0
+	}
+	executor.SetAdaptiveTimeouts(adaptive)
+	
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name: "test-provider",
+		},
+		Actions: map[string]types.Action{
+			"install": {Command: "echo install"},
+			"status":  {Command: "echo status"},
+		},
+	}
+	
+	ctx := context.Background()
+	
+	// System-changing actions are refused
+	result, err := executor.Execute(ctx, provider, "install", "test-software", nil, interfaces.ExecuteOptions{})
+	if err == nil {
+		t.Fatal("Expected install to be refused in read-only mode")
+	}
+	if result.Success || result.ExitCode != 1 {
+		t.Error("Expected refused execution to fail with exit code 1")
+	}
+	
+	// Dry runs are still allowed
+	if _, err := executor.Execute(ctx, provider, "install", "test-software", nil, interfaces.ExecuteOptions{DryRun: true}); err != nil {
+		t.Errorf("Expected dry run to be allowed in read-only mode, got %v", err)
+	}
+	
+	// Information-only actions are allowed, without writing run manifests, asking for
+	// download sizes or recording durations
+	runtime := &interfaces.RuntimeContext{ResolvedURL: "https://example.com/tool.zip"}
+	if _, err := executor.Execute(ctx, provider, "status", "test-software", nil, interfaces.ExecuteOptions{Runtime: runtime}); err != nil {
+		t.Errorf("Expected status to be allowed in read-only mode, got %v", err)
+	}
+	if _, err := os.Stat(runsDir); !os.IsNotExist(err) {
+		t.Errorf("Expected no run manifest in read-only mode, got %v", err)
+	}
+	if probes != 0 {
+		t.Errorf("Expected no download size request in read-only mode, got %d", probes)
+	}
+	if _, found := store.LongestDuration(HistoryKey("test-provider", "status", "test-software")); found {
+		t.Error("Expected no duration recorded in read-only mode")
+	}
+	
+	// Ad-hoc commands must be marked read-only
+	if _, err := executor.ExecuteCommand(ctx, "echo hello", interfaces.CommandOptions{}); err == nil {
+		t.Error("Expected unmarked command to be refused in read-only mode")
+	}
+	if _, err := executor.ExecuteCommand(ctx, "echo hello", interfaces.CommandOptions{ReadOnly: true}); err != nil {
+		t.Errorf("Expected read-only command to be allowed, got %v", err)
+	}
+}
+
 func TestExecuteSteps(t *testing.T) {
 	logger := &MockLogger{}
 	validator := &MockResourceValidator{}
@@ -360,6 +664,379 @@ func TestExecuteSteps(t *testing.T) {
 	}
 }
 
+func TestExecuteSteps_CaptureRuntime(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return strings.ReplaceAll(template, "{{.Runtime.SelectedPackage}}", context.Runtime.SelectedPackage), nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	
+	steps := []types.Step{
+		{Name: "use default", Command: "echo {{.Runtime.SelectedPackage}}"},
+		{Name: "pick alternative", Command: "echo nginx-full", Capture: interfaces.RuntimeSelectedPackage},
+		{Name: "use selected", Command: "echo {{.Runtime.SelectedPackage}}"},
+		{Name: "custom value", Command: "echo 1.25", Capture: "version"},
+	}
+	
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name: "apt",
+		},
+	}
+	
+	saidata := &types.SoftwareData{
+		Metadata: types.Metadata{Name: "nginx"},
+		Packages: []types.Package{{Name: "nginx"}},
+	}
+	
+	ctx := context.Background()
+	options := interfaces.ExecuteOptions{Timeout: 10 * time.Second}
+	
+	result, err := executor.ExecuteSteps(ctx, steps, saidata, provider, options)
+	
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	
+	expectedCommands := []string{"echo nginx", "echo nginx-full", "echo nginx-full", "echo 1.25"}
+	if strings.Join(result.Commands, "|") != strings.Join(expectedCommands, "|") {
+		t.Errorf("Expected commands %v, got %v", expectedCommands, result.Commands)
+	}
+	
+	if result.Runtime == nil || result.Runtime.SelectedPackage != "nginx-full" {
+		t.Fatalf("Expected selected package nginx-full in runtime context, got %+v", result.Runtime)
+	}
+	
+	if result.Runtime.Values["version"] != "1.25" {
+		t.Errorf("Expected captured version 1.25, got %q", result.Runtime.Values["version"])
+	}
+}
+
+func TestExecuteSteps_Register(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return strings.ReplaceAll(template, "{{.Registered.latest}}", context.Runtime.Values["latest"]), nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	
+	steps := []types.Step{
+		{Name: "detect latest", Command: "echo 1.27.0", Register: "latest"},
+		{Name: "download", Command: "echo nginx-{{.Registered.latest}}.tar.gz"},
+	}
+	
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "source"},
+	}
+	
+	result, err := executor.ExecuteSteps(context.Background(), steps, nil, provider, interfaces.ExecuteOptions{Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	
+	if len(result.Commands) != 2 || result.Commands[1] != "echo nginx-1.27.0.tar.gz" {
+		t.Errorf("Expected the registered version in the second command, got %v", result.Commands)
+	}
+	if result.Runtime.Values["latest"] != "1.27.0" {
+		t.Errorf("Expected registered variable latest=1.27.0, got %+v", result.Runtime.Values)
+	}
+}
+
+func TestExecuteSteps_ResumeFromCheckpoint(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return strings.ReplaceAll(template, "{{.Registered.latest}}", context.Runtime.Values["latest"]), nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	
+	dir := t.TempDir()
+	downloaded := filepath.Join(dir, "downloaded")
+	steps := []types.Step{
+		{Name: "detect latest", Command: "echo 1.27.0", Register: "latest"},
+		{Name: "download", Command: "test -f " + downloaded},
+		{Name: "build", Command: "echo nginx-{{.Registered.latest}}"},
+	}
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "source"},
+	}
+	options := interfaces.ExecuteOptions{Timeout: 10 * time.Second, Checkpoint: filepath.Join(dir, "source-install-nginx.json")}
+	
+	result, err := executor.ExecuteSteps(context.Background(), steps, nil, provider, options)
+	if result == nil || result.Success {
+		t.Fatalf("Expected the download step to fail, got %+v, %v", result, err)
+	}
+	if _, err := os.Stat(options.Checkpoint); err != nil {
+		t.Fatalf("Expected a checkpoint after the failure: %v", err)
+	}
+	
+	// Running the steps again skips the completed one and restores its output
+	os.WriteFile(downloaded, nil, 0644)
+	result, err = executor.ExecuteSteps(context.Background(), steps, nil, provider, options)
+	if err != nil || !result.Success {
+		t.Fatalf("Expected the resumed steps to succeed, got %+v, %v", result, err)
+	}
+	if !strings.Contains(result.Output, "Step detect latest completed on") {
+		t.Errorf("Expected the completed step to be skipped, got output %q", result.Output)
+	}
+	if len(result.Commands) != 3 || result.Commands[2] != "echo nginx-1.27.0" {
+		t.Errorf("Expected the restored registered version in the last command, got %v", result.Commands)
+	}
+	if _, err := os.Stat(options.Checkpoint); !os.IsNotExist(err) {
+		t.Errorf("Expected the checkpoint to be removed after success, got %v", err)
+	}
+	
+	// Steps undone by rollbacks are never skipped
+	if checkpointed(types.Step{Command: "useradd nginx", Rollback: "userdel nginx"}) {
+		t.Error("Expected steps with a rollback to run again")
+	}
+}
+
+func TestExecuteSteps_CheckpointDuplicateSteps(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return template, nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	
+	dir := t.TempDir()
+	steps := []types.Step{
+		{Command: "echo reload"},
+		{Command: "echo reload"},
+		{Command: "test -f " + filepath.Join(dir, "ready")},
+	}
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "source"},
+	}
+	options := interfaces.ExecuteOptions{Timeout: 10 * time.Second, Checkpoint: filepath.Join(dir, "source-install-nginx.json")}
+	
+	// Identical unnamed steps both run and are recorded apart
+	result, err := executor.ExecuteSteps(context.Background(), steps, nil, provider, options)
+	if result == nil || result.Success {
+		t.Fatalf("Expected the last step to fail, got %+v, %v", result, err)
+	}
+	if strings.Contains(result.Output, "completed on") {
+		t.Errorf("Expected no step to be skipped on a fresh run, got output %q", result.Output)
+	}
+	var checkpoint stepCheckpoint
+	data, err := os.ReadFile(options.Checkpoint)
+	if err != nil {
+		t.Fatalf("Expected a checkpoint after the failure: %v", err)
+	}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		t.Fatalf("Expected a readable checkpoint: %v", err)
+	}
+	if len(checkpoint.Steps) != 2 {
+		t.Errorf("Expected both identical steps in the checkpoint, got %+v", checkpoint.Steps)
+	}
+}
+
+func TestExecuteSteps_Workflow(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return strings.ReplaceAll(template, "{{.Workflow.java_home}}", context.Runtime.Workflow["java_home"]), nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "source"},
+	}
+	workflow := interfaces.NewWorkflow()
+	options := interfaces.ExecuteOptions{Timeout: 10 * time.Second, Workflow: workflow}
+	
+	// The dependency registers its install path, as the action manager publishes it
+	result, err := executor.ExecuteSteps(context.Background(), []types.Step{
+		{Name: "install", Command: "echo /opt/jdk-21", Register: "java_home"},
+	}, nil, provider, options)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	workflow.Publish("install", "openjdk", result.Runtime.Values)
+	
+	result, err = executor.ExecuteSteps(context.Background(), []types.Step{
+		{Name: "build", Command: "echo JAVA_HOME={{.Workflow.java_home}}"},
+	}, nil, provider, options)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Commands) != 1 || result.Commands[0] != "echo JAVA_HOME=/opt/jdk-21" {
+		t.Errorf("Expected the workflow variable in the command, got %v", result.Commands)
+	}
+	
+	variables := workflow.Variables()
+	if len(variables) != 1 || variables[0] != (interfaces.WorkflowVariable{Name: "java_home", Value: "/opt/jdk-21", Action: "install", Software: "openjdk"}) {
+		t.Errorf("Expected java_home published by install openjdk, got %+v", variables)
+	}
+	
+	workflow.Publish("install", "openjdk-17", map[string]string{"java_home": "/opt/jdk-17"})
+	if values := workflow.Values(); len(values) != 1 || values["java_home"] != "/opt/jdk-17" {
+		t.Errorf("Expected a later action to replace java_home, got %+v", values)
+	}
+}
+
+func TestExecuteSteps_DependsOnParallel(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return template, nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	
+	steps := []types.Step{
+		{Name: "download archive", Command: "sleep 0.4"},
+		{Name: "download checksum", Command: "sleep 0.4"},
+		{Name: "verify", Command: "echo verified", DependsOn: []string{"download archive", "download checksum"}},
+	}
+	
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name: "binary",
+		},
+	}
+	
+	ctx := context.Background()
+	options := interfaces.ExecuteOptions{Timeout: 10 * time.Second, MaxParallelSteps: 2}
+	
+	start := time.Now()
+	result, err := executor.ExecuteSteps(ctx, steps, nil, provider, options)
+	elapsed := time.Since(start)
+	
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got %v", result.Error)
+	}
+	
+	// Independent downloads run at the same time
+	if elapsed >= 800*time.Millisecond {
+		t.Errorf("Expected independent steps to run in parallel, took %v", elapsed)
+	}
+	
+	// Commands and output keep the step order
+	expectedCommands := []string{"sleep 0.4", "sleep 0.4", "echo verified"}
+	if strings.Join(result.Commands, "|") != strings.Join(expectedCommands, "|") {
+		t.Errorf("Expected commands %v, got %v", expectedCommands, result.Commands)
+	}
+	if !strings.HasSuffix(result.Output, "verified\n\n") {
+		t.Errorf("Expected verify output last, got %q", result.Output)
+	}
+}
+
+func TestExecuteSteps_DependsOnFailure(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return template, nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	
+	steps := []types.Step{
+		{Name: "download", Command: "nonexistentcommand123"},
+		{Name: "extract", Command: "echo extracted", DependsOn: []string{"download"}},
+		{Name: "cleanup", Command: "echo cleaned", DependsOn: []string{"extract"}},
+	}
+	
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name: "binary",
+		},
+	}
+	
+	ctx := context.Background()
+	options := interfaces.ExecuteOptions{Timeout: 10 * time.Second}
+	
+	result, _ := executor.ExecuteSteps(ctx, steps, nil, provider, options)
+	
+	if result.Success {
+		t.Fatal("Expected failure when a step fails")
+	}
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "step 1 failed") {
+		t.Errorf("Expected step 1 failure, got %v", result.Error)
+	}
+	if len(result.Commands) != 1 {
+		t.Errorf("Expected dependents of the failed step not to run, got commands %v", result.Commands)
+	}
+}
+
+func TestStepDependencies(t *testing.T) {
+	tests := []struct {
+		name        string
+		steps       []types.Step
+		expectedErr string
+	}{
+		{
+			name: "valid graph",
+			steps: []types.Step{
+				{Name: "a", Command: "true"},
+				{Name: "b", Command: "true", DependsOn: []string{"a"}},
+			},
+		},
+		{
+			name: "unknown dependency",
+			steps: []types.Step{
+				{Name: "a", Command: "true", DependsOn: []string{"missing"}},
+			},
+			expectedErr: `step a depends on unknown step "missing"`,
+		},
+		{
+			name: "duplicate name",
+			steps: []types.Step{
+				{Name: "a", Command: "true"},
+				{Name: "a", Command: "true"},
+			},
+			expectedErr: `duplicate step name "a"`,
+		},
+		{
+			name: "cycle",
+			steps: []types.Step{
+				{Name: "a", Command: "true", DependsOn: []string{"c"}},
+				{Name: "b", Command: "true", DependsOn: []string{"a"}},
+				{Name: "c", Command: "true", DependsOn: []string{"b"}},
+			},
+			expectedErr: "steps form a dependency cycle: a -> c -> b -> a",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := stepDependencies(tt.steps)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expectedErr {
+				t.Errorf("Expected error %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
 func TestExecuteSteps_IgnoreFailure(t *testing.T) {
 	logger := &MockLogger{}
 	validator := &MockResourceValidator{}
@@ -405,6 +1082,44 @@ func TestExecuteSteps_IgnoreFailure(t *testing.T) {
 	}
 }
 
+func TestExecuteSteps_RollbackChanges(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return template, nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+
+	steps := []types.Step{
+		{Name: "write", Command: "echo write", Rollback: "echo remove"},
+		{Name: "no-rollback", Command: "echo noop"},
+		{Name: "failing", Command: "nonexistentcommand123", Rollback: "echo never"},
+	}
+
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name: "test-provider",
+		},
+	}
+
+	result, _ := executor.ExecuteSteps(context.Background(), steps, nil, provider, interfaces.ExecuteOptions{})
+	if result.Success {
+		t.Fatal("Expected the failing step to fail the steps")
+	}
+
+	// Only completed steps with a rollback are recorded, so a partial run can be undone
+	if len(result.Changes) != 1 {
+		t.Fatalf("Expected 1 change, got %d", len(result.Changes))
+	}
+	change := result.Changes[0]
+	if change.Resource != "write" || change.RollbackCmd != "echo remove" || !change.Reversible {
+		t.Errorf("Unexpected change %+v", change)
+	}
+}
+
 func TestRenderTemplate(t *testing.T) {
 	logger := &MockLogger{}
 	validator := &MockResourceValidator{}
@@ -431,4 +1146,157 @@ func TestRenderTemplate(t *testing.T) {
 	if result != "rendered: test template" {
 		t.Errorf("Expected 'rendered: test template', got '%s'", result)
 	}
-}
\ No newline at end of file
+}
+
+func TestRenderTemplate_Host(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return strings.Join([]string{context.Platform, context.OS, context.OSVersion, context.Arch, context.PackageManager}, " "), nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "binary"},
+	}
+	
+	detections := 0
+	executor.SetHost(&osinfo.OSInfo{Platform: "linux", OS: "ubuntu", Version: "22.04", Architecture: "arm64"}, func() string {
+		detections++
+		return "apt"
+	})
+	
+	for i := 0; i < 2; i++ {
+		result, err := executor.RenderTemplate("{{.OS}}", nil, provider)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result != "linux ubuntu 22.04 arm64 apt" {
+			t.Errorf("Expected the host in the template context, got '%s'", result)
+		}
+	}
+	if detections != 1 {
+		t.Errorf("Expected the package manager to be detected once, got %d", detections)
+	}
+}
+
+func TestExecute_ArtifactCleanup(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return template, nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+	store := artifacts.NewStore(t.TempDir(), tempDir)
+	executor.SetArtifactStore(store)
+	
+	execute := func(commands ...string) (string, bool) {
+		var steps []types.Step
+		for _, command := range commands {
+			steps = append(steps, types.Step{Command: command})
+		}
+		provider := &types.ProviderData{
+			Provider: types.ProviderInfo{Name: "test-provider"},
+			Actions: map[string]types.Action{
+				"install": {Steps: steps, Timeout: 10},
+			},
+		}
+		options := interfaces.ExecuteOptions{Timeout: 10 * time.Second}
+		result, err := executor.Execute(context.Background(), provider, "install", "test-software", nil, options)
+		if result == nil || result.Runtime == nil {
+			t.Fatalf("Expected a result with its runtime context, got %v, %v", result, err)
+		}
+		return result.Runtime.ExtractDir, err == nil && result.Success
+	}
+	
+	// A failed run keeps its extraction directory for debugging
+	failedDir, success := execute("false")
+	if success {
+		t.Fatal("Expected execution to fail")
+	}
+	if filepath.Dir(failedDir) != tempDir {
+		t.Errorf("Expected extraction directory in %s, got %s", tempDir, failedDir)
+	}
+	if _, err := os.Stat(failedDir); err != nil {
+		t.Fatalf("Expected extraction directory of the failed run to be kept: %v", err)
+	}
+	
+	// A successful run gets its own directory and removes it
+	extractDir, success := execute("true")
+	if !success {
+		t.Fatal("Expected execution to succeed")
+	}
+	if extractDir == failedDir {
+		t.Errorf("Expected a new extraction directory, got the one of the failed run")
+	}
+	if _, err := os.Stat(extractDir); !os.IsNotExist(err) {
+		t.Errorf("Expected extraction directory to be removed, got %v", err)
+	}
+	if _, err := os.Stat(failedDir); err != nil {
+		t.Errorf("Expected extraction directory of the failed run to survive: %v", err)
+	}
+	
+	runs, err := store.Runs()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != artifacts.StatusFailed {
+		t.Errorf("Expected only the failed run to be recorded, got %v", runs)
+	}
+	
+	// Without a store, the extraction directory of a failed run is removed too
+	executor.SetArtifactStore(nil)
+	if failedDir, _ = execute("false"); failedDir == "" {
+		t.Fatal("Expected an extraction directory")
+	}
+	if _, err := os.Stat(failedDir); !os.IsNotExist(err) {
+		t.Errorf("Expected untracked extraction directory to be removed, got %v", err)
+	}
+}
+
+func TestExecute_ClassifiesPackageManagerErrors(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name: "apt",
+		},
+		Actions: map[string]types.Action{
+			"install": {
+				Script:  "echo 'E: Unable to locate package nginxx'\nexit 100",
+				Timeout: 10,
+			},
+		},
+	}
+	
+	result, err := executor.Execute(context.Background(), provider, "install", "nginxx", nil, interfaces.ExecuteOptions{})
+	
+	saiErr, ok := err.(*errors.SAIError)
+	if !ok {
+		t.Fatalf("Expected *errors.SAIError, got %T: %v", err, err)
+	}
+	if saiErr.Type != errors.ErrorTypePackageNotFound {
+		t.Errorf("Expected package_not_found, got %s", saiErr.Type)
+	}
+	if !saiErr.Recoverable {
+		t.Error("Expected a missing package to be recoverable with another provider")
+	}
+	if result.Error != err {
+		t.Error("Expected the result to carry the classified error")
+	}
+	if _, ok := saiErr.Cause.(*ScriptError); !ok {
+		t.Errorf("Expected the script error as cause, got %T", saiErr.Cause)
+	}
+}
@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"sync"
+
+	"sai/internal/interfaces"
+	"sai/internal/osinfo"
+)
+
+// host describes the system actions run on, exposed to templates
+type host struct {
+	info           *osinfo.OSInfo
+	packageManager func() string
+
+	once     sync.Once
+	resolved string // Result of packageManager, resolved on first use
+}
+
+// SetHost exposes the operating system and the default package manager of the host
+// to templates as .Platform, .OS, .OSVersion, .Arch and .PackageManager, so templates
+// can branch on the platform. packageManager is only called when a template renders,
+// as detecting the available providers runs their executables.
+func (ge *GenericExecutor) SetHost(info *osinfo.OSInfo, packageManager func() string) {
+	ge.host = &host{info: info, packageManager: packageManager}
+}
+
+// withHost fills the host fields of a template context left empty by its caller
+func (ge *GenericExecutor) withHost(context *interfaces.TemplateContext) *interfaces.TemplateContext {
+	if ge.host == nil || context == nil {
+		return context
+	}
+	if info := ge.host.info; info != nil {
+		if context.Platform == "" {
+			context.Platform = info.Platform
+		}
+		if context.OS == "" {
+			context.OS = info.OS
+		}
+		if context.OSVersion == "" {
+			context.OSVersion = info.Version
+		}
+		if context.Arch == "" {
+			context.Arch = info.Architecture
+		}
+	}
+	if context.PackageManager == "" && ge.host.packageManager != nil {
+		ge.host.once.Do(func() {
+			ge.host.resolved = ge.host.packageManager()
+		})
+		context.PackageManager = ge.host.resolved
+	}
+	return context
+}
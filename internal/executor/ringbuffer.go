@@ -0,0 +1,53 @@
+package executor
+
+import "fmt"
+
+// ringBuffer keeps the last size bytes written to it, such as the end of the output
+// of a long build, without moving the bytes kept on each write
+type ringBuffer struct {
+	data      []byte
+	next      int   // Where the next byte goes once data is full
+	discarded int64 // Bytes dropped from the head
+}
+
+// newRingBuffer creates a ring buffer keeping the last size bytes
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{data: make([]byte, 0, size)}
+}
+
+func (rb *ringBuffer) Write(p []byte) (int, error) {
+	written := len(p)
+	size := cap(rb.data)
+
+	// Fill the buffer before wrapping around
+	if free := size - len(rb.data); free > 0 {
+		n := min(free, len(p))
+		rb.data = append(rb.data, p[:n]...)
+		p = p[n:]
+	}
+	if len(p) == 0 {
+		return written, nil
+	}
+
+	// Once full, each byte written drops the oldest one
+	rb.discarded += int64(len(p))
+	if len(p) >= size {
+		copy(rb.data, p[len(p)-size:])
+		rb.next = 0
+		return written, nil
+	}
+	n := copy(rb.data[rb.next:], p)
+	copy(rb.data, p[n:])
+	rb.next = (rb.next + len(p)) % size
+	return written, nil
+}
+
+// Bytes returns the bytes kept in order, preceded by a note when the head was dropped
+func (rb *ringBuffer) Bytes() []byte {
+	var kept []byte
+	if rb.discarded > 0 {
+		kept = []byte(fmt.Sprintf("[... %d bytes of earlier output omitted ...]\n", rb.discarded))
+	}
+	kept = append(kept, rb.data[rb.next:]...)
+	return append(kept, rb.data[:rb.next]...)
+}
@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"sai/internal/interfaces"
+)
+
+func TestRingBuffer(t *testing.T) {
+	rb := newRingBuffer(8)
+	rb.Write([]byte("abc"))
+	if got := string(rb.Bytes()); got != "abc" {
+		t.Errorf("Bytes() = %q, want %q", got, "abc")
+	}
+
+	rb.Write([]byte("defgh"))
+	rb.Write([]byte("ij"))
+	if got, want := string(rb.Bytes()), "[... 2 bytes of earlier output omitted ...]\ncdefghij"; got != want {
+		t.Errorf("Bytes() = %q, want %q", got, want)
+	}
+
+	rb.Write([]byte("0123456789"))
+	if got, want := string(rb.Bytes()), "[... 12 bytes of earlier output omitted ...]\n23456789"; got != want {
+		t.Errorf("Bytes() = %q, want %q", got, want)
+	}
+}
+
+func TestExecuteCommand_StreamOutput(t *testing.T) {
+	executor := NewCommandExecutor(&MockLogger{}, &MockResourceValidator{})
+
+	var stream bytes.Buffer
+	result, err := executor.ExecuteCommand(context.Background(), "echo streamed", interfaces.CommandOptions{
+		Timeout: 10 * time.Second,
+		Output:  &stream,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(stream.String(), "streamed") {
+		t.Errorf("Expected the output to be streamed, got %q", stream.String())
+	}
+	if !strings.Contains(result.Output, "streamed") {
+		t.Errorf("Expected the output to be captured, got %q", result.Output)
+	}
+}
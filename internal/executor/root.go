@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// rootFlags returns the options pointing an executable at an alternate root filesystem,
+// such as an image mounted for an image build, instead of the running system
+var rootFlags = map[string]func(root string) []string{
+	"apt":          aptRootFlags,
+	"apt-get":      aptRootFlags,
+	"apt-cache":    aptRootFlags,
+	"dpkg":         func(root string) []string { return []string{"--root=" + root} },
+	"dpkg-query":   func(root string) []string { return []string{"--admindir=" + filepath.Join(root, "var/lib/dpkg")} },
+	"dnf":          func(root string) []string { return []string{"--installroot=" + root} },
+	"yum":          func(root string) []string { return []string{"--installroot=" + root} },
+	"rpm":          func(root string) []string { return []string{"--root", root} },
+	"pacman":       func(root string) []string { return []string{"--sysroot", root} },
+	"apk":          func(root string) []string { return []string{"--root", root} },
+	"zypper":       func(root string) []string { return []string{"--root", root} },
+	"xbps-install": func(root string) []string { return []string{"-r", root} },
+	"xbps-remove":  func(root string) []string { return []string{"-r", root} },
+	"xbps-query":   func(root string) []string { return []string{"-r", root} },
+	"emerge":       func(root string) []string { return []string{"--root=" + root} },
+	"opkg":         func(root string) []string { return []string{"-o", root} },
+	"pkg":          func(root string) []string { return []string{"-r", root} },
+	"systemctl":    func(root string) []string { return []string{"--root=" + root} },
+}
+
+// aptRootFlags makes apt read the package lists of the root and dpkg install into it
+func aptRootFlags(root string) []string {
+	return []string{"-o", "RootDir=" + root, "-o", "DPkg::Options::=--root=" + root}
+}
+
+// rootReference matches templates handling the alternate root themselves
+var rootReference = regexp.MustCompile(`\.Root\b`)
+
+// SetRoot makes actions act on the root filesystem mounted at root instead of the
+// running system. Package manager commands get their root options, download paths and
+// saidata paths are prefixed with root, and commands of executables without root
+// support are refused unless their template references {{.Root}}.
+func (ge *GenericExecutor) SetRoot(root string) {
+	ge.root = root
+}
+
+// usesRoot reports whether a template references the alternate root itself
+func usesRoot(template string) bool {
+	return rootReference.MatchString(template)
+}
+
+// ApplyRoot points a rendered command at the alternate root filesystem mounted at
+// root, inserting the root options of its executable after it. Commands of executables
+// without root support are refused, as they would change the running system.
+func ApplyRoot(command, root string) (string, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return command, nil
+	}
+
+	executable := filepath.Base(fields[0])
+	flags, supported := rootFlags[executable]
+	if !supported {
+		return "", fmt.Errorf("%s cannot act on the alternate root %s: it has no root option, use a provider whose package manager supports --root", executable, root)
+	}
+
+	args := append([]string{fields[0]}, flags(root)...)
+	return strings.Join(append(args, fields[1:]...), " "), nil
+}
+
+// rootPath prefixes an absolute path with the alternate root
+func rootPath(root, path string) string {
+	if root == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+func TestApplyRoot(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+		wantErr bool
+	}{
+		{"apt-get install -y nginx", "apt-get -o RootDir=/mnt/image -o DPkg::Options::=--root=/mnt/image install -y nginx", false},
+		{"/usr/bin/dnf install -y nginx", "/usr/bin/dnf --installroot=/mnt/image install -y nginx", false},
+		{"rpm -qa", "rpm --root /mnt/image -qa", false},
+		{"systemctl enable nginx", "systemctl --root=/mnt/image enable nginx", false},
+		{"", "", false},
+		{"curl -fsSL https://example.com", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ApplyRoot(tt.command, "/mnt/image")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ApplyRoot(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ApplyRoot(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestRootPath(t *testing.T) {
+	if got := rootPath("/mnt/image", "/etc/nginx/nginx.conf"); got != "/mnt/image/etc/nginx/nginx.conf" {
+		t.Errorf("rootPath() = %q", got)
+	}
+	if got := rootPath("/mnt/image", "relative/path"); got != "relative/path" {
+		t.Errorf("rootPath() of a relative path = %q", got)
+	}
+	if got := rootPath("", "/etc/nginx"); got != "/etc/nginx" {
+		t.Errorf("rootPath() without a root = %q", got)
+	}
+}
+
+func TestDryRun_Root(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	commandExecutor := NewCommandExecutor(logger, validator)
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			if context.Root != "/mnt/image" {
+				t.Errorf("Expected the root in the template context, got %q", context.Root)
+			}
+			return strings.ReplaceAll(template, "{{.Root}}", context.Root), nil
+		},
+	}
+	executor := NewGenericExecutor(commandExecutor, templateEngine, logger, validator)
+	executor.SetRoot("/mnt/image")
+
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "apt"},
+		Actions: map[string]types.Action{
+			"install":   {Command: "apt-get install -y nginx"},
+			"configure": {Command: "cp nginx.conf {{.Root}}/etc/nginx/"},
+			"fetch":     {Command: "curl -o /tmp/nginx https://example.com/nginx"},
+		},
+	}
+	options := interfaces.ExecuteOptions{DryRun: true}
+
+	result, err := executor.DryRun(context.Background(), provider, "install", "nginx", nil, options)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := "apt-get -o RootDir=/mnt/image -o DPkg::Options::=--root=/mnt/image install -y nginx"; result.Commands[0] != want {
+		t.Errorf("Expected %q, got %q", want, result.Commands[0])
+	}
+
+	result, err = executor.DryRun(context.Background(), provider, "configure", "nginx", nil, options)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := "cp nginx.conf /mnt/image/etc/nginx/"; result.Commands[0] != want {
+		t.Errorf("Expected %q, got %q", want, result.Commands[0])
+	}
+
+	if _, err := executor.DryRun(context.Background(), provider, "fetch", "nginx", nil, options); err == nil {
+		t.Error("Expected commands without root support to be refused")
+	}
+}
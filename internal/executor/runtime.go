@@ -0,0 +1,35 @@
+package executor
+
+import (
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// ExtractDirPattern names the extraction directory each action execution creates in
+// the system temp directory, see os.MkdirTemp
+const ExtractDirPattern = "sai-extract-*"
+
+// NewRuntimeContext creates the runtime context of an action execution. The first
+// package resolved for the provider is selected until a step captures another one.
+func NewRuntimeContext(provider *types.ProviderData, saidata *types.SoftwareData) *interfaces.RuntimeContext {
+	runtime := &interfaces.RuntimeContext{
+		Values: make(map[string]string),
+	}
+
+	if saidata == nil {
+		return runtime
+	}
+
+	packages := saidata.Packages
+	if provider != nil {
+		if providerConfig := saidata.GetProviderConfig(provider.Provider.Name); providerConfig != nil && len(providerConfig.Packages) > 0 {
+			packages = providerConfig.Packages
+		}
+	}
+	if len(packages) > 0 {
+		runtime.SelectedPackage = packages[0].GetPackageNameOrDefault()
+		runtime.ResolvedURL = packages[0].DownloadURL
+	}
+
+	return runtime
+}
@@ -0,0 +1,180 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Isolation modes of the sandbox
+const (
+	IsolationUnshare = "unshare" // Run commands in new namespaces
+	IsolationNsenter = "nsenter" // Run commands in the namespaces of a target process
+)
+
+// Namespaces lists the namespaces the sandbox can isolate commands in, as named by
+// the flags of unshare and nsenter
+var Namespaces = []string{"mount", "uts", "ipc", "net", "pid", "user", "cgroup"}
+
+// defaultNamespaces are isolated when the sandbox names none: those not cutting
+// package managers off the network or the processes they manage
+var defaultNamespaces = []string{"mount", "uts", "ipc"}
+
+// sandboxEnv lists the variables passed to commands of a sandbox restricting the
+// environment, besides the allowed ones
+var sandboxEnv = []string{"PATH", "HOME", "LANG", "LC_ALL", "TERM", "TMPDIR"}
+
+// Sandbox restricts how the commands rendered from provider templates run. The zero
+// value runs them as sai does.
+type Sandbox struct {
+	User            string   // Run commands as this user, which requires sai to run as root
+	RestrictEnv     bool     // Only pass the variables of sandboxEnv, AllowedEnv and the command to commands
+	AllowedEnv      []string // Variables passed to commands with RestrictEnv
+	Isolation       string   // "", IsolationUnshare or IsolationNsenter
+	Namespaces      []string // Namespaces unshare creates or nsenter enters, defaultNamespaces when empty
+	Target          int      // PID whose namespaces nsenter enters
+	AllowedCommands []string // Executables commands may run, by name or absolute path, empty allows any
+	AllowScripts    bool     // Run scripts despite AllowedCommands, which cannot tell what they run
+}
+
+// SetSandbox runs the commands and scripts executed from then on in the sandbox
+func (ce *CommandExecutor) SetSandbox(sandbox Sandbox) {
+	ce.sandbox = sandbox
+}
+
+// checkAllowed refuses an executable missing from the allowed commands of the
+// sandbox. Both are resolved through PATH and symlinks and compared by absolute path,
+// so a bare name only allows the executable PATH finds for it.
+func (s Sandbox) checkAllowed(executable string) error {
+	if len(s.AllowedCommands) == 0 {
+		return nil
+	}
+	resolved, err := resolveExecutable(executable)
+	if err != nil {
+		return fmt.Errorf("command %s is not in the allowed commands of the sandbox: %w", executable, err)
+	}
+	for _, allowed := range s.AllowedCommands {
+		if path, err := resolveExecutable(allowed); err == nil && path == resolved {
+			return nil
+		}
+	}
+	return fmt.Errorf("command %s (%s) is not in the allowed commands of the sandbox", executable, resolved)
+}
+
+// checkScripts refuses scripts in a sandbox restricting commands unless it allows
+// them explicitly: the interpreter of a script does not tell what the script runs
+func (s Sandbox) checkScripts() error {
+	if len(s.AllowedCommands) == 0 || s.AllowScripts {
+		return nil
+	}
+	return fmt.Errorf("scripts are not allowed in a sandbox restricting commands, set allow_scripts to run them")
+}
+
+// resolveExecutable returns the absolute path of an executable, looked up in PATH
+// when given by name, with symlinks resolved
+func resolveExecutable(executable string) (string, error) {
+	path, err := exec.LookPath(executable)
+	if err != nil {
+		return "", err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved, nil
+	}
+	return path, nil
+}
+
+// grant gives a file to the user of the sandbox, so commands running as the user
+// can read it
+func (s Sandbox) grant(path string) error {
+	if s.User == "" {
+		return nil
+	}
+	if err := chownToUser(path, s.User); err != nil {
+		return fmt.Errorf("failed to give %s to %s: %w", path, s.User, err)
+	}
+	return nil
+}
+
+// wrap returns the arguments running a command in the namespaces of the sandbox
+func (s Sandbox) wrap(parts []string) ([]string, error) {
+	if s.Isolation == "" {
+		return parts, nil
+	}
+
+	namespaces := s.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = defaultNamespaces
+	}
+	var wrapper []string
+	switch s.Isolation {
+	case IsolationUnshare:
+		wrapper = []string{"unshare"}
+	case IsolationNsenter:
+		wrapper = []string{"nsenter", "--target", strconv.Itoa(s.Target)}
+	default:
+		return nil, fmt.Errorf("unknown sandbox isolation %s", s.Isolation)
+	}
+	if _, err := exec.LookPath(wrapper[0]); err != nil {
+		return nil, fmt.Errorf("sandbox isolation requires %s: %w", wrapper[0], err)
+	}
+
+	for _, namespace := range namespaces {
+		wrapper = append(wrapper, "--"+namespace)
+		// A new pid namespace only applies to the children of unshare
+		if namespace == "pid" && s.Isolation == IsolationUnshare {
+			wrapper = append(wrapper, "--fork")
+		}
+	}
+	return append(append(wrapper, "--"), parts...), nil
+}
+
+// environment returns the environment of a command with the given variables, nil to
+// inherit the one of sai
+func (s Sandbox) environment(vars map[string]string) []string {
+	if !s.RestrictEnv && len(vars) == 0 {
+		return nil
+	}
+
+	env := os.Environ()
+	if s.RestrictEnv {
+		allowed := make(map[string]bool)
+		for _, name := range append(append([]string(nil), sandboxEnv...), s.AllowedEnv...) {
+			allowed[name] = true
+		}
+		env = env[:0:0]
+		for _, variable := range os.Environ() {
+			name, _, _ := strings.Cut(variable, "=")
+			if allowed[name] {
+				env = append(env, variable)
+			}
+		}
+	}
+	for key, value := range vars {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env
+}
+
+// apply prepares a command to run in the sandbox: as its user, with its environment
+func (s Sandbox) apply(cmd *exec.Cmd, vars map[string]string) error {
+	cmd.Env = s.environment(vars)
+	if s.User == "" {
+		return nil
+	}
+	home, err := runAsUser(cmd, s.User)
+	if err != nil {
+		return fmt.Errorf("failed to run commands as %s: %w", s.User, err)
+	}
+	// HOME of sai would point the user to files it cannot write
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, "HOME="+home)
+	return nil
+}
@@ -0,0 +1,144 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/privilege"
+)
+
+func TestSandboxCheckAllowed(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not installed")
+	}
+	// An executable named as an allowed one, outside of PATH
+	impostor := filepath.Join(t.TempDir(), "sh")
+	if err := os.WriteFile(impostor, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	sandbox := Sandbox{AllowedCommands: []string{"sh"}}
+	for _, executable := range []string{"sh", sh} {
+		if err := sandbox.checkAllowed(executable); err != nil {
+			t.Errorf("%s refused: %v", executable, err)
+		}
+	}
+	for _, executable := range []string{impostor, "sai-missing-command"} {
+		if err := sandbox.checkAllowed(executable); err == nil {
+			t.Errorf("%s allowed", executable)
+		}
+	}
+
+	sandbox = Sandbox{AllowedCommands: []string{impostor}}
+	if err := sandbox.checkAllowed(impostor); err != nil {
+		t.Errorf("%s refused: %v", impostor, err)
+	}
+	if err := sandbox.checkAllowed("sh"); err == nil {
+		t.Errorf("sh allowed by %s", impostor)
+	}
+
+	if err := (Sandbox{}).checkAllowed("curl"); err != nil {
+		t.Errorf("sandbox without allowed commands refused curl: %v", err)
+	}
+}
+
+func TestSandboxWrap(t *testing.T) {
+	parts := []string{"apt-get", "install", "-y", "nginx"}
+
+	wrapped, err := Sandbox{}.wrap(parts)
+	if err != nil || !reflect.DeepEqual(wrapped, parts) {
+		t.Errorf("wrap without isolation = %v, %v", wrapped, err)
+	}
+
+	if _, err := exec.LookPath("unshare"); err != nil {
+		t.Skip("unshare not installed")
+	}
+	wrapped, err = Sandbox{Isolation: IsolationUnshare, Namespaces: []string{"mount", "pid"}}.wrap(parts)
+	if err != nil {
+		t.Fatalf("wrap failed: %v", err)
+	}
+	expected := []string{"unshare", "--mount", "--pid", "--fork", "--", "apt-get", "install", "-y", "nginx"}
+	if !reflect.DeepEqual(wrapped, expected) {
+		t.Errorf("wrap = %v, want %v", wrapped, expected)
+	}
+
+	if _, err := exec.LookPath("nsenter"); err != nil {
+		t.Skip("nsenter not installed")
+	}
+	wrapped, _ = Sandbox{Isolation: IsolationNsenter, Target: 42}.wrap(parts)
+	expected = []string{"nsenter", "--target", "42", "--mount", "--uts", "--ipc", "--", "apt-get", "install", "-y", "nginx"}
+	if !reflect.DeepEqual(wrapped, expected) {
+		t.Errorf("wrap = %v, want %v", wrapped, expected)
+	}
+}
+
+func TestSandboxEnvironment(t *testing.T) {
+	t.Setenv("SAI_TEST_SECRET", "hunter2")
+	t.Setenv("HTTPS_PROXY", "http://proxy:3128")
+
+	if env := (Sandbox{}).environment(nil); env != nil {
+		t.Errorf("environment without restriction nor variables = %v, want inherited", env)
+	}
+
+	env := Sandbox{RestrictEnv: true, AllowedEnv: []string{"HTTPS_PROXY"}}.environment(map[string]string{"DEBIAN_FRONTEND": "noninteractive"})
+	joined := strings.Join(env, "\n")
+	for _, expected := range []string{"HTTPS_PROXY=http://proxy:3128", "DEBIAN_FRONTEND=noninteractive"} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("environment misses %s: %v", expected, env)
+		}
+	}
+	if strings.Contains(joined, "SAI_TEST_SECRET") {
+		t.Errorf("restricted environment leaks SAI_TEST_SECRET: %v", env)
+	}
+}
+
+func TestExecuteCommand_SandboxRefusesCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("echo is a shell builtin on Windows")
+	}
+	executor := NewCommandExecutor(&MockLogger{}, &MockResourceValidator{})
+	executor.SetSandbox(Sandbox{AllowedCommands: []string{"true"}})
+
+	result, err := executor.ExecuteCommand(context.Background(), "echo hello", interfaces.CommandOptions{Timeout: 10 * time.Second})
+	if err == nil || !strings.Contains(err.Error(), "not in the allowed commands") {
+		t.Fatalf("echo not refused: %v", err)
+	}
+	if result.ExitCode != 1 {
+		t.Errorf("exit code = %d, want 1", result.ExitCode)
+	}
+
+	if _, err := executor.ExecuteCommand(context.Background(), "true", interfaces.CommandOptions{Timeout: 10 * time.Second}); err != nil {
+		t.Errorf("allowed command failed: %v", err)
+	}
+}
+
+func TestExecuteCommand_Escalation(t *testing.T) {
+	envPath, err := exec.LookPath("env")
+	if err != nil {
+		t.Skip("env not installed")
+	}
+	executor := NewCommandExecutor(&MockLogger{}, &MockResourceValidator{})
+
+	// env stands in for sudo, marking the commands it runs
+	tool := &privilege.Tool{Name: "env", Path: envPath, Args: []string{"SAI_ESCALATED=1"}}
+	ctx := privilege.WithTool(context.Background(), tool)
+	result, err := executor.ExecuteCommand(ctx, "printenv SAI_ESCALATED DEBIAN_FRONTEND", interfaces.CommandOptions{
+		Timeout: 10 * time.Second,
+		Env:     map[string]string{"DEBIAN_FRONTEND": "noninteractive"},
+	})
+	if err != nil {
+		t.Fatalf("escalated command failed: %v", err)
+	}
+	if result.Output != "1\nnoninteractive\n" {
+		t.Errorf("output = %q, want the variables of the tool and of the command", result.Output)
+	}
+}
@@ -0,0 +1,64 @@
+//go:build !windows
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// runAsUser sets a command to run with the uid, gid and groups of a user, given by
+// name or uid, and returns its home directory
+func runAsUser(cmd *exec.Cmd, name string) (string, error) {
+	account, credential, err := lookupCredential(name)
+	if err != nil {
+		return "", err
+	}
+	if groupIDs, err := account.GroupIds(); err == nil {
+		for _, groupID := range groupIDs {
+			if id, err := strconv.ParseUint(groupID, 10, 32); err == nil {
+				credential.Groups = append(credential.Groups, uint32(id))
+			}
+		}
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = credential
+	return account.HomeDir, nil
+}
+
+// chownToUser gives a file to a user, given by name or uid, so commands running as
+// the user can read it
+func chownToUser(path, name string) error {
+	_, credential, err := lookupCredential(name)
+	if err != nil {
+		return err
+	}
+	return os.Chown(path, int(credential.Uid), int(credential.Gid))
+}
+
+// lookupCredential returns a user, given by name or uid, and its uid and gid
+func lookupCredential(name string) (*user.User, *syscall.Credential, error) {
+	account, err := user.Lookup(name)
+	if err != nil {
+		if account, err = user.LookupId(name); err != nil {
+			return nil, nil, fmt.Errorf("unknown user %s", name)
+		}
+	}
+
+	uid, err := strconv.ParseUint(account.Uid, 10, 32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid uid %s of user %s", account.Uid, name)
+	}
+	gid, err := strconv.ParseUint(account.Gid, 10, 32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid gid %s of user %s", account.Gid, name)
+	}
+	return account, &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}, nil
+}
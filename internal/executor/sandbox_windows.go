@@ -0,0 +1,19 @@
+//go:build windows
+
+package executor
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// runAsUser is not supported: Windows starts processes of other users with their
+// password or token, which sai does not hold
+func runAsUser(cmd *exec.Cmd, name string) (string, error) {
+	return "", fmt.Errorf("running commands as another user is not supported on Windows")
+}
+
+// chownToUser is not supported, as runAsUser
+func chownToUser(path, name string) error {
+	return fmt.Errorf("running commands as another user is not supported on Windows")
+}
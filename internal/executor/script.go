@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// scriptErrorMarker prefixes the line written to stderr by the ERR trap of bash scripts
+const scriptErrorMarker = "SAI_SCRIPT_ERROR"
+
+// scriptErrorPattern matches the ERR trap output: marker, line number and exit code
+var scriptErrorPattern = regexp.MustCompile(scriptErrorMarker + `:(\d+):(\d+)\r?\n?`)
+
+// ScriptError reports a failing line of a script action
+type ScriptError struct {
+	Line     int    // Line number within the script body, 0 if unknown
+	Command  string // Script line that failed
+	ExitCode int
+}
+
+func (e *ScriptError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("script failed with exit code %d", e.ExitCode)
+	}
+	return fmt.Sprintf("script failed at line %d with exit code %d: %s", e.Line, e.ExitCode, e.Command)
+}
+
+// BuildScript prepares a script body for execution from a script file. A shebang is
+// added for the interpreter unless the script has its own, strict mode is enabled and
+// bash scripts report the failing line through an ERR trap. It returns the script and
+// the number of lines by which the body lines were shifted.
+func BuildScript(body, interpreter string) (string, int) {
+	var shebang string
+	ownShebang := strings.HasPrefix(body, "#!")
+	if ownShebang {
+		shebang, body, _ = strings.Cut(body, "\n")
+		interpreter = shebangInterpreter(shebang)
+	} else {
+		if interpreter == "" {
+			interpreter = "bash"
+		}
+		shebang = "#!/usr/bin/env " + interpreter
+	}
+
+	header := []string{shebang}
+	switch filepath.Base(interpreter) {
+	case "bash":
+		header = append(header,
+			"set -euo pipefail",
+			fmt.Sprintf(`trap 'echo "%s:$LINENO:$?" >&2' ERR`, scriptErrorMarker),
+		)
+	case "zsh", "ksh":
+		header = append(header, "set -euo pipefail")
+	default:
+		// pipefail is not available in every POSIX sh
+		header = append(header, "set -eu")
+	}
+
+	// A script's own shebang keeps its place as the first line
+	offset := len(header)
+	if ownShebang {
+		offset--
+	}
+
+	return strings.Join(header, "\n") + "\n" + body, offset
+}
+
+// shebangInterpreter returns the interpreter name of a shebang line
+func shebangInterpreter(shebang string) string {
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	if filepath.Base(fields[0]) == "env" && len(fields) > 1 {
+		return fields[1]
+	}
+	return fields[0]
+}
+
+// ExecuteScript writes a script to a temporary file and executes it. When a bash
+// script fails, the returned error is a *ScriptError naming the failing line.
+func (ce *CommandExecutor) ExecuteScript(ctx context.Context, body, interpreter string, options interfaces.CommandOptions) (*interfaces.CommandResult, error) {
+	startTime := time.Now()
+
+	if normalizeShell(options.Shell) != types.ShellPOSIX {
+		err := fmt.Errorf("script actions require a POSIX shell, provider shell is %s", options.Shell)
+		return &interfaces.CommandResult{
+			Command:  body,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, err
+	}
+
+	// Refuse scripts the sandbox does not allow, dry runs included
+	if err := ce.sandbox.checkScripts(); err != nil {
+		return &interfaces.CommandResult{
+			Command:  body,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, err
+	}
+
+	script, offset := BuildScript(body, interpreter)
+	if ce.dryRun || options.Timeout == 0 {
+		ce.logger.Info("DRY RUN: Would execute script", interfaces.LogField{Key: "script", Value: script})
+		result := &interfaces.CommandResult{
+			Command:  script,
+			Output:   fmt.Sprintf("DRY RUN: %s", script),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}
+		ce.audit(ctx, script, result, nil, true)
+		return result, nil
+	}
+
+	file, err := os.CreateTemp("", "sai-script-*.sh")
+	if err != nil {
+		return &interfaces.CommandResult{
+			Command:  script,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, fmt.Errorf("failed to create script file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(script); err != nil {
+		file.Close()
+		return &interfaces.CommandResult{
+			Command:  script,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, fmt.Errorf("failed to write script file: %w", err)
+	}
+	file.Close()
+
+	// The script file is private to sai, the user of the sandbox must own it to read it
+	if err := ce.sandbox.grant(file.Name()); err != nil {
+		return &interfaces.CommandResult{
+			Command:  script,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, err
+	}
+
+	// Run the script through its interpreter so noexec temp directories work
+	shebang, _, _ := strings.Cut(script, "\n")
+	command := strings.TrimSpace(strings.TrimPrefix(shebang, "#!")) + " " + file.Name()
+
+	ce.logger.Debug("Executing script",
+		interfaces.LogField{Key: "command", Value: command},
+		interfaces.LogField{Key: "script", Value: script},
+	)
+
+	result, err := ce.execute(ctx, command, options, true)
+	ce.audit(ctx, script, result, err, false)
+	if result == nil {
+		return result, err
+	}
+	result.Command = script
+
+	if err == nil && result.ExitCode != 0 {
+		scriptErr := &ScriptError{ExitCode: result.ExitCode}
+		if match := scriptErrorPattern.FindStringSubmatch(result.Output); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			scriptErr.Line = line - offset
+			scriptErr.Command = scriptLine(body, scriptErr.Line)
+		}
+		result.Output = scriptErrorPattern.ReplaceAllString(result.Output, "")
+		result.Error = scriptErr
+	}
+
+	return result, err
+}
+
+// scriptLine returns the trimmed text of a 1-based line of the script body
+func scriptLine(body string, line int) string {
+	lines := strings.Split(body, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
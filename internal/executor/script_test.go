@@ -0,0 +1,121 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/interfaces"
+)
+
+func TestBuildScript(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		interpreter    string
+		expectedHeader string
+		expectedOffset int
+	}{
+		{
+			name:           "default bash with error trap",
+			body:           "echo one\necho two",
+			expectedHeader: "#!/usr/bin/env bash\nset -euo pipefail\ntrap 'echo \"SAI_SCRIPT_ERROR:$LINENO:$?\" >&2' ERR\n",
+			expectedOffset: 3,
+		},
+		{
+			name:           "sh interpreter without pipefail",
+			body:           "echo one",
+			interpreter:    "sh",
+			expectedHeader: "#!/usr/bin/env sh\nset -eu\n",
+			expectedOffset: 2,
+		},
+		{
+			name:           "own shebang is kept",
+			body:           "#!/bin/bash\necho one",
+			interpreter:    "sh",
+			expectedHeader: "#!/bin/bash\nset -euo pipefail\ntrap 'echo \"SAI_SCRIPT_ERROR:$LINENO:$?\" >&2' ERR\n",
+			expectedOffset: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, offset := BuildScript(tt.body, tt.interpreter)
+			assert.True(t, strings.HasPrefix(script, tt.expectedHeader), script)
+			assert.Equal(t, tt.expectedOffset, offset)
+		})
+	}
+}
+
+func TestCommandExecutor_ExecuteScript(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+
+	executor := NewCommandExecutor(&MockLogger{}, &MockResourceValidator{})
+	options := interfaces.CommandOptions{Timeout: 10 * time.Second}
+
+	t.Run("multi-line script succeeds", func(t *testing.T) {
+		result, err := executor.ExecuteScript(context.Background(), "echo one\n\necho two", "", options)
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Equal(t, "one\ntwo\n", result.Output)
+	})
+
+	t.Run("failing line is reported", func(t *testing.T) {
+		result, err := executor.ExecuteScript(context.Background(), "echo one\nfalse\necho two", "", options)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.ExitCode)
+		assert.Equal(t, "one\n", result.Output)
+
+		scriptErr, ok := result.Error.(*ScriptError)
+		require.True(t, ok)
+		assert.Equal(t, 2, scriptErr.Line)
+		assert.Equal(t, "false", scriptErr.Command)
+		assert.Equal(t, "script failed at line 2 with exit code 1: false", scriptErr.Error())
+	})
+
+	t.Run("working directory", func(t *testing.T) {
+		dir := t.TempDir()
+		workOptions := options
+		workOptions.WorkDir = dir
+
+		result, err := executor.ExecuteScript(context.Background(), "pwd", "", workOptions)
+		require.NoError(t, err)
+		resolved, _ := filepath.EvalSymlinks(dir)
+		assert.Contains(t, []string{dir, resolved}, strings.TrimSpace(result.Output))
+	})
+
+	t.Run("script file is removed", func(t *testing.T) {
+		result, err := executor.ExecuteScript(context.Background(), "echo $0", "", options)
+		require.NoError(t, err)
+		_, statErr := os.Stat(strings.TrimSpace(result.Output))
+		assert.True(t, os.IsNotExist(statErr))
+	})
+
+	t.Run("non-POSIX shell is refused", func(t *testing.T) {
+		psOptions := options
+		psOptions.Shell = "powershell"
+
+		_, err := executor.ExecuteScript(context.Background(), "echo one", "", psOptions)
+		assert.Error(t, err)
+	})
+
+	t.Run("sandbox restricting commands refuses scripts", func(t *testing.T) {
+		sandboxed := NewCommandExecutor(&MockLogger{}, &MockResourceValidator{})
+		sandboxed.SetSandbox(Sandbox{AllowedCommands: []string{"bash"}})
+		_, err := sandboxed.ExecuteScript(context.Background(), "echo one", "", options)
+		assert.Error(t, err)
+
+		sandboxed.SetSandbox(Sandbox{AllowedCommands: []string{"apt-get"}, AllowScripts: true})
+		result, err := sandboxed.ExecuteScript(context.Background(), "echo one", "", options)
+		require.NoError(t, err)
+		assert.Equal(t, "one\n", result.Output)
+	})
+}
@@ -0,0 +1,209 @@
+package executor
+
+import (
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sai/internal/types"
+)
+
+var (
+	// posixEnvPattern matches POSIX environment references ($NAME or ${NAME}). Only
+	// upper-case names are treated as environment variables so shell-native variables
+	// such as PowerShell's $_ or $true are left untouched.
+	posixEnvPattern = regexp.MustCompile(`\$\{([A-Z][A-Z0-9_]*)\}|\$([A-Z][A-Z0-9_]*)`)
+
+	// posixContinuationPattern matches a backslash line continuation
+	posixContinuationPattern = regexp.MustCompile(`\\[ \t]*(\r?\n)`)
+
+	// singleQuotedPattern matches a POSIX single-quoted string
+	singleQuotedPattern = regexp.MustCompile(`'([^']*)'`)
+
+	// devNullPattern matches output redirection to /dev/null
+	devNullPattern = regexp.MustCompile(`(\d?>)\s*/dev/null`)
+
+	// scAliasPattern matches invocations of sc, which PowerShell aliases to Set-Content
+	scAliasPattern = regexp.MustCompile(`(^|[|;&]\s*)sc\s`)
+)
+
+// ApplyShellDialect post-processes a rendered command written for POSIX sh so it
+// can run in the given shell dialect. Templates are written once in POSIX style and
+// line continuations, environment references and quoting are translated here.
+func ApplyShellDialect(command, shell string) string {
+	switch normalizeShell(shell) {
+	case types.ShellPowerShell:
+		command = posixContinuationPattern.ReplaceAllString(command, "`$1")
+		command = devNullPattern.ReplaceAllString(command, "$1$$null")
+		command = scAliasPattern.ReplaceAllString(command, "${1}sc.exe ")
+		command = replaceEnvReferences(command, func(name string) string {
+			return "$env:" + name
+		})
+	case types.ShellCmd:
+		command = posixContinuationPattern.ReplaceAllString(command, "^$1")
+		command = devNullPattern.ReplaceAllString(command, "${1}nul")
+		command = replaceEnvReferences(command, func(name string) string {
+			return "%" + name + "%"
+		})
+		// cmd.exe has no single quotes; convert them to double-quoted arguments
+		command = singleQuotedPattern.ReplaceAllStringFunc(command, func(match string) string {
+			return QuoteArgument(match[1:len(match)-1], types.ShellCmd)
+		})
+	}
+
+	return command
+}
+
+// QuoteArgument quotes a literal argument for the given shell dialect
+func QuoteArgument(arg, shell string) string {
+	switch normalizeShell(shell) {
+	case types.ShellPowerShell:
+		return "'" + strings.ReplaceAll(arg, "'", "''") + "'"
+	case types.ShellCmd:
+		return `"` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+	default:
+		return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+}
+
+// shellCommandArgs returns the program and arguments used to run a command in the
+// given shell dialect. POSIX commands keep being executed directly without a shell,
+// split into words as the shell would so quoted arguments stay one argument.
+func shellCommandArgs(command, shell string) []string {
+	switch normalizeShell(shell) {
+	case types.ShellPowerShell:
+		return []string{powerShellExecutable(), "-NoProfile", "-NonInteractive", "-Command", command}
+	case types.ShellCmd:
+		return []string{cmdExecutable(), "/C", command}
+	default:
+		return splitWords(command)
+	}
+}
+
+// splitWords splits a POSIX command into words, honoring single quotes, double quotes
+// and backslash escapes, without expanding anything. Commands with unbalanced quotes
+// are split on whitespace.
+func splitWords(command string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, c := range command {
+		switch {
+		case escaped:
+			word.WriteRune(c)
+			escaped = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case quote == '"':
+			switch c {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				word.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == '\\':
+			escaped = true
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return strings.Fields(command)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// PinExecutable replaces the bare name of an executable by its absolute path where it
+// is run by a POSIX command: at the start of the command, after a command separator or
+// pipe, and after sudo or env. Paths containing whitespace are left as they are since
+// commands are split on whitespace.
+func PinExecutable(command, path string) string {
+	return ReplaceExecutable(command, filepath.Base(path), path)
+}
+
+// ReplaceExecutable replaces the executable name by the absolute path where it is run
+// by a POSIX command, like PinExecutable. Providers whose executable is overridden in
+// the configuration run the configured executable in place of the one of their
+// commands, apt-get becoming /usr/local/bin/apt-fast.
+func ReplaceExecutable(command, name, path string) string {
+	if name == "" || path == "" || !filepath.IsAbs(path) || strings.ContainsAny(path, " \t\n") {
+		return command
+	}
+
+	pattern := regexp.MustCompile(`(^|[;&|(]\s*|\b(?:sudo|env)\s+)` + regexp.QuoteMeta(name) + `(\s|$|[;&|)])`)
+	// Adjacent occurrences share separators, replace until nothing changes
+	for {
+		pinned := pattern.ReplaceAllString(command, "${1}"+strings.ReplaceAll(path, "$", "$$")+"${2}")
+		if pinned == command {
+			return command
+		}
+		command = pinned
+	}
+}
+
+// normalizeShell maps a shell name to one of the supported dialects
+func normalizeShell(shell string) string {
+	info := types.ProviderInfo{Shell: shell}
+	return info.GetShell()
+}
+
+// powerShellExecutable prefers PowerShell 7, which supports && and || pipeline
+// chains, and falls back to Windows PowerShell. Under WSL the Windows binaries are
+// only reachable through interop with their .exe extension.
+func powerShellExecutable() string {
+	if executable := firstExecutable("pwsh", "powershell", "pwsh.exe", "powershell.exe"); executable != "" {
+		return executable
+	}
+	return "powershell"
+}
+
+// cmdExecutable returns the cmd.exe interpreter, reached as cmd.exe through WSL interop
+func cmdExecutable() string {
+	if executable := firstExecutable("cmd", "cmd.exe"); executable != "" {
+		return executable
+	}
+	return "cmd"
+}
+
+// firstExecutable returns the first candidate found in PATH, or "" if none is found
+func firstExecutable(candidates ...string) string {
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// replaceEnvReferences rewrites POSIX environment references using the given formatter
+func replaceEnvReferences(command string, format func(name string) string) string {
+	return posixEnvPattern.ReplaceAllStringFunc(command, func(match string) string {
+		name := strings.Trim(strings.TrimPrefix(match, "$"), "{}")
+		return format(name)
+	})
+}
@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyShellDialect(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		shell    string
+		expected string
+	}{
+		{
+			name:     "posix unchanged",
+			command:  "echo $HOME > /dev/null",
+			shell:    "",
+			expected: "echo $HOME > /dev/null",
+		},
+		{
+			name:     "powershell env references",
+			command:  "echo $HOME ${APPDATA}",
+			shell:    "powershell",
+			expected: "echo $env:HOME $env:APPDATA",
+		},
+		{
+			name:     "powershell keeps native variables",
+			command:  "Where-Object {$_.Name -eq 'nginx'}",
+			shell:    "powershell",
+			expected: "Where-Object {$_.Name -eq 'nginx'}",
+		},
+		{
+			name:     "powershell line continuation",
+			command:  "choco install nginx \\\n  -y",
+			shell:    "powershell",
+			expected: "choco install nginx `\n  -y",
+		},
+		{
+			name:     "powershell null redirection",
+			command:  "winget show nginx >/dev/null 2>&1",
+			shell:    "pwsh",
+			expected: "winget show nginx >$null 2>&1",
+		},
+		{
+			name:     "powershell sc alias",
+			command:  "sc query nginx | findstr RUNNING",
+			shell:    "powershell",
+			expected: "sc.exe query nginx | findstr RUNNING",
+		},
+		{
+			name:     "cmd env references and continuation",
+			command:  "dir ${USERPROFILE} \\\n  /b",
+			shell:    "cmd",
+			expected: "dir %USERPROFILE% ^\n  /b",
+		},
+		{
+			name:     "cmd quoting and null redirection",
+			command:  "findstr 'hello world' file.txt >/dev/null",
+			shell:    "cmd",
+			expected: "findstr \"hello world\" file.txt >nul",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ApplyShellDialect(tt.command, tt.shell)
+			if result != tt.expected {
+				t.Errorf("ApplyShellDialect() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuoteArgument(t *testing.T) {
+	tests := []struct {
+		arg      string
+		shell    string
+		expected string
+	}{
+		{"it's", "sh", `'it'\''s'`},
+		{"it's", "powershell", `'it''s'`},
+		{`say "hi"`, "cmd", `"say ""hi"""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			result := QuoteArgument(tt.arg, tt.shell)
+			if result != tt.expected {
+				t.Errorf("QuoteArgument() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShellCommandArgs(t *testing.T) {
+	args := shellCommandArgs("echo hello world", "")
+	if len(args) != 3 || args[0] != "echo" {
+		t.Errorf("expected direct execution for POSIX commands, got %v", args)
+	}
+
+	args = shellCommandArgs("dir /b", "cmd")
+	if len(args) != 3 || args[0] != "cmd" || args[1] != "/C" || args[2] != "dir /b" {
+		t.Errorf("expected cmd /C wrapper, got %v", args)
+	}
+
+	args = shellCommandArgs("Get-Service nginx", "powershell")
+	if len(args) != 5 || args[3] != "-Command" || args[4] != "Get-Service nginx" {
+		t.Errorf("expected PowerShell -Command wrapper, got %v", args)
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected []string
+	}{
+		{"apt-get install -y nginx", []string{"apt-get", "install", "-y", "nginx"}},
+		{`apt-get install -y 'x; rm -rf /' nginx`, []string{"apt-get", "install", "-y", "x; rm -rf /", "nginx"}},
+		{`cat '/etc/my app/it'\''s.conf'`, []string{"cat", "/etc/my app/it's.conf"}},
+		{`echo "a \"b\" $HOME" c\ d ''`, []string{"echo", `a "b" $HOME`, "c d", ""}},
+		{`dpkg-query -W -f='${Version}' nginx`, []string{"dpkg-query", "-W", "-f=${Version}", "nginx"}},
+		{`echo 'unbalanced`, []string{"echo", "'unbalanced"}},
+	}
+
+	for _, tt := range tests {
+		if result := splitWords(tt.command); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.command, result, tt.expected)
+		}
+	}
+}
+
+func TestPinExecutable(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		path     string
+		expected string
+	}{
+		{
+			name:     "command start",
+			command:  "apt install -y nginx",
+			path:     "/usr/bin/apt",
+			expected: "/usr/bin/apt install -y nginx",
+		},
+		{
+			name:     "after separators and sudo",
+			command:  "apt update && sudo apt install -y nginx; apt list | apt show",
+			path:     "/usr/bin/apt",
+			expected: "/usr/bin/apt update && sudo /usr/bin/apt install -y nginx; /usr/bin/apt list | /usr/bin/apt show",
+		},
+		{
+			name:     "arguments and longer names unchanged",
+			command:  "dpkg -l apt && apt-get install apt",
+			path:     "/usr/bin/apt",
+			expected: "dpkg -l apt && apt-get install apt",
+		},
+		{
+			name:     "bare command",
+			command:  "brew",
+			path:     "/opt/homebrew/bin/brew",
+			expected: "/opt/homebrew/bin/brew",
+		},
+		{
+			name:     "no path",
+			command:  "apt install -y nginx",
+			path:     "",
+			expected: "apt install -y nginx",
+		},
+		{
+			name:     "path with spaces",
+			command:  "tool install",
+			path:     "/opt/my tools/tool",
+			expected: "tool install",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := PinExecutable(tt.command, tt.path); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	command := "apt-get update && sudo apt-get install -y nginx && dpkg -l apt-get"
+	expected := "/usr/local/bin/apt-fast update && sudo /usr/local/bin/apt-fast install -y nginx && dpkg -l apt-get"
+	if result := ReplaceExecutable(command, "apt-get", "/usr/local/bin/apt-fast"); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	if result := ReplaceExecutable(command, "", "/usr/local/bin/apt-fast"); result != command {
+		t.Errorf("expected the command unchanged without a name, got %q", result)
+	}
+}
@@ -0,0 +1,479 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// DefaultMaxParallelSteps is the number of steps run at once when an action does not set max_parallel
+const DefaultMaxParallelSteps = 4
+
+// stepOutcome is the result of running one step of a multi-step action
+type stepOutcome struct {
+	command  string // Rendered command, empty when the step did not get that far
+	output   string // Command output, set when the command ran
+	ran      bool   // The command was executed
+	exitCode int
+	failure  error // Error failing the action, nil on success or ignored failure
+	err      error // Underlying error of the failure
+	changes  []interfaces.Change
+}
+
+// storeStepOutput stores the trimmed output of a step in the runtime context under its
+// capture and register names. Registering captures under a custom name, so registered
+// variables are the runtime context values.
+func (ge *GenericExecutor) storeStepOutput(index int, step types.Step, output string, runtime *interfaces.RuntimeContext) error {
+	value := strings.TrimSpace(output)
+
+	for _, name := range []string{step.Capture, step.Register} {
+		if name == "" {
+			continue
+		}
+		if err := runtime.Set(name, value); err != nil {
+			return err
+		}
+		ge.logger.Debug("Captured step output",
+			interfaces.LogField{Key: "step", Value: index + 1},
+			interfaces.LogField{Key: "name", Value: name},
+			interfaces.LogField{Key: "value", Value: value},
+		)
+	}
+	return nil
+}
+
+// runStep runs a single step. Rendering and condition evaluation hold the read lock of
+// runtimeMutex, storing a capture or registered variable holds the write lock, so steps running in parallel
+// can share the runtime context.
+func (ge *GenericExecutor) runStep(
+	ctx context.Context,
+	index int,
+	step types.Step,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+	runtimeMutex *sync.RWMutex,
+	checkpoint *stepCheckpoint,
+) stepOutcome {
+	var outcome stepOutcome
+
+	ge.logger.Debug("Executing step",
+		interfaces.LogField{Key: "step", Value: index + 1},
+		interfaces.LogField{Key: "name", Value: step.Name},
+	)
+
+	runtimeMutex.RLock()
+	shouldExecute, rendered, err := ge.prepareStep(index, step, saidata, provider, options)
+	primitive := step
+	if shouldExecute && err == nil && step.IsWindowsPrimitive() {
+		primitive, err = ge.renderWindowsStep(step, saidata, provider, options)
+	}
+	key := ""
+	if shouldExecute && err == nil && checkpoint != nil && checkpointed(step) {
+		key, err = ge.stepKey(index, step, rendered, saidata, provider, options)
+	}
+	runtimeMutex.RUnlock()
+
+	if !shouldExecute {
+		ge.logger.Debug("Skipping step due to condition",
+			interfaces.LogField{Key: "step", Value: index + 1},
+		)
+		return outcome
+	}
+	if err != nil {
+		if step.IgnoreFailure {
+			ge.logger.Warn("Step command rendering failed, ignoring",
+				interfaces.LogField{Key: "step", Value: index + 1},
+				interfaces.LogField{Key: "error", Value: err},
+			)
+			return outcome
+		}
+		outcome.exitCode = 1
+		outcome.failure = fmt.Errorf("failed to render step %d command: %w", index+1, err)
+		outcome.err = err
+		return outcome
+	}
+
+	if step.IsWindowsPrimitive() {
+		return ge.runWindowsStep(ctx, index, primitive, options, runtimeMutex)
+	}
+
+	outcome.command = rendered
+
+	// Skip the step completed by an earlier run, restoring its output for later steps
+	if key != "" {
+		if completed, found := checkpoint.completed(key); found {
+			return ge.resumeStep(index, step, rendered, completed, options, runtimeMutex)
+		}
+	}
+
+	// Execute step command
+	stepTimeout := options.Timeout
+	if step.Timeout > 0 {
+		stepTimeout = time.Duration(step.Timeout) * time.Second
+	}
+
+	cmdOptions := interfaces.CommandOptions{
+		Timeout:      stepTimeout,
+		WorkDir:      options.WorkDir,
+		Env:          options.Env,
+		Verbose:      options.Verbose,
+		Shell:        provider.Provider.GetShell(),
+		StallTimeout: options.StallTimeout,
+	}
+
+	result, err := ge.commandExecutor.ExecuteCommand(ctx, rendered, cmdOptions)
+	if result != nil {
+		outcome.ran = true
+		outcome.output = result.Output
+		outcome.exitCode = result.ExitCode
+	}
+
+	if err != nil || (result != nil && result.ExitCode != 0) {
+		if step.IgnoreFailure {
+			ge.logger.Warn("Step failed, ignoring",
+				interfaces.LogField{Key: "step", Value: index + 1},
+				interfaces.LogField{Key: "error", Value: err},
+			)
+			return outcome
+		}
+		outcome.failure = fmt.Errorf("step %d failed: %w", index+1, err)
+		outcome.err = err
+		return outcome
+	}
+
+	// Make the step output available to the following steps
+	if result != nil {
+		runtimeMutex.Lock()
+		err := ge.storeStepOutput(index, step, result.Output, options.Runtime)
+		runtimeMutex.Unlock()
+
+		if err != nil {
+			outcome.exitCode = 1
+			outcome.failure = fmt.Errorf("step %d capture failed: %w", index+1, err)
+			outcome.err = err
+			return outcome
+		}
+	}
+
+	// Record how to undo the step, for transactions to roll it back
+	if step.Rollback != "" {
+		runtimeMutex.RLock()
+		// The extra arguments are those of the action, not of the command undoing it
+		rollbackOptions := options
+		rollbackOptions.ExtraArgs = nil
+		rollback, err := ge.renderCommand(step.Rollback, "", saidata, provider, rollbackOptions)
+		runtimeMutex.RUnlock()
+
+		if err != nil {
+			ge.logger.Warn("Step rollback rendering failed, the step cannot be rolled back",
+				interfaces.LogField{Key: "step", Value: index + 1},
+				interfaces.LogField{Key: "error", Value: err},
+			)
+		} else {
+			resource := step.Name
+			if resource == "" {
+				resource = fmt.Sprintf("step %d", index+1)
+			}
+			outcome.changes = append(outcome.changes, interfaces.Change{
+				Type:        "command",
+				Resource:    resource,
+				Action:      "executed",
+				NewValue:    rendered,
+				Reversible:  true,
+				RollbackCmd: rollback,
+			})
+		}
+	}
+
+	// Record the step, so a failure of a later one does not run it again
+	if key != "" && result != nil {
+		completed := completedStep{Name: step.Name, Completed: time.Now()}
+		if step.Capture != "" || step.Register != "" {
+			completed.Output = strings.TrimSpace(result.Output)
+		}
+		if err := checkpoint.complete(key, completed); err != nil {
+			ge.logger.Warn("Failed to checkpoint step",
+				interfaces.LogField{Key: "step", Value: index + 1},
+				interfaces.LogField{Key: "error", Value: err},
+			)
+		}
+	}
+
+	ge.logger.Debug("Step completed successfully",
+		interfaces.LogField{Key: "step", Value: index + 1},
+	)
+	return outcome
+}
+
+// resumeStep skips a step completed by an earlier run, storing the output it had for
+// the following steps
+func (ge *GenericExecutor) resumeStep(
+	index int,
+	step types.Step,
+	rendered string,
+	completed completedStep,
+	options interfaces.ExecuteOptions,
+	runtimeMutex *sync.RWMutex,
+) stepOutcome {
+	outcome := stepOutcome{command: rendered, ran: true}
+	name := step.Name
+	if name == "" {
+		name = fmt.Sprintf("%d", index+1)
+	}
+	outcome.output = fmt.Sprintf("Step %s completed on %s, skipped", name, completed.Completed.Format(time.RFC3339))
+
+	runtimeMutex.Lock()
+	err := ge.storeStepOutput(index, step, completed.Output, options.Runtime)
+	runtimeMutex.Unlock()
+	if err != nil {
+		outcome.exitCode = 1
+		outcome.failure = fmt.Errorf("step %d capture failed: %w", index+1, err)
+		outcome.err = err
+	}
+	return outcome
+}
+
+// prepareStep evaluates the condition of a step and renders its command
+func (ge *GenericExecutor) prepareStep(
+	index int,
+	step types.Step,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+) (bool, string, error) {
+	// Check step condition if present
+	if step.Condition != "" {
+		shouldExecute, err := ge.evaluateCondition(step.Condition, saidata, provider, options.Runtime)
+		if err != nil {
+			ge.logger.Warn("Failed to evaluate step condition",
+				interfaces.LogField{Key: "step", Value: index + 1},
+				interfaces.LogField{Key: "condition", Value: step.Condition},
+				interfaces.LogField{Key: "error", Value: err},
+			)
+		}
+		if !shouldExecute {
+			return false, "", nil
+		}
+	}
+
+	if step.IsWindowsPrimitive() {
+		return true, "", nil
+	}
+
+	rendered, err := ge.renderCommand(step.Command, "", saidata, provider, options)
+	return true, rendered, err
+}
+
+// executeStepGraph runs steps as a dependency graph: every step starts once the steps
+// it depends on completed, with at most options.MaxParallelSteps running at once. A
+// failing step cancels the steps still running and prevents its dependents from
+// starting. Output and commands are collected in step order regardless of the order
+// in which steps finish.
+func (ge *GenericExecutor) executeStepGraph(
+	ctx context.Context,
+	steps []types.Step,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+	checkpoint *stepCheckpoint,
+) (*interfaces.ExecutionResult, error) {
+	startTime := time.Now()
+
+	dependencies, err := stepDependencies(steps)
+	if err != nil {
+		return &interfaces.ExecutionResult{
+			Success:  false,
+			Error:    err,
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+			Provider: provider.Provider.Name,
+			Runtime:  options.Runtime,
+		}, err
+	}
+
+	limit := options.MaxParallelSteps
+	if limit <= 0 {
+		limit = DefaultMaxParallelSteps
+	}
+
+	graphCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outcomes := make([]stepOutcome, len(steps))
+	failed := make([]bool, len(steps))
+	done := make([]chan struct{}, len(steps))
+	for i := range steps {
+		done[i] = make(chan struct{})
+	}
+
+	semaphore := make(chan struct{}, limit)
+	var runtimeMutex sync.RWMutex
+	var failureMutex sync.Mutex
+	firstFailure := -1
+
+	var wg sync.WaitGroup
+	for i, step := range steps {
+		wg.Add(1)
+		go func(i int, step types.Step) {
+			defer wg.Done()
+			defer close(done[i])
+
+			for _, dependency := range dependencies[i] {
+				<-done[dependency]
+				if failed[dependency] {
+					failed[i] = true
+					return
+				}
+			}
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-graphCtx.Done():
+				failed[i] = true
+				return
+			}
+			if graphCtx.Err() != nil {
+				failed[i] = true
+				return
+			}
+
+			outcomes[i] = ge.runStep(graphCtx, i, step, saidata, provider, options, &runtimeMutex, checkpoint)
+			if outcomes[i].failure != nil {
+				failed[i] = true
+
+				failureMutex.Lock()
+				if firstFailure < 0 {
+					firstFailure = i
+					cancel()
+				}
+				failureMutex.Unlock()
+			}
+		}(i, step)
+	}
+	wg.Wait()
+
+	var allOutput strings.Builder
+	var allCommands []string
+	var changes []interfaces.Change
+	for _, outcome := range outcomes {
+		changes = append(changes, outcome.changes...)
+		if outcome.command != "" {
+			allCommands = append(allCommands, outcome.command)
+		}
+		if outcome.ran {
+			allOutput.WriteString(outcome.output)
+			allOutput.WriteString("\n")
+		}
+	}
+
+	result := &interfaces.ExecutionResult{
+		Success:  firstFailure < 0,
+		Output:   allOutput.String(),
+		Duration: time.Since(startTime),
+		Commands: allCommands,
+		Provider: provider.Provider.Name,
+		Changes:  changes,
+		Runtime:  options.Runtime,
+	}
+	if firstFailure >= 0 {
+		result.Error = outcomes[firstFailure].failure
+		result.ExitCode = outcomes[firstFailure].exitCode
+		return result, outcomes[firstFailure].err
+	}
+	return result, nil
+}
+
+// hasStepDependencies reports whether any step declares depends_on
+func hasStepDependencies(steps []types.Step) bool {
+	for _, step := range steps {
+		if len(step.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// stepDependencies resolves the depends_on names of every step to step indexes and
+// rejects unknown names, duplicate step names and dependency cycles
+func stepDependencies(steps []types.Step) ([][]int, error) {
+	indexes := make(map[string]int)
+	for i, step := range steps {
+		if step.Name == "" {
+			continue
+		}
+		if _, exists := indexes[step.Name]; exists {
+			return nil, fmt.Errorf("duplicate step name %q", step.Name)
+		}
+		indexes[step.Name] = i
+	}
+
+	dependencies := make([][]int, len(steps))
+	for i, step := range steps {
+		for _, name := range step.DependsOn {
+			dependency, exists := indexes[name]
+			if !exists {
+				return nil, fmt.Errorf("step %s depends on unknown step %q", stepLabel(steps, i), name)
+			}
+			dependencies[i] = append(dependencies[i], dependency)
+		}
+	}
+
+	// Depth-first search for cycles
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(steps))
+	var path []int
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			var names []string
+			for j := len(path) - 1; j >= 0; j-- {
+				names = append([]string{stepLabel(steps, path[j])}, names...)
+				if path[j] == i {
+					break
+				}
+			}
+			names = append(names, stepLabel(steps, i))
+			return fmt.Errorf("steps form a dependency cycle: %s", strings.Join(names, " -> "))
+		case visited:
+			return nil
+		}
+
+		state[i] = visiting
+		path = append(path, i)
+		for _, dependency := range dependencies[i] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+	for i := range steps {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return dependencies, nil
+}
+
+// stepLabel returns the name of a step, or its position when it has none
+func stepLabel(steps []types.Step, index int) string {
+	if steps[index].Name != "" {
+		return steps[index].Name
+	}
+	return fmt.Sprintf("%d", index+1)
+}
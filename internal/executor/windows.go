@@ -0,0 +1,364 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+// registryHives maps registry hive names and abbreviations to the names used by the
+// PowerShell registry provider
+var registryHives = map[string]string{
+	"HKLM":                "HKEY_LOCAL_MACHINE",
+	"HKEY_LOCAL_MACHINE":  "HKEY_LOCAL_MACHINE",
+	"HKCU":                "HKEY_CURRENT_USER",
+	"HKEY_CURRENT_USER":   "HKEY_CURRENT_USER",
+	"HKCR":                "HKEY_CLASSES_ROOT",
+	"HKEY_CLASSES_ROOT":   "HKEY_CLASSES_ROOT",
+	"HKU":                 "HKEY_USERS",
+	"HKEY_USERS":          "HKEY_USERS",
+	"HKCC":                "HKEY_CURRENT_CONFIG",
+	"HKEY_CURRENT_CONFIG": "HKEY_CURRENT_CONFIG",
+}
+
+// envNamePattern matches names accepted for Windows environment variables
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.()-]*$`)
+
+// runWindowsStep runs a step using a Windows primitive. The primitives are executed as
+// generated PowerShell commands with every value quoted, so saidata values cannot
+// inject commands. Environment changes are returned as changes carrying the previous
+// value and the command undoing them.
+func (ge *GenericExecutor) runWindowsStep(
+	ctx context.Context,
+	index int,
+	step types.Step,
+	options interfaces.ExecuteOptions,
+	runtimeMutex *sync.RWMutex,
+) stepOutcome {
+	var outcome stepOutcome
+
+	command, err := windowsStepCommand(step)
+	if err != nil {
+		return ge.failStep(index, step, outcome, fmt.Errorf("invalid step %d: %w", index+1, err), err)
+	}
+	outcome.command = command
+
+	cmdOptions := interfaces.CommandOptions{
+		Timeout:      options.Timeout,
+		Env:          options.Env,
+		Verbose:      options.Verbose,
+		Shell:        types.ShellPowerShell,
+		StallTimeout: options.StallTimeout,
+	}
+	if step.Timeout > 0 {
+		cmdOptions.Timeout = time.Duration(step.Timeout) * time.Second
+	}
+	run := func(command string) (string, error) {
+		result, err := ge.commandExecutor.ExecuteCommand(ctx, command, cmdOptions)
+		if err == nil && result != nil && result.ExitCode != 0 {
+			err = fmt.Errorf("exit code %d: %s", result.ExitCode, strings.TrimSpace(result.Output))
+		}
+		if result == nil {
+			return "", err
+		}
+		return strings.TrimRight(result.Output, "\r\n"), err
+	}
+
+	switch {
+	case step.SetEnv != nil:
+		scope, _ := envScope(step.SetEnv.Scope)
+		previous, err := run(getEnvCommand(step.SetEnv.Name, scope))
+		if err == nil {
+			_, err = run(command)
+		}
+		if err != nil {
+			return ge.failStep(index, step, outcome, fmt.Errorf("step %d failed: %w", index+1, err), err)
+		}
+
+		action := "modified"
+		if previous == "" {
+			action = "created"
+		}
+		outcome.ran = true
+		outcome.output = fmt.Sprintf("Set %s environment variable %s", strings.ToLower(scope), step.SetEnv.Name)
+		outcome.changes = append(outcome.changes, interfaces.Change{
+			Type:        "environment",
+			Resource:    strings.ToLower(scope) + ":" + step.SetEnv.Name,
+			Action:      action,
+			OldValue:    previous,
+			NewValue:    step.SetEnv.Value,
+			Reversible:  true,
+			RollbackCmd: setEnvCommand(step.SetEnv.Name, previous, scope),
+		})
+
+	case step.AddPath != nil:
+		scope, _ := envScope(step.AddPath.Scope)
+		current, err := run(getPathCommand(scope))
+		if err != nil {
+			return ge.failStep(index, step, outcome, fmt.Errorf("step %d failed: %w", index+1, err), err)
+		}
+
+		outcome.ran = true
+		if pathContains(current, step.AddPath.Path) {
+			outcome.output = fmt.Sprintf("%s is already in the %s PATH", step.AddPath.Path, strings.ToLower(scope))
+			break
+		}
+
+		// The command itself skips existing entries, so a concurrent change is harmless
+		if _, err := run(command); err != nil {
+			return ge.failStep(index, step, outcome, fmt.Errorf("step %d failed: %w", index+1, err), err)
+		}
+		outcome.output = fmt.Sprintf("Added %s to the %s PATH", step.AddPath.Path, strings.ToLower(scope))
+		outcome.changes = append(outcome.changes, interfaces.Change{
+			Type:        "environment",
+			Resource:    strings.ToLower(scope) + ":Path",
+			Action:      "modified",
+			OldValue:    current,
+			NewValue:    appendPathEntry(current, step.AddPath.Path),
+			Reversible:  true,
+			RollbackCmd: removePathCommand(step.AddPath.Path, scope),
+		})
+
+	case step.QueryRegistry != nil:
+		value, err := run(command)
+		if err != nil {
+			return ge.failStep(index, step, outcome, fmt.Errorf("step %d failed: %w", index+1, err), err)
+		}
+		outcome.ran = true
+		outcome.output = value
+	}
+
+	if step.Capture != "" || step.Register != "" {
+		runtimeMutex.Lock()
+		err := ge.storeStepOutput(index, step, outcome.output, options.Runtime)
+		runtimeMutex.Unlock()
+		if err != nil {
+			return ge.failStep(index, step, outcome, fmt.Errorf("step %d capture failed: %w", index+1, err), err)
+		}
+	}
+
+	ge.logger.Debug("Step completed successfully",
+		interfaces.LogField{Key: "step", Value: index + 1},
+	)
+	return outcome
+}
+
+// failStep records a step failure, unless the step ignores failures
+func (ge *GenericExecutor) failStep(index int, step types.Step, outcome stepOutcome, failure, err error) stepOutcome {
+	if step.IgnoreFailure {
+		ge.logger.Warn("Step failed, ignoring",
+			interfaces.LogField{Key: "step", Value: index + 1},
+			interfaces.LogField{Key: "error", Value: err},
+		)
+		return outcome
+	}
+	outcome.exitCode = 1
+	outcome.failure = failure
+	outcome.err = err
+	return outcome
+}
+
+// renderWindowsStep renders the templates of a Windows primitive. Values are rendered
+// without shell dialect translation since they are quoted, not run.
+func (ge *GenericExecutor) renderWindowsStep(
+	step types.Step,
+	saidata *types.SoftwareData,
+	provider *types.ProviderData,
+	options interfaces.ExecuteOptions,
+) (types.Step, error) {
+	context := &interfaces.TemplateContext{
+		Provider:  provider.Provider.Name,
+		Saidata:   saidata,
+		Variables: options.Variables,
+		Runtime:   options.Runtime,
+		Root:      ge.root,
+		Version:   options.Version,
+	}
+
+	var err error
+	render := func(value string) string {
+		if err != nil || !strings.Contains(value, "{{") {
+			return value
+		}
+		var rendered string
+		rendered, err = ge.render(value, context)
+		return rendered
+	}
+
+	switch {
+	case step.SetEnv != nil:
+		setEnv := *step.SetEnv
+		setEnv.Name = render(setEnv.Name)
+		setEnv.Value = render(setEnv.Value)
+		step.SetEnv = &setEnv
+	case step.AddPath != nil:
+		addPath := *step.AddPath
+		addPath.Path = render(addPath.Path)
+		step.AddPath = &addPath
+	case step.QueryRegistry != nil:
+		query := *step.QueryRegistry
+		query.Key = render(query.Key)
+		query.Value = render(query.Value)
+		step.QueryRegistry = &query
+	}
+
+	return step, err
+}
+
+// windowsStepCommand validates a rendered Windows primitive and returns the PowerShell
+// command it runs: the change for set_env and add_path, the query for query_registry
+func windowsStepCommand(step types.Step) (string, error) {
+	primitives := 0
+	for _, set := range []bool{step.SetEnv != nil, step.AddPath != nil, step.QueryRegistry != nil} {
+		if set {
+			primitives++
+		}
+	}
+	if primitives != 1 || step.Command != "" {
+		return "", fmt.Errorf("a step uses either a command or exactly one of set_env, add_path and query_registry")
+	}
+
+	switch {
+	case step.SetEnv != nil:
+		if !envNamePattern.MatchString(step.SetEnv.Name) {
+			return "", fmt.Errorf("invalid environment variable name %q", step.SetEnv.Name)
+		}
+		if strings.EqualFold(step.SetEnv.Name, "Path") {
+			return "", fmt.Errorf("use add_path to change PATH")
+		}
+		scope, err := envScope(step.SetEnv.Scope)
+		if err != nil {
+			return "", err
+		}
+		return setEnvCommand(step.SetEnv.Name, step.SetEnv.Value, scope), nil
+
+	case step.AddPath != nil:
+		if strings.TrimSpace(step.AddPath.Path) == "" || strings.Contains(step.AddPath.Path, ";") {
+			return "", fmt.Errorf("invalid PATH entry %q", step.AddPath.Path)
+		}
+		scope, err := envScope(step.AddPath.Scope)
+		if err != nil {
+			return "", err
+		}
+		return addPathCommand(step.AddPath.Path, scope), nil
+
+	default:
+		path, err := registryPath(step.QueryRegistry.Key)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("$value = (Get-Item -LiteralPath %s -ErrorAction Stop).GetValue(%s); "+
+			"if ($null -eq $value) { Write-Error 'registry value not found'; exit 1 }; $value",
+			QuoteArgument(path, types.ShellPowerShell),
+			QuoteArgument(step.QueryRegistry.Value, types.ShellPowerShell)), nil
+	}
+}
+
+// envScope returns the .NET environment target of a scope
+func envScope(scope string) (string, error) {
+	switch strings.ToLower(scope) {
+	case "", types.EnvScopeUser:
+		return "User", nil
+	case types.EnvScopeMachine:
+		return "Machine", nil
+	}
+	return "", fmt.Errorf("invalid environment scope %q, must be %s or %s", scope, types.EnvScopeUser, types.EnvScopeMachine)
+}
+
+// getEnvCommand returns the PowerShell command printing a persistent environment variable
+func getEnvCommand(name, scope string) string {
+	return fmt.Sprintf("[Environment]::GetEnvironmentVariable(%s, '%s')",
+		QuoteArgument(name, types.ShellPowerShell), scope)
+}
+
+// setEnvCommand returns the PowerShell command setting a persistent environment
+// variable, removing it when value is empty
+func setEnvCommand(name, value, scope string) string {
+	quoted := "$null"
+	if value != "" {
+		quoted = QuoteArgument(value, types.ShellPowerShell)
+	}
+	return fmt.Sprintf("[Environment]::SetEnvironmentVariable(%s, %s, '%s')",
+		QuoteArgument(name, types.ShellPowerShell), quoted, scope)
+}
+
+// environmentKey returns the registry key holding the persistent environment of a scope
+func environmentKey(scope string) string {
+	if scope == "Machine" {
+		return `Registry::HKEY_LOCAL_MACHINE\SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
+	}
+	return `Registry::HKEY_CURRENT_USER\Environment`
+}
+
+// getPathCommand returns the PowerShell command printing the persistent PATH with
+// references such as %SystemRoot% left unexpanded
+func getPathCommand(scope string) string {
+	return fmt.Sprintf("(Get-Item -LiteralPath %s).GetValue('Path', '', 'DoNotExpandEnvironmentNames')",
+		QuoteArgument(environmentKey(scope), types.ShellPowerShell))
+}
+
+// addPathCommand returns the PowerShell command appending an entry to the persistent
+// PATH unless present. PATH is written through the registry as an expandable string,
+// since [Environment]::SetEnvironmentVariable would expand references such as
+// %SystemRoot% for good.
+func addPathCommand(entry, scope string) string {
+	return fmt.Sprintf("$path = %[3]s; "+
+		"if (-not (($path -split ';') | Where-Object { $_.TrimEnd('\\') -eq %[2]s })) { "+
+		"Set-ItemProperty -LiteralPath %[1]s -Name 'Path' -Value ((@($path.TrimEnd(';'), %[4]s) | Where-Object { $_ }) -join ';') -Type ExpandString }",
+		QuoteArgument(environmentKey(scope), types.ShellPowerShell),
+		QuoteArgument(strings.TrimRight(entry, `\`), types.ShellPowerShell),
+		getPathCommand(scope),
+		QuoteArgument(entry, types.ShellPowerShell))
+}
+
+// removePathCommand returns the PowerShell command removing an entry from the
+// persistent PATH, leaving entries added since untouched
+func removePathCommand(entry, scope string) string {
+	return fmt.Sprintf("$path = %[3]s; "+
+		"Set-ItemProperty -LiteralPath %[1]s -Name 'Path' -Value ((($path -split ';') | Where-Object { $_ -and $_.TrimEnd('\\') -ne %[2]s }) -join ';') -Type ExpandString",
+		QuoteArgument(environmentKey(scope), types.ShellPowerShell),
+		QuoteArgument(strings.TrimRight(entry, `\`), types.ShellPowerShell),
+		getPathCommand(scope))
+}
+
+// registryPath converts a registry key such as HKLM\SOFTWARE\App to a PowerShell
+// registry provider path
+func registryPath(key string) (string, error) {
+	key = strings.Trim(strings.ReplaceAll(key, "/", `\`), `\`)
+	hive, rest, _ := strings.Cut(key, `\`)
+	fullHive, ok := registryHives[strings.ToUpper(strings.TrimSuffix(hive, ":"))]
+	if !ok {
+		return "", fmt.Errorf("invalid registry key %q, must start with a hive such as HKLM or HKCU", key)
+	}
+	if rest == "" {
+		return "Registry::" + fullHive, nil
+	}
+	return "Registry::" + fullHive + `\` + rest, nil
+}
+
+// pathContains reports whether a PATH value contains an entry, ignoring case and
+// trailing backslashes like Windows does
+func pathContains(path, entry string) bool {
+	entry = strings.TrimRight(entry, `\`)
+	for _, existing := range strings.Split(path, ";") {
+		if strings.EqualFold(strings.TrimRight(existing, `\`), entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendPathEntry appends an entry to a PATH value
+func appendPathEntry(path, entry string) string {
+	path = strings.TrimRight(path, ";")
+	if path == "" {
+		return entry
+	}
+	return path + ";" + entry
+}
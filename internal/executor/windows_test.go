@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/interfaces"
+	"sai/internal/types"
+)
+
+func TestWindowsStepCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		step        types.Step
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "set user environment variable",
+			step:     types.Step{SetEnv: &types.EnvVarStep{Name: "TOOL_HOME", Value: `C:\Tool's`}},
+			expected: `[Environment]::SetEnvironmentVariable('TOOL_HOME', 'C:\Tool''s', 'User')`,
+		},
+		{
+			name:     "set machine environment variable",
+			step:     types.Step{SetEnv: &types.EnvVarStep{Name: "TOOL_HOME", Value: `C:\Tool`, Scope: "machine"}},
+			expected: `[Environment]::SetEnvironmentVariable('TOOL_HOME', 'C:\Tool', 'Machine')`,
+		},
+		{
+			name:        "injection in variable name",
+			step:        types.Step{SetEnv: &types.EnvVarStep{Name: "X'); Remove-Item C:\\ #", Value: "v"}},
+			expectedErr: "invalid environment variable name",
+		},
+		{
+			name:        "PATH through set_env",
+			step:        types.Step{SetEnv: &types.EnvVarStep{Name: "Path", Value: `C:\Tool`}},
+			expectedErr: "use add_path to change PATH",
+		},
+		{
+			name:        "invalid scope",
+			step:        types.Step{AddPath: &types.PathStep{Path: `C:\Tool\bin`, Scope: "system"}},
+			expectedErr: `invalid environment scope "system"`,
+		},
+		{
+			name:        "multiple PATH entries",
+			step:        types.Step{AddPath: &types.PathStep{Path: `C:\a;C:\b`}},
+			expectedErr: "invalid PATH entry",
+		},
+		{
+			name: "query registry value",
+			step: types.Step{QueryRegistry: &types.RegistryQueryStep{Key: `HKLM\SOFTWARE\Vendor\App`, Value: "InstallDir"}},
+			expected: `$value = (Get-Item -LiteralPath 'Registry::HKEY_LOCAL_MACHINE\SOFTWARE\Vendor\App' -ErrorAction Stop).GetValue('InstallDir'); ` +
+				`if ($null -eq $value) { Write-Error 'registry value not found'; exit 1 }; $value`,
+		},
+		{
+			name:        "unknown registry hive",
+			step:        types.Step{QueryRegistry: &types.RegistryQueryStep{Key: `SOFTWARE\Vendor`}},
+			expectedErr: "must start with a hive",
+		},
+		{
+			name:        "command and primitive",
+			step:        types.Step{Command: "echo", AddPath: &types.PathStep{Path: `C:\Tool`}},
+			expectedErr: "either a command or exactly one",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := windowsStepCommand(tt.step)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, command)
+		})
+	}
+}
+
+func TestPathEntries(t *testing.T) {
+	path := `%SystemRoot%\system32;C:\Tool\bin\;C:\Other`
+
+	assert.True(t, pathContains(path, `c:\tool\bin`))
+	assert.True(t, pathContains(path, `C:\Other\`))
+	assert.False(t, pathContains(path, `C:\Tool`))
+
+	assert.Equal(t, `C:\a;C:\b`, appendPathEntry(`C:\a;`, `C:\b`))
+	assert.Equal(t, `C:\b`, appendPathEntry("", `C:\b`))
+
+	// Undo removes only the added entry and keeps the value expandable
+	undo := removePathCommand(`C:\Tool\bin\`, "Machine")
+	assert.Contains(t, undo, `-ne 'C:\Tool\bin'`)
+	assert.Contains(t, undo, `Session Manager\Environment`)
+	assert.Contains(t, undo, "-Type ExpandString")
+}
+
+func TestDryRun_WindowsPrimitives(t *testing.T) {
+	logger := &MockLogger{}
+	validator := &MockResourceValidator{}
+	templateEngine := &MockTemplateEngine{
+		renderFunc: func(template string, context *interfaces.TemplateContext) (string, error) {
+			return `C:\Program Files\` + context.Variables["app"], nil
+		},
+	}
+	executor := NewGenericExecutor(NewCommandExecutor(logger, validator), templateEngine, logger, validator)
+
+	provider := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "winget", Shell: "powershell"},
+		Actions: map[string]types.Action{
+			"install": {
+				Steps: []types.Step{
+					{Name: "add to PATH", AddPath: &types.PathStep{Path: "{{.Variables.app}}", Scope: "machine"}},
+				},
+			},
+		},
+	}
+
+	options := interfaces.ExecuteOptions{DryRun: true, Variables: map[string]string{"app": "Tool"}}
+	result, err := executor.DryRun(context.Background(), provider, "install", "tool", nil, options)
+
+	require.NoError(t, err)
+	require.Len(t, result.Commands, 1)
+	assert.Contains(t, result.Commands[0], `-eq 'C:\Program Files\Tool'`)
+	assert.Contains(t, result.Commands[0], "-Type ExpandString")
+}
@@ -0,0 +1,130 @@
+package fileutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// backupSuffix is appended to the previous good copy of a state file
+const backupSuffix = ".bak"
+
+// WriteFileAtomic writes data to a temporary file in the target directory, syncs it
+// to disk and renames it over the target so readers never observe a partial file
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	// Clean up the temporary file on any failure
+	success := false
+	defer func() {
+		if !success {
+			tmpFile.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set permissions on temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	success = true
+
+	syncDir(dir)
+	return nil
+}
+
+// WriteJSONAtomic marshals v as indented JSON and writes it atomically, keeping the
+// previous version as a backup used by ReadJSON for recovery
+func WriteJSONAtomic(path string, v interface{}, perm os.FileMode) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Keep the last good copy before replacing it
+	if existing, err := os.ReadFile(path); err == nil && json.Valid(existing) {
+		if err := WriteFileAtomic(path+backupSuffix, existing, perm); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+
+	return WriteFileAtomic(path, data, perm)
+}
+
+// ReadJSON reads and unmarshals a JSON state file. Corrupted files are moved aside
+// and the last good backup is restored; os.ErrNotExist is returned if nothing is
+// recoverable. A missing file is not recovered, it may have been removed on purpose.
+func ReadJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err == nil {
+		return nil
+	}
+	quarantine(path)
+
+	// Attempt recovery from the last good backup
+	backup, backupErr := os.ReadFile(path + backupSuffix)
+	if backupErr != nil || json.Unmarshal(backup, v) != nil {
+		return fmt.Errorf("%s is corrupted and no valid backup exists: %w", path, os.ErrNotExist)
+	}
+
+	// Restore the backup so subsequent reads are fast
+	if restoreErr := WriteFileAtomic(path, backup, 0644); restoreErr != nil {
+		return fmt.Errorf("recovered %s from backup but failed to restore it: %w", path, restoreErr)
+	}
+
+	return nil
+}
+
+// RemoveWithBackup removes a state file together with its backup
+func RemoveWithBackup(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(path + backupSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// quarantine moves a corrupted file aside so it can be inspected later
+func quarantine(path string) {
+	corruptPath := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102150405"))
+	os.Rename(path, corruptPath)
+}
+
+// syncDir flushes directory metadata so a completed rename survives a crash
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
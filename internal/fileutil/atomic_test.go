@@ -0,0 +1,114 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testState struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.yaml")
+
+	require.NoError(t, WriteFileAtomic(path, []byte("key: value\n"), 0600))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "key: value\n", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// No temporary files should be left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestReadJSON_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	require.NoError(t, WriteJSONAtomic(path, testState{Name: "nginx", Count: 1}, 0644))
+
+	var state testState
+	require.NoError(t, ReadJSON(path, &state))
+	assert.Equal(t, "nginx", state.Name)
+	assert.Equal(t, 1, state.Count)
+}
+
+func TestReadJSON_RecoversFromBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	require.NoError(t, WriteJSONAtomic(path, testState{Name: "nginx", Count: 1}, 0644))
+	require.NoError(t, WriteJSONAtomic(path, testState{Name: "nginx", Count: 2}, 0644))
+
+	// Simulate a corrupted write
+	require.NoError(t, os.WriteFile(path, []byte(`{"name": "ngi`), 0644))
+
+	var state testState
+	require.NoError(t, ReadJSON(path, &state))
+	assert.Equal(t, 1, state.Count)
+
+	// The restored file should be valid again
+	var restored testState
+	require.NoError(t, ReadJSON(path, &restored))
+	assert.Equal(t, 1, restored.Count)
+
+	matches, err := filepath.Glob(path + ".corrupt-*")
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+}
+
+func TestReadJSON_Missing(t *testing.T) {
+	var state testState
+	err := ReadJSON(filepath.Join(t.TempDir(), "missing.json"), &state)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestReadJSON_MissingWithBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, WriteJSONAtomic(path, testState{Name: "nginx", Count: 1}, 0644))
+	require.NoError(t, WriteJSONAtomic(path, testState{Name: "nginx", Count: 2}, 0644))
+
+	// A removed file is not brought back from its backup
+	require.NoError(t, os.Remove(path))
+
+	var state testState
+	err := ReadJSON(path, &state)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr), "removed file should stay removed")
+}
+
+func TestReadJSON_CorruptWithoutBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0644))
+
+	var state testState
+	err := ReadJSON(path, &state)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr), "corrupted file should be moved aside")
+}
+
+func TestRemoveWithBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, WriteJSONAtomic(path, testState{Name: "a"}, 0644))
+	require.NoError(t, WriteJSONAtomic(path, testState{Name: "b"}, 0644))
+
+	require.NoError(t, RemoveWithBackup(path))
+	require.NoError(t, RemoveWithBackup(path))
+
+	_, err := os.Stat(path + backupSuffix)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,115 @@
+// Package history persists observations from past executions, such as action
+// durations and download throughput, used to tune later executions.
+package history
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"sai/internal/fileutil"
+)
+
+// maxSamples is the number of most recent samples kept per key
+const maxSamples = 10
+
+// durationsFile is the name of the file holding duration history in the store directory
+const durationsFile = "durations.json"
+
+// durationHistory is the persisted form of a DurationStore
+type durationHistory struct {
+	Durations  map[string][]float64 `json:"durations"`  // Seconds per execution key
+	Throughput []float64            `json:"throughput"` // Observed download throughput in bytes per second
+}
+
+// DurationStore records how long executions took and the throughput of downloads
+type DurationStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewDurationStore creates a duration store persisted in the given directory
+func NewDurationStore(dir string) *DurationStore {
+	return &DurationStore{
+		path: filepath.Join(dir, durationsFile),
+	}
+}
+
+// RecordDuration adds the duration of a successful execution
+func (ds *DurationStore) RecordDuration(key string, duration time.Duration) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	data := ds.load()
+	data.Durations[key] = appendSample(data.Durations[key], duration.Seconds())
+	return fileutil.WriteJSONAtomic(ds.path, data, 0644)
+}
+
+// RecordThroughput adds an observed download throughput in bytes per second
+func (ds *DurationStore) RecordThroughput(bytesPerSecond float64) error {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	data := ds.load()
+	data.Throughput = appendSample(data.Throughput, bytesPerSecond)
+	return fileutil.WriteJSONAtomic(ds.path, data, 0644)
+}
+
+// LongestDuration returns the longest recent duration recorded for a key
+func (ds *DurationStore) LongestDuration(key string) (time.Duration, bool) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	samples := ds.load().Durations[key]
+	if len(samples) == 0 {
+		return 0, false
+	}
+
+	longest := samples[0]
+	for _, sample := range samples[1:] {
+		if sample > longest {
+			longest = sample
+		}
+	}
+	return time.Duration(longest * float64(time.Second)), true
+}
+
+// Throughput returns the median of the recent download throughput observations
+func (ds *DurationStore) Throughput() (float64, bool) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	samples := append([]float64(nil), ds.load().Throughput...)
+	if len(samples) == 0 {
+		return 0, false
+	}
+
+	sort.Float64s(samples)
+	return samples[len(samples)/2], true
+}
+
+// load reads the history, starting empty when there is none
+func (ds *DurationStore) load() *durationHistory {
+	data := &durationHistory{}
+	if err := fileutil.ReadJSON(ds.path, data); err != nil {
+		data = &durationHistory{}
+	}
+	if data.Durations == nil {
+		data.Durations = make(map[string][]float64)
+	}
+	return data
+}
+
+// appendSample appends a sample, keeping only the most recent ones
+func appendSample(samples []float64, sample float64) []float64 {
+	samples = append(samples, sample)
+	if len(samples) > maxSamples {
+		samples = samples[len(samples)-maxSamples:]
+	}
+	return samples
+}
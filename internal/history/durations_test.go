@@ -0,0 +1,49 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDurationStore_Durations(t *testing.T) {
+	store := NewDurationStore(t.TempDir())
+
+	_, found := store.LongestDuration("apt/install/nginx")
+	assert.False(t, found)
+
+	require.NoError(t, store.RecordDuration("apt/install/nginx", 20*time.Second))
+	require.NoError(t, store.RecordDuration("apt/install/nginx", 90*time.Second))
+	require.NoError(t, store.RecordDuration("apt/install/nginx", 30*time.Second))
+
+	longest, found := store.LongestDuration("apt/install/nginx")
+	assert.True(t, found)
+	assert.Equal(t, 90*time.Second, longest)
+
+	// Only the most recent samples are kept
+	for i := 0; i < maxSamples; i++ {
+		require.NoError(t, store.RecordDuration("apt/install/nginx", 10*time.Second))
+	}
+	longest, _ = store.LongestDuration("apt/install/nginx")
+	assert.Equal(t, 10*time.Second, longest)
+}
+
+func TestDurationStore_Throughput(t *testing.T) {
+	dir := t.TempDir()
+	store := NewDurationStore(dir)
+
+	_, found := store.Throughput()
+	assert.False(t, found)
+
+	require.NoError(t, store.RecordThroughput(1000))
+	require.NoError(t, store.RecordThroughput(5000))
+	require.NoError(t, store.RecordThroughput(2000))
+	require.NoError(t, store.RecordThroughput(0)) // ignored
+
+	// History persists across store instances
+	throughput, found := NewDurationStore(dir).Throughput()
+	assert.True(t, found)
+	assert.Equal(t, 2000.0, throughput)
+}
@@ -2,8 +2,15 @@ package interfaces
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
+	"sai/internal/managed"
+	"sai/internal/transaction"
 	"sai/internal/types"
 )
 
@@ -21,6 +28,9 @@ type ProviderManager interface {
 	// GetAllProviders returns all providers (both available and unavailable)
 	GetAllProviders() []*types.ProviderData
 	
+	// GetPlatformProviders returns the providers compatible with the host platform
+	GetPlatformProviders() []*types.ProviderData
+	
 	// SelectProvider selects the best provider for a software and action
 	SelectProvider(software string, action string, preferredProvider string) (*types.ProviderData, error)
 	
@@ -30,6 +40,10 @@ type ProviderManager interface {
 	// GetProvidersForAction returns providers that support a specific action
 	GetProvidersForAction(action string) []*types.ProviderData
 	
+	// WhichProvidersCan reports for every provider whether it can run an action on a
+	// software on this host, given the compatibility declared by its saidata
+	WhichProvidersCan(action string, saidata *types.SoftwareData) []ProviderCapability
+	
 	// ValidateProvider validates a provider configuration
 	ValidateProvider(provider *types.ProviderData) error
 	
@@ -78,6 +92,15 @@ type ActionManager interface {
 	// ExecuteAction executes a specific action on software
 	ExecuteAction(ctx context.Context, action string, software string, options ActionOptions) (*ActionResult, error)
 	
+	// ExecuteBatch executes the same action on several software concurrently
+	ExecuteBatch(ctx context.Context, action string, software []string, options ActionOptions) (*BatchResult, error)
+	
+	// Rollback undoes the changes journaled by a transaction
+	Rollback(ctx context.Context, id string, options ActionOptions) (*transaction.Transaction, error)
+	
+	// GetTransactions returns the journaled transactions, the most recent first
+	GetTransactions() ([]*transaction.Transaction, error)
+	
 	// ValidateAction validates if an action can be performed
 	ValidateAction(action string, software string) error
 	
@@ -108,11 +131,44 @@ type ActionManager interface {
 	// GetSoftwareVersions gets version information with installation status
 	GetSoftwareVersions(software string) ([]*VersionInfo, error)
 	
+	// ListInstalled lists the packages installed by the available providers, or by
+	// a single provider when provider is not empty
+	ListInstalled(ctx context.Context, provider string) (*InstalledList, error)
+	
 	// ManageRepositorySetup automatically sets up repositories from saidata
 	ManageRepositorySetup(saidata *types.SoftwareData) error
 	
 	// GetProviderManager returns the provider manager for stats and debugging
 	GetProviderManager() ProviderManager
+	
+	// GetOpenCircuitBreakers returns the actions of a provider whose circuit breaker is open
+	GetOpenCircuitBreakers(provider string) []string
+	
+	// VerifySoftware checks the installed files of a software installed by sai against
+	// the package database of its provider, or the files recorded at install
+	VerifySoftware(ctx context.Context, software string) (*VerifyResult, error)
+
+	// GetSoftwareStatus returns the installed packages of a software and the state of
+	// the services, ports, containers, files, directories and commands of its saidata
+	GetSoftwareStatus(ctx context.Context, software string) (*SoftwareStatus, error)
+
+	// SoftwareNotes returns the next steps after installing a software, rendered from
+	// the post_install_message of its saidata
+	SoftwareNotes(software string) (*SoftwareNotes, error)
+
+	// CircuitBreakerStatuses returns the circuit breakers with recent failures or not
+	// closed, of one provider or of all when provider is empty
+	CircuitBreakerStatuses(provider string) []CircuitBreakerStatus
+	
+	// ResetProviderCircuitBreakers closes the circuit breakers of a provider, or of one
+	// of its actions, and returns the actions reset
+	ResetProviderCircuitBreakers(provider, action string) []string
+	
+	// ResetCircuitBreakers closes the circuit breakers of all providers
+	ResetCircuitBreakers()
+	
+	// BootstrapProvider installs an unavailable provider using its bootstrap definition
+	BootstrapProvider(ctx context.Context, provider string, allowUnverified bool, options ActionOptions) error
 }
 
 // GenericExecutor executes provider actions with safety validation
@@ -140,6 +196,9 @@ type GenericExecutor interface {
 	
 	// ExecuteSteps executes multiple steps in sequence
 	ExecuteSteps(ctx context.Context, steps []types.Step, saidata *types.SoftwareData, provider *types.ProviderData, options ExecuteOptions) (*ExecutionResult, error)
+
+	// ExecuteScript renders a script template for a software and runs it with the shell
+	ExecuteScript(ctx context.Context, script string, software string, saidata *types.SoftwareData, provider *types.ProviderData, options ExecuteOptions) (*ExecutionResult, error)
 }
 
 // DefaultsGenerator generates intelligent defaults for missing saidata
@@ -237,16 +296,151 @@ type TemplateEngine interface {
 	// SetSafetyMode enables or disables safety mode
 	SetSafetyMode(enabled bool)
 	
-	// SetSaidata sets the current saidata context for template functions
+	// SetSaidata sets the current saidata context for template functions.
+	//
+	// Deprecated: Render resolves template functions against the Saidata of its
+	// context, so renderings sharing an engine may run concurrently.
 	SetSaidata(saidata *types.SoftwareData)
 }
 
 // TemplateContext holds the context for template rendering
 type TemplateContext struct {
-	Software  string
-	Provider  string
-	Saidata   *types.SoftwareData
-	Variables map[string]string
+	Software   string
+	Provider   string
+	Executable string // Absolute path of the provider executable, exposed as .Executable
+	Saidata    *types.SoftwareData
+	Variables  map[string]string
+	Runtime    *RuntimeContext
+	Root       string // Alternate root filesystem actions act on, empty for the running system
+	Version    string // Version constraint requested with software@version, see version.Parse
+
+	// Host the action runs on, exposed as .Platform, .OS, .OSVersion, .Arch and
+	// .PackageManager. With an alternate root they still describe the running system.
+	Platform       string // linux, darwin or windows
+	OS             string // Distribution or operating system, e.g. ubuntu or macos
+	OSVersion      string // e.g. 22.04
+	Arch           string // e.g. amd64 or arm64
+	PackageManager string // Default package manager provider available, e.g. apt
+}
+
+// Runtime context names steps can capture their output into
+const (
+	RuntimeSelectedPackage = "selected_package"
+	RuntimePackageIndex    = "package_index"
+	RuntimeResolvedURL     = "resolved_url"
+	RuntimeExtractDir      = "extract_dir"
+)
+
+// RuntimeContext holds values resolved while an action executes. It is shared by the
+// steps of the action and exposed to templates as .Runtime, e.g. {{.Runtime.SelectedPackage}}.
+type RuntimeContext struct {
+	SelectedPackage string            // Package the action operates on
+	PackageIndex    int               // Index of SelectedPackage in the resolved packages
+	ResolvedURL     string            // Download URL of SelectedPackage
+	ExtractDir      string            // Directory downloaded archives are extracted to
+	Values          map[string]string // Values captured under other names and registered step outputs, also exposed to templates as .Registered
+	Workflow        map[string]string // Variables published by earlier actions of the workflow, exposed to templates as .Workflow
+}
+
+// Set stores a value captured by a step. Runtime context names set the matching
+// field, any other name is stored in Values.
+func (r *RuntimeContext) Set(name, value string) error {
+	switch name {
+	case RuntimeSelectedPackage:
+		r.SelectedPackage = value
+	case RuntimePackageIndex:
+		index, err := strconv.Atoi(value)
+		if err != nil || index < 0 {
+			return fmt.Errorf("invalid %s %q, expected a non-negative integer", name, value)
+		}
+		r.PackageIndex = index
+	case RuntimeResolvedURL:
+		r.ResolvedURL = value
+	case RuntimeExtractDir:
+		r.ExtractDir = value
+	default:
+		if r.Values == nil {
+			r.Values = make(map[string]string)
+		}
+		r.Values[name] = value
+	}
+	return nil
+}
+
+// WorkflowVariable is a variable of a workflow and the action that published it
+type WorkflowVariable struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Action   string `json:"action"`
+	Software string `json:"software"`
+}
+
+// Workflow is the variable store shared by the actions of a workflow, such as the
+// actions of an apply file. The variables registered by the steps of an action are
+// published to it once the action succeeds, and exposed to the templates of the later
+// actions as .Workflow, e.g. {{.Workflow.install_dir}}. A later action publishing the
+// same name replaces the value. A nil workflow holds no variables.
+type Workflow struct {
+	mutex     sync.RWMutex
+	variables []WorkflowVariable // In publication order
+}
+
+// NewWorkflow creates an empty workflow
+func NewWorkflow() *Workflow {
+	return &Workflow{}
+}
+
+// Publish stores the variables registered by an action
+func (w *Workflow) Publish(action, software string, registered map[string]string) {
+	if w == nil || len(registered) == 0 {
+		return
+	}
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, name := range names {
+		variable := WorkflowVariable{Name: name, Value: registered[name], Action: action, Software: software}
+		replaced := false
+		for i := range w.variables {
+			if w.variables[i].Name == name {
+				w.variables[i], replaced = variable, true
+				break
+			}
+		}
+		if !replaced {
+			w.variables = append(w.variables, variable)
+		}
+	}
+}
+
+// Values returns a copy of the variables by name, as exposed to templates
+func (w *Workflow) Values() map[string]string {
+	if w == nil {
+		return nil
+	}
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	values := make(map[string]string, len(w.variables))
+	for _, variable := range w.variables {
+		values[variable.Name] = variable.Value
+	}
+	return values
+}
+
+// Variables returns a copy of the variables with the action that published them, in
+// publication order, for the result of the workflow
+func (w *Workflow) Variables() []WorkflowVariable {
+	if w == nil {
+		return nil
+	}
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return append([]WorkflowVariable(nil), w.variables...)
 }
 
 // Logger provides structured logging
@@ -289,6 +483,19 @@ type ActionOptions struct {
 	Config      string
 	Variables   map[string]string
 	Timeout     time.Duration
+	Workers     int  // Software processed concurrently by ExecuteBatch
+	KeepGoing   bool // Continue a batch after a software failed
+	AllowGenerated bool // Run strict actions on generated saidata, see security.strict_actions
+	Version     string // Version constraint requested with software@version, see version.Parse
+	Purge       bool   // Remove the services, containers, files and directories saidata declares after an uninstall
+	
+	// Progress is called by ExecuteBatch when the software at index starts, with a
+	// nil result, and when it completes. It may be called concurrently.
+	Progress func(index int, result *ActionResult)
+
+	// Workflow receives the variables registered by the action and exposes the ones of
+	// earlier actions to its templates, nil outside of a workflow
+	Workflow *Workflow
 }
 
 // ExecuteOptions contains options for command execution
@@ -299,6 +506,18 @@ type ExecuteOptions struct {
 	Variables map[string]string
 	WorkDir   string
 	Env       map[string]string
+	Runtime   *RuntimeContext // Shared with the action's steps, created by Execute when nil
+	Workflow  *Workflow       // Variables of earlier actions copied into a created Runtime, nil outside of a workflow
+	ExtraArgs []string        // Added to the commands acting on packages, resolved by Execute when nil
+	Version   string          // Version constraint requested with software@version, exposed to templates
+
+	StallTimeout time.Duration // Abort commands producing no output for this long, 0 disables
+
+	MaxParallelSteps int // Concurrency limit of steps declaring depends_on, 0 for the default
+
+	Checkpoint string // File recording the steps completed by a failed run to skip them, empty to run them all
+
+	Output io.Writer // Receives the output of commands live as they run, nil buffers it until they finish
 }
 
 // CommandOptions contains options for single command execution
@@ -308,6 +527,12 @@ type CommandOptions struct {
 	Env       map[string]string
 	Input     string
 	Verbose   bool
+	Shell     string // Shell dialect of the command: "sh" (default, executed directly), "powershell" or "cmd"
+	ReadOnly  bool   // Command only inspects the system and may run in read-only mode
+
+	StallTimeout time.Duration // Abort the command when it produces no output for this long, 0 disables
+
+	Output io.Writer // Receives the output live, the result then holds only its last megabyte
 }
 
 // ActionResult contains the result of an action execution
@@ -321,8 +546,133 @@ type ActionResult struct {
 	Duration             time.Duration
 	Commands             []string
 	Changes              []Change
+	Notes                string // Next steps after an install, from the post_install_message of the saidata
 	ExitCode             int
 	RequiredConfirmation bool
+	TransactionID        string           // Journal transaction of a system-changing action, see 'sai rollback'
+	Streamed             bool             // Output was shown live as the commands ran, see output.stream
+	ProviderResults      []ProviderResult // Outcome of each provider of an action run across providers
+}
+
+// ProviderResult is the outcome of one provider of an information-only action run
+// across all providers
+type ProviderResult struct {
+	Provider string        `json:"provider"`
+	Success  bool          `json:"success"`
+	TimedOut bool          `json:"timed_out,omitempty"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+	Commands []string      `json:"commands,omitempty"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// SoftwareNotes are the next steps after installing a software, see 'sai notes'
+type SoftwareNotes struct {
+	Software  string `json:"software"`
+	Provider  string `json:"provider,omitempty"` // Provider the notes were rendered for
+	Installed bool   `json:"installed"`          // Installed by sai with the provider
+	Notes     string `json:"notes"`
+}
+
+// Methods of sai verify
+const (
+	VerifyMethodProvider = "provider" // The verify action of the provider, such as dpkg -V
+	VerifyMethodManifest = "manifest" // The files recorded when sai installed the software
+)
+
+// Checks of sai verify besides the installed files
+const (
+	VerifyCheckChecksum  = "checksum"  // A binary matches a package checksum of saidata
+	VerifyCheckOrigin    = "origin"    // A package comes from a repository saidata declares
+	VerifyCheckSignature = "signature" // A package is signed
+)
+
+// Statuses of the checks of sai verify
+const (
+	VerifyStatusOK      = "ok"
+	VerifyStatusDrift   = "drift"
+	VerifyStatusUnknown = "unknown" // The check could not be run
+)
+
+// VerifyResult lists the installed files of a software that were modified, removed
+// or added since it was installed, and the outcome of the checksum, origin and
+// signature checks
+type VerifyResult struct {
+	Software string `json:"software"`
+	Provider string `json:"provider"`
+	Method   string `json:"method"`
+	managed.FileVerification
+	Checks []VerifyCheck `json:"checks"`
+}
+
+// VerifyCheck is the outcome of a check of a binary or package of a software
+type VerifyCheck struct {
+	Check   string `json:"check"`   // checksum, origin or signature
+	Subject string `json:"subject"` // Path of the binary or name of the package
+	Status  string `json:"status"`  // ok, drift or unknown
+	Detail  string `json:"detail,omitempty"`
+}
+
+// FilesIntact reports whether no file was modified, removed or added
+func (r *VerifyResult) FilesIntact() bool {
+	return len(r.Modified) == 0 && len(r.Missing) == 0 && len(r.Extra) == 0
+}
+
+// Intact reports whether no file was modified, removed or added and no check drifted
+func (r *VerifyResult) Intact() bool {
+	for _, check := range r.Checks {
+		if check.Status == VerifyStatusDrift {
+			return false
+		}
+	}
+	return r.FilesIntact()
+}
+
+// SoftwareStatus is the consolidated status of a software on the system
+type SoftwareStatus struct {
+	Software  string           `json:"software"`
+	Provider  string           `json:"provider,omitempty"` // Provider sai installed the software with
+	Resources []ResourceStatus `json:"resources"`
+}
+
+// Healthy reports whether every resource is in its expected state
+func (s *SoftwareStatus) Healthy() bool {
+	for _, resource := range s.Resources {
+		if !resource.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// ResourceStatus is the state of a package or resource of a software
+type ResourceStatus struct {
+	Type   string `json:"type"` // package, service, port, container, file, directory or command
+	Name   string `json:"name"`
+	State  string `json:"state"`            // installed, active, listening, running, present...
+	Detail string `json:"detail,omitempty"` // Provider and version of packages, path of files...
+	OK     bool   `json:"ok"`
+}
+
+// CircuitBreakerStatus describes the persisted circuit breaker of a provider action
+type CircuitBreakerStatus struct {
+	Provider       string     `json:"provider"`
+	Action         string     `json:"action"`
+	State          string     `json:"state"` // closed, open or half-open once the cooldown passed
+	RecentFailures int        `json:"recent_failures"`
+	LastFailure    *time.Time `json:"last_failure,omitempty"`
+	OpenUntil      *time.Time `json:"open_until,omitempty"` // End of the cooldown of an open breaker
+}
+
+// BatchResult contains the results of an action run on several software
+type BatchResult struct {
+	Action    string
+	Results   []*ActionResult // In the order of the requested software, nil when skipped
+	Succeeded int
+	Failed    int
+	Skipped   int // Software not started after a failure without KeepGoing
+	Duration  time.Duration
 }
 
 // ExecutionResult contains the result of a command execution
@@ -335,6 +685,7 @@ type ExecutionResult struct {
 	Commands     []string
 	Provider     string
 	Changes      []Change
+	Runtime      *RuntimeContext
 }
 
 // CommandResult contains the result of a single command
@@ -350,13 +701,27 @@ type CommandResult struct {
 type Change struct {
 	Type        string // "file", "service", "package", etc.
 	Resource    string
-	Action      string // "created", "modified", "deleted", "started", "stopped"
+	Action      string // "created", "modified", "deleted", "started", "stopped", "installed", "removed", "upgraded"
 	OldValue    string
 	NewValue    string
 	Reversible  bool
 	RollbackCmd string
 }
 
+// String describes a change, e.g. "package nginx upgraded (1.24.0 -> 1.26.0)"
+func (c Change) String() string {
+	description := c.Type + " " + c.Resource + " " + c.Action
+	switch {
+	case c.OldValue != "" && c.NewValue != "":
+		description += " (" + c.OldValue + " -> " + c.NewValue + ")"
+	case c.NewValue != "":
+		description += " (" + c.NewValue + ")"
+	case c.OldValue != "":
+		description += " (" + c.OldValue + ")"
+	}
+	return description
+}
+
 // ActionInfo contains information about an action
 type ActionInfo struct {
 	Name         string
@@ -376,6 +741,15 @@ type ProviderOption struct {
 	Priority    int
 }
 
+// ProviderCapability tells whether a provider can run an action on a software on this
+// host, and why not when it cannot
+type ProviderCapability struct {
+	Provider  *types.ProviderData
+	Available bool   // The provider is available on this host
+	Capable   bool   // The provider is available, has the action and supports the software
+	Reason    string // Why the provider cannot run the action, empty when capable
+}
+
 // SearchResult represents a search result across providers
 type SearchResult struct {
 	Software    string
@@ -383,7 +757,10 @@ type SearchResult struct {
 	PackageName string
 	Version     string
 	Description string
+	Repository  string // Repository, tap or origin of the package, empty when the provider does not tell
 	Available   bool
+	Deprecated  string // Deprecation notice of the software, empty when it is not deprecated
+	ReplacedBy  string // Software to use instead of a deprecated one
 }
 
 // SoftwareInfo represents software information from providers
@@ -408,6 +785,34 @@ type VersionInfo struct {
 	LatestVersion string
 }
 
+// InstalledPackage is a package listed by the list_installed action of a provider
+type InstalledPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// ProviderPackages contains the packages installed by a provider
+type ProviderPackages struct {
+	Provider string             `json:"provider"`
+	Packages []InstalledPackage `json:"packages"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// InstalledSoftware is software installed by one or more providers
+type InstalledSoftware struct {
+	Name       string            `json:"name"`
+	Versions   map[string]string `json:"versions"` // Installed version by provider
+	Providers  []string          `json:"providers"`
+	HasSaidata bool              `json:"has_saidata"`
+}
+
+// InstalledList contains the installed packages of each provider, and the software
+// they install deduplicated across providers
+type InstalledList struct {
+	Providers []*ProviderPackages  `json:"providers"`
+	Software  []*InstalledSoftware `json:"software"`
+}
+
 // ResourceValidationResult contains resource validation results
 type ResourceValidationResult struct {
 	Valid              bool
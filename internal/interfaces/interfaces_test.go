@@ -196,6 +196,7 @@ func (m *mockGenericExecutor) CanExecute(*types.ProviderData, string, string, *t
 func (m *mockGenericExecutor) RenderTemplate(string, *types.SoftwareData, *types.ProviderData) (string, error) { return "", nil }
 func (m *mockGenericExecutor) ExecuteCommand(context.Context, string, CommandOptions) (*CommandResult, error) { return nil, nil }
 func (m *mockGenericExecutor) ExecuteSteps(context.Context, []types.Step, *types.SoftwareData, *types.ProviderData, ExecuteOptions) (*ExecutionResult, error) { return nil, nil }
+func (m *mockGenericExecutor) ExecuteScript(context.Context, string, string, *types.SoftwareData, *types.ProviderData, ExecuteOptions) (*ExecutionResult, error) { return nil, nil }
 
 type mockDefaultsGenerator struct{}
 func (m *mockDefaultsGenerator) GeneratePackageDefaults(string) []types.Package { return nil }
@@ -0,0 +1,428 @@
+// Package k8s deploys the containers of saidata to Kubernetes: it renders them as a
+// Deployment and a Service each, applied with kubectl, or as the values of a Helm
+// chart, and finds the chart saidata declares for the helm provider. The ports of
+// the Services come from the port mappings of the containers, named after the ports
+// saidata declares.
+package k8s
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"sai/internal/types"
+)
+
+// ManagedBy is the app.kubernetes.io/managed-by label of the objects rendered
+const ManagedBy = "sai"
+
+// Labels of the objects rendered. The instance is the software, so the objects of
+// one software are selected together, as the releases of Helm charts are.
+const (
+	NameLabel      = "app.kubernetes.io/name"
+	InstanceLabel  = "app.kubernetes.io/instance"
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+)
+
+var (
+	// invalidNameChars matches the characters Kubernetes object names cannot have
+	invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+	// portNamePattern matches the names of ports, IANA service names
+	portNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,13}[a-z0-9])?$`)
+
+	// labelKeyPattern and labelValuePattern match the keys and values of labels
+	labelKeyPattern   = regexp.MustCompile(`^([a-z0-9]([a-z0-9.-]{0,251}[a-z0-9])?/)?[A-Za-z0-9]([A-Za-z0-9_.-]{0,61}[A-Za-z0-9])?$`)
+	labelValuePattern = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9_.-]{0,61}[A-Za-z0-9])?)?$`)
+)
+
+// Chart is a Helm chart saidata declares in a repository of the helm provider
+type Chart struct {
+	Name       string // Chart name in its repository
+	Repository string // Name of the repository
+	URL        string // Repository URL, empty when the name is a full chart reference
+	Version    string // Chart version, the latest when empty
+}
+
+// FindChart returns the chart saidata declares for a provider: the first package of
+// its recommended repository, or of its first repository with a URL, and else its
+// first package, whose name is then a chart reference such as oci://registry/chart.
+// It returns nil when saidata declares no chart.
+func FindChart(saidata *types.SoftwareData, provider string) *Chart {
+	if saidata == nil {
+		return nil
+	}
+	config := saidata.GetProviderConfig(provider)
+	if config == nil {
+		return nil
+	}
+
+	var repository *types.Repository
+	for i := range config.Repositories {
+		candidate := &config.Repositories[i]
+		if candidate.URL == "" || len(candidate.Packages) == 0 {
+			continue
+		}
+		if repository == nil || (candidate.Recommended && !repository.Recommended) {
+			repository = candidate
+		}
+	}
+	if repository != nil {
+		pkg := repository.Packages[0]
+		return &Chart{
+			Name:       pkg.GetPackageNameOrDefault(),
+			Repository: repository.Name,
+			URL:        repository.URL,
+			Version:    pkg.Version,
+		}
+	}
+
+	if len(config.Packages) > 0 {
+		pkg := config.Packages[0]
+		return &Chart{Name: pkg.GetPackageNameOrDefault(), Version: pkg.Version}
+	}
+	return nil
+}
+
+// Containers returns the containers of saidata for a provider, its own containers
+// replacing the default ones
+func Containers(saidata *types.SoftwareData, provider string) []types.Container {
+	if saidata == nil {
+		return nil
+	}
+	if config := saidata.GetProviderConfig(provider); config != nil && len(config.Containers) > 0 {
+		return config.Containers
+	}
+	return saidata.Containers
+}
+
+// ports returns the ports of saidata for a provider, its own ports replacing the
+// default ones
+func ports(saidata *types.SoftwareData, provider string) []types.Port {
+	if config := saidata.GetProviderConfig(provider); config != nil && len(config.Ports) > 0 {
+		return config.Ports
+	}
+	return saidata.Ports
+}
+
+// Name turns a saidata name into the name of a Kubernetes object: lower-case
+// letters, digits and dashes, at most 63 characters
+func Name(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.Trim(name, "-")
+}
+
+// Port is a port of a container exposed by its Service
+type Port struct {
+	Name          string // Port name, from the saidata port of the same number
+	Port          int    // Port of the Service, the host port of the mapping
+	ContainerPort int    // Port the container listens on
+	Protocol      string // TCP, UDP or SCTP
+}
+
+// ContainerPorts returns the ports exposed for a container from its port mappings,
+// host:container[/protocol] as given to docker run -p. A container mapping no port
+// exposes the ports saidata declares when it is the only container of saidata.
+func ContainerPorts(saidata *types.SoftwareData, provider string, container types.Container) ([]Port, error) {
+	declared := ports(saidata, provider)
+
+	var exposed []Port
+	for _, mapping := range container.Ports {
+		port, err := parsePortMapping(mapping)
+		if err != nil {
+			return nil, fmt.Errorf("container %s: %w", container.Name, err)
+		}
+		exposed = append(exposed, port)
+	}
+	if len(exposed) == 0 && len(Containers(saidata, provider)) == 1 {
+		for _, p := range declared {
+			exposed = append(exposed, Port{Port: p.Port, ContainerPort: p.Port, Protocol: protocol(p.Protocol)})
+		}
+	}
+
+	// Services with several ports require unique names
+	used := make(map[string]bool)
+	for i := range exposed {
+		port := &exposed[i]
+		for _, p := range declared {
+			name := strings.ToLower(p.Service)
+			if p.Port == port.Port && portNamePattern.MatchString(name) && strings.ContainsAny(name, "abcdefghijklmnopqrstuvwxyz") {
+				port.Name = name
+				break
+			}
+		}
+		if port.Name == "" || used[port.Name] {
+			port.Name = fmt.Sprintf("%s-%d", strings.ToLower(port.Protocol), port.Port)
+		}
+		used[port.Name] = true
+	}
+	return exposed, nil
+}
+
+// parsePortMapping parses a port mapping, [ip:]host:container[/protocol] or a single
+// port published on the same number
+func parsePortMapping(mapping string) (Port, error) {
+	spec, proto, _ := strings.Cut(mapping, "/")
+	parts := strings.Split(spec, ":")
+	containerPort, err := parsePort(parts[len(parts)-1])
+	if err != nil {
+		return Port{}, fmt.Errorf("invalid port mapping %q: %w", mapping, err)
+	}
+	hostPort := containerPort
+	if len(parts) > 1 && parts[len(parts)-2] != "" {
+		if hostPort, err = parsePort(parts[len(parts)-2]); err != nil {
+			return Port{}, fmt.Errorf("invalid port mapping %q: %w", mapping, err)
+		}
+	}
+	return Port{Port: hostPort, ContainerPort: containerPort, Protocol: protocol(proto)}, nil
+}
+
+// parsePort parses a port number, port ranges are not supported by Services
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("%q is not a port number", s)
+	}
+	return port, nil
+}
+
+// protocol returns the Kubernetes name of a protocol, TCP by default
+func protocol(proto string) string {
+	if proto == "" {
+		return "TCP"
+	}
+	return strings.ToUpper(proto)
+}
+
+// Kubernetes objects, with only the fields rendered
+type (
+	object struct {
+		APIVersion string      `yaml:"apiVersion"`
+		Kind       string      `yaml:"kind"`
+		Metadata   metadata    `yaml:"metadata"`
+		Spec       interface{} `yaml:"spec"`
+	}
+	metadata struct {
+		Name   string            `yaml:"name,omitempty"`
+		Labels map[string]string `yaml:"labels,omitempty"`
+	}
+	deploymentSpec struct {
+		Replicas int         `yaml:"replicas"`
+		Selector selector    `yaml:"selector"`
+		Template podTemplate `yaml:"template"`
+	}
+	selector struct {
+		MatchLabels map[string]string `yaml:"matchLabels"`
+	}
+	podTemplate struct {
+		Metadata metadata `yaml:"metadata"`
+		Spec     podSpec  `yaml:"spec"`
+	}
+	podSpec struct {
+		Containers []container `yaml:"containers"`
+	}
+	container struct {
+		Name  string          `yaml:"name"`
+		Image string          `yaml:"image"`
+		Ports []containerPort `yaml:"ports,omitempty"`
+		Env   []envVar        `yaml:"env,omitempty"`
+	}
+	containerPort struct {
+		Name          string `yaml:"name"`
+		ContainerPort int    `yaml:"containerPort"`
+		Protocol      string `yaml:"protocol"`
+	}
+	envVar struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	}
+	serviceSpec struct {
+		Selector map[string]string `yaml:"selector"`
+		Ports    []servicePort     `yaml:"ports"`
+	}
+	servicePort struct {
+		Name       string `yaml:"name"`
+		Port       int    `yaml:"port"`
+		TargetPort int    `yaml:"targetPort"`
+		Protocol   string `yaml:"protocol"`
+	}
+)
+
+// Manifest renders the containers of saidata for a provider as Kubernetes objects
+// labeled with the software as instance: a Deployment per container and a Service
+// for the containers exposing ports. Objects have no namespace, they are created in
+// the one kubectl applies them to. Volumes are not rendered, host paths having no
+// meaning on the nodes of a cluster.
+func Manifest(saidata *types.SoftwareData, software, provider string) (string, error) {
+	containers := Containers(saidata, provider)
+	if len(containers) == 0 {
+		return "", fmt.Errorf("saidata of %s declares no container", software)
+	}
+
+	var documents []string
+	for _, c := range containers {
+		name := Name(c.Name)
+		if name == "" {
+			return "", fmt.Errorf("container %q has no valid name", c.Name)
+		}
+		exposed, err := ContainerPorts(saidata, provider, c)
+		if err != nil {
+			return "", err
+		}
+
+		selectorLabels := map[string]string{NameLabel: name, InstanceLabel: Name(software)}
+		labels := map[string]string{ManagedByLabel: ManagedBy}
+		for key, value := range selectorLabels {
+			labels[key] = value
+		}
+		podLabels := make(map[string]string, len(selectorLabels)+len(c.Labels))
+		for key, value := range validLabels(c.Labels) {
+			podLabels[key] = value
+		}
+		for key, value := range selectorLabels {
+			podLabels[key] = value
+		}
+
+		spec := container{Name: name, Image: c.GetImageReference()}
+		for _, port := range exposed {
+			spec.Ports = append(spec.Ports, containerPort{Name: port.Name, ContainerPort: port.ContainerPort, Protocol: port.Protocol})
+		}
+		for _, key := range sortedKeys(c.Environment) {
+			spec.Env = append(spec.Env, envVar{Name: key, Value: c.Environment[key]})
+		}
+
+		objects := []object{{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Metadata:   metadata{Name: name, Labels: labels},
+			Spec: deploymentSpec{
+				Replicas: 1,
+				Selector: selector{MatchLabels: selectorLabels},
+				Template: podTemplate{
+					Metadata: metadata{Labels: podLabels},
+					Spec:     podSpec{Containers: []container{spec}},
+				},
+			},
+		}}
+		if len(exposed) > 0 {
+			service := serviceSpec{Selector: selectorLabels}
+			for _, port := range exposed {
+				service.Ports = append(service.Ports, servicePort{Name: port.Name, Port: port.Port, TargetPort: port.ContainerPort, Protocol: port.Protocol})
+			}
+			objects = append(objects, object{
+				APIVersion: "v1",
+				Kind:       "Service",
+				Metadata:   metadata{Name: name, Labels: labels},
+				Spec:       service,
+			})
+		}
+
+		for _, o := range objects {
+			document, err := marshal(o)
+			if err != nil {
+				return "", fmt.Errorf("failed to render %s %s: %w", o.Kind, name, err)
+			}
+			documents = append(documents, document)
+		}
+	}
+	return strings.Join(documents, "---\n"), nil
+}
+
+// values are the values of a Helm chart set from the first container of saidata,
+// named as in the charts created by helm create
+type values struct {
+	Image     *imageValues      `yaml:"image,omitempty"`
+	Service   *serviceValues    `yaml:"service,omitempty"`
+	PodLabels map[string]string `yaml:"podLabels,omitempty"`
+}
+
+type imageValues struct {
+	Repository string `yaml:"repository"`
+	Tag        string `yaml:"tag,omitempty"`
+}
+
+type serviceValues struct {
+	Port int `yaml:"port"`
+}
+
+// Values renders the first container of saidata for a provider as the values of a
+// Helm chart: its image, the port of its Service and its labels. Charts ignore the
+// values they do not use, so a chart declaring its own image keeps it when saidata
+// declares no container and the values are empty.
+func Values(saidata *types.SoftwareData, provider string) (string, error) {
+	containers := Containers(saidata, provider)
+	if len(containers) == 0 {
+		return "{}\n", nil
+	}
+	c := containers[0]
+
+	v := values{Image: &imageValues{}}
+	reference := c.GetImageReference()
+	v.Image.Repository = reference
+	if !strings.Contains(reference, "@") {
+		if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+			v.Image.Repository, v.Image.Tag = reference[:i], reference[i+1:]
+		}
+	}
+
+	exposed, err := ContainerPorts(saidata, provider, c)
+	if err != nil {
+		return "", err
+	}
+	if len(exposed) > 0 {
+		v.Service = &serviceValues{Port: exposed[0].Port}
+	}
+	v.PodLabels = validLabels(c.Labels)
+
+	document, err := marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to render the values of container %s: %w", c.Name, err)
+	}
+	return document, nil
+}
+
+// validLabels returns the labels of a container Kubernetes accepts, nil when none is
+func validLabels(labels map[string]string) map[string]string {
+	var valid map[string]string
+	for key, value := range labels {
+		if labelKeyPattern.MatchString(key) && labelValuePattern.MatchString(value) {
+			if valid == nil {
+				valid = make(map[string]string)
+			}
+			valid[key] = value
+		}
+	}
+	return valid
+}
+
+// marshal renders a value as YAML indented by two spaces, as Kubernetes manifests are
+func marshal(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// sortedKeys returns the keys of a map in order, so renderings are reproducible
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
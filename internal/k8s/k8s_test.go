@@ -0,0 +1,140 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"sai/internal/types"
+)
+
+func prometheusSaidata() *types.SoftwareData {
+	return &types.SoftwareData{
+		Metadata: types.Metadata{Name: "prometheus"},
+		Ports: []types.Port{
+			{Port: 9090, Protocol: "tcp", Service: "prometheus-web"},
+			{Port: 9093, Protocol: "tcp", Service: "alertmanager"},
+		},
+		Containers: []types.Container{
+			{
+				Name: "prometheus", Image: "prom/prometheus", Tag: "v2.45.0", Registry: "docker.io",
+				Ports:       []string{"9090:9090"},
+				Environment: map[string]string{"TZ": "UTC"},
+				Labels:      map[string]string{"purpose": "monitoring-server", "bad": "not a value"},
+			},
+			{Name: "alertmanager", Image: "prom/alertmanager", Tag: "v0.25.0", Ports: []string{"127.0.0.1:9093:9093", "9094:9094/udp"}},
+		},
+		Providers: map[string]types.ProviderConfig{
+			"helm": {
+				Repositories: []types.Repository{
+					{Name: "mirror", URL: "https://mirror.example.com/charts", Packages: []types.Package{{Name: "prometheus"}}},
+					{
+						Name: "prometheus-community", URL: "https://prometheus-community.github.io/helm-charts", Recommended: true,
+						Packages: []types.Package{{Name: "prometheus", PackageName: "kube-prometheus-stack", Version: "51.0.0"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestFindChart(t *testing.T) {
+	chart := FindChart(prometheusSaidata(), "helm")
+	require.NotNil(t, chart)
+	assert.Equal(t, Chart{
+		Name:       "kube-prometheus-stack",
+		Repository: "prometheus-community",
+		URL:        "https://prometheus-community.github.io/helm-charts",
+		Version:    "51.0.0",
+	}, *chart)
+
+	saidata := &types.SoftwareData{Providers: map[string]types.ProviderConfig{
+		"helm": {Packages: []types.Package{{Name: "app", PackageName: "oci://registry.example.com/charts/app"}}},
+	}}
+	assert.Equal(t, &Chart{Name: "oci://registry.example.com/charts/app"}, FindChart(saidata, "helm"))
+
+	assert.Nil(t, FindChart(prometheusSaidata(), "kubernetes"))
+}
+
+func TestContainerPorts(t *testing.T) {
+	saidata := prometheusSaidata()
+
+	ports, err := ContainerPorts(saidata, "kubernetes", saidata.Containers[1])
+	require.NoError(t, err)
+	assert.Equal(t, []Port{
+		{Name: "alertmanager", Port: 9093, ContainerPort: 9093, Protocol: "TCP"},
+		{Name: "udp-9094", Port: 9094, ContainerPort: 9094, Protocol: "UDP"},
+	}, ports)
+
+	// The only container exposes the ports of saidata when it maps none
+	single := &types.SoftwareData{
+		Ports:      []types.Port{{Port: 80, Service: "http"}, {Port: 443, Service: "http"}},
+		Containers: []types.Container{{Name: "nginx", Image: "nginx"}},
+	}
+	ports, err = ContainerPorts(single, "kubernetes", single.Containers[0])
+	require.NoError(t, err)
+	assert.Equal(t, []Port{
+		{Name: "http", Port: 80, ContainerPort: 80, Protocol: "TCP"},
+		{Name: "tcp-443", Port: 443, ContainerPort: 443, Protocol: "TCP"},
+	}, ports)
+
+	_, err = ContainerPorts(single, "kubernetes", types.Container{Name: "nginx", Ports: []string{"8000-8010:8000-8010"}})
+	assert.Error(t, err)
+}
+
+func TestManifest(t *testing.T) {
+	manifest, err := Manifest(prometheusSaidata(), "prometheus", "kubernetes")
+	require.NoError(t, err)
+
+	decoder := yaml.NewDecoder(strings.NewReader(manifest))
+	var objects []map[string]interface{}
+	for {
+		var object map[string]interface{}
+		if decoder.Decode(&object) != nil {
+			break
+		}
+		objects = append(objects, object)
+	}
+	require.Len(t, objects, 4)
+
+	kinds := make([]string, len(objects))
+	for i, object := range objects {
+		kinds[i] = object["kind"].(string)
+	}
+	assert.Equal(t, []string{"Deployment", "Service", "Deployment", "Service"}, kinds)
+
+	assert.Contains(t, manifest, "image: docker.io/prom/prometheus:v2.45.0")
+	assert.Contains(t, manifest, "app.kubernetes.io/instance: prometheus")
+	assert.Contains(t, manifest, "purpose: monitoring-server")
+	assert.NotContains(t, manifest, "not a value")
+	assert.Contains(t, manifest, "- name: TZ\n              value: UTC")
+	assert.Contains(t, manifest, "- name: prometheus-web\n      port: 9090\n      targetPort: 9090\n      protocol: TCP")
+
+	_, err = Manifest(&types.SoftwareData{}, "nginx", "kubernetes")
+	assert.Error(t, err)
+}
+
+func TestValues(t *testing.T) {
+	values, err := Values(prometheusSaidata(), "helm")
+	require.NoError(t, err)
+	assert.Equal(t, `image:
+  repository: docker.io/prom/prometheus
+  tag: v2.45.0
+service:
+  port: 9090
+podLabels:
+  purpose: monitoring-server
+`, values)
+
+	values, err = Values(&types.SoftwareData{}, "helm")
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", values)
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "node-exporter", Name("Node_Exporter"))
+	assert.Equal(t, "app", Name("--app--"))
+}
@@ -0,0 +1,168 @@
+// Package lock serializes sai processes acting through the same provider with lock
+// files, so two concurrent runs wait for each other instead of failing on the package
+// manager's own lock.
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrTimeout is returned when a lock is still held by another process at the timeout
+var ErrTimeout = errors.New("timed out waiting for lock")
+
+// errLocked is returned by tryLock when another process holds the lock
+var errLocked = errors.New("lock held by another process")
+
+// pollInterval is how often a held lock is tried again
+var pollInterval = 250 * time.Millisecond
+
+// Holder describes the process holding a lock
+type Holder struct {
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	Since   time.Time `json:"since"`
+}
+
+// String describes the holder for wait and timeout messages
+func (h *Holder) String() string {
+	if h == nil || h.PID == 0 {
+		return "another process"
+	}
+	return fmt.Sprintf("'%s' (pid %d, since %s)", h.Command, h.PID, h.Since.Format("15:04:05"))
+}
+
+// Options control how a lock is waited for
+type Options struct {
+	Timeout time.Duration        // How long to wait for a held lock, 0 fails at once
+	OnWait  func(holder *Holder) // Called once when the lock is held by another process
+	Shared  bool                 // The directory is shared by all users, see Acquire
+}
+
+// Lock is a held lock file
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes the lock named name in dir, waiting while another process holds it
+// until opts.Timeout or ctx expire. The lock is released by Release or when the
+// process exits, so locks of crashed processes never need to be cleaned up.
+//
+// A shared dir is created writable by all users with the sticky bit, like /tmp, and its
+// lock files writable by all users, so that processes of any user, root included, wait
+// for each other on the same lock files.
+func Acquire(ctx context.Context, dir, name string, opts Options) (*Lock, error) {
+	path := filepath.Join(dir, sanitize(name)+".lock")
+	var file *os.File
+	var err error
+	if opts.Shared {
+		file, err = openShared(dir, path)
+	} else {
+		file, err = openPrivate(dir, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(opts.Timeout)
+	waiting := false
+	for {
+		err := tryLock(file)
+		if err == nil {
+			writeHolder(file)
+			return &Lock{file: file}, nil
+		}
+		if !errors.Is(err, errLocked) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		holder := readHolder(path)
+		if !waiting && opts.OnWait != nil && opts.Timeout > 0 {
+			opts.OnWait(holder)
+		}
+		waiting = true
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			file.Close()
+			return nil, fmt.Errorf("%w %s after %s, held by %s", ErrTimeout, name, opts.Timeout, holder)
+		}
+		wait := pollInterval
+		if remaining < wait {
+			wait = remaining
+		}
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, fmt.Errorf("stopped waiting for lock %s held by %s: %w", name, holder, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Release releases the lock
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	// The holder is cleared before unlocking, the next holder writes its own
+	l.file.Truncate(0)
+	err := unlock(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
+
+// openPrivate opens the lock file at path in dir, creating both when missing
+func openPrivate(dir, path string) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory %s: %w", dir, err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// writeHolder records the current process in a lock file it holds
+func writeHolder(file *os.File) {
+	data, err := json.Marshal(Holder{PID: os.Getpid(), Command: strings.Join(os.Args, " "), Since: time.Now()})
+	if err != nil {
+		return
+	}
+	file.Truncate(0)
+	file.WriteAt(data, 0)
+}
+
+// readHolder returns the process recorded in a lock file, nil when unknown
+func readHolder(path string) *Holder {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	var holder Holder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return nil
+	}
+	return &holder
+}
+
+// sanitize keeps lock names usable as file names
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+}
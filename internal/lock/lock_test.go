@@ -0,0 +1,109 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	held, err := Acquire(ctx, dir, "apt", Options{})
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	// Other lock names are independent
+	other, err := Acquire(ctx, dir, "brew", Options{})
+	if err != nil {
+		t.Fatalf("Acquire() of another lock error = %v", err)
+	}
+	other.Release()
+
+	if _, err := Acquire(ctx, dir, "apt", Options{}); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Acquire() of a held lock error = %v, want ErrTimeout", err)
+	} else if !strings.Contains(err.Error(), "pid "+strconv.Itoa(os.Getpid())) {
+		t.Errorf("Acquire() error = %v, want the holder", err)
+	}
+
+	// A waiter gets the lock once released
+	var waitedFor *Holder
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		held.Release()
+	}()
+	waiter, err := Acquire(ctx, dir, "apt", Options{
+		Timeout: 5 * time.Second,
+		OnWait:  func(holder *Holder) { waitedFor = holder },
+	})
+	if err != nil {
+		t.Fatalf("Acquire() after release error = %v", err)
+	}
+	defer waiter.Release()
+	if waitedFor == nil || waitedFor.PID != os.Getpid() {
+		t.Errorf("OnWait() holder = %v, want this process", waitedFor)
+	}
+}
+
+func TestAcquire_ContextCanceled(t *testing.T) {
+	dir := t.TempDir()
+	held, err := Acquire(context.Background(), dir, "dnf", Options{})
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer held.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := Acquire(ctx, dir, "dnf", Options{Timeout: time.Minute}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() error = %v, want the context error", err)
+	}
+}
+
+func TestAcquire_Shared(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shared lock directories rely on Unix permissions")
+	}
+	dir := filepath.Join(t.TempDir(), "sai")
+
+	held, err := Acquire(context.Background(), dir, "apt", Options{Shared: true})
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	held.Release()
+
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0777 || info.Mode()&os.ModeSticky == 0 {
+		t.Errorf("shared lock directory mode = %v, want drwxrwxrwt (%v)", info.Mode(), err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "apt.lock")); err != nil || info.Mode().Perm() != 0666 {
+		t.Errorf("shared lock file mode = %v, want -rw-rw-rw- (%v)", info.Mode(), err)
+	}
+
+	// Lock files planted by other users as links are refused
+	target := filepath.Join(t.TempDir(), "shadow")
+	if err := os.WriteFile(target, []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(dir, "dnf.lock")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(target, filepath.Join(dir, "yum.lock")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"dnf", "yum"} {
+		if _, err := Acquire(context.Background(), dir, name, Options{Shared: true}); err == nil {
+			t.Errorf("Acquire() of a linked %s lock file succeeded", name)
+		}
+	}
+	if data, _ := os.ReadFile(target); string(data) != "secret" {
+		t.Errorf("linked file was overwritten: %q", data)
+	}
+}
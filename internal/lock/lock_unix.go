@@ -0,0 +1,75 @@
+//go:build !windows
+
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// tryLock takes an exclusive lock on a file without blocking
+func tryLock(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLocked
+	}
+	return err
+}
+
+// unlock releases the lock on a file
+func unlock(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
+
+// openShared opens the lock file at path in the shared directory dir, creating both
+// when missing. Anyone may create files in dir, so lock files are opened without
+// following symbolic links and must be regular files no other name links to. Existing
+// lock files are opened without O_CREATE, which kernels protecting sticky directories
+// refuse on the files of other users.
+func openShared(dir, path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory %s: %w", dir, err)
+	}
+	if err := os.Mkdir(dir, 0755); err == nil {
+		// Unlike Mkdir, Chmod sets the sticky bit and ignores the umask
+		if err := os.Chmod(dir, 0777|os.ModeSticky); err != nil {
+			return nil, fmt.Errorf("failed to share lock directory %s: %w", dir, err)
+		}
+	} else if !os.IsExist(err) {
+		return nil, fmt.Errorf("failed to create lock directory %s: %w", dir, err)
+	}
+	if info, err := os.Lstat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("lock directory %s is not a directory", dir)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOFOLLOW, 0)
+	if os.IsNotExist(err) {
+		file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL|syscall.O_NOFOLLOW, 0666)
+		if os.IsExist(err) {
+			// Created by another process meanwhile
+			file, err = os.OpenFile(path, os.O_RDWR|syscall.O_NOFOLLOW, 0)
+		} else if err == nil {
+			if err := file.Chmod(0666); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("failed to share lock file %s: %w", path, err)
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); !info.Mode().IsRegular() || !ok || stat.Nlink != 1 {
+		file.Close()
+		return nil, fmt.Errorf("lock file %s is not a regular file", path)
+	}
+	return file, nil
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package lock
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset is the locked byte of lock files, past the holder they record: Windows
+// locks keep other processes from reading the locked range
+func lockOffset() *windows.Overlapped {
+	return &windows.Overlapped{OffsetHigh: 1}
+}
+
+// tryLock takes an exclusive lock on a file without blocking
+func tryLock(file *os.File) error {
+	err := windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, lockOffset())
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLocked
+	}
+	return err
+}
+
+// unlock releases the lock on a file
+func unlock(file *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, lockOffset())
+}
+
+// openShared opens the lock file at path in the shared directory dir, creating both
+// when missing. The directory inherits the permissions of its parent.
+func openShared(dir, path string) (*os.File, error) {
+	return openPrivate(dir, path)
+}
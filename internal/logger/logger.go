@@ -9,6 +9,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"sai/internal/config"
+	"sai/internal/debug"
 )
 
 // Logger provides structured logging with different output modes
@@ -33,6 +34,8 @@ func NewLogger(cfg *config.Config, verbose, quiet, jsonOutput bool) *Logger {
 	}
 
 	l.setupLogger()
+	// Secrets in command output and errors never reach the log
+	l.AddHook(&debug.RedactionHook{})
 	return l
 }
 
@@ -0,0 +1,171 @@
+package lsm
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// auditLogs lists the logs searched for denials. SELinux denials are written by
+// auditd; AppArmor denials end up in the kernel log when auditd is not running.
+var auditLogs = []string{
+	"/var/log/audit/audit.log",
+	"/var/log/kern.log",
+	"/var/log/syslog",
+	"/var/log/messages",
+}
+
+// maxDenials limits the number of denials reported, keeping the most recent ones
+const maxDenials = 10
+
+var (
+	// auditTimestampPattern matches the event time of audit records, msg=audit(1700000000.123:42)
+	auditTimestampPattern = regexp.MustCompile(`audit\((\d+)(?:\.\d+)?:\d+\)`)
+
+	// auditFieldPattern matches key=value and key="value" fields of audit records
+	auditFieldPattern = regexp.MustCompile(`(\w+)=("[^"]*"|\S+)`)
+)
+
+// Denial is an access denied by a security module
+type Denial struct {
+	Time       time.Time `json:"time,omitempty"`
+	Module     string    `json:"module"`
+	Command    string    `json:"command,omitempty"`    // comm of the denied process
+	Path       string    `json:"path,omitempty"`       // name or path of the denied object
+	Permission string    `json:"permission,omitempty"` // denied permission, e.g. "read open" or "r"
+	Record     string    `json:"record"`               // raw log line
+}
+
+// String summarizes the denial
+func (d Denial) String() string {
+	var parts []string
+	if d.Command != "" {
+		parts = append(parts, d.Command)
+	}
+	if d.Permission != "" {
+		parts = append(parts, "denied "+d.Permission)
+	}
+	if d.Path != "" {
+		parts = append(parts, "on "+d.Path)
+	}
+	if len(parts) == 0 {
+		return d.Record
+	}
+	return d.Module + ": " + strings.Join(parts, " ")
+}
+
+// FindDenials returns recent denials since the given time that mention any of the
+// terms (process names, paths). Unreadable logs are skipped, so the result is empty
+// rather than an error when sai lacks the privileges to read them.
+func FindDenials(since time.Time, terms []string) []Denial {
+	var denials []Denial
+	seen := make(map[string]bool)
+	for _, path := range auditLogs {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		for _, denial := range parseDenials(file, since, terms) {
+			// The kernel log and syslog can both hold the same audit event
+			key := auditTimestampPattern.FindString(denial.Record)
+			if key == "" {
+				key = denial.Record
+			}
+			if !seen[key] {
+				seen[key] = true
+				denials = append(denials, denial)
+			}
+		}
+		file.Close()
+	}
+
+	sort.SliceStable(denials, func(i, j int) bool {
+		return denials[i].Time.Before(denials[j].Time)
+	})
+
+	if len(denials) > maxDenials {
+		denials = denials[len(denials)-maxDenials:]
+	}
+	return denials
+}
+
+// parseDenials extracts SELinux AVC and AppArmor denials from log content
+func parseDenials(reader io.Reader, since time.Time, terms []string) []Denial {
+	var denials []Denial
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		var denial Denial
+		switch {
+		case strings.Contains(line, "avc:") && strings.Contains(line, "denied"):
+			denial.Module = SELinux
+		case strings.Contains(line, `apparmor="DENIED"`):
+			denial.Module = AppArmor
+		default:
+			continue
+		}
+
+		if !mentionsAny(line, terms) {
+			continue
+		}
+
+		if match := auditTimestampPattern.FindStringSubmatch(line); match != nil {
+			if seconds, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+				denial.Time = time.Unix(seconds, 0)
+			}
+		}
+		if !denial.Time.IsZero() && denial.Time.Before(since) {
+			continue
+		}
+
+		fields := auditFields(line)
+		denial.Command = fields["comm"]
+		denial.Path = fields["name"]
+		if denial.Path == "" {
+			denial.Path = fields["path"]
+		}
+		denial.Permission = fields["requested_mask"]
+		if denial.Module == SELinux {
+			// avc:  denied  { read open } for ...
+			if _, rest, found := strings.Cut(line, "denied"); found {
+				if _, permissions, found := strings.Cut(rest, "{"); found {
+					permissions, _, _ = strings.Cut(permissions, "}")
+					denial.Permission = strings.TrimSpace(permissions)
+				}
+			}
+		}
+		denial.Record = strings.TrimSpace(line)
+
+		denials = append(denials, denial)
+	}
+	return denials
+}
+
+// auditFields parses the key=value fields of an audit record, unquoting values
+func auditFields(line string) map[string]string {
+	fields := make(map[string]string)
+	for _, match := range auditFieldPattern.FindAllStringSubmatch(line, -1) {
+		if _, exists := fields[match[1]]; !exists {
+			fields[match[1]] = strings.Trim(match[2], `"`)
+		}
+	}
+	return fields
+}
+
+// mentionsAny reports whether the line contains any of the non-empty terms
+func mentionsAny(line string, terms []string) bool {
+	for _, term := range terms {
+		if term != "" && strings.Contains(line, term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+// Package lsm detects the Linux security modules (SELinux, AppArmor) enforcing access
+// control on this system, restores SELinux file contexts and finds access denials in
+// the audit logs.
+package lsm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Security module names
+const (
+	SELinux  = "selinux"
+	AppArmor = "apparmor"
+)
+
+// Security module modes
+const (
+	ModeEnforcing  = "enforcing"
+	ModePermissive = "permissive"
+	ModeEnabled    = "enabled" // AppArmor, enforcement is per profile
+)
+
+// Status describes the active security module
+type Status struct {
+	Module string `json:"module"` // SELinux, AppArmor or "" when none is active
+	Mode   string `json:"mode,omitempty"`
+}
+
+// Active reports whether a security module is active
+func (s *Status) Active() bool {
+	return s.Module != ""
+}
+
+// Enforcing reports whether the security module denies access rather than only logging it
+func (s *Status) Enforcing() bool {
+	return s.Module == AppArmor || s.Mode == ModeEnforcing
+}
+
+// Paths read for detection, relative to the filesystem root
+const (
+	selinuxEnforcePath  = "sys/fs/selinux/enforce"
+	apparmorEnabledPath = "sys/module/apparmor/parameters/enabled"
+)
+
+// Detect returns the security module active on this system
+func Detect() *Status {
+	if runtime.GOOS != "linux" {
+		return &Status{}
+	}
+	return detectAt("/")
+}
+
+// detectAt detects the security module below the given filesystem root
+func detectAt(root string) *Status {
+	if content, err := os.ReadFile(filepath.Join(root, selinuxEnforcePath)); err == nil {
+		mode := ModePermissive
+		if strings.TrimSpace(string(content)) == "1" {
+			mode = ModeEnforcing
+		}
+		return &Status{Module: SELinux, Mode: mode}
+	}
+
+	if content, err := os.ReadFile(filepath.Join(root, apparmorEnabledPath)); err == nil &&
+		strings.TrimSpace(string(content)) == "Y" {
+		return &Status{Module: AppArmor, Mode: ModeEnabled}
+	}
+
+	return &Status{}
+}
+
+// Runner runs a command, through the command executor of sai so it is audited,
+// sandboxed and refused in read-only mode, and returns its output
+type Runner func(ctx context.Context, args ...string) (string, error)
+
+// RestoreContexts resets the SELinux contexts of existing paths, recursively for
+// directories, to the policy defaults with restorecon. Files installed outside the
+// package manager otherwise keep the context of the location they were created in.
+// With an alternate root, the paths below it are labeled by setfiles with the policy
+// of the root. It returns the paths that were relabeled and is a no-op without
+// SELinux, as AppArmor confinement is path based and needs no labels.
+func RestoreContexts(ctx context.Context, status *Status, root string, paths []string, run Runner) ([]string, error) {
+	if status == nil || status.Module != SELinux {
+		return nil, nil
+	}
+
+	var existing []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		if root != "" {
+			path = filepath.Join(root, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			existing = append(existing, path)
+		}
+	}
+	if len(existing) == 0 {
+		return nil, nil
+	}
+
+	args := append([]string{"restorecon", "-R"}, existing...)
+	if root != "" {
+		spec, err := fileContexts(root)
+		if err != nil {
+			return nil, err
+		}
+		args = append([]string{"setfiles", "-r", root, spec}, existing...)
+	}
+
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil, fmt.Errorf("%s not found, install policycoreutils to restore SELinux contexts", args[0])
+	}
+	if output, err := run(ctx, args...); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", args[0], err, strings.TrimSpace(output))
+	}
+	return existing, nil
+}
+
+// fileContexts returns the file contexts of the SELinux policy configured below root
+func fileContexts(root string) (string, error) {
+	policy := "targeted"
+	if content, err := os.ReadFile(filepath.Join(root, "etc", "selinux", "config")); err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			if value, found := strings.CutPrefix(strings.TrimSpace(line), "SELINUXTYPE="); found && value != "" {
+				policy = value
+			}
+		}
+	}
+
+	spec := filepath.Join(root, "etc", "selinux", policy, "contexts", "files", "file_contexts")
+	if _, err := os.Stat(spec); err != nil {
+		return "", fmt.Errorf("%s has no SELinux policy %s to restore contexts with", root, policy)
+	}
+	return spec, nil
+}
@@ -0,0 +1,103 @@
+package lsm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRootFile(t *testing.T, root, path, content string) {
+	t.Helper()
+	full := filepath.Join(root, path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+	require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+}
+
+func TestDetectAt(t *testing.T) {
+	t.Run("selinux enforcing", func(t *testing.T) {
+		root := t.TempDir()
+		writeRootFile(t, root, selinuxEnforcePath, "1\n")
+
+		status := detectAt(root)
+		assert.Equal(t, &Status{Module: SELinux, Mode: ModeEnforcing}, status)
+		assert.True(t, status.Enforcing())
+	})
+
+	t.Run("selinux permissive", func(t *testing.T) {
+		root := t.TempDir()
+		writeRootFile(t, root, selinuxEnforcePath, "0\n")
+
+		status := detectAt(root)
+		assert.Equal(t, ModePermissive, status.Mode)
+		assert.False(t, status.Enforcing())
+	})
+
+	t.Run("apparmor", func(t *testing.T) {
+		root := t.TempDir()
+		writeRootFile(t, root, apparmorEnabledPath, "Y\n")
+
+		status := detectAt(root)
+		assert.Equal(t, &Status{Module: AppArmor, Mode: ModeEnabled}, status)
+	})
+
+	t.Run("apparmor disabled", func(t *testing.T) {
+		root := t.TempDir()
+		writeRootFile(t, root, apparmorEnabledPath, "N\n")
+
+		assert.False(t, detectAt(root).Active())
+	})
+
+	t.Run("none", func(t *testing.T) {
+		assert.False(t, detectAt(t.TempDir()).Active())
+	})
+}
+
+func TestRestoreContextsWithoutSELinux(t *testing.T) {
+	restored, err := RestoreContexts(context.Background(), &Status{Module: AppArmor}, "", []string{"/etc"}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, restored)
+}
+
+func TestFileContexts(t *testing.T) {
+	root := t.TempDir()
+	_, err := fileContexts(root)
+	assert.Error(t, err)
+
+	writeRootFile(t, root, "etc/selinux/config", "SELINUX=enforcing\nSELINUXTYPE=mls\n")
+	writeRootFile(t, root, "etc/selinux/mls/contexts/files/file_contexts", "")
+
+	spec, err := fileContexts(root)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "etc/selinux/mls/contexts/files/file_contexts"), spec)
+}
+
+func TestParseDenials(t *testing.T) {
+	log := strings.Join([]string{
+		`type=AVC msg=audit(1700000100.123:42): avc:  denied  { read open } for  pid=1234 comm="nginx" path="/opt/nginx/conf/nginx.conf" dev="dm-0" ino=123 scontext=system_u:system_r:httpd_t:s0 tcontext=unconfined_u:object_r:user_home_t:s0 tclass=file permissive=0`,
+		`type=AVC msg=audit(1700000000.000:41): avc:  denied  { write } for  pid=1200 comm="nginx" name="cache" tclass=dir permissive=0`,
+		`type=AVC msg=audit(1700000200.000:43): avc:  denied  { read } for  pid=99 comm="sshd" name="keys" tclass=file permissive=0`,
+		`Oct 16 10:00:00 host kernel: audit: type=1400 audit(1700000300.500:44): apparmor="DENIED" operation="open" profile="/usr/sbin/nginx" name="/srv/www/index.html" pid=1300 comm="nginx" requested_mask="r" denied_mask="r" fsuid=33 ouid=0`,
+		`type=SYSCALL msg=audit(1700000100.123:42): arch=c000003e syscall=257 success=no exit=-13 comm="nginx"`,
+	}, "\n")
+
+	denials := parseDenials(strings.NewReader(log), time.Unix(1700000050, 0), []string{"nginx"})
+	require.Len(t, denials, 2)
+
+	assert.Equal(t, SELinux, denials[0].Module)
+	assert.Equal(t, "nginx", denials[0].Command)
+	assert.Equal(t, "/opt/nginx/conf/nginx.conf", denials[0].Path)
+	assert.Equal(t, "read open", denials[0].Permission)
+	assert.Equal(t, time.Unix(1700000100, 0), denials[0].Time)
+	assert.Equal(t, "selinux: nginx denied read open on /opt/nginx/conf/nginx.conf", denials[0].String())
+
+	assert.Equal(t, AppArmor, denials[1].Module)
+	assert.Equal(t, "/srv/www/index.html", denials[1].Path)
+	assert.Equal(t, "r", denials[1].Permission)
+	assert.Equal(t, "apparmor: nginx denied r on /srv/www/index.html", denials[1].String())
+}
@@ -0,0 +1,174 @@
+package managed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// maxRecordedFiles bounds the files recorded for a software, so that a directory such
+// as a data directory does not turn the marker into a copy of the filesystem listing
+const maxRecordedFiles = 10000
+
+// FileDigest records an installed file so later changes to it can be detected
+type FileDigest struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Mode   string `json:"mode"` // Permission bits, as 0755
+	SHA256 string `json:"sha256"`
+}
+
+// FileChange is a recorded file that no longer matches its digest
+type FileChange struct {
+	Path    string   `json:"path"`
+	Changes []string `json:"changes"` // What differs: size, mode, checksum
+}
+
+// FileVerification lists the differences between the recorded and the installed files
+type FileVerification struct {
+	Checked  int          `json:"checked"`
+	Modified []FileChange `json:"modified"`
+	Missing  []string     `json:"missing"`
+	Extra    []string     `json:"extra"` // Files added to a recorded directory
+}
+
+// DigestFiles records the regular files among paths and under directories. Paths that
+// do not exist are skipped, as saidata lists the usual locations of a software rather
+// than the ones a given install uses. The digests are sorted by path.
+func DigestFiles(paths, directories []string) ([]FileDigest, error) {
+	recorded := make(map[string]bool)
+	digests := []FileDigest{}
+	add := func(path string) error {
+		if recorded[path] {
+			return nil
+		}
+		if len(digests) >= maxRecordedFiles {
+			return fmt.Errorf("more than %d files to record", maxRecordedFiles)
+		}
+		digest, err := digestFile(path)
+		if err != nil {
+			return err
+		}
+		recorded[path] = true
+		digests = append(digests, *digest)
+		return nil
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if err := add(filepath.Clean(path)); err != nil {
+			return nil, err
+		}
+	}
+	for _, dir := range directories {
+		err := walkFiles(dir, func(path string) error {
+			return add(path)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(digests, func(i, j int) bool {
+		return digests[i].Path < digests[j].Path
+	})
+	return digests, nil
+}
+
+// VerifyFiles compares recorded digests with the installed files, and reports the files
+// added since to the recorded directories
+func VerifyFiles(files []FileDigest, directories []string) *FileVerification {
+	verification := &FileVerification{
+		Modified: []FileChange{},
+		Missing:  []string{},
+		Extra:    []string{},
+	}
+
+	recorded := make(map[string]bool, len(files))
+	for _, file := range files {
+		recorded[file.Path] = true
+		verification.Checked++
+
+		current, err := digestFile(file.Path)
+		if err != nil {
+			verification.Missing = append(verification.Missing, file.Path)
+			continue
+		}
+		var changes []string
+		if current.Size != file.Size {
+			changes = append(changes, "size")
+		}
+		if current.Mode != file.Mode {
+			changes = append(changes, "mode")
+		}
+		if current.SHA256 != file.SHA256 {
+			changes = append(changes, "checksum")
+		}
+		if len(changes) > 0 {
+			verification.Modified = append(verification.Modified, FileChange{Path: file.Path, Changes: changes})
+		}
+	}
+
+	for _, dir := range directories {
+		_ = walkFiles(dir, func(path string) error {
+			if !recorded[path] {
+				verification.Extra = append(verification.Extra, path)
+			}
+			return nil
+		})
+	}
+	sort.Strings(verification.Extra)
+	return verification
+}
+
+// walkFiles calls fn with the regular files under dir, which may not exist
+func walkFiles(dir string, fn func(path string) error) error {
+	err := filepath.WalkDir(filepath.Clean(dir), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) || os.IsPermission(err) {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		return fn(path)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list the files of %s: %w", dir, err)
+	}
+	return nil
+}
+
+// digestFile computes the digest of a regular file
+func digestFile(path string) (*FileDigest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return &FileDigest{
+		Path:   path,
+		Size:   info.Size(),
+		Mode:   fmt.Sprintf("%04o", info.Mode().Perm()),
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
@@ -0,0 +1,45 @@
+package managed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestAndVerifyFiles(t *testing.T) {
+	root := t.TempDir()
+	binary := filepath.Join(root, "bin", "tool")
+	config := filepath.Join(root, "etc", "tool", "tool.conf")
+	plugin := filepath.Join(root, "etc", "tool", "plugins", "a.so")
+	for _, path := range []string{binary, config, plugin} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(path), 0644))
+	}
+	require.NoError(t, os.Chmod(binary, 0755))
+
+	files, err := DigestFiles([]string{binary, filepath.Join(root, "bin", "absent")}, []string{filepath.Join(root, "etc", "tool")})
+	require.NoError(t, err)
+	require.Len(t, files, 3, "absent paths are skipped")
+	assert.Equal(t, binary, files[0].Path)
+	assert.Equal(t, "0755", files[0].Mode)
+
+	verification := VerifyFiles(files, []string{filepath.Join(root, "etc", "tool")})
+	assert.Equal(t, 3, verification.Checked)
+	assert.Empty(t, verification.Modified)
+	assert.Empty(t, verification.Missing)
+	assert.Empty(t, verification.Extra)
+
+	require.NoError(t, os.WriteFile(config, []byte("tampered"), 0644))
+	require.NoError(t, os.Chmod(config, 0600))
+	require.NoError(t, os.Remove(plugin))
+	extra := filepath.Join(root, "etc", "tool", "plugins", "b.so")
+	require.NoError(t, os.WriteFile(extra, []byte("new"), 0644))
+
+	verification = VerifyFiles(files, []string{filepath.Join(root, "etc", "tool")})
+	assert.Equal(t, []FileChange{{Path: config, Changes: []string{"size", "mode", "checksum"}}}, verification.Modified)
+	assert.Equal(t, []string{plugin}, verification.Missing)
+	assert.Equal(t, []string{extra}, verification.Extra)
+}
@@ -0,0 +1,143 @@
+package managed
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sai/internal/fileutil"
+)
+
+// Marker records that a piece of software was installed and is managed by sai
+type Marker struct {
+	Software    string    `json:"software"`
+	Provider    string    `json:"provider"`
+	Packages    []string  `json:"packages,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	InstalledBy string    `json:"installed_by,omitempty"`
+
+	// Files installed by providers that cannot verify them, such as binary, script and
+	// source installs, checked by sai verify
+	Files       []FileDigest `json:"files,omitempty"`
+	Directories []string     `json:"directories,omitempty"` // Directories whose files are all in Files
+}
+
+// MarkerStore persists "managed by sai" markers as JSON files in a directory
+type MarkerStore struct {
+	dir string
+}
+
+// NewMarkerStore creates a marker store rooted at the given directory
+func NewMarkerStore(dir string) *MarkerStore {
+	return &MarkerStore{
+		dir: dir,
+	}
+}
+
+// Dir returns the directory where markers are stored
+func (ms *MarkerStore) Dir() string {
+	return ms.dir
+}
+
+// Record writes or refreshes the marker for a software, preserving the original install time
+func (ms *MarkerStore) Record(marker *Marker) error {
+	if marker == nil || marker.Software == "" {
+		return fmt.Errorf("marker software name is required")
+	}
+
+	now := time.Now()
+	marker.UpdatedAt = now
+	if existing, err := ms.Get(marker.Software); err == nil && !existing.InstalledAt.IsZero() {
+		marker.InstalledAt = existing.InstalledAt
+	} else if marker.InstalledAt.IsZero() {
+		marker.InstalledAt = now
+	}
+
+	if marker.InstalledBy == "" {
+		marker.InstalledBy = currentUsername()
+	}
+
+	if err := fileutil.WriteJSONAtomic(ms.markerPath(marker.Software), marker, 0644); err != nil {
+		return fmt.Errorf("failed to write marker for %s: %w", marker.Software, err)
+	}
+
+	return nil
+}
+
+// Get returns the marker for a software
+func (ms *MarkerStore) Get(software string) (*Marker, error) {
+	var marker Marker
+	if err := fileutil.ReadJSON(ms.markerPath(software), &marker); err != nil {
+		return nil, fmt.Errorf("no marker found for %s: %w", software, err)
+	}
+
+	return &marker, nil
+}
+
+// IsManaged checks if a software has a marker
+func (ms *MarkerStore) IsManaged(software string) bool {
+	_, err := ms.Get(software)
+	return err == nil
+}
+
+// Remove deletes the marker for a software, ignoring missing markers
+func (ms *MarkerStore) Remove(software string) error {
+	if err := fileutil.RemoveWithBackup(ms.markerPath(software)); err != nil {
+		return fmt.Errorf("failed to remove marker for %s: %w", software, err)
+	}
+	return nil
+}
+
+// List returns all markers sorted by software name
+func (ms *MarkerStore) List() ([]*Marker, error) {
+	entries, err := os.ReadDir(ms.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Marker{}, nil
+		}
+		return nil, fmt.Errorf("failed to read marker directory: %w", err)
+	}
+
+	markers := []*Marker{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		marker, err := ms.Get(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue // Skip unreadable markers
+		}
+		markers = append(markers, marker)
+	}
+
+	sort.Slice(markers, func(i, j int) bool {
+		return markers[i].Software < markers[j].Software
+	})
+
+	return markers, nil
+}
+
+// markerPath returns the file path of the marker for a software
+func (ms *MarkerStore) markerPath(software string) string {
+	// Prevent path traversal through software names
+	name := strings.ReplaceAll(filepath.Base(software), string(filepath.Separator), "_")
+	return filepath.Join(ms.dir, name+".json")
+}
+
+// currentUsername returns the name of the user running sai
+func currentUsername() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package managed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkerStore_RecordAndGet(t *testing.T) {
+	store := NewMarkerStore(t.TempDir())
+
+	err := store.Record(&Marker{
+		Software: "nginx",
+		Provider: "apt",
+		Packages: []string{"nginx"},
+		Version:  "1.24.0",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, store.IsManaged("nginx"))
+	assert.False(t, store.IsManaged("redis"))
+
+	marker, err := store.Get("nginx")
+	require.NoError(t, err)
+	assert.Equal(t, "apt", marker.Provider)
+	assert.Equal(t, "1.24.0", marker.Version)
+	assert.False(t, marker.InstalledAt.IsZero())
+}
+
+func TestMarkerStore_RecordPreservesInstallTime(t *testing.T) {
+	store := NewMarkerStore(t.TempDir())
+
+	installedAt := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	require.NoError(t, store.Record(&Marker{Software: "nginx", Provider: "apt", InstalledAt: installedAt}))
+	require.NoError(t, store.Record(&Marker{Software: "nginx", Provider: "apt", Version: "1.26.0"}))
+
+	marker, err := store.Get("nginx")
+	require.NoError(t, err)
+	assert.True(t, marker.InstalledAt.Equal(installedAt))
+	assert.True(t, marker.UpdatedAt.After(installedAt))
+	assert.Equal(t, "1.26.0", marker.Version)
+}
+
+func TestMarkerStore_ListAndRemove(t *testing.T) {
+	store := NewMarkerStore(t.TempDir())
+
+	markers, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, markers)
+
+	require.NoError(t, store.Record(&Marker{Software: "redis", Provider: "brew"}))
+	require.NoError(t, store.Record(&Marker{Software: "nginx", Provider: "apt"}))
+
+	markers, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, markers, 2)
+	assert.Equal(t, "nginx", markers[0].Software)
+	assert.Equal(t, "redis", markers[1].Software)
+
+	require.NoError(t, store.Remove("nginx"))
+	require.NoError(t, store.Remove("nginx")) // Removing a missing marker is not an error
+	assert.False(t, store.IsManaged("nginx"))
+}
+
+func TestMarkerStore_RejectsEmptySoftware(t *testing.T) {
+	store := NewMarkerStore(t.TempDir())
+	assert.Error(t, store.Record(&Marker{Provider: "apt"}))
+}
@@ -0,0 +1,232 @@
+// Package manifest plans the software section of apply files: each entry declares the
+// desired state of a software, and the plan lists the actions bringing the installed
+// software to that state, so a whole environment can be committed to git and applied.
+package manifest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sai/internal/interfaces"
+	"sai/internal/upgradetest"
+)
+
+// Desired states of software
+const (
+	StatePresent = "present"
+	StateLatest  = "latest"
+	StateAbsent  = "absent"
+)
+
+// Desired states of services
+const (
+	ServiceRunning = "running"
+	ServiceStopped = "stopped"
+)
+
+// Kinds of changes, shown as the prefix of the plan lines
+const (
+	KindAdd       = "+"
+	KindRemove    = "-"
+	KindChange    = "~"
+	KindService   = ">"
+	KindUnchanged = "="
+)
+
+// Software is the desired state of a software in a manifest
+type Software struct {
+	Name      string            `yaml:"name" json:"name"`
+	State     string            `yaml:"state,omitempty" json:"state,omitempty"`     // present (default), latest or absent
+	Version   string            `yaml:"version,omitempty" json:"version,omitempty"` // Version spec, "1.x" style wildcards allowed
+	Provider  string            `yaml:"provider,omitempty" json:"provider,omitempty"`
+	Service   string            `yaml:"service,omitempty" json:"service,omitempty"` // running or stopped, unmanaged when empty
+	Enabled   *bool             `yaml:"enabled,omitempty" json:"enabled,omitempty"` // Start the service at boot, unmanaged when nil
+	Variables map[string]string `yaml:"variables,omitempty" json:"variables,omitempty"`
+}
+
+// Change is a line of a plan: an action to run on a software, or a software already
+// in its desired state
+type Change struct {
+	Kind      string            `json:"kind"`
+	Software  string            `json:"software"`
+	Action    string            `json:"action,omitempty"` // Empty for unchanged software
+	Provider  string            `json:"provider,omitempty"`
+	Installed string            `json:"installed,omitempty"` // Installed version
+	Version   string            `json:"version,omitempty"`   // Desired version spec
+	Reason    string            `json:"reason"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// String returns the diff-style line of a change
+func (c Change) String() string {
+	line := c.Kind + " " + c.Software
+	if c.Action != "" {
+		line = c.Kind + " " + c.Action + " " + c.Software
+	}
+	if c.Provider != "" {
+		line += " (" + c.Provider + ")"
+	}
+	return line + ": " + c.Reason
+}
+
+// Validate checks the entries of a manifest
+func Validate(entries []Software) error {
+	seen := make(map[string]bool)
+	for i, entry := range entries {
+		if entry.Name == "" {
+			return fmt.Errorf("software[%d].name is required", i)
+		}
+		if seen[entry.Name] {
+			return fmt.Errorf("software[%d]: %s is listed more than once", i, entry.Name)
+		}
+		seen[entry.Name] = true
+
+		switch entry.State {
+		case "", StatePresent, StateLatest, StateAbsent:
+		default:
+			return fmt.Errorf("software[%d].state must be one of: %s, %s, %s", i, StatePresent, StateLatest, StateAbsent)
+		}
+		switch entry.Service {
+		case "", ServiceRunning, ServiceStopped:
+		default:
+			return fmt.Errorf("software[%d].service must be one of: %s, %s", i, ServiceRunning, ServiceStopped)
+		}
+		if entry.State == StateAbsent && (entry.Version != "" || entry.Service != "" || entry.Enabled != nil) {
+			return fmt.Errorf("software[%d]: %s is absent, it cannot declare a version or service state", i, entry.Name)
+		}
+		if entry.State == StateLatest && entry.Version != "" {
+			return fmt.Errorf("software[%d]: %s cannot declare both the latest state and a version", i, entry.Name)
+		}
+	}
+	return nil
+}
+
+// Plan compares the entries of a manifest with the installed software and returns the
+// changes in manifest order. packages returns the names a software is installed under,
+// the software name is used when it returns none. Service actions are always planned
+// for installed software, as they are idempotent and the service state is not listed.
+func Plan(entries []Software, installed *interfaces.InstalledList, packages func(software string) []string) []Change {
+	var changes []Change
+	for _, entry := range entries {
+		version, isInstalled := installedVersion(entry, installed, packages)
+		change := func(kind, action, reason string) Change {
+			c := Change{
+				Kind:      kind,
+				Software:  entry.Name,
+				Action:    action,
+				Provider:  entry.Provider,
+				Installed: version,
+				Version:   entry.Version,
+				Reason:    reason,
+				Variables: entry.Variables,
+			}
+			if entry.Version != "" && (action == "install" || action == "upgrade") {
+				c.Variables = withVersion(entry.Variables, entry.Version)
+			}
+			return c
+		}
+
+		switch {
+		case entry.State == StateAbsent && isInstalled:
+			changes = append(changes, change(KindRemove, "uninstall", "installed "+displayVersion(version)+", must be absent"))
+			continue
+		case entry.State == StateAbsent:
+			changes = append(changes, change(KindUnchanged, "", "not installed"))
+			continue
+		case !isInstalled:
+			reason := "not installed"
+			if entry.Version != "" {
+				reason += ", install " + entry.Version
+			}
+			changes = append(changes, change(KindAdd, "install", reason))
+		case entry.State == StateLatest:
+			changes = append(changes, change(KindChange, "upgrade", "installed "+displayVersion(version)+", upgrade to the latest version"))
+		case entry.Version != "" && !upgradetest.VersionMatches(entry.Version, version):
+			changes = append(changes, change(KindChange, "upgrade", "installed "+displayVersion(version)+", want "+entry.Version))
+		default:
+			changes = append(changes, change(KindUnchanged, "", "installed "+displayVersion(version)))
+		}
+
+		if entry.Enabled != nil {
+			if *entry.Enabled {
+				changes = append(changes, change(KindService, "enable", "start at boot"))
+			} else {
+				changes = append(changes, change(KindService, "disable", "do not start at boot"))
+			}
+		}
+		switch entry.Service {
+		case ServiceRunning:
+			changes = append(changes, change(KindService, "start", "ensure running"))
+		case ServiceStopped:
+			changes = append(changes, change(KindService, "stop", "ensure stopped"))
+		}
+	}
+	return changes
+}
+
+// HasChanges reports whether a plan runs any action
+func HasChanges(changes []Change) bool {
+	for _, c := range changes {
+		if c.Action != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// installedVersion returns the installed version of the software of an entry, from its
+// provider when the entry declares one, else from the first provider listing it
+func installedVersion(entry Software, installed *interfaces.InstalledList, packages func(string) []string) (string, bool) {
+	if installed == nil {
+		return "", false
+	}
+	var names []string
+	if packages != nil {
+		names = packages(entry.Name)
+	}
+	if len(names) == 0 {
+		names = []string{entry.Name}
+	}
+
+	for _, name := range names {
+		for _, software := range installed.Software {
+			if !strings.EqualFold(software.Name, name) {
+				continue
+			}
+			if entry.Provider != "" {
+				if version, ok := software.Versions[entry.Provider]; ok {
+					return version, true
+				}
+				continue
+			}
+			providers := make([]string, 0, len(software.Versions))
+			for provider := range software.Versions {
+				providers = append(providers, provider)
+			}
+			sort.Strings(providers)
+			if len(providers) > 0 {
+				return software.Versions[providers[0]], true
+			}
+		}
+	}
+	return "", false
+}
+
+// withVersion returns the variables of an entry with the version variable templates
+// pin the version with
+func withVersion(variables map[string]string, version string) map[string]string {
+	merged := make(map[string]string, len(variables)+1)
+	for k, v := range variables {
+		merged[k] = v
+	}
+	merged["version"] = version
+	return merged
+}
+
+func displayVersion(version string) string {
+	if version == "" {
+		return "(unknown version)"
+	}
+	return version
+}
@@ -0,0 +1,94 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"sai/internal/interfaces"
+)
+
+func TestPlan(t *testing.T) {
+	installed := &interfaces.InstalledList{
+		Software: []*interfaces.InstalledSoftware{
+			{Name: "nginx", Versions: map[string]string{"apt": "1.18.0-6ubuntu14"}},
+			{Name: "redis-server", Versions: map[string]string{"apt": "7.0.15"}},
+			{Name: "telnet", Versions: map[string]string{"apt": "0.17"}},
+			{Name: "git", Versions: map[string]string{"apt": "2.43.0", "brew": "2.44.0"}},
+		},
+	}
+	packages := func(software string) []string {
+		if software == "redis" {
+			return []string{"redis-server"}
+		}
+		return nil
+	}
+	enabled := true
+	entries := []Software{
+		{Name: "nginx", Version: "1.24", Service: ServiceRunning, Enabled: &enabled},
+		{Name: "redis", Version: "7.x"},
+		{Name: "telnet", State: StateAbsent},
+		{Name: "git", Provider: "brew", State: StateLatest},
+		{Name: "jq", Version: "1.7"},
+		{Name: "ftp", State: StateAbsent},
+	}
+
+	var lines []string
+	for _, change := range Plan(entries, installed, packages) {
+		lines = append(lines, change.String())
+	}
+	want := []string{
+		"~ upgrade nginx: installed 1.18.0-6ubuntu14, want 1.24",
+		"> enable nginx: start at boot",
+		"> start nginx: ensure running",
+		"= redis: installed 7.0.15",
+		"- uninstall telnet: installed 0.17, must be absent",
+		"~ upgrade git (brew): installed 2.44.0, upgrade to the latest version",
+		"+ install jq: not installed, install 1.7",
+		"= ftp: not installed",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Plan() =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPlan_PinsVersion(t *testing.T) {
+	entries := []Software{{Name: "jq", Version: "1.7", Variables: map[string]string{"channel": "stable"}}}
+	changes := Plan(entries, &interfaces.InstalledList{}, nil)
+	if len(changes) != 1 || changes[0].Action != "install" {
+		t.Fatalf("Plan() = %v, want an install", changes)
+	}
+	if changes[0].Variables["version"] != "1.7" || changes[0].Variables["channel"] != "stable" {
+		t.Errorf("install variables = %v, want the version and the entry variables", changes[0].Variables)
+	}
+	if _, exists := entries[0].Variables["version"]; exists {
+		t.Error("Plan() must not change the variables of the entry")
+	}
+	if !HasChanges(changes) {
+		t.Error("HasChanges() = false, want true")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	enabled := false
+	tests := []struct {
+		name    string
+		entries []Software
+		wantErr bool
+	}{
+		{"valid", []Software{{Name: "nginx", Version: "1.x", Service: ServiceRunning}}, false},
+		{"missing name", []Software{{Version: "1.x"}}, true},
+		{"duplicate", []Software{{Name: "nginx"}, {Name: "nginx"}}, true},
+		{"unknown state", []Software{{Name: "nginx", State: "installed"}}, true},
+		{"unknown service state", []Software{{Name: "nginx", Service: "started"}}, true},
+		{"absent with service", []Software{{Name: "nginx", State: StateAbsent, Enabled: &enabled}}, true},
+		{"latest with version", []Software{{Name: "nginx", State: StateLatest, Version: "1.x"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.entries); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
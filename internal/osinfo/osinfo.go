@@ -0,0 +1,362 @@
+// Package osinfo detects the operating system, distribution and version sai runs on.
+package osinfo
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// RollingVersion is reported as version for rolling releases without a VERSION_ID
+const RollingVersion = "rolling"
+
+// OSInfo contains detailed operating system information
+type OSInfo struct {
+	Platform     string   // "linux", "darwin", "windows"
+	OS           string   // "ubuntu", "debian", "centos", "macos", etc.
+	Version      string   // "22.04", "8", "13.0", etc.
+	Architecture string   // "amd64", "arm64", etc.
+	IDLike       []string // Parent distributions from ID_LIKE, e.g. ["rhel", "fedora"]
+	Name         string   // Human readable name, e.g. "Ubuntu 22.04.3 LTS"
+	Codename     string   // Release codename, e.g. "jammy"
+	Rolling      bool     // Rolling release without fixed versions (arch, tumbleweed, ...)
+	WSL          bool     // Running under Windows Subsystem for Linux
+	DetectedAt   time.Time
+}
+
+// rollingDistributions lists distribution IDs that are always rolling releases
+var rollingDistributions = map[string]bool{
+	"arch":                true,
+	"manjaro":             true,
+	"endeavouros":         true,
+	"gentoo":              true,
+	"opensuse-tumbleweed": true,
+	"void":                true,
+}
+
+// families maps distribution IDs to the family they belong to
+var families = map[string]string{
+	"debian":              "debian",
+	"ubuntu":              "debian",
+	"linuxmint":           "debian",
+	"pop":                 "debian",
+	"raspbian":            "debian",
+	"kali":                "debian",
+	"rhel":                "rhel",
+	"centos":              "rhel",
+	"fedora":              "rhel",
+	"rocky":               "rhel",
+	"almalinux":           "rhel",
+	"ol":                  "rhel",
+	"amzn":                "rhel",
+	"suse":                "suse",
+	"sles":                "suse",
+	"opensuse":            "suse",
+	"opensuse-leap":       "suse",
+	"opensuse-tumbleweed": "suse",
+	"arch":                "arch",
+	"manjaro":             "arch",
+	"endeavouros":         "arch",
+	"alpine":              "alpine",
+}
+
+// ArchitectureAliases maps the names architectures are commonly reported under, by
+// uname or package managers, to the Go names used by detection
+var ArchitectureAliases = map[string]string{
+	"x86_64":  "amd64",
+	"x64":     "amd64",
+	"aarch64": "arm64",
+	"armv7l":  "arm",
+	"armhf":   "arm",
+	"i386":    "386",
+	"i686":    "386",
+	"x86":     "386",
+}
+
+// NormalizeArchitecture returns the Go name of an architecture
+func NormalizeArchitecture(arch string) string {
+	arch = strings.ToLower(strings.TrimSpace(arch))
+	if alias, exists := ArchitectureAliases[arch]; exists {
+		return alias
+	}
+	return arch
+}
+
+// Detect detects the running operating system
+func Detect() (*OSInfo, error) {
+	osInfo := &OSInfo{
+		Platform:     runtime.GOOS,
+		Architecture: runtime.GOARCH,
+		DetectedAt:   time.Now(),
+	}
+
+	switch osInfo.Platform {
+	case "linux":
+		detectLinuxInfo(osInfo)
+	case "darwin":
+		detectMacOSInfo(osInfo)
+	case "windows":
+		detectWindowsInfo(osInfo)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", osInfo.Platform)
+	}
+
+	return osInfo, nil
+}
+
+// Family returns the distribution family (debian, rhel, suse, arch, alpine), looking
+// at ID_LIKE when the distribution itself is unknown. It returns "" if none match.
+func (o *OSInfo) Family() string {
+	if family := FamilyOf(o.OS); family != "" {
+		return family
+	}
+	for _, like := range o.IDLike {
+		if family := FamilyOf(like); family != "" {
+			return family
+		}
+	}
+	return ""
+}
+
+// IsLike reports whether the OS is the given distribution or derived from it
+func (o *OSInfo) IsLike(id string) bool {
+	id = strings.ToLower(id)
+	if o.OS == id {
+		return true
+	}
+	for _, like := range o.IDLike {
+		if like == id {
+			return true
+		}
+	}
+	return false
+}
+
+// FamilyOf returns the family of a distribution ID, or "" if it is unknown
+func FamilyOf(id string) string {
+	return families[strings.ToLower(id)]
+}
+
+// FromOSRelease builds Linux OS information from parsed os-release fields
+func FromOSRelease(fields map[string]string) *OSInfo {
+	osInfo := &OSInfo{
+		Platform: "linux",
+		OS:       strings.ToLower(fields["ID"]),
+		Version:  fields["VERSION_ID"],
+		IDLike:   strings.Fields(strings.ToLower(fields["ID_LIKE"])),
+		Name:     fields["PRETTY_NAME"],
+		Codename: fields["VERSION_CODENAME"],
+	}
+	if osInfo.Codename == "" {
+		osInfo.Codename = fields["UBUNTU_CODENAME"]
+	}
+
+	osInfo.Rolling = rollingDistributions[osInfo.OS] || fields["BUILD_ID"] == RollingVersion
+	if osInfo.Version == "" {
+		if osInfo.Rolling {
+			osInfo.Version = RollingVersion
+		} else {
+			osInfo.Version = versionFromPrettyName(osInfo.Name)
+		}
+	}
+
+	return osInfo
+}
+
+// detectLinuxInfo detects Linux distribution and version
+func detectLinuxInfo(osInfo *OSInfo) {
+	osInfo.WSL = detectWSL()
+
+	// Try /etc/os-release first (most common), then the systemd fallback location
+	for _, file := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+		if fields, err := ParseOSRelease(file); err == nil && fields["ID"] != "" {
+			applyLinuxInfo(osInfo, FromOSRelease(fields))
+			return
+		}
+	}
+
+	// Try /etc/lsb-release (Ubuntu/Debian)
+	if fields, err := ParseOSRelease("/etc/lsb-release"); err == nil && fields["DISTRIB_ID"] != "" {
+		osInfo.OS = strings.ToLower(fields["DISTRIB_ID"])
+		osInfo.Version = fields["DISTRIB_RELEASE"]
+		osInfo.Codename = fields["DISTRIB_CODENAME"]
+		if osInfo.Version == "" {
+			osInfo.Version = "unknown"
+		}
+		return
+	}
+
+	// Try distribution-specific files, most specific first
+	distFiles := []struct {
+		file   string
+		distro string
+	}{
+		{"/etc/rocky-release", "rocky"},
+		{"/etc/almalinux-release", "almalinux"},
+		{"/etc/fedora-release", "fedora"},
+		{"/etc/centos-release", "centos"},
+		{"/etc/redhat-release", "rhel"},
+		{"/etc/debian_version", "debian"},
+		{"/etc/alpine-release", "alpine"},
+		{"/etc/arch-release", "arch"},
+	}
+
+	for _, dist := range distFiles {
+		if content, err := os.ReadFile(dist.file); err == nil {
+			osInfo.OS = dist.distro
+			osInfo.Rolling = rollingDistributions[dist.distro]
+			if osInfo.Rolling {
+				osInfo.Version = RollingVersion
+			} else {
+				osInfo.Version = ExtractVersionFromContent(string(content))
+			}
+			return
+		}
+	}
+
+	// Fallback to generic linux
+	osInfo.OS = "linux"
+	osInfo.Version = "unknown"
+}
+
+// applyLinuxInfo copies distribution details onto osInfo
+func applyLinuxInfo(osInfo, release *OSInfo) {
+	osInfo.OS = release.OS
+	osInfo.Version = release.Version
+	osInfo.IDLike = release.IDLike
+	osInfo.Name = release.Name
+	osInfo.Codename = release.Codename
+	osInfo.Rolling = release.Rolling
+}
+
+// detectWSL reports whether Linux runs under Windows Subsystem for Linux
+func detectWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" || os.Getenv("WSL_INTEROP") != "" {
+		return true
+	}
+	if content, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		return IsWSLKernel(string(content))
+	}
+	return false
+}
+
+// IsWSLKernel reports whether a kernel release string belongs to a WSL kernel,
+// e.g. "5.15.133.1-microsoft-standard-WSL2"
+func IsWSLKernel(release string) bool {
+	release = strings.ToLower(release)
+	return strings.Contains(release, "microsoft") || strings.Contains(release, "wsl")
+}
+
+// detectMacOSInfo detects macOS version information
+func detectMacOSInfo(osInfo *OSInfo) {
+	osInfo.OS = "macos"
+	osInfo.Version = "unknown"
+
+	version := ""
+	if output, err := exec.Command("sw_vers", "-productVersion").Output(); err == nil {
+		version = strings.TrimSpace(string(output))
+	} else if data, err := os.ReadFile("/System/Library/CoreServices/SystemVersion.plist"); err == nil {
+		version = plistProductVersion(string(data))
+	}
+
+	if version != "" {
+		// Extract major version (e.g., "13.0.1" -> "13")
+		osInfo.Version = strings.Split(version, ".")[0]
+	}
+}
+
+// plistProductVersion extracts ProductVersion from a SystemVersion.plist
+func plistProductVersion(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "ProductVersion") && i+1 < len(lines) {
+			nextLine := strings.TrimSpace(lines[i+1])
+			if strings.HasPrefix(nextLine, "<string>") && strings.HasSuffix(nextLine, "</string>") {
+				return strings.TrimSuffix(strings.TrimPrefix(nextLine, "<string>"), "</string>")
+			}
+		}
+	}
+	return ""
+}
+
+// detectWindowsInfo detects Windows version information
+func detectWindowsInfo(osInfo *OSInfo) {
+	osInfo.OS = "windows"
+	osInfo.Version = "unknown"
+
+	output, err := exec.Command("wmic", "os", "get", "Version", "/value").Output()
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "Version=") {
+			// Extract major version (e.g., "10.0.19041" -> "10")
+			version := strings.TrimSpace(strings.TrimPrefix(line, "Version="))
+			osInfo.Version = strings.Split(version, ".")[0]
+			return
+		}
+	}
+}
+
+// ParseOSRelease parses /etc/os-release or /etc/lsb-release style files
+func ParseOSRelease(filename string) (map[string]string, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOSReleaseContent(string(content)), nil
+}
+
+// ParseOSReleaseContent parses the content of an os-release style file
+func ParseOSReleaseContent(content string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+
+		// Remove quotes
+		result[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return result
+}
+
+// ExtractVersionFromContent extracts a version number from release file content
+func ExtractVersionFromContent(content string) string {
+	parts := strings.Fields(strings.TrimSpace(content))
+
+	// Look for version patterns like "8.4", "20.04", etc.
+	for _, part := range parts {
+		if strings.Contains(part, ".") && len(part) <= 10 {
+			return part
+		}
+	}
+
+	// Extract first word as fallback
+	if len(parts) > 0 {
+		return parts[0]
+	}
+
+	return "unknown"
+}
+
+// versionFromPrettyName extracts a major.minor version from names like "Ubuntu 22.04.3 LTS"
+func versionFromPrettyName(prettyName string) string {
+	for _, part := range strings.Fields(prettyName) {
+		if strings.Contains(part, ".") && len(part) <= 10 {
+			versionParts := strings.Split(part, ".")
+			return versionParts[0] + "." + versionParts[1]
+		}
+	}
+	return "unknown"
+}
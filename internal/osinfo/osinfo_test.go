@@ -0,0 +1,165 @@
+package osinfo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	// Create a temporary os-release file
+	tempFile := filepath.Join(t.TempDir(), "os-release")
+	content := `NAME="Ubuntu"
+VERSION="22.04.1 LTS (Jammy Jellyfish)"
+ID=ubuntu
+ID_LIKE=debian
+PRETTY_NAME="Ubuntu 22.04.1 LTS"
+VERSION_ID="22.04"
+HOME_URL="https://www.ubuntu.com/"
+SUPPORT_URL="https://help.ubuntu.com/"
+BUG_REPORT_URL="https://bugs.launchpad.net/ubuntu/"
+PRIVACY_POLICY_URL="https://www.ubuntu.com/legal/terms-and-policies/privacy-policy"
+VERSION_CODENAME=jammy
+UBUNTU_CODENAME=jammy`
+
+	err := os.WriteFile(tempFile, []byte(content), 0644)
+	require.NoError(t, err)
+
+	result, err := ParseOSRelease(tempFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Ubuntu", result["NAME"])
+	assert.Equal(t, "ubuntu", result["ID"])
+	assert.Equal(t, "22.04", result["VERSION_ID"])
+	assert.Equal(t, "jammy", result["VERSION_CODENAME"])
+}
+
+func TestExtractVersionFromContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "simple version",
+			content:  "8.4",
+			expected: "8.4",
+		},
+		{
+			name:     "version with text",
+			content:  "CentOS Linux release 8.4.2105 (Core)",
+			expected: "8.4.2105",
+		},
+		{
+			name:     "ubuntu version",
+			content:  "22.04",
+			expected: "22.04",
+		},
+		{
+			name:     "no version",
+			content:  "some text without version",
+			expected: "some",
+		},
+		{
+			name:     "empty content",
+			content:  "",
+			expected: "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExtractVersionFromContent(tt.content)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestFromOSRelease_Corpus(t *testing.T) {
+	tests := []struct {
+		file     string
+		os       string
+		version  string
+		idLike   []string
+		codename string
+		family   string
+		rolling  bool
+	}{
+		{"ubuntu-22.04", "ubuntu", "22.04", []string{"debian"}, "jammy", "debian", false},
+		{"debian-12", "debian", "12", nil, "bookworm", "debian", false},
+		{"debian-sid", "debian", "unknown", nil, "trixie", "debian", false},
+		{"linuxmint-21", "linuxmint", "21.2", []string{"ubuntu", "debian"}, "victoria", "debian", false},
+		{"rhel-9", "rhel", "9.3", []string{"fedora"}, "", "rhel", false},
+		{"rocky-9", "rocky", "9.3", []string{"rhel", "centos", "fedora"}, "", "rhel", false},
+		{"almalinux-8", "almalinux", "8.9", []string{"rhel", "centos", "fedora"}, "", "rhel", false},
+		{"centos-7", "centos", "7", []string{"rhel", "fedora"}, "", "rhel", false},
+		{"fedora-39", "fedora", "39", nil, "", "rhel", false},
+		{"amzn-2023", "amzn", "2023", []string{"fedora"}, "", "rhel", false},
+		{"opensuse-leap-15.5", "opensuse-leap", "15.5", []string{"suse", "opensuse"}, "", "suse", false},
+		{"opensuse-tumbleweed", "opensuse-tumbleweed", "20240105", []string{"opensuse", "suse"}, "", "suse", true},
+		{"arch", "arch", RollingVersion, nil, "", "arch", true},
+		{"manjaro", "manjaro", RollingVersion, []string{"arch"}, "", "arch", true},
+		{"alpine-3.19", "alpine", "3.19.0", nil, "", "alpine", false},
+		{"gentoo", "gentoo", "2.14", nil, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			fields, err := ParseOSRelease(filepath.Join("testdata", "os-release", tt.file))
+			require.NoError(t, err)
+
+			osInfo := FromOSRelease(fields)
+			assert.Equal(t, "linux", osInfo.Platform)
+			assert.Equal(t, tt.os, osInfo.OS)
+			assert.Equal(t, tt.version, osInfo.Version)
+			if tt.idLike == nil {
+				assert.Empty(t, osInfo.IDLike)
+			} else {
+				assert.Equal(t, tt.idLike, osInfo.IDLike)
+			}
+			assert.Equal(t, tt.codename, osInfo.Codename)
+			assert.Equal(t, tt.family, osInfo.Family())
+			assert.Equal(t, tt.rolling, osInfo.Rolling)
+			assert.NotEmpty(t, osInfo.Name)
+		})
+	}
+}
+
+func TestFromOSRelease_PrettyNameVersion(t *testing.T) {
+	osInfo := FromOSRelease(ParseOSReleaseContent(`ID=ubuntu
+PRETTY_NAME="Ubuntu 22.04.3 LTS"`))
+
+	assert.Equal(t, "22.04", osInfo.Version)
+}
+
+func TestOSInfo_Family(t *testing.T) {
+	assert.Equal(t, "debian", FamilyOf("ubuntu"))
+	assert.Equal(t, "rhel", FamilyOf("Rocky"))
+	assert.Equal(t, "suse", FamilyOf("opensuse-leap"))
+	assert.Equal(t, "", FamilyOf("gentoo"))
+
+	// Unknown derivatives fall back to ID_LIKE
+	derived := &OSInfo{OS: "neon", IDLike: []string{"ubuntu", "debian"}}
+	assert.Equal(t, "debian", derived.Family())
+	assert.True(t, derived.IsLike("debian"))
+	assert.False(t, derived.IsLike("fedora"))
+}
+
+func TestIsWSLKernel(t *testing.T) {
+	assert.True(t, IsWSLKernel("5.15.133.1-microsoft-standard-WSL2"))
+	assert.True(t, IsWSLKernel("4.4.0-19041-Microsoft"))
+	assert.False(t, IsWSLKernel("6.5.0-14-generic"))
+}
+
+func TestDetect(t *testing.T) {
+	osInfo, err := Detect()
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, osInfo.Platform)
+	assert.NotEmpty(t, osInfo.OS)
+	assert.NotEmpty(t, osInfo.Version)
+	assert.NotEmpty(t, osInfo.Architecture)
+}
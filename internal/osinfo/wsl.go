@@ -0,0 +1,78 @@
+package osinfo
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// DefaultWSLMountRoot is where WSL mounts Windows drives unless /etc/wsl.conf says otherwise
+const DefaultWSLMountRoot = "/mnt/"
+
+// WSLMountRoot returns the directory Windows drives are mounted under, honoring the
+// [automount] root setting of /etc/wsl.conf
+func WSLMountRoot() string {
+	content, err := os.ReadFile("/etc/wsl.conf")
+	if err != nil {
+		return DefaultWSLMountRoot
+	}
+	return parseWSLMountRoot(string(content))
+}
+
+// parseWSLMountRoot extracts the automount root from wsl.conf content
+func parseWSLMountRoot(content string) string {
+	section := ""
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found || section != "automount" || strings.TrimSpace(key) != "root" {
+			continue
+		}
+		if root := strings.Trim(strings.TrimSpace(value), `"'`); root != "" {
+			return strings.TrimSuffix(root, "/") + "/"
+		}
+	}
+	return DefaultWSLMountRoot
+}
+
+// WindowsToWSLPath maps a Windows path like C:\Program Files\App to its location
+// under the WSL mount root (/mnt/c/Program Files/App). Paths without a drive letter
+// are returned unchanged.
+func WindowsToWSLPath(windowsPath, mountRoot string) string {
+	if len(windowsPath) < 2 || windowsPath[1] != ':' || !isDriveLetter(windowsPath[0]) {
+		return windowsPath
+	}
+
+	drive := strings.ToLower(windowsPath[:1])
+	rest := strings.ReplaceAll(windowsPath[2:], "\\", "/")
+	return path.Join(mountRoot, drive, rest)
+}
+
+// WSLToWindowsPath maps a path under the WSL mount root back to a Windows path.
+// Paths outside the mount root are returned unchanged.
+func WSLToWindowsPath(wslPath, mountRoot string) string {
+	mountRoot = strings.TrimSuffix(mountRoot, "/") + "/"
+	if !strings.HasPrefix(wslPath, mountRoot) {
+		return wslPath
+	}
+
+	rest := strings.TrimPrefix(wslPath, mountRoot)
+	drive, remainder, _ := strings.Cut(rest, "/")
+	if len(drive) != 1 || !isDriveLetter(drive[0]) {
+		return wslPath
+	}
+	return strings.ToUpper(drive) + ":\\" + strings.ReplaceAll(remainder, "/", "\\")
+}
+
+// isDriveLetter reports whether c is an ASCII letter
+func isDriveLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,41 @@
+package osinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowsToWSLPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		mountRoot string
+		expected  string
+	}{
+		{"program files", `C:\Program Files\Nginx\nginx.exe`, "/mnt/", "/mnt/c/Program Files/Nginx/nginx.exe"},
+		{"lower case drive", `d:\data`, "/mnt/", "/mnt/d/data"},
+		{"drive root", `C:\`, "/mnt/", "/mnt/c"},
+		{"custom mount root", `C:\ProgramData\App`, "/", "/c/ProgramData/App"},
+		{"not a windows path", "/etc/nginx", "/mnt/", "/etc/nginx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, WindowsToWSLPath(tt.path, tt.mountRoot))
+		})
+	}
+}
+
+func TestWSLToWindowsPath(t *testing.T) {
+	assert.Equal(t, `C:\Program Files\Nginx`, WSLToWindowsPath("/mnt/c/Program Files/Nginx", "/mnt/"))
+	assert.Equal(t, `D:\`, WSLToWindowsPath("/mnt/d", "/mnt"))
+	assert.Equal(t, "/mnt/data/file", WSLToWindowsPath("/mnt/data/file", "/mnt/"))
+	assert.Equal(t, "/etc/nginx", WSLToWindowsPath("/etc/nginx", "/mnt/"))
+}
+
+func TestParseWSLMountRoot(t *testing.T) {
+	assert.Equal(t, DefaultWSLMountRoot, parseWSLMountRoot(""))
+	assert.Equal(t, "/", parseWSLMountRoot("[automount]\nroot = /\n"))
+	assert.Equal(t, "/win/", parseWSLMountRoot("[network]\nroot = /ignored\n[automount]\nenabled = true\nroot = \"/win\"\n"))
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/fatih/color"
 	"sai/internal/config"
+	"sai/internal/debug"
 )
 
 // OutputFormatter handles all output formatting for the SAI CLI
@@ -32,6 +33,7 @@ func NewOutputFormatter(cfg *config.Config, verbose, quiet, jsonOutput bool) *Ou
 
 // FormatCommand formats a command for display before execution (Requirement 10.1)
 func (f *OutputFormatter) FormatCommand(command string, provider string) string {
+	command = debug.MaskSecrets(command)
 	if f.jsonMode {
 		return command // JSON mode doesn't format commands
 	}
@@ -54,6 +56,7 @@ func (f *OutputFormatter) FormatProviderName(provider string) string {
 
 // FormatCommandOutput formats command output with exit status (Requirement 10.3)
 func (f *OutputFormatter) FormatCommandOutput(output string, exitCode int) string {
+	output = debug.MaskSecrets(output)
 	if f.jsonMode {
 		return output // JSON mode handles output differently
 	}
@@ -86,6 +89,7 @@ func (f *OutputFormatter) FormatJSON(data interface{}) string {
 
 // ShowProgress displays a progress message
 func (f *OutputFormatter) ShowProgress(message string) {
+	message = debug.MaskSecrets(message)
 	if f.quietMode || f.jsonMode {
 		return
 	}
@@ -99,9 +103,10 @@ func (f *OutputFormatter) ShowProgress(message string) {
 
 // ShowError displays an error message
 func (f *OutputFormatter) ShowError(err error) {
+	message := debug.MaskSecrets(err.Error())
 	if f.jsonMode {
 		errorData := map[string]interface{}{
-			"error": err.Error(),
+			"error": message,
 			"type":  "error",
 		}
 		fmt.Println(f.FormatJSON(errorData))
@@ -110,14 +115,15 @@ func (f *OutputFormatter) ShowError(err error) {
 
 	if f.colorEnabled {
 		errorColor := f.getColorFunc(f.config.Output.ErrorColor)
-		fmt.Fprintf(os.Stderr, "%s %s\n", errorColor("Error:"), err.Error())
+		fmt.Fprintf(os.Stderr, "%s %s\n", errorColor("Error:"), message)
 	} else {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Error: %s\n", message)
 	}
 }
 
 // ShowSuccess displays a success message
 func (f *OutputFormatter) ShowSuccess(message string) {
+	message = debug.MaskSecrets(message)
 	if f.quietMode {
 		return
 	}
@@ -141,6 +147,7 @@ func (f *OutputFormatter) ShowSuccess(message string) {
 
 // ShowWarning displays a warning message
 func (f *OutputFormatter) ShowWarning(message string) {
+	message = debug.MaskSecrets(message)
 	if f.quietMode {
 		return
 	}
@@ -163,6 +170,7 @@ func (f *OutputFormatter) ShowWarning(message string) {
 
 // ShowInfo displays an informational message
 func (f *OutputFormatter) ShowInfo(message string) {
+	message = debug.MaskSecrets(message)
 	if f.quietMode {
 		return
 	}
@@ -181,6 +189,7 @@ func (f *OutputFormatter) ShowInfo(message string) {
 
 // ShowDebug displays a debug message (only in verbose mode)
 func (f *OutputFormatter) ShowDebug(message string) {
+	message = debug.MaskSecrets(message)
 	if !f.verboseMode || f.quietMode {
 		return
 	}
@@ -203,6 +212,7 @@ func (f *OutputFormatter) ShowDebug(message string) {
 
 // ShowCommandPreview shows commands that will be executed
 func (f *OutputFormatter) ShowCommandPreview(commands []string, provider string) {
+	commands = debug.MaskSecretsAll(commands)
 	if f.quietMode {
 		return
 	}
@@ -302,6 +312,7 @@ func (f *OutputFormatter) getColorFunc(colorName string) func(a ...interface{})
 // FormatCompactCommand formats a command in compact format for provider selection
 // This implements Requirement 15.3 - compact output format showing "Command: (full command)"
 func (f *OutputFormatter) FormatCompactCommand(command string, provider string) string {
+	command = debug.MaskSecrets(command)
 	if f.jsonMode {
 		return command
 	}
@@ -322,6 +333,8 @@ func (f *OutputFormatter) FormatCompactCommand(command string, provider string)
 // FormatProviderResult formats the result from a single provider in multi-provider execution
 // This implements Requirement 15.5 - more concise and informative output
 func (f *OutputFormatter) FormatProviderResult(provider string, success bool, output string, commands []string) {
+	output = debug.MaskSecrets(output)
+	commands = debug.MaskSecretsAll(commands)
 	if f.jsonMode {
 		resultData := map[string]interface{}{
 			"provider": provider,
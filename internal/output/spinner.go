@@ -0,0 +1,53 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// spinnerFrames animate the spinner, like the running tasks of the terminal UI
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerInterval is the time between two spinner frames
+const spinnerInterval = 100 * time.Millisecond
+
+// StartSpinner shows message with a spinner until the returned function is called,
+// which clears it. When standard output is not a terminal the message is printed once,
+// and nothing is shown in quiet and JSON modes.
+func (f *OutputFormatter) StartSpinner(message string) func() {
+	if f.quietMode || f.jsonMode {
+		return func() {}
+	}
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		fmt.Println(message)
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for frame := 0; ; frame = (frame + 1) % len(spinnerFrames) {
+			fmt.Printf("\r%s %s", spinnerFrames[frame], message)
+			select {
+			case <-done:
+				fmt.Print("\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
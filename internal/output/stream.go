@@ -0,0 +1,126 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"sai/internal/debug"
+)
+
+// streamQuietPeriod is how long a streamed command may print nothing before a spinner
+// with the elapsed time shows it is still running
+const streamQuietPeriod = 2 * time.Second
+
+// streamWriter prints the output of the commands of a provider line by line as they
+// produce it, each line prefixed with the provider
+type streamWriter struct {
+	formatter *OutputFormatter
+	provider  string
+	out       io.Writer
+	terminal  bool // Draw the spinner, which needs carriage returns
+
+	mutex     sync.Mutex
+	partial   []byte // Output of the current line, until its newline
+	lastLine  time.Time
+	started   time.Time
+	spinning  bool
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// StreamWriter returns a writer showing command output live, prefixed with the
+// provider, with secrets masked. On a terminal, a spinner with the elapsed time is
+// shown while the commands print nothing, such as during long builds. Close flushes a
+// last unterminated line and stops the spinner.
+func (f *OutputFormatter) StreamWriter(provider string) io.WriteCloser {
+	sw := &streamWriter{
+		formatter: f,
+		provider:  provider,
+		out:       os.Stdout,
+		started:   time.Now(),
+		lastLine:  time.Now(),
+		done:      make(chan struct{}),
+	}
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		sw.terminal = true
+		sw.wg.Add(1)
+		go sw.spin()
+	}
+	return sw
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+
+	sw.partial = append(sw.partial, p...)
+	for {
+		newline := bytes.IndexByte(sw.partial, '\n')
+		if newline < 0 {
+			break
+		}
+		sw.printLine(string(bytes.TrimRight(sw.partial[:newline], "\r")))
+		sw.partial = sw.partial[newline+1:]
+	}
+	return len(p), nil
+}
+
+// Close prints the last unterminated line and stops the spinner
+func (sw *streamWriter) Close() error {
+	sw.closeOnce.Do(func() {
+		close(sw.done)
+		sw.wg.Wait()
+
+		sw.mutex.Lock()
+		defer sw.mutex.Unlock()
+		if len(sw.partial) > 0 {
+			sw.printLine(string(sw.partial))
+			sw.partial = nil
+		}
+		sw.clearSpinner()
+	})
+	return nil
+}
+
+// printLine prints a line of output, replacing the spinner. Called with the mutex held.
+func (sw *streamWriter) printLine(line string) {
+	sw.clearSpinner()
+	fmt.Fprintf(sw.out, "%s %s\n", sw.formatter.FormatProviderName(sw.provider), debug.MaskSecrets(line))
+	sw.lastLine = time.Now()
+}
+
+// clearSpinner erases the spinner line. Called with the mutex held.
+func (sw *streamWriter) clearSpinner() {
+	if sw.spinning {
+		fmt.Fprint(sw.out, "\r\033[K")
+		sw.spinning = false
+	}
+}
+
+// spin draws the spinner while no line was printed for streamQuietPeriod
+func (sw *streamWriter) spin() {
+	defer sw.wg.Done()
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+
+	for frame := 0; ; frame = (frame + 1) % len(spinnerFrames) {
+		select {
+		case <-sw.done:
+			return
+		case <-ticker.C:
+		}
+
+		sw.mutex.Lock()
+		if time.Since(sw.lastLine) >= streamQuietPeriod {
+			elapsed := time.Since(sw.started).Round(time.Second)
+			fmt.Fprintf(sw.out, "\r\033[K%s %s running for %s", spinnerFrames[frame], sw.provider, elapsed)
+			sw.spinning = true
+		}
+		sw.mutex.Unlock()
+	}
+}
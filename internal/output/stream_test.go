@@ -0,0 +1,27 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"sai/internal/config"
+)
+
+func TestStreamWriter(t *testing.T) {
+	formatter := NewOutputFormatter(&config.Config{}, false, false, false)
+	formatter.colorEnabled = false
+
+	var out strings.Builder
+	sw := &streamWriter{formatter: formatter, provider: "source", out: &out, done: make(chan struct{})}
+
+	sw.Write([]byte("checking for gcc... yes\r\nmak"))
+	sw.Write([]byte("e: entering directory\npartial"))
+	if got, want := out.String(), "[source] checking for gcc... yes\n[source] make: entering directory\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	sw.Close()
+	if !strings.HasSuffix(out.String(), "[source] partial\n") {
+		t.Errorf("Close() did not flush the last line: %q", out.String())
+	}
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the functions available to --format templates
+var templateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// FormatTemplate renders results with a Go template, as with --format. A slice or
+// array is rendered item by item, one line per item, omitting items rendered empty so
+// templates can filter with {{if}}; any other value is rendered once.
+func FormatTemplate(format string, results interface{}) (string, error) {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("invalid format template: %w", err)
+	}
+
+	var items []interface{}
+	value := reflect.ValueOf(results)
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, value.Index(i).Interface())
+		}
+	} else {
+		items = []interface{}{results}
+	}
+
+	var buf strings.Builder
+	for _, item := range items {
+		var line strings.Builder
+		if err := tmpl.Execute(&line, item); err != nil {
+			return "", fmt.Errorf("failed to format output: %w", err)
+		}
+		if line.Len() > 0 {
+			buf.WriteString(line.String())
+			buf.WriteString("\n")
+		}
+	}
+	return buf.String(), nil
+}
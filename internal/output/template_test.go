@@ -0,0 +1,55 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+type formatItem struct {
+	Provider  string
+	Version   string
+	Installed bool
+	Tags      []string
+}
+
+func TestFormatTemplate(t *testing.T) {
+	items := []*formatItem{
+		{Provider: "apt", Version: "1.24", Installed: true, Tags: []string{"web", "proxy"}},
+		{Provider: "brew", Version: "1.25"},
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		results  interface{}
+		expected string
+	}{
+		{"slice item by item", "{{.Provider}}:{{.Version}}", items, "apt:1.24\nbrew:1.25\n"},
+		{"empty items omitted", "{{if .Installed}}{{.Provider}}{{end}}", items, "apt\n"},
+		{"functions", "{{upper .Provider}} {{join .Tags \",\"}}", items[:1], "APT web,proxy\n"},
+		{"json", "{{json .Tags}}", items[:1], "[\"web\",\"proxy\"]\n"},
+		{"single value", "{{.Provider}}", items[1], "brew\n"},
+		{"empty slice", "{{.Provider}}", []*formatItem{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := FormatTemplate(tt.format, tt.results)
+			if err != nil {
+				t.Fatalf("FormatTemplate() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("FormatTemplate() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatTemplate_Errors(t *testing.T) {
+	if _, err := FormatTemplate("{{.Provider", nil); err == nil || !strings.Contains(err.Error(), "invalid format template") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+	if _, err := FormatTemplate("{{.Missing}}", []formatItem{{}}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,190 @@
+// Package parsers normalizes the output of the commands providers use to list the
+// installed packages and to search packages. Provider actions select a parser by name
+// with their parser field, from the list parsers for list_installed and from the search
+// parsers for search.
+package parsers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Default is the parser of list actions without parser
+const Default = "name-version"
+
+// Package is an installed package listed by a provider
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Parser extracts the installed packages from the output of a list command
+type Parser func(output string) ([]Package, error)
+
+var (
+	registry = map[string]Parser{
+		"name-version": parseNameVersion,
+		"table":        parseTable,
+		"pipe":         parsePipe,
+		"json":         parseJSON,
+		"gem":          parseGem,
+		"cargo":        parseCargo,
+	}
+	registryMutex sync.RWMutex
+)
+
+// Register adds a parser to the registry, replacing the parser with the same name
+func Register(name string, parser Parser) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	registry[name] = parser
+}
+
+// Get returns the parser registered with a name, the default parser for ""
+func Get(name string) (Parser, error) {
+	if name == "" {
+		name = Default
+	}
+
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	parser, exists := registry[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown output parser '%s'", name)
+	}
+	return parser, nil
+}
+
+// Names returns the names of the registered parsers
+func Names() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lines returns the non-empty, non-comment lines of output
+func lines(output string) []string {
+	var result []string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// parseNameVersion parses "name [version]" lines, as printed by dpkg-query, rpm,
+// pacman -Q or brew list --versions. Only the first of several versions is kept.
+func parseNameVersion(output string) ([]Package, error) {
+	var packages []Package
+	for _, line := range lines(output) {
+		fields := strings.Fields(line)
+		pkg := Package{Name: fields[0]}
+		if len(fields) > 1 {
+			pkg.Version = fields[1]
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// parseTable parses a table with a header line followed by "name version ..." rows,
+// as printed by snap list
+func parseTable(output string) ([]Package, error) {
+	rows := lines(output)
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return parseNameVersion(strings.Join(rows[1:], "\n"))
+}
+
+// parsePipe parses "name|version" lines, as printed by choco list --limit-output
+func parsePipe(output string) ([]Package, error) {
+	var packages []Package
+	for _, line := range lines(output) {
+		name, version, _ := strings.Cut(line, "|")
+		packages = append(packages, Package{Name: strings.TrimSpace(name), Version: strings.TrimSpace(version)})
+	}
+	return packages, nil
+}
+
+// parseJSON parses a JSON array of objects with name and version, as printed by
+// pip list --format=json, or the dependencies object printed by npm ls --json
+func parseJSON(output string) ([]Package, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(output, "[") {
+		var packages []Package
+		if err := json.Unmarshal([]byte(output), &packages); err != nil {
+			return nil, fmt.Errorf("failed to parse package list: %w", err)
+		}
+		return packages, nil
+	}
+
+	var tree struct {
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal([]byte(output), &tree); err != nil {
+		return nil, fmt.Errorf("failed to parse package list: %w", err)
+	}
+	var packages []Package
+	for name, dependency := range tree.Dependencies {
+		packages = append(packages, Package{Name: name, Version: dependency.Version})
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+	return packages, nil
+}
+
+// parseGem parses the "name (version, ...)" lines printed by gem list
+func parseGem(output string) ([]Package, error) {
+	var packages []Package
+	for _, line := range lines(output) {
+		name, versions, found := strings.Cut(line, " (")
+		pkg := Package{Name: strings.TrimSpace(name)}
+		if found {
+			version, _, _ := strings.Cut(strings.TrimSuffix(versions, ")"), ",")
+			pkg.Version = strings.TrimSpace(strings.TrimPrefix(version, "default:"))
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// parseCargo parses the "name vversion:" lines printed by cargo install --list,
+// skipping the indented lines of the installed binaries
+func parseCargo(output string) ([]Package, error) {
+	var packages []Package
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(line), ":"))
+		if len(fields) == 0 {
+			continue
+		}
+		pkg := Package{Name: fields[0]}
+		if len(fields) > 1 {
+			pkg.Version = strings.TrimPrefix(fields[1], "v")
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
@@ -0,0 +1,156 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsers(t *testing.T) {
+	tests := []struct {
+		parser   string
+		output   string
+		expected []Package
+	}{
+		{
+			parser:   "",
+			output:   "curl 7.88.1-10\nnginx 1.22.1-9\n\n",
+			expected: []Package{{Name: "curl", Version: "7.88.1-10"}, {Name: "nginx", Version: "1.22.1-9"}},
+		},
+		{
+			parser:   "name-version",
+			output:   "git 2.43.0 2.42.0\nwget\n",
+			expected: []Package{{Name: "git", Version: "2.43.0"}, {Name: "wget"}},
+		},
+		{
+			parser:   "table",
+			output:   "Name    Version   Rev    Tracking       Publisher   Notes\ncore22  20240111  1122   latest/stable  canonical✓  base\nlxd     5.0.3     27037  5.0/stable     canonical✓  -\n",
+			expected: []Package{{Name: "core22", Version: "20240111"}, {Name: "lxd", Version: "5.0.3"}},
+		},
+		{
+			parser:   "pipe",
+			output:   "chocolatey|2.2.2\ngit|2.43.0\n",
+			expected: []Package{{Name: "chocolatey", Version: "2.2.2"}, {Name: "git", Version: "2.43.0"}},
+		},
+		{
+			parser:   "json",
+			output:   `[{"name": "pip", "version": "23.3.1"}, {"name": "requests", "version": "2.31.0"}]`,
+			expected: []Package{{Name: "pip", Version: "23.3.1"}, {Name: "requests", Version: "2.31.0"}},
+		},
+		{
+			parser:   "json",
+			output:   `{"name": "lib", "dependencies": {"typescript": {"version": "5.3.3"}, "npm": {"version": "10.2.4"}}}`,
+			expected: []Package{{Name: "npm", Version: "10.2.4"}, {Name: "typescript", Version: "5.3.3"}},
+		},
+		{
+			parser:   "gem",
+			output:   "bundler (default: 2.4.10)\nrake (13.1.0, 13.0.6)\n",
+			expected: []Package{{Name: "bundler", Version: "2.4.10"}, {Name: "rake", Version: "13.1.0"}},
+		},
+		{
+			parser:   "cargo",
+			output:   "ripgrep v14.1.0:\n    rg\nbat v0.24.0:\n    bat\n",
+			expected: []Package{{Name: "ripgrep", Version: "14.1.0"}, {Name: "bat", Version: "0.24.0"}},
+		},
+	}
+
+	for _, tt := range tests {
+		parser, err := Get(tt.parser)
+		require.NoError(t, err)
+		packages, err := parser(tt.output)
+		require.NoError(t, err, tt.parser)
+		assert.Equal(t, tt.expected, packages, tt.parser)
+	}
+}
+
+func TestParseJSONInvalid(t *testing.T) {
+	_, err := parseJSON("[{")
+	assert.Error(t, err)
+
+	packages, err := parseJSON("")
+	assert.NoError(t, err)
+	assert.Empty(t, packages)
+}
+
+func TestRegistry(t *testing.T) {
+	_, err := Get("xml")
+	assert.Error(t, err)
+
+	Register("lines", func(output string) ([]Package, error) {
+		return []Package{{Name: output}}, nil
+	})
+	parser, err := Get("lines")
+	require.NoError(t, err)
+	packages, err := parser("custom")
+	require.NoError(t, err)
+	assert.Equal(t, []Package{{Name: "custom"}}, packages)
+	assert.Contains(t, Names(), "lines")
+}
+
+func TestSearchParsers(t *testing.T) {
+	tests := []struct {
+		parser   string
+		output   string
+		expected []SearchResult
+	}{
+		{
+			parser: "apt-cache",
+			output: "Package: nginx\nVersion: 1.24.0-2ubuntu7\nOrigin: Ubuntu\nFilename: pool/main/n/nginx/nginx_1.24.0-2ubuntu7_amd64.deb\nDescription: small, powerful, scalable web/proxy server\n Nginx (\"engine X\") is a high-performance web and reverse proxy server.\n\n" +
+				"Package: nginx\nVersion: 1.24.0-2ubuntu1\nDescription: small, powerful, scalable web/proxy server\n\n" +
+				"Package: nginx-extras\nVersion: 1.24.0-2ubuntu7\nFilename: pool/universe/n/nginx/nginx-extras_1.24.0-2ubuntu7_amd64.deb\nDescription-en: nginx web/proxy server (extended version)\n",
+			expected: []SearchResult{
+				{Name: "nginx", Version: "1.24.0-2ubuntu7", Description: "small, powerful, scalable web/proxy server", Repository: "Ubuntu/main"},
+				{Name: "nginx-extras", Version: "1.24.0-2ubuntu7", Description: "nginx web/proxy server (extended version)", Repository: "universe"},
+			},
+		},
+		{
+			parser: "brew-json",
+			output: `{"formulae": [{"name": "nginx", "desc": "HTTP(S) server", "tap": "homebrew/core", "versions": {"stable": "1.25.3"}}], "casks": [{"token": "firefox", "desc": "Web browser", "tap": "homebrew/cask", "version": "121.0"}]}`,
+			expected: []SearchResult{
+				{Name: "nginx", Version: "1.25.3", Description: "HTTP(S) server", Repository: "homebrew/core"},
+				{Name: "firefox", Version: "121.0", Description: "Web browser", Repository: "homebrew/cask"},
+			},
+		},
+		{
+			parser: "dnf-repoquery",
+			output: "nginx|1.24.0-1.fc39|updates|A high performance web server | reverse proxy\nnginx|1.24.0-0.fc39|fedora|A high performance web server | reverse proxy\n",
+			expected: []SearchResult{
+				{Name: "nginx", Version: "1.24.0-1.fc39", Description: "A high performance web server | reverse proxy", Repository: "updates"},
+			},
+		},
+		{
+			parser: "npm-json",
+			output: `[{"name": "express", "scope": "unscoped", "version": "4.18.2", "description": "Fast, unopinionated, minimalist web framework"}, {"name": "@types/express", "scope": "types", "version": "4.17.21", "description": "TypeScript definitions for express"}]`,
+			expected: []SearchResult{
+				{Name: "express", Version: "4.18.2", Description: "Fast, unopinionated, minimalist web framework"},
+				{Name: "@types/express", Version: "4.17.21", Description: "TypeScript definitions for express", Repository: "@types"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		parser, err := GetSearch(tt.parser)
+		require.NoError(t, err)
+		results, err := parser(tt.output)
+		require.NoError(t, err, tt.parser)
+		assert.Equal(t, tt.expected, results, tt.parser)
+	}
+}
+
+func TestSearchRegistry(t *testing.T) {
+	_, err := GetSearch("")
+	assert.Error(t, err)
+	_, err = parseDnfRepoquery("nginx 1.24.0\n")
+	assert.Error(t, err)
+
+	RegisterSearch("pkg", func(output string) ([]SearchResult, error) {
+		return []SearchResult{{Name: output}}, nil
+	})
+	parser, err := GetSearch("pkg")
+	require.NoError(t, err)
+	results, err := parser("nginx")
+	require.NoError(t, err)
+	assert.Equal(t, []SearchResult{{Name: "nginx"}}, results)
+	assert.Contains(t, SearchNames(), "pkg")
+}
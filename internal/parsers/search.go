@@ -0,0 +1,218 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SearchResult is a package found by the search action of a provider
+type SearchResult struct {
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+	Repository  string `json:"repository,omitempty"` // Repository, tap or origin the package comes from
+}
+
+// SearchParser extracts the packages found from the output of a search command
+type SearchParser func(output string) ([]SearchResult, error)
+
+var (
+	searchRegistry = map[string]SearchParser{
+		"apt-cache":     parseAptCache,
+		"brew-json":     parseBrewJSON,
+		"dnf-repoquery": parseDnfRepoquery,
+		"npm-json":      parseNpmJSON,
+	}
+	searchRegistryMutex sync.RWMutex
+)
+
+// RegisterSearch adds a search parser to the registry, replacing the search parser
+// with the same name
+func RegisterSearch(name string, parser SearchParser) {
+	searchRegistryMutex.Lock()
+	defer searchRegistryMutex.Unlock()
+	searchRegistry[name] = parser
+}
+
+// GetSearch returns the search parser registered with a name
+func GetSearch(name string) (SearchParser, error) {
+	searchRegistryMutex.RLock()
+	defer searchRegistryMutex.RUnlock()
+	parser, exists := searchRegistry[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown search parser '%s'", name)
+	}
+	return parser, nil
+}
+
+// SearchNames returns the names of the registered search parsers
+func SearchNames() []string {
+	searchRegistryMutex.RLock()
+	defer searchRegistryMutex.RUnlock()
+
+	names := make([]string, 0, len(searchRegistry))
+	for name := range searchRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseAptCache parses the package records printed by apt-cache search --full or
+// apt-cache show. The repository is the origin of the archive and the component of
+// the package, such as Ubuntu/universe, as far as the record tells them.
+func parseAptCache(output string) ([]SearchResult, error) {
+	var results []SearchResult
+	var current *SearchResult
+	var origin, component string
+
+	flush := func() {
+		if current != nil && current.Name != "" {
+			current.Repository = strings.Trim(origin+"/"+component, "/")
+			results = append(results, *current)
+		}
+		current, origin, component = nil, "", ""
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		// Continuation lines of the long description
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		field, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		if current == nil {
+			current = &SearchResult{}
+		}
+		value = strings.TrimSpace(value)
+		switch field {
+		case "Package":
+			current.Name = value
+		case "Version":
+			current.Version = value
+		case "Description", "Description-en":
+			if current.Description == "" {
+				current.Description = value
+			}
+		case "Origin":
+			origin = value
+		case "Filename":
+			// pool/<component>/<prefix>/<source>/<file>
+			if parts := strings.Split(value, "/"); len(parts) > 2 && parts[0] == "pool" {
+				component = parts[1]
+			}
+		}
+	}
+	flush()
+
+	// apt-cache prints a record per version, the first one is the candidate
+	return uniqueResults(results), nil
+}
+
+// parseBrewJSON parses the formulae and casks printed by brew info --json=v2
+func parseBrewJSON(output string) ([]SearchResult, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil, nil
+	}
+
+	var info struct {
+		Formulae []struct {
+			Name     string `json:"name"`
+			Desc     string `json:"desc"`
+			Tap      string `json:"tap"`
+			Versions struct {
+				Stable string `json:"stable"`
+			} `json:"versions"`
+		} `json:"formulae"`
+		Casks []struct {
+			Token   string `json:"token"`
+			Desc    string `json:"desc"`
+			Tap     string `json:"tap"`
+			Version string `json:"version"`
+		} `json:"casks"`
+	}
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info: %w", err)
+	}
+
+	var results []SearchResult
+	for _, formula := range info.Formulae {
+		results = append(results, SearchResult{Name: formula.Name, Version: formula.Versions.Stable, Description: formula.Desc, Repository: formula.Tap})
+	}
+	for _, cask := range info.Casks {
+		results = append(results, SearchResult{Name: cask.Token, Version: cask.Version, Description: cask.Desc, Repository: cask.Tap})
+	}
+	return results, nil
+}
+
+// parseDnfRepoquery parses the "name|version|repository|summary" lines printed by
+// dnf repoquery --queryformat '%{name}|%{version}-%{release}|%{repoid}|%{summary}\n'
+func parseDnfRepoquery(output string) ([]SearchResult, error) {
+	var results []SearchResult
+	for _, line := range lines(output) {
+		fields := strings.SplitN(line, "|", 4)
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("unexpected repoquery line %q", line)
+		}
+		results = append(results, SearchResult{
+			Name:        strings.TrimSpace(fields[0]),
+			Version:     strings.TrimSpace(fields[1]),
+			Repository:  strings.TrimSpace(fields[2]),
+			Description: strings.TrimSpace(fields[3]),
+		})
+	}
+	return uniqueResults(results), nil
+}
+
+// parseNpmJSON parses the array printed by npm search --json. Scoped packages are
+// attributed to their scope.
+func parseNpmJSON(output string) ([]SearchResult, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil, nil
+	}
+
+	var packages []struct {
+		Name        string `json:"name"`
+		Scope       string `json:"scope"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal([]byte(output), &packages); err != nil {
+		return nil, fmt.Errorf("failed to parse npm search: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(packages))
+	for _, pkg := range packages {
+		result := SearchResult{Name: pkg.Name, Version: pkg.Version, Description: pkg.Description}
+		if pkg.Scope != "" && pkg.Scope != "unscoped" {
+			result.Repository = "@" + pkg.Scope
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// uniqueResults keeps the first result of each package name
+func uniqueResults(results []SearchResult) []SearchResult {
+	seen := make(map[string]bool, len(results))
+	unique := results[:0]
+	for _, result := range results {
+		if !seen[result.Name] {
+			seen[result.Name] = true
+			unique = append(unique, result)
+		}
+	}
+	return unique
+}
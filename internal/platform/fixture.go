@@ -0,0 +1,172 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables that override platform detection for tests
+const (
+	EnvTestOS          = "SAI_TEST_OS"
+	EnvTestOSVersion   = "SAI_TEST_OS_VERSION"
+	EnvTestPlatform    = "SAI_TEST_PLATFORM"
+	EnvTestArch        = "SAI_TEST_ARCH"
+	EnvTestFixture     = "SAI_TEST_FIXTURE"
+	EnvTestExecutables = "SAI_TEST_EXECUTABLES"
+)
+
+// Fixture describes a faked system for deterministic tests. When Executables is
+// set, executable availability is answered from the list instead of $PATH.
+type Fixture struct {
+	Platform     string   `yaml:"platform"`
+	OS           string   `yaml:"os"`
+	Version      string   `yaml:"version"`
+	Architecture string   `yaml:"architecture"`
+	IDLike       []string `yaml:"id_like"`
+	Executables  []string `yaml:"executables"`
+}
+
+// LoadFixture reads a fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test fixture %s: %w", path, err)
+	}
+
+	var fixture Fixture
+	if err := yaml.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse test fixture %s: %w", path, err)
+	}
+
+	return &fixture, nil
+}
+
+// ActiveFixture returns the fixture described by SAI_TEST_FIXTURE and the other
+// SAI_TEST_* variables, which override fields of the file. It returns nil when
+// no test override is set.
+func ActiveFixture() (*Fixture, error) {
+	fixture := &Fixture{}
+	active := false
+
+	if path := os.Getenv(EnvTestFixture); path != "" {
+		loaded, err := LoadFixture(path)
+		if err != nil {
+			return nil, err
+		}
+		fixture = loaded
+		active = true
+	}
+
+	overrides := []struct {
+		env   string
+		field *string
+	}{
+		{EnvTestOS, &fixture.OS},
+		{EnvTestOSVersion, &fixture.Version},
+		{EnvTestPlatform, &fixture.Platform},
+		{EnvTestArch, &fixture.Architecture},
+	}
+	for _, override := range overrides {
+		if value := os.Getenv(override.env); value != "" {
+			*override.field = value
+			active = true
+		}
+	}
+
+	if value, ok := os.LookupEnv(EnvTestExecutables); ok {
+		fixture.Executables = splitList(value)
+		if fixture.Executables == nil {
+			fixture.Executables = []string{}
+		}
+		active = true
+	}
+
+	if !active {
+		return nil, nil
+	}
+	return fixture, nil
+}
+
+// FakesExecutables reports whether the fixture answers executable lookups
+func (f *Fixture) FakesExecutables() bool {
+	return f != nil && f.Executables != nil
+}
+
+// HasExecutable reports whether the fixture lists the executable
+func (f *Fixture) HasExecutable(name string) bool {
+	for _, executable := range f.Executables {
+		if executable == name || filepath.Base(executable) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// osInfo builds the OS information described by the fixture
+func (f *Fixture) osInfo() *OSInfo {
+	osInfo := &OSInfo{
+		Platform:     f.Platform,
+		OS:           strings.ToLower(f.OS),
+		Version:      f.Version,
+		Architecture: f.Architecture,
+		IDLike:       f.IDLike,
+		DetectedAt:   time.Now(),
+	}
+	if osInfo.Platform == "" {
+		osInfo.Platform = PlatformForOS(osInfo.OS)
+	}
+	if osInfo.Version == "" {
+		osInfo.Version = "unknown"
+	}
+	if osInfo.Architecture == "" {
+		osInfo.Architecture = runtime.GOARCH
+	}
+	return osInfo
+}
+
+// LookPath searches for an executable like exec.LookPath, answering from the
+// active fixture when it fakes executable availability
+func LookPath(name string) (string, error) {
+	fixture, err := ActiveFixture()
+	if err != nil {
+		return "", err
+	}
+
+	if fixture.FakesExecutables() {
+		if fixture.HasExecutable(name) {
+			for _, executable := range fixture.Executables {
+				if filepath.IsAbs(executable) && filepath.Base(executable) == name {
+					return executable, nil
+				}
+			}
+			return filepath.Join("/usr/bin", name), nil
+		}
+		return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+
+	return exec.LookPath(name)
+}
+
+// FakesExecutables reports whether executable availability is currently faked
+func FakesExecutables() bool {
+	fixture, err := ActiveFixture()
+	return err == nil && fixture.FakesExecutables()
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
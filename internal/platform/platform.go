@@ -0,0 +1,46 @@
+package platform
+
+import (
+	"strings"
+
+	"sai/internal/osinfo"
+)
+
+// OSInfo contains detailed operating system information
+type OSInfo = osinfo.OSInfo
+
+// Detect returns information about the running operating system. Test overrides
+// (SAI_TEST_OS, SAI_TEST_OS_VERSION, SAI_TEST_PLATFORM, SAI_TEST_ARCH and a
+// SAI_TEST_FIXTURE file) take precedence over real detection.
+func Detect() (*OSInfo, error) {
+	fixture, err := ActiveFixture()
+	if err != nil {
+		return nil, err
+	}
+
+	if fixture != nil && fixture.OS != "" {
+		return fixture.osInfo(), nil
+	}
+
+	osInfo, err := osinfo.Detect()
+	if err != nil {
+		return nil, err
+	}
+	if fixture != nil && fixture.Architecture != "" {
+		osInfo.Architecture = fixture.Architecture
+	}
+
+	return osInfo, nil
+}
+
+// PlatformForOS maps an OS or distribution name to its platform
+func PlatformForOS(osName string) string {
+	switch strings.ToLower(osName) {
+	case "macos", "darwin", "osx":
+		return "darwin"
+	case "windows":
+		return "windows"
+	default:
+		return "linux"
+	}
+}
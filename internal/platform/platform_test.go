@@ -0,0 +1,102 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect_TestOSOverride(t *testing.T) {
+	t.Setenv(EnvTestOS, "Ubuntu")
+	t.Setenv(EnvTestOSVersion, "22.04")
+	t.Setenv(EnvTestArch, "arm64")
+
+	osInfo, err := Detect()
+	require.NoError(t, err)
+
+	assert.Equal(t, "linux", osInfo.Platform)
+	assert.Equal(t, "ubuntu", osInfo.OS)
+	assert.Equal(t, "22.04", osInfo.Version)
+	assert.Equal(t, "arm64", osInfo.Architecture)
+}
+
+func TestDetect_PlatformDerivedFromOS(t *testing.T) {
+	tests := []struct {
+		os       string
+		expected string
+	}{
+		{"macos", "darwin"},
+		{"windows", "windows"},
+		{"rocky", "linux"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.os, func(t *testing.T) {
+			t.Setenv(EnvTestOS, tt.os)
+
+			osInfo, err := Detect()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, osInfo.Platform)
+			assert.Equal(t, "unknown", osInfo.Version)
+		})
+	}
+}
+
+func TestActiveFixture(t *testing.T) {
+	t.Run("no overrides", func(t *testing.T) {
+		fixture, err := ActiveFixture()
+		require.NoError(t, err)
+		assert.Nil(t, fixture)
+		assert.False(t, FakesExecutables())
+	})
+
+	t.Run("fixture file with env overrides", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fixture.yaml")
+		content := `os: debian
+version: "12"
+architecture: amd64
+executables:
+  - apt-get
+  - /usr/sbin/service
+`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		t.Setenv(EnvTestFixture, path)
+		t.Setenv(EnvTestOSVersion, "11")
+
+		osInfo, err := Detect()
+		require.NoError(t, err)
+		assert.Equal(t, "debian", osInfo.OS)
+		assert.Equal(t, "11", osInfo.Version)
+		assert.Equal(t, "amd64", osInfo.Architecture)
+
+		assert.True(t, FakesExecutables())
+
+		path, err = LookPath("apt-get")
+		require.NoError(t, err)
+		assert.Equal(t, "/usr/bin/apt-get", path)
+
+		path, err = LookPath("service")
+		require.NoError(t, err)
+		assert.Equal(t, "/usr/sbin/service", path)
+
+		_, err = LookPath("brew")
+		assert.Error(t, err)
+	})
+
+	t.Run("empty executables list fakes an empty system", func(t *testing.T) {
+		t.Setenv(EnvTestExecutables, "")
+
+		_, err := LookPath("sh")
+		assert.Error(t, err)
+	})
+
+	t.Run("missing fixture file", func(t *testing.T) {
+		t.Setenv(EnvTestFixture, filepath.Join(t.TempDir(), "missing.yaml"))
+
+		_, err := Detect()
+		assert.Error(t, err)
+	})
+}
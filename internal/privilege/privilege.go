@@ -0,0 +1,207 @@
+// Package privilege decides how the actions requiring root run when sai runs as
+// another user: through an escalation tool, sudo, doas, pkexec or runas, once the user
+// agreed to it or right away, or not at all, failing before anything changed.
+package privilege
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"sai/internal/errors"
+)
+
+// Escalation strategies of the actions requiring root, see Manager
+const (
+	EscalationReexec  = "reexec"  // Fail before anything changed, the CLI offers to re-run sai with sudo
+	EscalationPrepend = "prepend" // Run the commands of the action through the escalation tool
+	EscalationPrompt  = "prompt"  // Ask once per run, then run the commands through the escalation tool
+	EscalationFail    = "fail"    // Fail before anything changed
+)
+
+// Escalations lists the escalation strategies
+var Escalations = []string{EscalationReexec, EscalationPrepend, EscalationPrompt, EscalationFail}
+
+// Tool is an escalation tool running commands as root
+type Tool struct {
+	Name     string
+	Path     string   // Absolute path of the tool, resolved by FindTool
+	Args     []string // Arguments before the command
+	Validate []string // Arguments asking for the credentials of the user once, nil when the tool caches none
+	Join     bool     // Takes the command line as a single argument
+}
+
+// tools are the supported escalation tools by name
+var tools = map[string]Tool{
+	"sudo":   {Name: "sudo", Args: []string{"--"}, Validate: []string{"-v"}},
+	"doas":   {Name: "doas", Args: []string{"--"}},
+	"pkexec": {Name: "pkexec"},
+	"runas":  {Name: "runas", Args: []string{"/user:Administrator"}, Join: true},
+}
+
+// lookPath resolves the executable of a tool, replaced by tests
+var lookPath = exec.LookPath
+
+// FindTool returns an installed escalation tool by name, or the first installed one
+// of sudo, doas and pkexec, runas on Windows, when name is empty
+func FindTool(name string) (*Tool, error) {
+	candidates := defaultTools
+	if name != "" {
+		if _, known := tools[name]; !known {
+			return nil, fmt.Errorf("unknown escalation tool %s, must be one of: sudo, doas, pkexec, runas", name)
+		}
+		candidates = []string{name}
+	}
+
+	for _, candidate := range candidates {
+		if path, err := lookPath(candidate); err == nil {
+			tool := tools[candidate]
+			tool.Path = path
+			return &tool, nil
+		}
+	}
+	return nil, fmt.Errorf("no escalation tool found, install one of: %s", strings.Join(candidates, ", "))
+}
+
+// Wrap returns the command line running a command through the tool. The environment
+// variables of the command are passed through env since the tools reset the
+// environment; runas does not pass them.
+func (t *Tool) Wrap(parts []string, env map[string]string) []string {
+	path := t.Path
+	if path == "" {
+		path = t.Name
+	}
+	wrapped := append([]string{path}, t.Args...)
+
+	if t.Join {
+		quoted := make([]string, len(parts))
+		for i, part := range parts {
+			quoted[i] = part
+			if strings.ContainsAny(part, " \t\"") {
+				quoted[i] = `"` + strings.ReplaceAll(part, `"`, `\"`) + `"`
+			}
+		}
+		return append(wrapped, strings.Join(quoted, " "))
+	}
+
+	if len(env) > 0 {
+		names := make([]string, 0, len(env))
+		for name := range env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		wrapped = append(wrapped, "env")
+		for _, name := range names {
+			wrapped = append(wrapped, name+"="+env[name])
+		}
+	}
+	return append(wrapped, parts...)
+}
+
+// toolKey is the context key of the escalation tool of an action
+type toolKey struct{}
+
+// WithTool returns a context whose commands run through an escalation tool
+func WithTool(ctx context.Context, tool *Tool) context.Context {
+	return context.WithValue(ctx, toolKey{}, tool)
+}
+
+// ToolFrom returns the escalation tool commands run through in a context, nil to run
+// them as sai
+func ToolFrom(ctx context.Context) *Tool {
+	tool, _ := ctx.Value(toolKey{}).(*Tool)
+	return tool
+}
+
+// Manager escalates the actions requiring root when sai does not run as root, following
+// the privilege.escalation configuration. With the prompt strategy, the user is asked
+// once per run: the answer applies to every later action.
+type Manager struct {
+	escalation string
+	toolName   string
+
+	mutex    sync.Mutex
+	answered bool
+	approved *Tool
+}
+
+// NewManager creates a manager following an escalation strategy, EscalationReexec when
+// empty, with the escalation tool named tool, the first one installed when empty
+func NewManager(escalation, tool string) *Manager {
+	if escalation == "" {
+		escalation = EscalationReexec
+	}
+	return &Manager{escalation: escalation, toolName: tool}
+}
+
+// Escalation returns the escalation strategy of the manager
+func (m *Manager) Escalation() string {
+	return m.escalation
+}
+
+// Escalate returns the tool the commands of an action requiring root run through. The
+// prompt strategy asks for confirmation with confirm, then for the credentials of the
+// user when the tool caches them. An action that cannot be escalated fails with a
+// root required error.
+func (m *Manager) Escalate(action, software, provider string, confirm func(message string) (bool, error)) (*Tool, error) {
+	refused := errors.NewRootRequiredError(action, software, provider)
+
+	switch m.escalation {
+	case EscalationPrepend:
+		tool, err := FindTool(m.toolName)
+		if err != nil {
+			refused.Cause = err
+			return nil, refused
+		}
+		return tool, nil
+
+	case EscalationPrompt:
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		if !m.answered {
+			m.answered = true
+			tool, err := FindTool(m.toolName)
+			if err != nil {
+				refused.Cause = err
+				return nil, refused
+			}
+			confirmed, err := confirm(fmt.Sprintf("%s, run the commands requiring root with %s?", refused.Message, tool.Name))
+			if err != nil || !confirmed {
+				return nil, refused
+			}
+			if err := validate(tool); err != nil {
+				refused.Cause = err
+				return nil, refused
+			}
+			m.approved = tool
+		}
+		if m.approved == nil {
+			return nil, refused
+		}
+		return m.approved, nil
+
+	case EscalationFail:
+		return nil, refused.WithSuggestion("Set privilege.escalation to prepend or prompt to run the commands with sudo")
+	}
+	return nil, refused
+}
+
+// validate asks for the credentials of the user once, so the tool does not prompt in
+// the middle of the output of the commands
+func validate(tool *Tool) error {
+	if tool.Validate == nil {
+		return nil
+	}
+	cmd := exec.Command(tool.Path, tool.Validate...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s refused the credentials: %w", tool.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package privilege
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"testing"
+
+	saierrors "sai/internal/errors"
+)
+
+// installed replaces the tool lookup with the given installed tools
+func installed(t *testing.T, names ...string) {
+	t.Helper()
+	t.Cleanup(func() { lookPath = exec.LookPath })
+	lookPath = func(name string) (string, error) {
+		for _, installed := range names {
+			if name == installed {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+}
+
+func TestFindTool(t *testing.T) {
+	installed(t, "doas", "pkexec")
+
+	if runtime.GOOS != "windows" {
+		if tool, err := FindTool(""); err != nil || tool.Name != "doas" {
+			t.Errorf("FindTool() = %+v, %v", tool, err)
+		}
+	}
+	if tool, err := FindTool("pkexec"); err != nil || tool.Path != "/usr/bin/pkexec" {
+		t.Errorf("FindTool(pkexec) = %+v, %v", tool, err)
+	}
+	if _, err := FindTool("sudo"); err == nil {
+		t.Error("missing sudo found")
+	}
+	if _, err := FindTool("su"); err == nil {
+		t.Error("unknown tool accepted")
+	}
+}
+
+func TestToolWrap(t *testing.T) {
+	sudo := tools["sudo"]
+	sudo.Path = "/usr/bin/sudo"
+	wrapped := sudo.Wrap([]string{"apt-get", "install", "-y", "nginx"}, map[string]string{"DEBIAN_FRONTEND": "noninteractive", "A": "1"})
+	expected := []string{"/usr/bin/sudo", "--", "env", "A=1", "DEBIAN_FRONTEND=noninteractive", "apt-get", "install", "-y", "nginx"}
+	if !reflect.DeepEqual(wrapped, expected) {
+		t.Errorf("sudo wrap = %v, want %v", wrapped, expected)
+	}
+
+	runas := tools["runas"]
+	wrapped = runas.Wrap([]string{"choco", "install", "my app"}, map[string]string{"A": "1"})
+	expected = []string{"runas", "/user:Administrator", `choco install "my app"`}
+	if !reflect.DeepEqual(wrapped, expected) {
+		t.Errorf("runas wrap = %v, want %v", wrapped, expected)
+	}
+}
+
+func TestManagerEscalate(t *testing.T) {
+	installed(t, "doas")
+
+	asked := 0
+	confirm := func(answer bool) func(string) (bool, error) {
+		return func(string) (bool, error) {
+			asked++
+			return answer, nil
+		}
+	}
+
+	// prompt asks once, the answer holds for the later actions
+	manager := NewManager(EscalationPrompt, "doas")
+	for i := 0; i < 2; i++ {
+		tool, err := manager.Escalate("install", "nginx", "apt", confirm(true))
+		if err != nil || tool.Name != "doas" {
+			t.Fatalf("Escalate() = %+v, %v", tool, err)
+		}
+	}
+	if asked != 1 {
+		t.Errorf("asked %d times, want once", asked)
+	}
+
+	asked = 0
+	manager = NewManager(EscalationPrompt, "doas")
+	for i := 0; i < 2; i++ {
+		if _, err := manager.Escalate("install", "nginx", "apt", confirm(false)); !isRootRequired(err) {
+			t.Errorf("refused escalation returned %v", err)
+		}
+	}
+	if asked != 1 {
+		t.Errorf("asked %d times after a refusal, want once", asked)
+	}
+
+	if tool, err := NewManager(EscalationPrepend, "").Escalate("install", "nginx", "apt", confirm(false)); err != nil || tool.Name != "doas" {
+		t.Errorf("prepend = %+v, %v", tool, err)
+	}
+	if _, err := NewManager(EscalationPrepend, "sudo").Escalate("install", "nginx", "apt", nil); !isRootRequired(err) {
+		t.Errorf("prepend without the tool returned %v", err)
+	}
+	for _, escalation := range []string{EscalationFail, EscalationReexec, ""} {
+		if _, err := NewManager(escalation, "").Escalate("install", "nginx", "apt", nil); !isRootRequired(err) {
+			t.Errorf("%q returned %v", escalation, err)
+		}
+	}
+}
+
+func TestToolFrom(t *testing.T) {
+	if ToolFrom(context.Background()) != nil {
+		t.Error("tool without escalation")
+	}
+	tool := tools["doas"]
+	if ToolFrom(WithTool(context.Background(), &tool)) != &tool {
+		t.Error("tool of the context lost")
+	}
+}
+
+// isRootRequired reports whether err is a root required error
+func isRootRequired(err error) bool {
+	var saiErr *saierrors.SAIError
+	return errors.As(err, &saiErr) && saiErr.Type == saierrors.ErrorTypeRootRequired
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package privilege
+
+import "os"
+
+// IsPrivileged returns whether sai runs as root
+func IsPrivileged() bool {
+	return os.Geteuid() == 0
+}
+
+// defaultTools are the escalation tools looked for, in order, when none is configured
+var defaultTools = []string{"sudo", "doas", "pkexec"}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package privilege
+
+import "golang.org/x/sys/windows"
+
+// IsPrivileged returns whether sai runs elevated
+func IsPrivileged() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// defaultTools are the escalation tools looked for, in order, when none is configured
+var defaultTools = []string{"runas"}
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"sai/internal/interfaces"
+	"sai/internal/osinfo"
+	"sai/internal/types"
+)
+
+// WhichProvidersCan reports for every provider, sorted by name, whether it can run an
+// action on a software on this host. Beyond having the action and being available, the
+// provider must not be marked unsupported by the saidata and the compatibility matrix
+// of the saidata must support it on the platform and architecture of this host. A nil
+// saidata only checks the action and the availability.
+func (pm *ProviderManager) WhichProvidersCan(action string, saidata *types.SoftwareData) []interfaces.ProviderCapability {
+	host := pm.detector.GetOSInfo()
+
+	var capabilities []interfaces.ProviderCapability
+	for _, provider := range pm.GetAllProviders() {
+		capability := interfaces.ProviderCapability{
+			Provider:  provider,
+			Available: pm.detector.IsAvailable(provider),
+		}
+		capability.Reason = pm.incapability(provider, action, saidata, host, capability.Available)
+		capability.Capable = capability.Reason == ""
+		capabilities = append(capabilities, capability)
+	}
+	return capabilities
+}
+
+// incapability returns why a provider cannot run an action on a software, "" when it can
+func (pm *ProviderManager) incapability(provider *types.ProviderData, action string, saidata *types.SoftwareData, host *OSInfo, available bool) string {
+	if !pm.detector.SupportsAction(provider, action) {
+		return fmt.Sprintf("no %s action", action)
+	}
+	if !available {
+		if result, exists := pm.detector.GetCachedResult(provider.Provider.Name); exists && result.Error != nil {
+			return result.Error.Error()
+		}
+		return "not available on this host"
+	}
+	if saidata == nil {
+		return ""
+	}
+	if !saidata.IsProviderSupported(provider.Provider.Name) {
+		return "marked unsupported by the saidata on this architecture"
+	}
+	return compatibilityReason(saidata.Compatibility, provider.Provider.Name, host)
+}
+
+// compatibilityReason returns why the compatibility matrix does not support a provider
+// on a host, "" when it does. The first entry of the provider matching the platform,
+// OS, OS version and architecture of the host decides. A provider without entries is
+// not restricted, one whose entries all target other hosts is not supported.
+func compatibilityReason(compatibility *types.Compatibility, provider string, host *OSInfo) string {
+	if compatibility == nil || host == nil {
+		return ""
+	}
+
+	arch := osinfo.NormalizeArchitecture(host.Architecture)
+	declared := false
+	var platforms, architectures []string
+	for i := range compatibility.Matrix {
+		entry := &compatibility.Matrix[i]
+		if !strings.EqualFold(entry.Provider, provider) {
+			continue
+		}
+		declared = true
+		if !matchesHostPlatform(entry, host) {
+			platforms = appendUnique(platforms, entry.GetPlatformsAsStrings()...)
+			continue
+		}
+		if !matchesArchitecture(entry.GetArchitecturesAsStrings(), arch) {
+			architectures = appendUnique(architectures, entry.GetArchitecturesAsStrings()...)
+			continue
+		}
+		if !entry.Supported {
+			reason := fmt.Sprintf("saidata compatibility marks it unsupported on %s/%s", hostName(host), arch)
+			if entry.Notes != "" {
+				reason += ": " + entry.Notes
+			}
+			return reason
+		}
+		return ""
+	}
+
+	switch {
+	case !declared:
+		return ""
+	case len(architectures) > 0:
+		return fmt.Sprintf("saidata compatibility supports it on %s only, not %s", strings.Join(architectures, ", "), arch)
+	default:
+		return fmt.Sprintf("saidata compatibility supports it on %s only, not %s", strings.Join(platforms, ", "), hostName(host))
+	}
+}
+
+// matchesHostPlatform reports whether the platform, OS and OS version of a compatibility
+// entry match a host, an empty field matching any host. Platforms match the platform
+// of the host or its distribution and the ones it derives from, OS versions match the
+// version of the host or its major versions.
+func matchesHostPlatform(entry *types.CompatibilityEntry, host *OSInfo) bool {
+	matchesOS := func(names []string) bool {
+		if len(names) == 0 {
+			return true
+		}
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if name == host.Platform || host.IsLike(name) || (name == "macos" && host.Platform == "darwin") {
+				return true
+			}
+		}
+		return false
+	}
+	if !matchesOS(entry.GetPlatformsAsStrings()) || !matchesOS(entry.GetOSAsStrings()) {
+		return false
+	}
+
+	versions := entry.GetOSVersionsAsStrings()
+	if len(versions) == 0 {
+		return true
+	}
+	for _, version := range versions {
+		if host.Version == version || strings.HasPrefix(host.Version, version+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesArchitecture reports whether an architecture is in a list of architectures
+// under any of their names, an empty list matching any architecture
+func matchesArchitecture(architectures []string, arch string) bool {
+	if len(architectures) == 0 {
+		return true
+	}
+	for _, candidate := range architectures {
+		if osinfo.NormalizeArchitecture(candidate) == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// hostName returns the OS and version of a host as shown in reasons, such as "ubuntu 22.04"
+func hostName(host *OSInfo) string {
+	name := host.OS
+	if name == "" {
+		name = host.Platform
+	}
+	return strings.TrimSpace(name + " " + host.Version)
+}
+
+// appendUnique appends the values missing from a list
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sai/internal/types"
+)
+
+func TestCompatibilityReason(t *testing.T) {
+	compatibility := &types.Compatibility{Matrix: []types.CompatibilityEntry{
+		{Provider: "apt", Platform: []interface{}{"ubuntu", "debian"}, Architecture: []interface{}{"amd64", "arm64"}, Supported: true},
+		{Provider: "apt", Platform: "debian", Architecture: "i386", Supported: false, Notes: "no upstream builds"},
+		{Provider: "dnf", Platform: []string{"fedora", "rhel"}, Supported: true},
+		{Provider: "brew", Platform: "macos", Supported: true},
+		{Provider: "snap", Platform: "linux", OSVersion: "22.04", Supported: true},
+	}}
+	mint := &OSInfo{Platform: "linux", OS: "linuxmint", Version: "21.2", Architecture: "x86_64", IDLike: []string{"ubuntu", "debian"}}
+	debian := &OSInfo{Platform: "linux", OS: "debian", Version: "12", Architecture: "386"}
+	riscv := &OSInfo{Platform: "linux", OS: "ubuntu", Version: "22.04", Architecture: "riscv64"}
+	mac := &OSInfo{Platform: "darwin", OS: "macos", Version: "14.2", Architecture: "arm64"}
+
+	assert.Empty(t, compatibilityReason(compatibility, "apt", mint), "derived distributions match their parents")
+	assert.Equal(t, "saidata compatibility marks it unsupported on debian 12/386: no upstream builds", compatibilityReason(compatibility, "apt", debian))
+	assert.Equal(t, "saidata compatibility supports it on amd64, arm64 only, not riscv64", compatibilityReason(compatibility, "apt", riscv))
+	assert.Equal(t, "saidata compatibility supports it on fedora, rhel only, not linuxmint 21.2", compatibilityReason(compatibility, "dnf", mint))
+	assert.Empty(t, compatibilityReason(compatibility, "brew", mac))
+	assert.Empty(t, compatibilityReason(compatibility, "snap", riscv), "22.04 matches the OS version")
+	assert.NotEmpty(t, compatibilityReason(compatibility, "snap", mint))
+	assert.Empty(t, compatibilityReason(compatibility, "pacman", debian), "providers without entries are not restricted")
+	assert.Empty(t, compatibilityReason(nil, "apt", riscv))
+}
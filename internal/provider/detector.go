@@ -2,40 +2,38 @@ package provider
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
-	"runtime"
 	"strings"
 	"sync"
 	"time"
 
 	"sai/internal/debug"
+	"sai/internal/osinfo"
+	"sai/internal/platform"
 	"sai/internal/types"
 )
 
 // ProviderDetector handles provider availability detection and platform compatibility
 type ProviderDetector struct {
-	platform     string
-	architecture string
-	osInfo       *OSInfo
-	cache        map[string]*DetectionResult
-	cacheMutex   sync.RWMutex
-	cacheExpiry  time.Duration
+	platform          string
+	architecture      string
+	osInfo            *OSInfo
+	wslPrefer         string
+	verifyExecutables bool // Check ownership and permissions of resolved executables
+	executables       map[string]string // Configured executables replacing those of providers
+	cache             map[string]*DetectionResult
+	cacheMutex        sync.RWMutex
+	cacheExpiry       time.Duration
 }
 
 // OSInfo contains detailed operating system information
-type OSInfo struct {
-	Platform     string // "linux", "darwin", "windows"
-	OS           string // "ubuntu", "debian", "centos", "macos", etc.
-	Version      string // "22.04", "8", "13.0", etc.
-	Architecture string // "amd64", "arm64", etc.
-	DetectedAt   time.Time
-}
+type OSInfo = osinfo.OSInfo
 
 // DetectionResult caches the result of provider detection
 type DetectionResult struct {
 	Available   bool
 	Executable  string
+	Path        string // Absolute path the executable was resolved to, pinned for execution
 	Version     string
 	Error       error
 	DetectedAt  time.Time
@@ -44,195 +42,22 @@ type DetectionResult struct {
 // NewProviderDetector creates a new provider detector with OS detection
 func NewProviderDetector() (*ProviderDetector, error) {
 	detector := &ProviderDetector{
-		platform:     runtime.GOOS,
-		architecture: runtime.GOARCH,
-		cache:        make(map[string]*DetectionResult),
-		cacheExpiry:  5 * time.Minute, // Cache results for 5 minutes
+		cache:       make(map[string]*DetectionResult),
+		cacheExpiry: 5 * time.Minute, // Cache results for 5 minutes
 	}
 
 	// Detect OS information
-	osInfo, err := detector.detectOSInfo()
+	osInfo, err := platform.Detect()
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect OS information: %w", err)
 	}
 	detector.osInfo = osInfo
+	detector.platform = osInfo.Platform
+	detector.architecture = osInfo.Architecture
 
 	return detector, nil
 }
 
-// detectOSInfo detects detailed operating system information
-func (pd *ProviderDetector) detectOSInfo() (*OSInfo, error) {
-	osInfo := &OSInfo{
-		Platform:     pd.platform,
-		Architecture: pd.architecture,
-		DetectedAt:   time.Now(),
-	}
-
-	switch pd.platform {
-	case "linux":
-		if err := pd.detectLinuxInfo(osInfo); err != nil {
-			return nil, err
-		}
-	case "darwin":
-		if err := pd.detectMacOSInfo(osInfo); err != nil {
-			return nil, err
-		}
-	case "windows":
-		if err := pd.detectWindowsInfo(osInfo); err != nil {
-			return nil, err
-		}
-	default:
-		return nil, fmt.Errorf("unsupported platform: %s", pd.platform)
-	}
-
-	return osInfo, nil
-}
-
-// detectLinuxInfo detects Linux distribution and version
-func (pd *ProviderDetector) detectLinuxInfo(osInfo *OSInfo) error {
-	// Try /etc/os-release first (most common)
-	if info, err := pd.parseOSRelease("/etc/os-release"); err == nil {
-		osInfo.OS = strings.ToLower(info["ID"])
-		osInfo.Version = info["VERSION_ID"]
-		return nil
-	}
-
-	// Try /etc/lsb-release (Ubuntu/Debian)
-	if info, err := pd.parseOSRelease("/etc/lsb-release"); err == nil {
-		if distrib := info["DISTRIB_ID"]; distrib != "" {
-			osInfo.OS = strings.ToLower(distrib)
-		}
-		if version := info["DISTRIB_RELEASE"]; version != "" {
-			osInfo.Version = version
-		}
-		return nil
-	}
-
-	// Try distribution-specific files
-	distFiles := map[string]string{
-		"/etc/redhat-release": "centos",
-		"/etc/debian_version": "debian",
-		"/etc/alpine-release": "alpine",
-	}
-
-	for file, distro := range distFiles {
-		if _, err := os.Stat(file); err == nil {
-			osInfo.OS = distro
-			if content, err := os.ReadFile(file); err == nil {
-				osInfo.Version = pd.extractVersionFromContent(string(content))
-			}
-			return nil
-		}
-	}
-
-	// Fallback to generic linux
-	osInfo.OS = "linux"
-	osInfo.Version = "unknown"
-	return nil
-}
-
-// detectMacOSInfo detects macOS version information
-func (pd *ProviderDetector) detectMacOSInfo(osInfo *OSInfo) error {
-	osInfo.OS = "macos"
-
-	// Try sw_vers command
-	if cmd := exec.Command("sw_vers", "-productVersion"); cmd != nil {
-		if output, err := cmd.Output(); err == nil {
-			version := strings.TrimSpace(string(output))
-			// Extract major version (e.g., "13.0.1" -> "13")
-			if parts := strings.Split(version, "."); len(parts) > 0 {
-				osInfo.Version = parts[0]
-			}
-			return nil
-		}
-	}
-
-	// Fallback to system version
-	osInfo.Version = "unknown"
-	return nil
-}
-
-// detectWindowsInfo detects Windows version information
-func (pd *ProviderDetector) detectWindowsInfo(osInfo *OSInfo) error {
-	osInfo.OS = "windows"
-
-	// Try wmic command for version detection
-	if cmd := exec.Command("wmic", "os", "get", "Version", "/value"); cmd != nil {
-		if output, err := cmd.Output(); err == nil {
-			lines := strings.Split(string(output), "\n")
-			for _, line := range lines {
-				if strings.HasPrefix(line, "Version=") {
-					version := strings.TrimPrefix(line, "Version=")
-					version = strings.TrimSpace(version)
-					// Extract major version (e.g., "10.0.19041" -> "10")
-					if parts := strings.Split(version, "."); len(parts) > 0 {
-						osInfo.Version = parts[0]
-					}
-					return nil
-				}
-			}
-		}
-	}
-
-	// Fallback
-	osInfo.Version = "unknown"
-	return nil
-}
-
-// parseOSRelease parses /etc/os-release or /etc/lsb-release files
-func (pd *ProviderDetector) parseOSRelease(filename string) (map[string]string, error) {
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
-
-	result := make(map[string]string)
-	lines := strings.Split(string(content), "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		
-		// Remove quotes
-		value = strings.Trim(value, `"'`)
-		
-		result[key] = value
-	}
-
-	return result, nil
-}
-
-// extractVersionFromContent extracts version number from file content
-func (pd *ProviderDetector) extractVersionFromContent(content string) string {
-	content = strings.TrimSpace(content)
-	
-	// Look for version patterns like "8.4", "20.04", etc.
-	parts := strings.Fields(content)
-	for _, part := range parts {
-		if strings.Contains(part, ".") && len(part) <= 10 {
-			// Simple heuristic for version-like strings
-			return part
-		}
-	}
-	
-	// Extract first word as fallback
-	if len(parts) > 0 {
-		return parts[0]
-	}
-	
-	return "unknown"
-}
-
 // IsAvailable checks if a provider is available on the current system
 func (pd *ProviderDetector) IsAvailable(provider *types.ProviderData) bool {
 	return pd.IsAvailableWithDebug(provider, false)
@@ -296,30 +121,58 @@ func (pd *ProviderDetector) detectProvider(provider *types.ProviderData) *Detect
 	}
 
 	// Check executable availability - this is the critical fix for Requirement 13.2
-	if provider.Provider.Executable != "" {
-		if pd.CheckExecutable(provider.Provider.Executable) {
+	if override, exists := pd.executables[provider.Provider.Name]; exists {
+		path, err := pd.pinExecutable(override)
+		if err != nil {
+			result.Error = fmt.Errorf("configured executable of provider %s: %w", provider.Provider.Name, err)
+			return result
+		}
+		result.Available = true
+		result.Executable = override
+		result.Path = path
+		if version := pd.getExecutableVersion(path); version != "" {
+			result.Version = version
+		}
+	} else if provider.Provider.Executable != "" {
+		if executable, found := pd.findExecutableOrAlternative(provider); found {
+			path, err := pd.pinExecutable(executable)
+			if err != nil {
+				result.Error = err
+				return result
+			}
 			result.Available = true
-			result.Executable = provider.Provider.Executable
+			result.Executable = executable
+			result.Path = path
 			
 			// Try to get version if possible
-			if version := pd.getExecutableVersion(provider.Provider.Executable); version != "" {
+			if version := pd.getExecutableVersion(path); version != "" {
 				result.Version = version
 			}
 		} else {
 			// Provider is not available because executable is missing
 			result.Available = false
 			result.Error = fmt.Errorf("executable '%s' not found in PATH", provider.Provider.Executable)
+			if len(provider.Provider.Alternatives) > 0 {
+				result.Error = fmt.Errorf("none of the executables %s found in PATH",
+					strings.Join(append([]string{provider.Provider.Executable}, provider.Provider.Alternatives...), ", "))
+			}
 			return result
 		}
 	} else {
 		// If no executable specified, check if provider name itself is an executable
 		// This handles cases where provider name matches the executable (like 'docker', 'brew')
-		if pd.CheckExecutable(provider.Provider.Name) {
+		if executable, found := pd.findProviderExecutable(provider, provider.Provider.Name); found {
+			path, err := pd.pinExecutable(executable)
+			if err != nil {
+				result.Error = err
+				return result
+			}
 			result.Available = true
-			result.Executable = provider.Provider.Name
+			result.Executable = executable
+			result.Path = path
 			
 			// Try to get version
-			if version := pd.getExecutableVersion(provider.Provider.Name); version != "" {
+			if version := pd.getExecutableVersion(path); version != "" {
 				result.Version = version
 			}
 		} else {
@@ -334,8 +187,14 @@ func (pd *ProviderDetector) detectProvider(provider *types.ProviderData) *Detect
 	return result
 }
 
-// isPlatformCompatible checks if the provider is compatible with the current platform
+// isPlatformCompatible checks if the provider is compatible with the current platform.
+// Under WSL, Windows providers are compatible too since they are reachable via interop.
 func (pd *ProviderDetector) isPlatformCompatible(provider *types.ProviderData) bool {
+	return pd.matchesPlatform(provider) || pd.isWSLInterop(provider)
+}
+
+// matchesPlatform checks if the provider supports the current platform or OS natively
+func (pd *ProviderDetector) matchesPlatform(provider *types.ProviderData) bool {
 	if len(provider.Provider.Platforms) == 0 {
 		// No platform restrictions
 		return true
@@ -355,6 +214,80 @@ func (pd *ProviderDetector) isPlatformCompatible(provider *types.ProviderData) b
 	return false
 }
 
+// isWSLInterop reports whether the provider is a Windows provider that can only run
+// through WSL interop
+func (pd *ProviderDetector) isWSLInterop(provider *types.ProviderData) bool {
+	if !pd.osInfo.WSL || pd.matchesPlatform(provider) {
+		return false
+	}
+	for _, platform := range provider.Provider.Platforms {
+		if platform == "windows" {
+			return true
+		}
+	}
+	return false
+}
+
+// findProviderExecutable looks up a provider executable. Windows executables reached
+// through WSL interop keep their extension, so those are tried as well.
+func (pd *ProviderDetector) findProviderExecutable(provider *types.ProviderData, executable string) (string, bool) {
+	if !pd.isWSLInterop(provider) {
+		return executable, pd.CheckExecutable(executable)
+	}
+
+	for _, extension := range wslInteropExtensions {
+		if pd.CheckExecutable(executable + extension) {
+			return executable + extension, true
+		}
+	}
+	return "", false
+}
+
+// findExecutableOrAlternative looks up the executable of a provider, or else the first
+// of its alternatives found, such as podman or nerdctl for docker. Commands of the
+// provider then run the alternative in place of the executable.
+func (pd *ProviderDetector) findExecutableOrAlternative(provider *types.ProviderData) (string, bool) {
+	if executable, found := pd.findProviderExecutable(provider, provider.Provider.Executable); found {
+		return executable, true
+	}
+	for _, alternative := range provider.Provider.Alternatives {
+		if executable, found := pd.findProviderExecutable(provider, alternative); found {
+			return executable, true
+		}
+	}
+	return "", false
+}
+
+// pinExecutable resolves a found executable to the absolute path its commands run,
+// verifying it when enabled
+func (pd *ProviderDetector) pinExecutable(executable string) (string, error) {
+	path, err := resolveExecutable(executable)
+	if err != nil {
+		return "", err
+	}
+	if pd.verifyExecutables {
+		if err := verifyExecutable(path); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+// wslInteropExtensions are the extensions of Windows executables reachable from WSL
+var wslInteropExtensions = []string{".exe", ".cmd", ".bat"}
+
+// SetWSLPreference selects which providers are preferred under WSL: "linux" (default)
+// for Linux-native providers or "windows" for Windows providers reached through interop
+func (pd *ProviderDetector) SetWSLPreference(prefer string) {
+	pd.wslPrefer = prefer
+	pd.ClearCache()
+}
+
+// IsWSL reports whether sai runs under Windows Subsystem for Linux
+func (pd *ProviderDetector) IsWSL() bool {
+	return pd.osInfo.WSL
+}
+
 // CheckExecutable checks if an executable is available in PATH
 func (pd *ProviderDetector) CheckExecutable(executable string) bool {
 	return pd.CheckExecutableWithTimeout(executable, 5*time.Second)
@@ -368,13 +301,13 @@ func (pd *ProviderDetector) CheckExecutableWithTimeout(executable string, timeou
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				// If there's a panic in exec.LookPath, treat as not found
+				// If there's a panic in the lookup, treat as not found
 				result = false
 			}
 			done <- true
 		}()
 		
-		_, err := exec.LookPath(executable)
+		_, err := platform.LookPath(executable)
 		result = err == nil
 	}()
 	
@@ -409,6 +342,11 @@ func (pd *ProviderDetector) getExecutableVersion(executable string) string {
 
 // getExecutableVersionWithTimeout attempts to get version information with a timeout
 func (pd *ProviderDetector) getExecutableVersionWithTimeout(executable string, timeout time.Duration) string {
+	// Faked executables from a test fixture cannot be run
+	if platform.FakesExecutables() {
+		return ""
+	}
+
 	// Common version flags to try
 	versionFlags := []string{"--version", "-version", "-V", "-v"}
 	
@@ -472,6 +410,14 @@ func (pd *ProviderDetector) GetProviderPriority(provider *types.ProviderData) in
 		basePriority = 50 // Default priority
 	}
 
+	// Under WSL, Windows providers rank above or below Linux-native ones by preference
+	if pd.isWSLInterop(provider) {
+		if pd.wslPrefer == "windows" {
+			return basePriority + 30
+		}
+		return basePriority - 20
+	}
+
 	// Boost priority for exact platform matches
 	for _, platform := range provider.Provider.Platforms {
 		if platform == pd.osInfo.OS {
@@ -539,11 +485,13 @@ func (pd *ProviderDetector) SetCacheExpiry(duration time.Duration) {
 
 // RefreshOSInfo re-detects OS information (useful for testing or dynamic environments)
 func (pd *ProviderDetector) RefreshOSInfo() error {
-	osInfo, err := pd.detectOSInfo()
+	osInfo, err := platform.Detect()
 	if err != nil {
 		return err
 	}
 	pd.osInfo = osInfo
+	pd.platform = osInfo.Platform
+	pd.architecture = osInfo.Architecture
 	
 	// Clear cache since OS info changed
 	pd.ClearCache()
@@ -622,7 +570,7 @@ func (pd *ProviderDetector) GetDetectionStats(providers []*types.ProviderData) *
 		hasExecutable := provider.Provider.Executable != ""
 		executableFound := false
 		if hasExecutable {
-			executableFound = pd.CheckExecutable(provider.Provider.Executable)
+			_, executableFound = pd.findProviderExecutable(provider, provider.Provider.Executable)
 			if executableFound {
 				stats.ExecutableFound++
 			} else {
@@ -1,7 +1,6 @@
 package provider
 
 import (
-	"os"
 	"runtime"
 	"testing"
 	"time"
@@ -283,81 +282,6 @@ func TestProviderDetector_Cache(t *testing.T) {
 	assert.False(t, exists)
 }
 
-func TestProviderDetector_parseOSRelease(t *testing.T) {
-	detector, err := NewProviderDetector()
-	require.NoError(t, err)
-
-	// Create a temporary os-release file
-	tempFile := t.TempDir() + "/os-release"
-	content := `NAME="Ubuntu"
-VERSION="22.04.1 LTS (Jammy Jellyfish)"
-ID=ubuntu
-ID_LIKE=debian
-PRETTY_NAME="Ubuntu 22.04.1 LTS"
-VERSION_ID="22.04"
-HOME_URL="https://www.ubuntu.com/"
-SUPPORT_URL="https://help.ubuntu.com/"
-BUG_REPORT_URL="https://bugs.launchpad.net/ubuntu/"
-PRIVACY_POLICY_URL="https://www.ubuntu.com/legal/terms-and-policies/privacy-policy"
-VERSION_CODENAME=jammy
-UBUNTU_CODENAME=jammy`
-
-	err = os.WriteFile(tempFile, []byte(content), 0644)
-	require.NoError(t, err)
-
-	result, err := detector.parseOSRelease(tempFile)
-	require.NoError(t, err)
-
-	assert.Equal(t, "Ubuntu", result["NAME"])
-	assert.Equal(t, "ubuntu", result["ID"])
-	assert.Equal(t, "22.04", result["VERSION_ID"])
-	assert.Equal(t, "jammy", result["VERSION_CODENAME"])
-}
-
-func TestProviderDetector_extractVersionFromContent(t *testing.T) {
-	detector, err := NewProviderDetector()
-	require.NoError(t, err)
-
-	tests := []struct {
-		name     string
-		content  string
-		expected string
-	}{
-		{
-			name:     "simple version",
-			content:  "8.4",
-			expected: "8.4",
-		},
-		{
-			name:     "version with text",
-			content:  "CentOS Linux release 8.4.2105 (Core)",
-			expected: "8.4.2105",
-		},
-		{
-			name:     "ubuntu version",
-			content:  "22.04",
-			expected: "22.04",
-		},
-		{
-			name:     "no version",
-			content:  "some text without version",
-			expected: "some",
-		},
-		{
-			name:     "empty content",
-			content:  "",
-			expected: "unknown",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := detector.extractVersionFromContent(tt.content)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestProviderDetector_RefreshOSInfo(t *testing.T) {
 	detector, err := NewProviderDetector()
 	require.NoError(t, err)
@@ -377,6 +301,38 @@ func TestProviderDetector_RefreshOSInfo(t *testing.T) {
 		newOSInfo.DetectedAt.Equal(originalOSInfo.DetectedAt))
 }
 
+func TestProviderDetector_TestFixture(t *testing.T) {
+	t.Setenv("SAI_TEST_OS", "macos")
+	t.Setenv("SAI_TEST_OS_VERSION", "14")
+	t.Setenv("SAI_TEST_EXECUTABLES", "brew")
+
+	detector, err := NewProviderDetector()
+	require.NoError(t, err)
+
+	assert.Equal(t, "darwin", detector.GetPlatform())
+	assert.Equal(t, "macos", detector.GetOSInfo().OS)
+	assert.Equal(t, "14", detector.GetOSInfo().Version)
+
+	brew := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name:       "brew",
+			Executable: "brew",
+			Platforms:  []string{"darwin"},
+		},
+	}
+	apt := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name:       "apt",
+			Executable: "apt-get",
+			Platforms:  []string{"linux"},
+		},
+	}
+
+	assert.True(t, detector.IsAvailable(brew))
+	assert.False(t, detector.IsAvailable(apt))
+	assert.False(t, detector.CheckExecutable("apt-get"))
+}
+
 func TestProviderDetector_ExecutableBasedDetection(t *testing.T) {
 	detector, err := NewProviderDetector()
 	require.NoError(t, err)
@@ -548,4 +504,61 @@ func getExistingExecutable() string {
 	default:
 		return "sh"
 	}
-}
\ No newline at end of file
+}
+
+func TestProviderDetector_WSLInterop(t *testing.T) {
+	t.Setenv("SAI_TEST_OS", "ubuntu")
+	t.Setenv("SAI_TEST_EXECUTABLES", "apt-get,winget.exe")
+
+	detector, err := NewProviderDetector()
+	require.NoError(t, err)
+
+	apt := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "apt", Executable: "apt-get", Platforms: []string{"linux"}},
+	}
+	winget := &types.ProviderData{
+		Provider: types.ProviderInfo{Name: "winget", Executable: "winget", Platforms: []string{"windows"}},
+	}
+
+	// Outside WSL Windows providers are not compatible
+	assert.False(t, detector.IsAvailable(winget))
+
+	detector.osInfo.WSL = true
+	detector.ClearCache()
+	assert.True(t, detector.IsAvailable(winget))
+	result, ok := detector.GetCachedResult("winget")
+	require.True(t, ok)
+	assert.Equal(t, "winget.exe", result.Executable)
+
+	// Linux-native providers are preferred unless Windows is requested
+	assert.Greater(t, detector.GetProviderPriority(apt), detector.GetProviderPriority(winget))
+	detector.SetWSLPreference("windows")
+	assert.Greater(t, detector.GetProviderPriority(winget), detector.GetProviderPriority(apt))
+}
+
+func TestProviderDetector_Alternatives(t *testing.T) {
+	t.Setenv("SAI_TEST_OS", "ubuntu")
+	t.Setenv("SAI_TEST_EXECUTABLES", "podman,nerdctl")
+
+	detector, err := NewProviderDetector()
+	require.NoError(t, err)
+
+	docker := &types.ProviderData{
+		Provider: types.ProviderInfo{
+			Name:         "docker",
+			Executable:   "docker",
+			Alternatives: []string{"podman", "nerdctl"},
+			Platforms:    []string{"linux"},
+		},
+	}
+	assert.True(t, detector.IsAvailable(docker))
+	result, ok := detector.GetCachedResult("docker")
+	require.True(t, ok)
+	assert.Equal(t, "podman", result.Executable, "the first alternative found runs the docker commands")
+
+	t.Setenv("SAI_TEST_EXECUTABLES", "ctr")
+	detector.ClearCache()
+	assert.False(t, detector.IsAvailable(docker))
+	result, _ = detector.GetCachedResult("docker")
+	assert.EqualError(t, result.Error, "none of the executables docker, podman, nerdctl found in PATH")
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"sai/internal/platform"
+	"sai/internal/types"
+)
+
+// resolveExecutable resolves an executable found in PATH to the absolute path commands
+// are pinned to. Executables found relative to the current directory, through an empty
+// or "." PATH entry, are refused: a malicious ./apt must never run in place of apt.
+func resolveExecutable(executable string) (string, error) {
+	path, err := platform.LookPath(executable)
+	if errors.Is(err, exec.ErrDot) || (err == nil && !filepath.IsAbs(path)) {
+		return "", fmt.Errorf("refusing executable '%s' found relative to the current directory", executable)
+	}
+	if err != nil {
+		return "", fmt.Errorf("executable '%s' not found in PATH", executable)
+	}
+	return path, nil
+}
+
+// verifyExecutable checks that a resolved executable can only have been installed by
+// root or the current user: the binary must be owned by one of them and not be
+// writable by group or others, and its directory must not be writable by others
+// unless it is sticky.
+func verifyExecutable(path string) error {
+	// Faked executables of a test fixture do not exist
+	if platform.FakesExecutables() {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to verify executable %s: %w", path, err)
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("executable %s is writable by group or others (%s)", path, info.Mode().Perm())
+	}
+	if uid, ok := fileOwner(info); ok && uid != 0 && uid != os.Geteuid() {
+		return fmt.Errorf("executable %s is owned by uid %d, neither root nor the current user", path, uid)
+	}
+
+	dir := filepath.Dir(path)
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to verify directory %s: %w", dir, err)
+	}
+	if dirInfo.Mode().Perm()&0002 != 0 && dirInfo.Mode()&os.ModeSticky == 0 {
+		return fmt.Errorf("directory %s of executable %s is writable by others", dir, filepath.Base(path))
+	}
+	return nil
+}
+
+// SetExecutableVerification enables the ownership and permission checks of resolved
+// provider executables. Providers failing them are reported as unavailable.
+func (pd *ProviderDetector) SetExecutableVerification(enabled bool) {
+	pd.verifyExecutables = enabled
+	pd.ClearCache()
+}
+
+// SetExecutableOverrides replaces the executables of providers, by provider name, with
+// configured names or absolute paths. Overridden providers are available when their
+// configured executable is found, and their commands run it.
+func (pd *ProviderDetector) SetExecutableOverrides(executables map[string]string) {
+	pd.executables = executables
+	pd.ClearCache()
+}
+
+// ExecutablePath returns the absolute path the executable of an available provider was
+// resolved to at detection, "" for providers without executable
+func (pd *ProviderDetector) ExecutablePath(provider *types.ProviderData) string {
+	if !pd.IsAvailable(provider) {
+		return ""
+	}
+	if result, exists := pd.GetCachedResult(provider.Provider.Name); exists {
+		return result.Path
+	}
+	return ""
+}
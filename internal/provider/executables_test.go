@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executables on Windows")
+	}
+
+	binDir := t.TempDir()
+	workDir := t.TempDir()
+	for _, dir := range []string{binDir, workDir} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "saitool"), []byte("#!/bin/sh\n"), 0755))
+	}
+
+	t.Setenv("PATH", binDir)
+	path, err := resolveExecutable("saitool")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(binDir, "saitool"), path)
+
+	// An executable reached through a "." PATH entry is refused
+	t.Chdir(workDir)
+	t.Setenv("PATH", ".")
+	_, err = resolveExecutable("saitool")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "current directory")
+
+	_, err = resolveExecutable("sai-missing-tool")
+	assert.Error(t, err)
+}
+
+func TestVerifyExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on Windows")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0755))
+	executable := filepath.Join(dir, "saitool")
+	require.NoError(t, os.WriteFile(executable, []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, os.Chmod(executable, 0755))
+	assert.NoError(t, verifyExecutable(executable))
+
+	require.NoError(t, os.Chmod(executable, 0775))
+	assert.Error(t, verifyExecutable(executable), "group writable executable")
+	require.NoError(t, os.Chmod(executable, 0755))
+
+	require.NoError(t, os.Chmod(dir, 0777))
+	assert.Error(t, verifyExecutable(executable), "world writable directory")
+
+	require.NoError(t, os.Chmod(dir, 0777|os.ModeSticky))
+	assert.NoError(t, verifyExecutable(executable), "sticky directory like /tmp")
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package provider
+
+import "os"
+
+// fileOwner reports no owner: Windows files are owned by SIDs, not uids
+func fileOwner(info os.FileInfo) (int, bool) {
+	return 0, false
+}
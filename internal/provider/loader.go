@@ -5,15 +5,31 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/xeipuuv/gojsonschema"
 
+	"sai/internal/interfaces"
 	"sai/internal/types"
 )
 
+// registerNamePattern matches step register names, usable as {{.Registered.<name>}}
+var registerNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// isRuntimeName reports whether name is a runtime context field steps capture into,
+// rather than a custom name stored in the runtime context values
+func isRuntimeName(name string) bool {
+	switch name {
+	case interfaces.RuntimeSelectedPackage, interfaces.RuntimePackageIndex, interfaces.RuntimeResolvedURL, interfaces.RuntimeExtractDir:
+		return true
+	}
+	return false
+}
+
 // ProviderLoader implements the provider loading functionality
 type ProviderLoader struct {
 	schemaPath   string
@@ -177,9 +193,48 @@ func (pl *ProviderLoader) validateProviderLogic(provider *types.ProviderData) er
 		// Validate steps if present
 		if action.HasSteps() {
 			for i, step := range action.Steps {
-				if step.Command == "" {
+				if step.Command == "" && !step.IsWindowsPrimitive() {
 					return fmt.Errorf("action %s step %d: command cannot be empty", actionName, i)
 				}
+				if step.Command != "" && step.IsWindowsPrimitive() {
+					return fmt.Errorf("action %s step %d: use either command or a Windows primitive", actionName, i)
+				}
+				if step.Register != "" && !registerNamePattern.MatchString(step.Register) {
+					return fmt.Errorf("action %s step %d: register name %q must start with a letter or underscore and contain only letters, digits and underscores", actionName, i, step.Register)
+				}
+				if isRuntimeName(step.Register) {
+					return fmt.Errorf("action %s step %d: register name %q is a runtime context name, use capture to set it", actionName, i, step.Register)
+				}
+			}
+		}
+		for i, download := range action.Downloads {
+			if download.URL == "" || download.Path == "" {
+				return fmt.Errorf("action %s download %d: url and path are required", actionName, i)
+			}
+			if download.Mode != "" {
+				if _, err := strconv.ParseUint(download.Mode, 8, 32); err != nil {
+					return fmt.Errorf("action %s download %d: mode %q must be octal, such as 0755", actionName, i, download.Mode)
+				}
+			}
+		}
+		for i, checkout := range action.Checkouts {
+			if checkout.Repository == "" || checkout.Path == "" {
+				return fmt.Errorf("action %s checkout %d: repository and path are required", actionName, i)
+			}
+		}
+		if action.MaxParallel < 0 {
+			return fmt.Errorf("action %s: max_parallel cannot be negative", actionName)
+		}
+
+		// Validate inputs
+		for i, input := range action.Inputs {
+			if input.Name == "" {
+				return fmt.Errorf("action %s input %d: name cannot be empty", actionName, i)
+			}
+			switch input.GetType() {
+			case types.InputTypeString, types.InputTypeBoolean, types.InputTypeNumber:
+			default:
+				return fmt.Errorf("action %s input %s: type must be 'string', 'boolean' or 'number'", actionName, input.Name)
 			}
 		}
 
@@ -198,8 +253,8 @@ func (pl *ProviderLoader) validateProviderLogic(provider *types.ProviderData) er
 
 		// Validate validation configuration
 		if action.Validation != nil {
-			if action.Validation.Command == "" {
-				return fmt.Errorf("action %s: validation command cannot be empty", actionName)
+			if action.Validation.Command == "" && action.Validation.Condition == "" {
+				return fmt.Errorf("action %s: validation command and condition cannot both be empty", actionName)
 			}
 			if action.Validation.Timeout < 0 {
 				return fmt.Errorf("action %s: validation timeout cannot be negative", actionName)
@@ -363,9 +363,7 @@ func TestProviderLoader_LoadExistingProviders(t *testing.T) {
 		
 		// Verify actions have execution methods
 		for actionName, action := range provider.Actions {
-			hasExecutionMethod := action.Template != "" || action.Command != "" || 
-								action.Script != "" || len(action.Steps) > 0
-			assert.True(t, hasExecutionMethod, 
+			assert.True(t, action.IsValid(), 
 				"Action %s in provider %s should have at least one execution method", 
 				actionName, provider.Provider.Name)
 		}
@@ -23,10 +23,14 @@ type ProviderManager struct {
 // ManagerConfig contains configuration for the provider manager
 type ManagerConfig struct {
 	ProviderDirectory string
+	PluginDirectories []string // Third-party providers overriding the bundled ones, in increasing precedence
 	SchemaPath        string
 	DefaultProvider   string
 	ProviderPriority  map[string]int
 	EnableWatching    bool
+	WSLPrefer         string // "linux" or "windows"; which providers win under WSL
+	VerifyExecutables bool   // Refuse provider executables writable by other users
+	Executables       map[string]string // Executable replacing the one of a provider, by provider name
 }
 
 // ProviderSelection represents a provider option for user selection
@@ -53,6 +57,9 @@ func NewProviderManager(config *ManagerConfig) (*ProviderManager, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create provider detector: %w", err)
 	}
+	detector.SetWSLPreference(config.WSLPrefer)
+	detector.SetExecutableVerification(config.VerifyExecutables)
+	detector.SetExecutableOverrides(config.Executables)
 
 	manager := &ProviderManager{
 		loader:    loader,
@@ -100,6 +107,11 @@ func (pm *ProviderManager) LoadProviders(providerDir string) error {
 		pm.providers[provider.Provider.Name] = provider
 	}
 
+	// Third-party providers take precedence over the bundled ones
+	for _, pluginErr := range pm.loadPluginProviders(pm.providers, pm.config.PluginDirectories) {
+		fmt.Printf("Warning: %v\n", pluginErr)
+	}
+
 	return nil
 }
 
@@ -179,6 +191,43 @@ func (pm *ProviderManager) GetAllProviders() []*types.ProviderData {
 	return all
 }
 
+// GetOSInfo returns the operating system providers are detected on
+func (pm *ProviderManager) GetOSInfo() *OSInfo {
+	return pm.detector.GetOSInfo()
+}
+
+// DefaultPackageManager returns the package manager provider actions use by default:
+// the configured default provider when it is an available package manager, the
+// available package manager with the highest priority otherwise, and empty when none
+// is available
+func (pm *ProviderManager) DefaultPackageManager() string {
+	var best string
+	for _, provider := range pm.GetAvailableProviders() {
+		if provider.Provider.Type != "package_manager" {
+			continue
+		}
+		if provider.Provider.Name == pm.config.DefaultProvider {
+			return provider.Provider.Name
+		}
+		if best == "" {
+			best = provider.Provider.Name
+		}
+	}
+	return best
+}
+
+// GetPlatformProviders returns the providers compatible with the platform of this host,
+// available or not, sorted by name
+func (pm *ProviderManager) GetPlatformProviders() []*types.ProviderData {
+	var compatible []*types.ProviderData
+	for _, provider := range pm.GetAllProviders() {
+		if pm.detector.isPlatformCompatible(provider) {
+			compatible = append(compatible, provider)
+		}
+	}
+	return compatible
+}
+
 // SelectProvider selects the best provider for a software and action
 func (pm *ProviderManager) SelectProvider(software string, action string, preferredProvider string) (*types.ProviderData, error) {
 	// If a preferred provider is specified, try to use it
@@ -238,6 +287,12 @@ func (pm *ProviderManager) IsProviderAvailable(name string) bool {
 	return pm.detector.IsAvailable(provider)
 }
 
+// GetExecutablePath returns the absolute path the executable of a provider was resolved
+// to at detection, "" when the provider is unavailable or has no executable
+func (pm *ProviderManager) GetExecutablePath(provider *types.ProviderData) string {
+	return pm.detector.ExecutablePath(provider)
+}
+
 // GetProvidersForAction returns providers that support a specific action
 func (pm *ProviderManager) GetProvidersForAction(action string) []*types.ProviderData {
 	pm.mutex.RLock()
@@ -260,6 +315,8 @@ func (pm *ProviderManager) ValidateProvider(provider *types.ProviderData) error
 
 // ReloadProviders reloads all providers (useful for development)
 func (pm *ProviderManager) ReloadProviders() error {
+	// Clear cached detection results so newly installed providers are detected
+	pm.detector.ClearCache()
 	return pm.LoadProviders(pm.config.ProviderDirectory)
 }
 
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"sai/internal/types"
+)
+
+// DefaultPluginDirectories returns the directories third-party providers are loaded
+// from, in increasing precedence: system-wide providers, then the user's
+func DefaultPluginDirectories() []string {
+	dirs := []string{"/etc/sai/providers.d"}
+	if runtime.GOOS == "windows" {
+		dirs = []string{filepath.Join("C:", "ProgramData", "sai", "providers.d")}
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(homeDir, ".sai", "providers.d"))
+	}
+	return dirs
+}
+
+// loadPluginProviders adds the third-party providers of dirs to providers. A provider
+// of a later directory replaces the bundled or earlier provider with the same name.
+// Files of one directory defining the same provider conflict: the first file in
+// lexical order is kept and the conflict is reported. Missing directories are skipped.
+func (pm *ProviderManager) loadPluginProviders(providers map[string]*types.ProviderData, dirs []string) []error {
+	var errs []error
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to read provider directory %s: %w", dir, err))
+			}
+			continue
+		}
+
+		var files []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || (!strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml")) {
+				continue
+			}
+			files = append(files, filepath.Join(dir, name))
+		}
+		sort.Strings(files)
+
+		loaded := make(map[string]string) // Provider name to the file defining it
+		for _, file := range files {
+			provider, err := pm.loader.LoadFromFile(file)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			name := provider.Provider.Name
+			if previous, exists := loaded[name]; exists {
+				errs = append(errs, fmt.Errorf("provider %s is defined by both %s and %s, ignoring %s", name, previous, file, file))
+				continue
+			}
+			loaded[name] = file
+			providers[name] = provider
+		}
+	}
+
+	return errs
+}
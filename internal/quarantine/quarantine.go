@@ -0,0 +1,55 @@
+// Package quarantine handles the macOS quarantine attribute that Gatekeeper checks on
+// downloaded files, along with the code signature and notarization checks that
+// must pass before the attribute is removed.
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Attribute is the extended attribute set on downloaded files
+const Attribute = "com.apple.quarantine"
+
+// Runner runs a command, through the command executor of sai so it is audited,
+// sandboxed and refused in read-only mode, and returns its output
+type Runner func(ctx context.Context, args ...string) (string, error)
+
+// IsQuarantined reports whether a file carries the quarantine attribute
+func IsQuarantined(ctx context.Context, path string, run Runner) bool {
+	_, err := run(ctx, "xattr", "-p", Attribute, path)
+	return err == nil
+}
+
+// Clear removes the quarantine attribute, recursively for application bundles and
+// directories
+func Clear(ctx context.Context, path string, run Runner) error {
+	args := []string{"xattr", "-d", Attribute, path}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		args = []string{"xattr", "-r", "-d", Attribute, path}
+	}
+
+	if output, err := run(ctx, args...); err != nil {
+		return fmt.Errorf("failed to clear quarantine of %s: %w: %s", path, err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// CheckSignature verifies the code signature of a binary or bundle with codesign
+func CheckSignature(ctx context.Context, path string, run Runner) error {
+	if output, err := run(ctx, "codesign", "--verify", "--strict", path); err != nil {
+		return fmt.Errorf("code signature of %s is not valid: %s", path, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// CheckNotarization asks Gatekeeper whether it would allow the binary or bundle to
+// run once the quarantine attribute is removed
+func CheckNotarization(ctx context.Context, path string, run Runner) error {
+	if output, err := run(ctx, "spctl", "--assess", "--type", "execute", path); err != nil {
+		return fmt.Errorf("Gatekeeper rejects %s: %s", path, strings.TrimSpace(output))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package saidata
+
+import (
+	"sort"
+
+	"sai/internal/osinfo"
+	"sai/internal/types"
+)
+
+// normalizeArchitecture returns the Go name of an architecture
+func normalizeArchitecture(arch string) string {
+	return osinfo.NormalizeArchitecture(arch)
+}
+
+// architectureNames returns the names an architecture override file may have, the Go
+// name of the architecture first and then its aliases in lexical order
+func architectureNames(arch string) []string {
+	arch = normalizeArchitecture(arch)
+	if arch == "" {
+		return nil
+	}
+	var aliases []string
+	for alias, name := range osinfo.ArchitectureAliases {
+		if name == arch {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return append([]string{arch}, aliases...)
+}
+
+// resolveArchitecture merges the architecture overrides of each provider config that
+// match arch into the config. The overrides are dropped once resolved, so the result
+// describes the software on this architecture only.
+func resolveArchitecture(saidata *types.SoftwareData, arch string) *types.SoftwareData {
+	if saidata == nil || len(saidata.Providers) == 0 {
+		return saidata
+	}
+
+	arch = normalizeArchitecture(arch)
+	providers := make(map[string]types.ProviderConfig, len(saidata.Providers))
+	for name, config := range saidata.Providers {
+		overrides := config.Architectures
+		config.Architectures = nil
+
+		// Sorted, for aliases of the same architecture to merge in a stable order
+		keys := make([]string, 0, len(overrides))
+		for key := range overrides {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if normalizeArchitecture(key) == arch {
+				config = mergeProviderConfig(config, overrides[key])
+			}
+		}
+		providers[name] = config
+	}
+
+	result := *saidata
+	result.Providers = providers
+	return &result
+}
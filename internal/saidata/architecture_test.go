@@ -0,0 +1,78 @@
+package saidata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/platform"
+	"sai/internal/types"
+)
+
+func TestResolveArchitecture(t *testing.T) {
+	saidata := &types.SoftwareData{
+		Providers: map[string]types.ProviderConfig{
+			"apt": {
+				Packages: []types.Package{{Name: "app", PackageName: "app-x64"}},
+				Architectures: map[string]types.ProviderConfig{
+					"aarch64": {Packages: []types.Package{{Name: "app", PackageName: "app-arm64"}}},
+				},
+			},
+			"snap": {
+				Architectures: map[string]types.ProviderConfig{
+					"arm64": {Unsupported: true},
+				},
+			},
+		},
+	}
+
+	arm := resolveArchitecture(saidata, "arm64")
+	assert.Equal(t, "app-arm64", arm.Providers["apt"].Packages[0].PackageName)
+	assert.Nil(t, arm.Providers["apt"].Architectures)
+	assert.False(t, arm.IsProviderSupported("snap"))
+	assert.True(t, arm.IsProviderSupported("brew"), "software without a provider config stays supported")
+
+	amd := resolveArchitecture(saidata, "x86_64")
+	assert.Equal(t, "app-x64", amd.Providers["apt"].Packages[0].PackageName)
+	assert.True(t, amd.IsProviderSupported("snap"))
+
+	// The input is left untouched
+	assert.Len(t, saidata.Providers["apt"].Architectures, 1)
+}
+
+func TestSaidataManager_ArchitectureOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+	appDir := filepath.Join(tempDir, "software", "ap", "app")
+	require.NoError(t, os.MkdirAll(appDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(appDir, "default.yaml"), []byte(`version: "0.2"
+metadata:
+  name: app
+packages:
+  - name: app
+providers:
+  apt:
+    packages:
+      - name: app
+        package_name: app
+    architectures:
+      arm64:
+        packages:
+          - name: app
+            package_name: app-arm
+  docker:
+    architectures:
+      arm64:
+        unsupported: true
+`), 0644))
+
+	t.Setenv(platform.EnvTestOS, "debian")
+	t.Setenv(platform.EnvTestOSVersion, "12")
+	t.Setenv(platform.EnvTestArch, "aarch64")
+
+	saidata, err := NewManager(tempDir).LoadSoftware("app")
+	require.NoError(t, err)
+	assert.Equal(t, "app-arm", saidata.Providers["apt"].Packages[0].PackageName)
+	assert.False(t, saidata.IsProviderSupported("docker"))
+}
@@ -1,10 +1,27 @@
 package saidata
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 )
 
+// ErrSaidataMissing is returned when the saidata repository is not available and
+// downloading it is disabled
+var ErrSaidataMissing = errors.New("saidata repository is not available")
+
+// BootstrapOptions configures the download of the saidata repository on first run
+type BootstrapOptions struct {
+	GitURL         string
+	ZipFallbackURL string
+	Sparse         SparseFilter // Limits the download to the selected software
+	Verification   Verification // Downloaded saidata is refused unless it passes verification
+	Disabled       bool         // Fail with ErrSaidataMissing instead of downloading
+	Progress       io.Writer    // Receives the download messages and progress, nil discards them
+}
+
 // Bootstrap handles first-time saidata setup
 type Bootstrap struct {
 	repositoryManager *RepositoryManager
@@ -17,8 +34,9 @@ func NewBootstrap(gitURL, zipFallbackURL string) *Bootstrap {
 	}
 }
 
-// CheckAndInitialize checks if this is the first run and initializes saidata if needed
-func (b *Bootstrap) CheckAndInitialize() error {
+// CheckAndInitialize checks if this is the first run and initializes saidata if needed.
+// Cancelling ctx aborts the download.
+func (b *Bootstrap) CheckAndInitialize(ctx context.Context) error {
 	if !b.repositoryManager.IsFirstRun() {
 		// Repository already exists and is valid
 		return nil
@@ -30,29 +48,61 @@ func (b *Bootstrap) CheckAndInitialize() error {
 	}
 	
 	// Initialize repository
-	if err := b.repositoryManager.InitializeRepository(); err != nil {
+	if err := b.repositoryManager.InitializeRepository(ctx); err != nil {
 		return fmt.Errorf("failed to initialize saidata repository: %w", err)
 	}
 	
 	return nil
 }
 
+// SetSparseFilter limits the initial sync to the software selected by filter
+func (b *Bootstrap) SetSparseFilter(filter SparseFilter) {
+	b.repositoryManager.SetSparseFilter(filter)
+}
+
+// SetVerification sets how the downloaded saidata is verified
+func (b *Bootstrap) SetVerification(verification Verification) {
+	b.repositoryManager.SetVerification(verification)
+}
+
 // GetRepositoryManager returns the repository manager
 func (b *Bootstrap) GetRepositoryManager() *RepositoryManager {
 	return b.repositoryManager
 }
 
-// EnsureSaidataAvailable ensures saidata is available, initializing if necessary
-func EnsureSaidataAvailable(gitURL, zipFallbackURL string) (string, error) {
+// EnsureSaidataAvailable ensures saidata is available, initializing if necessary.
+// A non-empty sparse filter limits the initial sync to the selected software, and the
+// downloaded saidata is refused unless it passes verification.
+func EnsureSaidataAvailable(gitURL, zipFallbackURL string, sparse SparseFilter, verification Verification) (string, error) {
+	return EnsureSaidataAvailableContext(context.Background(), BootstrapOptions{
+		GitURL:         gitURL,
+		ZipFallbackURL: zipFallbackURL,
+		Sparse:         sparse,
+		Verification:   verification,
+		Progress:       os.Stdout,
+	})
+}
+
+// EnsureSaidataAvailableContext ensures saidata is available, downloading it as set
+// by options when missing. Cancelling ctx aborts the download without leaving partial
+// saidata behind.
+func EnsureSaidataAvailableContext(ctx context.Context, options BootstrapOptions) (string, error) {
 	// For development/testing, check if docs/saidata_samples exists and use it
 	if _, err := os.Stat("docs/saidata_samples"); err == nil {
 		return "docs/saidata_samples", nil
 	}
 	
-	bootstrap := NewBootstrap(gitURL, zipFallbackURL)
+	bootstrap := NewBootstrap(options.GitURL, options.ZipFallbackURL)
+	bootstrap.SetSparseFilter(options.Sparse)
+	bootstrap.SetVerification(options.Verification)
+	bootstrap.repositoryManager.SetProgress(options.Progress)
+	
+	if options.Disabled && bootstrap.repositoryManager.IsFirstRun() {
+		return "", fmt.Errorf("%w at %s, run 'sai saidata init' to download it", ErrSaidataMissing, GetSaidataPath())
+	}
 	
 	// Check and initialize if needed
-	if err := bootstrap.CheckAndInitialize(); err != nil {
+	if err := bootstrap.CheckAndInitialize(ctx); err != nil {
 		return "", err
 	}
 	
@@ -62,7 +62,7 @@ func TestEnsureSaidataAvailable(t *testing.T) {
 	
 	// For testing, we'll just verify the function doesn't panic
 	// and returns a valid path
-	path, err := EnsureSaidataAvailable("", "")
+	path, err := EnsureSaidataAvailable("", "", SparseFilter{}, Verification{})
 	
 	// In development environment, this should succeed using docs/saidata_samples
 	// In production, it might fail due to invalid URLs, which is expected
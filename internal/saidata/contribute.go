@@ -0,0 +1,406 @@
+package saidata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"sai/internal/types"
+	"sai/internal/validation"
+)
+
+// Contribution is a validated and formatted software definition ready to be proposed
+// to the upstream saidata repository
+type Contribution struct {
+	Software string
+	Path     string            // Definition directory, relative to the repository root
+	Files    map[string][]byte // Formatted files, relative to the repository root
+}
+
+// ContributeOptions configures how a contribution is proposed
+type ContributeOptions struct {
+	GitURL string // Upstream saidata repository, hosted on GitHub
+	Title  string // Pull request title, derived from the software when empty
+	Token  string // GitHub API token, used when the gh CLI is not installed
+}
+
+// githubAPIURL is the API endpoint of github.com
+var githubAPIURL = "https://api.github.com"
+
+// forkPushAttempts bounds the pushes to a fork GitHub is still creating
+const forkPushAttempts = 5
+
+var pullRequestTemplate = template.Must(template.New("pull-request").Parse(`{{if .New}}Adds{{else}}Updates{{end}} the saidata definition of ` + "`{{.Software}}`" + `.
+
+### Files
+
+{{range .Files}}- ` + "`{{.}}`" + `
+{{end}}
+### Checklist
+
+- [x] ` + "`default.yaml`" + ` validated against the saidata 0.2 schema
+- [x] Overrides parsed as saidata
+- [x] YAML formatted with 2 space indentation
+- [ ] Tested with ` + "`sai install {{.Software}} --dry-run`" + ` on the affected platforms
+
+Proposed with ` + "`sai saidata contribute {{.Software}}`" + `.
+`))
+
+// PrepareContribution validates and formats the definition of software found in
+// saidataDir. The default.yaml is validated against the schema when a validator is
+// given, overrides must parse as saidata.
+func PrepareContribution(saidataDir, software string, validator *validation.SaidataValidator) (*Contribution, error) {
+	relDir := DefinitionDir(saidataDir, software)
+	if relDir == "" {
+		return nil, fmt.Errorf("no saidata definition of %s found in %s", software, saidataDir)
+	}
+
+	contribution := &Contribution{
+		Software: software,
+		Path:     filepath.ToSlash(relDir),
+		Files:    make(map[string][]byte),
+	}
+
+	var problems []string
+	err := filepath.WalkDir(filepath.Join(saidataDir, relDir), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || (!strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml")) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(saidataDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		formatted, err := FormatSaidataYAML(data)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", relPath, err))
+			return nil
+		}
+
+		if relPath == contribution.Path+"/default.yaml" && validator != nil {
+			err = validator.ValidateSaidataYAML(formatted)
+		} else {
+			_, err = types.LoadSoftwareDataFromYAML(formatted)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", relPath, err))
+			return nil
+		}
+
+		contribution.Files[relPath] = formatted
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the definition of %s: %w", software, err)
+	}
+
+	if _, exists := contribution.Files[contribution.Path+"/default.yaml"]; !exists && len(problems) == 0 {
+		problems = append(problems, contribution.Path+"/default.yaml is missing")
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid saidata definition of %s:\n  %s", software, strings.Join(problems, "\n  "))
+	}
+
+	return contribution, nil
+}
+
+// FileNames returns the files of the contribution in lexical order
+func (c *Contribution) FileNames() []string {
+	names := make([]string, 0, len(c.Files))
+	for name := range c.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatSaidataYAML re-encodes a saidata document with 2 space indentation, keeping
+// key order and comments
+func FormatSaidataYAML(data []byte) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if document.Kind == 0 {
+		return nil, fmt.Errorf("empty document")
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, fmt.Errorf("failed to format YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to format YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// OpenPullRequest proposes the contribution to the upstream repository: the repository
+// is cloned to a temporary directory, the definition committed on a new branch, pushed
+// to the user's fork and a pull request opened. It returns the pull request URL. The
+// GitHub API is called through the gh CLI when installed, with opts.Token otherwise.
+func OpenPullRequest(contribution *Contribution, opts ContributeOptions) (string, error) {
+	host, owner, repo, err := parseGitHubRepository(opts.GitURL)
+	if err != nil {
+		return "", err
+	}
+
+	client := &githubClient{host: host, token: opts.Token}
+	if _, err := exec.LookPath("gh"); err == nil {
+		client.token = ""
+	} else if opts.Token == "" {
+		return "", fmt.Errorf("contributing requires the gh CLI or a GitHub token in GITHUB_TOKEN")
+	}
+
+	dir, err := os.MkdirTemp("", "sai-contribute-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := runGit("", "clone", "--quiet", "--depth", "1", opts.GitURL, dir); err != nil {
+		return "", err
+	}
+	base, err := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	_, statErr := os.Stat(filepath.Join(dir, filepath.FromSlash(contribution.Path)))
+	isNew := os.IsNotExist(statErr)
+
+	branch := contributionBranch(contribution.Software, time.Now())
+	if err := runGit(dir, "checkout", "--quiet", "-b", branch); err != nil {
+		return "", err
+	}
+	for _, name := range contribution.FileNames() {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, contribution.Files[name], 0644); err != nil {
+			return "", err
+		}
+	}
+	if err := runGit(dir, "add", "--", contribution.Path); err != nil {
+		return "", err
+	}
+	if err := runGit(dir, "diff", "--cached", "--quiet"); err == nil {
+		return "", fmt.Errorf("no changes to contribute: the definition of %s matches the upstream repository", contribution.Software)
+	}
+
+	title := opts.Title
+	if title == "" {
+		verb := "Update"
+		if isNew {
+			verb = "Add"
+		}
+		title = fmt.Sprintf("%s %s saidata", verb, contribution.Software)
+	}
+	if err := runGit(dir, "commit", "--quiet", "-m", title); err != nil {
+		return "", err
+	}
+
+	var body strings.Builder
+	if err := pullRequestTemplate.Execute(&body, struct {
+		Software string
+		New      bool
+		Files    []string
+	}{contribution.Software, isNew, contribution.FileNames()}); err != nil {
+		return "", fmt.Errorf("failed to render pull request description: %w", err)
+	}
+
+	var fork struct {
+		CloneURL string `json:"clone_url"`
+		Owner    struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	}
+	if err := client.call(http.MethodPost, fmt.Sprintf("repos/%s/%s/forks", owner, repo), nil, &fork); err != nil {
+		return "", fmt.Errorf("failed to fork %s/%s: %w", owner, repo, err)
+	}
+
+	// A new fork is created asynchronously, retry until it accepts the push
+	for attempt := 1; ; attempt++ {
+		err = client.push(dir, fork.CloneURL, branch)
+		if err == nil {
+			break
+		}
+		if attempt == forkPushAttempts {
+			return "", err
+		}
+		time.Sleep(time.Duration(attempt) * 2 * time.Second)
+	}
+
+	var pull struct {
+		HTMLURL string `json:"html_url"`
+	}
+	request := map[string]interface{}{
+		"title":                 title,
+		"head":                  fork.Owner.Login + ":" + branch,
+		"base":                  base,
+		"body":                  body.String(),
+		"maintainer_can_modify": true,
+	}
+	if err := client.call(http.MethodPost, fmt.Sprintf("repos/%s/%s/pulls", owner, repo), request, &pull); err != nil {
+		return "", fmt.Errorf("failed to open pull request: %w", err)
+	}
+	return pull.HTMLURL, nil
+}
+
+// contributionBranch returns the branch name of a contribution
+func contributionBranch(software string, now time.Time) string {
+	return fmt.Sprintf("saidata/%s-%s", software, now.UTC().Format("20060102150405"))
+}
+
+// parseGitHubRepository returns the host, owner and name of a GitHub repository URL
+func parseGitHubRepository(gitURL string) (string, string, string, error) {
+	var host, path string
+	if strings.HasPrefix(gitURL, "git@") {
+		// SCP-like syntax: git@github.com:owner/repo.git
+		parts := strings.SplitN(strings.TrimPrefix(gitURL, "git@"), ":", 2)
+		if len(parts) == 2 {
+			host, path = parts[0], parts[1]
+		}
+	} else if parsed, err := url.Parse(gitURL); err == nil {
+		host, path = parsed.Host, parsed.Path
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(path, ".git"), "/"), "/")
+	if host == "" || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("saidata repository %s is not a GitHub repository", gitURL)
+	}
+	return host, parts[0], parts[1], nil
+}
+
+// githubClient calls the GitHub REST API through the gh CLI or with a token
+type githubClient struct {
+	host  string
+	token string // API token, the gh CLI is used when empty
+}
+
+// call sends a REST API request, decoding the JSON response into result
+func (c *githubClient) call(method, path string, body, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	var response []byte
+	if c.token == "" {
+		args := []string{"api", "--hostname", c.host, "--method", method, path}
+		if payload != nil {
+			args = append(args, "--input", "-")
+		}
+		cmd := exec.Command("gh", args...)
+		cmd.Stdin = bytes.NewReader(payload)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("gh api %s failed: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+		}
+		response = output
+	} else {
+		req, err := http.NewRequest(method, c.apiURL()+"/"+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if response, err = io.ReadAll(resp.Body); err != nil {
+			return err
+		}
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("GitHub API %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(response)))
+		}
+	}
+
+	if result == nil || len(response) == 0 {
+		return nil
+	}
+	return json.Unmarshal(response, result)
+}
+
+// apiURL returns the REST API endpoint of the host
+func (c *githubClient) apiURL() string {
+	if c.host == "github.com" {
+		return githubAPIURL
+	}
+	return "https://" + c.host + "/api/v3"
+}
+
+// push pushes branch to remote, authenticated like the API calls. The credentials are
+// passed through the environment so they do not show up in the process list.
+func (c *githubClient) push(dir, remote, branch string) error {
+	env := []string{"GIT_CONFIG_COUNT=2", "GIT_CONFIG_KEY_0=credential.helper", "GIT_CONFIG_VALUE_0="}
+	if c.token == "" {
+		env = append(env, "GIT_CONFIG_KEY_1=credential.helper", "GIT_CONFIG_VALUE_1=!gh auth git-credential")
+	} else {
+		credentials := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + c.token))
+		env = append(env, "GIT_CONFIG_KEY_1=http.extraHeader", "GIT_CONFIG_VALUE_1=Authorization: Basic "+credentials)
+	}
+
+	cmd := exec.Command("git", "push", "--quiet", remote, branch)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to push %s to %s: %w: %s", branch, remote, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// runGit runs a git command in dir
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// gitOutput runs a git command in dir and returns its trimmed output
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,155 @@
+package saidata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatSaidataYAML(t *testing.T) {
+	input := "version: \"0.2\"\n# Package list\npackages:\n    - name: nginx\n      package_name: nginx\n"
+	expected := "version: \"0.2\"\n# Package list\npackages:\n  - name: nginx\n    package_name: nginx\n"
+
+	formatted, err := FormatSaidataYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("FormatSaidataYAML failed: %v", err)
+	}
+	if string(formatted) != expected {
+		t.Errorf("Unexpected formatting:\n%s", formatted)
+	}
+
+	if _, err := FormatSaidataYAML([]byte("packages: [")); err == nil {
+		t.Error("Expected invalid YAML to be rejected")
+	}
+	if _, err := FormatSaidataYAML([]byte("")); err == nil {
+		t.Error("Expected empty document to be rejected")
+	}
+}
+
+func TestPrepareContribution(t *testing.T) {
+	saidataDir := t.TempDir()
+	definition := filepath.Join(saidataDir, "software", "ng", "nginx")
+	if err := os.MkdirAll(filepath.Join(definition, "ubuntu"), 0755); err != nil {
+		t.Fatalf("Failed to create definition: %v", err)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(definition, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	write("default.yaml", "version: \"0.2\"\nmetadata:\n    name: nginx\n")
+	write("ubuntu/22.04.yaml", "version: \"0.2\"\npackages:\n  - name: nginx-full\n")
+	write("README.md", "Not saidata\n")
+
+	contribution, err := PrepareContribution(saidataDir, "nginx", nil)
+	if err != nil {
+		t.Fatalf("PrepareContribution failed: %v", err)
+	}
+	if contribution.Path != "software/ng/nginx" {
+		t.Errorf("Expected path software/ng/nginx, got %s", contribution.Path)
+	}
+	expectedFiles := []string{"software/ng/nginx/default.yaml", "software/ng/nginx/ubuntu/22.04.yaml"}
+	if strings.Join(contribution.FileNames(), ",") != strings.Join(expectedFiles, ",") {
+		t.Errorf("Expected files %v, got %v", expectedFiles, contribution.FileNames())
+	}
+	if string(contribution.Files["software/ng/nginx/default.yaml"]) != "version: \"0.2\"\nmetadata:\n  name: nginx\n" {
+		t.Errorf("Expected default.yaml to be formatted, got:\n%s", contribution.Files["software/ng/nginx/default.yaml"])
+	}
+
+	write("ubuntu/22.04.yaml", "packages: [")
+	if _, err := PrepareContribution(saidataDir, "nginx", nil); err == nil || !strings.Contains(err.Error(), "ubuntu/22.04.yaml") {
+		t.Errorf("Expected the invalid override to be reported, got %v", err)
+	}
+
+	if _, err := PrepareContribution(saidataDir, "redis", nil); err == nil {
+		t.Error("Expected a missing definition to be reported")
+	}
+}
+
+func TestParseGitHubRepository(t *testing.T) {
+	tests := []struct {
+		url     string
+		host    string
+		owner   string
+		repo    string
+		wantErr bool
+	}{
+		{url: "https://github.com/example42/saidata.git", host: "github.com", owner: "example42", repo: "saidata"},
+		{url: "https://github.example.com/infra/saidata", host: "github.example.com", owner: "infra", repo: "saidata"},
+		{url: "git@github.com:example42/saidata.git", host: "github.com", owner: "example42", repo: "saidata"},
+		{url: "/srv/git/saidata", wantErr: true},
+		{url: "https://github.com/example42", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		host, owner, repo, err := parseGitHubRepository(tt.url)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.url, err)
+			continue
+		}
+		if host != tt.host || owner != tt.owner || repo != tt.repo {
+			t.Errorf("%s: got %s %s/%s", tt.url, host, owner, repo)
+		}
+	}
+}
+
+func TestContributionBranch(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if branch := contributionBranch("nginx", now); branch != "saidata/nginx-20240102030405" {
+		t.Errorf("Unexpected branch %s", branch)
+	}
+}
+
+func TestGitHubClientToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/example42/saidata/pulls":
+			var request map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request["head"] != "contributor:saidata/nginx" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"html_url": "https://github.com/example42/saidata/pull/1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	previous := githubAPIURL
+	githubAPIURL = server.URL
+	defer func() { githubAPIURL = previous }()
+
+	client := &githubClient{host: "github.com", token: "secret"}
+	var pull struct {
+		HTMLURL string `json:"html_url"`
+	}
+	request := map[string]interface{}{"head": "contributor:saidata/nginx"}
+	if err := client.call(http.MethodPost, "repos/example42/saidata/pulls", request, &pull); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if pull.HTMLURL != "https://github.com/example42/saidata/pull/1" {
+		t.Errorf("Unexpected pull request URL %s", pull.HTMLURL)
+	}
+
+	if err := client.call(http.MethodGet, "repos/example42/missing", nil, nil); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected the API error to be reported, got %v", err)
+	}
+}
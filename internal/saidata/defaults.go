@@ -3,17 +3,38 @@ package saidata
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 
+	"sai/internal/osinfo"
+	"sai/internal/platform"
 	"sai/internal/types"
 )
 
 // DefaultsGenerator generates intelligent defaults for missing saidata
 type DefaultsGenerator struct {
 	validator ResourceValidator
+	prober    SystemProber
+}
+
+// softwareAliases lists alternative names used by distributions for common software
+var softwareAliases = map[string][]string{
+	"apache":     {"apache2", "httpd"},
+	"apache2":    {"httpd"},
+	"httpd":      {"apache2"},
+	"mysql":      {"mysqld", "mariadb"},
+	"mariadb":    {"mysql", "mysqld"},
+	"postgresql": {"postgres"},
+	"postgres":   {"postgresql"},
+	"mongodb":    {"mongod"},
+	"redis":      {"redis-server"},
+	"ssh":        {"sshd"},
+	"openssh":    {"sshd", "ssh"},
+	"docker":     {"dockerd"},
+	"bind":       {"named", "bind9"},
 }
 
 // ResourceValidator validates resource existence
@@ -25,10 +46,15 @@ type ResourceValidator interface {
 	ValidatePort(port int) bool
 }
 
-// NewDefaultsGenerator creates a new defaults generator
+// NewDefaultsGenerator creates a new defaults generator. Generated resources are
+// always validated, using a system resource validator if none is provided.
 func NewDefaultsGenerator(validator ResourceValidator) *DefaultsGenerator {
+	if validator == nil {
+		validator = NewSystemResourceValidator()
+	}
 	return &DefaultsGenerator{
 		validator: validator,
+		prober:    NewSystemProber(),
 	}
 }
 
@@ -48,12 +74,18 @@ func (g *DefaultsGenerator) GenerateDefaults(software string) (*types.SoftwareDa
 	switch runtime.GOOS {
 	case "linux":
 		g.generateLinuxDefaults(saidata, software)
+		if mountRoot := g.prober.WSLMountRoot(); mountRoot != "" {
+			g.appendWSLWindowsDefaults(saidata, software, mountRoot)
+		}
 	case "darwin":
 		g.generateMacOSDefaults(saidata, software)
 	case "windows":
 		g.generateWindowsDefaults(saidata, software)
 	}
 
+	// Add resources discovered on the system ahead of guessed candidates
+	g.probeSystem(saidata, software)
+
 	// Validate generated resources and filter out non-existent ones
 	g.validateAndFilterResources(saidata)
 
@@ -193,6 +225,27 @@ func (g *DefaultsGenerator) generateWindowsDefaults(saidata *types.SoftwareData,
 	saidata.Ports = g.GeneratePortDefaults(software)
 }
 
+// appendWSLWindowsDefaults adds Windows-side file, directory and command candidates,
+// mapped under the WSL mount root, after the Linux ones. Software installed through
+// Windows providers is then found when no Linux-native installation exists.
+func (g *DefaultsGenerator) appendWSLWindowsDefaults(saidata *types.SoftwareData, software, mountRoot string) {
+	windows := &types.SoftwareData{}
+	g.generateWindowsDefaults(windows, software)
+
+	for _, file := range windows.Files {
+		file.Path = osinfo.WindowsToWSLPath(file.Path, mountRoot)
+		saidata.Files = append(saidata.Files, file)
+	}
+	for _, directory := range windows.Directories {
+		directory.Path = osinfo.WindowsToWSLPath(directory.Path, mountRoot)
+		saidata.Directories = append(saidata.Directories, directory)
+	}
+	for _, command := range windows.Commands {
+		command.Path = osinfo.WindowsToWSLPath(command.Path, mountRoot)
+		saidata.Commands = append(saidata.Commands, command)
+	}
+}
+
 // GeneratePackageDefaults generates default package definitions
 func (g *DefaultsGenerator) GeneratePackageDefaults(software string) []types.Package {
 	return []types.Package{
@@ -226,37 +279,20 @@ func (g *DefaultsGenerator) GenerateFileDefaults(software string) []types.File {
 	
 	switch runtime.GOOS {
 	case "linux":
-		files = []types.File{
-			{
-				Name: "config",
-				Path: fmt.Sprintf("/etc/%s/%s.conf", software, software),
-				Type: "config",
-			},
-			{
-				Name: "alt_config",
-				Path: fmt.Sprintf("/etc/%s.conf", software),
-				Type: "config",
-			},
-			{
-				Name: "binary",
-				Path: fmt.Sprintf("/usr/bin/%s", software),
-				Type: "binary",
-			},
-			{
-				Name: "alt_binary",
-				Path: fmt.Sprintf("/usr/sbin/%s", software),
-				Type: "binary",
-			},
-			{
-				Name: "log",
-				Path: fmt.Sprintf("/var/log/%s.log", software),
-				Type: "log",
-			},
-			{
-				Name: "alt_log",
-				Path: fmt.Sprintf("/var/log/%s/%s.log", software, software),
-				Type: "log",
-			},
+		// Candidates share logical names; the first one found on the system is kept
+		for _, name := range g.nameVariants(software) {
+			files = append(files,
+				types.File{Name: "config", Path: fmt.Sprintf("/etc/%s/%s.conf", name, name), Type: "config"},
+				types.File{Name: "config", Path: fmt.Sprintf("/etc/%s.conf", name), Type: "config"},
+				types.File{Name: "binary", Path: fmt.Sprintf("/usr/bin/%s", name), Type: "binary"},
+				types.File{Name: "binary", Path: fmt.Sprintf("/usr/sbin/%s", name), Type: "binary"},
+				types.File{Name: "log", Path: fmt.Sprintf("/var/log/%s.log", name), Type: "log"},
+				types.File{Name: "log", Path: fmt.Sprintf("/var/log/%s/%s.log", name, name), Type: "log"},
+				types.File{Name: "log", Path: fmt.Sprintf("/var/log/%s/error.log", name), Type: "log"},
+			)
+			if envFile := g.environmentFilePath(name); envFile != "" {
+				files = append(files, types.File{Name: "environment", Path: envFile, Type: "config"})
+			}
 		}
 	case "darwin":
 		files = []types.File{
@@ -305,27 +341,14 @@ func (g *DefaultsGenerator) GenerateDirectoryDefaults(software string) []types.D
 	
 	switch runtime.GOOS {
 	case "linux":
-		directories = []types.Directory{
-			{
-				Name: "config",
-				Path: fmt.Sprintf("/etc/%s", software),
-			},
-			{
-				Name: "data",
-				Path: fmt.Sprintf("/var/lib/%s", software),
-			},
-			{
-				Name: "log",
-				Path: fmt.Sprintf("/var/log/%s", software),
-			},
-			{
-				Name: "cache",
-				Path: fmt.Sprintf("/var/cache/%s", software),
-			},
-			{
-				Name: "run",
-				Path: fmt.Sprintf("/var/run/%s", software),
-			},
+		for _, name := range g.nameVariants(software) {
+			directories = append(directories,
+				types.Directory{Name: "config", Path: fmt.Sprintf("/etc/%s", name)},
+				types.Directory{Name: "data", Path: fmt.Sprintf("/var/lib/%s", name)},
+				types.Directory{Name: "log", Path: fmt.Sprintf("/var/log/%s", name)},
+				types.Directory{Name: "cache", Path: fmt.Sprintf("/var/cache/%s", name)},
+				types.Directory{Name: "run", Path: fmt.Sprintf("/run/%s", name)},
+			)
 		}
 	case "darwin":
 		directories = []types.Directory{
@@ -364,19 +387,13 @@ func (g *DefaultsGenerator) GenerateCommandDefaults(software string) []types.Com
 	
 	switch runtime.GOOS {
 	case "linux":
-		commands = []types.Command{
-			{
-				Name: software,
-				Path: fmt.Sprintf("/usr/bin/%s", software),
-			},
-			{
-				Name: fmt.Sprintf("%s-alt", software),
-				Path: fmt.Sprintf("/usr/sbin/%s", software),
-			},
-			{
-				Name: fmt.Sprintf("%s-local", software),
-				Path: fmt.Sprintf("/usr/local/bin/%s", software),
-			},
+		for _, name := range g.nameVariants(software) {
+			for _, dir := range []string{"/usr/bin", "/usr/sbin", "/usr/local/bin", "/usr/local/sbin"} {
+				commands = append(commands, types.Command{
+					Name: name,
+					Path: filepath.Join(dir, name),
+				})
+			}
 		}
 	case "darwin":
 		commands = []types.Command{
@@ -443,61 +460,191 @@ func (g *DefaultsGenerator) GeneratePortDefaults(software string) []types.Port {
 	return []types.Port{}
 }
 
-// validateAndFilterResources validates generated resources and removes non-existent ones
-func (g *DefaultsGenerator) validateAndFilterResources(saidata *types.SoftwareData) {
-	if g.validator == nil {
-		return // Skip validation if no validator provided
+// probeSystem adds commands and services discovered on the system. Discovered
+// resources are placed before the guessed candidates so they take precedence.
+func (g *DefaultsGenerator) probeSystem(saidata *types.SoftwareData, software string) {
+	if g.prober == nil {
+		return
 	}
-	
+
+	var commands []types.Command
+	var services []types.Service
+	serviceType := "systemd"
+	if len(saidata.Services) > 0 {
+		serviceType = saidata.Services[0].Type
+	}
+
+	for _, name := range g.nameVariants(software) {
+		// Resolve commands like which(1)
+		if path, err := g.prober.LookPath(name); err == nil {
+			commands = append(commands, types.Command{Name: name, Path: path})
+		}
+
+		// Match service units against the name, preferring an exact match
+		matches := g.prober.ListServices(name)
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i] == name && matches[j] != name
+		})
+		for _, serviceName := range matches {
+			services = append(services, types.Service{
+				Name:        software,
+				ServiceName: serviceName,
+				Type:        serviceType,
+			})
+		}
+	}
+
+	saidata.Commands = append(commands, saidata.Commands...)
+	saidata.Services = append(services, saidata.Services...)
+}
+
+// validateAndFilterResources validates generated resources and only keeps those found
+// on the system. For each logical name the first existing candidate is kept; missing
+// resources are recorded in AbsentResources instead of being guessed.
+func (g *DefaultsGenerator) validateAndFilterResources(saidata *types.SoftwareData) {
+	absent := newAbsentTracker()
+
 	// Filter files
 	var validFiles []types.File
+	seen := make(map[string]bool)
 	for _, file := range saidata.Files {
+		if seen[file.Name] {
+			continue
+		}
 		if g.validator.ValidateFile(file.Path) {
 			file.Exists = true
+			seen[file.Name] = true
 			validFiles = append(validFiles, file)
 		}
 	}
+	for _, file := range saidata.Files {
+		if !seen[file.Name] {
+			absent.add("file", file.Name)
+		}
+	}
 	saidata.Files = validFiles
-	
+
 	// Filter services
 	var validServices []types.Service
+	seen = make(map[string]bool)
 	for _, service := range saidata.Services {
+		if seen[service.Name] {
+			continue
+		}
 		if g.validator.ValidateService(service.GetServiceNameOrDefault()) {
 			service.Exists = true
+			seen[service.Name] = true
 			validServices = append(validServices, service)
 		}
 	}
+	for _, service := range saidata.Services {
+		if !seen[service.Name] {
+			absent.add("service", service.Name)
+		}
+	}
 	saidata.Services = validServices
-	
+
 	// Filter commands
 	var validCommands []types.Command
+	seen = make(map[string]bool)
 	for _, command := range saidata.Commands {
+		if seen[command.Name] {
+			continue
+		}
 		if g.validator.ValidateCommand(command.GetPathOrDefault()) {
 			command.Exists = true
+			seen[command.Name] = true
 			validCommands = append(validCommands, command)
 		}
 	}
+	for _, command := range saidata.Commands {
+		if !seen[command.Name] {
+			absent.add("command", command.Name)
+		}
+	}
 	saidata.Commands = validCommands
-	
+
 	// Filter directories
 	var validDirectories []types.Directory
+	seen = make(map[string]bool)
 	for _, directory := range saidata.Directories {
+		if seen[directory.Name] {
+			continue
+		}
 		if g.validator.ValidateDirectory(directory.Path) {
 			directory.Exists = true
+			seen[directory.Name] = true
 			validDirectories = append(validDirectories, directory)
 		}
 	}
+	for _, directory := range saidata.Directories {
+		if !seen[directory.Name] {
+			absent.add("directory", directory.Name)
+		}
+	}
 	saidata.Directories = validDirectories
-	
+
 	// Filter ports (check if they're open/in use)
 	var validPorts []types.Port
 	for _, port := range saidata.Ports {
 		if g.validator.ValidatePort(port.Port) {
 			port.IsOpen = true
 			validPorts = append(validPorts, port)
+		} else {
+			absent.add("port", strconv.Itoa(port.Port))
 		}
 	}
 	saidata.Ports = validPorts
+
+	saidata.AbsentResources = absent.resources
+}
+
+// absentTracker collects unique "type:name" entries for resources not found
+type absentTracker struct {
+	resources []string
+	seen      map[string]bool
+}
+
+func newAbsentTracker() *absentTracker {
+	return &absentTracker{seen: make(map[string]bool)}
+}
+
+func (a *absentTracker) add(resourceType, name string) {
+	key := resourceType + ":" + name
+	if !a.seen[key] {
+		a.seen[key] = true
+		a.resources = append(a.resources, key)
+	}
+}
+
+// nameVariants returns the software name followed by its known distribution aliases
+func (g *DefaultsGenerator) nameVariants(software string) []string {
+	variants := []string{software}
+	for _, alias := range softwareAliases[strings.ToLower(software)] {
+		if alias != software {
+			variants = append(variants, alias)
+		}
+	}
+	return variants
+}
+
+// environmentFilePath returns the distribution-specific service environment file
+func (g *DefaultsGenerator) environmentFilePath(name string) string {
+	family := ""
+	if g.prober != nil {
+		family = g.prober.DistroFamily()
+	}
+
+	switch family {
+	case "debian":
+		return fmt.Sprintf("/etc/default/%s", name)
+	case "rhel", "suse":
+		return fmt.Sprintf("/etc/sysconfig/%s", name)
+	case "alpine":
+		return fmt.Sprintf("/etc/conf.d/%s", name)
+	default:
+		return ""
+	}
 }
 
 // ValidatePathExists checks if a file or directory path exists
@@ -517,7 +664,14 @@ func (g *DefaultsGenerator) ValidateServiceExists(service string) bool {
 		return g.validator.ValidateService(service)
 	}
 	
-	// Fallback implementation would check systemctl, launchctl, etc.
+	// Fallback to matching services listed by systemctl or launchctl
+	if g.prober != nil {
+		for _, name := range g.prober.ListServices(service) {
+			if name == service {
+				return true
+			}
+		}
+	}
 	return false
 }
 
@@ -537,7 +691,7 @@ func (g *DefaultsGenerator) ValidateCommandExists(command string) bool {
 	}
 	
 	// Check PATH for relative commands
-	_, err := exec.LookPath(command)
+	_, err := platform.LookPath(command)
 	return err == nil
 }
 
@@ -0,0 +1,147 @@
+package saidata
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeValidator reports only the configured resources as existing
+type fakeValidator struct {
+	paths    map[string]bool
+	services map[string]bool
+	ports    map[int]bool
+}
+
+func (f *fakeValidator) ValidateFile(path string) bool           { return f.paths[path] }
+func (f *fakeValidator) ValidateService(serviceName string) bool { return f.services[serviceName] }
+func (f *fakeValidator) ValidateCommand(command string) bool     { return f.paths[command] }
+func (f *fakeValidator) ValidateDirectory(path string) bool      { return f.paths[path] }
+func (f *fakeValidator) ValidatePort(port int) bool              { return f.ports[port] }
+
+// fakeProber returns canned discovery results
+type fakeProber struct {
+	commands  map[string]string
+	services  map[string][]string
+	family    string
+	mountRoot string
+}
+
+func (f *fakeProber) LookPath(command string) (string, error) {
+	if path, ok := f.commands[command]; ok {
+		return path, nil
+	}
+	return "", fmt.Errorf("%s not found", command)
+}
+
+func (f *fakeProber) ListServices(pattern string) []string { return f.services[pattern] }
+func (f *fakeProber) DistroFamily() string                 { return f.family }
+func (f *fakeProber) WSLMountRoot() string                 { return f.mountRoot }
+
+func TestDefaultsGenerator_OnlyEmitsExistingResources(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Linux candidate paths")
+	}
+
+	validator := &fakeValidator{
+		paths: map[string]bool{
+			"/usr/sbin/apache2":          true,
+			"/etc/apache2":               true,
+			"/var/log/apache2/error.log": true,
+			"/etc/default/apache2":       true,
+		},
+		services: map[string]bool{"apache2": true},
+		ports:    map[int]bool{80: true},
+	}
+	generator := NewDefaultsGenerator(validator)
+	generator.prober = &fakeProber{
+		commands: map[string]string{"apache2": "/usr/sbin/apache2"},
+		services: map[string][]string{"apache2": {"apache2", "apache2@"}},
+		family:   "debian",
+	}
+
+	saidata, err := generator.GenerateDefaults("apache")
+	require.NoError(t, err)
+
+	require.Len(t, saidata.Commands, 1)
+	assert.Equal(t, "/usr/sbin/apache2", saidata.Commands[0].Path)
+	assert.True(t, saidata.Commands[0].Exists)
+
+	require.Len(t, saidata.Services, 1)
+	assert.Equal(t, "apache", saidata.Services[0].Name)
+	assert.Equal(t, "apache2", saidata.Services[0].ServiceName)
+
+	files := make(map[string]string)
+	for _, file := range saidata.Files {
+		files[file.Name] = file.Path
+	}
+	assert.Equal(t, map[string]string{
+		"binary":      "/usr/sbin/apache2",
+		"log":         "/var/log/apache2/error.log",
+		"environment": "/etc/default/apache2",
+	}, files)
+
+	require.Len(t, saidata.Directories, 1)
+	assert.Equal(t, "/etc/apache2", saidata.Directories[0].Path)
+
+	require.Len(t, saidata.Ports, 1)
+	assert.Equal(t, 80, saidata.Ports[0].Port)
+
+	// Resources not found are recorded as absent instead of guessed
+	assert.Contains(t, saidata.AbsentResources, "file:config")
+	assert.Contains(t, saidata.AbsentResources, "directory:data")
+	assert.Contains(t, saidata.AbsentResources, "port:443")
+	assert.NotContains(t, saidata.AbsentResources, "file:log")
+}
+
+func TestDefaultsGenerator_NothingFound(t *testing.T) {
+	generator := NewDefaultsGenerator(&fakeValidator{})
+	generator.prober = &fakeProber{}
+
+	saidata, err := generator.GenerateDefaults("unknown-software")
+	require.NoError(t, err)
+
+	// Packages are kept so the software can still be installed
+	require.Len(t, saidata.Packages, 1)
+	assert.Equal(t, "unknown-software", saidata.Packages[0].Name)
+	assert.Empty(t, saidata.Files)
+	assert.Empty(t, saidata.Services)
+	assert.Empty(t, saidata.Commands)
+	assert.Empty(t, saidata.Directories)
+	assert.Contains(t, saidata.AbsentResources, "service:unknown-software")
+}
+
+func TestDefaultsGenerator_WSLWindowsCandidates(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("WSL only exists on Linux")
+	}
+
+	validator := &fakeValidator{
+		paths: map[string]bool{
+			"/etc/nginx":                           true,
+			"/mnt/c/ProgramData/Nginx":             true,
+			"/mnt/c/Program Files/Nginx/nginx.exe": true,
+		},
+	}
+	generator := NewDefaultsGenerator(validator)
+	generator.prober = &fakeProber{mountRoot: "/mnt/"}
+
+	saidata, err := generator.GenerateDefaults("nginx")
+	require.NoError(t, err)
+
+	directories := make(map[string]string)
+	for _, directory := range saidata.Directories {
+		directories[directory.Name] = directory.Path
+	}
+	// Linux-native locations win over Windows ones
+	assert.Equal(t, map[string]string{
+		"config": "/etc/nginx",
+		"data":   "/mnt/c/ProgramData/Nginx",
+	}, directories)
+
+	require.Len(t, saidata.Commands, 1)
+	assert.Equal(t, "/mnt/c/Program Files/Nginx/nginx.exe", saidata.Commands[0].Path)
+}
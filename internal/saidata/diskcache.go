@@ -0,0 +1,158 @@
+package saidata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sai/internal/fileutil"
+	"sai/internal/types"
+)
+
+// diskCacheVersion is part of every key, bump it when cached entries become incompatible
+const diskCacheVersion = "1"
+
+// DiskCache persists resolved saidata across sai invocations. Entries are keyed by
+// the content of the saidata files they were resolved from, so editing a file never
+// returns stale data, and expire after a TTL.
+type DiskCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// diskCacheEntry is a cached saidata with the time it was resolved
+type diskCacheEntry struct {
+	Created time.Time           `json:"created"`
+	Data    *types.SoftwareData `json:"data"`
+}
+
+// NewDiskCache creates a cache storing entries in dir for ttl
+func NewDiskCache(dir string, ttl time.Duration) *DiskCache {
+	return &DiskCache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// Dir returns the directory of the cache
+func (c *DiskCache) Dir() string {
+	return c.dir
+}
+
+// Key returns the key of saidata resolved for a platform from the files of software
+// directories, one per repository defining the software, "" when a directory cannot
+// be read
+func (c *DiskCache) Key(platformKey string, softwareDirs ...string) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00", diskCacheVersion, platformKey)
+
+	for i, softwareDir := range softwareDirs {
+		var files []string
+		err := filepath.WalkDir(softwareDir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil || len(files) == 0 {
+			return ""
+		}
+		sort.Strings(files)
+
+		for _, path := range files {
+			file, err := os.Open(path)
+			if err != nil {
+				return ""
+			}
+			rel, _ := filepath.Rel(softwareDir, path)
+			fmt.Fprintf(hash, "%d/%s\x00", i, filepath.ToSlash(rel))
+			_, err = io.Copy(hash, file)
+			file.Close()
+			if err != nil {
+				return ""
+			}
+			hash.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the saidata cached with a key, if it has not expired
+func (c *DiskCache) Get(key string) (*types.SoftwareData, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry diskCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Data == nil {
+		os.Remove(c.path(key))
+		return nil, false
+	}
+	if c.now().Sub(entry.Created) > c.ttl {
+		os.Remove(c.path(key))
+		return nil, false
+	}
+	return entry.Data, true
+}
+
+// Put caches saidata with a key
+func (c *DiskCache) Put(key string, saidata *types.SoftwareData) error {
+	data, err := json.Marshal(diskCacheEntry{Created: c.now(), Data: saidata})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached saidata: %w", err)
+	}
+	return fileutil.WriteFileAtomic(c.path(key), data, 0644)
+}
+
+// Clear removes every cached entry and returns how many were removed
+func (c *DiskCache) Clear() (int, error) {
+	entries, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read saidata cache: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove cached saidata: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// path returns the file of the entry with a key
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// SetDiskCache sets the saidata cache invalidated when the repository changes
+func (rm *RepositoryManager) SetDiskCache(cache *DiskCache) {
+	rm.diskCache = cache
+}
+
+// invalidateCache clears the saidata cache after the repository changed. Entries are
+// keyed by file content and would not be returned anyway, clearing reclaims the space.
+func (rm *RepositoryManager) invalidateCache() {
+	if rm.diskCache == nil {
+		return
+	}
+	if _, err := rm.diskCache.Clear(); err != nil {
+		fmt.Fprintf(rm.progress, "⚠️  Failed to clear the saidata cache: %v\n", err)
+	}
+}
@@ -0,0 +1,82 @@
+package saidata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCache(t *testing.T) {
+	saidataDir := t.TempDir()
+	nginxDir := filepath.Join(saidataDir, "software", "ng", "nginx")
+	require.NoError(t, os.MkdirAll(nginxDir, 0755))
+	defaultFile := filepath.Join(nginxDir, "default.yaml")
+	require.NoError(t, os.WriteFile(defaultFile, []byte(`version: "0.2"
+metadata:
+  name: "nginx"
+packages:
+  - name: "nginx"
+    version: "1.24.0"`), 0644))
+
+	cacheDir := t.TempDir()
+	cache := NewDiskCache(cacheDir, time.Hour)
+
+	manager := NewManager(saidataDir)
+	manager.SetDiskCache(cache)
+	saidata, err := manager.LoadSoftware("nginx")
+	require.NoError(t, err)
+	assert.Equal(t, "1.24.0", saidata.Packages[0].Version)
+
+	// A new manager loads the cached saidata without parsing the files
+	key := manager.diskCacheKey("nginx")
+	require.NotEmpty(t, key)
+	cached, found := cache.Get(key)
+	require.True(t, found)
+	assert.Equal(t, saidata, cached)
+
+	manager = NewManager(saidataDir)
+	manager.SetDiskCache(cache)
+	reloaded, err := manager.LoadSoftware("nginx")
+	require.NoError(t, err)
+	assert.Equal(t, saidata, reloaded)
+
+	// Changing a file changes the key
+	require.NoError(t, os.WriteFile(defaultFile, []byte(`version: "0.2"
+metadata:
+  name: "nginx"
+packages:
+  - name: "nginx"
+    version: "1.26.0"`), 0644))
+	assert.NotEqual(t, key, manager.diskCacheKey("nginx"))
+	manager = NewManager(saidataDir)
+	manager.SetDiskCache(cache)
+	saidata, err = manager.LoadSoftware("nginx")
+	require.NoError(t, err)
+	assert.Equal(t, "1.26.0", saidata.Packages[0].Version)
+
+	// Entries expire after the TTL
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, found = cache.Get(manager.diskCacheKey("nginx"))
+	assert.False(t, found)
+	cache.now = time.Now
+
+	// Generated defaults are not cached
+	assert.Empty(t, manager.diskCacheKey("nonexistent"))
+
+	removed, err := cache.Clear()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDiskCacheClearMissing(t *testing.T) {
+	removed, err := NewDiskCache(filepath.Join(t.TempDir(), "missing"), time.Hour).Clear()
+	assert.NoError(t, err)
+	assert.Zero(t, removed)
+}
@@ -8,7 +8,7 @@ import (
 
 func TestManagerWithBootstrap(t *testing.T) {
 	// Test with invalid URLs to ensure it doesn't crash
-	manager, err := NewManagerWithBootstrap("", "")
+	manager, err := NewManagerWithBootstrap("", "", SparseFilter{}, Verification{})
 	
 	// This should either succeed (if docs/saidata_samples exists) or fail gracefully
 	if err != nil {
@@ -119,7 +119,7 @@ func TestEnsureSaidataAvailableWithDocs(t *testing.T) {
 	}
 	
 	// This should work in development environment
-	path, err := EnsureSaidataAvailable("", "")
+	path, err := EnsureSaidataAvailable("", "", SparseFilter{}, Verification{})
 	if err != nil {
 		t.Fatalf("EnsureSaidataAvailable failed: %v", err)
 	}
@@ -0,0 +1,37 @@
+package saidata
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LocalChanges returns the saidata files of a git repository modified or added locally,
+// such as definitions written before contributing them, relative to the repository.
+// Repositories downloaded as a zip have no local changes to report.
+func LocalChanges(saidataDir string) ([]string, error) {
+	// Only a repository of its own, paths are relative to the top level
+	if prefix, err := gitOutput(saidataDir, "rev-parse", "--show-prefix"); err != nil || prefix != "" {
+		return nil, nil
+	}
+
+	output, err := gitOutput(saidataDir, "status", "--porcelain", "--untracked-files=all", "--", "*.yaml", "*.yml")
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, line := range strings.Split(output, "\n") {
+		// Lines are "XY path" or "XY old -> new" for renames; deleted files have nothing to check
+		if len(line) < 4 || strings.Contains(line[:2], "D") {
+			continue
+		}
+		path := line[3:]
+		if i := strings.Index(path, " -> "); i >= 0 {
+			path = path[i+len(" -> "):]
+		}
+		changed = append(changed, filepath.FromSlash(strings.Trim(path, `"`)))
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
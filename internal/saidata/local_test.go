@@ -0,0 +1,51 @@
+package saidata
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// Not a git repository, as a repository downloaded as a zip
+	changed, err := LocalChanges(dir)
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	write("software/ng/nginx/default.yaml", "metadata:\n  name: nginx\n")
+	write("software/ap/apache/default.yaml", "metadata:\n  name: apache\n")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=sai", "-c", "user.email=sai@example.com", "commit", "-q", "-m", "saidata"},
+	} {
+		require.NoError(t, runGit(dir, args...))
+	}
+
+	write("software/ng/nginx/default.yaml", "metadata:\n  name: nginx\n  license: BSD-2-Clause\n")
+	write("software/ng/nginx/ubuntu/24.04.yaml", "metadata:\n  name: nginx\n")
+	write("README.md", "# saidata\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "software/ap/apache/default.yaml")))
+
+	changed, err = LocalChanges(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.FromSlash("software/ng/nginx/default.yaml"),
+		filepath.FromSlash("software/ng/nginx/ubuntu/24.04.yaml"),
+	}, changed)
+}
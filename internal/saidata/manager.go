@@ -1,15 +1,17 @@
 package saidata
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"sai/internal/debug"
 	"sai/internal/interfaces"
+	"sai/internal/platform"
 	"sai/internal/types"
 	"sai/internal/validation"
 )
@@ -19,8 +21,13 @@ type Manager struct {
 	saidataDir        string
 	validator         *validation.SaidataValidator
 	cache             map[string]*types.SoftwareData
+	cacheMutex        sync.RWMutex
 	defaultsGenerator *DefaultsGenerator
 	resourceValidator *SystemResourceValidator
+	verification      Verification // Verification of repository updates
+	diskCache         *DiskCache   // Resolved saidata persisted across runs, nil when disabled
+	offline           bool         // Refuse repository updates, see SetOffline
+	repositories      []Repository // Searched by descending priority, see SetRepositories
 }
 
 // NewManager creates a new saidata manager
@@ -42,18 +49,33 @@ func NewManager(saidataDir string) *Manager {
 		cache:             make(map[string]*types.SoftwareData),
 		defaultsGenerator: NewDefaultsGenerator(resourceValidator),
 		resourceValidator: resourceValidator,
+		verification:      Verification{Method: VerifyGPG},
 	}
 }
 
 // NewManagerWithBootstrap creates a new saidata manager with automatic bootstrap
-func NewManagerWithBootstrap(gitURL, zipFallbackURL string) (*Manager, error) {
+func NewManagerWithBootstrap(gitURL, zipFallbackURL string, sparse SparseFilter, verification Verification) (*Manager, error) {
+	return NewManagerWithBootstrapContext(context.Background(), BootstrapOptions{
+		GitURL:         gitURL,
+		ZipFallbackURL: zipFallbackURL,
+		Sparse:         sparse,
+		Verification:   verification,
+		Progress:       os.Stdout,
+	})
+}
+
+// NewManagerWithBootstrapContext creates a new saidata manager, downloading the
+// saidata repository as set by options when missing
+func NewManagerWithBootstrapContext(ctx context.Context, options BootstrapOptions) (*Manager, error) {
 	// Ensure saidata is available
-	saidataDir, err := EnsureSaidataAvailable(gitURL, zipFallbackURL)
+	saidataDir, err := EnsureSaidataAvailableContext(ctx, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to ensure saidata availability: %w", err)
 	}
 	
-	return NewManager(saidataDir), nil
+	manager := NewManager(saidataDir)
+	manager.verification = options.Verification
+	return manager, nil
 }
 
 // LoadSoftware loads saidata for a specific software with OS-specific overrides
@@ -61,127 +83,97 @@ func (m *Manager) LoadSoftware(name string) (*types.SoftwareData, error) {
 	startTime := time.Now()
 	
 	// Check cache first
-	if cached, exists := m.cache[name]; exists {
+	if cached, err := m.GetCachedData(name); err == nil {
 		debug.LogSaidataLoadingGlobal(name, "cache", "", nil, time.Since(startTime), true, nil)
 		return cached, nil
 	}
 
-	// Generate prefix from software name (first 2 characters)
-	prefix := generatePrefix(name)
-	
-	// Load base configuration following hierarchical pattern: software/{prefix}/{software}/default.yaml
-	basePath := filepath.Join(m.saidataDir, "software", prefix, name, "default.yaml")
-	baseData, err := m.loadSaidataFile(basePath)
-	var saidataPath string = basePath
-	var osOverride string = ""
-	
-	if err != nil {
-		// Check if it's a file not found error (including nested path errors)
-		if os.IsNotExist(err) || strings.Contains(err.Error(), "no such file or directory") {
-			// Try alternative path without "software" prefix for backward compatibility
-			altBasePath := filepath.Join(m.saidataDir, prefix, name, "default.yaml")
-			saidataPath = altBasePath
-			baseData, err = m.loadSaidataFile(altBasePath)
-			if err != nil {
-				if os.IsNotExist(err) || strings.Contains(err.Error(), "no such file or directory") {
-					// Generate intelligent defaults
-					saidataPath = "generated_defaults"
-					baseData, err = m.GenerateDefaults(name)
-					if err != nil {
-						debug.LogSaidataLoadingGlobal(name, saidataPath, osOverride, nil, time.Since(startTime), false, err)
-						return nil, fmt.Errorf("failed to generate defaults for software '%s': %w", name, err)
-					}
-					// Cache and return generated defaults (no OS overrides for generated data)
-					m.cache[name] = baseData
-					
-					mergeResults := map[string]interface{}{
-						"source": "generated_defaults",
-						"packages": len(baseData.Packages),
-						"services": len(baseData.Services),
-						"files": len(baseData.Files),
-					}
-					debug.LogSaidataLoadingGlobal(name, saidataPath, osOverride, mergeResults, time.Since(startTime), true, nil)
-					return baseData, nil
-				} else {
-					debug.LogSaidataLoadingGlobal(name, saidataPath, osOverride, nil, time.Since(startTime), false, err)
-					return nil, fmt.Errorf("failed to load base saidata for software '%s' from %s: %w", name, altBasePath, err)
-				}
-			}
-		} else {
-			debug.LogSaidataLoadingGlobal(name, saidataPath, osOverride, nil, time.Since(startTime), false, err)
-			return nil, fmt.Errorf("failed to load base saidata for software '%s' from %s: %w", name, basePath, err)
+	// Then the saidata resolved by earlier runs from the same files
+	diskCacheKey := m.diskCacheKey(name)
+	if diskCacheKey != "" {
+		if cached, found := m.diskCache.Get(diskCacheKey); found {
+			m.CacheData(name, cached)
+			debug.LogSaidataLoadingGlobal(name, "disk_cache", "", nil, time.Since(startTime), true, nil)
+			return cached, nil
 		}
 	}
 
 	// Detect current OS and version for OS-specific overrides
-	osInfo, err := detectOSInfo()
+	osInfo, err := platform.Detect()
 	if err != nil {
-		// If OS detection fails, log warning but continue with base data
+		// If OS detection fails, log warning but continue with the definitions only
 		fmt.Printf("Warning: OS detection failed, using base saidata only: %v\n", err)
-		m.cache[name] = baseData
-		return baseData, nil
+		osInfo = nil
 	}
 
-	// Try to load OS-specific override following pattern: software/{prefix}/{software}/{os}/{os_version}.yaml
-	overridePath := filepath.Join(m.saidataDir, "software", prefix, name, osInfo.OS, osInfo.Version+".yaml")
-	if _, err := os.Stat(overridePath); err == nil {
-		osOverride = fmt.Sprintf("%s/%s", osInfo.OS, osInfo.Version)
-		overrideData, err := m.loadSaidataFile(overridePath)
+	// Load the base definition following the hierarchical pattern
+	// software/{prefix}/{software}/default.yaml of the repositories
+	saidataPath, overrides := m.definitionFiles(name, osInfo)
+	osOverride := ""
+	if saidataPath == "" {
+		// Generate intelligent defaults
+		saidataPath = "generated_defaults"
+		baseData, err := m.GenerateDefaults(name)
 		if err != nil {
-			// If override fails to load, log warning but continue with base data
-			fmt.Printf("Warning: failed to load OS override from %s: %v\n", overridePath, err)
-		} else {
-			// Deep merge override with base data
-			baseData = m.mergeSaidata(baseData, overrideData)
+			debug.LogSaidataLoadingGlobal(name, saidataPath, osOverride, nil, time.Since(startTime), false, err)
+			return nil, fmt.Errorf("failed to generate defaults for software '%s': %w", name, err)
 		}
-	} else {
-		// Try alternative path without "software" prefix for backward compatibility
-		altOverridePath := filepath.Join(m.saidataDir, prefix, name, osInfo.OS, osInfo.Version+".yaml")
-		if _, err := os.Stat(altOverridePath); err == nil {
-			osOverride = fmt.Sprintf("%s/%s", osInfo.OS, osInfo.Version)
-			overrideData, err := m.loadSaidataFile(altOverridePath)
-			if err != nil {
-				fmt.Printf("Warning: failed to load OS override from %s: %v\n", altOverridePath, err)
-			} else {
-				// Applying OS override from alternative path
-				baseData = m.mergeSaidata(baseData, overrideData)
-			}
-		} else {
-			// Try without version (just OS) - first with "software" prefix
-			osOnlyPath := filepath.Join(m.saidataDir, "software", prefix, name, osInfo.OS, "default.yaml")
-			if _, err := os.Stat(osOnlyPath); err == nil {
-				osOverride = osInfo.OS
-				overrideData, err := m.loadSaidataFile(osOnlyPath)
-				if err != nil {
-					fmt.Printf("Warning: failed to load OS-only override from %s: %v\n", osOnlyPath, err)
-				} else {
-					// Applying OS-only override
-					baseData = m.mergeSaidata(baseData, overrideData)
-				}
-			} else {
-				// Try alternative path without "software" prefix
-				altOSOnlyPath := filepath.Join(m.saidataDir, prefix, name, osInfo.OS, "default.yaml")
-				if _, err := os.Stat(altOSOnlyPath); err == nil {
-					osOverride = osInfo.OS
-					overrideData, err := m.loadSaidataFile(altOSOnlyPath)
-					if err != nil {
-						fmt.Printf("Warning: failed to load OS-only override from %s: %v\n", altOSOnlyPath, err)
-					} else {
-						// Applying OS-only override from alternative path
-						baseData = m.mergeSaidata(baseData, overrideData)
-					}
-				}
-			}
+		// Cache and return generated defaults (no OS overrides for generated data)
+		m.CacheData(name, baseData)
+
+		mergeResults := map[string]interface{}{
+			"source": "generated_defaults",
+			"packages": len(baseData.Packages),
+			"services": len(baseData.Services),
+			"files": len(baseData.Files),
+		}
+		debug.LogSaidataLoadingGlobal(name, saidataPath, osOverride, mergeResults, time.Since(startTime), true, nil)
+		return baseData, nil
+	}
+
+	baseData, err := m.loadSaidataFile(saidataPath)
+	if err != nil {
+		debug.LogSaidataLoadingGlobal(name, saidataPath, osOverride, nil, time.Since(startTime), false, err)
+		return nil, fmt.Errorf("failed to load base saidata for software '%s' from %s: %w", name, saidataPath, err)
+	}
+
+	// Merge the definitions of the repositories of higher priority and the platform,
+	// distribution family, OS and architecture overrides, from the most generic to the
+	// most specific
+	var labels []string
+	for _, override := range overrides {
+		overrideData, err := m.loadSaidataFile(override.Path)
+		if err != nil {
+			// If override fails to load, log warning but continue without it
+			fmt.Printf("Warning: failed to load OS override from %s: %v\n", override.Path, err)
+			continue
 		}
+		// Deep merge override with base data
+		baseData = m.mergeSaidata(baseData, overrideData)
+		labels = append(labels, override.Label)
+	}
+	osOverride = strings.Join(labels, " + ")
+
+	if osInfo == nil {
+		m.CacheData(name, baseData)
+		return baseData, nil
 	}
 
+	// Apply the provider overrides of the detected architecture
+	baseData = resolveArchitecture(baseData, osInfo.Architecture)
+
 	// Cache the result
-	m.cache[name] = baseData
+	m.CacheData(name, baseData)
+	if diskCacheKey != "" {
+		// Failing to persist only costs the next run a reload
+		_ = m.diskCache.Put(diskCacheKey, baseData)
+	}
 	
 	// Log successful saidata loading with merge results
 	mergeResults := map[string]interface{}{
 		"source": saidataPath,
 		"os_override": osOverride,
+		"architecture": osInfo.Architecture,
 		"packages": len(baseData.Packages),
 		"services": len(baseData.Services),
 		"files": len(baseData.Files),
@@ -196,6 +188,85 @@ func (m *Manager) LoadSoftware(name string) (*types.SoftwareData, error) {
 	return baseData, nil
 }
 
+// OverrideFile is an override merged into the base saidata of a software
+type OverrideFile struct {
+	Path  string `json:"path"`
+	Label string `json:"label"` // Directory and file of the override, e.g. ubuntu/22.04 or linux/arm64
+}
+
+// findOverrides returns the overrides of a software that apply to osInfo, in merge
+// order from the most generic to the most specific, each later one taking precedence:
+//
+//  1. {platform}/default.yaml, e.g. linux/default.yaml
+//  2. {platform}/{arch}.yaml, e.g. linux/arm64.yaml
+//  3. {family}/default.yaml then {family}/{arch}.yaml for each parent distribution,
+//     the most generic first, e.g. debian/default.yaml on Ubuntu
+//  4. {os}/{os_version}.yaml, or else {os}/default.yaml
+//  5. {os}/{arch}.yaml, e.g. ubuntu/arm64.yaml
+//
+// Files live in software/{prefix}/{software}/ of the repository directory, or
+// {prefix}/{software}/ for backward compatibility. Architecture files may be named after any alias of the architecture
+// (x86_64.yaml for amd64), the Go name being preferred when both exist.
+func (m *Manager) findOverrides(repositoryDir, prefix, name string, osInfo *platform.OSInfo) []OverrideFile {
+	var overrides []OverrideFile
+	find := func(dir string, files ...string) bool {
+		for _, file := range files {
+			for _, root := range []string{filepath.Join(repositoryDir, "software"), repositoryDir} {
+				path := filepath.Join(root, prefix, name, dir, file+".yaml")
+				if _, err := os.Stat(path); err == nil {
+					label := dir
+					if file != "default" {
+						label = dir + "/" + file
+					}
+					overrides = append(overrides, OverrideFile{Path: path, Label: label})
+					return true
+				}
+			}
+		}
+		return false
+	}
+	archFiles := architectureNames(osInfo.Architecture)
+
+	levels := []string{}
+	if osInfo.Platform != "" && osInfo.Platform != osInfo.OS {
+		levels = append(levels, osInfo.Platform)
+	}
+	for _, family := range distributionFamilies(osInfo) {
+		if family != osInfo.OS && family != osInfo.Platform {
+			levels = append(levels, family)
+		}
+	}
+	for _, level := range levels {
+		find(level, "default")
+		find(level, archFiles...)
+	}
+
+	if osInfo.OS != "" {
+		if osInfo.Version == "" || !find(osInfo.OS, osInfo.Version) {
+			find(osInfo.OS, "default")
+		}
+		find(osInfo.OS, archFiles...)
+	}
+	return overrides
+}
+
+// distributionFamilies returns the parent distributions of an OS from the most generic
+// to the most specific: ID_LIKE in reverse order, as os-release lists the closest
+// parent first, preceded by the known family of the OS (debian, rhel, ...) when ID_LIKE
+// does not list it
+func distributionFamilies(osInfo *platform.OSInfo) []string {
+	var families []string
+	if family := osInfo.Family(); family != "" && !osInfo.IsLike(family) {
+		families = append(families, family)
+	}
+	for i := len(osInfo.IDLike) - 1; i >= 0; i-- {
+		if osInfo.IDLike[i] != "" {
+			families = append(families, osInfo.IDLike[i])
+		}
+	}
+	return families
+}
+
 // loadSaidataFile loads and validates a saidata YAML file
 func (m *Manager) loadSaidataFile(filePath string) (*types.SoftwareData, error) {
 	data, err := os.ReadFile(filePath)
@@ -203,6 +274,10 @@ func (m *Manager) loadSaidataFile(filePath string) (*types.SoftwareData, error)
 		return nil, fmt.Errorf("failed to read saidata file %s: %w", filePath, err)
 	}
 
+	if len(data) > LargeDefinitionSize {
+		fmt.Printf("Warning: %s is %.1f MB, large saidata files slow down every load\n", filePath, float64(len(data))/(1024*1024))
+	}
+
 	// Parse YAML
 	saidata, err := types.LoadSoftwareDataFromYAML(data)
 	if err != nil {
@@ -265,14 +340,21 @@ func (m *Manager) mergeSaidata(base, override *types.SoftwareData) *types.Softwa
 
 	// Merge provider configurations
 	if override.Providers != nil {
-		if result.Providers == nil {
-			result.Providers = make(map[string]types.ProviderConfig)
+		// Copy the providers of base, which must not see the override
+		providers := make(map[string]types.ProviderConfig, len(result.Providers)+len(override.Providers))
+		for providerName, providerConfig := range result.Providers {
+			providers[providerName] = providerConfig
 		}
+		result.Providers = providers
 		for providerName, providerConfig := range override.Providers {
 			result.Providers[providerName] = mergeProviderConfig(result.Providers[providerName], providerConfig)
 		}
 	}
 
+	if override.PostInstallMessage != "" {
+		result.PostInstallMessage = override.PostInstallMessage
+	}
+
 	// Merge compatibility
 	if override.Compatibility != nil {
 		if result.Compatibility == nil {
@@ -300,74 +382,79 @@ func (m *Manager) GetProviderConfig(software string, provider string) (*types.Pr
 	return &types.ProviderConfig{}, nil
 }
 
-// SearchSoftware searches for software in the saidata directory
+// SearchSoftware searches for software in the saidata repositories, a software
+// defined by several being described by the one of highest priority
 func (m *Manager) SearchSoftware(query string) ([]*interfaces.SoftwareInfo, error) {
 	var results []*interfaces.SoftwareInfo
+	seen := make(map[string]bool)
 
-	// Walk through the saidata directory structure
-	err := filepath.Walk(m.saidataDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip errors and continue
-		}
-
-		// Look for default.yaml files
-		if info.Name() == "default.yaml" {
-			// Extract software name from path
-			relPath, err := filepath.Rel(m.saidataDir, path)
+	// Walk through the saidata directory structure of each repository
+	for _, repository := range m.Repositories() {
+		err := filepath.Walk(repository.Dir, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
-				return nil
+				return nil // Skip errors and continue
 			}
 
-			parts := strings.Split(relPath, string(filepath.Separator))
-			var softwareName string
-			
-			// Handle both hierarchical patterns:
-			// 1. software/{prefix}/{software}/default.yaml (new format)
-			// 2. {prefix}/{software}/default.yaml (backward compatibility)
-			if len(parts) >= 4 && parts[0] == "software" {
-				softwareName = parts[2] // software/prefix/software/default.yaml
-			} else if len(parts) >= 3 {
-				softwareName = parts[1] // prefix/software/default.yaml
-			} else {
-				return nil // Skip invalid paths
-			}
-			
-			// Check if software name matches query
-			if strings.Contains(strings.ToLower(softwareName), strings.ToLower(query)) {
-				// Load basic metadata
-				saidata, err := m.loadSaidataFile(path)
+			// Look for default.yaml files
+			if info.Name() == "default.yaml" {
+				// Extract software name from path
+				relPath, err := filepath.Rel(repository.Dir, path)
 				if err != nil {
-					fmt.Printf("Warning: Failed to load saidata for %s: %v\n", softwareName, err)
-					return nil // Skip invalid files
+					return nil
 				}
 
-				homepage := ""
-				license := ""
-				if saidata.Metadata.URLs != nil {
-					homepage = saidata.Metadata.URLs.Website
-				}
-				if saidata.Metadata.License != "" {
-					license = saidata.Metadata.License
+				parts := strings.Split(relPath, string(filepath.Separator))
+				var softwareName string
+			
+				// Handle both hierarchical patterns:
+				// 1. software/{prefix}/{software}/default.yaml (new format)
+				// 2. {prefix}/{software}/default.yaml (backward compatibility)
+				if len(parts) >= 4 && parts[0] == "software" {
+					softwareName = parts[2] // software/prefix/software/default.yaml
+				} else if len(parts) >= 3 {
+					softwareName = parts[1] // prefix/software/default.yaml
+				} else {
+					return nil // Skip invalid paths
 				}
+			
+				// Check if software name matches query
+				if !seen[softwareName] && strings.Contains(strings.ToLower(softwareName), strings.ToLower(query)) {
+					// Load basic metadata
+					saidata, err := m.loadSaidataFile(path)
+					if err != nil {
+						fmt.Printf("Warning: Failed to load saidata for %s: %v\n", softwareName, err)
+						return nil // Skip invalid files
+					}
+
+					homepage := ""
+					license := ""
+					if saidata.Metadata.URLs != nil {
+						homepage = saidata.Metadata.URLs.Website
+					}
+					if saidata.Metadata.License != "" {
+						license = saidata.Metadata.License
+					}
 
-				results = append(results, &interfaces.SoftwareInfo{
-					Software:     softwareName,
-					Provider:     "saidata",
-					PackageName:  softwareName,
-					Version:      saidata.Metadata.Version,
-					Description:  saidata.Metadata.Description,
-					Homepage:     homepage,
-					License:      license,
-					Dependencies: []string{},
-				})
+					seen[softwareName] = true
+					results = append(results, &interfaces.SoftwareInfo{
+						Software:     softwareName,
+						Provider:     "saidata",
+						PackageName:  softwareName,
+						Version:      saidata.Metadata.Version,
+						Description:  saidata.Metadata.Description,
+						Homepage:     homepage,
+						License:      license,
+						Dependencies: []string{},
+					})
+				}
 			}
-		}
 
-		return nil
-	})
+			return nil
+		})
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to search saidata directory: %w", err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search saidata directory: %w", err)
+		}
 	}
 
 	return results, nil
@@ -397,12 +484,6 @@ type SoftwareInfo struct {
 	Tags        []string `json:"tags,omitempty"`
 }
 
-// OSInfo represents detected OS information
-type OSInfo struct {
-	OS      string
-	Version string
-}
-
 // generatePrefix generates a 2-character prefix from software name
 func generatePrefix(name string) string {
 	if len(name) < 2 {
@@ -411,195 +492,16 @@ func generatePrefix(name string) string {
 	return strings.ToLower(name[:2])
 }
 
-// detectOSInfo detects the current OS and version
-func detectOSInfo() (*OSInfo, error) {
-	// Check for environment variable override for testing
-	if testOS := os.Getenv("SAI_TEST_OS"); testOS != "" {
-		testVersion := os.Getenv("SAI_TEST_OS_VERSION")
-		if testVersion == "" {
-			testVersion = "unknown"
-		}
-		// Using test OS override for testing
-		return &OSInfo{OS: testOS, Version: testVersion}, nil
-	}
-	
-	switch runtime.GOOS {
-	case "linux":
-		osInfo, err := detectLinuxInfo()
-		if err != nil {
-			return nil, fmt.Errorf("failed to detect Linux distribution: %w", err)
-		}
-		return osInfo, nil
-	case "darwin":
-		osInfo, err := detectMacOSInfo()
-		if err != nil {
-			return nil, fmt.Errorf("failed to detect macOS version: %w", err)
-		}
-		return osInfo, nil
-	case "windows":
-		osInfo, err := detectWindowsInfo()
-		if err != nil {
-			return nil, fmt.Errorf("failed to detect Windows version: %w", err)
+// DefinitionDir returns the directory holding the definition of software in
+// saidataDir, relative to it, or an empty string when there is none
+func DefinitionDir(saidataDir, software string) string {
+	prefix := generatePrefix(software)
+	for _, candidate := range []string{filepath.Join("software", prefix, software), filepath.Join(prefix, software)} {
+		if info, err := os.Stat(filepath.Join(saidataDir, candidate)); err == nil && info.IsDir() {
+			return candidate
 		}
-		return osInfo, nil
-	default:
-		return nil, fmt.Errorf("unsupported OS: %s", runtime.GOOS)
 	}
-}
-
-// detectLinuxInfo detects Linux distribution and version
-func detectLinuxInfo() (*OSInfo, error) {
-	// Try /etc/os-release first (most modern distributions)
-	if data, err := os.ReadFile("/etc/os-release"); err == nil {
-		if osInfo, err := parseOSRelease(string(data)); err == nil {
-			return osInfo, nil
-		}
-		// Failed to parse /etc/os-release, trying other methods
-	}
-
-	// Try /etc/lsb-release (Ubuntu/Debian)
-	if data, err := os.ReadFile("/etc/lsb-release"); err == nil {
-		if osInfo, err := parseLSBRelease(string(data)); err == nil {
-			return osInfo, nil
-		}
-		// Failed to parse /etc/lsb-release, trying other methods
-	}
-
-	// Try other distribution-specific files
-	distFiles := map[string]string{
-		"/etc/redhat-release": "rhel",
-		"/etc/centos-release": "centos",
-		"/etc/fedora-release": "fedora",
-		"/etc/debian_version": "debian",
-		"/etc/rocky-release":  "rocky",
-		"/etc/almalinux-release": "almalinux",
-		"/etc/alpine-release": "alpine",
-	}
-	
-	for file, distro := range distFiles {
-		if data, err := os.ReadFile(file); err == nil {
-			version := strings.TrimSpace(string(data))
-			// Extract version number from release strings
-			if strings.Contains(version, "release") {
-				parts := strings.Fields(version)
-				for _, part := range parts {
-					if strings.Contains(part, ".") && len(part) <= 10 {
-						version = part
-						break
-					}
-				}
-			}
-			return &OSInfo{OS: distro, Version: version}, nil
-		}
-	}
-
-	// Fallback to generic linux
-	// No specific Linux distribution detected, using generic linux
-	return &OSInfo{OS: "linux", Version: "unknown"}, nil
-}
-
-// detectMacOSInfo detects macOS version
-func detectMacOSInfo() (*OSInfo, error) {
-	// Try to read macOS version from system
-	if data, err := os.ReadFile("/System/Library/CoreServices/SystemVersion.plist"); err == nil {
-		// Parse plist for version - simplified approach
-		content := string(data)
-		if strings.Contains(content, "ProductVersion") {
-			// Extract version using simple string parsing
-			lines := strings.Split(content, "\n")
-			for i, line := range lines {
-				if strings.Contains(line, "ProductVersion") && i+1 < len(lines) {
-					nextLine := strings.TrimSpace(lines[i+1])
-					if strings.HasPrefix(nextLine, "<string>") && strings.HasSuffix(nextLine, "</string>") {
-						version := strings.TrimPrefix(nextLine, "<string>")
-						version = strings.TrimSuffix(version, "</string>")
-						// Extract major version (e.g., "13.0.1" -> "13")
-						parts := strings.Split(version, ".")
-						if len(parts) > 0 {
-							return &OSInfo{OS: "macos", Version: parts[0]}, nil
-						}
-					}
-				}
-			}
-		}
-	}
-	
-	// Fallback to default
-	return &OSInfo{OS: "macos", Version: "13"}, nil
-}
-
-// detectWindowsInfo detects Windows version
-func detectWindowsInfo() (*OSInfo, error) {
-	// Try to detect Windows version using registry or system files
-	// For now, return a reasonable default
-	// In a full implementation, this would use Windows APIs or registry queries
-	return &OSInfo{OS: "windows", Version: "11"}, nil
-}
-
-// parseOSRelease parses /etc/os-release format
-func parseOSRelease(content string) (*OSInfo, error) {
-	var osID, versionID, prettyName string
-	
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "ID=") {
-			osID = strings.Trim(strings.TrimPrefix(line, "ID="), "\"")
-		} else if strings.HasPrefix(line, "VERSION_ID=") {
-			versionID = strings.Trim(strings.TrimPrefix(line, "VERSION_ID="), "\"")
-		} else if strings.HasPrefix(line, "PRETTY_NAME=") {
-			prettyName = strings.Trim(strings.TrimPrefix(line, "PRETTY_NAME="), "\"")
-		}
-	}
-
-	if osID == "" {
-		return nil, fmt.Errorf("could not determine OS ID from os-release")
-	}
-
-	// If no VERSION_ID, try to extract from PRETTY_NAME
-	if versionID == "" && prettyName != "" {
-		// Try to extract version from pretty name (e.g., "Ubuntu 22.04.3 LTS")
-		parts := strings.Fields(prettyName)
-		for _, part := range parts {
-			if strings.Contains(part, ".") && len(part) <= 10 {
-				// Extract major version (e.g., "22.04.3" -> "22.04")
-				versionParts := strings.Split(part, ".")
-				if len(versionParts) >= 2 {
-					versionID = versionParts[0] + "." + versionParts[1]
-				} else {
-					versionID = part
-				}
-				break
-			}
-		}
-	}
-
-	if versionID == "" {
-		versionID = "unknown"
-	}
-
-	return &OSInfo{OS: osID, Version: versionID}, nil
-}
-
-// parseLSBRelease parses /etc/lsb-release format
-func parseLSBRelease(content string) (*OSInfo, error) {
-	var distID, release string
-	
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "DISTRIB_ID=") {
-			distID = strings.ToLower(strings.TrimPrefix(line, "DISTRIB_ID="))
-		} else if strings.HasPrefix(line, "DISTRIB_RELEASE=") {
-			release = strings.TrimPrefix(line, "DISTRIB_RELEASE=")
-		}
-	}
-
-	if distID == "" {
-		return nil, fmt.Errorf("could not determine distribution ID from lsb-release")
-	}
-
-	return &OSInfo{OS: distID, Version: release}, nil
+	return ""
 }
 
 // GenerateDefaults generates intelligent defaults for missing saidata scenarios
@@ -614,6 +516,8 @@ func (m *Manager) UpdateRepository() error {
 		"https://github.com/example42/saidata.git",
 		"https://github.com/example42/saidata/archive/main.zip",
 	)
+	repoManager.SetVerification(m.verification)
+	repoManager.SetOffline(m.offline)
 	
 	return repoManager.UpdateRepository()
 }
@@ -625,6 +529,8 @@ func (m *Manager) ManageRepositoryOperations() error {
 		"https://github.com/example42/saidata.git",
 		"https://github.com/example42/saidata/archive/main.zip",
 	)
+	repoManager.SetVerification(m.verification)
+	repoManager.SetOffline(m.offline)
 	
 	// For now, this just updates the repository
 	return repoManager.UpdateRepository()
@@ -637,6 +543,8 @@ func (m *Manager) SynchronizeRepository() error {
 		"https://github.com/example42/saidata.git",
 		"https://github.com/example42/saidata/archive/main.zip",
 	)
+	repoManager.SetVerification(m.verification)
+	repoManager.SetOffline(m.offline)
 	
 	return repoManager.SynchronizeRepository()
 }
@@ -651,59 +559,107 @@ func (m *Manager) GetResourceStatus(saidata *types.SoftwareData) (*ResourceStatu
 	return m.resourceValidator.GetResourceStatus(saidata)
 }
 
+// Close releases the connection to systemd of the resource validator
+func (m *Manager) Close() {
+	m.resourceValidator.Close()
+}
+
 // CacheData caches saidata for performance
 func (m *Manager) CacheData(software string, data *types.SoftwareData) error {
+	m.cacheMutex.Lock()
+	defer m.cacheMutex.Unlock()
 	m.cache[software] = data
 	return nil
 }
 
+// SetDiskCache persists the resolved saidata in cache, nil disables the disk cache
+func (m *Manager) SetDiskCache(cache *DiskCache) {
+	m.diskCache = cache
+}
+
+// diskCacheKey returns the disk cache key of a software, "" when the disk cache is
+// disabled or no repository defines the software
+func (m *Manager) diskCacheKey(name string) string {
+	if m.diskCache == nil {
+		return ""
+	}
+	osInfo, err := platform.Detect()
+	if err != nil {
+		return ""
+	}
+	platformKey := fmt.Sprintf("%s/%s/%s", osInfo.OS, osInfo.Version, osInfo.Architecture)
+
+	var dirs []string
+	defined := false
+	for _, repository := range m.Repositories() {
+		if dir := repositorySoftwareDir(repository.Dir, name); dir != "" {
+			dirs = append(dirs, dir)
+			if _, err := os.Stat(filepath.Join(dir, "default.yaml")); err == nil {
+				defined = true
+			}
+		}
+	}
+	if !defined {
+		return ""
+	}
+	return m.diskCache.Key(platformKey, dirs...)
+}
+
 // GetCachedData retrieves cached saidata
 func (m *Manager) GetCachedData(software string) (*types.SoftwareData, error) {
+	m.cacheMutex.RLock()
+	defer m.cacheMutex.RUnlock()
 	if cached, exists := m.cache[software]; exists {
 		return cached, nil
 	}
 	return nil, fmt.Errorf("no cached data for software: %s", software)
 }
 
-// GetSoftwareList returns a list of available software
+// GetSoftwareList returns a list of the software defined by the saidata repositories
 func (m *Manager) GetSoftwareList() ([]string, error) {
 	var softwareList []string
+	seen := make(map[string]bool)
 	
-	err := filepath.Walk(m.saidataDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip errors
-		}
-
-		// Look for default.yaml files
-		if info.Name() == "default.yaml" {
-			// Extract software name from path
-			relPath, err := filepath.Rel(m.saidataDir, path)
+	for _, repository := range m.Repositories() {
+		err := filepath.Walk(repository.Dir, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
-				return nil
+				return nil // Skip errors
 			}
 
-			parts := strings.Split(relPath, string(filepath.Separator))
-			var softwareName string
+			// Look for default.yaml files
+			if info.Name() == "default.yaml" {
+				// Extract software name from path
+				relPath, err := filepath.Rel(repository.Dir, path)
+				if err != nil {
+					return nil
+				}
+
+				parts := strings.Split(relPath, string(filepath.Separator))
+				var softwareName string
 			
-			// Handle both hierarchical patterns:
-			// 1. software/{prefix}/{software}/default.yaml (new format)
-			// 2. {prefix}/{software}/default.yaml (backward compatibility)
-			if len(parts) >= 4 && parts[0] == "software" {
-				softwareName = parts[2] // software/prefix/software/default.yaml
-			} else if len(parts) >= 3 {
-				softwareName = parts[1] // prefix/software/default.yaml
-			} else {
-				return nil // Skip invalid paths
-			}
+				// Handle both hierarchical patterns:
+				// 1. software/{prefix}/{software}/default.yaml (new format)
+				// 2. {prefix}/{software}/default.yaml (backward compatibility)
+				if len(parts) >= 4 && parts[0] == "software" {
+					softwareName = parts[2] // software/prefix/software/default.yaml
+				} else if len(parts) >= 3 {
+					softwareName = parts[1] // prefix/software/default.yaml
+				} else {
+					return nil // Skip invalid paths
+				}
 			
-			softwareList = append(softwareList, softwareName)
-		}
+				if !seen[softwareName] {
+					seen[softwareName] = true
+					softwareList = append(softwareList, softwareName)
+				}
+			}
 
-		return nil
-	})
+			return nil
+		})
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to list software: %w", err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list software: %w", err)
+		}
 	}
 
 	return softwareList, nil
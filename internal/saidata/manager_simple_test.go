@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"sai/internal/types"
 )
 
 func TestSaidataManager_Basic(t *testing.T) {
@@ -100,4 +101,15 @@ func TestSaidataManager_GenerateDefaults(t *testing.T) {
 		assert.Equal(t, "nginx", saidata.Services[0].Name)
 		assert.Equal(t, "nginx", saidata.Services[0].ServiceName)
 	}
-}
\ No newline at end of file
+}
+
+func TestSaidataManager_MergePostInstallMessage(t *testing.T) {
+	manager := NewManager(t.TempDir())
+	base := &types.SoftwareData{PostInstallMessage: "Edit {{sai_file \"config\"}}, then run postgresql-setup --initdb"}
+
+	merged := manager.mergeSaidata(base, &types.SoftwareData{})
+	assert.Equal(t, base.PostInstallMessage, merged.PostInstallMessage)
+
+	merged = manager.mergeSaidata(base, &types.SoftwareData{PostInstallMessage: "Run pg_createcluster 16 main --start"})
+	assert.Equal(t, "Run pg_createcluster 16 main --start", merged.PostInstallMessage)
+}
@@ -223,6 +223,32 @@ func mergeProviderConfig(base, override types.ProviderConfig) types.ProviderConf
 		result.Repositories = mergeRepositories(result.Repositories, override.Repositories)
 	}
 	
+	// The args of an action replace those of the base for the same action
+	if len(override.Args) > 0 {
+		args := make(map[string][]string, len(result.Args)+len(override.Args))
+		for action, actionArgs := range result.Args {
+			args[action] = actionArgs
+		}
+		for action, actionArgs := range override.Args {
+			args[action] = actionArgs
+		}
+		result.Args = args
+	}
+	
+	if override.Unsupported {
+		result.Unsupported = true
+	}
+	if len(override.Architectures) > 0 {
+		architectures := make(map[string]types.ProviderConfig, len(result.Architectures)+len(override.Architectures))
+		for arch, config := range result.Architectures {
+			architectures[arch] = config
+		}
+		for arch, config := range override.Architectures {
+			architectures[arch] = mergeProviderConfig(architectures[arch], config)
+		}
+		result.Architectures = architectures
+	}
+	
 	return result
 }
 
@@ -0,0 +1,64 @@
+package saidata
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrOffline is returned when offline mode refuses to fetch saidata from the network
+var ErrOffline = errors.New("saidata network access refused in offline mode")
+
+// SetOffline refuses every download of the repository, so saidata only comes from
+// imported bundles
+func (rm *RepositoryManager) SetOffline(offline bool) {
+	rm.offline = offline
+}
+
+// checkOnline fails with ErrOffline when offline mode refuses operation
+func (rm *RepositoryManager) checkOnline(operation string) error {
+	if rm.offline {
+		return fmt.Errorf("%w: cannot %s the repository, import a bundle with 'sai bundle import' instead", ErrOffline, operation)
+	}
+	return nil
+}
+
+// LocalPath returns the directory of the local copy of the repository
+func (rm *RepositoryManager) LocalPath() string {
+	return rm.localPath
+}
+
+// StagingPath returns a directory next to the local copy where a replacement is
+// prepared, so ImportRepository can move it into place without copying
+func (rm *RepositoryManager) StagingPath() string {
+	return rm.localPath + ".import"
+}
+
+// ImportRepository replaces the local copy with the saidata in dir, typically
+// extracted from a bundle into StagingPath. The saidata is validated first and the
+// local copy is left untouched when it fails.
+func (rm *RepositoryManager) ImportRepository(dir string) error {
+	staging := *rm
+	staging.localPath = dir
+	if err := staging.ValidateRepository(); err != nil {
+		return fmt.Errorf("imported saidata is not valid: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rm.localPath), 0755); err != nil {
+		return fmt.Errorf("failed to create saidata directory: %w", err)
+	}
+	if err := os.RemoveAll(rm.localPath); err != nil {
+		return fmt.Errorf("failed to remove existing directory: %w", err)
+	}
+	if err := os.Rename(dir, rm.localPath); err != nil {
+		return fmt.Errorf("failed to install saidata repository: %w", err)
+	}
+	rm.invalidateCache()
+	return nil
+}
+
+// SetOffline refuses the repository updates and synchronizations of the manager
+func (m *Manager) SetOffline(offline bool) {
+	m.offline = offline
+}
@@ -0,0 +1,55 @@
+package saidata
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOfflineRefusesNetwork(t *testing.T) {
+	rm := &RepositoryManager{localPath: t.TempDir(), zipFallbackURL: "http://127.0.0.1:1/saidata.zip"}
+	rm.SetOffline(true)
+
+	if err := rm.InitializeRepository(context.Background()); !errors.Is(err, ErrOffline) {
+		t.Errorf("InitializeRepository() error = %v, want ErrOffline", err)
+	}
+	if err := rm.UpdateRepository(); !errors.Is(err, ErrOffline) {
+		t.Errorf("UpdateRepository() error = %v, want ErrOffline", err)
+	}
+	if err := rm.SynchronizeRepository(); !errors.Is(err, ErrOffline) {
+		t.Errorf("SynchronizeRepository() error = %v, want ErrOffline", err)
+	}
+}
+
+func TestImportRepository(t *testing.T) {
+	localPath := filepath.Join(t.TempDir(), "saidata")
+	if err := os.MkdirAll(filepath.Join(localPath, "software", "ol"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rm := &RepositoryManager{localPath: localPath}
+
+	// Invalid saidata leaves the local copy untouched
+	invalid := t.TempDir()
+	if err := rm.ImportRepository(invalid); err == nil {
+		t.Error("ImportRepository() of invalid saidata succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(localPath, "software", "ol")); err != nil {
+		t.Errorf("local copy changed by a failed import: %v", err)
+	}
+
+	staging := rm.StagingPath()
+	if err := os.MkdirAll(filepath.Join(staging, "software", "ng", "nginx"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.ImportRepository(staging); err != nil {
+		t.Fatalf("ImportRepository() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(localPath, "software", "ng", "nginx")); err != nil {
+		t.Errorf("imported saidata missing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(localPath, "software", "ol")); !os.IsNotExist(err) {
+		t.Errorf("previous saidata kept after import")
+	}
+}
@@ -0,0 +1,137 @@
+package saidata
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"sai/internal/osinfo"
+	"sai/internal/platform"
+)
+
+// SystemProber discovers resources that are actually present on the system
+type SystemProber interface {
+	// LookPath resolves a command name to its executable path, like which(1)
+	LookPath(command string) (string, error)
+
+	// ListServices returns the names of services matching a name pattern
+	ListServices(pattern string) []string
+
+	// DistroFamily returns the Linux distribution family (debian, rhel, suse, arch, alpine)
+	DistroFamily() string
+
+	// WSLMountRoot returns where Windows drives are mounted when running under WSL,
+	// or "" outside WSL
+	WSLMountRoot() string
+}
+
+// systemProber implements SystemProber using system tools
+type systemProber struct {
+	family    string
+	mountRoot *string
+}
+
+// NewSystemProber creates a prober for the running system
+func NewSystemProber() SystemProber {
+	return &systemProber{}
+}
+
+// LookPath resolves a command name in PATH
+func (p *systemProber) LookPath(command string) (string, error) {
+	return platform.LookPath(command)
+}
+
+// ListServices returns service names matching pattern using systemctl or launchctl
+func (p *systemProber) ListServices(pattern string) []string {
+	switch runtime.GOOS {
+	case "linux":
+		return p.listSystemdServices(pattern)
+	case "darwin":
+		return p.listLaunchdServices(pattern)
+	default:
+		return nil
+	}
+}
+
+// DistroFamily returns the distribution family of the running Linux system
+func (p *systemProber) DistroFamily() string {
+	if p.family != "" {
+		return p.family
+	}
+
+	if osInfo, err := platform.Detect(); err == nil && osInfo.Platform == "linux" {
+		p.family = osInfo.Family()
+	}
+	return p.family
+}
+
+// WSLMountRoot returns the Windows drive mount root under WSL, or "" outside WSL
+func (p *systemProber) WSLMountRoot() string {
+	if p.mountRoot != nil {
+		return *p.mountRoot
+	}
+
+	mountRoot := ""
+	if osInfo, err := platform.Detect(); err == nil && osInfo.WSL {
+		mountRoot = osinfo.WSLMountRoot()
+	}
+	p.mountRoot = &mountRoot
+	return mountRoot
+}
+
+// listSystemdServices lists loaded units (including template instances) and installed
+// unit files whose names start with pattern
+func (p *systemProber) listSystemdServices(pattern string) []string {
+	var services []string
+	seen := make(map[string]bool)
+
+	queries := [][]string{
+		{"list-units", "--all", "--type=service", "--no-legend", "--plain", "--no-pager", pattern + "*.service"},
+		{"list-unit-files", "--type=service", "--no-legend", "--no-pager", pattern + "*.service"},
+	}
+
+	for _, args := range queries {
+		output, err := exec.Command("systemctl", args...).Output()
+		if err != nil {
+			continue
+		}
+
+		for _, line := range strings.Split(string(output), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+
+			name := strings.TrimSuffix(fields[0], ".service")
+			// Template units (name@.service) cannot be managed without an instance
+			if name == fields[0] || strings.HasSuffix(name, "@") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			services = append(services, name)
+		}
+	}
+
+	return services
+}
+
+// listLaunchdServices lists launchd labels containing pattern
+func (p *systemProber) listLaunchdServices(pattern string) []string {
+	output, err := exec.Command("launchctl", "list").Output()
+	if err != nil {
+		return nil
+	}
+
+	var services []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		if strings.Contains(strings.ToLower(fields[2]), strings.ToLower(pattern)) {
+			services = append(services, fields[2])
+		}
+	}
+
+	return services
+}
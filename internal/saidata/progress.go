@@ -0,0 +1,62 @@
+package saidata
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressInterval is the minimum time between two download progress reports
+const progressInterval = 500 * time.Millisecond
+
+// downloadProgress is a writer reporting how much of a download was received
+type downloadProgress struct {
+	out      io.Writer
+	total    int64 // Expected size, -1 when the server did not announce it
+	received int64
+	reported time.Time
+}
+
+// newDownloadProgress reports the progress of a download of total bytes to out
+func newDownloadProgress(out io.Writer, total int64) *downloadProgress {
+	return &downloadProgress{out: out, total: total}
+}
+
+// Write counts the received bytes, reporting them at most every progressInterval
+func (p *downloadProgress) Write(data []byte) (int, error) {
+	p.received += int64(len(data))
+	if time.Since(p.reported) >= progressInterval {
+		p.report()
+	}
+	return len(data), nil
+}
+
+// Done reports the final size of a complete download
+func (p *downloadProgress) Done() {
+	p.report()
+	fmt.Fprintln(p.out)
+}
+
+// report overwrites the progress line with the received size
+func (p *downloadProgress) report() {
+	p.reported = time.Now()
+	if p.total > 0 {
+		fmt.Fprintf(p.out, "\r📥 Downloaded %s of %s (%d%%)", formatSize(p.received), formatSize(p.total), p.received*100/p.total)
+		return
+	}
+	fmt.Fprintf(p.out, "\r📥 Downloaded %s", formatSize(p.received))
+}
+
+// formatSize formats a size in bytes with a binary unit
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,248 @@
+package saidata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"sai/internal/platform"
+	"sai/internal/types"
+)
+
+// Layers a field of the effective saidata comes from
+const (
+	SourceBase     = "base"
+	SourceOverride = "override"
+	SourceBoth     = "base+override" // Same value in the base and the OS override
+	SourceResolved = "resolved"      // Set while resolving, e.g. by an architecture override
+	SourceDropped  = "dropped"       // Declared by a layer but absent from the effective saidata
+)
+
+// GeneratedDefaults is the base path of software without saidata
+const GeneratedDefaults = "generated defaults"
+
+// MergeLayers holds the layers merged into the effective saidata of a software: the
+// base definition, the OS overrides merged into it, and the result after resolving the
+// architecture overrides
+type MergeLayers struct {
+	Software      string
+	OS            string
+	Architecture  string
+	BasePath      string         // GeneratedDefaults for software without saidata
+	Overrides     []OverrideFile // In merge order, see definitionFiles
+	OverridePath  string         // The most specific override, empty when none applies
+	OverrideLabel string         // Labels of the overrides joined with " + ", e.g. debian + ubuntu/22.04
+	Base          *types.SoftwareData
+	Override      *types.SoftwareData // The overrides merged together, nil when none applies
+	Merged        *types.SoftwareData
+}
+
+// FieldProvenance is the value of a saidata field in each layer and the layer its
+// effective value comes from. Values are nil in the layers not declaring the field.
+type FieldProvenance struct {
+	Path     string  `json:"path"` // e.g. packages[nginx].package_name
+	Base     *string `json:"base,omitempty"`
+	Override *string `json:"override,omitempty"`
+	Merged   *string `json:"merged,omitempty"`
+	Source   string  `json:"source"`
+}
+
+// Changed reports whether the effective value of the field is not the base one
+func (f FieldProvenance) Changed() bool {
+	return f.Source != SourceBase && f.Source != SourceBoth
+}
+
+// LoadSoftwareLayers loads the layers of the saidata of a software as LoadSoftware
+// merges them, for osInfo or the detected OS when nil. The definitions of repositories
+// of higher priority than the base one are layers of the override. The cache is bypassed, and an
+// OS override failing to load is an error instead of being skipped.
+func (m *Manager) LoadSoftwareLayers(name string, osInfo *platform.OSInfo) (*MergeLayers, error) {
+	if osInfo == nil {
+		detected, err := platform.Detect()
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect the OS: %w", err)
+		}
+		osInfo = detected
+	}
+
+	layers := &MergeLayers{Software: name, OS: osInfo.OS, Architecture: osInfo.Architecture}
+	basePath, files := m.definitionFiles(name, osInfo)
+	layers.BasePath = basePath
+
+	// Generated defaults have no OS overrides
+	if layers.BasePath == "" {
+		base, err := m.GenerateDefaults(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate defaults for software '%s': %w", name, err)
+		}
+		layers.BasePath = GeneratedDefaults
+		layers.Base, layers.Merged = base, base
+		return layers, nil
+	}
+
+	base, err := m.loadSaidataFile(layers.BasePath)
+	if err != nil {
+		return nil, err
+	}
+	layers.Base, layers.Merged = base, base
+
+	var labels []string
+	for _, file := range files {
+		override, err := m.loadSaidataFile(file.Path)
+		if err != nil {
+			return nil, err
+		}
+		if layers.Override == nil {
+			layers.Override = override
+		} else {
+			layers.Override = m.mergeSaidata(layers.Override, override)
+		}
+		layers.Merged = m.mergeSaidata(layers.Merged, override)
+		layers.Overrides = append(layers.Overrides, file)
+		layers.OverridePath = file.Path
+		labels = append(labels, file.Label)
+	}
+	layers.OverrideLabel = strings.Join(labels, " + ")
+	layers.Merged = resolveArchitecture(layers.Merged, osInfo.Architecture)
+	return layers, nil
+}
+
+// Provenance returns the fields of the layers in the order of the effective saidata,
+// followed by the fields it dropped
+func (l *MergeLayers) Provenance() []FieldProvenance {
+	basePaths, base := flattenSaidata(l.Base)
+	overridePaths, override := flattenSaidata(l.Override)
+	mergedPaths, merged := flattenSaidata(l.Merged)
+
+	var fields []FieldProvenance
+	seen := make(map[string]bool)
+	lookup := func(values map[string]string, path string) *string {
+		if value, exists := values[path]; exists {
+			return &value
+		}
+		return nil
+	}
+	for _, paths := range [][]string{mergedPaths, overridePaths, basePaths} {
+		for _, path := range paths {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			field := FieldProvenance{
+				Path:     path,
+				Base:     lookup(base, path),
+				Override: lookup(override, path),
+				Merged:   lookup(merged, path),
+			}
+			field.Source = fieldSource(field)
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// AnnotatedYAML returns the effective saidata as YAML, with the fields not coming from
+// the base definition commented with their source
+func (l *MergeLayers) AnnotatedYAML() ([]byte, error) {
+	sources := make(map[string]string)
+	for _, field := range l.Provenance() {
+		if field.Changed() {
+			sources[field.Path] = field.Source
+		}
+	}
+
+	var node yaml.Node
+	if err := node.Encode(l.Merged); err != nil {
+		return nil, fmt.Errorf("failed to encode saidata: %w", err)
+	}
+	walkLeaves(&node, "", func(path string, leaf *yaml.Node) {
+		if source, exists := sources[path]; exists {
+			leaf.LineComment = source
+			if source == SourceOverride && l.OverrideLabel != "" {
+				leaf.LineComment = source + " " + l.OverrideLabel
+			}
+		}
+	})
+	data, err := yaml.Marshal(&node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode saidata: %w", err)
+	}
+	return FormatSaidataYAML(data)
+}
+
+// fieldSource returns the layer the effective value of a field comes from
+func fieldSource(field FieldProvenance) string {
+	switch {
+	case field.Merged == nil:
+		return SourceDropped
+	case field.Override != nil && *field.Override == *field.Merged:
+		if field.Base != nil && *field.Base == *field.Merged {
+			return SourceBoth
+		}
+		return SourceOverride
+	case field.Base != nil && *field.Base == *field.Merged:
+		return SourceBase
+	default:
+		return SourceResolved
+	}
+}
+
+// flattenSaidata returns the paths of the leaf fields of saidata in document order,
+// and their values
+func flattenSaidata(data *types.SoftwareData) ([]string, map[string]string) {
+	values := make(map[string]string)
+	if data == nil {
+		return nil, values
+	}
+
+	var node yaml.Node
+	if err := node.Encode(data); err != nil {
+		return nil, values
+	}
+	var paths []string
+	walkLeaves(&node, "", func(path string, leaf *yaml.Node) {
+		paths = append(paths, path)
+		values[path] = leaf.Value
+	})
+	return paths, values
+}
+
+// walkLeaves calls visit with the path of every scalar of a YAML tree. Sequence items
+// are identified by their name, as the merge matches them by name, or else by index.
+func walkLeaves(node *yaml.Node, path string, visit func(path string, leaf *yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkLeaves(child, path, visit)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path != "" {
+				key = path + "." + key
+			}
+			walkLeaves(node.Content[i+1], key, visit)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			walkLeaves(item, path+"["+itemKey(item, i)+"]", visit)
+		}
+	case yaml.AliasNode:
+		walkLeaves(node.Alias, path, visit)
+	case yaml.ScalarNode:
+		visit(path, node)
+	}
+}
+
+// itemKey returns the name of a sequence item, or its index when it has none
+func itemKey(item *yaml.Node, index int) string {
+	if item.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "name" && item.Content[i+1].Kind == yaml.ScalarNode {
+				return item.Content[i+1].Value
+			}
+		}
+	}
+	return strconv.Itoa(index)
+}
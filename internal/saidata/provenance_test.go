@@ -0,0 +1,156 @@
+package saidata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/platform"
+)
+
+func TestLoadSoftwareLayers(t *testing.T) {
+	tempDir := t.TempDir()
+	nginxDir := filepath.Join(tempDir, "software", "ng", "nginx")
+	require.NoError(t, os.MkdirAll(filepath.Join(nginxDir, "ubuntu"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(nginxDir, "default.yaml"), []byte(`version: "0.2"
+metadata:
+  name: nginx
+  description: HTTP server
+packages:
+  - name: nginx
+    package_name: nginx
+services:
+  - name: nginx
+    service_name: nginx
+providers:
+  apt:
+    packages:
+      - name: nginx
+        package_name: nginx
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(nginxDir, "ubuntu", "22.04.yaml"), []byte(`version: "0.2"
+metadata:
+  name: nginx
+packages:
+  - name: nginx
+    package_name: nginx-full
+providers:
+  apt:
+    packages:
+      - name: nginx
+        package_name: nginx-full
+`), 0644))
+
+	osInfo := &platform.OSInfo{Platform: "linux", OS: "ubuntu", Version: "22.04", Architecture: "amd64"}
+	layers, err := NewManager(tempDir).LoadSoftwareLayers("nginx", osInfo)
+	require.NoError(t, err)
+	assert.Equal(t, "ubuntu/22.04", layers.OverrideLabel)
+	assert.Equal(t, "nginx", layers.Base.Providers["apt"].Packages[0].PackageName, "the base is left untouched by the merge")
+
+	sources := make(map[string]FieldProvenance)
+	for _, field := range layers.Provenance() {
+		sources[field.Path] = field
+	}
+	assert.Equal(t, SourceOverride, sources["packages[nginx].package_name"].Source)
+	assert.Equal(t, "nginx", *sources["packages[nginx].package_name"].Base)
+	assert.Equal(t, "nginx-full", *sources["packages[nginx].package_name"].Merged)
+	assert.Equal(t, SourceOverride, sources["providers.apt.packages[nginx].package_name"].Source)
+	assert.Equal(t, SourceBoth, sources["metadata.name"].Source)
+	assert.Equal(t, SourceBase, sources["metadata.description"].Source)
+	assert.Equal(t, SourceBase, sources["services[nginx].service_name"].Source)
+	assert.False(t, sources["services[nginx].service_name"].Changed())
+
+	annotated, err := layers.AnnotatedYAML()
+	require.NoError(t, err)
+	assert.Contains(t, string(annotated), "package_name: nginx-full # override ubuntu/22.04")
+	assert.False(t, strings.Contains(string(annotated), "description: HTTP server #"), "base fields are not annotated")
+
+	// Other OS versions get the base definition only
+	osInfo.OS = "debian"
+	layers, err = NewManager(tempDir).LoadSoftwareLayers("nginx", osInfo)
+	require.NoError(t, err)
+	assert.Empty(t, layers.OverridePath)
+	for _, field := range layers.Provenance() {
+		assert.Equal(t, SourceBase, field.Source, field.Path)
+	}
+}
+
+func TestLoadSoftwareLayersOverrideMatrix(t *testing.T) {
+	tempDir := t.TempDir()
+	nginxDir := filepath.Join(tempDir, "software", "ng", "nginx")
+	write := func(file, packageName string) {
+		path := filepath.Join(nginxDir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(`version: "0.2"
+metadata:
+  name: nginx
+packages:
+  - name: nginx
+    package_name: `+packageName+`
+`), 0644))
+	}
+	write("default.yaml", "nginx")
+	write("linux/default.yaml", "nginx-linux")
+	write("linux/x86_64.yaml", "nginx-linux-amd64")
+	write("debian/default.yaml", "nginx-debian")
+	write("ubuntu/default.yaml", "nginx-ubuntu")
+	write("ubuntu/22.04.yaml", "nginx-ubuntu-22.04")
+	write("ubuntu/arm64.yaml", "nginx-ubuntu-arm64")
+	write("rhel/default.yaml", "nginx-rhel")
+
+	tests := []struct {
+		name   string
+		osInfo *platform.OSInfo
+		labels []string
+		pkg    string
+	}{
+		{
+			name:   "version then architecture",
+			osInfo: &platform.OSInfo{Platform: "linux", OS: "ubuntu", Version: "22.04", Architecture: "arm64"},
+			labels: []string{"linux", "debian", "ubuntu/22.04", "ubuntu/arm64"},
+			pkg:    "nginx-ubuntu-arm64",
+		},
+		{
+			name:   "architecture alias",
+			osInfo: &platform.OSInfo{Platform: "linux", OS: "ubuntu", Version: "24.04", Architecture: "amd64"},
+			labels: []string{"linux", "linux/x86_64", "debian", "ubuntu"},
+			pkg:    "nginx-ubuntu",
+		},
+		{
+			name:   "parent distributions from ID_LIKE",
+			osInfo: &platform.OSInfo{Platform: "linux", OS: "pop", Version: "22.04", Architecture: "arm64", IDLike: []string{"ubuntu", "debian"}},
+			labels: []string{"linux", "debian", "ubuntu", "ubuntu/arm64"},
+			pkg:    "nginx-ubuntu-arm64",
+		},
+		{
+			name:   "family of the distribution",
+			osInfo: &platform.OSInfo{Platform: "linux", OS: "rocky", Version: "9", Architecture: "arm64"},
+			labels: []string{"linux", "rhel"},
+			pkg:    "nginx-rhel",
+		},
+		{
+			name:   "other platform",
+			osInfo: &platform.OSInfo{Platform: "darwin", OS: "macos", Version: "14", Architecture: "arm64"},
+			pkg:    "nginx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layers, err := NewManager(tempDir).LoadSoftwareLayers("nginx", tt.osInfo)
+			require.NoError(t, err)
+
+			var labels []string
+			for _, override := range layers.Overrides {
+				labels = append(labels, override.Label)
+			}
+			assert.Equal(t, tt.labels, labels)
+			assert.Equal(t, strings.Join(tt.labels, " + "), layers.OverrideLabel)
+			assert.Equal(t, tt.pkg, layers.Merged.Packages[0].PackageName)
+			assert.Equal(t, "nginx", layers.Base.Packages[0].PackageName)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package saidata
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sai/internal/platform"
+	"sai/internal/types"
+)
+
+// DefaultRepository is the name of the repository a manager is created with
+const DefaultRepository = "default"
+
+// Repository is a directory of saidata searched by a manager. The definitions of a
+// repository are merged over those of the repositories of lower priority, so that a
+// company-internal repository can override or complete upstream definitions.
+type Repository struct {
+	Name     string
+	Dir      string
+	Priority int // The repository the manager is created with has priority 0
+}
+
+// SetRepositories sets the repositories searched besides the one the manager was
+// created with. Repositories of equal priority are searched in the given order, before
+// the manager one.
+func (m *Manager) SetRepositories(repositories []Repository) {
+	ordered := append([]Repository(nil), repositories...)
+	ordered = append(ordered, Repository{Name: DefaultRepository, Dir: m.saidataDir})
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Priority > ordered[j].Priority
+	})
+
+	m.cacheMutex.Lock()
+	defer m.cacheMutex.Unlock()
+	m.repositories = ordered
+	m.cache = make(map[string]*types.SoftwareData)
+}
+
+// Repositories returns the repositories searched, the highest priority first
+func (m *Manager) Repositories() []Repository {
+	if m.repositories == nil {
+		return []Repository{{Name: DefaultRepository, Dir: m.saidataDir}}
+	}
+	return m.repositories
+}
+
+// repositorySoftwareDir returns the directory of a software in a repository,
+// software/{prefix}/{name} or {prefix}/{name} for backward compatibility, empty when
+// it has none
+func repositorySoftwareDir(repositoryDir, name string) string {
+	prefix := generatePrefix(name)
+	for _, dir := range []string{
+		filepath.Join(repositoryDir, "software", prefix, name),
+		filepath.Join(repositoryDir, prefix, name),
+	} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+// definitionFiles returns the base definition of a software and the files merged into
+// it in order: the definition then the overrides for osInfo of each repository, from
+// the lowest priority to the highest. The base is the definition of the lowest priority
+// repository defining the software, empty when none does. Files of repositories other
+// than the default one are labelled with the repository name, e.g. internal:default or
+// internal:ubuntu/22.04. No override is returned when osInfo is nil.
+func (m *Manager) definitionFiles(name string, osInfo *platform.OSInfo) (string, []OverrideFile) {
+	prefix := generatePrefix(name)
+	repositories := m.Repositories()
+
+	var base string
+	var files []OverrideFile
+	for i := len(repositories) - 1; i >= 0; i-- {
+		repository := repositories[i]
+		labelPrefix := ""
+		if repository.Name != DefaultRepository {
+			labelPrefix = repository.Name + ":"
+		}
+
+		dir := repositorySoftwareDir(repository.Dir, name)
+		if dir == "" {
+			continue
+		}
+		definition := filepath.Join(dir, "default.yaml")
+		if _, err := os.Stat(definition); err == nil {
+			if base == "" {
+				base = definition
+			} else {
+				files = append(files, OverrideFile{Path: definition, Label: labelPrefix + "default"})
+			}
+		}
+		// Overrides apply to the definition of the repository or of one of lower priority
+		if osInfo == nil || base == "" {
+			continue
+		}
+		for _, override := range m.findOverrides(repository.Dir, prefix, name, osInfo) {
+			override.Label = labelPrefix + override.Label
+			files = append(files, override)
+		}
+	}
+	return base, files
+}
@@ -0,0 +1,87 @@
+package saidata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/platform"
+)
+
+// writeDefinition writes a saidata file of a repository
+func writeDefinition(t *testing.T, repositoryDir, file, content string) {
+	path := filepath.Join(repositoryDir, file)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestManager_Repositories(t *testing.T) {
+	upstream, internal, fallback := t.TempDir(), t.TempDir(), t.TempDir()
+	writeDefinition(t, upstream, "software/ng/nginx/default.yaml", `version: "0.2"
+metadata:
+  name: nginx
+  description: HTTP server
+packages:
+  - name: nginx
+    package_name: nginx
+`)
+	writeDefinition(t, upstream, "software/ng/nginx/ubuntu/default.yaml", `version: "0.2"
+metadata:
+  name: nginx
+packages:
+  - name: nginx
+    package_name: nginx-full
+`)
+	writeDefinition(t, internal, "ng/nginx/default.yaml", `version: "0.2"
+metadata:
+  name: nginx
+packages:
+  - name: nginx
+    package_name: acme-nginx
+`)
+	writeDefinition(t, fallback, "software/ng/nginx/default.yaml", `version: "0.2"
+metadata:
+  name: nginx
+  description: Fallback definition
+`)
+	writeDefinition(t, fallback, "software/to/tool/default.yaml", `version: "0.2"
+metadata:
+  name: tool
+`)
+
+	manager := NewManager(upstream)
+	manager.SetRepositories([]Repository{
+		{Name: "fallback", Dir: fallback, Priority: -1},
+		{Name: "internal", Dir: internal, Priority: 10},
+	})
+	var names []string
+	for _, repository := range manager.Repositories() {
+		names = append(names, repository.Name)
+	}
+	assert.Equal(t, []string{"internal", DefaultRepository, "fallback"}, names)
+
+	osInfo := &platform.OSInfo{Platform: "linux", OS: "ubuntu", Version: "22.04", Architecture: "amd64"}
+	layers, err := manager.LoadSoftwareLayers("nginx", osInfo)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(fallback, "software/ng/nginx/default.yaml"), layers.BasePath,
+		"the lowest priority definition is the base")
+	assert.Equal(t, "default + ubuntu + internal:default", layers.OverrideLabel)
+	assert.Equal(t, "acme-nginx", layers.Merged.Packages[0].PackageName, "higher priority repositories win")
+	assert.Equal(t, "HTTP server", layers.Merged.Metadata.Description)
+
+	software, err := manager.LoadSoftware("nginx")
+	require.NoError(t, err)
+	assert.Equal(t, "acme-nginx", software.Packages[0].PackageName)
+
+	list, err := manager.GetSoftwareList()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"nginx", "tool"}, list, "software defined by several repositories is listed once")
+
+	// Without other repositories only the manager one is searched
+	layers, err = NewManager(upstream).LoadSoftwareLayers("nginx", osInfo)
+	require.NoError(t, err)
+	assert.Equal(t, "ubuntu", layers.OverrideLabel)
+	assert.Equal(t, "nginx-full", layers.Merged.Packages[0].PackageName)
+}
@@ -2,8 +2,10 @@ package saidata
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
 	"os/exec"
@@ -19,6 +21,11 @@ type RepositoryManager struct {
 	zipFallbackURL string
 	localPath      string
 	isRoot         bool
+	sparse         SparseFilter
+	verification   Verification
+	progress       io.Writer  // Receives the messages and progress of downloads
+	diskCache      *DiskCache // Saidata cache cleared when the repository changes
+	offline        bool       // Refuse network fetches, saidata only comes from bundles
 }
 
 // RepositoryStatus represents the current status of the saidata repository
@@ -43,9 +50,25 @@ func NewRepositoryManager(gitURL, zipFallbackURL string) *RepositoryManager {
 		zipFallbackURL: zipFallbackURL,
 		localPath:      localPath,
 		isRoot:         os.Getuid() == 0,
+		progress:       os.Stdout,
 	}
 }
 
+// SetProgress sets where the messages and progress of downloads are written, nil
+// discards them
+func (rm *RepositoryManager) SetProgress(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+	rm.progress = w
+}
+
+// SetLocalPath sets the directory of the local copy of the repository, GetSaidataPath
+// by default
+func (rm *RepositoryManager) SetLocalPath(path string) {
+	rm.localPath = path
+}
+
 // GetSaidataPath returns the appropriate saidata directory path
 func GetSaidataPath() string {
 	// Check if running as root
@@ -93,60 +116,84 @@ func (rm *RepositoryManager) IsFirstRun() bool {
 
 // ShowWelcomeMessage displays a welcome message for first-time users
 func (rm *RepositoryManager) ShowWelcomeMessage() error {
-	fmt.Println("🎉 Welcome to SAI - Software Action Interface!")
-	fmt.Println()
-	fmt.Println("SAI provides a unified interface for managing software across different")
-	fmt.Println("operating systems and package managers. To get started, SAI needs to")
-	fmt.Println("download the saidata repository containing software definitions.")
-	fmt.Println()
+	fmt.Fprintln(rm.progress, "🎉 Welcome to SAI - Software Action Interface!")
+	fmt.Fprintln(rm.progress)
+	fmt.Fprintln(rm.progress, "SAI provides a unified interface for managing software across different")
+	fmt.Fprintln(rm.progress, "operating systems and package managers. To get started, SAI needs to")
+	fmt.Fprintln(rm.progress, "download the saidata repository containing software definitions.")
+	fmt.Fprintln(rm.progress)
 	
 	if rm.isRoot {
-		fmt.Printf("📁 Installing saidata to system directory: %s\n", rm.localPath)
+		fmt.Fprintf(rm.progress, "📁 Installing saidata to system directory: %s\n", rm.localPath)
 	} else {
-		fmt.Printf("📁 Installing saidata to user directory: %s\n", rm.localPath)
+		fmt.Fprintf(rm.progress, "📁 Installing saidata to user directory: %s\n", rm.localPath)
 	}
 	
-	fmt.Println("🔄 Downloading saidata repository...")
-	fmt.Println()
+	fmt.Fprintln(rm.progress, "🔄 Downloading saidata repository...")
+	fmt.Fprintln(rm.progress)
 	
 	return nil
 }
 
-// InitializeRepository sets up the saidata repository for the first time
-func (rm *RepositoryManager) InitializeRepository() error {
-	// Create the directory structure
-	if err := os.MkdirAll(rm.localPath, 0755); err != nil {
+// InitializeRepository sets up the saidata repository for the first time. The
+// repository is shallow cloned, falling back to the zip download when cloning fails.
+// Both download into a staging directory that replaces the local copy only once
+// complete and verified, so a cancelled or failed download leaves nothing behind.
+func (rm *RepositoryManager) InitializeRepository(ctx context.Context) error {
+	if err := rm.checkOnline("download"); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(rm.localPath), 0755); err != nil {
 		return fmt.Errorf("failed to create saidata directory: %w", err)
 	}
 	
+	staging := *rm
+	staging.localPath = rm.localPath + ".download"
+	os.RemoveAll(staging.localPath)
+	defer os.RemoveAll(staging.localPath)
+	
 	// Try Git clone first, fallback to zip download
-	if err := rm.gitClone(); err != nil {
-		fmt.Printf("⚠️  Git clone failed: %v\n", err)
-		fmt.Println("🔄 Falling back to zip download...")
+	if err := staging.gitClone(ctx); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("saidata download cancelled: %w", ctx.Err())
+		}
+		fmt.Fprintf(rm.progress, "⚠️  Git clone failed: %v\n", err)
+		fmt.Fprintln(rm.progress, "🔄 Falling back to zip download...")
 		
-		if err := rm.zipDownload(); err != nil {
+		if err := staging.zipDownload(ctx); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("saidata download cancelled: %w", ctx.Err())
+			}
 			return fmt.Errorf("both git clone and zip download failed: %w", err)
 		}
 	}
 	
 	// Validate the downloaded repository
-	if err := rm.ValidateRepository(); err != nil {
+	if err := staging.ValidateRepository(); err != nil {
 		return fmt.Errorf("repository validation failed: %w", err)
 	}
 	
-	fmt.Println("✅ Saidata repository successfully initialized!")
-	fmt.Println()
-	fmt.Println("You can now use SAI to manage software. Try:")
-	fmt.Println("  sai install nginx")
-	fmt.Println("  sai list")
-	fmt.Println("  sai stats")
-	fmt.Println()
+	if err := os.RemoveAll(rm.localPath); err != nil {
+		return fmt.Errorf("failed to remove existing directory: %w", err)
+	}
+	if err := os.Rename(staging.localPath, rm.localPath); err != nil {
+		return fmt.Errorf("failed to install saidata repository: %w", err)
+	}
+	rm.invalidateCache()
+	
+	fmt.Fprintln(rm.progress, "✅ Saidata repository successfully initialized!")
+	fmt.Fprintln(rm.progress)
+	fmt.Fprintln(rm.progress, "You can now use SAI to manage software. Try:")
+	fmt.Fprintln(rm.progress, "  sai install nginx")
+	fmt.Fprintln(rm.progress, "  sai list")
+	fmt.Fprintln(rm.progress, "  sai stats")
+	fmt.Fprintln(rm.progress)
 	
 	return nil
 }
 
-// gitClone attempts to clone the repository using Git
-func (rm *RepositoryManager) gitClone() error {
+// gitClone attempts to clone the repository using Git, without history
+func (rm *RepositoryManager) gitClone(ctx context.Context) error {
 	// Check if git is available
 	if _, err := exec.LookPath("git"); err != nil {
 		return fmt.Errorf("git not found in PATH")
@@ -159,20 +206,36 @@ func (rm *RepositoryManager) gitClone() error {
 		}
 	}
 	
-	// Clone the repository
-	cmd := exec.Command("git", "clone", rm.gitURL, rm.localPath)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// Clone the repository. A sparse sync clones without file contents and
+	// fetches only the contents of the selected paths on checkout.
+	args := []string{"clone", "--depth", "1", "--progress"}
+	if !rm.sparse.IsEmpty() {
+		args = append(args, "--filter=blob:none", "--sparse")
+	}
+	args = append(args, rm.gitURL, rm.localPath)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = rm.progress
+	cmd.Stderr = rm.progress
 	
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("git clone failed: %w", err)
 	}
 	
+	// Never leave unverified saidata behind
+	if err := rm.verifyCommit("HEAD"); err != nil {
+		os.RemoveAll(rm.localPath)
+		return err
+	}
+	
+	if !rm.sparse.IsEmpty() {
+		return rm.applySparseCheckout()
+	}
+	
 	return nil
 }
 
 // zipDownload downloads and extracts the repository as a zip file
-func (rm *RepositoryManager) zipDownload() error {
+func (rm *RepositoryManager) zipDownload(ctx context.Context) error {
 	if rm.zipFallbackURL == "" {
 		return fmt.Errorf("no zip fallback URL configured")
 	}
@@ -186,7 +249,11 @@ func (rm *RepositoryManager) zipDownload() error {
 	defer tmpFile.Close()
 	
 	// Download the zip file
-	resp, err := http.Get(rm.zipFallbackURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rm.zipFallbackURL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid zip fallback URL: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to download zip file: %w", err)
 	}
@@ -197,12 +264,20 @@ func (rm *RepositoryManager) zipDownload() error {
 	}
 	
 	// Copy response to temporary file
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
+	progress := newDownloadProgress(rm.progress, resp.ContentLength)
+	if _, err := io.Copy(io.MultiWriter(tmpFile, progress), resp.Body); err != nil {
+		fmt.Fprintln(rm.progress)
 		return fmt.Errorf("failed to save zip file: %w", err)
 	}
+	progress.Done()
 	
 	tmpFile.Close()
 	
+	// Verify the archive before anything is extracted
+	if err := rm.verifyArchive(tmpFile.Name()); err != nil {
+		return err
+	}
+	
 	// Extract the zip file
 	if err := rm.extractZip(tmpFile.Name()); err != nil {
 		return fmt.Errorf("failed to extract zip file: %w", err)
@@ -220,6 +295,19 @@ func (rm *RepositoryManager) extractZip(zipPath string) error {
 	}
 	defer reader.Close()
 	
+	// Resolve the selected software directories before touching the local copy
+	var sparseDirs []string
+	if !rm.sparse.IsEmpty() && len(reader.File) > 0 {
+		root := strings.Split(reader.File[0].Name, "/")[0]
+		archive, err := fs.Sub(&reader.Reader, root)
+		if err != nil {
+			return fmt.Errorf("failed to open zip root directory: %w", err)
+		}
+		if sparseDirs, err = selectSoftwareDirs(archive, rm.sparse); err != nil {
+			return err
+		}
+	}
+	
 	// Remove existing directory if it exists
 	if _, err := os.Stat(rm.localPath); err == nil {
 		if err := os.RemoveAll(rm.localPath); err != nil {
@@ -246,6 +334,11 @@ func (rm *RepositoryManager) extractZip(zipPath string) error {
 			continue
 		}
 		
+		// Skip paths outside the sparse selection
+		if sparseDirs != nil && !inSparseSelection(relativePath, sparseDirs) {
+			continue
+		}
+		
 		destPath := filepath.Join(rm.localPath, relativePath)
 		
 		if file.FileInfo().IsDir() {
@@ -292,6 +385,11 @@ func (rm *RepositoryManager) UpdateRepository() error {
 	if !rm.repositoryExists() {
 		return fmt.Errorf("repository not initialized, run 'sai saidata init' first")
 	}
+	if err := rm.checkOnline("update"); err != nil {
+		return err
+	}
+	
+	defer rm.invalidateCache()
 	
 	// Check if it's a git repository
 	if rm.isGitRepository() {
@@ -300,7 +398,7 @@ func (rm *RepositoryManager) UpdateRepository() error {
 	
 	// For zip-based repositories, re-download
 	fmt.Println("🔄 Updating saidata repository (zip-based)...")
-	return rm.zipDownload()
+	return rm.zipDownload(context.Background())
 }
 
 // gitPull updates a git-based repository
@@ -317,6 +415,11 @@ func (rm *RepositoryManager) gitPull() error {
 		return fmt.Errorf("git fetch failed: %w", err)
 	}
 	
+	// Verify the fetched commit before the local copy is moved to it
+	if err := rm.verifyCommit("origin/main"); err != nil {
+		return err
+	}
+	
 	// Reset to origin/main to ensure we always use the remote main branch
 	// This handles divergent branches by discarding local changes
 	resetCmd := exec.Command("git", "reset", "--hard", "origin/main")
@@ -346,6 +449,12 @@ func (rm *RepositoryManager) gitPull() error {
 		}
 	}
 	
+	// Re-resolve the sparse selection, categories may have gained software, or restore
+	// the whole working tree when the selection was emptied
+	if err := rm.applySparseCheckout(); err != nil {
+		return err
+	}
+	
 	fmt.Println("✅ Repository updated successfully!")
 	return nil
 }
@@ -398,6 +507,11 @@ func (rm *RepositoryManager) SynchronizeRepository() error {
 	if !rm.repositoryExists() {
 		return fmt.Errorf("repository not initialized, run 'sai saidata init' first")
 	}
+	if err := rm.checkOnline("synchronize"); err != nil {
+		return err
+	}
+	
+	defer rm.invalidateCache()
 	
 	// For git repositories, force sync to main branch
 	if rm.isGitRepository() {
@@ -406,7 +520,7 @@ func (rm *RepositoryManager) SynchronizeRepository() error {
 	
 	// For zip-based repositories, re-download
 	fmt.Println("🔄 Synchronizing saidata repository (zip-based)...")
-	return rm.zipDownload()
+	return rm.zipDownload(context.Background())
 }
 
 // ValidateRepository validates the repository structure and content
@@ -518,6 +632,7 @@ func (rm *RepositoryManager) CleanRepository() error {
 	if err := os.RemoveAll(rm.localPath); err != nil {
 		return fmt.Errorf("failed to remove repository: %w", err)
 	}
+	rm.invalidateCache()
 	
 	fmt.Printf("✅ Repository cleaned: %s\n", rm.localPath)
 	return nil
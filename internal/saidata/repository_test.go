@@ -1,8 +1,15 @@
 package saidata
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -175,4 +182,69 @@ func TestIsGitRepository(t *testing.T) {
 	if !rm.isGitRepository() {
 		t.Error("Expected to be git repository")
 	}
-}
\ No newline at end of file
+}
+
+func TestInitializeRepository(t *testing.T) {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	file, _ := writer.Create("saidata-main/software/ng/nginx/default.yaml")
+	file.Write([]byte("version: \"0.2\"\n"))
+	writer.Close()
+
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing || r.URL.Path != "/main.zip" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	localPath := filepath.Join(t.TempDir(), "saidata")
+	rm := &RepositoryManager{
+		gitURL:         filepath.Join(t.TempDir(), "missing.git"),
+		zipFallbackURL: server.URL + "/main.zip",
+		localPath:      localPath,
+		verification:   Verification{Insecure: true},
+		progress:       io.Discard,
+	}
+
+	// Cloning fails, the zip fallback is downloaded
+	if err := rm.InitializeRepository(context.Background()); err != nil {
+		t.Fatalf("InitializeRepository failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(localPath, "software", "ng", "nginx", "default.yaml")); err != nil {
+		t.Errorf("Expected the archive to be extracted: %v", err)
+	}
+
+	// A failed or cancelled download keeps the local copy and leaves no staging directory
+	failing = true
+	if err := rm.InitializeRepository(context.Background()); err == nil {
+		t.Error("Expected the failed download to be reported")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rm.InitializeRepository(ctx); err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("Expected the cancellation to be reported, got %v", err)
+	}
+	if rm.IsFirstRun() {
+		t.Error("Expected the local copy to be kept")
+	}
+	if _, err := os.Stat(localPath + ".download"); !os.IsNotExist(err) {
+		t.Errorf("Expected the staging directory to be removed, got %v", err)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:             "512 B",
+		1536:            "1.5 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+	}
+	for bytes, expected := range tests {
+		if size := formatSize(bytes); size != expected {
+			t.Errorf("formatSize(%d) = %s, expected %s", bytes, size, expected)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package saidata
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"sai/internal/types"
+)
+
+// ScaffoldPath returns where the definition of a new software goes in a saidata
+// repository, relative to its root: default.yaml in the hierarchical prefix directory
+func ScaffoldPath(software string) string {
+	return filepath.Join("software", generatePrefix(software), software, "default.yaml")
+}
+
+// Scaffold returns the skeleton of a software definition from the defaults generated
+// for it and the names providers found its package under, keyed by provider. The
+// package names differing from the generated one become provider configs.
+func Scaffold(defaults *types.SoftwareData, packageNames map[string]string) *types.SoftwareData {
+	software := defaults.Metadata.Name
+	scaffold := &types.SoftwareData{
+		Version: "0.2",
+		Metadata: types.Metadata{
+			Name:        software,
+			DisplayName: defaults.Metadata.DisplayName,
+		},
+		Packages:    make([]types.Package, 0, len(defaults.Packages)),
+		Services:    defaults.Services,
+		Files:       defaults.Files,
+		Directories: defaults.Directories,
+		Commands:    defaults.Commands,
+		Ports:       defaults.Ports,
+	}
+
+	// The schema requires package_name, generated packages leave it to default to the name
+	for _, pkg := range defaults.Packages {
+		pkg.PackageName = pkg.GetPackageNameOrDefault()
+		scaffold.Packages = append(scaffold.Packages, pkg)
+	}
+	packageName := software
+	if len(scaffold.Packages) > 0 {
+		packageName = scaffold.Packages[0].PackageName
+	}
+
+	providers := make([]string, 0, len(packageNames))
+	for provider := range packageNames {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		name := packageNames[provider]
+		if name == "" || name == packageName {
+			continue
+		}
+		if scaffold.Providers == nil {
+			scaffold.Providers = make(map[string]types.ProviderConfig)
+		}
+		scaffold.Providers[provider] = types.ProviderConfig{
+			Packages: []types.Package{{Name: software, PackageName: name}},
+		}
+	}
+	return scaffold
+}
+
+// MarshalScaffold encodes a software definition skeleton as YAML with 2 space
+// indentation, headed by a comment asking to review the guessed values
+func MarshalScaffold(scaffold *types.SoftwareData) ([]byte, error) {
+	data, err := yaml.Marshal(scaffold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the saidata of %s: %w", scaffold.Metadata.Name, err)
+	}
+	formatted, err := FormatSaidataYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []string{
+		fmt.Sprintf("# Saidata of %s generated by 'sai new saidata %s'.", scaffold.Metadata.Name, scaffold.Metadata.Name),
+		"# Resources are guessed from the conventions of this host and package names from",
+		"# the providers searched: review them and add a description, license and URLs.",
+	}
+	return append([]byte(strings.Join(header, "\n")+"\n"), formatted...), nil
+}
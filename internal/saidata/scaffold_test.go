@@ -0,0 +1,62 @@
+package saidata
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sai/internal/types"
+)
+
+func TestScaffoldPath(t *testing.T) {
+	if got, want := ScaffoldPath("nginx"), filepath.Join("software", "ng", "nginx", "default.yaml"); got != want {
+		t.Errorf("ScaffoldPath(nginx) = %s, want %s", got, want)
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	defaults := &types.SoftwareData{
+		Version:     "0.2",
+		Metadata:    types.Metadata{Name: "apache", DisplayName: "Apache", Description: "Generated defaults for apache"},
+		Packages:    []types.Package{{Name: "apache"}},
+		Commands:    []types.Command{{Name: "apache2", Path: "/usr/sbin/apache2"}},
+		IsGenerated: true,
+	}
+
+	scaffold := Scaffold(defaults, map[string]string{"apt": "apache2", "dnf": "httpd", "brew": "apache"})
+
+	if scaffold.Metadata.Description != "" {
+		t.Errorf("generated description kept: %q", scaffold.Metadata.Description)
+	}
+	if scaffold.Packages[0].PackageName != "apache" {
+		t.Errorf("package_name = %q, want apache", scaffold.Packages[0].PackageName)
+	}
+	if defaults.Packages[0].PackageName != "" {
+		t.Error("defaults changed by the scaffold")
+	}
+	if len(scaffold.Providers) != 2 {
+		t.Fatalf("providers = %v, want apt and dnf only", scaffold.Providers)
+	}
+	if got := scaffold.Providers["dnf"].Packages[0]; got.Name != "apache" || got.PackageName != "httpd" {
+		t.Errorf("dnf package = %+v", got)
+	}
+
+	data, err := MarshalScaffold(scaffold)
+	if err != nil {
+		t.Fatalf("MarshalScaffold failed: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# Saidata of apache generated by 'sai new saidata apache'.\n") {
+		t.Errorf("missing header comment:\n%s", data)
+	}
+	if !strings.Contains(string(data), "packages:\n  - name: apache\n    package_name: apache\n") {
+		t.Errorf("not indented with 2 spaces:\n%s", data)
+	}
+
+	loaded, err := types.LoadSoftwareDataFromYAML(data)
+	if err != nil {
+		t.Fatalf("scaffold does not load: %v", err)
+	}
+	if loaded.GetProviderConfig("apt").Packages[0].PackageName != "apache2" {
+		t.Errorf("apt package name lost: %+v", loaded.GetProviderConfig("apt"))
+	}
+}
@@ -0,0 +1,223 @@
+package saidata
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Signature verification methods
+const (
+	VerifyGPG      = "gpg"      // Signed git commits and detached .asc signatures of archives
+	VerifySigstore = "sigstore" // gitsign commits and cosign .sigstore.json bundles of archives
+)
+
+// Verification sets how the signatures of downloaded saidata are checked. Saidata
+// failing verification, or downloaded without verification configured, is refused
+// unless Insecure is set.
+type Verification struct {
+	Method   string
+	Keyring  string // gpg: keyring of the trusted keys, checked with gpgv
+	Identity string // sigstore: certificate identity of the signer
+	Issuer   string // sigstore: OIDC issuer of the signer identity
+	Insecure bool   // Accept unsigned or tampered saidata with a warning
+}
+
+// SetVerification sets how downloaded saidata is verified
+func (rm *RepositoryManager) SetVerification(verification Verification) {
+	rm.verification = verification
+}
+
+// configured fails unless the verification names what it trusts: a keyring of its own
+// for gpg, as the default keyring of the user trusts any key imported for other uses,
+// or a signer identity for sigstore
+func (v Verification) configured() error {
+	switch v.Method {
+	case "":
+		return fmt.Errorf("signature verification is not configured, set repository.signature")
+	case VerifyGPG:
+		if v.Keyring == "" {
+			return fmt.Errorf("gpg verification requires repository.signature.keyring, a keyring of the trusted keys")
+		}
+	case VerifySigstore:
+		if v.Identity == "" || v.Issuer == "" {
+			return fmt.Errorf("sigstore verification requires repository.signature.identity and issuer")
+		}
+	default:
+		return fmt.Errorf("unknown signature verification method %s", v.Method)
+	}
+	return nil
+}
+
+// checkSignature runs a signature check, turning a failure into a warning in insecure mode
+func (rm *RepositoryManager) checkSignature(what string, check func() error) error {
+	err := rm.verification.configured()
+	if err == nil {
+		if err = check(); err == nil {
+			fmt.Printf("🔏 Verified %s signature of %s\n", rm.verification.Method, what)
+			return nil
+		}
+	}
+	if rm.verification.Insecure {
+		fmt.Printf("⚠️  Signature verification of %s failed, continuing with --insecure-saidata: %v\n", what, err)
+		return nil
+	}
+	return fmt.Errorf("refusing unverified saidata %s: %w (use --insecure-saidata to accept it)", what, err)
+}
+
+// verifyCommit checks the signature of a commit of the local repository
+func (rm *RepositoryManager) verifyCommit(rev string) error {
+	return rm.checkSignature("commit "+rev, func() error {
+		if rm.verification.Method == VerifySigstore {
+			return runVerifier(rm.localPath, "gitsign", "verify",
+				"--certificate-identity="+rm.verification.Identity,
+				"--certificate-oidc-issuer="+rm.verification.Issuer,
+				rev)
+		}
+
+		cmd := exec.Command("git", "cat-file", "commit", rev)
+		cmd.Dir = rm.localPath
+		object, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("failed to read commit %s: %w", rev, err)
+		}
+		payload, signature, err := splitSignedCommit(object)
+		if err != nil {
+			return err
+		}
+		return rm.verifyGPG(payload, signature)
+	})
+}
+
+// verifyArchive checks the detached signature published next to a downloaded archive
+func (rm *RepositoryManager) verifyArchive(archivePath string) error {
+	return rm.checkSignature("archive "+rm.zipFallbackURL, func() error {
+		if rm.verification.Method == VerifySigstore {
+			bundle := archivePath + ".sigstore.json"
+			if err := downloadFile(rm.zipFallbackURL+".sigstore.json", bundle); err != nil {
+				return err
+			}
+			defer os.Remove(bundle)
+			return runVerifier("", "cosign", "verify-blob",
+				"--bundle", bundle,
+				"--certificate-identity", rm.verification.Identity,
+				"--certificate-oidc-issuer", rm.verification.Issuer,
+				archivePath)
+		}
+
+		signature := archivePath + ".asc"
+		if err := downloadFile(rm.zipFallbackURL+".asc", signature); err != nil {
+			return err
+		}
+		defer os.Remove(signature)
+		return runVerifier("", "gpgv", rm.gpgvArgs(signature, archivePath)...)
+	})
+}
+
+// verifyGPG checks a detached GPG signature of payload
+func (rm *RepositoryManager) verifyGPG(payload, signature []byte) error {
+	dir, err := os.MkdirTemp("", "sai-signature-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	payloadPath := filepath.Join(dir, "payload")
+	signaturePath := filepath.Join(dir, "payload.asc")
+	if err := os.WriteFile(payloadPath, payload, 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(signaturePath, signature, 0600); err != nil {
+		return err
+	}
+	return runVerifier("", "gpgv", rm.gpgvArgs(signaturePath, payloadPath)...)
+}
+
+// gpgvArgs returns the gpgv arguments verifying a detached signature against the
+// configured keyring only
+func (rm *RepositoryManager) gpgvArgs(signaturePath, dataPath string) []string {
+	keyring, err := filepath.Abs(rm.verification.Keyring)
+	if err != nil {
+		keyring = rm.verification.Keyring
+	}
+	// gpgv looks a relative keyring up in its home directory
+	return []string{"--keyring", keyring, signaturePath, dataPath}
+}
+
+// splitSignedCommit splits a raw git commit object into the signed payload and its
+// signature, taken from the gpgsig header
+func splitSignedCommit(object []byte) ([]byte, []byte, error) {
+	// The headers end with the first empty line, the message follows
+	headerEnd := bytes.Index(object, []byte("\n\n")) + 1
+	if headerEnd == 0 {
+		headerEnd = len(object)
+	}
+
+	var payload, signature bytes.Buffer
+	inSignature := false
+	for _, line := range strings.SplitAfter(string(object[:headerEnd]), "\n") {
+		switch {
+		case strings.HasPrefix(line, "gpgsig "):
+			inSignature = true
+			signature.WriteString(strings.TrimPrefix(line, "gpgsig "))
+		case inSignature && strings.HasPrefix(line, " "):
+			signature.WriteString(strings.TrimPrefix(line, " "))
+		default:
+			inSignature = false
+			payload.WriteString(line)
+		}
+	}
+	payload.Write(object[headerEnd:])
+
+	if signature.Len() == 0 {
+		return nil, nil, fmt.Errorf("commit is not signed")
+	}
+	if !strings.HasSuffix(signature.String(), "\n") {
+		signature.WriteString("\n")
+	}
+	return payload.Bytes(), signature.Bytes(), nil
+}
+
+// runVerifier runs a signature verification tool, reporting its output on failure
+func runVerifier(dir, name string, args ...string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s not found in PATH", name)
+	}
+
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s verification failed: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// downloadFile downloads url to path
+func downloadFile(url, path string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		return fmt.Errorf("failed to save %s: %w", url, err)
+	}
+	return nil
+}
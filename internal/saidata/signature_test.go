@@ -0,0 +1,117 @@
+package saidata
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitSignedCommit(t *testing.T) {
+	object := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"author A <a@example.com> 1700000000 +0000\n" +
+		"committer A <a@example.com> 1700000000 +0000\n" +
+		"gpgsig -----BEGIN PGP SIGNATURE-----\n" +
+		" \n" +
+		" iHUEABYKAB0WIQ\n" +
+		" -----END PGP SIGNATURE-----\n" +
+		"\n" +
+		"Update saidata\n"
+
+	payload, signature, err := splitSignedCommit([]byte(object))
+	if err != nil {
+		t.Fatalf("splitSignedCommit failed: %v", err)
+	}
+
+	expectedPayload := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"author A <a@example.com> 1700000000 +0000\n" +
+		"committer A <a@example.com> 1700000000 +0000\n" +
+		"\n" +
+		"Update saidata\n"
+	if string(payload) != expectedPayload {
+		t.Errorf("Unexpected payload:\n%s", payload)
+	}
+
+	expectedSignature := "-----BEGIN PGP SIGNATURE-----\n\niHUEABYKAB0WIQ\n-----END PGP SIGNATURE-----\n"
+	if string(signature) != expectedSignature {
+		t.Errorf("Unexpected signature:\n%s", signature)
+	}
+
+	if _, _, err := splitSignedCommit([]byte("tree abc\n\nUnsigned\n")); err == nil {
+		t.Error("Expected unsigned commit to be rejected")
+	}
+}
+
+func TestVerifyCommit(t *testing.T) {
+	for _, tool := range []string{"git", "gpg", "gpgv"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available", tool)
+		}
+	}
+
+	tmpDir := t.TempDir()
+	gnupgHome := filepath.Join(tmpDir, "gnupg")
+	if err := os.Mkdir(gnupgHome, 0700); err != nil {
+		t.Fatalf("Failed to create GNUPGHOME: %v", err)
+	}
+	t.Setenv("GNUPGHOME", gnupgHome)
+
+	run := func(dir string, name string, args ...string) string {
+		cmd := exec.Command(name, args...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s %s failed: %v\n%s", name, strings.Join(args, " "), err, output)
+		}
+		return string(output)
+	}
+
+	run(tmpDir, "gpg", "--batch", "--passphrase", "", "--quick-gen-key", "Saidata Test <saidata@example.com>", "ed25519", "sign", "never")
+
+	repo := filepath.Join(tmpDir, "saidata")
+	run(tmpDir, "git", "init", "-q", repo)
+	run(repo, "git", "config", "user.name", "Saidata Test")
+	run(repo, "git", "config", "user.email", "saidata@example.com")
+	run(repo, "git", "config", "user.signingkey", "saidata@example.com")
+	run(repo, "git", "commit", "-q", "-S", "--allow-empty", "-m", "Signed")
+
+	keyring := filepath.Join(tmpDir, "trusted.gpg")
+	run(tmpDir, "gpg", "--batch", "--output", keyring, "--export", "saidata@example.com")
+
+	// The default keyring of the user is not trusted
+	rm := &RepositoryManager{localPath: repo, verification: Verification{Method: VerifyGPG}}
+	if err := rm.verifyCommit("HEAD"); err == nil {
+		t.Error("Expected verification without a keyring to be refused")
+	}
+
+	rm.verification.Keyring = keyring
+	if err := rm.verifyCommit("HEAD"); err != nil {
+		t.Errorf("Expected signed commit to verify: %v", err)
+	}
+
+	run(repo, "git", "commit", "-q", "--no-gpg-sign", "--allow-empty", "-m", "Unsigned")
+	if err := rm.verifyCommit("HEAD"); err == nil {
+		t.Error("Expected unsigned commit to be refused")
+	}
+
+	// A keyring without the signing key does not trust the signature
+	rm.verification.Keyring = filepath.Join(tmpDir, "empty.gpg")
+	if err := rm.verifyCommit("HEAD~1"); err == nil {
+		t.Error("Expected commit signed by an untrusted key to be refused")
+	}
+
+	rm.verification.Insecure = true
+	if err := rm.verifyCommit("HEAD"); err != nil {
+		t.Errorf("Expected unsigned commit to be accepted in insecure mode: %v", err)
+	}
+
+	rm.verification = Verification{}
+	if err := rm.verifyCommit("HEAD"); err == nil {
+		t.Error("Expected saidata to be refused without verification")
+	}
+	rm.verification.Insecure = true
+	if err := rm.verifyCommit("HEAD"); err != nil {
+		t.Errorf("Expected saidata to be accepted without verification in insecure mode: %v", err)
+	}
+}
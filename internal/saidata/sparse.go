@@ -0,0 +1,187 @@
+package saidata
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"sai/internal/types"
+)
+
+// SparseFilter selects the software of the saidata repository to sync. A software is
+// selected when its name is listed in Software or its metadata category is listed in
+// Categories. An empty filter selects the whole repository.
+type SparseFilter struct {
+	Software   []string
+	Categories []string
+}
+
+// IsEmpty reports whether the filter selects the whole repository
+func (f SparseFilter) IsEmpty() bool {
+	return len(f.Software) == 0 && len(f.Categories) == 0
+}
+
+// candidateDirs returns the directories of the software listed by name, in both the
+// software/{prefix}/{software} and the {prefix}/{software} layouts
+func (f SparseFilter) candidateDirs() []string {
+	var dirs []string
+	for _, name := range f.Software {
+		prefix := generatePrefix(name)
+		dirs = append(dirs, path.Join("software", prefix, name), path.Join(prefix, name))
+	}
+	return dirs
+}
+
+// matches reports whether the software with the given name and category is selected
+func (f SparseFilter) matches(name, category string) bool {
+	for _, software := range f.Software {
+		if software == name {
+			return true
+		}
+	}
+	for _, selected := range f.Categories {
+		if category != "" && strings.EqualFold(selected, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetSparseFilter limits syncing to the software selected by filter
+func (rm *RepositoryManager) SetSparseFilter(filter SparseFilter) {
+	rm.sparse = filter
+}
+
+// softwareDir returns the software directory of a default.yaml path in either
+// repository layout, or false when the path is not a software definition
+func softwareDir(filePath string) (string, string, bool) {
+	parts := strings.Split(filePath, "/")
+	if parts[len(parts)-1] != "default.yaml" {
+		return "", "", false
+	}
+
+	switch {
+	case len(parts) == 4 && parts[0] == "software":
+		return path.Join(parts[:3]...), parts[2], true
+	case len(parts) == 3 && parts[0] != "software":
+		return path.Join(parts[:2]...), parts[1], true
+	}
+	return "", "", false
+}
+
+// selectSoftwareDirs walks the software definitions in fsys and returns the sorted
+// directories of the software selected by filter
+func selectSoftwareDirs(fsys fs.FS, filter SparseFilter) ([]string, error) {
+	var dirs []string
+
+	err := fs.WalkDir(fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		dir, name, ok := softwareDir(filePath)
+		if !ok {
+			return nil
+		}
+
+		category := ""
+		if len(filter.Categories) > 0 {
+			data, err := fs.ReadFile(fsys, filePath)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", filePath, err)
+			}
+			// Definitions that do not parse cannot be matched by category
+			if saidata, err := types.LoadSoftwareDataFromYAML(data); err == nil {
+				category = saidata.Metadata.Category
+			}
+		}
+
+		if filter.matches(name, category) {
+			dirs = append(dirs, dir)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no saidata matches the sparse selection (software: %s, categories: %s)",
+			strings.Join(filter.Software, ", "), strings.Join(filter.Categories, ", "))
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// inSparseSelection reports whether a repository-relative path is kept by a sparse
+// sync selecting dirs. Files at the repository root are always kept.
+func inSparseSelection(relativePath string, dirs []string) bool {
+	relativePath = strings.TrimSuffix(relativePath, "/")
+	if !strings.Contains(relativePath, "/") {
+		return true
+	}
+	for _, dir := range dirs {
+		if relativePath == dir || strings.HasPrefix(relativePath, dir+"/") ||
+			strings.HasPrefix(dir, relativePath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// applySparseCheckout restricts the working tree of the git repository to the selected
+// software. Selecting by category first checks out only the software definitions to
+// read their metadata, so the other files are never fetched. An empty filter restores
+// the whole working tree.
+func (rm *RepositoryManager) applySparseCheckout() error {
+	if rm.sparse.IsEmpty() {
+		if err := rm.runGit("sparse-checkout", "disable"); err != nil {
+			return fmt.Errorf("git sparse-checkout disable failed: %w", err)
+		}
+		return nil
+	}
+
+	dirs := rm.sparse.candidateDirs()
+
+	if len(rm.sparse.Categories) > 0 {
+		if err := rm.runGit("sparse-checkout", "set", "--no-cone",
+			"/software/*/*/default.yaml", "/*/*/default.yaml"); err != nil {
+			return fmt.Errorf("git sparse-checkout failed: %w", err)
+		}
+
+		selected, err := selectSoftwareDirs(os.DirFS(rm.localPath), rm.sparse)
+		if err != nil {
+			return err
+		}
+		dirs = selected
+	}
+
+	args := append([]string{"sparse-checkout", "set", "--cone"}, dirs...)
+	if err := rm.runGit(args...); err != nil {
+		return fmt.Errorf("git sparse-checkout failed: %w", err)
+	}
+
+	fmt.Fprintf(rm.progress, "📦 Sparse sync: %d saidata directories selected\n", len(dirs))
+	return nil
+}
+
+// runGit runs a git command in the repository directory, reporting its output as
+// progress
+func (rm *RepositoryManager) runGit(args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = rm.localPath
+	cmd.Stdout = rm.progress
+	cmd.Stderr = rm.progress
+	return cmd.Run()
+}
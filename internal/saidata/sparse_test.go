@@ -0,0 +1,195 @@
+package saidata
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func sparseTestFiles() map[string]string {
+	return map[string]string{
+		"README.md":                                "# saidata",
+		"software/ng/nginx/default.yaml":           "metadata:\n  name: nginx\n  category: web-server\n",
+		"software/ng/nginx/ubuntu/22.04.yaml":      "metadata:\n  name: nginx\n",
+		"software/ap/apache/default.yaml":          "metadata:\n  name: apache\n  category: Web-Server\n",
+		"software/my/mysql/default.yaml":           "metadata:\n  name: mysql\n  category: database\n",
+		"re/redis/default.yaml":                    "metadata:\n  name: redis\n  category: database\n",
+		"software/te/terraform/default.yaml":       "metadata:\n  name: terraform\n  category: infrastructure\n",
+		"software/te/terraform/macos/default.yaml": "metadata:\n  name: terraform\n",
+	}
+}
+
+func TestSelectSoftwareDirs(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, content := range sparseTestFiles() {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	tests := []struct {
+		name     string
+		filter   SparseFilter
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "software by name",
+			filter:   SparseFilter{Software: []string{"nginx", "redis"}},
+			expected: []string{"re/redis", "software/ng/nginx"},
+		},
+		{
+			name:     "categories are case insensitive",
+			filter:   SparseFilter{Categories: []string{"web-server"}},
+			expected: []string{"software/ap/apache", "software/ng/nginx"},
+		},
+		{
+			name:     "software and categories",
+			filter:   SparseFilter{Software: []string{"terraform"}, Categories: []string{"database"}},
+			expected: []string{"re/redis", "software/my/mysql", "software/te/terraform"},
+		},
+		{
+			name:    "no match",
+			filter:  SparseFilter{Software: []string{"unknown"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dirs, err := selectSoftwareDirs(fsys, tt.filter)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got dirs %v", dirs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dirs, tt.expected) {
+				t.Errorf("Expected dirs %v, got %v", tt.expected, dirs)
+			}
+		})
+	}
+}
+
+func TestExtractZipSparse(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Build an archive laid out like the GitHub zip download
+	zipPath := filepath.Join(tmpDir, "saidata.zip")
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	writer := zip.NewWriter(zipFile)
+	if _, err := writer.Create("saidata-main/"); err != nil {
+		t.Fatalf("Failed to add root directory: %v", err)
+	}
+	for name, content := range sparseTestFiles() {
+		entry, err := writer.Create("saidata-main/" + name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		entry.Write([]byte(content))
+	}
+	writer.Close()
+	zipFile.Close()
+
+	rm := &RepositoryManager{
+		localPath: filepath.Join(tmpDir, "saidata"),
+		sparse:    SparseFilter{Software: []string{"terraform"}, Categories: []string{"web-server"}},
+	}
+	if err := rm.extractZip(zipPath); err != nil {
+		t.Fatalf("extractZip failed: %v", err)
+	}
+
+	for name := range sparseTestFiles() {
+		_, err := os.Stat(filepath.Join(rm.localPath, name))
+		selected := name == "README.md" || inSparseSelection(name, []string{
+			"software/ap/apache", "software/ng/nginx", "software/te/terraform",
+		})
+		if selected && err != nil {
+			t.Errorf("Expected %s to be extracted: %v", name, err)
+		}
+		if !selected && err == nil {
+			t.Errorf("Expected %s to be skipped", name)
+		}
+	}
+
+	if err := rm.ValidateRepository(); err != nil {
+		t.Errorf("Expected sparse repository to validate: %v", err)
+	}
+}
+
+func TestInSparseSelection(t *testing.T) {
+	dirs := []string{"software/ng/nginx"}
+
+	tests := map[string]bool{
+		"README.md":                             true,
+		"software/":                             true,
+		"software/ng/":                          true,
+		"software/ng/nginx/default.yaml":        true,
+		"software/ng/nginx/ubuntu/22.04.yaml":   true,
+		"software/ng/nginx-extras/default.yaml": false,
+		"software/my/mysql/default.yaml":        false,
+		"schemas/saidata.json":                  false,
+	}
+
+	for path, expected := range tests {
+		if got := inSparseSelection(path, dirs); got != expected {
+			t.Errorf("inSparseSelection(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+}
+
+func TestApplySparseCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	for name, content := range sparseTestFiles() {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=Saidata Test", "-c", "user.email=saidata@example.com", "commit", "-q", "-m", "Saidata"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	rm := &RepositoryManager{localPath: repo, progress: io.Discard, sparse: SparseFilter{Software: []string{"nginx"}}}
+	if err := rm.applySparseCheckout(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "software/ap/apache/default.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected apache outside the sparse selection, got %v", err)
+	}
+
+	// Emptying the filter restores the whole working tree
+	rm.SetSparseFilter(SparseFilter{})
+	if err := rm.applySparseCheckout(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for name := range sparseTestFiles() {
+		if _, err := os.Stat(filepath.Join(repo, name)); err != nil {
+			t.Errorf("Expected %s after disabling the sparse checkout: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package saidata
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"sai/internal/types"
+)
+
+// LargeDefinitionSize is the size in bytes above which a software definition is reported
+// as large. Such definitions slow down every load and usually embed data, such as long
+// package lists, better split into OS overrides.
+const LargeDefinitionSize = 1 << 20
+
+// RepositoryStats summarizes the software definitions of a saidata repository
+type RepositoryStats struct {
+	Software           int                `json:"software"`
+	Invalid            []string           `json:"invalid,omitempty"`   // Definitions that fail to parse
+	Oversized          []string           `json:"oversized,omitempty"` // Definitions larger than LargeDefinitionSize
+	Providers          []ProviderCoverage `json:"providers"`
+	Categories         []CategoryCount    `json:"categories"`
+	MissingDescription []string           `json:"missing_description"`
+	MissingLicense     []string           `json:"missing_license"`
+	MissingURLs        []string           `json:"missing_urls"`
+	Largest            []DefinitionSize   `json:"largest"`
+}
+
+// ProviderCoverage counts the software with a configuration for a provider
+type ProviderCoverage struct {
+	Provider string  `json:"provider"`
+	Software int     `json:"software"`
+	Percent  float64 `json:"percent"`
+}
+
+// CategoryCount counts the software of a metadata category
+type CategoryCount struct {
+	Category string `json:"category"`
+	Software int    `json:"software"`
+}
+
+// DefinitionSize is the size of a software definition
+type DefinitionSize struct {
+	Software  string `json:"software"`
+	Path      string `json:"path"`
+	Bytes     int    `json:"bytes"`
+	Resources int    `json:"resources"` // Packages, services, files, directories, commands, ports and containers
+}
+
+// CollectStats walks the software definitions in fsys, in either repository layout,
+// and reports coverage and quality statistics. Category and size rankings are limited
+// to the top entries, all entries are kept when top is not positive.
+func CollectStats(fsys fs.FS, top int) (*RepositoryStats, error) {
+	stats := &RepositoryStats{
+		Providers:          []ProviderCoverage{},
+		Categories:         []CategoryCount{},
+		MissingDescription: []string{},
+		MissingLicense:     []string{},
+		MissingURLs:        []string{},
+		Largest:            []DefinitionSize{},
+	}
+	providers := make(map[string]int)
+	categories := make(map[string]int)
+
+	err := fs.WalkDir(fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		_, name, ok := softwareDir(filePath)
+		if !ok {
+			return nil
+		}
+
+		size, saidata, err := loadDefinitionLazy(fsys, filePath)
+		if err != nil {
+			return err
+		}
+		stats.Software++
+		if size > LargeDefinitionSize {
+			stats.Oversized = append(stats.Oversized, filePath)
+		}
+		if saidata == nil {
+			stats.Invalid = append(stats.Invalid, filePath)
+			return nil
+		}
+
+		// Only the provider names are needed, their sections are never decoded
+		for _, provider := range saidata.ProviderNames() {
+			providers[provider]++
+		}
+		if category := strings.ToLower(saidata.Metadata.Category); category != "" {
+			categories[category]++
+		}
+
+		if saidata.Metadata.Description == "" {
+			stats.MissingDescription = append(stats.MissingDescription, name)
+		}
+		if saidata.Metadata.License == "" {
+			stats.MissingLicense = append(stats.MissingLicense, name)
+		}
+		if urls := saidata.Metadata.URLs; urls == nil || (urls.Website == "" && urls.Documentation == "" && urls.Source == "") {
+			stats.MissingURLs = append(stats.MissingURLs, name)
+		}
+
+		stats.Largest = append(stats.Largest, DefinitionSize{
+			Software:  name,
+			Path:      filePath,
+			Bytes:     int(size),
+			Resources: resourceCount(saidata),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect saidata statistics: %w", err)
+	}
+
+	for provider, count := range providers {
+		coverage := ProviderCoverage{Provider: provider, Software: count}
+		if stats.Software > 0 {
+			coverage.Percent = float64(count) * 100 / float64(stats.Software)
+		}
+		stats.Providers = append(stats.Providers, coverage)
+	}
+	sort.Slice(stats.Providers, func(i, j int) bool {
+		if stats.Providers[i].Software != stats.Providers[j].Software {
+			return stats.Providers[i].Software > stats.Providers[j].Software
+		}
+		return stats.Providers[i].Provider < stats.Providers[j].Provider
+	})
+
+	for category, count := range categories {
+		stats.Categories = append(stats.Categories, CategoryCount{Category: category, Software: count})
+	}
+	sort.Slice(stats.Categories, func(i, j int) bool {
+		if stats.Categories[i].Software != stats.Categories[j].Software {
+			return stats.Categories[i].Software > stats.Categories[j].Software
+		}
+		return stats.Categories[i].Category < stats.Categories[j].Category
+	})
+
+	sort.Slice(stats.Largest, func(i, j int) bool {
+		if stats.Largest[i].Bytes != stats.Largest[j].Bytes {
+			return stats.Largest[i].Bytes > stats.Largest[j].Bytes
+		}
+		return stats.Largest[i].Software < stats.Largest[j].Software
+	})
+
+	sort.Strings(stats.MissingDescription)
+	sort.Strings(stats.MissingLicense)
+	sort.Strings(stats.MissingURLs)
+	sort.Strings(stats.Oversized)
+
+	if top > 0 {
+		if len(stats.Categories) > top {
+			stats.Categories = stats.Categories[:top]
+		}
+		if len(stats.Largest) > top {
+			stats.Largest = stats.Largest[:top]
+		}
+	}
+	return stats, nil
+}
+
+// loadDefinitionLazy streams a software definition from fsys, with its provider sections
+// left undecoded, and returns its size. The saidata is nil when the definition does not
+// parse; the error is only set when the file cannot be read.
+func loadDefinitionLazy(fsys fs.FS, filePath string) (int64, *types.SoftwareData, error) {
+	file, err := fsys.Open(filePath)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	saidata, err := types.LoadSoftwareDataLazy(file)
+	if err != nil {
+		return info.Size(), nil, nil
+	}
+	return info.Size(), saidata, nil
+}
+
+// resourceCount counts the resources declared by a software definition
+func resourceCount(saidata *types.SoftwareData) int {
+	return len(saidata.Packages) + len(saidata.Services) + len(saidata.Files) + len(saidata.Directories) +
+		len(saidata.Commands) + len(saidata.Ports) + len(saidata.Containers)
+}
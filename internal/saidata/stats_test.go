@@ -0,0 +1,135 @@
+package saidata
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"sai/internal/types"
+)
+
+func TestCollectStats(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md": {Data: []byte("# saidata")},
+		"software/ng/nginx/default.yaml": {Data: []byte(`metadata:
+  name: nginx
+  category: web-server
+  description: HTTP server
+  license: BSD-2-Clause
+  urls:
+    website: https://nginx.org
+packages:
+  - name: nginx
+services:
+  - name: nginx
+providers:
+  apt:
+    packages:
+      - name: nginx-full
+  brew: {}
+`)},
+		"software/ng/nginx/ubuntu/22.04.yaml": {Data: []byte("metadata:\n  name: nginx\n")},
+		"software/ap/apache/default.yaml":     {Data: []byte("metadata:\n  name: apache\n  category: Web-Server\n  description: HTTP server\nproviders:\n  apt: {}\n")},
+		"re/redis/default.yaml":               {Data: []byte("metadata:\n  name: redis\n  category: database\n")},
+		"software/br/broken/default.yaml":     {Data: []byte("metadata: [")},
+	}
+
+	stats, err := CollectStats(fsys, 1)
+	if err != nil {
+		t.Fatalf("CollectStats() error = %v", err)
+	}
+
+	if stats.Software != 4 {
+		t.Errorf("Software = %d, want 4", stats.Software)
+	}
+	if !reflect.DeepEqual(stats.Invalid, []string{"software/br/broken/default.yaml"}) {
+		t.Errorf("Invalid = %v", stats.Invalid)
+	}
+
+	expectedProviders := []ProviderCoverage{
+		{Provider: "apt", Software: 2, Percent: 50},
+		{Provider: "brew", Software: 1, Percent: 25},
+	}
+	if !reflect.DeepEqual(stats.Providers, expectedProviders) {
+		t.Errorf("Providers = %+v, want %+v", stats.Providers, expectedProviders)
+	}
+
+	// Categories are compared case-insensitively and limited to the top entry
+	if !reflect.DeepEqual(stats.Categories, []CategoryCount{{Category: "web-server", Software: 2}}) {
+		t.Errorf("Categories = %+v", stats.Categories)
+	}
+
+	if !reflect.DeepEqual(stats.MissingDescription, []string{"redis"}) {
+		t.Errorf("MissingDescription = %v", stats.MissingDescription)
+	}
+	if !reflect.DeepEqual(stats.MissingLicense, []string{"apache", "redis"}) {
+		t.Errorf("MissingLicense = %v", stats.MissingLicense)
+	}
+	if !reflect.DeepEqual(stats.MissingURLs, []string{"apache", "redis"}) {
+		t.Errorf("MissingURLs = %v", stats.MissingURLs)
+	}
+
+	if len(stats.Largest) != 1 || stats.Largest[0].Software != "nginx" || stats.Largest[0].Resources != 2 {
+		t.Errorf("Largest = %+v, want nginx with 2 resources", stats.Largest)
+	}
+}
+
+func TestCollectStats_Empty(t *testing.T) {
+	stats, err := CollectStats(fstest.MapFS{}, 10)
+	if err != nil {
+		t.Fatalf("CollectStats() error = %v", err)
+	}
+	if stats.Software != 0 || len(stats.Providers) != 0 || stats.MissingLicense == nil {
+		t.Errorf("unexpected stats for an empty repository: %+v", stats)
+	}
+}
+
+// benchmarkRepository returns a repository of definitions with many provider sections,
+// the shape of the largest definitions of the saidata repository
+func benchmarkRepository() fstest.MapFS {
+	var definition strings.Builder
+	definition.WriteString("metadata:\n  name: software\n  description: Benchmark\nproviders:\n")
+	for provider := 0; provider < 20; provider++ {
+		fmt.Fprintf(&definition, "  provider%d:\n    packages:\n", provider)
+		for pkg := 0; pkg < 20; pkg++ {
+			fmt.Fprintf(&definition, "      - name: package%d\n        package_name: package%d-bin\n        version: \"1.%d\"\n", pkg, pkg, pkg)
+		}
+	}
+
+	fsys := fstest.MapFS{}
+	for software := 0; software < 50; software++ {
+		name := fmt.Sprintf("software%02d", software)
+		fsys[fmt.Sprintf("software/%s/%s/default.yaml", name[:2], name)] = &fstest.MapFile{Data: []byte(definition.String())}
+	}
+	return fsys
+}
+
+// BenchmarkCollectStats measures the allocations of the statistics of a repository,
+// which leave the provider sections undecoded
+func BenchmarkCollectStats(b *testing.B) {
+	fsys := benchmarkRepository()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := CollectStats(fsys, 10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCollectStatsFullDecode is the baseline of BenchmarkCollectStats, loading
+// each definition with its provider sections decoded
+func BenchmarkCollectStatsFullDecode(b *testing.B) {
+	fsys := benchmarkRepository()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for path, file := range fsys {
+			saidata, err := types.LoadSoftwareDataFromYAML(file.Data)
+			if err != nil {
+				b.Fatalf("%s: %v", path, err)
+			}
+			_ = len(saidata.Providers)
+		}
+	}
+}
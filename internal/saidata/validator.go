@@ -1,6 +1,7 @@
 package saidata
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -9,8 +10,11 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"sai/internal/platform"
+	"sai/internal/systemd"
 	"sai/internal/types"
 )
 
@@ -30,8 +34,16 @@ type ValidationResult struct {
 // SystemResourceValidator validates system resources
 type SystemResourceValidator struct {
 	timeout time.Duration
+
+	// Native systemd access, connected on first use and released by Close
+	systemdMutex     sync.Mutex
+	systemdConnected bool
+	systemdBackend   systemd.Backend
 }
 
+// recentLogLines is the number of journal entries included in service status
+const recentLogLines = 10
+
 // NewSystemResourceValidator creates a new system resource validator
 func NewSystemResourceValidator() *SystemResourceValidator {
 	return &SystemResourceValidator{
@@ -145,7 +157,7 @@ func (v *SystemResourceValidator) ValidateCommand(command string) bool {
 	}
 
 	// Check in PATH
-	_, err := exec.LookPath(command)
+	_, err := platform.LookPath(command)
 	return err == nil
 }
 
@@ -179,9 +191,63 @@ func (v *SystemResourceValidator) ValidatePort(port int) bool {
 	return true
 }
 
+// serviceBackend returns the systemd backend, or nil when systemd is not available
+func (v *SystemResourceValidator) serviceBackend() systemd.Backend {
+	v.systemdMutex.Lock()
+	defer v.systemdMutex.Unlock()
+	if !v.systemdConnected {
+		v.systemdConnected = true
+		ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+		defer cancel()
+		if backend, err := systemd.New(ctx); err == nil {
+			v.systemdBackend = backend
+		}
+	}
+	return v.systemdBackend
+}
+
+// Close releases the connection to systemd. The validator connects again when used.
+func (v *SystemResourceValidator) Close() {
+	v.systemdMutex.Lock()
+	defer v.systemdMutex.Unlock()
+	if v.systemdBackend != nil {
+		v.systemdBackend.Close()
+		v.systemdBackend = nil
+	}
+	v.systemdConnected = false
+}
+
+// unitStatus returns the systemd state of a service, or nil if it cannot be queried
+func (v *SystemResourceValidator) unitStatus(serviceName string) *systemd.UnitStatus {
+	backend := v.serviceBackend()
+	if backend == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+	defer cancel()
+	status, err := backend.UnitStatus(ctx, serviceName)
+	if err != nil {
+		return nil
+	}
+	return status
+}
+
+// linuxUnitStatus returns the systemd state of a service on Linux, or nil elsewhere
+func (v *SystemResourceValidator) linuxUnitStatus(serviceName string) *systemd.UnitStatus {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	return v.unitStatus(serviceName)
+}
+
 // validateLinuxService checks if a systemd service exists
 func (v *SystemResourceValidator) validateLinuxService(serviceName string) bool {
-	// Try systemctl first
+	if status := v.unitStatus(serviceName); status != nil && status.Exists() {
+		return true
+	}
+
+	// Try systemctl unit files
 	cmd := exec.Command("systemctl", "list-unit-files", serviceName+".service")
 	output, err := cmd.Output()
 	if err == nil && strings.Contains(string(output), serviceName+".service") {
@@ -240,7 +306,7 @@ func (v *SystemResourceValidator) validateExecutablePath(path string) bool {
 // canProceedWithAction determines if an action can proceed based on validation results
 func (v *SystemResourceValidator) canProceedWithAction(action string, result *ValidationResult) bool {
 	// Information-only actions can always proceed
-	infoActions := []string{"search", "info", "version", "status", "logs", "config", "check", "cpu", "memory", "io", "list", "stats"}
+	infoActions := []string{"search", "info", "version", "status", "logs", "config", "check", "cpu", "memory", "io", "list", "list_installed", "stats", "verify", "origin", "signature"}
 	for _, infoAction := range infoActions {
 		if action == infoAction {
 			return true
@@ -382,8 +448,15 @@ func (v *SystemResourceValidator) GetResourceStatus(saidata *types.SoftwareData)
 			Exists: v.ValidateService(serviceName),
 		}
 		if serviceStatus.Exists {
-			serviceStatus.IsActive = v.isServiceActive(serviceName)
-			serviceStatus.IsEnabled = v.isServiceEnabled(serviceName)
+			if unit := v.linuxUnitStatus(serviceName); unit != nil {
+				serviceStatus.IsActive = unit.IsActive()
+				serviceStatus.IsEnabled = unit.IsEnabled()
+				serviceStatus.State = unit.SubState
+				serviceStatus.RecentLogs = v.recentLogs(serviceName)
+			} else {
+				serviceStatus.IsActive = v.isServiceActive(serviceName)
+				serviceStatus.IsEnabled = v.isServiceEnabled(serviceName)
+			}
 		}
 		status.Services[service.Name] = serviceStatus
 	}
@@ -431,10 +504,29 @@ func (v *SystemResourceValidator) GetResourceStatus(saidata *types.SoftwareData)
 	return status, nil
 }
 
+// recentLogs returns the most recent journal entries of a service
+func (v *SystemResourceValidator) recentLogs(serviceName string) []systemd.JournalEntry {
+	backend := v.serviceBackend()
+	if backend == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+	defer cancel()
+	entries, err := backend.JournalEntries(ctx, serviceName, recentLogLines)
+	if err != nil {
+		return nil
+	}
+	return entries
+}
+
 // isServiceActive checks if a service is currently active/running
 func (v *SystemResourceValidator) isServiceActive(serviceName string) bool {
 	switch runtime.GOOS {
 	case "linux":
+		if status := v.unitStatus(serviceName); status != nil {
+			return status.IsActive()
+		}
 		cmd := exec.Command("systemctl", "is-active", serviceName)
 		output, err := cmd.Output()
 		return err == nil && strings.TrimSpace(string(output)) == "active"
@@ -453,6 +545,9 @@ func (v *SystemResourceValidator) isServiceActive(serviceName string) bool {
 func (v *SystemResourceValidator) isServiceEnabled(serviceName string) bool {
 	switch runtime.GOOS {
 	case "linux":
+		if status := v.unitStatus(serviceName); status != nil {
+			return status.IsEnabled()
+		}
 		cmd := exec.Command("systemctl", "is-enabled", serviceName)
 		output, err := cmd.Output()
 		return err == nil && strings.TrimSpace(string(output)) == "enabled"
@@ -487,10 +582,12 @@ type FileStatus struct {
 
 // ServiceStatus represents the status of a service resource
 type ServiceStatus struct {
-	Name      string `json:"name"`
-	Exists    bool   `json:"exists"`
-	IsActive  bool   `json:"is_active"`
-	IsEnabled bool   `json:"is_enabled"`
+	Name       string                 `json:"name"`
+	Exists     bool                   `json:"exists"`
+	IsActive   bool                   `json:"is_active"`
+	IsEnabled  bool                   `json:"is_enabled"`
+	State      string                 `json:"state,omitempty"`       // systemd sub state, e.g. "running"
+	RecentLogs []systemd.JournalEntry `json:"recent_logs,omitempty"` // most recent journal entries
 }
 
 // CommandStatus represents the status of a command resource
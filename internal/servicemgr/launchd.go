@@ -0,0 +1,105 @@
+package servicemgr
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// launchdJob is a launchd job resolved from the property list of a service
+type launchdJob struct {
+	Label  string
+	Domain string // system, or gui/<uid> for launch agents
+	Plist  string // Property list of the job, empty when none was found
+}
+
+// Target returns the <domain>/<label> service target of launchctl
+func (j launchdJob) Target() string {
+	return j.Domain + "/" + j.Label
+}
+
+// launchdDir is a directory holding the property lists of launchd jobs
+type launchdDir struct {
+	Path   string
+	Domain string
+}
+
+// plistLabel matches the Label key of XML property lists
+var plistLabel = regexp.MustCompile(`<key>Label</key>\s*<string>([^<]+)</string>`)
+
+// launchdDirs returns the directories of launchd property lists, daemons first
+var launchdDirs = func() []launchdDir {
+	gui := "gui/" + strconv.Itoa(os.Getuid())
+	dirs := []launchdDir{{"/Library/LaunchDaemons", "system"}}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, launchdDir{filepath.Join(home, "Library", "LaunchAgents"), gui})
+	}
+	return append(dirs,
+		launchdDir{"/Library/LaunchAgents", gui},
+		launchdDir{"/System/Library/LaunchDaemons", "system"},
+		launchdDir{"/System/Library/LaunchAgents", gui},
+	)
+}
+
+// resolveLaunchdJob finds the property list of a service, named after the service
+// or ending with it like homebrew.mxcl.nginx.plist, and returns its job. Services
+// without a property list are assumed to be system jobs labelled with their name.
+func resolveLaunchdJob(service string, dirs []launchdDir) launchdJob {
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir.Path)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if name != service+".plist" && !strings.HasSuffix(name, "."+service+".plist") {
+				continue
+			}
+			path := filepath.Join(dir.Path, name)
+			return launchdJob{Label: readPlistLabel(path), Domain: dir.Domain, Plist: path}
+		}
+	}
+	return launchdJob{Label: service, Domain: "system"}
+}
+
+// readPlistLabel returns the Label of a property list, or its file name without
+// extension for binary property lists, which launchd requires to match the label
+func readPlistLabel(path string) string {
+	if data, err := os.ReadFile(path); err == nil {
+		if match := plistLabel.FindSubmatch(data); match != nil {
+			return strings.TrimSpace(string(match[1]))
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(path), ".plist")
+}
+
+// launchdLoaded reports whether a launchd job is loaded
+var launchdLoaded = func(target string) bool {
+	return exec.Command("launchctl", "print", target).Run() == nil
+}
+
+// brewPrefixes returns the Homebrew installation prefixes, HOMEBREW_PREFIX first
+func brewPrefixes() []string {
+	var prefixes []string
+	if prefix := os.Getenv("HOMEBREW_PREFIX"); prefix != "" {
+		prefixes = append(prefixes, prefix)
+	}
+	return append(prefixes, "/opt/homebrew", "/usr/local", "/home/linuxbrew/.linuxbrew")
+}
+
+// brewInstalled reports whether a formula is installed in one of the Homebrew
+// prefixes, which link every installed formula under opt
+func brewInstalled(formula string, prefixes []string) bool {
+	if formula == "" || strings.ContainsAny(formula, `/\`) {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if info, err := os.Stat(filepath.Join(prefix, "opt", formula)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+// Package servicemgr generates the commands controlling services with the service
+// manager of the running system: systemd, launchd, the Windows service control
+// manager, OpenRC or runit. On macOS, services of Homebrew formulae are controlled
+// with brew services.
+package servicemgr
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"sai/internal/platform"
+)
+
+// Manager is a service manager
+type Manager string
+
+// Supported service managers
+const (
+	Systemd Manager = "systemd"
+	Launchd Manager = "launchd"
+	Windows Manager = "windows"
+	OpenRC  Manager = "openrc"
+	Runit   Manager = "runit"
+	Brew    Manager = "brew"
+)
+
+// Service actions. IsActive prints "active" for a running service and "inactive"
+// otherwise, IsEnabled "enabled" for a service started at boot and "disabled"
+// otherwise, whatever the service manager.
+const (
+	Start     = "start"
+	Stop      = "stop"
+	Restart   = "restart"
+	Enable    = "enable"
+	Disable   = "disable"
+	Status    = "status"
+	IsActive  = "is-active"
+	IsEnabled = "is-enabled"
+)
+
+// commandFormats are the commands of the service actions, %[1]s being the service name
+var commandFormats = map[Manager]map[string]string{
+	Systemd: {
+		Start:     "systemctl start %[1]s",
+		Stop:      "systemctl stop %[1]s",
+		Restart:   "systemctl restart %[1]s",
+		Enable:    "systemctl enable %[1]s",
+		Disable:   "systemctl disable %[1]s",
+		Status:    "systemctl status %[1]s",
+		IsActive:  "systemctl is-active %[1]s",
+		IsEnabled: "systemctl is-enabled %[1]s",
+	},
+	// The service name is replaced by the <domain>/<label> target of the launchd job
+	Launchd: {
+		Start:     "launchctl kickstart %[1]s",
+		Stop:      "launchctl bootout %[1]s",
+		Restart:   "launchctl kickstart -k %[1]s",
+		Enable:    "launchctl enable %[1]s",
+		Disable:   "launchctl disable %[1]s",
+		Status:    "launchctl print %[1]s",
+		IsActive:  "launchctl print %[1]s 2>/dev/null | grep -q 'state = running' && echo active || echo inactive",
+		IsEnabled: "launchctl print %[1]s >/dev/null 2>&1 && echo enabled || echo disabled",
+	},
+	// brew services registers started services to run at login or boot
+	Brew: {
+		Start:     "brew services start %[1]s",
+		Stop:      "brew services stop %[1]s",
+		Restart:   "brew services restart %[1]s",
+		Enable:    "brew services start %[1]s",
+		Disable:   "brew services stop %[1]s",
+		Status:    "brew services info %[1]s",
+		IsActive:  "brew services info %[1]s --json | grep -q '\"running\": true' && echo active || echo inactive",
+		IsEnabled: "brew services info %[1]s --json | grep -q '\"loaded\": true' && echo enabled || echo disabled",
+	},
+	Windows: {
+		Start:     "sc.exe start %[1]s",
+		Stop:      "sc.exe stop %[1]s",
+		Restart:   "powershell -NoProfile -Command \"Restart-Service -Name '%[1]s'\"",
+		Enable:    "sc.exe config %[1]s start= auto",
+		Disable:   "sc.exe config %[1]s start= disabled",
+		Status:    "sc.exe query %[1]s",
+		IsActive:  "powershell -NoProfile -Command \"if ((Get-Service -Name '%[1]s').Status -eq 'Running') { 'active' } else { 'inactive' }\"",
+		IsEnabled: "powershell -NoProfile -Command \"if ((Get-Service -Name '%[1]s').StartType -eq 'Automatic') { 'enabled' } else { 'disabled' }\"",
+	},
+	OpenRC: {
+		Start:     "rc-service %[1]s start",
+		Stop:      "rc-service %[1]s stop",
+		Restart:   "rc-service %[1]s restart",
+		Enable:    "rc-update add %[1]s default",
+		Disable:   "rc-update del %[1]s default",
+		Status:    "rc-service %[1]s status",
+		IsActive:  "rc-service -q %[1]s status && echo active || echo inactive",
+		IsEnabled: "rc-update show default | grep -qw %[1]s && echo enabled || echo disabled",
+	},
+	Runit: {
+		Start:     "sv start %[1]s",
+		Stop:      "sv stop %[1]s",
+		Restart:   "sv restart %[1]s",
+		Enable:    "ln -sf /etc/sv/%[1]s /var/service/",
+		Disable:   "rm -f /var/service/%[1]s",
+		Status:    "sv status %[1]s",
+		IsActive:  "sv status %[1]s | grep -q '^run:' && echo active || echo inactive",
+		IsEnabled: "test -L /var/service/%[1]s && echo enabled || echo disabled",
+	},
+}
+
+// Command returns the command running a service action with the service manager
+func (m Manager) Command(action, service string) (string, error) {
+	formats, exists := commandFormats[m]
+	if !exists {
+		return "", fmt.Errorf("unsupported service manager: %s", m)
+	}
+	format, exists := formats[action]
+	if !exists {
+		return "", fmt.Errorf("unsupported service action '%s' (must be one of: %v)", action, Actions())
+	}
+	if m == Launchd {
+		job := resolveLaunchdJob(service, launchdDirs())
+		// Jobs that are not loaded are started from their property list
+		if (action == Start || action == Restart) && job.Plist != "" && !launchdLoaded(job.Target()) {
+			return fmt.Sprintf("launchctl bootstrap %s %s", job.Domain, job.Plist), nil
+		}
+		service = job.Target()
+	}
+	return fmt.Sprintf(format, service), nil
+}
+
+// ForService returns the service manager controlling a service. On macOS, services of
+// software installed with Homebrew are controlled with brew services and other
+// services with launchd.
+func ForService(service string) Manager {
+	manager := Detect()
+	if manager == Launchd && hasExecutable("brew") && brewInstalled(service, brewPrefixes()) {
+		return Brew
+	}
+	return manager
+}
+
+// Actions returns the supported service actions
+func Actions() []string {
+	var actions []string
+	for action := range commandFormats[Systemd] {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// Detect returns the service manager of the running system. Linux systems are
+// probed for systemd, then OpenRC and runit, and default to systemd.
+func Detect() Manager {
+	osInfo, err := platform.Detect()
+	if err != nil {
+		return Systemd
+	}
+	return detect(osInfo.Platform, hasExecutable, booted)
+}
+
+// detect returns the service manager of a platform
+func detect(platformName string, hasExecutable func(string) bool, booted func(Manager) bool) Manager {
+	switch platform.PlatformForOS(platformName) {
+	case "darwin":
+		return Launchd
+	case "windows":
+		return Windows
+	}
+
+	for _, manager := range []struct {
+		manager    Manager
+		executable string
+	}{
+		{Systemd, "systemctl"},
+		{OpenRC, "rc-service"},
+		{Runit, "sv"},
+	} {
+		if hasExecutable(manager.executable) && booted(manager.manager) {
+			return manager.manager
+		}
+	}
+	return Systemd
+}
+
+// hasExecutable reports whether an executable is available
+func hasExecutable(name string) bool {
+	_, err := platform.LookPath(name)
+	return err == nil
+}
+
+// booted reports whether the system was booted with a service manager. Installing
+// the tools of a service manager does not make it the running one, so its runtime
+// directory is checked too, unless a test fixture fakes the system.
+func booted(manager Manager) bool {
+	if platform.FakesExecutables() {
+		return true
+	}
+
+	var dir string
+	switch manager {
+	case Systemd:
+		dir = "/run/systemd/system"
+	case OpenRC:
+		dir = "/run/openrc"
+	case Runit:
+		dir = "/run/runit"
+	default:
+		return false
+	}
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,125 @@
+package servicemgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand(t *testing.T) {
+	command, err := Systemd.Command(Start, "nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, "systemctl start nginx", command)
+
+	command, err = Runit.Command(IsActive, "nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, "sv status nginx | grep -q '^run:' && echo active || echo inactive", command)
+
+	command, err = Windows.Command(Enable, "nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, "sc.exe config nginx start= auto", command)
+
+	_, err = OpenRC.Command("reload", "nginx")
+	assert.Error(t, err)
+	_, err = Manager("upstart").Command(Start, "nginx")
+	assert.Error(t, err)
+}
+
+func TestCommandFormatsCoverActions(t *testing.T) {
+	for manager, formats := range commandFormats {
+		for _, action := range Actions() {
+			assert.Contains(t, formats, action, "%s has no %s command", manager, action)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	available := func(executables ...string) func(string) bool {
+		return func(name string) bool {
+			for _, executable := range executables {
+				if executable == name {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	bootedWith := func(managers ...Manager) func(Manager) bool {
+		return func(manager Manager) bool {
+			for _, m := range managers {
+				if m == manager {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	tests := []struct {
+		name     string
+		platform string
+		has      func(string) bool
+		booted   func(Manager) bool
+		expected Manager
+	}{
+		{"macos", "darwin", available(), bootedWith(), Launchd},
+		{"windows", "windows", available(), bootedWith(), Windows},
+		{"systemd", "linux", available("systemctl", "sv"), bootedWith(Systemd, Runit), Systemd},
+		{"openrc", "linux", available("systemctl", "rc-service"), bootedWith(OpenRC), OpenRC},
+		{"runit", "linux", available("sv"), bootedWith(Runit), Runit},
+		{"container without service manager", "linux", available(), bootedWith(), Systemd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, detect(tt.platform, tt.has, tt.booted))
+		})
+	}
+}
+
+func TestLaunchdCommand(t *testing.T) {
+	daemons, agents := t.TempDir(), t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(daemons, "org.postgresql.postgres.plist"),
+		[]byte("<plist><dict>\n  <key>Label</key>\n  <string>org.postgresql.server</string>\n</dict></plist>"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(agents, "homebrew.mxcl.nginx.plist"), []byte("bplist00"), 0644))
+
+	dirs, loaded := launchdDirs, launchdLoaded
+	t.Cleanup(func() { launchdDirs, launchdLoaded = dirs, loaded })
+	launchdDirs = func() []launchdDir {
+		return []launchdDir{{daemons, "system"}, {agents, "gui/501"}}
+	}
+	loadedTargets := map[string]bool{"system/org.postgresql.server": true}
+	launchdLoaded = func(target string) bool { return loadedTargets[target] }
+
+	tests := []struct {
+		action   string
+		service  string
+		expected string
+	}{
+		{Stop, "postgres", "launchctl bootout system/org.postgresql.server"},
+		{Start, "postgres", "launchctl kickstart system/org.postgresql.server"},
+		{Start, "nginx", "launchctl bootstrap gui/501 " + filepath.Join(agents, "homebrew.mxcl.nginx.plist")},
+		{Enable, "nginx", "launchctl enable gui/501/homebrew.mxcl.nginx"},
+		{Restart, "sshd", "launchctl kickstart -k system/sshd"},
+	}
+	for _, tt := range tests {
+		command, err := Launchd.Command(tt.action, tt.service)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, command)
+	}
+}
+
+func TestBrewInstalled(t *testing.T) {
+	prefix := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(prefix, "opt", "nginx"), 0755))
+
+	assert.True(t, brewInstalled("nginx", []string{"/nonexistent", prefix}))
+	assert.False(t, brewInstalled("redis", []string{prefix}))
+	assert.False(t, brewInstalled("../opt", []string{prefix}))
+
+	command, err := Brew.Command(Enable, "nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, "brew services start nginx", command)
+}
@@ -0,0 +1,63 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+
+	sddbus "github.com/coreos/go-systemd/v22/dbus"
+)
+
+// dbusBackend queries systemd natively over the system D-Bus
+type dbusBackend struct {
+	conn *sddbus.Conn
+}
+
+// newDBusBackend connects to systemd on the system bus
+func newDBusBackend(ctx context.Context) (*dbusBackend, error) {
+	conn, err := sddbus.NewSystemConnectionContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to systemd over D-Bus: %w", err)
+	}
+	return &dbusBackend{conn: conn}, nil
+}
+
+// Name returns the backend name
+func (b *dbusBackend) Name() string {
+	return BackendDBus
+}
+
+// UnitStatus reads the unit state properties over D-Bus
+func (b *dbusBackend) UnitStatus(ctx context.Context, name string) (*UnitStatus, error) {
+	unit := UnitName(name)
+	properties, err := b.conn.GetUnitPropertiesContext(ctx, unit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get properties of %s: %w", unit, err)
+	}
+
+	return &UnitStatus{
+		Name:          unit,
+		LoadState:     stringProperty(properties, "LoadState"),
+		ActiveState:   stringProperty(properties, "ActiveState"),
+		SubState:      stringProperty(properties, "SubState"),
+		UnitFileState: stringProperty(properties, "UnitFileState"),
+	}, nil
+}
+
+// JournalEntries reads recent journal entries. The journal is not exposed over
+// D-Bus, so entries come from journalctl's JSON output.
+func (b *dbusBackend) JournalEntries(ctx context.Context, name string, lines int) ([]JournalEntry, error) {
+	return readJournal(ctx, UnitName(name), lines)
+}
+
+// Close closes the D-Bus connection
+func (b *dbusBackend) Close() {
+	b.conn.Close()
+}
+
+// stringProperty returns a string unit property, or "" if it is missing
+func stringProperty(properties map[string]any, name string) string {
+	if value, ok := properties[name].(string); ok {
+		return value
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package systemd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// readJournal returns the most recent journal entries of a unit using journalctl's
+// structured JSON output
+func readJournal(ctx context.Context, unit string, lines int) ([]JournalEntry, error) {
+	if lines <= 0 {
+		lines = 50
+	}
+
+	output, err := exec.CommandContext(ctx, "journalctl", "--unit", unit, "--lines", strconv.Itoa(lines),
+		"--output=json", "--no-pager", "--quiet").Output()
+	if err != nil {
+		return nil, fmt.Errorf("journalctl for %s failed: %w", unit, err)
+	}
+
+	return parseJournalJSON(string(output))
+}
+
+// parseJournalJSON parses journalctl --output=json, one JSON object per line
+func parseJournalJSON(output string) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var record map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse journal entry: %w", err)
+		}
+
+		entry := JournalEntry{
+			Unit:     journalString(record, "_SYSTEMD_UNIT"),
+			Message:  journalString(record, "MESSAGE"),
+			Priority: 6, // journald defaults to info
+		}
+		if priority, err := strconv.Atoi(journalString(record, "PRIORITY")); err == nil {
+			entry.Priority = priority
+		}
+		if usec, err := strconv.ParseInt(journalString(record, "__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+			entry.Time = time.UnixMicro(usec)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal output: %w", err)
+	}
+	return entries, nil
+}
+
+// journalString decodes a journal field. Fields are strings, except binary
+// messages which journalctl prints as arrays of bytes.
+func journalString(record map[string]json.RawMessage, field string) string {
+	raw, ok := record[field]
+	if !ok {
+		return ""
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value
+	}
+
+	var bytes []byte
+	var numbers []int
+	if err := json.Unmarshal(raw, &numbers); err == nil {
+		for _, number := range numbers {
+			bytes = append(bytes, byte(number))
+		}
+		return string(bytes)
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package systemd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// systemctlBackend queries systemd through the systemctl command
+type systemctlBackend struct{}
+
+// newSystemctlBackend returns a systemctl backend if systemctl is installed
+func newSystemctlBackend() (*systemctlBackend, error) {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return nil, err
+	}
+	return &systemctlBackend{}, nil
+}
+
+// Name returns the backend name
+func (b *systemctlBackend) Name() string {
+	return BackendSystemctl
+}
+
+// UnitStatus reads the unit state properties with systemctl show
+func (b *systemctlBackend) UnitStatus(ctx context.Context, name string) (*UnitStatus, error) {
+	unit := UnitName(name)
+	output, err := exec.CommandContext(ctx, "systemctl", "show", unit, "--no-pager",
+		"--property=LoadState,ActiveState,SubState,UnitFileState").Output()
+	if err != nil {
+		return nil, fmt.Errorf("systemctl show %s failed: %w", unit, err)
+	}
+
+	properties := parseShowOutput(string(output))
+	return &UnitStatus{
+		Name:          unit,
+		LoadState:     properties["LoadState"],
+		ActiveState:   properties["ActiveState"],
+		SubState:      properties["SubState"],
+		UnitFileState: properties["UnitFileState"],
+	}, nil
+}
+
+// JournalEntries reads recent journal entries with journalctl
+func (b *systemctlBackend) JournalEntries(ctx context.Context, name string, lines int) ([]JournalEntry, error) {
+	return readJournal(ctx, UnitName(name), lines)
+}
+
+// Close is a no-op for the systemctl backend
+func (b *systemctlBackend) Close() {}
+
+// parseShowOutput parses the KEY=VALUE lines printed by systemctl show
+func parseShowOutput(output string) map[string]string {
+	properties := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if key, value, found := strings.Cut(scanner.Text(), "="); found {
+			properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return properties
+}
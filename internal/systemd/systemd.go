@@ -0,0 +1,90 @@
+// Package systemd queries systemd for service state and journal entries, natively over
+// D-Bus when the system bus is reachable and through systemctl otherwise.
+package systemd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrUnavailable is returned when systemd cannot be reached on this system
+var ErrUnavailable = errors.New("systemd is not available")
+
+// Backend names
+const (
+	BackendDBus      = "dbus"
+	BackendSystemctl = "systemctl"
+)
+
+// UnitStatus describes the state of a systemd unit
+type UnitStatus struct {
+	Name          string `json:"name"`
+	LoadState     string `json:"load_state"`      // "loaded", "not-found", "masked", ...
+	ActiveState   string `json:"active_state"`    // "active", "inactive", "failed", ...
+	SubState      string `json:"sub_state"`       // "running", "dead", "exited", ...
+	UnitFileState string `json:"unit_file_state"` // "enabled", "disabled", "static", ...
+}
+
+// Exists reports whether systemd knows the unit
+func (s *UnitStatus) Exists() bool {
+	return s.LoadState != "" && s.LoadState != "not-found"
+}
+
+// IsActive reports whether the unit is active
+func (s *UnitStatus) IsActive() bool {
+	return s.ActiveState == "active"
+}
+
+// IsEnabled reports whether the unit starts at boot
+func (s *UnitStatus) IsEnabled() bool {
+	return s.UnitFileState == "enabled" || s.UnitFileState == "enabled-runtime"
+}
+
+// JournalEntry is a single journal record
+type JournalEntry struct {
+	Time     time.Time `json:"time"`
+	Unit     string    `json:"unit"`
+	Priority int       `json:"priority"`
+	Message  string    `json:"message"`
+}
+
+// Backend queries systemd
+type Backend interface {
+	// Name returns the backend name (dbus or systemctl)
+	Name() string
+
+	// UnitStatus returns the state of a unit; ".service" is implied when the name has no suffix
+	UnitStatus(ctx context.Context, name string) (*UnitStatus, error)
+
+	// JournalEntries returns up to lines most recent journal entries of a unit, oldest first
+	JournalEntries(ctx context.Context, name string, lines int) ([]JournalEntry, error)
+
+	// Close releases the backend connection
+	Close()
+}
+
+// New returns the best available backend: native D-Bus when the system bus is
+// reachable, systemctl otherwise. It returns ErrUnavailable without systemd.
+func New(ctx context.Context) (Backend, error) {
+	if backend, err := newDBusBackend(ctx); err == nil {
+		return backend, nil
+	}
+
+	if backend, err := newSystemctlBackend(); err == nil {
+		return backend, nil
+	}
+
+	return nil, ErrUnavailable
+}
+
+// UnitName adds the .service suffix to names without a unit type
+func UnitName(name string) string {
+	for _, suffix := range []string{".service", ".socket", ".timer", ".target", ".mount", ".path", ".scope", ".slice"} {
+		if strings.HasSuffix(name, suffix) {
+			return name
+		}
+	}
+	return name + ".service"
+}
@@ -0,0 +1,70 @@
+package systemd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitName(t *testing.T) {
+	assert.Equal(t, "nginx.service", UnitName("nginx"))
+	assert.Equal(t, "nginx.service", UnitName("nginx.service"))
+	assert.Equal(t, "docker.socket", UnitName("docker.socket"))
+	assert.Equal(t, "getty@tty1.service", UnitName("getty@tty1"))
+}
+
+func TestUnitStatus(t *testing.T) {
+	running := &UnitStatus{LoadState: "loaded", ActiveState: "active", SubState: "running", UnitFileState: "enabled"}
+	assert.True(t, running.Exists())
+	assert.True(t, running.IsActive())
+	assert.True(t, running.IsEnabled())
+
+	missing := &UnitStatus{LoadState: "not-found", ActiveState: "inactive", SubState: "dead"}
+	assert.False(t, missing.Exists())
+	assert.False(t, missing.IsActive())
+	assert.False(t, missing.IsEnabled())
+
+	static := &UnitStatus{LoadState: "loaded", ActiveState: "failed", UnitFileState: "static"}
+	assert.True(t, static.Exists())
+	assert.False(t, static.IsActive())
+	assert.False(t, static.IsEnabled())
+}
+
+func TestParseShowOutput(t *testing.T) {
+	output := "LoadState=loaded\nActiveState=active\nSubState=running\nUnitFileState=enabled\n"
+
+	properties := parseShowOutput(output)
+	assert.Equal(t, "loaded", properties["LoadState"])
+	assert.Equal(t, "active", properties["ActiveState"])
+	assert.Equal(t, "running", properties["SubState"])
+	assert.Equal(t, "enabled", properties["UnitFileState"])
+}
+
+func TestParseJournalJSON(t *testing.T) {
+	output := `{"__REALTIME_TIMESTAMP":"1700000000000000","_SYSTEMD_UNIT":"nginx.service","PRIORITY":"6","MESSAGE":"Started nginx."}
+{"__REALTIME_TIMESTAMP":"1700000001000000","_SYSTEMD_UNIT":"nginx.service","PRIORITY":"3","MESSAGE":[98,105,110,97,114,121]}
+
+{"__REALTIME_TIMESTAMP":"1700000002000000","_SYSTEMD_UNIT":"nginx.service","MESSAGE":"no priority"}
+`
+
+	entries, err := parseJournalJSON(output)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "nginx.service", entries[0].Unit)
+	assert.Equal(t, "Started nginx.", entries[0].Message)
+	assert.Equal(t, 6, entries[0].Priority)
+	assert.True(t, entries[0].Time.Equal(time.Unix(1700000000, 0)))
+
+	assert.Equal(t, "binary", entries[1].Message)
+	assert.Equal(t, 3, entries[1].Priority)
+
+	assert.Equal(t, 6, entries[2].Priority)
+}
+
+func TestParseJournalJSON_Invalid(t *testing.T) {
+	_, err := parseJournalJSON("not json\n")
+	assert.Error(t, err)
+}
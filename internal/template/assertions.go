@@ -0,0 +1,96 @@
+package template
+
+import (
+	"fmt"
+	"text/template"
+
+	"sai/internal/types"
+)
+
+// TemplateAssertionError is returned when a template assertion (assert, sai_require)
+// fails. Its message is the one written by the provider author.
+type TemplateAssertionError struct {
+	Message string
+}
+
+func (e *TemplateAssertionError) Error() string {
+	return e.Message
+}
+
+// assert fails template execution with message unless condition is true. Truth follows
+// Go template rules: false, 0, nil and empty strings, slices and maps are false.
+// Usage: {{assert (gt (len (sai_packages "apt")) 0) "apt packages missing"}}
+func (e *TemplateEngine) assert(condition interface{}, message string) (string, error) {
+	if truth, ok := template.IsTrue(condition); !ok || !truth {
+		return "", &TemplateAssertionError{Message: message}
+	}
+	return "", nil
+}
+
+// saiRequire fails template execution unless saidata defines at least min resources
+// of a kind (packages, services, files, directories, commands, ports, containers).
+// Provider specific resources are counted when present, as for sai_packages.
+// Usage: {{sai_require "packages" 1}} or {{sai_require "services" 1 "apt"}}
+func (r *renderer) saiRequire(kind string, min int, provider ...string) (string, error) {
+	providerName := r.provider
+	if len(provider) > 0 && provider[0] != "" {
+		providerName = provider[0]
+	}
+
+	software := ""
+	if r.saidata != nil {
+		software = r.saidata.Metadata.Name
+	}
+
+	count, err := r.countResources(kind, providerName)
+	if err != nil {
+		return "", err
+	}
+
+	if count < min {
+		return "", &TemplateAssertionError{
+			Message: fmt.Sprintf("saidata for %s must define at least %d %s for provider %s, found %d",
+				software, min, kind, providerName, count),
+		}
+	}
+	return "", nil
+}
+
+// countResources counts resources of a kind, preferring provider specific ones
+func (r *renderer) countResources(kind, provider string) (int, error) {
+	saidata := r.saidata
+	if saidata == nil {
+		saidata = &types.SoftwareData{}
+	}
+
+	count := func(base, specific int) int {
+		if specific > 0 {
+			return specific
+		}
+		return base
+	}
+
+	providerConfig := saidata.GetProviderConfig(provider)
+	if providerConfig == nil {
+		providerConfig = &types.ProviderConfig{}
+	}
+
+	switch kind {
+	case "packages":
+		return count(len(saidata.Packages), len(providerConfig.Packages)), nil
+	case "services":
+		return count(len(saidata.Services), len(providerConfig.Services)), nil
+	case "files":
+		return count(len(saidata.Files), len(providerConfig.Files)), nil
+	case "directories":
+		return count(len(saidata.Directories), len(providerConfig.Directories)), nil
+	case "commands":
+		return count(len(saidata.Commands), len(providerConfig.Commands)), nil
+	case "ports":
+		return count(len(saidata.Ports), len(providerConfig.Ports)), nil
+	case "containers":
+		return count(len(saidata.Containers), len(providerConfig.Containers)), nil
+	default:
+		return 0, fmt.Errorf("sai_require: unknown resource kind %q", kind)
+	}
+}
@@ -0,0 +1,110 @@
+package template
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/types"
+)
+
+func TestTemplateEngine_AssertionFunctions(t *testing.T) {
+	validator := NewMockResourceValidator()
+	defaultsGen := NewMockDefaultsGenerator()
+	engine := NewTemplateEngine(validator, defaultsGen)
+
+	saidata := &types.SoftwareData{
+		Version: "0.2",
+		Metadata: types.Metadata{
+			Name: "nginx",
+		},
+		Packages: []types.Package{
+			{Name: "nginx"},
+		},
+		Providers: map[string]types.ProviderConfig{
+			"apt": {
+				Packages: []types.Package{
+					{Name: "nginx", PackageName: "nginx-full"},
+					{Name: "nginx-common"},
+				},
+			},
+		},
+	}
+
+	engine.SetSaidata(saidata)
+
+	context := &TemplateContext{
+		Software: "nginx",
+		Provider: "apt",
+		Saidata:  saidata,
+	}
+
+	tests := []struct {
+		name        string
+		template    string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "assert passes",
+			template: "{{assert (gt (len (sai_packages \"apt\")) 0) \"apt packages missing\"}}apt-get install -y nginx",
+			expected: "apt-get install -y nginx",
+		},
+		{
+			name:        "assert fails with author message",
+			template:    "{{assert .Variables.version \"version variable is required\"}}apt-get install -y nginx",
+			expectedErr: "version variable is required",
+		},
+		{
+			name:        "assert fails on empty value",
+			template:    "{{assert \"\" \"value required\"}}",
+			expectedErr: "value required",
+		},
+		{
+			name:     "sai_require passes with provider packages",
+			template: "{{sai_require \"packages\" 2}}ok",
+			expected: "ok",
+		},
+		{
+			name:     "sai_require falls back to default packages",
+			template: "{{sai_require \"packages\" 1 \"brew\"}}ok",
+			expected: "ok",
+		},
+		{
+			name:        "sai_require fails when resources are missing",
+			template:    "{{sai_require \"services\" 1}}ok",
+			expectedErr: "saidata for nginx must define at least 1 services for provider apt, found 0",
+		},
+		{
+			name:        "sai_require legacy call syntax",
+			template:    "{{sai_require('packages', 3)}}ok",
+			expectedErr: "saidata for nginx must define at least 3 packages for provider apt, found 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := engine.Render(tt.template, context)
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+				return
+			}
+
+			require.Error(t, err)
+			var assertionErr *TemplateAssertionError
+			require.True(t, errors.As(err, &assertionErr))
+			assert.Equal(t, tt.expectedErr, err.Error())
+		})
+	}
+}
+
+func TestTemplateEngine_SaiRequireUnknownKind(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+	engine.SetSaidata(&types.SoftwareData{Metadata: types.Metadata{Name: "nginx"}})
+
+	_, err := engine.Render("{{sai_require \"widgets\" 1}}", &TemplateContext{Software: "nginx", Provider: "apt"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown resource kind")
+}
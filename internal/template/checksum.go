@@ -0,0 +1,38 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"sai/internal/checksum"
+)
+
+// checksumFile returns the lower-case hex digest of a file, computed natively so
+// templates don't depend on sha256sum or shasum being installed
+func (e *TemplateEngine) checksumFile(path, algorithm string) (string, error) {
+	digest, err := checksum.File(path, algorithm)
+	if err != nil {
+		return "", fmt.Errorf("checksum_file: %w", err)
+	}
+	return digest, nil
+}
+
+// checksumMatches reports whether a file matches an expected checksum, written as
+// "<algorithm>:<hex>" like saidata checksums or as bare hex whose length selects the
+// algorithm. Missing files don't match, so the function can guard conditions.
+func (e *TemplateEngine) checksumMatches(path, expected string) (bool, error) {
+	parsed, err := checksum.Parse(expected, checksum.All)
+	if err != nil {
+		return false, fmt.Errorf("checksum_matches: %w", err)
+	}
+
+	actual, err := checksum.File(path, parsed.Algorithm)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("checksum_matches: %w", err)
+	}
+	return actual == parsed.Digest, nil
+}
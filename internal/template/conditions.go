@@ -0,0 +1,74 @@
+package template
+
+import (
+	"fmt"
+)
+
+// Conditional functions choose between values by the existence of resources on the
+// system, so templates express fallbacks without if/else blocks:
+//
+//	cat {{first_existing_path "/etc/nginx/nginx.conf" "/usr/local/etc/nginx/nginx.conf"}}
+//	{{when_service "nginx" "systemctl reload nginx" "nginx -s reload"}}
+//
+// Shell-quoted arguments, such as the values of the saidata functions, are unquoted
+// before they are looked up.
+
+// firstExistingPath returns the first of paths existing as a file or directory,
+// shell-quoted. The last path is the fallback returned when none exists.
+// Usage: {{first_existing_path (sai_file "config") "/usr/local/etc/nginx/nginx.conf"}}
+func (e *TemplateEngine) firstExistingPath(paths ...string) (string, error) {
+	if len(paths) == 0 {
+		return "", fmt.Errorf("first_existing_path: no paths")
+	}
+	for _, path := range paths {
+		path = unquoted(path)
+		if e.fileExists(path) || e.directoryExists(path) {
+			return shellQuote(path), nil
+		}
+	}
+	return shellQuote(unquoted(paths[len(paths)-1])), nil
+}
+
+// whenFile returns value when a file exists, otherwise the optional fallback or an
+// empty string. Values are rendered as they are, they are command fragments.
+// Usage: {{when_file "/etc/default/nginx" "--env-file /etc/default/nginx"}}
+func (e *TemplateEngine) whenFile(path, value string, otherwise ...string) (string, error) {
+	return when("when_file", e.fileExists(unquoted(path)), value, otherwise)
+}
+
+// whenDirectory returns value when a directory exists, as whenFile does
+func (e *TemplateEngine) whenDirectory(path, value string, otherwise ...string) (string, error) {
+	return when("when_directory", e.directoryExists(unquoted(path)), value, otherwise)
+}
+
+// whenService returns value when a service exists, as whenFile does
+// Usage: {{when_service (sai_service "main") "systemctl reload nginx" "nginx -s reload"}}
+func (e *TemplateEngine) whenService(service, value string, otherwise ...string) (string, error) {
+	return when("when_service", e.serviceExists(unquoted(service)), value, otherwise)
+}
+
+// whenCommand returns value when a command is found in PATH, as whenFile does
+func (e *TemplateEngine) whenCommand(command, value string, otherwise ...string) (string, error) {
+	return when("when_command", e.commandExists(unquoted(command)), value, otherwise)
+}
+
+// when returns value when condition holds, otherwise the fallback of a conditional
+// function, an empty string without one
+func when(function string, condition bool, value string, otherwise []string) (string, error) {
+	if len(otherwise) > 1 {
+		return "", fmt.Errorf("%s: expected at most one fallback value, got %d", function, len(otherwise))
+	}
+	if condition {
+		return value, nil
+	}
+	if len(otherwise) == 1 {
+		return otherwise[0], nil
+	}
+	return "", nil
+}
+
+// unquoted returns the value of a shell-quoted word, other words as they are
+func unquoted(word string) string {
+	value, _ := shellUnquote(word)
+	return value
+}
@@ -2,26 +2,88 @@ package template
 
 import (
 	"os"
-	"path/filepath"
+	"path"
 	"runtime"
 	"strings"
 )
 
+// Confinements of the software installed by some providers, whose paths differ from
+// the ones of the platform
+const (
+	confinementCask    = "cask"    // Homebrew casks, application bundles under /Applications
+	confinementFlatpak = "flatpak" // Flatpak applications, with their data under ~/.var/app
+	confinementSnap    = "snap"    // Snaps, mounted under /snap with their data under /var/snap
+)
+
+// providerConfinements are the confinements of the software of providers by provider name
+var providerConfinements = map[string]string{
+	"brew-cask": confinementCask,
+	"flatpak":   confinementFlatpak,
+	"snap":      confinementSnap,
+}
+
+// userHomeDir resolves the home directory of confined applications, replaced by tests
+var userHomeDir = os.UserHomeDir
+
 // SystemDefaultsGenerator provides platform-specific default path generation
 type SystemDefaultsGenerator struct {
-	validator ResourceValidator
+	validator   ResourceValidator
+	platform    string // linux, darwin or windows
+	confinement string // Confinement of the software of the provider, empty for none
+	software    string // Software installed by the provider as app
+	app         string
 }
 
-// NewSystemDefaultsGenerator creates a new system defaults generator
+// NewSystemDefaultsGenerator creates a new system defaults generator for the running platform
 func NewSystemDefaultsGenerator(validator ResourceValidator) *SystemDefaultsGenerator {
 	return &SystemDefaultsGenerator{
 		validator: validator,
+		platform:  runtime.GOOS,
 	}
 }
 
+// SetPlatform sets the platform the defaults are generated for, the running platform
+// when empty
+func (g *SystemDefaultsGenerator) SetPlatform(platform string) {
+	if platform == "" {
+		platform = runtime.GOOS
+	}
+	g.platform = platform
+}
+
+// ForPlatform returns a generator of the defaults of a platform, the running platform
+// when empty, leaving this one unchanged. The template engine renders each template
+// with the generator of the .Platform of its context.
+func (g *SystemDefaultsGenerator) ForPlatform(platform string) DefaultsGenerator {
+	generator := *g
+	generator.SetPlatform(platform)
+	return &generator
+}
+
+// ForProvider returns a generator of the defaults of the software installed by a
+// provider, leaving this one unchanged. Homebrew casks, flatpaks and snaps are confined
+// to paths of their own, named after app, the package of software for the provider,
+// when not empty. The template engine renders each template with the generator of the
+// .Provider of its context.
+func (g *SystemDefaultsGenerator) ForProvider(provider, software, app string) DefaultsGenerator {
+	generator := *g
+	generator.confinement = providerConfinements[provider]
+	generator.software = software
+	generator.app = app
+	return &generator
+}
+
 // DefaultConfigPath generates a default configuration file path for the software
 func (g *SystemDefaultsGenerator) DefaultConfigPath(software string) string {
-	switch runtime.GOOS {
+	switch g.confinement {
+	case confinementCask:
+		return g.caskConfigPath(software)
+	case confinementFlatpak:
+		return g.flatpakConfigPath(software)
+	case confinementSnap:
+		return g.snapConfigPath(software)
+	}
+	switch g.platform {
 	case "linux":
 		return g.linuxConfigPath(software)
 	case "darwin":
@@ -29,13 +91,21 @@ func (g *SystemDefaultsGenerator) DefaultConfigPath(software string) string {
 	case "windows":
 		return g.windowsConfigPath(software)
 	default:
-		return filepath.Join("/etc", software, software+".conf")
+		return path.Join("/etc", software, software+".conf")
 	}
 }
 
 // DefaultLogPath generates a default log file path for the software
 func (g *SystemDefaultsGenerator) DefaultLogPath(software string) string {
-	switch runtime.GOOS {
+	switch g.confinement {
+	case confinementCask:
+		return g.caskLogPath(software)
+	case confinementFlatpak:
+		return g.flatpakLogPath(software)
+	case confinementSnap:
+		return g.snapLogPath(software)
+	}
+	switch g.platform {
 	case "linux":
 		return g.linuxLogPath(software)
 	case "darwin":
@@ -43,13 +113,21 @@ func (g *SystemDefaultsGenerator) DefaultLogPath(software string) string {
 	case "windows":
 		return g.windowsLogPath(software)
 	default:
-		return filepath.Join("/var/log", software+".log")
+		return path.Join("/var/log", software+".log")
 	}
 }
 
 // DefaultDataDir generates a default data directory path for the software
 func (g *SystemDefaultsGenerator) DefaultDataDir(software string) string {
-	switch runtime.GOOS {
+	switch g.confinement {
+	case confinementCask:
+		return g.caskDataDir(software)
+	case confinementFlatpak:
+		return g.flatpakDataDir(software)
+	case confinementSnap:
+		return g.snapDataDir(software)
+	}
+	switch g.platform {
 	case "linux":
 		return g.linuxDataDir(software)
 	case "darwin":
@@ -57,19 +135,32 @@ func (g *SystemDefaultsGenerator) DefaultDataDir(software string) string {
 	case "windows":
 		return g.windowsDataDir(software)
 	default:
-		return filepath.Join("/var/lib", software)
+		return path.Join("/var/lib", software)
 	}
 }
 
 // DefaultServiceName generates a default service name for the software
 func (g *SystemDefaultsGenerator) DefaultServiceName(software string) string {
+	// Snap services are named after the snap and its app
+	if g.confinement == confinementSnap {
+		name := g.appName(software)
+		return "snap." + name + "." + name
+	}
 	// Service names are generally consistent across platforms
 	return software
 }
 
 // DefaultCommandPath generates a default command path for the software
 func (g *SystemDefaultsGenerator) DefaultCommandPath(software string) string {
-	switch runtime.GOOS {
+	switch g.confinement {
+	case confinementCask:
+		return g.caskCommandPath(software)
+	case confinementFlatpak:
+		return g.flatpakCommandPath(software)
+	case confinementSnap:
+		return g.snapCommandPath(software)
+	}
+	switch g.platform {
 	case "linux":
 		return g.linuxCommandPath(software)
 	case "darwin":
@@ -77,7 +168,7 @@ func (g *SystemDefaultsGenerator) DefaultCommandPath(software string) string {
 	case "windows":
 		return g.windowsCommandPath(software)
 	default:
-		return filepath.Join("/usr/bin", software)
+		return path.Join("/usr/bin", software)
 	}
 }
 
@@ -85,143 +176,267 @@ func (g *SystemDefaultsGenerator) DefaultCommandPath(software string) string {
 func (g *SystemDefaultsGenerator) linuxConfigPath(software string) string {
 	// Try common Linux config paths in order of preference
 	candidates := []string{
-		filepath.Join("/etc", software, software+".conf"),
-		filepath.Join("/etc", software+".conf"),
-		filepath.Join("/etc", software, "config"),
-		filepath.Join("/etc", software, software+".yaml"),
-		filepath.Join("/etc", software, software+".yml"),
+		path.Join("/etc", software, software+".conf"),
+		path.Join("/etc", software+".conf"),
+		path.Join("/etc", software, "config"),
+		path.Join("/etc", software, software+".yaml"),
+		path.Join("/etc", software, software+".yml"),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join("/etc", software, software+".conf"))
+	return g.findExistingPath(candidates, path.Join("/etc", software, software+".conf"))
 }
 
 func (g *SystemDefaultsGenerator) linuxLogPath(software string) string {
 	candidates := []string{
-		filepath.Join("/var/log", software, software+".log"),
-		filepath.Join("/var/log", software+".log"),
-		filepath.Join("/var/log", software, "access.log"),
-		filepath.Join("/var/log", software, "error.log"),
+		path.Join("/var/log", software, software+".log"),
+		path.Join("/var/log", software+".log"),
+		path.Join("/var/log", software, "access.log"),
+		path.Join("/var/log", software, "error.log"),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join("/var/log", software+".log"))
+	return g.findExistingPath(candidates, path.Join("/var/log", software+".log"))
 }
 
 func (g *SystemDefaultsGenerator) linuxDataDir(software string) string {
 	candidates := []string{
-		filepath.Join("/var/lib", software),
-		filepath.Join("/opt", software),
-		filepath.Join("/usr/share", software),
-		filepath.Join("/var", software),
+		path.Join("/var/lib", software),
+		path.Join("/opt", software),
+		path.Join("/usr/share", software),
+		path.Join("/var", software),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join("/var/lib", software))
+	return g.findExistingPath(candidates, path.Join("/var/lib", software))
 }
 
 func (g *SystemDefaultsGenerator) linuxCommandPath(software string) string {
 	candidates := []string{
-		filepath.Join("/usr/bin", software),
-		filepath.Join("/usr/local/bin", software),
-		filepath.Join("/bin", software),
-		filepath.Join("/sbin", software),
-		filepath.Join("/usr/sbin", software),
+		path.Join("/usr/bin", software),
+		path.Join("/usr/local/bin", software),
+		path.Join("/bin", software),
+		path.Join("/sbin", software),
+		path.Join("/usr/sbin", software),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join("/usr/bin", software))
+	return g.findExistingPath(candidates, path.Join("/usr/bin", software))
 }
 
 // macOS-specific default paths
 func (g *SystemDefaultsGenerator) macOSConfigPath(software string) string {
 	candidates := []string{
-		filepath.Join("/usr/local/etc", software, software+".conf"),
-		filepath.Join("/usr/local/etc", software+".conf"),
-		filepath.Join("/etc", software, software+".conf"),
-		filepath.Join("/etc", software+".conf"),
-		filepath.Join("/opt/homebrew/etc", software, software+".conf"),
-		filepath.Join("/opt/homebrew/etc", software+".conf"),
+		path.Join("/usr/local/etc", software, software+".conf"),
+		path.Join("/usr/local/etc", software+".conf"),
+		path.Join("/etc", software, software+".conf"),
+		path.Join("/etc", software+".conf"),
+		path.Join("/opt/homebrew/etc", software, software+".conf"),
+		path.Join("/opt/homebrew/etc", software+".conf"),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join("/usr/local/etc", software, software+".conf"))
+	return g.findExistingPath(candidates, path.Join("/usr/local/etc", software, software+".conf"))
 }
 
 func (g *SystemDefaultsGenerator) macOSLogPath(software string) string {
 	candidates := []string{
-		filepath.Join("/usr/local/var/log", software, software+".log"),
-		filepath.Join("/usr/local/var/log", software+".log"),
-		filepath.Join("/var/log", software+".log"),
-		filepath.Join("/opt/homebrew/var/log", software+".log"),
+		path.Join("/usr/local/var/log", software, software+".log"),
+		path.Join("/usr/local/var/log", software+".log"),
+		path.Join("/var/log", software+".log"),
+		path.Join("/opt/homebrew/var/log", software+".log"),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join("/usr/local/var/log", software+".log"))
+	return g.findExistingPath(candidates, path.Join("/usr/local/var/log", software+".log"))
 }
 
 func (g *SystemDefaultsGenerator) macOSDataDir(software string) string {
 	candidates := []string{
-		filepath.Join("/usr/local/var", software),
-		filepath.Join("/usr/local/share", software),
-		filepath.Join("/opt/homebrew/var", software),
-		filepath.Join("/var/lib", software),
+		path.Join("/usr/local/var", software),
+		path.Join("/usr/local/share", software),
+		path.Join("/opt/homebrew/var", software),
+		path.Join("/var/lib", software),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join("/usr/local/var", software))
+	return g.findExistingPath(candidates, path.Join("/usr/local/var", software))
 }
 
 func (g *SystemDefaultsGenerator) macOSCommandPath(software string) string {
 	candidates := []string{
-		filepath.Join("/usr/local/bin", software),
-		filepath.Join("/opt/homebrew/bin", software),
-		filepath.Join("/usr/bin", software),
-		filepath.Join("/bin", software),
+		path.Join("/usr/local/bin", software),
+		path.Join("/opt/homebrew/bin", software),
+		path.Join("/usr/bin", software),
+		path.Join("/bin", software),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join("/usr/local/bin", software))
+	return g.findExistingPath(candidates, path.Join("/usr/local/bin", software))
 }
 
 // Windows-specific default paths
 func (g *SystemDefaultsGenerator) windowsConfigPath(software string) string {
 	programData := g.getWindowsProgramData()
 	candidates := []string{
-		filepath.Join(programData, strings.Title(software), software+".conf"),
-		filepath.Join(programData, strings.Title(software), "config", software+".conf"),
-		filepath.Join(programData, strings.Title(software), software+".ini"),
-		filepath.Join(programData, strings.Title(software), "config.ini"),
+		windowsPath(programData, strings.Title(software), software+".conf"),
+		windowsPath(programData, strings.Title(software), "config", software+".conf"),
+		windowsPath(programData, strings.Title(software), software+".ini"),
+		windowsPath(programData, strings.Title(software), "config.ini"),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join(programData, strings.Title(software), software+".conf"))
+	return g.findExistingPath(candidates, windowsPath(programData, strings.Title(software), software+".conf"))
 }
 
 func (g *SystemDefaultsGenerator) windowsLogPath(software string) string {
 	programData := g.getWindowsProgramData()
 	candidates := []string{
-		filepath.Join(programData, strings.Title(software), "logs", software+".log"),
-		filepath.Join(programData, strings.Title(software), software+".log"),
-		filepath.Join("C:", "logs", software+".log"),
+		windowsPath(programData, strings.Title(software), "logs", software+".log"),
+		windowsPath(programData, strings.Title(software), software+".log"),
+		windowsPath("C:", "logs", software+".log"),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join(programData, strings.Title(software), "logs", software+".log"))
+	return g.findExistingPath(candidates, windowsPath(programData, strings.Title(software), "logs", software+".log"))
 }
 
 func (g *SystemDefaultsGenerator) windowsDataDir(software string) string {
 	programData := g.getWindowsProgramData()
 	candidates := []string{
-		filepath.Join(programData, strings.Title(software)),
-		filepath.Join(programData, strings.Title(software), "data"),
-		filepath.Join("C:", strings.Title(software)),
+		windowsPath(programData, strings.Title(software)),
+		windowsPath(programData, strings.Title(software), "data"),
+		windowsPath("C:", strings.Title(software)),
 	}
 	
-	return g.findExistingPath(candidates, filepath.Join(programData, strings.Title(software)))
+	return g.findExistingPath(candidates, windowsPath(programData, strings.Title(software)))
 }
 
 func (g *SystemDefaultsGenerator) windowsCommandPath(software string) string {
 	candidates := []string{
-		filepath.Join("C:", "Program Files", strings.Title(software), software+".exe"),
-		filepath.Join("C:", "Program Files (x86)", strings.Title(software), software+".exe"),
+		windowsPath("C:", "Program Files", strings.Title(software), software+".exe"),
+		windowsPath("C:", "Program Files (x86)", strings.Title(software), software+".exe"),
 		software + ".exe", // Assume it's in PATH
 	}
 	
 	return g.findExistingPath(candidates, software+".exe")
 }
 
+// Homebrew cask default paths, named after the application bundle
+func (g *SystemDefaultsGenerator) caskConfigPath(software string) string {
+	bundle := caskBundleName(g.appName(software))
+	home := g.homeDir()
+	candidates := []string{
+		path.Join(home, "Library", "Preferences", bundle),
+		path.Join(home, "Library", "Application Support", bundle),
+	}
+	
+	return g.findExistingPath(candidates, candidates[0])
+}
+
+func (g *SystemDefaultsGenerator) caskLogPath(software string) string {
+	bundle := caskBundleName(g.appName(software))
+	candidates := []string{
+		path.Join(g.homeDir(), "Library", "Logs", bundle),
+		path.Join("/Library", "Logs", bundle),
+	}
+	
+	return g.findExistingPath(candidates, candidates[0])
+}
+
+func (g *SystemDefaultsGenerator) caskDataDir(software string) string {
+	bundle := caskBundleName(g.appName(software))
+	candidates := []string{
+		path.Join(g.homeDir(), "Library", "Application Support", bundle),
+		path.Join("/Library", "Application Support", bundle),
+	}
+	
+	return g.findExistingPath(candidates, candidates[0])
+}
+
+func (g *SystemDefaultsGenerator) caskCommandPath(software string) string {
+	bundle := caskBundleName(g.appName(software))
+	candidates := []string{
+		path.Join("/Applications", bundle+".app", "Contents", "MacOS", bundle),
+		path.Join(g.homeDir(), "Applications", bundle+".app", "Contents", "MacOS", bundle),
+		// Casks shipping a command link it to the Homebrew prefix
+		path.Join("/opt/homebrew/bin", software),
+		path.Join("/usr/local/bin", software),
+	}
+	
+	return g.findExistingPath(candidates, candidates[0])
+}
+
+// Flatpak default paths, named after the application ID. The data of an application
+// is kept per user, whether it was installed system wide or not.
+func (g *SystemDefaultsGenerator) flatpakConfigPath(software string) string {
+	return path.Join(g.homeDir(), ".var", "app", g.appName(software), "config")
+}
+
+func (g *SystemDefaultsGenerator) flatpakLogPath(software string) string {
+	return path.Join(g.homeDir(), ".var", "app", g.appName(software), "cache")
+}
+
+func (g *SystemDefaultsGenerator) flatpakDataDir(software string) string {
+	return path.Join(g.homeDir(), ".var", "app", g.appName(software), "data")
+}
+
+func (g *SystemDefaultsGenerator) flatpakCommandPath(software string) string {
+	app := g.appName(software)
+	candidates := []string{
+		path.Join("/var/lib/flatpak/exports/bin", app),
+		path.Join(g.homeDir(), ".local", "share", "flatpak", "exports", "bin", app),
+	}
+	
+	return g.findExistingPath(candidates, candidates[0])
+}
+
+// Snap default paths, named after the snap
+func (g *SystemDefaultsGenerator) snapConfigPath(software string) string {
+	return path.Join("/var/snap", g.appName(software), "current")
+}
+
+func (g *SystemDefaultsGenerator) snapLogPath(software string) string {
+	name := g.appName(software)
+	candidates := []string{
+		path.Join("/var/snap", name, "common", "logs"),
+		path.Join("/var/snap", name, "common", "log"),
+		path.Join("/var/snap", name, "current", "logs"),
+	}
+	
+	return g.findExistingPath(candidates, candidates[0])
+}
+
+func (g *SystemDefaultsGenerator) snapDataDir(software string) string {
+	name := g.appName(software)
+	candidates := []string{
+		path.Join("/var/snap", name, "common"),
+		path.Join("/var/snap", name, "current"),
+		path.Join("/snap", name, "current"),
+	}
+	
+	return g.findExistingPath(candidates, candidates[0])
+}
+
+func (g *SystemDefaultsGenerator) snapCommandPath(software string) string {
+	return path.Join("/snap/bin", g.appName(software))
+}
+
 // Helper functions
+
+// appName returns the name the provider installed software as, software itself for
+// the software other than the one of the generator
+func (g *SystemDefaultsGenerator) appName(software string) string {
+	if g.app != "" && software == g.software {
+		return g.app
+	}
+	return software
+}
+
+// homeDir returns the home directory of the user, ~ when unknown
+func (g *SystemDefaultsGenerator) homeDir() string {
+	if home, err := userHomeDir(); err == nil && home != "" {
+		return home
+	}
+	return "~"
+}
+
+// caskBundleName returns the name of the application bundle of a cask, the cask token
+// in title case: visual-studio-code is installed as Visual Studio Code.app
+func caskBundleName(token string) string {
+	return strings.Title(strings.ReplaceAll(token, "-", " "))
+}
+
 func (g *SystemDefaultsGenerator) findExistingPath(candidates []string, defaultPath string) string {
 	if g.validator == nil {
 		return defaultPath
@@ -236,8 +451,17 @@ func (g *SystemDefaultsGenerator) findExistingPath(candidates []string, defaultP
 	return defaultPath
 }
 
+// windowsPath joins path elements with backslashes, whatever the running platform
+func windowsPath(elem ...string) string {
+	return strings.Join(elem, "\\")
+}
+
 func (g *SystemDefaultsGenerator) getWindowsProgramData() string {
-	programData := os.Getenv("PROGRAMDATA")
+	// Only a running Windows system tells where its program data is
+	programData := ""
+	if runtime.GOOS == "windows" {
+		programData = os.Getenv("PROGRAMDATA")
+	}
 	if programData == "" {
 		programData = "C:\\ProgramData"
 	}
@@ -1,6 +1,7 @@
 package template
 
 import (
+	"os"
 	"runtime"
 	"strings"
 	"testing"
@@ -218,12 +219,9 @@ func TestSystemDefaultsGenerator_FindExistingPath(t *testing.T) {
 }
 
 func TestSystemDefaultsGenerator_LinuxPaths(t *testing.T) {
-	if runtime.GOOS != "linux" {
-		t.Skip("Skipping Linux-specific test on non-Linux platform")
-	}
-	
 	validator := NewMockResourceValidator()
 	generator := NewSystemDefaultsGenerator(validator)
+	generator.SetPlatform("linux")
 	
 	software := "nginx"
 	
@@ -250,12 +248,9 @@ func TestSystemDefaultsGenerator_LinuxPaths(t *testing.T) {
 }
 
 func TestSystemDefaultsGenerator_MacOSPaths(t *testing.T) {
-	if runtime.GOOS != "darwin" {
-		t.Skip("Skipping macOS-specific test on non-macOS platform")
-	}
-	
 	validator := NewMockResourceValidator()
 	generator := NewSystemDefaultsGenerator(validator)
+	generator.SetPlatform("darwin")
 	
 	software := "nginx"
 	
@@ -282,12 +277,9 @@ func TestSystemDefaultsGenerator_MacOSPaths(t *testing.T) {
 }
 
 func TestSystemDefaultsGenerator_WindowsPaths(t *testing.T) {
-	if runtime.GOOS != "windows" {
-		t.Skip("Skipping Windows-specific test on non-Windows platform")
-	}
-	
 	validator := NewMockResourceValidator()
 	generator := NewSystemDefaultsGenerator(validator)
+	generator.SetPlatform("windows")
 	
 	software := "nginx"
 	
@@ -311,4 +303,52 @@ func TestSystemDefaultsGenerator_WindowsPaths(t *testing.T) {
 	if !strings.HasSuffix(commandPath, ".exe") {
 		t.Errorf("Expected Windows command path to end with .exe, got %s", commandPath)
 	}
-}
\ No newline at end of file
+}
+
+func TestSystemDefaultsGenerator_ForProvider(t *testing.T) {
+	userHomeDir = func() (string, error) { return "/home/user", nil }
+	defer func() { userHomeDir = os.UserHomeDir }()
+	
+	validator := NewMockResourceValidator()
+	generator := NewSystemDefaultsGenerator(validator)
+	generator.SetPlatform("linux")
+	
+	flatpak := generator.ForProvider("flatpak", "firefox", "org.mozilla.firefox")
+	if dataDir := flatpak.DefaultDataDir("firefox"); dataDir != "/home/user/.var/app/org.mozilla.firefox/data" {
+		t.Errorf("Expected flatpak data dir under ~/.var/app, got %s", dataDir)
+	}
+	if configPath := flatpak.DefaultConfigPath("firefox"); configPath != "/home/user/.var/app/org.mozilla.firefox/config" {
+		t.Errorf("Expected flatpak config path under ~/.var/app, got %s", configPath)
+	}
+	// Other software keeps its own name
+	if dataDir := flatpak.DefaultDataDir("gimp"); dataDir != "/home/user/.var/app/gimp/data" {
+		t.Errorf("Expected flatpak data dir of other software named after it, got %s", dataDir)
+	}
+	
+	snap := generator.ForProvider("snap", "lxd", "lxd")
+	if commandPath := snap.DefaultCommandPath("lxd"); commandPath != "/snap/bin/lxd" {
+		t.Errorf("Expected snap command under /snap/bin, got %s", commandPath)
+	}
+	if serviceName := snap.DefaultServiceName("lxd"); serviceName != "snap.lxd.lxd" {
+		t.Errorf("Expected snap service name, got %s", serviceName)
+	}
+	validator.SetFileExists("/var/snap/lxd/common", false)
+	validator.SetDirectoryExists("/var/snap/lxd/common", false)
+	if dataDir := snap.DefaultDataDir("lxd"); dataDir != "/var/snap/lxd/current" {
+		t.Errorf("Expected existing snap data dir, got %s", dataDir)
+	}
+	
+	cask := generator.ForProvider("brew-cask", "visual-studio-code", "")
+	if commandPath := cask.DefaultCommandPath("visual-studio-code"); commandPath != "/Applications/Visual Studio Code.app/Contents/MacOS/Visual Studio Code" {
+		t.Errorf("Expected cask command in its application bundle, got %s", commandPath)
+	}
+	if logPath := cask.DefaultLogPath("visual-studio-code"); logPath != "/home/user/Library/Logs/Visual Studio Code" {
+		t.Errorf("Expected cask logs under ~/Library/Logs, got %s", logPath)
+	}
+	
+	// Providers without confinement keep the defaults of the platform
+	apt := generator.ForProvider("apt", "nginx", "nginx-full")
+	if dataDir := apt.DefaultDataDir("nginx"); dataDir != "/var/lib/nginx" {
+		t.Errorf("Expected platform data dir, got %s", dataDir)
+	}
+}
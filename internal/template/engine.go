@@ -1,6 +1,7 @@
 package template
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,18 +12,33 @@ import (
 
 	"sai/internal/debug"
 	"sai/internal/interfaces"
+	"sai/internal/servicemgr"
 	"sai/internal/types"
+	"sai/internal/version"
 )
 
-// TemplateEngine provides template rendering with saidata functions
+// TemplateEngine provides template rendering with saidata functions. Renderings keep
+// their saidata and context in a renderer of their own, so an engine may render
+// templates from several goroutines.
 type TemplateEngine struct {
-	template     *template.Template
-	saidata      *types.SoftwareData
+	funcs        template.FuncMap // Functions of templates parsed without rendering them
 	safetyMode   bool
 	validator    ResourceValidator
 	defaultsGen  DefaultsGenerator
 }
 
+// renderer is the state of one rendering: the saidata and context the template
+// functions, its methods, resolve against
+type renderer struct {
+	*TemplateEngine
+	saidata  *types.SoftwareData
+	provider string
+	software string
+	root     string              // Alternate root prefixed to saidata paths
+	version  *version.Constraint // Version requested with software@version, nil for none
+	defaults DefaultsGenerator   // Defaults of the platform of the context
+}
+
 // ResourceValidator validates resource existence
 type ResourceValidator interface {
 	FileExists(path string) bool
@@ -40,6 +56,18 @@ type DefaultsGenerator interface {
 	DefaultCommandPath(software string) string
 }
 
+// platformDefaults is implemented by defaults generators generating the defaults of a
+// given platform rather than of the running one
+type platformDefaults interface {
+	ForPlatform(platform string) DefaultsGenerator
+}
+
+// providerDefaults is implemented by defaults generators generating the defaults of the
+// software installed by a given provider, app being the package of software for it
+type providerDefaults interface {
+	ForProvider(provider, software, app string) DefaultsGenerator
+}
+
 // TemplateContext is an alias to the interfaces.TemplateContext for compatibility
 type TemplateContext = interfaces.TemplateContext
 
@@ -51,10 +79,7 @@ func NewTemplateEngine(validator ResourceValidator, defaultsGen DefaultsGenerato
 		safetyMode:  true,
 	}
 	
-	// Create template with custom functions
-	tmpl := template.New("sai").Funcs(engine.createFuncMap())
-	engine.template = tmpl
-	
+	engine.funcs = engine.createFuncMap()
 	return engine
 }
 
@@ -63,9 +88,38 @@ func (e *TemplateEngine) SetSafetyMode(enabled bool) {
 	e.safetyMode = enabled
 }
 
-// SetSaidata sets the current saidata context for template functions
-func (e *TemplateEngine) SetSaidata(saidata *types.SoftwareData) {
-	e.saidata = saidata
+// SetSaidata is kept for the TemplateEngine interface.
+//
+// Deprecated: templates render against the Saidata of their TemplateContext.
+func (e *TemplateEngine) SetSaidata(saidata *types.SoftwareData) {}
+
+// newRenderer returns the renderer of a template context, nil rendering templates
+// without saidata
+func (e *TemplateEngine) newRenderer(context *TemplateContext) (*renderer, error) {
+	r := &renderer{TemplateEngine: e, defaults: e.defaultsGen}
+	if context == nil {
+		return r, nil
+	}
+	constraint, err := parseContextVersion(context.Version)
+	if err != nil {
+		return nil, err
+	}
+	r.saidata = context.Saidata
+	r.provider = context.Provider
+	r.software = context.Software
+	r.root = context.Root
+	r.version = constraint
+	if defaults, ok := e.defaultsGen.(platformDefaults); ok {
+		r.defaults = defaults.ForPlatform(context.Platform)
+	}
+	if defaults, ok := r.defaults.(providerDefaults); ok {
+		app := ""
+		if r.saidata != nil {
+			app, _ = r.getPackageByIndex(r.provider, 0)
+		}
+		r.defaults = defaults.ForProvider(r.provider, r.software, app)
+	}
+	return r, nil
 }
 
 // Render renders a template string with the given context
@@ -77,35 +131,77 @@ func (e *TemplateEngine) Render(templateStr string, context *TemplateContext) (s
 		return "", fmt.Errorf("template context cannot be nil")
 	}
 	
-	// Set saidata context for template functions
-	e.saidata = context.Saidata
+	r, err := e.newRenderer(context)
+	if err != nil {
+		debug.LogTemplateResolutionGlobal(templateStr, nil, "", false, time.Since(startTime), err)
+		return "", err
+	}
 	
 	// Preprocess template to convert legacy syntax to Go template syntax
 	processedTemplate := e.preprocessTemplate(templateStr)
 	
-	// Parse the template
-	tmpl, err := e.template.Clone()
-	if err != nil {
-		debug.LogTemplateResolutionGlobal(templateStr, e.createVariableMap(context), "", false, time.Since(startTime), fmt.Errorf("failed to clone template: %w", err))
-		return "", fmt.Errorf("failed to clone template: %w", err)
-	}
-	
-	tmpl, err = tmpl.Parse(processedTemplate)
+	// Parse the template with the functions of this rendering
+	tmpl, err := template.New("sai").Funcs(r.createFuncMap()).Parse(processedTemplate)
 	if err != nil {
 		debug.LogTemplateResolutionGlobal(templateStr, e.createVariableMap(context), "", false, time.Since(startTime), fmt.Errorf("failed to parse template: %w", err))
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
 	
-	// Create template data
+	// Create template data; .Runtime is always set so templates can reference its fields
+	runtime := context.Runtime
+	if runtime == nil {
+		runtime = &interfaces.RuntimeContext{}
+	}
 	data := map[string]interface{}{
-		"Software":  context.Software,
-		"Provider":  context.Provider,
-		"Variables": context.Variables,
+		"Software":   context.Software,
+		"Provider":   context.Provider,
+		"Executable": context.Executable,
+		"Variables":  context.Variables,
+		"Runtime":    runtime,
+		"Registered": runtime.Values,
+		"Workflow":   runtime.Workflow,
+		"Root":       context.Root,
+
+		// Requested version: .Version is the pinned version, empty for a range, and
+		// .VersionConstraint the constraint terms separated by commas
+		"Version":           "",
+		"VersionConstraint": "",
+
+		"Platform":       context.Platform,
+		"OS":             context.OS,
+		"OSVersion":      context.OSVersion,
+		"Arch":           context.Arch,
+		"PackageManager": context.PackageManager,
+	}
+	
+	if r.version != nil {
+		data["Version"] = r.version.Pinned()
+		data["VersionConstraint"] = strings.Join(r.version.Terms(), ",")
 	}
 	
 	// Execute template
 	var buf strings.Builder
 	if err := tmpl.Execute(&buf, data); err != nil {
+		// Failed assertions carry the provider author's message as is
+		var assertionErr *TemplateAssertionError
+		if errors.As(err, &assertionErr) {
+			debug.LogTemplateResolutionGlobal(templateStr, e.createVariableMap(context), "", false, time.Since(startTime), assertionErr)
+			return "", assertionErr
+		}
+		// Template functions return errors instead of embedding them in the output, so a
+		// failing sai_* call stops the rendering whatever the safety mode. text/template
+		// wraps only the errors of function calls.
+		var execErr template.ExecError
+		if errors.As(err, &execErr) && errors.Unwrap(execErr.Err) != nil {
+			functionErr := &TemplateResolutionError{
+				Type:     "function_error",
+				Message:  fmt.Sprintf("Template function failed: %v", execErr.Err),
+				Template: templateStr,
+				Context:  context,
+			}
+			debug.LogTemplateResolutionGlobal(templateStr, e.createVariableMap(context), "", false, time.Since(startTime), functionErr)
+			return "", functionErr
+		}
 		debug.LogTemplateResolutionGlobal(templateStr, e.createVariableMap(context), "", false, time.Since(startTime), fmt.Errorf("failed to execute template: %w", err))
 		return "", fmt.Errorf("failed to execute template: %w", err)
 	}
@@ -116,7 +212,7 @@ func (e *TemplateEngine) Render(templateStr string, context *TemplateContext) (s
 	// Validate template resolution if safety mode is enabled
 	var validationErr error
 	if e.safetyMode {
-		if err := e.validateTemplateResolution(result, processedTemplate, context); err != nil {
+		if err := e.validateTemplateResolution(result, context); err != nil {
 			validationErr = fmt.Errorf("template validation failed: %w", err)
 			debug.LogTemplateResolutionGlobal(templateStr, e.createVariableMap(context), result, false, resolutionTime, validationErr)
 			return "", validationErr
@@ -134,12 +230,7 @@ func (e *TemplateEngine) ValidateTemplate(templateStr string) error {
 	// Preprocess template to convert legacy syntax to Go template syntax
 	processedTemplate := e.preprocessTemplate(templateStr)
 	
-	tmpl, err := e.template.Clone()
-	if err != nil {
-		return fmt.Errorf("failed to clone template: %w", err)
-	}
-	
-	_, err = tmpl.Parse(processedTemplate)
+	_, err := template.New("sai").Funcs(e.funcs).Parse(processedTemplate)
 	if err != nil {
 		return fmt.Errorf("template syntax error: %w", err)
 	}
@@ -147,31 +238,67 @@ func (e *TemplateEngine) ValidateTemplate(templateStr string) error {
 	return nil
 }
 
-// createFuncMap creates the function map for template functions
+// createFuncMap creates the function map of templates parsed without rendering them,
+// whose functions resolve against no saidata
 func (e *TemplateEngine) createFuncMap() template.FuncMap {
+	r, _ := e.newRenderer(nil)
+	return r.createFuncMap()
+}
+
+// createFuncMap creates the function map for template functions
+func (r *renderer) createFuncMap() template.FuncMap {
 	return template.FuncMap{
 		// Saidata functions - now support multiple calling patterns
-		"sai_package":       e.saiPackage,
-		"sai_packages":      e.saiPackages,
-		"sai_service":       e.saiService,
-		"sai_port":          e.saiPort,
-		"sai_file":          e.saiFile,
-		"sai_directory":     e.saiDirectory,
-		"sai_command":       e.saiCommand,
-		"sai_container":     e.saiContainer,
+		"sai_package":       r.saiPackage,
+		"sai_packages":      r.saiPackages,
+		"sai_service":       r.saiService,
+		"sai_service_command": r.saiServiceCommand,
+		"sai_port":          r.saiPort,
+		"sai_file":          r.saiFile,
+		"sai_directory":     r.saiDirectory,
+		"sai_command":       r.saiCommand,
+		"sai_container":     r.saiContainer,
+		"sai_k8s_manifest":  r.saiK8sManifest,
+		"sai_helm_values":   r.saiHelmValues,
+		"sai_helm_chart":    r.saiHelmChart,
+		
+		// List functions building shell-quoted commands over all packages
+		"sai_package_list":  r.saiPackageList,
+		"sai_versioned":     r.saiVersioned,
+		"map":               r.mapList,
+		"quote":             r.quote,
+		"join":              r.joinList,
+		
+		// Escaping functions for values built in templates
+		"shellquote":        r.quote,
+		"regexquote":        r.regexQuote,
+		"pathjoin":          r.pathJoin,
+		
+		// Assertion functions failing the action with an author-written message
+		"assert":            r.assert,
+		"sai_require":       r.saiRequire,
 		
 		// Safety validation functions
-		"file_exists":       e.fileExists,
-		"service_exists":    e.serviceExists,
-		"command_exists":    e.commandExists,
-		"directory_exists":  e.directoryExists,
+		"file_exists":       r.fileExists,
+		"service_exists":    r.serviceExists,
+		"command_exists":    r.commandExists,
+		"directory_exists":  r.directoryExists,
+		"checksum_file":     r.checksumFile,
+		"checksum_matches":  r.checksumMatches,
+		
+		// Conditional functions choosing values by the existence of resources
+		"first_existing_path": r.firstExistingPath,
+		"when_file":         r.whenFile,
+		"when_directory":    r.whenDirectory,
+		"when_service":      r.whenService,
+		"when_command":      r.whenCommand,
 		
 		// Default generation functions
-		"default_config_path": e.defaultConfigPath,
-		"default_log_path":    e.defaultLogPath,
-		"default_data_dir":    e.defaultDataDir,
-		"default_service_name": e.defaultServiceName,
-		"default_command_path": e.defaultCommandPath,
+		"default_config_path": r.defaultConfigPath,
+		"default_log_path":    r.defaultLogPath,
+		"default_data_dir":    r.defaultDataDir,
+		"default_service_name": r.defaultServiceName,
+		"default_command_path": r.defaultCommandPath,
 	}
 }
 
@@ -181,13 +308,15 @@ func (e *TemplateEngine) createFuncMap() template.FuncMap {
 // - sai_package("provider", index) - returns package at index for provider  
 // - sai_package("*", "name", "provider") - returns all package names for provider (space-separated)
 // - sai_package(index, "name", "provider") - returns package name at index for provider
-func (e *TemplateEngine) saiPackage(args ...interface{}) string {
-	if e.saidata == nil {
-		return "sai_package error: no saidata context available"
+// - sai_package("*"|index, "name") - same as above using the provider from the template context
+// Package names are shell-quoted, so names with spaces or metacharacters stay one word.
+func (r *renderer) saiPackage(args ...interface{}) (string, error) {
+	if r.saidata == nil {
+		return "", errors.New("no saidata context available")
 	}
 	
 	if len(args) == 0 {
-		return "sai_package error: requires at least one argument"
+		return "", errors.New("requires at least one argument")
 	}
 	
 	// Handle different calling patterns
@@ -196,71 +325,85 @@ func (e *TemplateEngine) saiPackage(args ...interface{}) string {
 		// sai_package("provider") - return first package
 		provider, ok := args[0].(string)
 		if !ok {
-			return "sai_package error: first argument must be provider name (string)"
+			return "", errors.New("first argument must be provider name (string)")
 		}
-		result, err := e.getPackageByIndex(provider, 0)
+		result, err := r.getPackageByIndex(provider, 0)
 		if err != nil {
-			return fmt.Sprintf("sai_package error: %v", err)
+			return "", err
 		}
-		return result
+		return shellQuote(result), nil
 		
 	case 2:
+		// sai_package("*"|index, "name") - legacy format defaulting to the context provider
+		if _, ok := args[1].(string); ok {
+			return r.saiPackage(args[0], args[1], r.provider)
+		}
+		
 		// sai_package("provider", index) - return package at index
 		provider, ok := args[0].(string)
 		if !ok {
-			return "sai_package error: first argument must be provider name (string)"
+			return "", errors.New("first argument must be provider name (string)")
 		}
 		idx, ok := args[1].(int)
 		if !ok {
-			return "sai_package error: second argument must be index (int)"
+			return "", errors.New("second argument must be index (int)")
 		}
-		result, err := e.getPackageByIndex(provider, idx)
+		result, err := r.getPackageByIndex(provider, idx)
 		if err != nil {
-			return fmt.Sprintf("sai_package error: %v", err)
+			return "", err
 		}
-		return result
+		return shellQuote(result), nil
 		
 	case 3:
 		// Handle legacy provider template format: sai_package("*"|index, "name", "provider")
 		provider, ok := args[2].(string)
 		if !ok {
-			return "sai_package error: third argument must be provider name (string)"
+			return "", errors.New("third argument must be provider name (string)")
 		}
 		
 		field, ok := args[1].(string)
-		if !ok || field != "name" {
-			return "sai_package error: second argument must be 'name' field"
+		if !ok || (field != "name" && field != "checksum") {
+			return "", errors.New("second argument must be 'name' or 'checksum' field")
+		}
+		
+		// Checksums are looked up by index, for checksum_matches
+		if field == "checksum" {
+			idx, ok := args[0].(int)
+			if !ok {
+				return "", errors.New("first argument must be index (int) for the 'checksum' field")
+			}
+			return r.getPackageChecksum(provider, idx)
 		}
 		
 		// Check if first arg is "*" for all packages
 		if firstArg, ok := args[0].(string); ok && firstArg == "*" {
-			result, err := e.getAllPackageNames(provider)
+			result, err := r.getAllPackageNames(provider)
 			if err != nil {
-				return fmt.Sprintf("sai_package error: %v", err)
+				return "", err
 			}
-			return result
+			return result, nil
 		}
 		
 		// Otherwise treat first arg as index
 		if idx, ok := args[0].(int); ok {
-			result, err := e.getPackageByIndex(provider, idx)
+			result, err := r.getPackageByIndex(provider, idx)
 			if err != nil {
-				return fmt.Sprintf("sai_package error: %v", err)
+				return "", err
 			}
-			return result
+			return shellQuote(result), nil
 		}
 		
-		return "sai_package error: first argument must be '*' or index (int)"
+		return "", errors.New("first argument must be '*' or index (int)")
 		
 	default:
-		return fmt.Sprintf("sai_package error: accepts 1-3 arguments, got %d", len(args))
+		return "", fmt.Errorf("accepts 1-3 arguments, got %d", len(args))
 	}
 }
 
 // getPackageByIndex returns package name at specific index for provider
-func (e *TemplateEngine) getPackageByIndex(provider string, idx int) (string, error) {
+func (r *renderer) getPackageByIndex(provider string, idx int) (string, error) {
 	// Check provider-specific packages first
-	if providerConfig := e.saidata.GetProviderConfig(provider); providerConfig != nil {
+	if providerConfig := r.saidata.GetProviderConfig(provider); providerConfig != nil {
 		if len(providerConfig.Packages) > idx {
 			// Use GetPackageNameOrDefault method for consistent naming
 			return providerConfig.Packages[idx].GetPackageNameOrDefault(), nil
@@ -268,23 +411,39 @@ func (e *TemplateEngine) getPackageByIndex(provider string, idx int) (string, er
 	}
 	
 	// Fall back to default packages
-	if len(e.saidata.Packages) > idx {
+	if len(r.saidata.Packages) > idx {
 		// Use GetPackageNameOrDefault method for consistent naming
-		return e.saidata.Packages[idx].GetPackageNameOrDefault(), nil
+		return r.saidata.Packages[idx].GetPackageNameOrDefault(), nil
+	}
+	
+	return "", fmt.Errorf("no package found at index %d for provider %s", idx, provider)
+}
+
+// getPackageChecksum returns the checksum of the package at index for provider, empty
+// when saidata declares none
+func (r *renderer) getPackageChecksum(provider string, idx int) (string, error) {
+	if providerConfig := r.saidata.GetProviderConfig(provider); providerConfig != nil {
+		if len(providerConfig.Packages) > idx {
+			return providerConfig.Packages[idx].Checksum, nil
+		}
+	}
+	
+	if len(r.saidata.Packages) > idx {
+		return r.saidata.Packages[idx].Checksum, nil
 	}
 	
-	return fmt.Sprintf("sai_package error: no package found at index %d for provider %s", idx, provider), nil
+	return "", fmt.Errorf("no package found at index %d for provider %s", idx, provider)
 }
 
-// getAllPackageNames returns all package names for provider (space-separated)
-func (e *TemplateEngine) getAllPackageNames(provider string) (string, error) {
+// getAllPackageNames returns all package names for provider, shell-quoted and space-separated
+func (r *renderer) getAllPackageNames(provider string) (string, error) {
 	var packages []string
 	
 	// Check provider-specific packages first
-	if providerConfig := e.saidata.GetProviderConfig(provider); providerConfig != nil {
+	if providerConfig := r.saidata.GetProviderConfig(provider); providerConfig != nil {
 		for _, pkg := range providerConfig.Packages {
 			// Use GetPackageNameOrDefault method for consistent naming
-			packages = append(packages, pkg.GetPackageNameOrDefault())
+			packages = append(packages, shellQuote(pkg.GetPackageNameOrDefault()))
 		}
 		if len(packages) > 0 {
 			return strings.Join(packages, " "), nil
@@ -292,61 +451,83 @@ func (e *TemplateEngine) getAllPackageNames(provider string) (string, error) {
 	}
 	
 	// Fall back to default packages
-	for _, pkg := range e.saidata.Packages {
+	for _, pkg := range r.saidata.Packages {
 		// Use GetPackageNameOrDefault method for consistent naming
-		packages = append(packages, pkg.GetPackageNameOrDefault())
+		packages = append(packages, shellQuote(pkg.GetPackageNameOrDefault()))
 	}
 	
 	if len(packages) == 0 {
-		return fmt.Sprintf("sai_package error: no packages found for provider %s", provider), nil
+		return "", fmt.Errorf("no packages found for provider %s", provider)
 	}
 	
 	return strings.Join(packages, " "), nil
 }
 
 // saiPackages returns all package names for a specific provider as a space-separated string
-func (e *TemplateEngine) saiPackages(provider string) string {
-	if e.saidata == nil {
-		return "sai_packages error: no saidata context available"
+// of shell-quoted words
+// The provider defaults to the template context provider when omitted
+func (r *renderer) saiPackages(args ...string) (string, error) {
+	if r.saidata == nil {
+		return "", errors.New("no saidata context available")
+	}
+	
+	if len(args) > 1 {
+		return "", fmt.Errorf("accepts 0 or 1 arguments, got %d", len(args))
+	}
+	
+	provider := r.provider
+	if len(args) == 1 {
+		provider = args[0]
 	}
 	
 	var packages []string
 	
 	// Check provider-specific packages first
-	if providerConfig := e.saidata.GetProviderConfig(provider); providerConfig != nil {
+	if providerConfig := r.saidata.GetProviderConfig(provider); providerConfig != nil {
 		for _, pkg := range providerConfig.Packages {
 			// Use GetPackageNameOrDefault method for consistent naming
-			packages = append(packages, pkg.GetPackageNameOrDefault())
+			packages = append(packages, shellQuote(pkg.GetPackageNameOrDefault()))
 		}
 		if len(packages) > 0 {
-			return strings.Join(packages, " ")
+			return strings.Join(packages, " "), nil
 		}
 	}
 	
 	// Fall back to default packages
-	for _, pkg := range e.saidata.Packages {
+	for _, pkg := range r.saidata.Packages {
 		// Use GetPackageNameOrDefault method for consistent naming
-		packages = append(packages, pkg.GetPackageNameOrDefault())
+		packages = append(packages, shellQuote(pkg.GetPackageNameOrDefault()))
 	}
 	
 	if len(packages) == 0 {
-		return fmt.Sprintf("sai_packages error: no packages found for provider %s", provider)
+		return "", fmt.Errorf("no packages found for provider %s", provider)
 	}
 	
-	return strings.Join(packages, " ")
+	return strings.Join(packages, " "), nil
 }
 
 // saiService returns the service name
 // Supports multiple calling patterns:
 // - sai_service("name") - returns service_name for service with logical name
 // - sai_service(index, "service_name", "provider") - returns service_name at index for provider
-func (e *TemplateEngine) saiService(args ...interface{}) string {
-	if e.saidata == nil {
-		return "sai_service error: no saidata context available"
+// - sai_service(index, "service_name") - same as above using the provider from the template context
+// The service name is shell-quoted.
+func (r *renderer) saiService(args ...interface{}) (string, error) {
+	name, err := r.serviceName(args...)
+	if err != nil {
+		return "", err
+	}
+	return shellQuote(name), nil
+}
+
+// serviceName returns the service name selected by the arguments of sai_service
+func (r *renderer) serviceName(args ...interface{}) (string, error) {
+	if r.saidata == nil {
+		return "", errors.New("no saidata context available")
 	}
 	
 	if len(args) == 0 {
-		return "sai_service error: requires at least one argument"
+		return "", errors.New("requires at least one argument")
 	}
 	
 	switch len(args) {
@@ -354,56 +535,74 @@ func (e *TemplateEngine) saiService(args ...interface{}) string {
 		// sai_service("name") - return service_name for logical name
 		name, ok := args[0].(string)
 		if !ok {
-			return "sai_service error: argument must be service name (string)"
+			return "", errors.New("argument must be service name (string)")
 		}
 		
-		service := e.saidata.GetServiceByName(name)
+		service := r.saidata.GetServiceByName(name)
 		if service == nil {
-			return fmt.Sprintf("sai_service error: service %s not found", name)
+			return "", fmt.Errorf("service %s not found", name)
 		}
 		
-		return service.GetServiceNameOrDefault()
+		return service.GetServiceNameOrDefault(), nil
+		
+	case 2:
+		// Legacy format without provider: sai_service(index, "service_name")
+		return r.serviceName(args[0], args[1], r.provider)
 		
 	case 3:
 		// Handle legacy provider template format: sai_service(index, "service_name", "provider")
 		provider, ok := args[2].(string)
 		if !ok {
-			return "sai_service error: third argument must be provider name (string)"
+			return "", errors.New("third argument must be provider name (string)")
 		}
 		
 		field, ok := args[1].(string)
 		if !ok || field != "service_name" {
-			return "sai_service error: second argument must be 'service_name' field"
+			return "", errors.New("second argument must be 'service_name' field")
 		}
 		
 		idx, ok := args[0].(int)
 		if !ok {
-			return "sai_service error: first argument must be index (int)"
+			return "", errors.New("first argument must be index (int)")
 		}
 		
-		result, err := e.getServiceByIndex(provider, idx)
+		result, err := r.getServiceByIndex(provider, idx)
 		if err != nil {
-			return fmt.Sprintf("sai_service error: %v", err)
+			return "", err
 		}
-		return result
+		return result, nil
 		
 	default:
-		return fmt.Sprintf("sai_service error: accepts 1 or 3 arguments, got %d", len(args))
+		return "", fmt.Errorf("accepts 1-3 arguments, got %d", len(args))
 	}
 }
 
+// saiServiceCommand returns the command running a service action with the service
+// manager of the system. The arguments after the action select the service like
+// those of sai_service:
+// - sai_service_command("start", index, "service_name", "provider")
+// - sai_service_command("start", "name")
+func (r *renderer) saiServiceCommand(action string, args ...interface{}) (string, error) {
+	name, err := r.serviceName(args...)
+	if err != nil {
+		return "", err
+	}
+
+	return servicemgr.ForService(name).Command(action, shellQuote(name))
+}
+
 // getServiceByIndex returns service_name at specific index for provider
-func (e *TemplateEngine) getServiceByIndex(provider string, idx int) (string, error) {
+func (r *renderer) getServiceByIndex(provider string, idx int) (string, error) {
 	// Check provider-specific services first
-	if providerConfig := e.saidata.GetProviderConfig(provider); providerConfig != nil {
+	if providerConfig := r.saidata.GetProviderConfig(provider); providerConfig != nil {
 		if len(providerConfig.Services) > idx {
 			return providerConfig.Services[idx].GetServiceNameOrDefault(), nil
 		}
 	}
 	
 	// Fall back to default services
-	if len(e.saidata.Services) > idx {
-		return e.saidata.Services[idx].GetServiceNameOrDefault(), nil
+	if len(r.saidata.Services) > idx {
+		return r.saidata.Services[idx].GetServiceNameOrDefault(), nil
 	}
 	
 	return "", fmt.Errorf("no service found at index %d for provider %s", idx, provider)
@@ -414,65 +613,58 @@ func (e *TemplateEngine) getServiceByIndex(provider string, idx int) (string, er
 // - sai_port() - returns first port
 // - sai_port(index) - returns port at index
 // - sai_port(index, "port", "provider") - returns port at index for provider
-func (e *TemplateEngine) saiPort(args ...interface{}) int {
-	if e.saidata == nil {
-		return -1 // Return error indicator
+// - sai_port(index, "port") - same as above using the provider from the template context
+func (r *renderer) saiPort(args ...interface{}) (int, error) {
+	if r.saidata == nil {
+		return 0, errors.New("no saidata context available")
 	}
 	
 	switch len(args) {
 	case 0:
 		// sai_port() - return first port
-		result, err := e.getPortByIndex("", 0)
-		if err != nil {
-			return -1 // Return error indicator
-		}
-		return result
+		return r.getPortByIndex("", 0)
 		
 	case 1:
 		// sai_port(index) - return port at index
 		idx, ok := args[0].(int)
 		if !ok {
-			return -1 // Return error indicator
-		}
-		result, err := e.getPortByIndex("", idx)
-		if err != nil {
-			return -1 // Return error indicator
+			return 0, errors.New("argument must be index (int)")
 		}
-		return result
+		return r.getPortByIndex("", idx)
+		
+	case 2:
+		// Legacy format without provider: sai_port(index, "port")
+		return r.saiPort(args[0], args[1], r.provider)
 		
 	case 3:
 		// Handle legacy provider template format: sai_port(index, "port", "provider")
 		provider, ok := args[2].(string)
 		if !ok {
-			return -1 // Return error indicator
+			return 0, errors.New("third argument must be provider name (string)")
 		}
 		
 		field, ok := args[1].(string)
 		if !ok || field != "port" {
-			return -1 // Return error indicator
+			return 0, errors.New("second argument must be 'port' field")
 		}
 		
 		idx, ok := args[0].(int)
 		if !ok {
-			return -1 // Return error indicator
+			return 0, errors.New("first argument must be index (int)")
 		}
 		
-		result, err := e.getPortByIndex(provider, idx)
-		if err != nil {
-			return -1 // Return error indicator
-		}
-		return result
+		return r.getPortByIndex(provider, idx)
 		
 	default:
-		return -1 // Return error indicator
+		return 0, fmt.Errorf("accepts 0-3 arguments, got %d", len(args))
 	}
 }
 
 // getPortByIndex returns port number at specific index for provider
-func (e *TemplateEngine) getPortByIndex(provider string, idx int) (int, error) {
+func (r *renderer) getPortByIndex(provider string, idx int) (int, error) {
 	// If provider specified, check provider-specific ports first
 	if provider != "" {
-		if providerConfig := e.saidata.GetProviderConfig(provider); providerConfig != nil {
+		if providerConfig := r.saidata.GetProviderConfig(provider); providerConfig != nil {
 			if len(providerConfig.Ports) > idx {
 				return providerConfig.Ports[idx].Port, nil
 			}
@@ -480,24 +672,26 @@ func (e *TemplateEngine) getPortByIndex(provider string, idx int) (int, error) {
 	}
 	
 	// Fall back to default ports
-	if len(e.saidata.Ports) <= idx {
-		return -1, fmt.Errorf("no port found at index %d", idx)
+	if len(r.saidata.Ports) <= idx {
+		return 0, fmt.Errorf("no port found at index %d", idx)
 	}
 	
-	return e.saidata.Ports[idx].Port, nil
+	return r.saidata.Ports[idx].Port, nil
 }
 
 // saiFile returns the file path
 // Supports multiple calling patterns:
 // - sai_file("name") - returns path for file with logical name
 // - sai_file("name", "path", "provider") - returns path for file with logical name for provider
-func (e *TemplateEngine) saiFile(args ...interface{}) string {
-	if e.saidata == nil {
-		return "sai_file error: no saidata context available"
+// - sai_file("name", "path") - same as above using the provider from the template context
+// The path is shell-quoted.
+func (r *renderer) saiFile(args ...interface{}) (string, error) {
+	if r.saidata == nil {
+		return "", errors.New("no saidata context available")
 	}
 	
 	if len(args) == 0 {
-		return "sai_file error: requires at least one argument"
+		return "", errors.New("requires at least one argument")
 	}
 	
 	switch len(args) {
@@ -505,49 +699,53 @@ func (e *TemplateEngine) saiFile(args ...interface{}) string {
 		// sai_file("name") - return path for logical name
 		name, ok := args[0].(string)
 		if !ok {
-			return "sai_file error: argument must be file name (string)"
+			return "", errors.New("argument must be file name (string)")
 		}
 		
-		file := e.saidata.GetFileByName(name)
+		file := r.saidata.GetFileByName(name)
 		if file == nil {
-			return fmt.Sprintf("sai_file error: file %s not found", name)
+			return "", fmt.Errorf("file %s not found", name)
 		}
 		
-		return file.Path
+		return shellQuote(r.rootPath(file.Path)), nil
+		
+	case 2:
+		// Legacy format without provider: sai_file("name", "path")
+		return r.saiFile(args[0], args[1], r.provider)
 		
 	case 3:
 		// Handle legacy provider template format: sai_file("name", "path", "provider")
 		provider, ok := args[2].(string)
 		if !ok {
-			return "sai_file error: third argument must be provider name (string)"
+			return "", errors.New("third argument must be provider name (string)")
 		}
 		
 		field, ok := args[1].(string)
 		if !ok || field != "path" {
-			return "sai_file error: second argument must be 'path' field"
+			return "", errors.New("second argument must be 'path' field")
 		}
 		
 		name, ok := args[0].(string)
 		if !ok {
-			return "sai_file error: first argument must be file name (string)"
+			return "", errors.New("first argument must be file name (string)")
 		}
 		
-		result, err := e.getFileByName(provider, name)
+		result, err := r.getFileByName(provider, name)
 		if err != nil {
-			return fmt.Sprintf("sai_file error: %v", err)
+			return "", err
 		}
-		return result
+		return shellQuote(r.rootPath(result)), nil
 		
 	default:
-		return fmt.Sprintf("sai_file error: accepts 1 or 3 arguments, got %d", len(args))
+		return "", fmt.Errorf("accepts 1-3 arguments, got %d", len(args))
 	}
 }
 
 // getFileByName returns file path for logical name, checking provider-specific files first
-func (e *TemplateEngine) getFileByName(provider, name string) (string, error) {
+func (r *renderer) getFileByName(provider, name string) (string, error) {
 	// Check provider-specific files first
 	if provider != "" {
-		if providerConfig := e.saidata.GetProviderConfig(provider); providerConfig != nil {
+		if providerConfig := r.saidata.GetProviderConfig(provider); providerConfig != nil {
 			for _, file := range providerConfig.Files {
 				if file.Name == name {
 					return file.Path, nil
@@ -557,7 +755,7 @@ func (e *TemplateEngine) getFileByName(provider, name string) (string, error) {
 	}
 	
 	// Fall back to default files
-	file := e.saidata.GetFileByName(name)
+	file := r.saidata.GetFileByName(name)
 	if file == nil {
 		return "", fmt.Errorf("file %s not found", name)
 	}
@@ -565,45 +763,55 @@ func (e *TemplateEngine) getFileByName(provider, name string) (string, error) {
 	return file.Path, nil
 }
 
-// saiDirectory returns the directory path
-func (e *TemplateEngine) saiDirectory(name string) string {
-	if e.saidata == nil {
-		return "sai_directory error: no saidata context available"
+// saiDirectory returns the shell-quoted directory path
+func (r *renderer) saiDirectory(name string) (string, error) {
+	if r.saidata == nil {
+		return "", errors.New("no saidata context available")
 	}
 	
-	directory := e.saidata.GetDirectoryByName(name)
+	directory := r.saidata.GetDirectoryByName(name)
 	if directory == nil {
-		return fmt.Sprintf("sai_directory error: directory %s not found", name)
+		return "", fmt.Errorf("directory %s not found", name)
 	}
 	
-	return directory.Path
+	return shellQuote(r.rootPath(directory.Path)), nil
 }
 
-// saiCommand returns the command path
-func (e *TemplateEngine) saiCommand(name string) string {
-	if e.saidata == nil {
-		return "sai_command error: no saidata context available"
+// saiCommand returns the shell-quoted command path
+func (r *renderer) saiCommand(name string) (string, error) {
+	if r.saidata == nil {
+		return "", errors.New("no saidata context available")
 	}
 	
-	command := e.saidata.GetCommandByName(name)
+	command := r.saidata.GetCommandByName(name)
 	if command == nil {
-		return fmt.Sprintf("sai_command error: command %s not found", name)
+		return "", fmt.Errorf("command %s not found", name)
 	}
 	
-	return command.GetPathOrDefault()
+	return shellQuote(r.rootPath(command.GetPathOrDefault())), nil
+}
+
+// rootPath prefixes an absolute saidata path with the alternate root actions act on
+func (r *renderer) rootPath(path string) string {
+	if r.root == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(r.root, path)
 }
 
 // saiContainer returns container information
 // Supports multiple calling patterns:
 // - sai_container("name") - returns full image name for container with logical name
 // - sai_container(index, "field", "provider") - returns field value at index for provider
-func (e *TemplateEngine) saiContainer(args ...interface{}) string {
-	if e.saidata == nil {
-		return "sai_container error: no saidata context available"
+// - sai_container(index, "field") - same as above using the provider from the template context
+// The value is shell-quoted.
+func (r *renderer) saiContainer(args ...interface{}) (string, error) {
+	if r.saidata == nil {
+		return "", errors.New("no saidata context available")
 	}
 	
 	if len(args) == 0 {
-		return "sai_container error: requires at least one argument"
+		return "", errors.New("requires at least one argument")
 	}
 	
 	switch len(args) {
@@ -611,50 +819,58 @@ func (e *TemplateEngine) saiContainer(args ...interface{}) string {
 		// sai_container("name") - return full image name for logical name
 		name, ok := args[0].(string)
 		if !ok {
-			return "sai_container error: argument must be container name (string)"
+			return "", errors.New("argument must be container name (string)")
 		}
 		
-		container := e.saidata.GetContainerByName(name)
+		container := r.saidata.GetContainerByName(name)
 		if container == nil {
-			return fmt.Sprintf("sai_container error: container %s not found", name)
+			return "", fmt.Errorf("container %s not found", name)
 		}
 		
-		return container.GetFullImageName()
+		return shellQuote(container.GetFullImageName()), nil
+		
+	case 2:
+		// Legacy format without provider: sai_container(index, "field")
+		return r.saiContainer(args[0], args[1], r.provider)
 		
 	case 3:
 		// Handle legacy provider template format: sai_container(index, "field", "provider")
 		provider, ok := args[2].(string)
 		if !ok {
-			return "sai_container error: third argument must be provider name (string)"
+			return "", errors.New("third argument must be provider name (string)")
 		}
 		
 		field, ok := args[1].(string)
 		if !ok {
-			return "sai_container error: second argument must be field name (string)"
+			return "", errors.New("second argument must be field name (string)")
 		}
 		
 		idx, ok := args[0].(int)
 		if !ok {
-			return "sai_container error: first argument must be index (int)"
+			return "", errors.New("first argument must be index (int)")
 		}
 		
-		result, err := e.getContainerField(provider, idx, field)
+		result, err := r.getContainerField(provider, idx, field)
 		if err != nil {
-			return fmt.Sprintf("sai_container error: %v", err)
+			return "", err
 		}
-		return result
+		// Unset fields such as the registry render as nothing
+		if result == "" {
+			return "", nil
+		}
+		return shellQuote(result), nil
 		
 	default:
-		return fmt.Sprintf("sai_container error: accepts 1 or 3 arguments, got %d", len(args))
+		return "", fmt.Errorf("accepts 1-3 arguments, got %d", len(args))
 	}
 }
 
 // getContainerField returns specific field value for container at index for provider
-func (e *TemplateEngine) getContainerField(provider string, idx int, field string) (string, error) {
+func (r *renderer) getContainerField(provider string, idx int, field string) (string, error) {
 	var container *types.Container
 	
 	// Check provider-specific containers first
-	if providerConfig := e.saidata.GetProviderConfig(provider); providerConfig != nil {
+	if providerConfig := r.saidata.GetProviderConfig(provider); providerConfig != nil {
 		if len(providerConfig.Containers) > idx {
 			container = &providerConfig.Containers[idx]
 		}
@@ -662,10 +878,10 @@ func (e *TemplateEngine) getContainerField(provider string, idx int, field strin
 	
 	// Fall back to default containers
 	if container == nil {
-		if len(e.saidata.Containers) <= idx {
+		if len(r.saidata.Containers) <= idx {
 			return "", fmt.Errorf("no container found at index %d", idx)
 		}
-		container = &e.saidata.Containers[idx]
+		container = &r.saidata.Containers[idx]
 	}
 	
 	// Return requested field
@@ -680,6 +896,8 @@ func (e *TemplateEngine) getContainerField(provider string, idx int, field strin
 		return container.Registry, nil
 	case "full_image":
 		return container.GetFullImageName(), nil
+	case "reference":
+		return container.GetImageReference(), nil
 	default:
 		return "", fmt.Errorf("unsupported container field: %s", field)
 	}
@@ -722,48 +940,48 @@ func (e *TemplateEngine) directoryExists(path string) bool {
 }
 
 // Default generation functions
-func (e *TemplateEngine) defaultConfigPath(software string) string {
-	if e.defaultsGen != nil {
-		return e.defaultsGen.DefaultConfigPath(software)
+func (r *renderer) defaultConfigPath(software string) string {
+	if r.defaults != nil {
+		return r.defaults.DefaultConfigPath(software)
 	}
 	// Fallback default
 	return filepath.Join("/etc", software, software+".conf")
 }
 
-func (e *TemplateEngine) defaultLogPath(software string) string {
-	if e.defaultsGen != nil {
-		return e.defaultsGen.DefaultLogPath(software)
+func (r *renderer) defaultLogPath(software string) string {
+	if r.defaults != nil {
+		return r.defaults.DefaultLogPath(software)
 	}
 	// Fallback default
 	return filepath.Join("/var/log", software+".log")
 }
 
-func (e *TemplateEngine) defaultDataDir(software string) string {
-	if e.defaultsGen != nil {
-		return e.defaultsGen.DefaultDataDir(software)
+func (r *renderer) defaultDataDir(software string) string {
+	if r.defaults != nil {
+		return r.defaults.DefaultDataDir(software)
 	}
 	// Fallback default
 	return filepath.Join("/var/lib", software)
 }
 
-func (e *TemplateEngine) defaultServiceName(software string) string {
-	if e.defaultsGen != nil {
-		return e.defaultsGen.DefaultServiceName(software)
+func (r *renderer) defaultServiceName(software string) string {
+	if r.defaults != nil {
+		return r.defaults.DefaultServiceName(software)
 	}
 	// Fallback default
 	return software
 }
 
-func (e *TemplateEngine) defaultCommandPath(software string) string {
-	if e.defaultsGen != nil {
-		return e.defaultsGen.DefaultCommandPath(software)
+func (r *renderer) defaultCommandPath(software string) string {
+	if r.defaults != nil {
+		return r.defaults.DefaultCommandPath(software)
 	}
 	// Fallback default
 	return filepath.Join("/usr/bin", software)
 }
 
 // validateTemplateResolution validates that the rendered template doesn't contain unresolved variables
-func (e *TemplateEngine) validateTemplateResolution(rendered string, originalTemplate string, context *TemplateContext) error {
+func (e *TemplateEngine) validateTemplateResolution(rendered string, context *TemplateContext) error {
 	// Check for unresolved template variables ({{...}})
 	if strings.Contains(rendered, "{{") || strings.Contains(rendered, "}}") {
 		return &TemplateResolutionError{
@@ -784,47 +1002,6 @@ func (e *TemplateEngine) validateTemplateResolution(rendered string, originalTem
 		}
 	}
 	
-	// Check for function error indicators - these indicate template functions failed
-	errorIndicators := []string{
-		"sai_package error:",
-		"sai_packages error:",
-		"sai_service error:",
-		"sai_port error:",
-		"sai_file error:",
-		"sai_directory error:",
-		"sai_command error:",
-		"sai_container error:",
-		"no saidata context available",
-		"no package found",
-		"no service found",
-		"no file found",
-		"no directory found",
-		"no command found",
-		"no container found",
-		"no port found",
-	}
-	
-	// Check for port error indicators (port functions return -1 on error)
-	if strings.Contains(rendered, "-1") && strings.Contains(originalTemplate, "sai_port") {
-		return &TemplateResolutionError{
-			Type:     "function_error",
-			Message:  "Port template function failed: no port found",
-			Template: rendered,
-			Context:  context,
-		}
-	}
-	
-	for _, indicator := range errorIndicators {
-		if strings.Contains(rendered, indicator) {
-			return &TemplateResolutionError{
-				Type:     "function_error",
-				Message:  fmt.Sprintf("Template function failed: %s", indicator),
-				Template: rendered,
-				Context:  context,
-			}
-		}
-	}
-	
 	// In safety mode, validate that referenced resources exist
 	if e.safetyMode && e.validator != nil {
 		if err := e.validateResourceExistence(rendered, context); err != nil {
@@ -910,39 +1087,116 @@ func (e *TemplateResolutionError) Error() string {
 	return details.String()
 }
 
-// preprocessTemplate converts legacy template syntax to Go template syntax
+// preprocessTemplate converts legacy template syntax to Go template syntax.
+// Legacy calls become parenthesized pipelines and single-quoted strings become
+// double-quoted ones:
+//
+//	Legacy:      {{sai_package(0, 'name', 'apt')}}
+//	Go template: {{(sai_package 0 "name" "apt")}}
+//
+// Only template actions are rewritten; text outside {{ }}, string literals, comments
+// and Go template grouping parentheses such as (gt (len .X) 0) are left untouched.
 func (e *TemplateEngine) preprocessTemplate(templateStr string) string {
-	// Convert function calls from legacy format to Go template format
-	// Legacy: {{sai_package(0, 'name', 'apt')}}
-	// Go template: {{sai_package 0 "name" "apt"}}
-	
-	result := templateStr
-	
-	// Use regex to find and replace function calls with parentheses
-	// This handles patterns like: {{sai_package(0, 'name', 'apt')}}
-	
-	// Step 1: Replace parentheses with spaces
-	result = strings.ReplaceAll(result, "(", " ")
-	result = strings.ReplaceAll(result, ")", " ")
-	
-	// Step 2: Replace commas with spaces
-	result = strings.ReplaceAll(result, ",", " ")
-	
-	// Step 3: Replace single quotes with double quotes for string literals
-	result = strings.ReplaceAll(result, "'", "\"")
-	
-	// Step 4: Clean up multiple spaces
-	for strings.Contains(result, "  ") {
-		result = strings.ReplaceAll(result, "  ", " ")
+	var result strings.Builder
+	rest := templateStr
+
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			result.WriteString(rest)
+			return result.String()
+		}
+		result.WriteString(rest[:start])
+
+		action, remaining := convertLegacyAction(rest[start+2:])
+		result.WriteString("{{")
+		result.WriteString(action)
+		rest = remaining
 	}
-	
-	// Step 5: Clean up spaces around template delimiters
-	result = strings.ReplaceAll(result, "{{ ", "{{")
-	result = strings.ReplaceAll(result, " }}", "}}")
-	result = strings.ReplaceAll(result, "{{  ", "{{")
-	result = strings.ReplaceAll(result, "  }}", "}}")
-	
-	return result
+}
+
+// convertLegacyAction rewrites legacy call syntax in a template action. It returns
+// the converted action including the closing delimiter and the text that follows it.
+func convertLegacyAction(input string) (string, string) {
+	var out []byte
+	var parens []bool // true for legacy call parentheses
+
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == '}' && i+1 < len(input) && input[i+1] == '}':
+			return string(out) + "}}", input[i+2:]
+		case c == '/' && i+1 < len(input) && input[i+1] == '*':
+			// Comments are copied as they are, quotes and parentheses included
+			end := strings.Index(input[i+2:], "*/")
+			if end < 0 {
+				return string(out) + input[i:], ""
+			}
+			out = append(out, input[i:i+2+end+2]...)
+			i += 2 + end + 1
+		case c == '"' || c == '`':
+			end := literalEnd(input, i)
+			out = append(out, input[i:end]...)
+			i = end - 1
+		case c == '\'':
+			end := literalEnd(input, i)
+			content := strings.TrimSuffix(input[i+1:end], "'")
+			out = append(out, fmt.Sprintf("%q", strings.ReplaceAll(content, "\\'", "'"))...)
+			i = end - 1
+		case c == '(':
+			identStart := len(out)
+			for identStart > 0 && isIdentByte(out[identStart-1]) {
+				identStart--
+			}
+			// Variables and fields such as $x( or .Name( are not legacy calls
+			legacy := identStart < len(out) && (identStart == 0 || (out[identStart-1] != '$' && out[identStart-1] != '.'))
+			if legacy {
+				// name(args) becomes (name args)
+				name := string(out[identStart:])
+				out = append(out[:identStart], '(')
+				out = append(out, name...)
+				out = append(out, ' ')
+			} else {
+				out = append(out, c)
+			}
+			parens = append(parens, legacy)
+		case c == ')':
+			if len(parens) > 0 {
+				parens = parens[:len(parens)-1]
+			}
+			out = append(out, c)
+		case c == ',' && len(parens) > 0 && parens[len(parens)-1]:
+			// Arguments are separated by the whitespace following the comma or a space
+			if i+1 >= len(input) || (input[i+1] != ' ' && input[i+1] != '\t') {
+				out = append(out, ' ')
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+
+	// Unterminated action, leave it to the parser to report
+	return string(out), ""
+}
+
+// literalEnd returns the index just past the string literal starting at start
+func literalEnd(input string, start int) int {
+	quote := input[start]
+	for i := start + 1; i < len(input); i++ {
+		if input[i] == '\\' && quote != '`' {
+			i++
+			continue
+		}
+		if input[i] == quote {
+			return i + 1
+		}
+	}
+	return len(input)
+}
+
+// isIdentByte reports whether c can be part of a template function name
+func isIdentByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
 }
 
 // createVariableMap creates a map of variables for debug logging
@@ -952,6 +1206,9 @@ func (e *TemplateEngine) createVariableMap(context *TemplateContext) map[string]
 	if context != nil {
 		variables["software"] = context.Software
 		variables["provider"] = context.Provider
+		if context.Executable != "" {
+			variables["executable"] = context.Executable
+		}
 		
 		// Add context variables
 		if context.Variables != nil {
@@ -1,10 +1,16 @@
 package template
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"sai/internal/interfaces"
 	"sai/internal/types"
 )
 
@@ -171,6 +177,92 @@ func TestTemplateEngine_SaiPackageFunction(t *testing.T) {
 	}
 }
 
+func TestTemplateEngine_ContextProviderDefault(t *testing.T) {
+	validator := NewMockResourceValidator()
+	defaultsGen := NewMockDefaultsGenerator()
+	engine := NewTemplateEngine(validator, defaultsGen)
+
+	saidata := &types.SoftwareData{
+		Version: "0.2",
+		Metadata: types.Metadata{
+			Name: "nginx",
+		},
+		Packages: []types.Package{
+			{Name: "nginx", PackageName: "nginx"},
+		},
+		Services: []types.Service{
+			{Name: "nginx", ServiceName: "nginx"},
+		},
+		Ports: []types.Port{
+			{Port: 80},
+		},
+		Providers: map[string]types.ProviderConfig{
+			"apt": {
+				Packages: []types.Package{
+					{Name: "nginx", PackageName: "nginx-full"},
+				},
+				Services: []types.Service{
+					{Name: "nginx", ServiceName: "nginx-apt"},
+				},
+				Ports: []types.Port{
+					{Port: 8080},
+				},
+			},
+		},
+	}
+
+	context := &TemplateContext{
+		Software: "nginx",
+		Provider: "apt",
+		Saidata:  saidata,
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "sai_package without provider uses context provider",
+			template: "{{sai_package(0, 'name')}}",
+			expected: "nginx-full",
+		},
+		{
+			name:     "sai_package all packages without provider",
+			template: "{{sai_package('*', 'name')}}",
+			expected: "nginx-full",
+		},
+		{
+			name:     "explicit provider still takes precedence",
+			template: "{{sai_package(0, 'name', 'brew')}}",
+			expected: "nginx",
+		},
+		{
+			name:     "sai_service without provider uses context provider",
+			template: "{{sai_service(0, 'service_name')}}",
+			expected: "nginx-apt",
+		},
+		{
+			name:     "sai_port without provider uses context provider",
+			template: "{{sai_port(0, 'port')}}",
+			expected: "8080",
+		},
+		{
+			name:     "sai_packages without provider uses context provider",
+			template: "{{sai_packages}}",
+			expected: "nginx-full",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := engine.Render(tt.template, context)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestTemplateEngine_SaiServiceFunction(t *testing.T) {
 	validator := NewMockResourceValidator()
 	defaultsGen := NewMockDefaultsGenerator()
@@ -299,6 +391,47 @@ func TestTemplateEngine_SaiFileFunction(t *testing.T) {
 	}
 }
 
+func TestTemplateEngine_Root(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+	saidata := &types.SoftwareData{
+		Version: "0.2",
+		Files:   []types.File{{Name: "config", Path: "/etc/nginx/nginx.conf", Type: "config"}},
+	}
+	engine.SetSaidata(saidata)
+	context := &TemplateContext{Software: "nginx", Provider: "apt", Saidata: saidata, Root: "/mnt/image"}
+
+	// Saidata paths resolve inside the alternate root
+	result, err := engine.Render("cat {{sai_file \"config\"}}", context)
+	require.NoError(t, err)
+	assert.Equal(t, "cat /mnt/image/etc/nginx/nginx.conf", result)
+
+	result, err = engine.Render("chroot {{.Root}} nginx -t", context)
+	require.NoError(t, err)
+	assert.Equal(t, "chroot /mnt/image nginx -t", result)
+}
+
+func TestTemplateEngine_Host(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+	context := &TemplateContext{
+		Software:       "kubectl",
+		Provider:       "binary",
+		Saidata:        &types.SoftwareData{Version: "0.2"},
+		Platform:       "linux",
+		OS:             "ubuntu",
+		OSVersion:      "22.04",
+		Arch:           "arm64",
+		PackageManager: "apt",
+	}
+
+	result, err := engine.Render("https://dl.k8s.io/v1.30.0/bin/{{.Platform}}/{{.Arch}}/kubectl", context)
+	require.NoError(t, err)
+	assert.Equal(t, "https://dl.k8s.io/v1.30.0/bin/linux/arm64/kubectl", result)
+
+	result, err = engine.Render(`{{if eq .PackageManager "apt"}}apt-get install -y {{.Software}}{{else}}{{.OS}} {{.OSVersion}}{{end}}`, context)
+	require.NoError(t, err)
+	assert.Equal(t, "apt-get install -y kubectl", result)
+}
+
 func TestTemplateEngine_SaiPortFunction(t *testing.T) {
 	validator := NewMockResourceValidator()
 	defaultsGen := NewMockDefaultsGenerator()
@@ -421,6 +554,79 @@ func TestTemplateEngine_ValidationFunctions(t *testing.T) {
 	}
 }
 
+func TestTemplateEngine_ChecksumFunctions(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+
+	path := filepath.Join(t.TempDir(), "app.tar.gz")
+	require.NoError(t, os.WriteFile(path, []byte("hello\n"), 0644))
+	sha256sum := "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+
+	context := &TemplateContext{
+		Software: "app",
+		Provider: "binary",
+		Saidata: &types.SoftwareData{
+			Packages: []types.Package{{Name: "app", Checksum: "sha256:" + sha256sum}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "checksum_file sha256",
+			template: `{{checksum_file "` + path + `" "sha256"}}`,
+			expected: sha256sum,
+		},
+		{
+			name:     "checksum_file md5",
+			template: `{{checksum_file "` + path + `" "MD5"}}`,
+			expected: "b1946ac92492d2347c6235b4d2611184",
+		},
+		{
+			name:     "checksum_matches saidata checksum",
+			template: `{{checksum_matches "` + path + `" (sai_package 0 "checksum")}}`,
+			expected: "true",
+		},
+		{
+			name:     "sai_package checksum",
+			template: `{{sai_package(0, 'checksum', 'binary')}}`,
+			expected: "sha256:" + sha256sum,
+		},
+		{
+			name:     "checksum_matches bare hex",
+			template: `{{checksum_matches "` + path + `" "` + strings.ToUpper(sha256sum) + `"}}`,
+			expected: "true",
+		},
+		{
+			name:     "checksum_matches mismatch",
+			template: `{{checksum_matches "` + path + `" "sha1:0000000000000000000000000000000000000000"}}`,
+			expected: "false",
+		},
+		{
+			name:     "checksum_matches missing file",
+			template: `{{checksum_matches "/nonexistent/app.tar.gz" "sha256:` + sha256sum + `"}}`,
+			expected: "false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := engine.Render(tt.template, context)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	_, err := engine.Render(`{{checksum_file "`+path+`" "crc32"}}`, context)
+	assert.Error(t, err)
+	_, err = engine.Render(`{{checksum_matches "`+path+`" "abc"}}`, context)
+	assert.Error(t, err)
+	_, err = engine.Render(`{{checksum_file "/nonexistent/app.tar.gz" "sha256"}}`, context)
+	assert.Error(t, err)
+}
+
 func TestTemplateEngine_DefaultGenerationFunctions(t *testing.T) {
 	validator := NewMockResourceValidator()
 	defaultsGen := NewMockDefaultsGenerator()
@@ -738,6 +944,31 @@ func TestTemplateEngine_ErrorHandling(t *testing.T) {
 	}
 }
 
+func TestTemplateEngine_FunctionErrors(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+	engine.SetSafetyMode(false)
+
+	saidata := &types.SoftwareData{
+		Metadata: types.Metadata{Name: "test"},
+		Packages: []types.Package{{Name: "no-service-found"}},
+	}
+	context := &TemplateContext{Software: "test", Provider: "apt", Saidata: saidata}
+
+	// Output that reads like an error message is not mistaken for a failed function
+	result, err := engine.Render("echo 'no service found' && apt install {{sai_package(0, 'name', 'apt')}}", context)
+	require.NoError(t, err)
+	assert.Equal(t, "echo 'no service found' && apt install no-service-found", result)
+
+	// Failing functions stop the rendering even without safety mode
+	for _, tmpl := range []string{"{{sai_service \"web\"}}", "{{sai_port}}", "{{sai_file \"config\"}}", "{{sai_container \"app\"}}"} {
+		result, err := engine.Render(tmpl, context)
+		var resolutionErr *TemplateResolutionError
+		require.ErrorAs(t, err, &resolutionErr, tmpl)
+		assert.Equal(t, "function_error", resolutionErr.Type)
+		assert.Empty(t, result)
+	}
+}
+
 func TestTemplateEngine_WithExistingSaidataFiles(t *testing.T) {
 	validator := NewMockResourceValidator()
 	defaultsGen := NewMockDefaultsGenerator()
@@ -825,4 +1056,258 @@ func TestTemplateEngine_WithExistingSaidataFiles(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestTemplateEngine_RuntimeContext(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+
+	context := &TemplateContext{
+		Software: "nginx",
+		Provider: "apt",
+		Runtime: &interfaces.RuntimeContext{
+			SelectedPackage: "nginx-full",
+			ExtractDir:      "/tmp/sai-nginx",
+			Values:          map[string]string{"version": "1.25"},
+		},
+	}
+
+	result, err := engine.Render("install {{.Runtime.SelectedPackage}} {{.Runtime.Values.version}} into {{.Runtime.ExtractDir}}", context)
+	require.NoError(t, err)
+	assert.Equal(t, "install nginx-full 1.25 into /tmp/sai-nginx", result)
+
+	result, err = engine.Render("download nginx-{{.Registered.version}}.tar.gz", context)
+	require.NoError(t, err)
+	assert.Equal(t, "download nginx-1.25.tar.gz", result)
+
+	// Templates referencing the runtime context render without one
+	context.Runtime = nil
+	engine.SetSafetyMode(false)
+	result, err = engine.Render("install {{.Runtime.SelectedPackage}}", context)
+	require.NoError(t, err)
+	assert.Equal(t, "install ", result)
+}
+
+func TestTemplateEngine_PreprocessTemplate(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "legacy call",
+			template: "apt install {{sai_package(0, 'name', 'apt')}}",
+			expected: `apt install {{(sai_package 0 "name" "apt")}}`,
+		},
+		{
+			name:     "nested legacy calls",
+			template: "{{sai_file(sai_package(0), 'path')}}",
+			expected: `{{(sai_file (sai_package 0) "path")}}`,
+		},
+		{
+			name:     "shell outside actions untouched",
+			template: "if [ -f 'a,b' ]; then (cd /tmp && echo \"x, y\"); fi {{sai_package(0)}}",
+			expected: "if [ -f 'a,b' ]; then (cd /tmp && echo \"x, y\"); fi {{(sai_package 0)}}",
+		},
+		{
+			name:     "commas and parentheses inside string literals",
+			template: `{{sai_package(0, 'a, (b)', "c, d")}}`,
+			expected: `{{(sai_package 0 "a, (b)" "c, d")}}`,
+		},
+		{
+			name:     "escaped single quote",
+			template: `{{sai_package(0, 'it\'s')}}`,
+			expected: `{{(sai_package 0 "it's")}}`,
+		},
+		{
+			name:     "go template grouping and range commas untouched",
+			template: "{{if (gt (len .Variables) 0)}}{{range $k, $v := .Variables}}{{$k}}{{end}}{{end}}",
+			expected: "{{if (gt (len .Variables) 0)}}{{range $k, $v := .Variables}}{{$k}}{{end}}{{end}}",
+		},
+		{
+			name:     "comments untouched",
+			template: "{{/* don't rewrite f(a, b) */}}{{- sai_package(0) -}}",
+			expected: `{{/* don't rewrite f(a, b) */}}{{- (sai_package 0) -}}`,
+		},
+		{
+			name:     "field followed by parenthesis not a call",
+			template: "{{.Software(x)}}",
+			expected: "{{.Software(x)}}",
+		},
+		{
+			name:     "closing delimiter inside literal",
+			template: `{{sai_package(0, '}}')}} done`,
+			expected: `{{(sai_package 0 "}}")}} done`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, engine.preprocessTemplate(tt.template))
+		})
+	}
+}
+
+func TestTemplateEngine_SaiServiceCommand(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+	saidata := &types.SoftwareData{
+		Version:  "0.2",
+		Services: []types.Service{{Name: "nginx", ServiceName: "nginx", Type: "systemd"}},
+	}
+	engine.SetSaidata(saidata)
+	context := &TemplateContext{Software: "nginx", Provider: "nix", Saidata: saidata}
+
+	tests := []struct {
+		name        string
+		os          string
+		executables string
+		template    string
+		expected    string
+	}{
+		{"systemd", "ubuntu", "systemctl", "{{sai_service_command('start', 0, 'service_name', 'nix')}}", "systemctl start nginx"},
+		{"openrc", "alpine", "rc-service", "{{sai_service_command('enable', 'nginx')}}", "rc-update add nginx default"},
+		{"launchd", "macos", "", "{{sai_service_command('restart', 0, 'service_name')}}", "launchctl kickstart -k system/nginx"},
+		{"windows", "windows", "", "{{sai_service_command('stop', 0, 'service_name', 'nix')}}", "sc.exe stop nginx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SAI_TEST_OS", tt.os)
+			t.Setenv("SAI_TEST_EXECUTABLES", tt.executables)
+
+			result, err := engine.Render(tt.template, context)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	// Homebrew formulae are controlled with brew services on macOS
+	prefix := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(prefix, "opt", "nginx"), 0755))
+	t.Setenv("HOMEBREW_PREFIX", prefix)
+	t.Setenv("SAI_TEST_OS", "macos")
+	t.Setenv("SAI_TEST_EXECUTABLES", "brew")
+	result, err := engine.Render("{{sai_service_command('start', 0, 'service_name')}}", context)
+	require.NoError(t, err)
+	assert.Equal(t, "brew services start nginx", result)
+
+	t.Setenv("SAI_TEST_OS", "ubuntu")
+	_, err = engine.Render("{{sai_service_command('reload', 0, 'service_name', 'nix')}}", context)
+	assert.Error(t, err)
+}
+
+func TestTemplateEngine_ConcurrentRender(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+
+	// Renderings sharing the engine each resolve against the saidata of their context
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			software := fmt.Sprintf("software%02d", i)
+			context := &TemplateContext{
+				Software: software,
+				Provider: "apt",
+				Saidata: &types.SoftwareData{
+					Metadata: types.Metadata{Name: software},
+					Packages: []types.Package{{Name: software, PackageName: software + "-pkg"}},
+				},
+			}
+			for j := 0; j < 50; j++ {
+				result, err := engine.Render("apt-get install -y {{sai_package(0, 'name', 'apt')}}", context)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if expected := "apt-get install -y " + software + "-pkg"; result != expected {
+					errs <- fmt.Errorf("rendered %q, expected %q", result, expected)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// benchmarkContext is the context of the rendering benchmarks, an install template
+// over several packages
+func benchmarkContext() (string, *TemplateContext) {
+	saidata := &types.SoftwareData{
+		Metadata: types.Metadata{Name: "nginx"},
+		Packages: []types.Package{{Name: "nginx", Version: "1.24.0"}, {Name: "nginx-common"}},
+		Services: []types.Service{{Name: "nginx", ServiceName: "nginx"}},
+	}
+	template := "apt-get install -y {{sai_package_list('apt') | map '{name}' | join ' '}} && systemctl enable {{sai_service('nginx')}}"
+	return template, &TemplateContext{Software: "nginx", Provider: "apt", Saidata: saidata}
+}
+
+// BenchmarkTemplateEngine_Render measures the rendering of a template, parsed with
+// the functions of its rendering each time
+func BenchmarkTemplateEngine_Render(b *testing.B) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+	template, context := benchmarkContext()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.Render(template, context); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkTemplateEngine_RenderParallel measures renderings sharing an engine from
+// several goroutines, as the steps of actions running in parallel do
+func BenchmarkTemplateEngine_RenderParallel(b *testing.B) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+	template, context := benchmarkContext()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := engine.Render(template, context); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestTemplateEngine_ConditionalFunctions(t *testing.T) {
+	validator := NewMockResourceValidator()
+	validator.SetFileExists("/etc/nginx/nginx.conf", false)
+	validator.SetDirectoryExists("/etc/nginx/nginx.conf", false)
+	validator.SetFileExists("/etc/default/nginx", false)
+	validator.SetServiceExists("nginx", false)
+	engine := NewTemplateEngine(validator, NewSystemDefaultsGenerator(validator))
+	context := &TemplateContext{Software: "nginx", Provider: "apt", Saidata: &types.SoftwareData{}}
+	
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{`cat {{first_existing_path "/etc/nginx/nginx.conf" "/opt/nginx conf/nginx.conf" "/usr/local/etc/nginx/nginx.conf"}}`, `cat '/opt/nginx conf/nginx.conf'`},
+		{`cat {{first_existing_path(shellquote("/etc/nginx/nginx.conf"))}}`, `cat /etc/nginx/nginx.conf`},
+		{`nginx {{when_file "/etc/default/nginx" "-c /etc/default/nginx"}}-t`, `nginx -t`},
+		{`{{when_service('nginx', 'systemctl reload nginx', 'nginx -s reload')}}`, `nginx -s reload`},
+		{`{{when_command "nginx" "nginx -t"}}`, `nginx -t`},
+	}
+	
+	for _, tt := range tests {
+		result, err := engine.Render(tt.template, context)
+		if err != nil {
+			t.Errorf("Render(%s) failed: %v", tt.template, err)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("Render(%s) = %q, want %q", tt.template, result, tt.expected)
+		}
+	}
+	
+	if _, err := engine.Render(`{{when_file "/etc/default/nginx" "a" "b" "c"}}`, context); err == nil {
+		t.Error("Expected an error for several fallback values")
+	}
+}
@@ -0,0 +1,70 @@
+package template
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Escaping functions make values safe in the commands templates render: the values
+// of the saidata functions are shell-quoted, and these handle values built in the
+// template itself:
+//
+//	grep -E {{regexquote .Software | shellquote}} /etc/hosts
+//	ls {{pathjoin (sai_directory "data") "backups" .Software}}
+
+// regexQuote escapes the regular expression metacharacters of a value, for patterns
+// of grep -E, sed -E, awk and Go. A shell-quoted value is unquoted first, so the
+// pattern matches the value rather than its quotes.
+func (e *TemplateEngine) regexQuote(value string) string {
+	if unquoted, quoted := shellUnquote(value); quoted {
+		value = unquoted
+	}
+	return regexp.QuoteMeta(value)
+}
+
+// pathJoin joins path elements into a cleaned, shell-quoted path. Shell-quoted
+// elements, such as the paths of sai_file and sai_directory, are unquoted first.
+// Elements after the first cannot climb out of it with "..", so a software name
+// cannot point a command at another directory.
+func (e *TemplateEngine) pathJoin(first string, elements ...string) (string, error) {
+	if unquoted, quoted := shellUnquote(first); quoted {
+		first = unquoted
+	}
+	if first == "" {
+		return "", fmt.Errorf("pathjoin: empty base path")
+	}
+
+	base := filepath.Clean(first)
+	joined := base
+	for _, element := range elements {
+		if unquoted, quoted := shellUnquote(element); quoted {
+			element = unquoted
+		}
+		joined = filepath.Join(joined, element)
+	}
+	if len(elements) > 0 && !withinPath(base, joined) {
+		return "", fmt.Errorf("pathjoin: %s escapes %s", joined, base)
+	}
+	return shellQuote(joined), nil
+}
+
+// withinPath reports whether path is base or below it
+func withinPath(base, path string) bool {
+	relative, err := filepath.Rel(base, path)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// shellUnquote returns the value of a word quoted as shellQuote quotes it: in single
+// quotes, each single quote it contains closing the quotes, escaped and reopening them
+func shellUnquote(word string) (string, bool) {
+	if len(word) < 2 || word[0] != '\'' || word[len(word)-1] != '\'' {
+		return word, false
+	}
+	inner := word[1 : len(word)-1]
+	if strings.Contains(strings.ReplaceAll(inner, `'\''`, ""), "'") {
+		return word, false
+	}
+	return strings.ReplaceAll(inner, `'\''`, "'"), true
+}
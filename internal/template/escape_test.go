@@ -0,0 +1,90 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/types"
+)
+
+func TestTemplateEngine_EscapingFunctions(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+
+	saidata := &types.SoftwareData{
+		Version:  "0.2",
+		Metadata: types.Metadata{Name: "nginx"},
+		Packages: []types.Package{
+			{Name: "nginx"},
+			{Name: "evil", PackageName: "x; rm -rf /"},
+		},
+		Services:    []types.Service{{Name: "web", ServiceName: "my service"}},
+		Files:       []types.File{{Name: "config", Path: "/etc/my app/app.conf"}},
+		Directories: []types.Directory{{Name: "data", Path: "/var/lib/my app"}},
+	}
+	context := &TemplateContext{Software: "c++ (beta)", Provider: "apt", Saidata: saidata}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "saidata values are shell-quoted",
+			template: `apt-get install -y {{sai_package('*', 'name', 'apt')}} && cat {{sai_file("config")}} && systemctl start {{sai_service("web")}}`,
+			expected: `apt-get install -y nginx 'x; rm -rf /' && cat '/etc/my app/app.conf' && systemctl start 'my service'`,
+		},
+		{
+			name:     "safe values are left as they are",
+			template: `{{sai_package(0, 'name', 'apt')}}`,
+			expected: `nginx`,
+		},
+		{
+			name:     "shellquote does not quote twice",
+			template: `{{shellquote .Software}} {{sai_directory "data" | shellquote}} {{quote "it's"}}`,
+			expected: `'c++ (beta)' '/var/lib/my app' 'it'\''s'`,
+		},
+		{
+			name:     "regexquote",
+			template: `grep -E {{regexquote .Software | shellquote}} {{regexquote (sai_package 1 "name" "apt")}}`,
+			expected: `grep -E 'c\+\+ \(beta\)' x; rm -rf /`,
+		},
+		{
+			name:     "pathjoin unquotes and quotes",
+			template: `{{pathjoin (sai_directory "data") "backups" "today/"}} {{pathjoin "/opt" "nginx" "bin"}}`,
+			expected: `'/var/lib/my app/backups/today' /opt/nginx/bin`,
+		},
+		{
+			name:     "pathjoin refuses escaping elements",
+			template: `{{pathjoin "/opt/sai" "../../etc/passwd"}}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := engine.Render(tt.template, context)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestShellUnquote(t *testing.T) {
+	for _, value := range []string{"a b", "it's", "'", "a'\\''b", ""} {
+		unquoted, quoted := shellUnquote(shellQuote(value))
+		assert.True(t, quoted, value)
+		assert.Equal(t, value, unquoted)
+	}
+
+	_, quoted := shellUnquote("nginx")
+	assert.False(t, quoted)
+
+	_, quoted = shellUnquote(`'a' 'b'`)
+	assert.False(t, quoted)
+}
@@ -0,0 +1,225 @@
+package template
+
+import "sort"
+
+// FunctionDoc documents a template function available to provider templates
+type FunctionDoc struct {
+	Name        string   `json:"name"`
+	Category    string   `json:"category"`
+	Usage       []string `json:"usage"`
+	Description string   `json:"description"`
+}
+
+// functionDocs documents every function of createFuncMap
+var functionDocs = []FunctionDoc{
+	{
+		Name: "sai_package", Category: "saidata",
+		Usage: []string{
+			`sai_package("provider")`,
+			`sai_package(index, "name", "provider")`,
+			`sai_package("*", "name", "provider")`,
+			`sai_package(index|"*", "name")`,
+			`sai_package(index, "checksum")`,
+		},
+		Description: "Shell-quoted package name at an index, or all package names separated by spaces, preferring provider specific packages. The checksum field returns the checksum of a package. The provider defaults to the provider of the template context.",
+	},
+	{
+		Name: "sai_packages", Category: "saidata",
+		Usage:       []string{`sai_packages()`, `sai_packages("provider")`},
+		Description: "All shell-quoted package names for a provider separated by spaces.",
+	},
+	{
+		Name: "sai_service", Category: "saidata",
+		Usage:       []string{`sai_service("name")`, `sai_service(index, "service_name", "provider")`, `sai_service(index, "service_name")`},
+		Description: "Shell-quoted system service name of a service by logical name or index.",
+	},
+	{
+		Name: "sai_service_command", Category: "saidata",
+		Usage:       []string{`sai_service_command("start", "name")`, `sai_service_command("start", index, "service_name", "provider")`},
+		Description: "Command running a service action (start, stop, restart, enable, disable, status, is-active) with the service manager of the system.",
+	},
+	{
+		Name: "sai_port", Category: "saidata",
+		Usage:       []string{`sai_port()`, `sai_port(index)`, `sai_port(index, "port", "provider")`, `sai_port(index, "port")`},
+		Description: "Port number at an index, failing the rendering when saidata defines no port.",
+	},
+	{
+		Name: "sai_file", Category: "saidata",
+		Usage:       []string{`sai_file("name")`, `sai_file("name", "path", "provider")`, `sai_file("name", "path")`},
+		Description: "Shell-quoted path of a file by logical name, such as config or log.",
+	},
+	{
+		Name: "sai_directory", Category: "saidata",
+		Usage:       []string{`sai_directory("name")`},
+		Description: "Shell-quoted path of a directory by logical name.",
+	},
+	{
+		Name: "sai_command", Category: "saidata",
+		Usage:       []string{`sai_command("name")`},
+		Description: "Shell-quoted executable path of a command, /usr/bin/<name> when saidata declares no path.",
+	},
+	{
+		Name: "sai_container", Category: "saidata",
+		Usage:       []string{`sai_container("name")`, `sai_container(index, "field", "provider")`, `sai_container(index, "field")`},
+		Description: "Shell-quoted full image name of a container by logical name, or a field (name, image, tag, registry, full_image, reference) at an index. The reference is fully qualified, docker.io/library/nginx:latest for nginx, as ctr requires.",
+	},
+	{
+		Name: "sai_k8s_manifest", Category: "saidata",
+		Usage:       []string{`sai_k8s_manifest()`, `sai_k8s_manifest("provider")`},
+		Description: "Containers as Kubernetes objects for kubectl apply: a Deployment per container and a Service publishing its port mappings, the ports named after the saidata ports of the same number. Objects are labeled app.kubernetes.io/instance with the software.",
+	},
+	{
+		Name: "sai_helm_values", Category: "saidata",
+		Usage:       []string{`sai_helm_values()`, `sai_helm_values("provider")`},
+		Description: "First container as the values of a Helm chart created by helm create: image.repository, image.tag, service.port and podLabels.",
+	},
+	{
+		Name: "sai_helm_chart", Category: "saidata",
+		Usage:       []string{`sai_helm_chart()`, `sai_helm_chart("field")`},
+		Description: "Shell-quoted chart of the helm provider followed by its --repo and --version, or a shell-quoted field of the chart (name, repository, url, version). The chart is the first package of the recommended repository of the provider, or its first package.",
+	},
+	{
+		Name: "sai_package_list", Category: "list",
+		Usage:       []string{`sai_package_list()`, `sai_package_list("provider")`},
+		Description: "Packages for a provider as a list, for map, quote and join, preferring provider specific packages.",
+	},
+	{
+		Name: "sai_versioned", Category: "saidata",
+		Usage:       []string{`sai_versioned("{name}={version}*", "provider")`, `sai_versioned("{name}{constraint}")`},
+		Description: "Shell-quoted package names for a provider separated by spaces, the first package formatted with the version requested with software@version: {version} is the pinned version, {constraint} the constraint terms separated by commas and {range} by spaces. A format referencing {version} leaves the package unpinned when a range was requested, and all packages are unpinned when no version was requested.",
+	},
+	{
+		Name: "map", Category: "list",
+		Usage:       []string{`map "{name}={version}" list`, `sai_package_list | map "{name}@{version}"`},
+		Description: "Formats each element of a list: {name} is the package name or string element, {version} and {checksum} the fields of the package. Packages without a version are rendered as their name alone when the format references {version}.",
+	},
+	{
+		Name: "quote", Category: "list",
+		Usage:       []string{`quote "value"`, `quote list`},
+		Description: "Alias of shellquote.",
+	},
+	{
+		Name: "join", Category: "list",
+		Usage:       []string{`join " " list`, `sai_package_list | quote | join " "`},
+		Description: "Joins the elements of a list with a separator, packages by name.",
+	},
+	{
+		Name: "shellquote", Category: "escaping",
+		Usage:       []string{`shellquote "value"`, `shellquote list`, `.Software | shellquote`},
+		Description: "Shell-quotes a string or each element of a list, leaving words of safe characters and already quoted words, such as the values of the saidata functions, as they are.",
+	},
+	{
+		Name: "regexquote", Category: "escaping",
+		Usage:       []string{`regexquote "value"`, `regexquote .Software | shellquote`},
+		Description: "Escapes the regular expression metacharacters of a value for grep -E, sed -E and awk patterns, unquoting shell-quoted values first.",
+	},
+	{
+		Name: "pathjoin", Category: "escaping",
+		Usage:       []string{`pathjoin "/opt" .Software "bin"`, `pathjoin (sai_directory "data") "backups"`},
+		Description: "Joins path elements into a cleaned, shell-quoted path, failing when later elements climb out of the first with \"..\".",
+	},
+	{
+		Name: "assert", Category: "assertion",
+		Usage:       []string{`assert condition "message"`},
+		Description: "Fails the action with the message unless the condition is true.",
+	},
+	{
+		Name: "sai_require", Category: "assertion",
+		Usage:       []string{`sai_require "kind" min`, `sai_require "kind" min "provider"`},
+		Description: "Fails the action unless saidata defines at least min resources of a kind (packages, services, files, directories, commands, ports, containers).",
+	},
+	{
+		Name: "file_exists", Category: "safety",
+		Usage:       []string{`file_exists("path")`},
+		Description: "Whether a file exists on the system.",
+	},
+	{
+		Name: "service_exists", Category: "safety",
+		Usage:       []string{`service_exists("service")`},
+		Description: "Whether a service exists on the system.",
+	},
+	{
+		Name: "command_exists", Category: "safety",
+		Usage:       []string{`command_exists("command")`},
+		Description: "Whether a command is found in PATH.",
+	},
+	{
+		Name: "directory_exists", Category: "safety",
+		Usage:       []string{`directory_exists("path")`},
+		Description: "Whether a directory exists on the system.",
+	},
+	{
+		Name: "checksum_file", Category: "safety",
+		Usage:       []string{`checksum_file("path", "sha256")`},
+		Description: "Hex digest of a file with md5, sha1, sha256 or sha512, computed without external tools.",
+	},
+	{
+		Name: "checksum_matches", Category: "safety",
+		Usage:       []string{`checksum_matches("path", "sha256:<hex>")`, `checksum_matches("path", sai_package(0, "checksum"))`},
+		Description: "Whether a file matches a checksum written as <algorithm>:<hex> or bare hex, false when the file does not exist.",
+	},
+	{
+		Name: "first_existing_path", Category: "conditional",
+		Usage:       []string{`first_existing_path("path", "fallback")`, `first_existing_path(sai_file("config"), "/usr/local/etc/nginx/nginx.conf")`},
+		Description: "First of the paths existing as a file or directory, shell-quoted, the last path when none exists.",
+	},
+	{
+		Name: "when_file", Category: "conditional",
+		Usage:       []string{`when_file("path", "value")`, `when_file("path", "value", "otherwise")`},
+		Description: "The value when the file exists, otherwise the fallback or nothing. Values render as they are.",
+	},
+	{
+		Name: "when_directory", Category: "conditional",
+		Usage:       []string{`when_directory("path", "value")`, `when_directory("path", "value", "otherwise")`},
+		Description: "The value when the directory exists, otherwise the fallback or nothing. Values render as they are.",
+	},
+	{
+		Name: "when_service", Category: "conditional",
+		Usage:       []string{`when_service("service", "value")`, `when_service(sai_service("main"), "systemctl reload nginx", "nginx -s reload")`},
+		Description: "The value when the service exists, otherwise the fallback or nothing. Values render as they are.",
+	},
+	{
+		Name: "when_command", Category: "conditional",
+		Usage:       []string{`when_command("command", "value")`, `when_command("command", "value", "otherwise")`},
+		Description: "The value when the command is found in PATH, otherwise the fallback or nothing. Values render as they are.",
+	},
+	{
+		Name: "default_config_path", Category: "defaults",
+		Usage:       []string{`default_config_path("software")`},
+		Description: "Conventional configuration file path of the software.",
+	},
+	{
+		Name: "default_log_path", Category: "defaults",
+		Usage:       []string{`default_log_path("software")`},
+		Description: "Conventional log file path of the software.",
+	},
+	{
+		Name: "default_data_dir", Category: "defaults",
+		Usage:       []string{`default_data_dir("software")`},
+		Description: "Conventional data directory of the software.",
+	},
+	{
+		Name: "default_service_name", Category: "defaults",
+		Usage:       []string{`default_service_name("software")`},
+		Description: "Conventional service name of the software.",
+	},
+	{
+		Name: "default_command_path", Category: "defaults",
+		Usage:       []string{`default_command_path("software")`},
+		Description: "Conventional executable path of the software.",
+	},
+}
+
+// Functions returns the documentation of the template functions sorted by category
+// and name
+func Functions() []FunctionDoc {
+	functions := make([]FunctionDoc, len(functionDocs))
+	copy(functions, functionDocs)
+	sort.SliceStable(functions, func(i, j int) bool {
+		if functions[i].Category != functions[j].Category {
+			return functions[i].Category < functions[j].Category
+		}
+		return functions[i].Name < functions[j].Name
+	})
+	return functions
+}
@@ -0,0 +1,28 @@
+package template
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctions_DocumentFuncMap(t *testing.T) {
+	engine := NewTemplateEngine(nil, nil)
+
+	var registered []string
+	for name := range engine.createFuncMap() {
+		registered = append(registered, name)
+	}
+	sort.Strings(registered)
+
+	var documented []string
+	for _, function := range Functions() {
+		documented = append(documented, function.Name)
+		assert.NotEmpty(t, function.Usage, function.Name)
+		assert.NotEmpty(t, function.Description, function.Name)
+	}
+	sort.Strings(documented)
+
+	assert.Equal(t, registered, documented, "every template function is documented")
+}
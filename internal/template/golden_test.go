@@ -0,0 +1,185 @@
+package template
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/types"
+)
+
+// updateGolden rewrites the golden corpus instead of comparing against it:
+//
+//	go test ./internal/template -run TestGoldenCorpus -update
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// goldenHost is a platform the golden corpus is rendered for
+type goldenHost struct {
+	Platform       string
+	OS             string
+	OSVersion      string
+	Arch           string
+	PackageManager string
+}
+
+// goldenHosts are the platforms and architectures every template is rendered for, so
+// the corpus is the same whatever platform the tests run on
+var goldenHosts = func() []goldenHost {
+	var hosts []goldenHost
+	for _, platform := range []goldenHost{
+		{Platform: "linux", OS: "ubuntu", OSVersion: "22.04", PackageManager: "apt"},
+		{Platform: "darwin", OS: "macos", OSVersion: "14.0", PackageManager: "brew"},
+		{Platform: "windows", OS: "windows", OSVersion: "11", PackageManager: "winget"},
+	} {
+		for _, arch := range []string{"amd64", "arm64"} {
+			host := platform
+			host.Arch = arch
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}()
+
+func (h goldenHost) String() string {
+	return h.Platform + "/" + h.Arch
+}
+
+// TestGoldenCorpus renders every action of the built-in providers, and the
+// post_install_message of the sample saidata, for each of goldenHosts and compares
+// the result with testdata/golden. Renderings identical on all hosts are recorded
+// once, and providers without templates, as the source builds, have no golden file.
+// After an intended change, regenerate the corpus with -update and review its diff.
+func TestGoldenCorpus(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "golden", "nginx.yaml"))
+	require.NoError(t, err)
+	saidata, err := types.LoadSoftwareDataFromYAML(data)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join("..", "..", "providers", "*.yaml"))
+	require.NoError(t, err)
+	specialized, err := filepath.Glob(filepath.Join("..", "..", "providers", "specialized", "*.yaml"))
+	require.NoError(t, err)
+	files = append(files, specialized...)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		provider, err := types.LoadProviderFromYAML(data)
+		require.NoError(t, err, file)
+
+		name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		if filepath.Base(filepath.Dir(file)) == "specialized" {
+			name = "specialized-" + name
+		}
+		t.Run(name, func(t *testing.T) {
+			corpus := renderProviderCorpus(provider, saidata)
+			if corpus == "" {
+				t.Skipf("provider %s has no templates to render", name)
+			}
+			checkGolden(t, name, corpus)
+		})
+	}
+
+	t.Run("saidata", func(t *testing.T) {
+		var corpus strings.Builder
+		writeGoldenTemplate(&corpus, "post_install_message", saidata.PostInstallMessage, "", saidata)
+		checkGolden(t, "saidata", corpus.String())
+	})
+}
+
+// renderProviderCorpus renders the templates of all the actions of a provider
+func renderProviderCorpus(provider *types.ProviderData, saidata *types.SoftwareData) string {
+	actions := make([]string, 0, len(provider.Actions))
+	for name := range provider.Actions {
+		actions = append(actions, name)
+	}
+	sort.Strings(actions)
+
+	var corpus strings.Builder
+	providerName := provider.Provider.Name
+	for _, name := range actions {
+		action := provider.Actions[name]
+		field := func(field, template string) {
+			writeGoldenTemplate(&corpus, name+"."+field, template, providerName, saidata)
+		}
+		field("template", action.Template)
+		field("command", action.Command)
+		field("script", action.Script)
+		field("rollback", action.Rollback)
+		field("detection", action.Detection)
+		if action.Validation != nil {
+			field("validation.command", action.Validation.Command)
+			field("validation.condition", action.Validation.Condition)
+		}
+		for i, step := range action.Steps {
+			field(fmt.Sprintf("steps[%d].command", i), step.Command)
+			field(fmt.Sprintf("steps[%d].condition", i), step.Condition)
+			field(fmt.Sprintf("steps[%d].rollback", i), step.Rollback)
+		}
+	}
+	return corpus.String()
+}
+
+// writeGoldenTemplate renders a template for each of goldenHosts and writes the
+// renderings under a header naming the template, followed by the hosts they were
+// rendered for unless identical on all of them
+func writeGoldenTemplate(corpus *strings.Builder, name, template, provider string, saidata *types.SoftwareData) {
+	if template == "" {
+		return
+	}
+
+	var distinct []string
+	renderings := map[string][]string{}
+	for _, host := range goldenHosts {
+		// Without a validator the defaults are the first candidate paths, which do
+		// not depend on the files of the system running the tests
+		engine := NewTemplateEngine(nil, NewSystemDefaultsGenerator(nil))
+		engine.SetSafetyMode(false)
+		rendered, err := engine.Render(template, &TemplateContext{
+			Software:       saidata.Metadata.Name,
+			Provider:       provider,
+			Saidata:        saidata,
+			Platform:       host.Platform,
+			OS:             host.OS,
+			OSVersion:      host.OSVersion,
+			Arch:           host.Arch,
+			PackageManager: host.PackageManager,
+		})
+		if err != nil {
+			rendered = "error: " + err.Error()
+		}
+		if _, ok := renderings[rendered]; !ok {
+			distinct = append(distinct, rendered)
+		}
+		renderings[rendered] = append(renderings[rendered], host.String())
+	}
+
+	for _, rendered := range distinct {
+		if len(distinct) == 1 {
+			fmt.Fprintf(corpus, "## %s\n", name)
+		} else {
+			fmt.Fprintf(corpus, "## %s [%s]\n", name, strings.Join(renderings[rendered], " "))
+		}
+		corpus.WriteString(strings.TrimRight(rendered, "\n"))
+		corpus.WriteString("\n\n")
+	}
+}
+
+// checkGolden compares a corpus with its golden file, or rewrites the file with -update
+func checkGolden(t *testing.T, name, corpus string) {
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(path, []byte(corpus), 0644))
+		return
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden file, generate it with -update")
+	assert.Equal(t, string(expected), corpus, "rendering changed, review it and regenerate %s with -update", path)
+}
@@ -0,0 +1,101 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"sai/internal/k8s"
+)
+
+// The kubernetes and helm providers deploy the containers of saidata to a cluster,
+// feeding kubectl and helm from a heredoc of their script:
+//
+//	kubectl apply -f - <<'SAI_MANIFEST'
+//	{{sai_k8s_manifest()}}
+//	SAI_MANIFEST
+
+// saiK8sManifest returns the containers of saidata as Kubernetes Deployments and
+// Services, for the provider of the template context unless one is given
+func (r *renderer) saiK8sManifest(args ...string) (string, error) {
+	if r.saidata == nil {
+		return "", errors.New("no saidata context available")
+	}
+	provider, err := r.optionalProvider(args)
+	if err != nil {
+		return "", err
+	}
+	manifest, err := k8s.Manifest(r.saidata, r.software, provider)
+	return strings.TrimSuffix(manifest, "\n"), err
+}
+
+// saiHelmValues returns the first container of saidata as the values of a Helm chart,
+// for the provider of the template context unless one is given
+func (r *renderer) saiHelmValues(args ...string) (string, error) {
+	if r.saidata == nil {
+		return "", errors.New("no saidata context available")
+	}
+	provider, err := r.optionalProvider(args)
+	if err != nil {
+		return "", err
+	}
+	values, err := k8s.Values(r.saidata, provider)
+	return strings.TrimSuffix(values, "\n"), err
+}
+
+// saiHelmChart returns the arguments of helm installing the chart saidata declares,
+// the shell-quoted chart followed by its --repo and --version, or a shell-quoted field
+// of the chart: name, repository, url or version
+func (r *renderer) saiHelmChart(args ...string) (string, error) {
+	if r.saidata == nil {
+		return "", errors.New("no saidata context available")
+	}
+	if len(args) > 1 {
+		return "", fmt.Errorf("accepts 0 or 1 argument, got %d", len(args))
+	}
+	chart := k8s.FindChart(r.saidata, r.provider)
+	if chart == nil {
+		return "", fmt.Errorf("saidata of %s declares no helm chart", r.software)
+	}
+
+	if len(args) == 1 {
+		var value string
+		switch args[0] {
+		case "name":
+			value = chart.Name
+		case "repository":
+			value = chart.Repository
+		case "url":
+			value = chart.URL
+		case "version":
+			value = chart.Version
+		default:
+			return "", fmt.Errorf("unsupported chart field: %s", args[0])
+		}
+		if value == "" {
+			return "", nil
+		}
+		return shellQuote(value), nil
+	}
+
+	words := []string{shellQuote(chart.Name)}
+	if chart.URL != "" {
+		words = append(words, "--repo", shellQuote(chart.URL))
+	}
+	if chart.Version != "" {
+		words = append(words, "--version", shellQuote(chart.Version))
+	}
+	return strings.Join(words, " "), nil
+}
+
+// optionalProvider returns the provider given as the only argument of a function,
+// the provider of the template context without argument
+func (r *renderer) optionalProvider(args []string) (string, error) {
+	switch len(args) {
+	case 0:
+		return r.provider, nil
+	case 1:
+		return args[0], nil
+	}
+	return "", fmt.Errorf("accepts 0 or 1 argument, got %d", len(args))
+}
@@ -0,0 +1,80 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sai/internal/types"
+)
+
+func TestTemplateEngine_Kubernetes(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+
+	saidata := &types.SoftwareData{
+		Version:    "0.2",
+		Metadata:   types.Metadata{Name: "prometheus"},
+		Containers: []types.Container{{Name: "prometheus", Image: "prom/prometheus", Tag: "v2.45.0", Ports: []string{"9090:9090"}}},
+		Providers: map[string]types.ProviderConfig{
+			"helm": {
+				Repositories: []types.Repository{{
+					Name: "prometheus-community", URL: "https://prometheus-community.github.io/helm-charts",
+					Packages: []types.Package{{Name: "prometheus", PackageName: "kube-prometheus-stack"}},
+				}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		template string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "chart arguments",
+			provider: "helm",
+			template: `helm upgrade --install {{.Software}} {{sai_helm_chart()}}`,
+			expected: `helm upgrade --install prometheus kube-prometheus-stack --repo https://prometheus-community.github.io/helm-charts`,
+		},
+		{
+			name:     "chart field",
+			provider: "helm",
+			template: `{{sai_helm_chart('repository')}}/{{sai_helm_chart('name')}}`,
+			expected: `prometheus-community/kube-prometheus-stack`,
+		},
+		{
+			name:     "values",
+			provider: "helm",
+			template: "--values - <<'EOF'\n{{sai_helm_values()}}\nEOF",
+			expected: "--values - <<'EOF'\nimage:\n  repository: docker.io/prom/prometheus\n  tag: v2.45.0\nservice:\n  port: 9090\nEOF",
+		},
+		{
+			name:     "no chart",
+			provider: "kubernetes",
+			template: `{{sai_helm_chart()}}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := &TemplateContext{Software: "prometheus", Provider: tt.provider, Saidata: saidata}
+			result, err := engine.Render(tt.template, context)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	manifest, err := engine.Render(`{{sai_k8s_manifest()}}`, &TemplateContext{Software: "prometheus", Provider: "kubernetes", Saidata: saidata})
+	require.NoError(t, err)
+	assert.Contains(t, manifest, "kind: Deployment")
+	assert.Contains(t, manifest, "kind: Service")
+	assert.Contains(t, manifest, "image: docker.io/prom/prometheus:v2.45.0")
+}
@@ -0,0 +1,140 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"sai/internal/types"
+)
+
+// List functions build commands over all the packages of the software, each package
+// being one shell word whatever its name or version contains:
+//
+//	{{sai_package_list | map "{name}={version}" | quote | join " "}}
+//	{{join(' ', quote(map('{name}={version}', sai_package_list())))}}
+
+// shellSafe are the characters a word can contain without being quoted
+const shellSafe = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-"
+
+// saiPackageList returns the packages of a provider, preferring its provider specific
+// packages as sai_packages does. The provider defaults to the template context one.
+func (r *renderer) saiPackageList(args ...string) ([]types.Package, error) {
+	if r.saidata == nil {
+		return nil, errors.New("no saidata context available")
+	}
+	if len(args) > 1 {
+		return nil, fmt.Errorf("accepts 0 or 1 arguments, got %d", len(args))
+	}
+
+	provider := r.provider
+	if len(args) == 1 {
+		provider = args[0]
+	}
+
+	packages := r.saidata.Packages
+	if providerConfig := r.saidata.GetProviderConfig(provider); providerConfig != nil && len(providerConfig.Packages) > 0 {
+		packages = providerConfig.Packages
+	}
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("no packages found for provider %s", provider)
+	}
+
+	list := make([]types.Package, len(packages))
+	copy(list, packages)
+	return list, nil
+}
+
+// mapList formats each element of a list. {name} is replaced by the package name (or
+// the element of a list of strings), {version} and {checksum} by the fields of the
+// package. Packages without a version are rendered as their name alone when the
+// format references {version}, so "{name}={version}" only pins declared versions.
+func (e *TemplateEngine) mapList(format string, list interface{}) ([]string, error) {
+	if packages, ok := list.([]types.Package); ok {
+		mapped := make([]string, 0, len(packages))
+		for _, pkg := range packages {
+			name := pkg.GetPackageNameOrDefault()
+			if pkg.Version == "" && strings.Contains(format, "{version}") {
+				mapped = append(mapped, name)
+				continue
+			}
+			mapped = append(mapped, strings.NewReplacer(
+				"{name}", name,
+				"{version}", pkg.Version,
+				"{checksum}", pkg.Checksum,
+			).Replace(format))
+		}
+		return mapped, nil
+	}
+
+	values, err := listStrings("map", list)
+	if err != nil {
+		return nil, err
+	}
+	mapped := make([]string, 0, len(values))
+	for _, value := range values {
+		mapped = append(mapped, strings.ReplaceAll(format, "{name}", value))
+	}
+	return mapped, nil
+}
+
+// quote shell-quotes a string, or each element of a list. Words made of safe
+// characters only, or already quoted, are left as they are.
+func (e *TemplateEngine) quote(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		return shellQuote(s), nil
+	}
+
+	values, err := listStrings("quote", value)
+	if err != nil {
+		return nil, err
+	}
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, shellQuote(v))
+	}
+	return quoted, nil
+}
+
+// joinList joins the elements of a list with a separator
+func (e *TemplateEngine) joinList(separator string, list interface{}) (string, error) {
+	values, err := listStrings("join", list)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(values, separator), nil
+}
+
+// listStrings converts a list of a template to strings, packages to their names
+func listStrings(function string, list interface{}) ([]string, error) {
+	switch values := list.(type) {
+	case []string:
+		return values, nil
+	case []types.Package:
+		names := make([]string, 0, len(values))
+		for _, pkg := range values {
+			names = append(names, pkg.GetPackageNameOrDefault())
+		}
+		return names, nil
+	case []interface{}:
+		strs := make([]string, 0, len(values))
+		for _, value := range values {
+			strs = append(strs, fmt.Sprint(value))
+		}
+		return strs, nil
+	default:
+		return nil, fmt.Errorf("%s: expected a list, got %T", function, list)
+	}
+}
+
+// shellQuote quotes a word for POSIX shells unless it only has safe characters or is
+// already quoted, as the values of the saidata functions are
+func shellQuote(word string) string {
+	if word != "" && strings.Trim(word, shellSafe) == "" {
+		return word
+	}
+	if _, quoted := shellUnquote(word); quoted {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
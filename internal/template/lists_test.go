@@ -0,0 +1,162 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/types"
+)
+
+func TestTemplateEngine_ListFunctions(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+
+	saidata := &types.SoftwareData{
+		Version:  "0.2",
+		Metadata: types.Metadata{Name: "nginx"},
+		Packages: []types.Package{
+			{Name: "nginx"},
+		},
+		Providers: map[string]types.ProviderConfig{
+			"apt": {
+				Packages: []types.Package{
+					{Name: "nginx", PackageName: "nginx-full", Version: "1.24.0-1"},
+					{Name: "nginx-common"},
+					{Name: "odd", PackageName: "lib it's"},
+				},
+			},
+		},
+	}
+	context := &TemplateContext{Software: "nginx", Provider: "apt", Saidata: saidata}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "pinned versions, quoted",
+			template: `apt-get install -y {{sai_package_list | map "{name}={version}" | quote | join " "}}`,
+			expected: `apt-get install -y nginx-full=1.24.0-1 nginx-common 'lib it'\''s'`,
+		},
+		{
+			name:     "legacy syntax",
+			template: `{{join(' ', quote(map('{name}@{version}', sai_package_list())))}}`,
+			expected: `nginx-full@1.24.0-1 nginx-common 'lib it'\''s'`,
+		},
+		{
+			name:     "other provider falls back to default packages",
+			template: `{{sai_package_list "brew" | join ","}}`,
+			expected: `nginx`,
+		},
+		{
+			name:     "quote a string",
+			template: `{{quote "a b"}} {{quote ""}} {{quote "/usr/bin/nginx"}}`,
+			expected: `'a b' '' /usr/bin/nginx`,
+		},
+		{
+			name:     "map over strings",
+			template: `{{sai_package_list | map "{name}" | map "--with={name}" | quote | join " "}}`,
+			expected: `--with=nginx-full --with=nginx-common '--with=lib it'\''s'`,
+		},
+		{
+			name:     "join of a non-list",
+			template: `{{join " " 42}}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := engine.Render(tt.template, context)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "nginx", shellQuote("nginx"))
+	assert.Equal(t, "python3.11=3.11.2-6", shellQuote("python3.11=3.11.2-6"))
+	assert.Equal(t, "'a;rm -rf /'", shellQuote("a;rm -rf /"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, "''", shellQuote(""))
+}
+
+func TestTemplateEngine_Versioned(t *testing.T) {
+	engine := NewTemplateEngine(NewMockResourceValidator(), NewMockDefaultsGenerator())
+
+	saidata := &types.SoftwareData{
+		Version:  "0.2",
+		Metadata: types.Metadata{Name: "nginx"},
+		Packages: []types.Package{{Name: "nginx"}, {Name: "nginx-common"}},
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		template string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "no version requested",
+			template: `apt-get install -y {{sai_versioned('{name}={version}*', 'apt')}}{{if .VersionConstraint}} pinned{{end}}`,
+			expected: `apt-get install -y nginx nginx-common`,
+		},
+		{
+			name:     "pinned version",
+			version:  "1.24",
+			template: `apt-get install -y {{sai_versioned('{name}={version}*', 'apt')}} # {{.Version}} {{.VersionConstraint}}`,
+			expected: `apt-get install -y 'nginx=1.24*' nginx-common # 1.24 >=1.24,<1.25`,
+		},
+		{
+			name:     "range refused by a pinned format",
+			version:  ">=1.20 <1.25",
+			template: `{{sai_versioned "{name}={version}*"}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "range refused by a version flag",
+			version:  ">=1.20 <1.25",
+			template: `choco install nginx{{if .VersionConstraint}}{{assert .Version "choco only installs exact versions"}} --version {{quote .Version}}{{end}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "pinned version flag",
+			version:  "1.24",
+			template: `choco install nginx{{if .VersionConstraint}}{{assert .Version "choco only installs exact versions"}} --version {{quote .Version}}{{end}}`,
+			expected: `choco install nginx --version 1.24`,
+		},
+		{
+			name:     "range",
+			version:  ">=1.20 <1.25",
+			template: `{{sai_versioned "{name}{constraint}"}} {{sai_versioned "{name}@{range}"}}`,
+			expected: `'nginx>=1.20,<1.25' nginx-common 'nginx@>=1.20 <1.25' nginx-common`,
+		},
+		{
+			name:     "invalid version",
+			version:  "1.24;id",
+			template: `{{sai_versioned "{name}={version}"}}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := &TemplateContext{Software: "nginx", Provider: "apt", Saidata: saidata, Version: tt.version}
+			result, err := engine.Render(tt.template, context)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"sai/internal/version"
+)
+
+// Install templates pin the version requested with software@version on the main
+// package of the software, the first one, in the syntax of their package manager:
+//
+//	apt-get install -y {{sai_versioned('{name}={version}*', 'apt')}}
+//	pip install {{sai_versioned('{name}{constraint}', 'pypi')}}
+//
+// Without a requested version they render as sai_package('*', 'name', provider).
+
+// saiVersioned returns the package names of a provider separated by spaces, the first
+// package formatted with format when a version was requested: {name} is its name,
+// {version} the pinned version, {constraint} the constraint terms separated by commas
+// (>=1.20,<1.25) and {range} separated by spaces. A format referencing {version} fails
+// when a range was requested, as the package manager only installs exact versions.
+// Each word is shell-quoted.
+func (r *renderer) saiVersioned(format string, args ...string) (string, error) {
+	if len(args) > 1 {
+		return "", fmt.Errorf("accepts 1 or 2 arguments, got %d", len(args)+1)
+	}
+	packages, err := r.saiPackageList(args...)
+	if err != nil {
+		return "", err
+	}
+
+	words := make([]string, 0, len(packages))
+	for i, pkg := range packages {
+		word := pkg.GetPackageNameOrDefault()
+		if i == 0 && r.version != nil {
+			if word, err = formatVersioned(format, word, r.version); err != nil {
+				return "", err
+			}
+		}
+		words = append(words, shellQuote(word))
+	}
+	return strings.Join(words, " "), nil
+}
+
+// formatVersioned formats a package name with a requested version
+func formatVersioned(format, name string, constraint *version.Constraint) (string, error) {
+	pinned := constraint.Pinned()
+	if pinned == "" && strings.Contains(format, "{version}") {
+		return "", fmt.Errorf("%s only installs exact versions, request a version such as %s@1.24 instead of %s", name, name, constraint)
+	}
+	terms := constraint.Terms()
+	return strings.NewReplacer(
+		"{name}", name,
+		"{version}", pinned,
+		"{constraint}", strings.Join(terms, ","),
+		"{range}", strings.Join(terms, " "),
+	).Replace(format), nil
+}
+
+// parseContextVersion parses the version constraint of a template context, nil when
+// no version was requested
+func parseContextVersion(spec string) (*version.Constraint, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	return version.Parse(spec)
+}
@@ -85,6 +85,14 @@ func (m *MockProviderManager) GetProvidersForAction(action string) []*types.Prov
 	return providers
 }
 
+func (m *MockProviderManager) WhichProvidersCan(action string, saidata *types.SoftwareData) []interfaces.ProviderCapability {
+	var capabilities []interfaces.ProviderCapability
+	for _, provider := range m.GetProvidersForAction(action) {
+		capabilities = append(capabilities, interfaces.ProviderCapability{Provider: provider, Available: true, Capable: true})
+	}
+	return capabilities
+}
+
 func (m *MockProviderManager) ValidateProvider(provider *types.ProviderData) error {
 	return nil
 }
@@ -314,6 +322,16 @@ func (m *MockExecutor) ExecuteSteps(ctx context.Context, steps []types.Step, sai
 	}, nil
 }
 
+func (m *MockExecutor) ExecuteScript(ctx context.Context, script string, software string, saidata *types.SoftwareData, provider *types.ProviderData, options interfaces.ExecuteOptions) (*interfaces.ExecutionResult, error) {
+	return &interfaces.ExecutionResult{
+		Success:  true,
+		Output:   "Mock script execution",
+		Commands: []string{script},
+		ExitCode: 0,
+		Duration: time.Millisecond * 10,
+	}, nil
+}
+
 // MockResourceValidator implements interfaces.ResourceValidator for testing
 type MockResourceValidator struct {
 	files       map[string]bool
@@ -0,0 +1,177 @@
+package transaction
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sai/internal/debug"
+	"sai/internal/fileutil"
+)
+
+// Statuses of a transaction
+const (
+	StatusOpen       = "open"        // The action is running, or sai stopped while it ran
+	StatusCommitted  = "committed"   // The action succeeded
+	StatusFailed     = "failed"      // The action failed and was not rolled back
+	StatusRolledBack = "rolled_back" // The changes of the transaction were undone
+)
+
+// Change is a change made to the system by an action. A change is undone either by
+// running its rollback command or by executing its inverse action on the software.
+type Change struct {
+	Type          string `json:"type"`     // package, service, environment, command...
+	Resource      string `json:"resource"` // What changed, e.g. a package or service name
+	Action        string `json:"action"`   // What was done: an action name, created, modified, executed...
+	RollbackCmd   string `json:"rollback_command,omitempty"`
+	InverseAction string `json:"inverse_action,omitempty"` // sai action undoing the change
+}
+
+// IsReversible reports whether the change can be undone
+func (c *Change) IsReversible() bool {
+	return c.RollbackCmd != "" || c.InverseAction != ""
+}
+
+// Transaction journals the changes made by one action on one software
+type Transaction struct {
+	ID           string    `json:"id"`
+	Action       string    `json:"action"`
+	Software     string    `json:"software"`
+	Provider     string    `json:"provider"`
+	Status       string    `json:"status"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at,omitempty"`
+	RolledBackAt time.Time `json:"rolled_back_at,omitempty"`
+	Changes      []Change  `json:"changes"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// RollbackPlan returns the reversible changes in the order undoing them, the most
+// recent change first
+func (t *Transaction) RollbackPlan() []Change {
+	var plan []Change
+	for i := len(t.Changes) - 1; i >= 0; i-- {
+		if t.Changes[i].IsReversible() {
+			plan = append(plan, t.Changes[i])
+		}
+	}
+	return plan
+}
+
+// Journal persists transactions as JSON files in a directory
+type Journal struct {
+	dir string
+}
+
+// NewJournal creates a journal rooted at the given directory
+func NewJournal(dir string) *Journal {
+	return &Journal{dir: dir}
+}
+
+// Dir returns the directory where transactions are stored
+func (j *Journal) Dir() string {
+	return j.dir
+}
+
+// Begin records a new open transaction
+func (j *Journal) Begin(action, software, provider string) (*Transaction, error) {
+	now := time.Now()
+	tx := &Transaction{
+		ID:        newID(now),
+		Action:    action,
+		Software:  software,
+		Provider:  provider,
+		Status:    StatusOpen,
+		StartedAt: now,
+		Changes:   []Change{},
+	}
+	if err := j.Save(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Finish records the changes and outcome of a transaction
+func (j *Journal) Finish(tx *Transaction, changes []Change, err error) error {
+	tx.Changes = append(tx.Changes, changes...)
+	tx.FinishedAt = time.Now()
+	tx.Status = StatusCommitted
+	if err != nil {
+		tx.Status = StatusFailed
+		tx.Error = debug.MaskSecrets(err.Error())
+	}
+	return j.Save(tx)
+}
+
+// Save writes a transaction
+func (j *Journal) Save(tx *Transaction) error {
+	path, err := j.path(tx.ID)
+	if err != nil {
+		return err
+	}
+	if err := fileutil.WriteJSONAtomic(path, tx, 0644); err != nil {
+		return fmt.Errorf("failed to write transaction %s: %w", tx.ID, err)
+	}
+	return nil
+}
+
+// Get returns a transaction by ID
+func (j *Journal) Get(id string) (*Transaction, error) {
+	path, err := j.path(id)
+	if err != nil {
+		return nil, err
+	}
+	var tx Transaction
+	if err := fileutil.ReadJSON(path, &tx); err != nil {
+		return nil, fmt.Errorf("no transaction found with ID %s: %w", id, err)
+	}
+	return &tx, nil
+}
+
+// List returns all transactions, the most recent first
+func (j *Journal) List() ([]*Transaction, error) {
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Transaction{}, nil
+		}
+		return nil, fmt.Errorf("failed to read transaction journal: %w", err)
+	}
+
+	transactions := []*Transaction{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		tx, err := j.Get(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue // Skip unreadable transactions
+		}
+		transactions = append(transactions, tx)
+	}
+
+	sort.Slice(transactions, func(i, k int) bool {
+		return transactions[i].StartedAt.After(transactions[k].StartedAt)
+	})
+	return transactions, nil
+}
+
+// path returns the file path of a transaction, refusing IDs that are not file names
+func (j *Journal) path(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid transaction ID %q", id)
+	}
+	return filepath.Join(j.dir, id+".json"), nil
+}
+
+// newID returns a sortable, unique transaction ID
+func newID(now time.Time) string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return now.Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,73 @@
+package transaction
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_BeginFinishGet(t *testing.T) {
+	journal := NewJournal(t.TempDir())
+
+	tx, err := journal.Begin("install", "nginx", "apt")
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, tx.Status)
+
+	stored, err := journal.Get(tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, stored.Status)
+
+	changes := []Change{
+		{Type: "command", Resource: "write config", Action: "executed", RollbackCmd: "rm /etc/nginx/sai.conf"},
+		{Type: "package", Resource: "nginx", Action: "installed", InverseAction: "uninstall"},
+	}
+	require.NoError(t, journal.Finish(tx, changes, nil))
+
+	stored, err = journal.Get(tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCommitted, stored.Status)
+	assert.Equal(t, changes, stored.Changes)
+	assert.False(t, stored.FinishedAt.IsZero())
+
+	failed, err := journal.Begin("start", "nginx", "systemd")
+	require.NoError(t, err)
+	require.NoError(t, journal.Finish(failed, nil, fmt.Errorf("unit not found")))
+	stored, err = journal.Get(failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, stored.Status)
+	assert.Equal(t, "unit not found", stored.Error)
+
+	transactions, err := journal.List()
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, failed.ID, transactions[0].ID, "most recent first")
+}
+
+func TestJournal_InvalidID(t *testing.T) {
+	journal := NewJournal(t.TempDir())
+	for _, id := range []string{"", "../escape", ".hidden", "a/b"} {
+		_, err := journal.Get(id)
+		assert.Error(t, err, id)
+	}
+}
+
+func TestJournal_ListMissingDir(t *testing.T) {
+	transactions, err := NewJournal(t.TempDir() + "/missing").List()
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+}
+
+func TestTransaction_RollbackPlan(t *testing.T) {
+	tx := &Transaction{Changes: []Change{
+		{Resource: "first", RollbackCmd: "undo first"},
+		{Resource: "irreversible"},
+		{Resource: "last", InverseAction: "stop"},
+	}}
+
+	plan := tx.RollbackPlan()
+	require.Len(t, plan, 2)
+	assert.Equal(t, "last", plan[0].Resource)
+	assert.Equal(t, "first", plan[1].Resource)
+}
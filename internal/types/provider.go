@@ -3,6 +3,9 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -12,8 +15,9 @@ import (
 type ProviderData struct {
 	Version  string                 `yaml:"version" json:"version"`
 	Provider ProviderInfo          `yaml:"provider" json:"provider"`
-	Actions  map[string]Action     `yaml:"actions" json:"actions"`
-	Mappings *Mappings             `yaml:"mappings,omitempty" json:"mappings,omitempty"`
+	Actions   map[string]Action     `yaml:"actions" json:"actions"`
+	Mappings  *Mappings             `yaml:"mappings,omitempty" json:"mappings,omitempty"`
+	Bootstrap *Bootstrap            `yaml:"bootstrap,omitempty" json:"bootstrap,omitempty"`
 }
 
 // ProviderInfo contains metadata about the provider
@@ -26,6 +30,27 @@ type ProviderInfo struct {
 	Capabilities []string `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
 	Priority     int      `yaml:"priority,omitempty" json:"priority,omitempty"`
 	Executable   string   `yaml:"executable,omitempty" json:"executable,omitempty"`
+	Alternatives []string `yaml:"alternatives,omitempty" json:"alternatives,omitempty"` // Executables with a compatible command line detected, in order, when the executable is missing
+	Shell        string   `yaml:"shell,omitempty" json:"shell,omitempty"`
+}
+
+// Shell dialects used to render provider commands
+const (
+	ShellPOSIX      = "sh"
+	ShellPowerShell = "powershell"
+	ShellCmd        = "cmd"
+)
+
+// GetShell returns the shell dialect of the provider commands, defaulting to POSIX sh
+func (pi *ProviderInfo) GetShell() string {
+	switch strings.ToLower(pi.Shell) {
+	case ShellPowerShell, "pwsh":
+		return ShellPowerShell
+	case ShellCmd:
+		return ShellCmd
+	default:
+		return ShellPOSIX
+	}
 }
 
 // Action represents a single action that can be performed by the provider
@@ -34,7 +59,13 @@ type Action struct {
 	Template      string            `yaml:"template,omitempty" json:"template,omitempty"`
 	Command       string            `yaml:"command,omitempty" json:"command,omitempty"`
 	Script        string            `yaml:"script,omitempty" json:"script,omitempty"`
+	Interpreter   string            `yaml:"interpreter,omitempty" json:"interpreter,omitempty"`
+	WorkDir       string            `yaml:"workdir,omitempty" json:"workdir,omitempty"`
+	Downloads     []ActionDownload  `yaml:"downloads,omitempty" json:"downloads,omitempty"` // Files fetched and verified before the commands run
+	Checkouts     []ActionCheckout  `yaml:"checkouts,omitempty" json:"checkouts,omitempty"` // Git repositories cloned before the commands run
+	Build         bool              `yaml:"build,omitempty" json:"build,omitempty"`         // Build and install the sources of the saidata before the commands run
 	Steps         []Step            `yaml:"steps,omitempty" json:"steps,omitempty"`
+	MaxParallel   int               `yaml:"max_parallel,omitempty" json:"max_parallel,omitempty"` // Concurrency limit of steps declaring depends_on
 	RequiresRoot  bool              `yaml:"requires_root,omitempty" json:"requires_root,omitempty"`
 	Timeout       int               `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 	Retry         *RetryConfig      `yaml:"retry,omitempty" json:"retry,omitempty"`
@@ -42,6 +73,97 @@ type Action struct {
 	Rollback      string            `yaml:"rollback,omitempty" json:"rollback,omitempty"`
 	Variables     map[string]string `yaml:"variables,omitempty" json:"variables,omitempty"`
 	Detection     string            `yaml:"detection,omitempty" json:"detection,omitempty"`
+	Inputs        []Input           `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	Parser        string            `yaml:"parser,omitempty" json:"parser,omitempty"` // Parser normalizing the output of list_installed or search
+}
+
+// Failure policies of hooks
+const (
+	HookAbort = "abort" // A failing pre hook cancels the action, a failing post hook fails it
+	HookWarn  = "warn"  // A failing hook is reported and the action goes on
+)
+
+// Hook is a shell script run before or after an action, such as a backup before an
+// uninstall or a notification after an install. Hooks are declared by saidata and the
+// configuration under a pre_<action> or post_<action> key and rendered as templates.
+type Hook struct {
+	Name      string `yaml:"name,omitempty" json:"name,omitempty"`
+	Command   string `yaml:"command" json:"command"`
+	OnFailure string `yaml:"on_failure,omitempty" json:"on_failure,omitempty"` // abort or warn, abort for pre hooks and warn for post hooks when unset
+	Timeout   int    `yaml:"timeout,omitempty" json:"timeout,omitempty"`       // Seconds, 300 when unset
+}
+
+// GetTimeout returns the hook timeout, 5 minutes when unset
+func (h *Hook) GetTimeout() time.Duration {
+	if h.Timeout > 0 {
+		return time.Duration(h.Timeout) * time.Second
+	}
+	return 300 * time.Second
+}
+
+// HookKey returns the key of the hooks run before (pre) or after (post) an action
+func HookKey(phase, action string) string {
+	return phase + "_" + action
+}
+
+// hookKeyPattern matches the keys hooks are declared under
+var hookKeyPattern = regexp.MustCompile(`^(pre|post)_[a-z][a-z_-]*$`)
+
+// ValidateHooks checks the keys, commands and failure policies of hooks
+func ValidateHooks(hooks map[string][]Hook) error {
+	for key, list := range hooks {
+		if !hookKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid hook key '%s', must be pre_<action> or post_<action>", key)
+		}
+		for i, hook := range list {
+			if strings.TrimSpace(hook.Command) == "" {
+				return fmt.Errorf("hook %d of %s has no command", i+1, key)
+			}
+			if hook.OnFailure != "" && hook.OnFailure != HookAbort && hook.OnFailure != HookWarn {
+				return fmt.Errorf("invalid on_failure '%s' for hook %d of %s, must be one of: %s, %s", hook.OnFailure, i+1, key, HookAbort, HookWarn)
+			}
+		}
+	}
+	return nil
+}
+
+// Input types accepted by action inputs
+const (
+	InputTypeString  = "string"
+	InputTypeBoolean = "boolean"
+	InputTypeNumber  = "number"
+)
+
+// Input is a value collected from the user before an action runs, such as a license
+// acceptance or an admin password, and available to templates as .Variables.<name>
+type Input struct {
+	Name    string   `yaml:"name" json:"name"`
+	Prompt  string   `yaml:"prompt,omitempty" json:"prompt,omitempty"`
+	Type    string   `yaml:"type,omitempty" json:"type,omitempty"` // string (default), boolean or number
+	Secret  bool     `yaml:"secret,omitempty" json:"secret,omitempty"`
+	Default string   `yaml:"default,omitempty" json:"default,omitempty"`
+	Actions []string `yaml:"actions,omitempty" json:"actions,omitempty"` // Actions asking for the input, all when empty (saidata inputs)
+}
+
+// GetType returns the input type, string when unset
+func (i *Input) GetType() string {
+	if i.Type == "" {
+		return InputTypeString
+	}
+	return i.Type
+}
+
+// AppliesTo reports whether the input is asked for the given action
+func (i *Input) AppliesTo(action string) bool {
+	if len(i.Actions) == 0 {
+		return true
+	}
+	for _, a := range i.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
 }
 
 // Step represents a single step in a multi-step action
@@ -51,6 +173,104 @@ type Step struct {
 	Condition     string `yaml:"condition,omitempty" json:"condition,omitempty"`
 	IgnoreFailure bool   `yaml:"ignore_failure,omitempty" json:"ignore_failure,omitempty"`
 	Timeout       int    `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Capture       string `yaml:"capture,omitempty" json:"capture,omitempty"` // Runtime context name receiving the trimmed step output
+	Register      string `yaml:"register,omitempty" json:"register,omitempty"` // Variable receiving the trimmed step output, {{.Registered.<name>}} in later steps
+	Rollback      string `yaml:"rollback,omitempty" json:"rollback,omitempty"` // Command undoing the step, recorded in the transaction journal
+
+	IdempotencyKey string `yaml:"idempotency_key,omitempty" json:"idempotency_key,omitempty"` // Key a completed step is checkpointed under, its name and command when empty
+
+	DependsOn []string `yaml:"depends_on,omitempty" json:"depends_on,omitempty"` // Names of steps that must complete first
+
+	// Windows primitives, used instead of command
+	SetEnv        *EnvVarStep        `yaml:"set_env,omitempty" json:"set_env,omitempty"`
+	AddPath       *PathStep          `yaml:"add_path,omitempty" json:"add_path,omitempty"`
+	QueryRegistry *RegistryQueryStep `yaml:"query_registry,omitempty" json:"query_registry,omitempty"`
+}
+
+// IsWindowsPrimitive returns true if the step uses a Windows primitive instead of a command
+func (s *Step) IsWindowsPrimitive() bool {
+	return s.SetEnv != nil || s.AddPath != nil || s.QueryRegistry != nil
+}
+
+// Scopes of Windows environment variables
+const (
+	EnvScopeUser    = "user"
+	EnvScopeMachine = "machine"
+)
+
+// EnvVarStep sets a persistent Windows environment variable
+type EnvVarStep struct {
+	Name  string `yaml:"name" json:"name"`
+	Value string `yaml:"value" json:"value"`
+	Scope string `yaml:"scope,omitempty" json:"scope,omitempty"` // user (default) or machine
+}
+
+// PathStep appends a directory to the persistent Windows PATH unless it is already present
+type PathStep struct {
+	Path  string `yaml:"path" json:"path"`
+	Scope string `yaml:"scope,omitempty" json:"scope,omitempty"` // user (default) or machine
+}
+
+// RegistryQueryStep reads a Windows registry value, typically captured for later steps
+type RegistryQueryStep struct {
+	Key   string `yaml:"key" json:"key"`                         // For example HKLM\SOFTWARE\Vendor\App
+	Value string `yaml:"value,omitempty" json:"value,omitempty"` // Value name, the default value when empty
+}
+
+// Bootstrap defines how to install the provider itself when it is not available
+type Bootstrap struct {
+	Description  string              `yaml:"description,omitempty" json:"description,omitempty"`
+	Downloads    []BootstrapDownload `yaml:"downloads,omitempty" json:"downloads,omitempty"` // Installers fetched before the commands run
+	Command      string              `yaml:"command,omitempty" json:"command,omitempty"`
+	Steps        []Step              `yaml:"steps,omitempty" json:"steps,omitempty"`
+	RequiresRoot bool                `yaml:"requires_root,omitempty" json:"requires_root,omitempty"`
+	Timeout      int                 `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// BootstrapDownload is an official installer downloaded and verified before the
+// bootstrap commands run. Installers are downloaded into a private directory the
+// commands run in, so other users cannot replace them before they run.
+type BootstrapDownload struct {
+	URL          string `yaml:"url" json:"url"`
+	Path         string `yaml:"path" json:"path"`                                     // File the commands refer to, relative to the bootstrap directory
+	Checksum     string `yaml:"checksum,omitempty" json:"checksum,omitempty"`         // sha256:<hex> or sha512:<hex>
+	ChecksumURL  string `yaml:"checksum_url,omitempty" json:"checksum_url,omitempty"` // Checksum file published next to the installer
+	Mode         string `yaml:"mode,omitempty" json:"mode,omitempty"`                 // Octal permissions, 0755 makes the installer executable
+	Platform     string `yaml:"platform,omitempty" json:"platform,omitempty"`         // Only downloaded on this platform, such as linux or darwin
+	Architecture string `yaml:"architecture,omitempty" json:"architecture,omitempty"` // Only downloaded on this architecture, such as amd64 or arm64
+}
+
+// IsVerified reports whether the download declares a checksum to verify it against
+func (d *BootstrapDownload) IsVerified() bool {
+	return d.Checksum != "" || d.ChecksumURL != ""
+}
+
+// AppliesTo reports whether the download is fetched on a platform and architecture,
+// given by their Go names
+func (d *BootstrapDownload) AppliesTo(platform, architecture string) bool {
+	return (d.Platform == "" || d.Platform == platform) && (d.Architecture == "" || d.Architecture == architecture)
+}
+
+// ActionDownload is a file, such as a release binary or an installer script, that an
+// action downloads and verifies before its commands run. Every field but the mode is a
+// template.
+type ActionDownload struct {
+	URL         string `yaml:"url" json:"url"`
+	Path        string `yaml:"path" json:"path"`                                     // Local file the commands refer to
+	Checksum    string `yaml:"checksum,omitempty" json:"checksum,omitempty"`         // sha256:<hex> or sha512:<hex>
+	ChecksumURL string `yaml:"checksum_url,omitempty" json:"checksum_url,omitempty"` // Checksum file published next to the file
+	Signature   string `yaml:"signature,omitempty" json:"signature,omitempty"`       // URL of a detached GPG signature of the file
+	Mode        string `yaml:"mode,omitempty" json:"mode,omitempty"`                 // Octal permissions, 0755 makes the file executable
+}
+
+// ActionCheckout is a git repository cloned before the commands of an action run, such
+// as the sources a build compiles. Field values are templates.
+type ActionCheckout struct {
+	Repository  string `yaml:"repository" json:"repository"`
+	Path        string `yaml:"path" json:"path"`                                     // Directory the commands build in
+	Ref         string `yaml:"ref,omitempty" json:"ref,omitempty"`                   // Tag, branch or commit, the default branch when empty
+	Submodules  bool   `yaml:"submodules,omitempty" json:"submodules,omitempty"`     // Also clone the submodules, recursively
+	FullHistory bool   `yaml:"full_history,omitempty" json:"full_history,omitempty"` // Clone the whole history instead of a shallow clone
 }
 
 // RetryConfig defines retry behavior for actions
@@ -65,6 +285,7 @@ type Validation struct {
 	Command          string `yaml:"command" json:"command"`
 	ExpectedExitCode int    `yaml:"expected_exit_code,omitempty" json:"expected_exit_code,omitempty"`
 	ExpectedOutput   string `yaml:"expected_output,omitempty" json:"expected_output,omitempty"`
+	Condition        string `yaml:"condition,omitempty" json:"condition,omitempty"` // Template that must render true once the action ran
 	Timeout          int    `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 }
 
@@ -204,11 +425,85 @@ func (a *Action) GetCommand() string {
 	return ""
 }
 
+// IsScript returns true if the action runs its multi-line script from a script file
+func (a *Action) IsScript() bool {
+	return a.Template == "" && a.Command == "" && a.Script != ""
+}
+
 // IsValid checks if the action has at least one execution method
 func (a *Action) IsValid() bool {
+	return a.Template != "" || a.Command != "" || a.Script != "" || len(a.Steps) > 0 || a.Build
+}
+
+// HasCommands returns true if the action runs commands besides building sources
+func (a *Action) HasCommands() bool {
 	return a.Template != "" || a.Command != "" || a.Script != "" || len(a.Steps) > 0
 }
 
+// GetTimeout returns the timeout for the bootstrap with fallback to default
+func (b *Bootstrap) GetTimeout() time.Duration {
+	if b.Timeout > 0 {
+		return time.Duration(b.Timeout) * time.Second
+	}
+	return 600 * time.Second // Default 10 minutes
+}
+
+// GetCommands returns the bootstrap commands in execution order
+func (b *Bootstrap) GetCommands() []string {
+	if len(b.Steps) > 0 {
+		commands := make([]string, 0, len(b.Steps))
+		for _, step := range b.Steps {
+			commands = append(commands, step.Command)
+		}
+		return commands
+	}
+	if b.Command != "" {
+		return []string{b.Command}
+	}
+	return nil
+}
+
+// IsValid checks if the bootstrap has at least one command and every download names
+// both its URL and a path inside the bootstrap directory
+func (b *Bootstrap) IsValid() bool {
+	for _, download := range b.Downloads {
+		if download.URL == "" || !filepath.IsLocal(download.Path) {
+			return false
+		}
+	}
+	return b.Command != "" || len(b.Steps) > 0
+}
+
+// DownloadsFor returns the downloads fetched on a platform and architecture. It fails
+// when a file the commands refer to has no download for them.
+func (b *Bootstrap) DownloadsFor(platform, architecture string) ([]BootstrapDownload, error) {
+	var downloads []BootstrapDownload
+	found := make(map[string]bool)
+	for _, download := range b.Downloads {
+		if download.AppliesTo(platform, architecture) {
+			downloads = append(downloads, download)
+			found[download.Path] = true
+		}
+	}
+	for _, download := range b.Downloads {
+		if !found[download.Path] {
+			return nil, fmt.Errorf("no download of %s for %s/%s", download.Path, platform, architecture)
+		}
+	}
+	return downloads, nil
+}
+
+// UnverifiedDownloads returns the URLs of downloads without a checksum
+func (b *Bootstrap) UnverifiedDownloads() []string {
+	var urls []string
+	for _, download := range b.Downloads {
+		if !download.IsVerified() {
+			urls = append(urls, download.URL)
+		}
+	}
+	return urls
+}
+
 // GetPortAsInt returns the port as an integer, handling both int and string types
 func (p *PortMapping) GetPortAsInt() (int, error) {
 	switch v := p.Port.(type) {
@@ -191,6 +191,100 @@ func TestLoadExistingProviderFiles(t *testing.T) {
 	}
 }
 
+func TestBootstrap(t *testing.T) {
+	yamlData := `
+version: "1.0"
+provider:
+  name: "cargo"
+  type: "package_manager"
+  executable: "cargo"
+bootstrap:
+  description: "Install Rust toolchain"
+  steps:
+    - name: "download"
+      command: "curl -o /tmp/rustup-init.sh https://sh.rustup.rs"
+    - name: "install"
+      command: "sh /tmp/rustup-init.sh -y"
+actions:
+  install:
+    template: "cargo install {{sai_package(0, 'name')}}"
+`
+
+	provider, err := LoadProviderFromYAML([]byte(yamlData))
+	require.NoError(t, err)
+	require.NotNil(t, provider.Bootstrap)
+
+	assert.True(t, provider.Bootstrap.IsValid())
+	assert.Equal(t, []string{"curl -o /tmp/rustup-init.sh https://sh.rustup.rs", "sh /tmp/rustup-init.sh -y"}, provider.Bootstrap.GetCommands())
+	assert.Equal(t, 600*time.Second, provider.Bootstrap.GetTimeout())
+
+	single := &Bootstrap{Command: "install-nix", Timeout: 60}
+	assert.Equal(t, []string{"install-nix"}, single.GetCommands())
+	assert.Equal(t, 60*time.Second, single.GetTimeout())
+
+	empty := &Bootstrap{}
+	assert.False(t, empty.IsValid())
+	assert.Nil(t, empty.GetCommands())
+}
+
+func TestBootstrapDownloads(t *testing.T) {
+	yamlData := `
+version: "1.0"
+provider:
+  name: "nix"
+  type: "package_manager"
+  executable: "nix-env"
+bootstrap:
+  downloads:
+    - url: "https://releases.nixos.org/nix/nix-2.24.9/install"
+      path: "nix-install.sh"
+      checksum_url: "https://releases.nixos.org/nix/nix-2.24.9/install.sha256"
+    - url: "https://example.com/install.sh"
+      path: "install.sh"
+  command: "sh nix-install.sh --daemon"
+actions:
+  install:
+    template: "nix-env -iA nixpkgs.{{sai_package(0, 'name')}}"
+`
+
+	provider, err := LoadProviderFromYAML([]byte(yamlData))
+	require.NoError(t, err)
+	require.NotNil(t, provider.Bootstrap)
+	require.Len(t, provider.Bootstrap.Downloads, 2)
+
+	assert.True(t, provider.Bootstrap.IsValid())
+	assert.True(t, provider.Bootstrap.Downloads[0].IsVerified())
+	assert.Equal(t, []string{"https://example.com/install.sh"}, provider.Bootstrap.UnverifiedDownloads())
+
+	noPath := &Bootstrap{Command: "sh install.sh", Downloads: []BootstrapDownload{{URL: "https://example.com/install.sh"}}}
+	assert.False(t, noPath.IsValid())
+
+	// Installers are only written inside the private bootstrap directory
+	for _, path := range []string{"/tmp/install.sh", "../install.sh"} {
+		shared := &Bootstrap{Command: "sh install.sh", Downloads: []BootstrapDownload{{URL: "https://example.com/install.sh", Path: path}}}
+		assert.False(t, shared.IsValid(), path)
+	}
+}
+
+func TestBootstrapDownloadsFor(t *testing.T) {
+	bootstrap := &Bootstrap{
+		Command: "./rustup-init -y",
+		Downloads: []BootstrapDownload{
+			{URL: "https://example.com/x86_64-unknown-linux-gnu/rustup-init", Path: "rustup-init", Platform: "linux", Architecture: "amd64"},
+			{URL: "https://example.com/aarch64-apple-darwin/rustup-init", Path: "rustup-init", Platform: "darwin", Architecture: "arm64"},
+			{URL: "https://example.com/LICENSE", Path: "LICENSE"},
+		},
+	}
+
+	downloads, err := bootstrap.DownloadsFor("darwin", "arm64")
+	require.NoError(t, err)
+	require.Len(t, downloads, 2)
+	assert.Equal(t, "https://example.com/aarch64-apple-darwin/rustup-init", downloads[0].URL)
+
+	_, err = bootstrap.DownloadsFor("linux", "riscv64")
+	assert.EqualError(t, err, "no download of rustup-init for linux/riscv64")
+}
+
 func TestActionMethods(t *testing.T) {
 	t.Run("GetTimeout", func(t *testing.T) {
 		action := Action{Timeout: 120}
@@ -316,4 +410,11 @@ func TestProviderDataToJSON(t *testing.T) {
 	assert.Equal(t, "1.0", result["version"])
 	assert.Contains(t, result, "provider")
 	assert.Contains(t, result, "actions")
-}
\ No newline at end of file
+}
+
+func TestValidateHooks(t *testing.T) {
+	assert.NoError(t, ValidateHooks(map[string][]Hook{"pre_uninstall": {{Command: "backup"}}}))
+	assert.Error(t, ValidateHooks(map[string][]Hook{"before_install": {{Command: "backup"}}}))
+	assert.Error(t, ValidateHooks(map[string][]Hook{"post_install": {{Command: " "}}}))
+	assert.Error(t, ValidateHooks(map[string][]Hook{"post_install": {{Command: "notify", OnFailure: "ignore"}}}))
+}
@@ -3,6 +3,10 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,10 +22,60 @@ type SoftwareData struct {
 	Commands      []Command                    `yaml:"commands,omitempty" json:"commands,omitempty"`
 	Ports         []Port                       `yaml:"ports,omitempty" json:"ports,omitempty"`
 	Containers    []Container                  `yaml:"containers,omitempty" json:"containers,omitempty"`
+	Sources       []Source                     `yaml:"sources,omitempty" json:"sources,omitempty"` // Built by actions setting build, see internal/build
 	Providers     map[string]ProviderConfig    `yaml:"providers,omitempty" json:"providers,omitempty"`
 	Compatibility *Compatibility              `yaml:"compatibility,omitempty" json:"compatibility,omitempty"`
 	Requirements  *Requirements                `yaml:"requirements,omitempty" json:"requirements,omitempty"`
+	Inputs        []Input                      `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	PostInstallMessage string                  `yaml:"post_install_message,omitempty" json:"post_install_message,omitempty"` // Next steps shown after install, a template
+	Hooks         map[string][]Hook            `yaml:"hooks,omitempty" json:"hooks,omitempty"` // Run around actions, keyed by pre_<action> or post_<action>
 	IsGenerated   bool                         `yaml:"-" json:"-"` // Runtime flag for generated defaults
+	AbsentResources []string                   `yaml:"-" json:"-"` // Runtime list of generated resources not found on the system ("type:name")
+
+	// Provider sections left undecoded by LoadSoftwareDataLazy, decoded on first use
+	lazy *lazyProviders
+}
+
+// lazyProviders are the provider sections of saidata loaded by LoadSoftwareDataLazy.
+// Sections are decoded once, on first use, under the mutex, so saidata shared across
+// goroutines can be read concurrently.
+type lazyProviders struct {
+	raw     map[string]*yaml.Node // Never modified after loading
+	mutex   sync.Mutex
+	decoded map[string]decodedProvider
+}
+
+// decodedProvider is the outcome of decoding a provider section
+type decodedProvider struct {
+	config *ProviderConfig
+	err    error
+}
+
+// decode returns the provider section of name, nil when the saidata has none
+func (l *lazyProviders) decode(name string) (*ProviderConfig, error) {
+	node, exists := l.raw[name]
+	if !exists {
+		return nil, nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if result, done := l.decoded[name]; done {
+		return result.config, result.err
+	}
+
+	var result decodedProvider
+	var config ProviderConfig
+	if err := node.Decode(&config); err != nil {
+		result.err = fmt.Errorf("failed to unmarshal saidata YAML of provider %s: %w", name, err)
+	} else {
+		result.config = &config
+	}
+	if l.decoded == nil {
+		l.decoded = make(map[string]decodedProvider)
+	}
+	l.decoded[name] = result
+	return result.config, result.err
 }
 
 // Metadata contains software metadata information
@@ -38,6 +92,31 @@ type Metadata struct {
 	Maintainer   string            `yaml:"maintainer,omitempty" json:"maintainer,omitempty"`
 	URLs         *URLs             `yaml:"urls,omitempty" json:"urls,omitempty"`
 	Security     *SecurityMetadata `yaml:"security,omitempty" json:"security,omitempty"`
+	Deprecation  *Deprecation      `yaml:"deprecation,omitempty" json:"deprecation,omitempty"`
+}
+
+// Deprecation marks a software as deprecated, or renamed upstream, and names the
+// software to use instead
+type Deprecation struct {
+	Message    string `yaml:"message,omitempty" json:"message,omitempty"`         // Why, e.g. "renamed upstream"
+	Since      string `yaml:"since,omitempty" json:"since,omitempty"`             // Version or date of the deprecation
+	ReplacedBy string `yaml:"replaced_by,omitempty" json:"replaced_by,omitempty"` // Software offered instead on install
+}
+
+// Notice describes the deprecation of a software for warnings, e.g. "nginx-light is
+// deprecated since 1.22 (merged into nginx), use nginx instead"
+func (d *Deprecation) Notice(software string) string {
+	notice := software + " is deprecated"
+	if d.Since != "" {
+		notice += " since " + d.Since
+	}
+	if d.Message != "" {
+		notice += " (" + d.Message + ")"
+	}
+	if d.ReplacedBy != "" {
+		notice += ", use " + d.ReplacedBy + " instead"
+	}
+	return notice
 }
 
 // URLs contains various URLs related to the software
@@ -79,6 +158,20 @@ type Package struct {
 	IsInstalled bool `yaml:"-" json:"-"`
 }
 
+// Source is a source archive built and installed by the build pipeline
+type Source struct {
+	Name          string            `yaml:"name" json:"name"`
+	URL           string            `yaml:"url" json:"url"`
+	Version       string            `yaml:"version,omitempty" json:"version,omitempty"`
+	Checksum      string            `yaml:"checksum,omitempty" json:"checksum,omitempty"`         // sha256:<hex> or sha512:<hex> of the archive
+	BuildSystem   string            `yaml:"build_system,omitempty" json:"build_system,omitempty"` // autotools, cmake, meson or make, detected from the sources when empty
+	Prefix        string            `yaml:"prefix,omitempty" json:"prefix,omitempty"`             // Installation prefix, /usr/local when empty
+	ConfigureArgs []string          `yaml:"configure_args,omitempty" json:"configure_args,omitempty"`
+	BuildArgs     []string          `yaml:"build_args,omitempty" json:"build_args,omitempty"`
+	InstallArgs   []string          `yaml:"install_args,omitempty" json:"install_args,omitempty"`
+	Env           map[string]string `yaml:"env,omitempty" json:"env,omitempty"` // Variables of the configure, build and install commands, such as CFLAGS
+}
+
 // Service represents a system service
 type Service struct {
 	Name        string   `yaml:"name" json:"name"`
@@ -87,19 +180,21 @@ type Service struct {
 	Enabled     bool     `yaml:"enabled,omitempty" json:"enabled,omitempty"`
 	ConfigFiles []string `yaml:"config_files,omitempty" json:"config_files,omitempty"`
 	// Runtime validation flags
-	Exists   bool `yaml:"-" json:"-"`
-	IsActive bool `yaml:"-" json:"-"`
+	Exists    bool `yaml:"-" json:"-"`
+	IsActive  bool `yaml:"-" json:"-"`
+	IsEnabled bool `yaml:"-" json:"-"`
 }
 
 // File represents a file resource
 type File struct {
-	Name   string `yaml:"name" json:"name"`
-	Path   string `yaml:"path" json:"path"`
-	Type   string `yaml:"type,omitempty" json:"type,omitempty"`
-	Owner  string `yaml:"owner,omitempty" json:"owner,omitempty"`
-	Group  string `yaml:"group,omitempty" json:"group,omitempty"`
-	Mode   string `yaml:"mode,omitempty" json:"mode,omitempty"`
-	Backup bool   `yaml:"backup,omitempty" json:"backup,omitempty"`
+	Name     string `yaml:"name" json:"name"`
+	Path     string `yaml:"path" json:"path"`
+	Type     string `yaml:"type,omitempty" json:"type,omitempty"`
+	Owner    string `yaml:"owner,omitempty" json:"owner,omitempty"`
+	Group    string `yaml:"group,omitempty" json:"group,omitempty"`
+	Mode     string `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Backup   bool   `yaml:"backup,omitempty" json:"backup,omitempty"`
+	Checksum string `yaml:"checksum,omitempty" json:"checksum,omitempty"` // sha256:<hex> or sha512:<hex> of an installed binary
 	// Runtime validation flags
 	Exists bool `yaml:"-" json:"-"`
 }
@@ -124,6 +219,7 @@ type Command struct {
 	Aliases         []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
 	ShellCompletion bool     `yaml:"shell_completion,omitempty" json:"shell_completion,omitempty"`
 	ManPage         string   `yaml:"man_page,omitempty" json:"man_page,omitempty"`
+	Checksum        string   `yaml:"checksum,omitempty" json:"checksum,omitempty"` // sha256:<hex> or sha512:<hex> of the installed executable
 	// Runtime validation flags
 	Exists bool `yaml:"-" json:"-"`
 }
@@ -168,6 +264,17 @@ type ProviderConfig struct {
 	Commands       []Command       `yaml:"commands,omitempty" json:"commands,omitempty"`
 	Ports          []Port          `yaml:"ports,omitempty" json:"ports,omitempty"`
 	Containers     []Container     `yaml:"containers,omitempty" json:"containers,omitempty"`
+
+	// Args are extra arguments of the provider commands, keyed by action (install,
+	// uninstall, ...), such as --no-install-recommends for apt install
+	Args map[string][]string `yaml:"args,omitempty" json:"args,omitempty"`
+
+	// Unsupported marks software the provider cannot manage, typically on one architecture
+	Unsupported bool `yaml:"unsupported,omitempty" json:"unsupported,omitempty"`
+
+	// Architectures holds overrides merged into the config on a matching architecture,
+	// keyed by architecture (amd64, arm64, ...). They are resolved when saidata is loaded.
+	Architectures map[string]ProviderConfig `yaml:"architectures,omitempty" json:"architectures,omitempty"`
 }
 
 // PackageSource represents a package source with priority
@@ -255,29 +362,74 @@ func LoadSoftwareDataFromYAML(data []byte) (*SoftwareData, error) {
 	if err := yaml.Unmarshal(data, &saidata); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal saidata YAML: %w", err)
 	}
-	
+	saidata.setDefaults()
+	return &saidata, nil
+}
+
+// LoadSoftwareDataLazy loads saidata from a YAML stream, leaving the provider sections
+// undecoded until GetProviderConfig or DecodeProviders asks for them. Bulk operations
+// reading many definitions, such as repository statistics, only pay for the providers
+// they look at, and ProviderNames lists the providers without decoding any.
+func LoadSoftwareDataLazy(r io.Reader) (*SoftwareData, error) {
+	var document yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to unmarshal saidata YAML: %w", err)
+	}
+
+	var saidata SoftwareData
+	if len(document.Content) == 0 {
+		return &saidata, nil
+	}
+	root := document.Content[0]
+
+	// Set the providers section aside before decoding the rest
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != "providers" {
+				continue
+			}
+			providers := root.Content[i+1]
+			if providers.Kind != yaml.MappingNode {
+				break // Let the full decode report the type error
+			}
+			saidata.lazy = &lazyProviders{raw: make(map[string]*yaml.Node, len(providers.Content)/2)}
+			for j := 0; j+1 < len(providers.Content); j += 2 {
+				saidata.lazy.raw[providers.Content[j].Value] = providers.Content[j+1]
+			}
+			root.Content = append(root.Content[:i:i], root.Content[i+2:]...)
+			break
+		}
+	}
+
+	if err := root.Decode(&saidata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal saidata YAML: %w", err)
+	}
+	saidata.setDefaults()
+	return &saidata, nil
+}
+
+// setDefaults fills the fields a definition may omit
+func (s *SoftwareData) setDefaults() {
 	// Set default service names if not specified
-	for i, service := range saidata.Services {
+	for i, service := range s.Services {
 		if service.ServiceName == "" {
-			saidata.Services[i].ServiceName = service.Name
+			s.Services[i].ServiceName = service.Name
 		}
 	}
-	
+
 	// Set default command paths if not specified
-	for i, command := range saidata.Commands {
+	for i, command := range s.Commands {
 		if command.Path == "" {
-			saidata.Commands[i].Path = fmt.Sprintf("/usr/bin/%s", command.Name)
+			s.Commands[i].Path = fmt.Sprintf("/usr/bin/%s", command.Name)
 		}
 	}
-	
+
 	// Set default port protocols if not specified
-	for i, port := range saidata.Ports {
+	for i, port := range s.Ports {
 		if port.Protocol == "" {
-			saidata.Ports[i].Protocol = "tcp"
+			s.Ports[i].Protocol = "tcp"
 		}
 	}
-	
-	return &saidata, nil
 }
 
 // ToJSON converts the saidata to JSON for validation
@@ -488,14 +640,83 @@ func (s *SoftwareData) GetContainerByName(name string) *Container {
 	return nil
 }
 
-// GetProviderConfig returns provider-specific configuration
+// GetProviderConfig returns provider-specific configuration, decoding it first when
+// the saidata was loaded lazily. A provider section that fails to decode is treated
+// as missing; LoadProviderConfig reports the error.
 func (s *SoftwareData) GetProviderConfig(providerName string) *ProviderConfig {
+	config, err := s.LoadProviderConfig(providerName)
+	if err != nil {
+		return nil
+	}
+	return config
+}
+
+// LoadProviderConfig returns provider-specific configuration like GetProviderConfig,
+// and the error of a provider section that fails to decode. Decoding leaves the saidata
+// unchanged, so it is safe for concurrent use.
+func (s *SoftwareData) LoadProviderConfig(providerName string) (*ProviderConfig, error) {
 	if config, exists := s.Providers[providerName]; exists {
-		return &config
+		return &config, nil
+	}
+	if s.lazy == nil {
+		return nil, nil
+	}
+	config, err := s.lazy.decode(providerName)
+	if config == nil || err != nil {
+		return nil, err
+	}
+	copied := *config
+	return &copied, nil
+}
+
+// DecodeProviders decodes the provider sections left undecoded by LoadSoftwareDataLazy
+// into Providers, for callers reading the Providers map directly. It modifies the
+// saidata, so it must be called before the saidata is shared across goroutines.
+func (s *SoftwareData) DecodeProviders() error {
+	if s.lazy == nil {
+		return nil
+	}
+	for name := range s.lazy.raw {
+		if _, decoded := s.Providers[name]; decoded {
+			continue
+		}
+		config, err := s.lazy.decode(name)
+		if err != nil {
+			return err
+		}
+		if s.Providers == nil {
+			s.Providers = make(map[string]ProviderConfig)
+		}
+		s.Providers[name] = *config
 	}
 	return nil
 }
 
+// ProviderNames returns the sorted names of the providers configured by the saidata,
+// decoded or not
+func (s *SoftwareData) ProviderNames() []string {
+	names := make([]string, 0, len(s.Providers))
+	for name := range s.Providers {
+		names = append(names, name)
+	}
+	if s.lazy != nil {
+		for name := range s.lazy.raw {
+			if _, decoded := s.Providers[name]; !decoded {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsProviderSupported reports whether the provider can manage the software, that is
+// whether its provider config, if any, is not marked unsupported
+func (s *SoftwareData) IsProviderSupported(providerName string) bool {
+	config := s.GetProviderConfig(providerName)
+	return config == nil || !config.Unsupported
+}
+
 // GetPlatformsAsStrings converts platform interface{} to []string
 func (c *CompatibilityEntry) GetPlatformsAsStrings() []string {
 	return interfaceToStringSlice(c.Platform)
@@ -552,6 +773,33 @@ func (c *Container) GetFullImageName() string {
 	return imageName
 }
 
+// GetImageReference returns the fully qualified image reference, as runtimes without
+// Docker's defaults such as containerd's ctr require: docker.io/library/nginx:latest
+// for the image nginx
+func (c *Container) GetImageReference() string {
+	registry := c.Registry
+	image := c.Image
+	if registry == "" {
+		// The first component of the image names its registry when it looks like a host
+		if first, rest, found := strings.Cut(image, "/"); found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+			registry, image = first, rest
+		} else {
+			registry = "docker.io"
+		}
+	}
+	if registry == "docker.io" && !strings.Contains(image, "/") {
+		image = "library/" + image
+	}
+	tag := c.Tag
+	if tag == "" && !strings.Contains(image, "@") {
+		tag = "latest"
+	}
+	if tag != "" {
+		image += ":" + tag
+	}
+	return registry + "/" + image
+}
+
 // GetServiceNameOrDefault returns the service name or defaults to the logical name
 func (s *Service) GetServiceNameOrDefault() string {
 	if s.ServiceName != "" {
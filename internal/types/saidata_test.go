@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -173,6 +175,67 @@ packages:
 	}
 }
 
+func TestLoadSoftwareDataLazy(t *testing.T) {
+	yamlData := `
+version: "0.2"
+metadata:
+  name: nginx
+services:
+  - name: nginx
+providers:
+  apt:
+    packages:
+      - name: nginx
+        package_name: nginx-full
+  brew: {}
+  broken:
+    packages: "not a list"
+`
+	data, err := LoadSoftwareDataLazy(strings.NewReader(yamlData))
+	require.NoError(t, err)
+
+	assert.Equal(t, "nginx", data.Metadata.Name)
+	assert.Equal(t, "nginx", data.Services[0].ServiceName, "defaults are set")
+	assert.Empty(t, data.Providers, "provider sections are not decoded on load")
+	assert.Equal(t, []string{"apt", "brew", "broken"}, data.ProviderNames())
+
+	apt := data.GetProviderConfig("apt")
+	require.NotNil(t, apt)
+	assert.Equal(t, "nginx-full", apt.Packages[0].PackageName)
+	assert.Empty(t, data.Providers, "reading a provider section leaves the saidata unchanged")
+	assert.Nil(t, data.GetProviderConfig("yum"))
+	assert.Nil(t, data.GetProviderConfig("broken"))
+	_, err = data.LoadProviderConfig("broken")
+	assert.Error(t, err)
+	assert.True(t, data.IsProviderSupported("brew"))
+
+	assert.Error(t, data.DecodeProviders())
+
+	_, err = LoadSoftwareDataLazy(strings.NewReader("metadata: ["))
+	assert.Error(t, err)
+
+	empty, err := LoadSoftwareDataLazy(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, empty.ProviderNames())
+}
+
+func TestLoadSoftwareDataLazy_Concurrent(t *testing.T) {
+	data, err := LoadSoftwareDataLazy(strings.NewReader("providers:\n  apt:\n    packages:\n      - name: nginx\n"))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if config := data.GetProviderConfig("apt"); config == nil || config.Packages[0].Name != "nginx" {
+				t.Errorf("GetProviderConfig() = %+v", config)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func TestLoadExistingSaidataFiles(t *testing.T) {
 	// Test loading actual saidata files from the samples directory
 	saidataFiles := []string{
@@ -350,6 +413,21 @@ func TestCompatibilityEntry(t *testing.T) {
 	})
 }
 
+func TestDeprecation(t *testing.T) {
+	data, err := LoadSoftwareDataFromYAML([]byte(`version: "0.2"
+metadata:
+  name: nginx-light
+  deprecation:
+    message: merged into nginx
+    since: "1.22"
+    replaced_by: nginx
+`))
+	require.NoError(t, err)
+	require.NotNil(t, data.Metadata.Deprecation)
+	assert.Equal(t, "nginx-light is deprecated since 1.22 (merged into nginx), use nginx instead", data.Metadata.Deprecation.Notice("nginx-light"))
+	assert.Equal(t, "telnet is deprecated", (&Deprecation{}).Notice("telnet"))
+}
+
 func TestContainerMethods(t *testing.T) {
 	t.Run("GetFullImageName", func(t *testing.T) {
 		// Test with registry and tag
@@ -372,6 +450,15 @@ func TestContainerMethods(t *testing.T) {
 		container = Container{Image: "nginx", Registry: "docker.io"}
 		assert.Equal(t, "docker.io/nginx", container.GetFullImageName())
 	})
+
+	t.Run("GetImageReference", func(t *testing.T) {
+		assert.Equal(t, "docker.io/library/nginx:latest", (&Container{Image: "nginx"}).GetImageReference())
+		assert.Equal(t, "docker.io/library/nginx:1.21", (&Container{Image: "nginx", Tag: "1.21", Registry: "docker.io"}).GetImageReference())
+		assert.Equal(t, "docker.io/bitnami/redis:7", (&Container{Image: "bitnami/redis", Tag: "7"}).GetImageReference())
+		assert.Equal(t, "ghcr.io/acme/app:latest", (&Container{Image: "ghcr.io/acme/app"}).GetImageReference())
+		assert.Equal(t, "quay.io/prometheus/node-exporter:v1", (&Container{Image: "prometheus/node-exporter", Registry: "quay.io", Tag: "v1"}).GetImageReference())
+		assert.Equal(t, "localhost:5000/app:dev", (&Container{Image: "localhost:5000/app", Tag: "dev"}).GetImageReference())
+	})
 }
 
 func TestServiceMethods(t *testing.T) {
@@ -4,8 +4,12 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+
+	"github.com/charmbracelet/x/term"
 
 	"sai/internal/config"
 	"sai/internal/output"
@@ -16,6 +20,7 @@ type UserInterface struct {
 	config    *config.Config
 	formatter *output.OutputFormatter
 	reader    *bufio.Reader
+	tui       bool // Use the full-screen terminal UI instead of line-based prompts
 }
 
 // ProviderOption represents a provider option for user selection
@@ -51,6 +56,10 @@ func (ui *UserInterface) ShowProviderSelection(software string, options []*Provi
 		return options[0], nil
 	}
 
+	if ui.usesTUI() {
+		return ui.selectProviderTUI(software, options)
+	}
+
 	ui.formatter.ShowInfo(fmt.Sprintf("Multiple providers available for %s:", software))
 	fmt.Println()
 
@@ -102,6 +111,120 @@ func (ui *UserInterface) ShowProviderSelection(software string, options []*Provi
 	}
 }
 
+// selectProviderTUI lets the user pick a provider in the terminal UI
+func (ui *UserInterface) selectProviderTUI(software string, options []*ProviderOption) (*ProviderOption, error) {
+	items := make([]selectItem, len(options))
+	for i, option := range options {
+		var details []string
+		if option.Command != "" {
+			details = append(details, "Command: "+option.Command)
+		} else {
+			details = append(details, "Package: "+option.PackageName)
+			if option.Version != "" {
+				details = append(details, "Version: "+option.Version)
+			}
+		}
+		status := "Available"
+		if option.IsInstalled {
+			status = "Installed"
+		}
+		details = append(details, "Status: "+status)
+		items[i] = selectItem{Title: option.Name, Detail: strings.Join(details, "\n")}
+	}
+
+	selected, err := runSelect(fmt.Sprintf("Select the provider for %s", software), items, false)
+	if err != nil {
+		return nil, err
+	}
+	return options[selected[0]], nil
+}
+
+// SelectSoftware lets the user choose which of several software to process, all
+// being selected initially. Without a terminal every software is returned.
+func (ui *UserInterface) SelectSoftware(message string, software []string) ([]string, error) {
+	if !ui.IsInteractive() || len(software) < 2 {
+		return software, nil
+	}
+
+	if ui.usesTUI() {
+		items := make([]selectItem, len(software))
+		for i, name := range software {
+			items[i] = selectItem{Title: name, Selected: true}
+		}
+		selected, err := runSelect(message, items, true)
+		if err != nil {
+			return nil, err
+		}
+		var chosen []string
+		for _, index := range selected {
+			chosen = append(chosen, software[index])
+		}
+		return chosen, nil
+	}
+
+	fmt.Println(message)
+	for i, name := range software {
+		fmt.Printf("%d. %s\n", i+1, name)
+	}
+	for {
+		fmt.Printf("Select software (e.g. 1,3, empty for all): ")
+		input, err := ui.reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read user input: %w", err)
+		}
+		input = strings.TrimSpace(input)
+		if input == "" || strings.EqualFold(input, "all") {
+			return software, nil
+		}
+
+		var chosen []string
+		valid := true
+		for _, field := range strings.Split(input, ",") {
+			choice, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || choice < 1 || choice > len(software) {
+				valid = false
+				break
+			}
+			chosen = append(chosen, software[choice-1])
+		}
+		if valid {
+			return chosen, nil
+		}
+		ui.formatter.ShowError(fmt.Errorf("invalid selection. Please enter numbers between 1 and %d", len(software)))
+	}
+}
+
+// MonitorProgress shows the live progress of tasks in the terminal UI. It returns nil,
+// whose methods do nothing, when the terminal UI is not used.
+func (ui *UserInterface) MonitorProgress(title string, tasks []string) *ProgressMonitor {
+	if !ui.usesTUI() {
+		return nil
+	}
+	return newProgressMonitor(title, tasks, os.Stderr)
+}
+
+// SetTUI enables the full-screen terminal UI for selections and progress
+func (ui *UserInterface) SetTUI(enabled bool) {
+	ui.tui = enabled
+}
+
+// usesTUI reports whether the terminal UI is enabled and can be drawn
+func (ui *UserInterface) usesTUI() bool {
+	return ui.tui && !ui.formatter.IsJSONMode() && TUIAvailable()
+}
+
+// TUIAvailable reports whether the terminal UI can be used: both stdin and stderr,
+// where it is drawn, are terminals
+func TUIAvailable() bool {
+	for _, file := range []*os.File{os.Stdin, os.Stderr} {
+		info, err := file.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // ConfirmAction prompts for confirmation of system-changing actions (Requirements 9.1, 9.2)
 func (ui *UserInterface) ConfirmAction(action, software, provider string, commands []string) (bool, error) {
 	if ui.formatter.IsJSONMode() {
@@ -144,6 +267,39 @@ func (ui *UserInterface) PromptForInput(message string) (string, error) {
 	return strings.TrimSpace(input), nil
 }
 
+// PromptForSecret prompts for a sensitive value without echoing it when stdin is a terminal
+func (ui *UserInterface) PromptForSecret(message string) (string, error) {
+	if ui.formatter.IsJSONMode() {
+		return "", fmt.Errorf("interactive input not supported in JSON mode")
+	}
+
+	fmt.Print(message)
+	if fd := os.Stdin.Fd(); term.IsTerminal(fd) {
+		secret, err := readPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("failed to read user input: %w", err)
+		}
+		return string(secret), nil
+	}
+
+	input, err := ui.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read user input: %w", err)
+	}
+
+	return strings.TrimRight(input, "\r\n"), nil
+}
+
+// IsInteractive reports whether the user can answer prompts on stdin
+func (ui *UserInterface) IsInteractive() bool {
+	if ui.formatter.IsJSONMode() {
+		return false
+	}
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 // PromptForConfirmation prompts for a yes/no confirmation
 func (ui *UserInterface) PromptForConfirmation(message string) (bool, error) {
 	if ui.formatter.IsJSONMode() {
@@ -160,6 +316,21 @@ func (ui *UserInterface) PromptForConfirmation(message string) (bool, error) {
 	return input == "y" || input == "yes", nil
 }
 
+// PromptForTypedConfirmation requires the user to type the expected phrase exactly to confirm
+func (ui *UserInterface) PromptForTypedConfirmation(message, expected string) (bool, error) {
+	if ui.formatter.IsJSONMode() {
+		return false, fmt.Errorf("interactive confirmation not supported in JSON mode")
+	}
+
+	fmt.Printf("%s\nType \"%s\" to continue: ", message, expected)
+	input, err := ui.reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read user input: %w", err)
+	}
+
+	return strings.TrimSpace(input) == expected, nil
+}
+
 // ShowCommandPreview displays commands that will be executed
 func (ui *UserInterface) ShowCommandPreview(commands []string, provider string) {
 	ui.formatter.ShowCommandPreview(commands, provider)
@@ -259,4 +430,32 @@ func (ui *UserInterface) printTableSeparator(colWidths []int) {
 		fmt.Print(strings.Repeat("-", width+2))
 	}
 	fmt.Println()
-}
\ No newline at end of file
+}
+
+// readPassword reads a line from a terminal without echoing it. The terminal state is
+// restored before exiting when the prompt is interrupted, since echo would stay off.
+func readPassword(fd uintptr) ([]byte, error) {
+	state, err := term.GetState(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the terminal state: %w", err)
+	}
+
+	interrupted := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	defer func() {
+		signal.Stop(interrupted)
+		close(done)
+	}()
+	go func() {
+		select {
+		case <-interrupted:
+			_ = term.Restore(fd, state)
+			fmt.Println()
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	return term.ReadPassword(fd)
+}
@@ -0,0 +1,340 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrSelectionCancelled is returned when the user leaves a selection without choosing
+var ErrSelectionCancelled = errors.New("selection cancelled")
+
+// selectItem is an entry of a selection list
+type selectItem struct {
+	Title    string
+	Detail   string // Shown under the title, may span several lines
+	Selected bool
+}
+
+// selectModel is a full-screen list the user picks one entry, or several entries
+// when multi is set, from
+type selectModel struct {
+	title     string
+	items     []selectItem
+	multi     bool
+	cursor    int
+	height    int
+	done      bool
+	cancelled bool
+}
+
+func (m *selectModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *selectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			m.cancelled = true
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.items)-1 {
+				m.cursor++
+			}
+		case "home", "g":
+			m.cursor = 0
+		case "end", "G":
+			m.cursor = len(m.items) - 1
+		case " ", "x":
+			if m.multi {
+				m.items[m.cursor].Selected = !m.items[m.cursor].Selected
+			}
+		case "a":
+			if m.multi {
+				all := true
+				for _, item := range m.items {
+					all = all && item.Selected
+				}
+				for i := range m.items {
+					m.items[i].Selected = !all
+				}
+			}
+		case "enter":
+			if !m.multi {
+				m.items[m.cursor].Selected = true
+			}
+			m.done = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *selectModel) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", m.title)
+
+	// Keep the cursor visible when the list is taller than the terminal
+	first, last := m.visibleRange()
+	for i := first; i < last; i++ {
+		item := m.items[i]
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		check := ""
+		if m.multi {
+			check = "[ ] "
+			if item.Selected {
+				check = "[x] "
+			}
+		}
+		fmt.Fprintf(&b, "%s%s%s\n", cursor, check, item.Title)
+		if item.Detail != "" {
+			for _, line := range strings.Split(item.Detail, "\n") {
+				fmt.Fprintf(&b, "      %s\n", line)
+			}
+		}
+	}
+
+	if m.multi {
+		b.WriteString("\n↑/↓ move • space toggle • a all • enter confirm • esc cancel\n")
+	} else {
+		b.WriteString("\n↑/↓ move • enter select • esc cancel\n")
+	}
+	return b.String()
+}
+
+// visibleRange returns the items fitting the terminal around the cursor
+func (m *selectModel) visibleRange() (int, int) {
+	linesPerItem := 1
+	for _, item := range m.items {
+		if lines := 1 + strings.Count(item.Detail, "\n") + 1; item.Detail != "" && lines > linesPerItem {
+			linesPerItem = lines
+		}
+	}
+	visible := len(m.items)
+	if m.height > 0 {
+		// Title, blank line, blank line and help take 4 lines
+		visible = (m.height - 4) / linesPerItem
+		if visible < 1 {
+			visible = 1
+		}
+	}
+	if visible >= len(m.items) {
+		return 0, len(m.items)
+	}
+	first := m.cursor - visible/2
+	if first < 0 {
+		first = 0
+	}
+	if first+visible > len(m.items) {
+		first = len(m.items) - visible
+	}
+	return first, first + visible
+}
+
+// selected returns the indexes of the selected items
+func (m *selectModel) selected() []int {
+	var indexes []int
+	for i, item := range m.items {
+		if item.Selected {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// runSelect shows a full-screen selection list and returns the indexes of the chosen items
+func runSelect(title string, items []selectItem, multi bool) ([]int, error) {
+	model := &selectModel{title: title, items: items, multi: multi}
+	if _, err := tea.NewProgram(model, tea.WithAltScreen(), tea.WithOutput(os.Stderr)).Run(); err != nil {
+		return nil, fmt.Errorf("terminal UI failed: %w", err)
+	}
+	if model.cancelled {
+		return nil, ErrSelectionCancelled
+	}
+	return model.selected(), nil
+}
+
+// Task statuses of the progress monitor
+const (
+	taskPending = iota
+	taskRunning
+	taskSucceeded
+	taskFailed
+)
+
+// spinnerFrames animate the running tasks
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// progressTask is a task shown by the progress monitor
+type progressTask struct {
+	name    string
+	status  int
+	detail  string
+	started time.Time
+}
+
+// taskMsg changes the status of a task
+type taskMsg struct {
+	index  int
+	status int
+	detail string
+}
+
+// tickMsg advances the spinner
+type tickMsg struct{}
+
+// stopMsg ends the progress monitor
+type stopMsg struct{}
+
+// progressModel shows the status of tasks running concurrently
+type progressModel struct {
+	title   string
+	tasks   []progressTask
+	width   int
+	frame   int
+	stopped bool
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+func (m *progressModel) Init() tea.Cmd {
+	return tick()
+}
+
+func (m *progressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case taskMsg:
+		task := &m.tasks[msg.index]
+		if msg.status == taskRunning {
+			task.started = time.Now()
+		}
+		task.status = msg.status
+		task.detail = msg.detail
+	case tickMsg:
+		m.frame = (m.frame + 1) % len(spinnerFrames)
+		return m, tick()
+	case stopMsg:
+		m.stopped = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *progressModel) View() string {
+	var b strings.Builder
+	done := 0
+	nameWidth := 0
+	for _, task := range m.tasks {
+		if task.status == taskSucceeded || task.status == taskFailed {
+			done++
+		}
+		if len(task.name) > nameWidth {
+			nameWidth = len(task.name)
+		}
+	}
+	fmt.Fprintf(&b, "%s (%d/%d)\n", m.title, done, len(m.tasks))
+
+	for _, task := range m.tasks {
+		var symbol, detail string
+		switch task.status {
+		case taskPending:
+			symbol, detail = "·", "pending"
+		case taskRunning:
+			symbol = spinnerFrames[m.frame]
+			detail = fmt.Sprintf("running (%s)", time.Since(task.started).Round(time.Second))
+		case taskSucceeded:
+			symbol, detail = "✓", task.detail
+		case taskFailed:
+			symbol, detail = "✗", task.detail
+		}
+		// Multi-line errors would break the layout, keep their first line
+		detail, _, _ = strings.Cut(detail, "\n")
+		line := fmt.Sprintf("  %s %-*s  %s", symbol, nameWidth, task.name, detail)
+		if m.width > 0 && len([]rune(line)) > m.width {
+			line = string([]rune(line)[:m.width-1]) + "…"
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// ProgressMonitor shows the live status of tasks, such as the software of a batch,
+// until it is stopped. Its methods do nothing on a nil monitor.
+type ProgressMonitor struct {
+	program *tea.Program
+	done    chan struct{}
+	once    sync.Once
+}
+
+// newProgressMonitor starts showing the progress of tasks on out
+func newProgressMonitor(title string, tasks []string, out io.Writer) *ProgressMonitor {
+	model := &progressModel{title: title}
+	for _, task := range tasks {
+		model.tasks = append(model.tasks, progressTask{name: task})
+	}
+
+	monitor := &ProgressMonitor{
+		program: tea.NewProgram(model, tea.WithOutput(out), tea.WithInput(nil)),
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(monitor.done)
+		monitor.program.Run()
+	}()
+	return monitor
+}
+
+// Start marks a task as running
+func (pm *ProgressMonitor) Start(index int) {
+	if pm != nil {
+		pm.program.Send(taskMsg{index: index, status: taskRunning})
+	}
+}
+
+// Finish marks a task as succeeded or failed with a detail such as the error
+func (pm *ProgressMonitor) Finish(index int, success bool, detail string) {
+	if pm == nil {
+		return
+	}
+	status := taskSucceeded
+	if !success {
+		status = taskFailed
+	}
+	pm.program.Send(taskMsg{index: index, status: status, detail: detail})
+}
+
+// Stop renders the final status of the tasks and returns once the monitor exited
+func (pm *ProgressMonitor) Stop() {
+	if pm == nil {
+		return
+	}
+	pm.once.Do(func() {
+		pm.program.Send(stopMsg{})
+		<-pm.done
+	})
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestSelectModelSingle(t *testing.T) {
+	model := &selectModel{
+		title: "Select the provider for nginx",
+		items: []selectItem{{Title: "apt", Detail: "Command: apt install nginx"}, {Title: "snap"}, {Title: "docker"}},
+	}
+
+	for _, k := range []string{"down", "down", "down", "up"} {
+		model.Update(key(k))
+	}
+	view := model.View()
+	if !strings.Contains(view, "> snap") {
+		t.Errorf("Expected the cursor on snap, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Command: apt install nginx") {
+		t.Errorf("Expected the detail of apt, got:\n%s", view)
+	}
+
+	// Toggling does nothing in a single selection
+	model.Update(key(" "))
+	if _, cmd := model.Update(key("enter")); cmd == nil {
+		t.Error("Expected enter to quit")
+	}
+	if got := model.selected(); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("Expected snap to be selected, got %v", got)
+	}
+}
+
+func TestSelectModelMulti(t *testing.T) {
+	model := &selectModel{
+		items: []selectItem{{Title: "nginx", Selected: true}, {Title: "redis", Selected: true}, {Title: "git", Selected: true}},
+		multi: true,
+	}
+
+	model.Update(key("down"))
+	model.Update(key(" "))
+	if got := model.selected(); !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Errorf("Expected redis to be deselected, got %v", got)
+	}
+	if view := model.View(); !strings.Contains(view, "> [ ] redis") || !strings.Contains(view, "  [x] nginx") {
+		t.Errorf("Unexpected view:\n%s", view)
+	}
+
+	// a selects everything unless everything is selected
+	model.Update(key("a"))
+	if got := model.selected(); len(got) != 3 {
+		t.Errorf("Expected all software to be selected, got %v", got)
+	}
+	model.Update(key("a"))
+	if got := model.selected(); len(got) != 0 {
+		t.Errorf("Expected no software to be selected, got %v", got)
+	}
+
+	model.Update(key("esc"))
+	if !model.cancelled {
+		t.Error("Expected esc to cancel")
+	}
+}
+
+func TestSelectModelScrolls(t *testing.T) {
+	model := &selectModel{height: 7}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		model.items = append(model.items, selectItem{Title: name})
+	}
+	for i := 0; i < 6; i++ {
+		model.Update(key("down"))
+	}
+
+	first, last := model.visibleRange()
+	if last-first != 3 || model.cursor < first || model.cursor >= last {
+		t.Errorf("Expected 3 visible items around the cursor %d, got %d-%d", model.cursor, first, last)
+	}
+}
+
+func TestProgressModel(t *testing.T) {
+	model := &progressModel{title: "install 3 software"}
+	for _, name := range []string{"nginx", "redis", "git"} {
+		model.tasks = append(model.tasks, progressTask{name: name})
+	}
+
+	model.Update(taskMsg{index: 0, status: taskRunning})
+	model.Update(taskMsg{index: 0, status: taskSucceeded, detail: "ok (apt, 2s)"})
+	model.Update(taskMsg{index: 1, status: taskRunning})
+	model.Update(taskMsg{index: 2, status: taskFailed, detail: "failed: not found\nSuggestions:"})
+
+	view := model.View()
+	for _, expected := range []string{"install 3 software (2/3)", "✓ nginx  ok (apt, 2s)", "redis  running", "✗ git    failed: not found"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected %q in view:\n%s", expected, view)
+		}
+	}
+	if strings.Contains(view, "Suggestions") {
+		t.Errorf("Expected only the first line of errors, got:\n%s", view)
+	}
+
+	if _, cmd := model.Update(stopMsg{}); cmd == nil || !model.stopped {
+		t.Error("Expected stop to quit")
+	}
+}
+
+func TestProgressMonitor(t *testing.T) {
+	var out bytes.Buffer
+	monitor := newProgressMonitor("install 1 software", []string{"nginx"}, &out)
+	monitor.Start(0)
+	monitor.Finish(0, true, "ok (apt, 1s)")
+	monitor.Stop()
+	monitor.Stop()
+
+	if !strings.Contains(out.String(), "ok (apt, 1s)") {
+		t.Errorf("Expected the final status in the output, got %q", out.String())
+	}
+
+	// A nil monitor does nothing
+	var disabled *ProgressMonitor
+	disabled.Start(0)
+	disabled.Finish(0, false, "")
+	disabled.Stop()
+}
@@ -0,0 +1,296 @@
+// Package upgradetest checks the upgrade path of software in a throwaway container: it
+// installs an old version, runs the upgrade action and verifies the upgraded version,
+// so broken upgrade templates are caught before users run them.
+package upgradetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sai/internal/interfaces"
+)
+
+// Paths of the sai binary, saidata and provider definitions inside the container
+const (
+	containerBinary  = "/usr/local/bin/sai"
+	containerSaidata = "/etc/sai/saidata"
+	containerWorkDir = "/sai"
+)
+
+// Phases of an upgrade test, in order
+const (
+	PhaseStart         = "start"
+	PhaseInstall       = "install"
+	PhaseVerifyInstall = "verify-install"
+	PhaseUpgrade       = "upgrade"
+	PhaseVerifyUpgrade = "verify-upgrade"
+	PhaseCleanup       = "cleanup"
+)
+
+// Options describe an upgrade test
+type Options struct {
+	Software   string
+	From       string    // Version installed first, "1.x" style wildcards allowed
+	To         string    // Version expected after the upgrade
+	Provider   string    // Provider of the install and upgrade, detected in the container when empty
+	Image      string    // Container image the test runs in
+	Runtime    string    // docker or podman, detected when empty
+	Binary     string    // Linux sai executable mounted into the container
+	SaidataDir string    // Saidata repository mounted into the container
+	WorkDir    string    // Directory holding the providers and schemas directories
+	Packages   []string  // Names the software is installed under, the software name when empty
+	Keep       bool      // Leave the container running for inspection
+	Output     io.Writer // Receives the output of each phase, nil discards it
+}
+
+// Phase is the outcome of one step of an upgrade test
+type Phase struct {
+	Name     string        `json:"name"`
+	Command  string        `json:"command"`
+	Success  bool          `json:"success"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the outcome of an upgrade test
+type Report struct {
+	Software         string   `json:"software"`
+	From             string   `json:"from"`
+	To               string   `json:"to"`
+	Provider         string   `json:"provider,omitempty"`
+	Image            string   `json:"image"`
+	Container        string   `json:"container"`
+	InstalledVersion string   `json:"installed_version,omitempty"`
+	UpgradedVersion  string   `json:"upgraded_version,omitempty"`
+	Phases           []*Phase `json:"phases"`
+	Success          bool     `json:"success"`
+}
+
+// runner runs a container runtime command and returns its standard output, and its
+// standard error in the error of failed commands
+type runner func(ctx context.Context, name string, args ...string) (string, error)
+
+// DetectRuntime returns the first container runtime found in PATH
+func DetectRuntime() (string, error) {
+	for _, runtime := range []string{"docker", "podman"} {
+		if _, err := exec.LookPath(runtime); err == nil {
+			return runtime, nil
+		}
+	}
+	return "", fmt.Errorf("no container runtime found, install docker or podman")
+}
+
+// Run installs the old version in a new container, upgrades it and verifies the
+// installed versions. The report lists every phase run; the returned error is set
+// when the test could not pass.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Runtime == "" {
+		runtime, err := DetectRuntime()
+		if err != nil {
+			return nil, err
+		}
+		opts.Runtime = runtime
+	}
+	return run(ctx, opts, execRunner)
+}
+
+func run(ctx context.Context, opts Options, runCommand runner) (*Report, error) {
+	report := &Report{
+		Software:  opts.Software,
+		From:      opts.From,
+		To:        opts.To,
+		Provider:  opts.Provider,
+		Image:     opts.Image,
+		Container: fmt.Sprintf("sai-upgrade-test-%s-%d", sanitize(opts.Software), time.Now().Unix()),
+	}
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	phase := func(name string, args []string, check func(output string) error) bool {
+		fmt.Fprintf(out, "==> %s: %s %s\n", name, opts.Runtime, strings.Join(args, " "))
+		start := time.Now()
+		output, err := runCommand(ctx, opts.Runtime, args...)
+		if err == nil && check != nil {
+			err = check(output)
+		}
+
+		p := &Phase{
+			Name:     name,
+			Command:  opts.Runtime + " " + strings.Join(args, " "),
+			Success:  err == nil,
+			Output:   strings.TrimSpace(output),
+			Duration: time.Since(start),
+		}
+		if err != nil {
+			p.Error = err.Error()
+			fmt.Fprintf(out, "    failed: %v\n", err)
+		}
+		report.Phases = append(report.Phases, p)
+		return err == nil
+	}
+
+	if !phase(PhaseStart, startArgs(opts, report.Container), nil) {
+		return report, fmt.Errorf("failed to start the %s container: %s", opts.Image, report.Phases[0].Error)
+	}
+	if !opts.Keep {
+		defer phase(PhaseCleanup, []string{"rm", "-f", report.Container}, nil)
+	}
+
+	ok := phase(PhaseInstall, saiArgs(report.Container, "install", opts, opts.From), nil) &&
+		phase(PhaseVerifyInstall, saiArgs(report.Container, "list", opts, ""), func(output string) error {
+			version, err := installedVersion(output, opts)
+			report.InstalledVersion = version
+			if err == nil && !VersionMatches(opts.From, version) {
+				err = fmt.Errorf("installed version %s does not match %s, check that the install action pins the version variable", version, opts.From)
+			}
+			return err
+		}) &&
+		phase(PhaseUpgrade, saiArgs(report.Container, "upgrade", opts, opts.To), nil) &&
+		phase(PhaseVerifyUpgrade, saiArgs(report.Container, "list", opts, ""), func(output string) error {
+			version, err := installedVersion(output, opts)
+			report.UpgradedVersion = version
+			if err == nil && !VersionMatches(opts.To, version) {
+				err = fmt.Errorf("upgraded version %s does not match %s", version, opts.To)
+			}
+			return err
+		})
+
+	report.Success = ok
+	if !ok {
+		failed := report.Phases[len(report.Phases)-1]
+		return report, fmt.Errorf("upgrade test of %s failed in the %s phase: %s", opts.Software, failed.Name, failed.Error)
+	}
+	return report, nil
+}
+
+// startArgs returns the arguments starting the test container with sai, saidata and
+// the provider definitions mounted read-only
+func startArgs(opts Options, container string) []string {
+	mount := func(source, target string) []string {
+		return []string{"-v", source + ":" + target + ":ro"}
+	}
+
+	args := []string{"run", "-d", "--name", container}
+	args = append(args, mount(opts.Binary, containerBinary)...)
+	args = append(args, mount(opts.SaidataDir, containerSaidata)...)
+	args = append(args, mount(filepath.Join(opts.WorkDir, "providers"), containerWorkDir+"/providers")...)
+	args = append(args, mount(filepath.Join(opts.WorkDir, "schemas"), containerWorkDir+"/schemas")...)
+	args = append(args, "-w", containerWorkDir, opts.Image, "sleep", "infinity")
+	return args
+}
+
+// saiArgs returns the arguments running a sai action in the container. Install and
+// upgrade pass the version as the version variable, for templates to pin it.
+func saiArgs(container, action string, opts Options, version string) []string {
+	args := []string{"exec", container, containerBinary}
+	switch action {
+	case "list":
+		args = append(args, "list", "--installed", "--json")
+	default:
+		args = append(args, action, opts.Software, "--yes")
+		if version != "" {
+			args = append(args, "--var", "version="+version)
+		}
+	}
+	// Saidata is mounted, a missing repository must not be downloaded
+	args = append(args, "--no-bootstrap")
+	if opts.Provider != "" {
+		args = append(args, "--provider", opts.Provider)
+	}
+	return args
+}
+
+// installedVersion finds the version of the software in the output of
+// sai list --installed --json
+func installedVersion(output string, opts Options) (string, error) {
+	var list struct {
+		Software []*interfaces.InstalledSoftware `json:"software"`
+	}
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return "", fmt.Errorf("failed to parse the installed software: %w", err)
+	}
+
+	names := opts.Packages
+	if len(names) == 0 {
+		names = []string{opts.Software}
+	}
+	for _, name := range names {
+		for _, software := range list.Software {
+			if !strings.EqualFold(software.Name, name) {
+				continue
+			}
+			if version, ok := software.Versions[opts.Provider]; ok {
+				return version, nil
+			}
+			// Without a provider, take the first provider reporting the software
+			providers := make([]string, 0, len(software.Versions))
+			for provider := range software.Versions {
+				providers = append(providers, provider)
+			}
+			sort.Strings(providers)
+			if opts.Provider == "" && len(providers) > 0 {
+				return software.Versions[providers[0]], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s is not installed", opts.Software)
+}
+
+// VersionMatches reports whether a version matches a version spec such as "1.x",
+// "1.24" or "1.24.0". Spec components x and * match any component, and the version
+// may have more components than the spec. Epochs and package revisions of versions
+// like 1:1.24.0-1ubuntu1 are ignored.
+func VersionMatches(spec, version string) bool {
+	if _, rest, found := strings.Cut(version, ":"); found {
+		version = rest
+	}
+	specParts := strings.Split(strings.TrimSpace(spec), ".")
+	versionParts := strings.FieldsFunc(version, func(r rune) bool {
+		return r == '.' || r == '-' || r == '+' || r == '~' || r == '_'
+	})
+	if spec == "" || len(versionParts) < len(specParts) {
+		return false
+	}
+
+	for i, part := range specParts {
+		if part != "x" && part != "X" && part != "*" && part != versionParts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitize keeps the characters allowed in container names
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+func execRunner(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return stdout.String(), fmt.Errorf("%w: %s", err, message)
+		}
+		return stdout.String(), err
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,129 @@
+package upgradetest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeRuntime answers container runtime commands with the installed version of each
+// phase, recording the commands run
+type fakeRuntime struct {
+	commands []string
+	versions []string // Versions reported by successive list commands
+	failOn   string   // Action whose command fails
+}
+
+func (f *fakeRuntime) run(ctx context.Context, name string, args ...string) (string, error) {
+	command := strings.Join(args, " ")
+	f.commands = append(f.commands, command)
+	if f.failOn != "" && strings.Contains(command, " "+f.failOn+" ") {
+		return "", errors.New("exit status 1: template rendering failed")
+	}
+	if strings.Contains(command, " list --installed") {
+		version := f.versions[0]
+		f.versions = f.versions[1:]
+		return `{"type": "installed_software_list", "software": [{"name": "nginx-core", "versions": {"apt": "` + version + `"}}]}`, nil
+	}
+	return "", nil
+}
+
+func testOptions() Options {
+	return Options{
+		Software:   "nginx",
+		From:       "1.18.x",
+		To:         "1.24",
+		Provider:   "apt",
+		Image:      "ubuntu:22.04",
+		Runtime:    "docker",
+		Binary:     "/usr/local/bin/sai",
+		SaidataDir: "/etc/sai/saidata",
+		WorkDir:    "/opt/sai",
+		Packages:   []string{"nginx", "nginx-core"},
+	}
+}
+
+func TestRun(t *testing.T) {
+	runtime := &fakeRuntime{versions: []string{"1.18.0-6ubuntu14", "1.24.0-2ubuntu7"}}
+	report, err := run(context.Background(), testOptions(), runtime.run)
+	if err != nil {
+		t.Fatalf("Expected the upgrade test to pass, got %v", err)
+	}
+	if !report.Success || report.InstalledVersion != "1.18.0-6ubuntu14" || report.UpgradedVersion != "1.24.0-2ubuntu7" {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	var phases []string
+	for _, phase := range report.Phases {
+		phases = append(phases, phase.Name)
+	}
+	expected := []string{PhaseStart, PhaseInstall, PhaseVerifyInstall, PhaseUpgrade, PhaseVerifyUpgrade, PhaseCleanup}
+	if strings.Join(phases, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected phases %v, got %v", expected, phases)
+	}
+
+	if !strings.Contains(runtime.commands[0], "-v /opt/sai/providers:/sai/providers:ro") || !strings.HasSuffix(runtime.commands[0], "ubuntu:22.04 sleep infinity") {
+		t.Errorf("Unexpected start command %q", runtime.commands[0])
+	}
+	if !strings.Contains(runtime.commands[1], "sai install nginx --yes --var version=1.18.x --no-bootstrap --provider apt") {
+		t.Errorf("Unexpected install command %q", runtime.commands[1])
+	}
+	if !strings.HasPrefix(runtime.commands[5], "rm -f sai-upgrade-test-nginx-") {
+		t.Errorf("Expected the container to be removed, got %q", runtime.commands[5])
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	// The install ignoring the requested version fails before the upgrade
+	runtime := &fakeRuntime{versions: []string{"1.24.0-2ubuntu7"}}
+	report, err := run(context.Background(), testOptions(), runtime.run)
+	if err == nil || !strings.Contains(err.Error(), "verify-install") || report.Success {
+		t.Errorf("Expected the install verification to fail, got %v", err)
+	}
+
+	// A failing upgrade is reported, and the container still removed
+	runtime = &fakeRuntime{versions: []string{"1.18.0"}, failOn: "upgrade"}
+	report, err = run(context.Background(), testOptions(), runtime.run)
+	if err == nil || !strings.Contains(err.Error(), "upgrade phase: exit status 1") {
+		t.Errorf("Expected the upgrade to fail, got %v", err)
+	}
+	if last := report.Phases[len(report.Phases)-1]; last.Name != PhaseCleanup {
+		t.Errorf("Expected the container to be removed after a failure, got %s", last.Name)
+	}
+
+	// Kept containers are not removed
+	opts := testOptions()
+	opts.Keep = true
+	runtime = &fakeRuntime{versions: []string{"1.18.0", "1.22.1"}}
+	report, err = run(context.Background(), opts, runtime.run)
+	if err == nil || !strings.Contains(err.Error(), "upgraded version 1.22.1 does not match 1.24") {
+		t.Errorf("Expected the upgrade verification to fail, got %v", err)
+	}
+	if last := report.Phases[len(report.Phases)-1]; last.Name != PhaseVerifyUpgrade {
+		t.Errorf("Expected the kept container not to be removed, got %s", last.Name)
+	}
+}
+
+func TestVersionMatches(t *testing.T) {
+	tests := []struct {
+		spec     string
+		version  string
+		expected bool
+	}{
+		{"1.x", "1.24.0", true},
+		{"1.*", "1.18.0-6ubuntu14", true},
+		{"1.24", "1.24.0-2ubuntu7", true},
+		{"1.24.0", "1:1.24.0-1", true},
+		{"1.24", "1.2", false},
+		{"2.x", "1.24.0", false},
+		{"1.24.1", "1.24", false},
+		{"", "1.24", false},
+	}
+
+	for _, tt := range tests {
+		if got := VersionMatches(tt.spec, tt.version); got != tt.expected {
+			t.Errorf("VersionMatches(%q, %q) = %v, expected %v", tt.spec, tt.version, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,541 @@
+package validation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"sai/internal/template"
+	"sai/internal/types"
+)
+
+// Severities of lint findings. Errors make 'sai validate' fail.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Categories of lint findings
+const (
+	FindingSchema        = "schema"              // The file does not parse or does not follow the schema
+	FindingMissingField  = "missing_field"       // A field the schema or a provider template needs is missing
+	FindingUnresolved    = "unresolved_variable" // A provider template renders with unresolved values
+	FindingAssertion     = "assertion"           // A provider template assertion failed
+	FindingCompatibility = "compatibility"       // The compatibility matrix or the provider sections are wrong
+)
+
+// knownArchitectures are the architecture names compatibility entries may use: the Go
+// names and the names uname and package managers report
+var knownArchitectures = map[string]bool{
+	"amd64": true, "arm64": true, "386": true, "arm": true, "ppc64le": true, "s390x": true, "riscv64": true,
+	"x86_64": true, "x64": true, "aarch64": true, "armv7l": true, "armhf": true, "i386": true, "i686": true, "x86": true,
+}
+
+// nonLinuxPlatforms are the platforms not covered by providers declaring linux
+var nonLinuxPlatforms = map[string]bool{
+	"macos": true, "darwin": true, "windows": true, "freebsd": true, "openbsd": true, "netbsd": true, "dragonfly": true,
+}
+
+var (
+	// yamlLinePattern finds the line number in YAML parse errors
+	yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+	// functionErrorPattern extracts the error of the template function that failed
+	functionErrorPattern = regexp.MustCompile(`error calling \w+: (.*)$`)
+	// missingResourcePattern matches the template function errors naming a resource
+	// missing from the saidata, and captures its kind
+	missingResourcePattern = regexp.MustCompile(`^(?:no (package|service|port|file|directory|command|container)s? found|(package|service|port|file|directory|command|container) [^*\s]\S* not found)`)
+	// providerSuffixPattern is dropped from missing resource errors so the errors of all
+	// providers group together
+	providerSuffixPattern = regexp.MustCompile(` for provider \S+$`)
+)
+
+// Finding is a problem found in a saidata file
+type Finding struct {
+	Line     int      `json:"line,omitempty"` // 0 when the problem has no place in the file
+	Severity string   `json:"severity"`
+	Category string   `json:"category"`
+	Field    string   `json:"field,omitempty"` // Dotted path of the field, e.g. packages.0.name
+	Message  string   `json:"message"`
+	UsedBy   []string `json:"used_by,omitempty"` // Provider actions whose templates report the problem
+}
+
+// LintResult holds the findings of a saidata file, sorted by line
+type LintResult struct {
+	File      string    `json:"file"`
+	Software  string    `json:"software,omitempty"`
+	Templates int       `json:"templates"` // Provider templates rendered
+	Errors    int       `json:"errors"`
+	Warnings  int       `json:"warnings"`
+	Findings  []Finding `json:"findings"`
+}
+
+// Linter checks saidata files the way sai uses them: against the schema, by rendering
+// the templates of providers with the saidata, and by checking the compatibility
+// matrix against the providers
+type Linter struct {
+	schema    *SaidataValidator
+	providers []*types.ProviderData
+	rendered  map[string]bool // Providers whose templates are rendered, all when nil
+}
+
+// NewLinter creates a linter rendering the templates of providers. A nil schema skips
+// the schema validation.
+func NewLinter(schema *SaidataValidator, providers []*types.ProviderData) *Linter {
+	return &Linter{schema: schema, providers: providers}
+}
+
+// RenderOnly limits the rendered templates to those of the named providers. The
+// compatibility matrix is still checked against all the providers.
+func (l *Linter) RenderOnly(providers []string) {
+	l.rendered = make(map[string]bool, len(providers))
+	for _, name := range providers {
+		l.rendered[name] = true
+	}
+}
+
+// LintFile checks a saidata file
+func (l *Linter) LintFile(path string) (*LintResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saidata file: %w", err)
+	}
+	return l.Lint(path, data), nil
+}
+
+// Lint checks the saidata data read from file
+func (l *Linter) Lint(file string, data []byte) *LintResult {
+	result := &LintResult{File: file, Findings: []Finding{}}
+	defer result.summarize()
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		result.add(Finding{Line: yamlErrorLine(err), Severity: SeverityError, Category: FindingSchema, Message: err.Error()})
+		return result
+	}
+	if len(root.Content) == 0 {
+		result.add(Finding{Severity: SeverityError, Category: FindingSchema, Message: "the file is empty"})
+		return result
+	}
+	document := root.Content[0]
+
+	l.lintSchema(result, document)
+
+	// Provider sections are decoded when their templates are rendered, the schema checks
+	// the others
+	saidata, err := types.LoadSoftwareDataLazy(bytes.NewReader(data))
+	if err != nil {
+		result.add(Finding{Line: yamlErrorLine(err), Severity: SeverityError, Category: FindingSchema, Message: err.Error()})
+		return result
+	}
+	result.Software = saidata.Metadata.Name
+	for _, name := range saidata.ProviderNames() {
+		if !l.renders(name) {
+			continue
+		}
+		if _, err := saidata.LoadProviderConfig(name); err != nil {
+			result.add(Finding{Line: yamlErrorLine(err), Severity: SeverityError, Category: FindingSchema, Field: "providers." + name, Message: err.Error()})
+			return result
+		}
+	}
+
+	l.lintCompatibility(result, document, saidata)
+	l.lintTemplates(result, document, saidata)
+	return result
+}
+
+// renders reports whether the templates of provider are rendered
+func (l *Linter) renders(provider string) bool {
+	return l.rendered == nil || l.rendered[provider]
+}
+
+// lintSchema reports the schema violations of document
+func (l *Linter) lintSchema(result *LintResult, document *yaml.Node) {
+	if l.schema == nil {
+		return
+	}
+	var decoded interface{}
+	if err := document.Decode(&decoded); err != nil {
+		result.add(Finding{Line: document.Line, Severity: SeverityError, Category: FindingSchema, Message: err.Error()})
+		return
+	}
+	violations, err := l.schema.schemaErrors(decoded)
+	if err != nil {
+		result.add(Finding{Severity: SeverityError, Category: FindingSchema, Message: err.Error()})
+		return
+	}
+
+	for _, violation := range violations {
+		field := violation.Field()
+		if field == "(root)" {
+			field = ""
+		}
+		finding := Finding{
+			Line:     nodeLine(document, field),
+			Severity: SeverityError,
+			Category: FindingSchema,
+			Field:    field,
+			Message:  violation.Description(),
+		}
+		if violation.Type() == "required" {
+			finding.Category = FindingMissingField
+			if property, ok := violation.Details()["property"].(string); ok {
+				finding.Field = strings.TrimPrefix(field+"."+property, ".")
+			}
+		}
+		result.add(finding)
+	}
+}
+
+// lintCompatibility checks the providers of the compatibility matrix and of the
+// provider sections exist, and that the matrix only claims support on the platforms
+// of the providers, for known architectures and without contradicting itself
+func (l *Linter) lintCompatibility(result *LintResult, document *yaml.Node, saidata *types.SoftwareData) {
+	if len(l.providers) == 0 {
+		return
+	}
+	providers := make(map[string]*types.ProviderData, len(l.providers))
+	for _, provider := range l.providers {
+		providers[provider.Provider.Name] = provider
+	}
+
+	for _, name := range saidata.ProviderNames() {
+		if providers[name] == nil {
+			field := "providers." + name
+			result.add(Finding{
+				Line:     nodeLine(document, field),
+				Severity: SeverityWarning,
+				Category: FindingCompatibility,
+				Field:    field,
+				Message:  fmt.Sprintf("unknown provider %s, its section is never used", name),
+			})
+		}
+	}
+
+	if saidata.Compatibility == nil {
+		return
+	}
+	claims := make(map[string]int) // provider/platform/architecture to the index of the entry claiming it
+	for i, entry := range saidata.Compatibility.Matrix {
+		prefix := fmt.Sprintf("compatibility.matrix.%d", i)
+		compatibilityError := func(field, message string) {
+			result.add(Finding{
+				Line:     nodeLine(document, prefix+"."+field),
+				Severity: SeverityError,
+				Category: FindingCompatibility,
+				Field:    prefix + "." + field,
+				Message:  message,
+			})
+		}
+
+		provider := providers[entry.Provider]
+		if entry.Provider != "" && provider == nil {
+			compatibilityError("provider", fmt.Sprintf("unknown provider %s", entry.Provider))
+		}
+
+		platforms := entry.GetPlatformsAsStrings()
+		if provider != nil && entry.Supported {
+			for _, platform := range platforms {
+				if !runsOn(provider, platform) {
+					compatibilityError("platform", fmt.Sprintf("%s does not run on %s, it supports %s",
+						entry.Provider, platform, strings.Join(provider.Provider.Platforms, ", ")))
+				}
+			}
+		}
+
+		architectures := entry.GetArchitecturesAsStrings()
+		for _, arch := range architectures {
+			if !knownArchitectures[strings.ToLower(arch)] {
+				compatibilityError("architecture", fmt.Sprintf("unknown architecture %s, use amd64, arm64, 386, arm, ppc64le, s390x or riscv64", arch))
+			}
+		}
+
+		if len(architectures) == 0 {
+			architectures = []string{"*"}
+		}
+		for _, platform := range platforms {
+			for _, arch := range architectures {
+				claim := entry.Provider + "/" + platform + "/" + arch
+				j, exists := claims[claim]
+				if !exists {
+					claims[claim] = i
+					continue
+				}
+				if saidata.Compatibility.Matrix[j].Supported != entry.Supported {
+					compatibilityError("supported", fmt.Sprintf("contradicts compatibility.matrix.%d about %s on %s", j, entry.Provider, platform))
+				}
+			}
+		}
+	}
+}
+
+// runsOn reports whether provider runs on platform, a platform or distribution name
+func runsOn(provider *types.ProviderData, platform string) bool {
+	if len(provider.Provider.Platforms) == 0 {
+		return true
+	}
+	platform = strings.ToLower(platform)
+	for _, supported := range provider.Provider.Platforms {
+		supported = strings.ToLower(supported)
+		if supported == platform || (supported == "linux" && !nonLinuxPlatforms[platform]) {
+			return true
+		}
+		// Providers of some distributions run on linux
+		if platform == "linux" && !nonLinuxPlatforms[supported] {
+			return true
+		}
+	}
+	return false
+}
+
+// lintTemplates renders the templates of every action of the providers with the
+// saidata and the safety mode on, and reports the unresolved values, the resources
+// missing from the saidata and the failed assertions. They are errors when the install
+// of a provider the saidata targets reports them, warnings otherwise, as software does
+// not need to support every action. Templates failing whatever the saidata, such as
+// those that do not parse, are the provider's problem and are left to provider
+// validation.
+func (l *Linter) lintTemplates(result *LintResult, document *yaml.Node, saidata *types.SoftwareData) {
+	engine := template.NewTemplateEngine(nil, nil)
+	engine.SetSafetyMode(true)
+
+	grouped := make(map[string]*Finding)
+	var order []string
+	report := func(finding Finding, usedBy string, targeted bool) {
+		key := finding.Category + "\x00" + finding.Message
+		existing, ok := grouped[key]
+		if !ok {
+			finding.Severity = SeverityWarning
+			existing = &finding
+			grouped[key] = existing
+			order = append(order, key)
+		}
+		if targeted {
+			existing.Severity = SeverityError
+		}
+		for _, name := range existing.UsedBy {
+			if name == usedBy {
+				return
+			}
+		}
+		existing.UsedBy = append(existing.UsedBy, usedBy)
+	}
+
+	for _, provider := range l.providers {
+		name := provider.Provider.Name
+		if !l.renders(name) {
+			continue
+		}
+		targeted := targets(saidata, name)
+
+		actions := make([]string, 0, len(provider.Actions))
+		for action := range provider.Actions {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+
+		for _, action := range actions {
+			providerAction := provider.Actions[action]
+			context := &template.TemplateContext{
+				Software:  saidata.Metadata.Name,
+				Provider:  name,
+				Saidata:   saidata,
+				Variables: inputVariables(saidata, providerAction),
+			}
+			for _, tmpl := range actionTemplates(providerAction) {
+				if engine.ValidateTemplate(tmpl) != nil {
+					continue
+				}
+				result.Templates++
+				_, err := engine.Render(tmpl, context)
+				if err == nil {
+					continue
+				}
+				if finding, ok := templateFinding(err, document); ok {
+					report(finding, name+" "+action, targeted && action == "install")
+				}
+			}
+		}
+	}
+
+	for _, key := range order {
+		result.add(*grouped[key])
+	}
+}
+
+// targets reports whether the saidata was written for provider: it has a section for
+// it or the compatibility matrix says it supports the software
+func targets(saidata *types.SoftwareData, provider string) bool {
+	if saidata.GetProviderConfig(provider) != nil {
+		return true
+	}
+	if saidata.Compatibility != nil {
+		for _, entry := range saidata.Compatibility.Matrix {
+			if entry.Provider == provider && entry.Supported {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// actionTemplates returns the templates of an action, as rendered when it runs
+func actionTemplates(action types.Action) []string {
+	var templates []string
+	for _, tmpl := range []string{action.Template, action.Command, action.Script, action.Rollback, action.Detection} {
+		if tmpl != "" {
+			templates = append(templates, tmpl)
+		}
+	}
+	if action.Validation != nil && action.Validation.Command != "" {
+		templates = append(templates, action.Validation.Command)
+	}
+	for _, step := range action.Steps {
+		for _, tmpl := range []string{step.Command, step.Condition, step.Rollback} {
+			if tmpl != "" {
+				templates = append(templates, tmpl)
+			}
+		}
+	}
+	return templates
+}
+
+// inputVariables returns a value for each input of the action and of the saidata, its
+// default or its name, as the user would be asked for them
+func inputVariables(saidata *types.SoftwareData, action types.Action) map[string]string {
+	variables := make(map[string]string)
+	for _, input := range append(append([]types.Input{}, saidata.Inputs...), action.Inputs...) {
+		value := input.Default
+		if value == "" {
+			value = input.Name
+		}
+		variables[input.Name] = value
+	}
+	return variables
+}
+
+// templateFinding turns a rendering error due to the saidata into a finding, and
+// reports false for the errors of the template itself
+func templateFinding(err error, document *yaml.Node) (Finding, bool) {
+	var assertionErr *template.TemplateAssertionError
+	if errors.As(err, &assertionErr) {
+		return Finding{Category: FindingAssertion, Message: assertionErr.Message}, true
+	}
+
+	var resolutionErr *template.TemplateResolutionError
+	if !errors.As(err, &resolutionErr) {
+		return Finding{}, false
+	}
+	switch resolutionErr.Type {
+	case "unresolved_variables", "no_value":
+		return Finding{
+			Category: FindingUnresolved,
+			Message:  fmt.Sprintf("renders with unresolved values: %s", strings.TrimSpace(resolutionErr.Template)),
+		}, true
+	case "function_error":
+		match := functionErrorPattern.FindStringSubmatch(resolutionErr.Message)
+		if match == nil {
+			return Finding{}, false
+		}
+		message := providerSuffixPattern.ReplaceAllString(match[1], "")
+		resource := missingResourcePattern.FindStringSubmatch(message)
+		if resource == nil {
+			return Finding{}, false
+		}
+		kind := resource[1] + resource[2]
+		section := kind + "s"
+		if kind == "directory" {
+			section = "directories"
+		}
+		line := 0
+		if hasKey(document, section) {
+			line = nodeLine(document, section)
+		}
+		return Finding{
+			Line:     line,
+			Category: FindingMissingField,
+			Field:    section,
+			Message:  fmt.Sprintf("%s, add it to %s", message, section),
+		}, true
+	}
+	return Finding{}, false
+}
+
+// add records a finding
+func (r *LintResult) add(finding Finding) {
+	r.Findings = append(r.Findings, finding)
+}
+
+// summarize sorts the findings by line and counts them
+func (r *LintResult) summarize() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		return r.Findings[i].Line < r.Findings[j].Line
+	})
+	r.Errors, r.Warnings = 0, 0
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityError {
+			r.Errors++
+		} else {
+			r.Warnings++
+		}
+	}
+}
+
+// nodeLine returns the line of the field at a dotted path in document, such as
+// packages.0.name, or of its closest parent present in the document
+func nodeLine(document *yaml.Node, path string) int {
+	node, line := document, document.Line
+	if path == "" {
+		return line
+	}
+	for _, segment := range strings.Split(path, ".") {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(node.Content) {
+				next = node.Content[index]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			return line
+		}
+		node = next
+	}
+	return line
+}
+
+// hasKey reports whether the mapping node has key
+func hasKey(node *yaml.Node, key string) bool {
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlErrorLine returns the line a YAML error is about, 0 when it does not say
+func yamlErrorLine(err error) int {
+	if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return line
+	}
+	return 0
+}
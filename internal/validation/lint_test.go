@@ -0,0 +1,261 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sai/internal/types"
+)
+
+// lintProviders returns an apt provider whose install needs a package and whose logs
+// action needs a log file, and a brew provider
+func lintProviders(t testing.TB) []*types.ProviderData {
+	apt, err := types.LoadProviderFromYAML([]byte(`
+version: "1.0"
+provider:
+  name: apt
+  type: package_manager
+  platforms: ["debian", "ubuntu"]
+actions:
+  install:
+    template: "apt-get install -y {{sai_package(0, 'name', 'apt')}}"
+  logs:
+    template: "tail {{sai_file('log', 'path', 'apt')}}"
+  broken:
+    template: "apt-get {{"
+`))
+	require.NoError(t, err)
+	brew, err := types.LoadProviderFromYAML([]byte(`
+version: "1.0"
+provider:
+  name: brew
+  type: package_manager
+  platforms: ["macos"]
+actions:
+  install:
+    template: "brew install {{sai_package(0, 'name', 'brew')}} {{.Variables.missing}}"
+`))
+	require.NoError(t, err)
+	return []*types.ProviderData{apt, brew}
+}
+
+// findingAt returns the finding of category about field
+func findingAt(result *LintResult, category, field string) *Finding {
+	for i, finding := range result.Findings {
+		if finding.Category == category && finding.Field == field {
+			return &result.Findings[i]
+		}
+	}
+	return nil
+}
+
+func TestLinter_Schema(t *testing.T) {
+	schema, err := NewSaidataValidator("../../schemas/saidata-0.2-schema.json")
+	require.NoError(t, err)
+	linter := NewLinter(schema, nil)
+
+	result := linter.Lint("default.yaml", []byte(`version: "0.2"
+metadata:
+  description: Test
+packages:
+  - name: test
+    package_name: test
+    version: 1
+`))
+	missing := findingAt(result, FindingMissingField, "metadata.name")
+	require.NotNil(t, missing, "%+v", result.Findings)
+	assert.Equal(t, 2, missing.Line)
+	assert.Equal(t, SeverityError, missing.Severity)
+
+	invalid := findingAt(result, FindingSchema, "packages.0.version")
+	require.NotNil(t, invalid, "%+v", result.Findings)
+	assert.Equal(t, 7, invalid.Line)
+
+	result = linter.Lint("default.yaml", []byte("metadata:\n  name: [\n"))
+	require.Len(t, result.Findings, 1)
+	assert.Equal(t, FindingSchema, result.Findings[0].Category)
+	assert.Equal(t, 1, result.Errors)
+}
+
+func TestLinter_Templates(t *testing.T) {
+	linter := NewLinter(nil, lintProviders(t))
+
+	// apt is targeted by its section: its install failing is an error, its logs
+	// failing a warning; brew is not targeted, its unresolved variable is a warning
+	result := linter.Lint("default.yaml", []byte(`version: "0.2"
+metadata:
+  name: test
+files:
+  - name: config
+    path: /etc/test.conf
+providers:
+  apt:
+    repositories: []
+`))
+
+	packages := findingAt(result, FindingMissingField, "packages")
+	require.NotNil(t, packages, "%+v", result.Findings)
+	assert.Equal(t, SeverityError, packages.Severity)
+	assert.Equal(t, 0, packages.Line)
+	assert.Equal(t, []string{"apt install", "brew install"}, packages.UsedBy)
+
+	files := findingAt(result, FindingMissingField, "files")
+	require.NotNil(t, files, "%+v", result.Findings)
+	assert.Equal(t, SeverityWarning, files.Severity)
+	assert.Equal(t, 4, files.Line)
+	assert.Contains(t, files.Message, "file log not found")
+
+	// The template that does not parse is not the saidata's problem
+	assert.Equal(t, 3, result.Templates)
+
+	result = linter.Lint("default.yaml", []byte(`version: "0.2"
+metadata:
+  name: test
+packages:
+  - name: test
+`))
+	require.Len(t, result.Findings, 2, "%+v", result.Findings)
+	unresolved := result.Findings[1]
+	assert.Equal(t, FindingUnresolved, unresolved.Category)
+	assert.Equal(t, SeverityWarning, unresolved.Severity)
+	assert.Equal(t, []string{"brew install"}, unresolved.UsedBy)
+	assert.Equal(t, 0, result.Errors)
+
+	linter.RenderOnly([]string{"apt"})
+	result = linter.Lint("default.yaml", []byte(`version: "0.2"
+metadata:
+  name: test
+packages:
+  - name: test
+`))
+	require.Len(t, result.Findings, 1, "%+v", result.Findings)
+	assert.Equal(t, []string{"apt logs"}, result.Findings[0].UsedBy)
+}
+
+func TestLinter_Compatibility(t *testing.T) {
+	linter := NewLinter(nil, lintProviders(t))
+
+	result := linter.Lint("default.yaml", []byte(`version: "0.2"
+metadata:
+  name: test
+packages:
+  - name: test
+files:
+  - name: log
+    path: /var/log/test.log
+providers:
+  aptitude:
+    packages: []
+compatibility:
+  matrix:
+    - provider: apt
+      platform: [ubuntu, linux]
+      architecture: [amd64, x86_64]
+      supported: true
+    - provider: apt
+      platform: macos
+      architecture: amd65
+      supported: true
+    - provider: yum
+      platform: rhel
+      supported: true
+    - provider: apt
+      platform: ubuntu
+      architecture: amd64
+      supported: false
+`))
+
+	unknownSection := findingAt(result, FindingCompatibility, "providers.aptitude")
+	require.NotNil(t, unknownSection, "%+v", result.Findings)
+	assert.Equal(t, SeverityWarning, unknownSection.Severity)
+	assert.Equal(t, 10, unknownSection.Line)
+
+	platform := findingAt(result, FindingCompatibility, "compatibility.matrix.1.platform")
+	require.NotNil(t, platform, "%+v", result.Findings)
+	assert.Equal(t, 19, platform.Line)
+	assert.Contains(t, platform.Message, "apt does not run on macos")
+
+	arch := findingAt(result, FindingCompatibility, "compatibility.matrix.1.architecture")
+	require.NotNil(t, arch, "%+v", result.Findings)
+	assert.Equal(t, 20, arch.Line)
+
+	provider := findingAt(result, FindingCompatibility, "compatibility.matrix.2.provider")
+	require.NotNil(t, provider, "%+v", result.Findings)
+	assert.Contains(t, provider.Message, "unknown provider yum")
+
+	contradiction := findingAt(result, FindingCompatibility, "compatibility.matrix.3.supported")
+	require.NotNil(t, contradiction, "%+v", result.Findings)
+	assert.Contains(t, contradiction.Message, "compatibility.matrix.0")
+
+	assert.Nil(t, findingAt(result, FindingCompatibility, "compatibility.matrix.0.platform"), "linux covers apt")
+	assert.Equal(t, 4, result.Errors)
+}
+
+func TestLinter_ProviderSections(t *testing.T) {
+	linter := NewLinter(nil, lintProviders(t))
+	data := []byte(`
+version: "0.2"
+metadata:
+  name: nginx
+providers:
+  apt:
+    packages: "not a list"
+`)
+
+	result := linter.Lint("nginx.yaml", data)
+	broken := findingAt(result, FindingSchema, "providers.apt")
+	require.NotNil(t, broken, "%+v", result.Findings)
+	assert.Equal(t, SeverityError, broken.Severity)
+
+	// Sections of providers whose templates are not rendered are left to the schema
+	linter.RenderOnly([]string{"brew"})
+	assert.Nil(t, findingAt(linter.Lint("nginx.yaml", data), FindingSchema, "providers.apt"))
+}
+
+// benchmarkDefinition returns a definition with many provider sections, the shape of
+// the largest generated definitions
+func benchmarkDefinition() []byte {
+	var definition strings.Builder
+	definition.WriteString("version: \"0.2\"\nmetadata:\n  name: software\npackages:\n  - name: software\nproviders:\n")
+	for provider := 0; provider < 200; provider++ {
+		fmt.Fprintf(&definition, "  provider%d:\n    packages:\n", provider)
+		for pkg := 0; pkg < 20; pkg++ {
+			fmt.Fprintf(&definition, "      - name: package%d\n        package_name: package%d-bin\n        version: \"1.%d\"\n", pkg, pkg, pkg)
+		}
+	}
+	return []byte(definition.String())
+}
+
+// BenchmarkLint measures the allocations of linting a large definition for one
+// provider, which leaves the other provider sections undecoded
+func BenchmarkLint(b *testing.B) {
+	data := benchmarkDefinition()
+	linter := NewLinter(nil, lintProviders(b))
+	linter.RenderOnly([]string{"apt"})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if result := linter.Lint("software.yaml", data); result.Errors != 0 {
+			b.Fatalf("unexpected findings: %+v", result.Findings)
+		}
+	}
+}
+
+// BenchmarkLintFullDecode is the baseline of BenchmarkLint, adding the decoding of every
+// provider section the linter did before sections were decoded lazily
+func BenchmarkLintFullDecode(b *testing.B) {
+	data := benchmarkDefinition()
+	linter := NewLinter(nil, lintProviders(b))
+	linter.RenderOnly([]string{"apt"})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := types.LoadSoftwareDataFromYAML(data); err != nil {
+			b.Fatal(err)
+		}
+		if result := linter.Lint("software.yaml", data); result.Errors != 0 {
+			b.Fatalf("unexpected findings: %+v", result.Findings)
+		}
+	}
+}
@@ -57,6 +57,16 @@ func (v *SaidataValidator) ValidateSaidata(saidata *types.SoftwareData) error {
 	return nil
 }
 
+// schemaErrors validates a saidata document, as decoded from YAML, against the schema.
+// Unlike ValidateSaidata it sees the fields sai does not know.
+func (v *SaidataValidator) schemaErrors(document interface{}) ([]gojsonschema.ResultError, error) {
+	result, err := gojsonschema.Validate(v.schemaLoader, gojsonschema.NewGoLoader(document))
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	return result.Errors(), nil
+}
+
 // ValidateSaidataYAML validates saidata YAML data against the schema
 func (v *SaidataValidator) ValidateSaidataYAML(yamlData []byte) error {
 	// First parse the YAML
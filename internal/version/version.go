@@ -0,0 +1,293 @@
+// Package version parses the versions requested with software@version, a version
+// pinned by prefix (1.24, 1.x) or a constraint (>=1.20 <1.25, ~1.24, ^1.2), and checks
+// installed versions against them.
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionPattern matches the versions of a constraint, without characters a shell
+// would interpret
+var versionPattern = regexp.MustCompile(`^[0-9A-Za-z*][0-9A-Za-z.+~_*-]*$`)
+
+// operators are the comparison operators of constraint terms, longest first
+var operators = []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"}
+
+// term is a comparison of a constraint. The pin operator "" matches the versions
+// starting with the components of its version.
+type term struct {
+	op      string // "", ">=", ">", "<=", "<" or "!="
+	version string
+}
+
+// Constraint is a requested version, all of whose terms an installed version must
+// satisfy
+type Constraint struct {
+	spec  string
+	terms []term
+}
+
+// Split splits a software@version argument into the software and the version, empty
+// when the argument has none. A leading @, as in npm scoped packages, is part of the
+// software name.
+func Split(arg string) (string, string) {
+	if i := strings.LastIndex(arg, "@"); i > 0 {
+		return arg[:i], arg[i+1:]
+	}
+	return arg, ""
+}
+
+// Parse parses a version constraint: a version, optionally prefixed by = or ==, pins
+// the versions starting with its components, x and * matching any component; terms
+// separated by spaces or commas are all satisfied, each a version prefixed by >=, >,
+// <=, <, or !=, by ~ (same minor version) or by ^ (same major version).
+func Parse(spec string) (*Constraint, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+
+	// Operators may be separated from their version, as in ">= 1.20"
+	fields := strings.FieldsFunc(spec, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' })
+	var tokens []string
+	for i := 0; i < len(fields); i++ {
+		token := fields[i]
+		if isOperator(token) && i+1 < len(fields) {
+			i++
+			token += fields[i]
+		}
+		tokens = append(tokens, token)
+	}
+
+	c := &Constraint{spec: spec}
+	for _, token := range tokens {
+		op := ""
+		for _, candidate := range operators {
+			if strings.HasPrefix(token, candidate) {
+				op = candidate
+				break
+			}
+		}
+		version := strings.TrimPrefix(token, op)
+		if !versionPattern.MatchString(version) {
+			return nil, fmt.Errorf("invalid version constraint %q: %q is not a version", spec, version)
+		}
+		if len(version) > 1 && version[0] == 'v' && version[1] >= '0' && version[1] <= '9' {
+			version = version[1:]
+		}
+		if op != "" && op != "=" && op != "==" && hasWildcard(version) {
+			return nil, fmt.Errorf("invalid version constraint %q: wildcards are only allowed in pinned versions", spec)
+		}
+
+		switch op {
+		case "", "=", "==":
+			c.terms = append(c.terms, term{op: "", version: trimWildcards(version)})
+		case "~":
+			c.terms = append(c.terms, term{op: ">=", version: version}, term{op: "<", version: bump(version, 1)})
+		case "^":
+			c.terms = append(c.terms, term{op: ">=", version: version}, term{op: "<", version: bump(version, caretLevel(version))})
+		default:
+			c.terms = append(c.terms, term{op: op, version: version})
+		}
+	}
+	return c, nil
+}
+
+// isOperator reports whether a token is an operator alone
+func isOperator(token string) bool {
+	for _, op := range operators {
+		if token == op {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the constraint as requested
+func (c *Constraint) String() string {
+	return c.spec
+}
+
+// Pinned returns the version pinned by the constraint without its wildcard components,
+// 1.24 for 1.24 or 1.24.x, empty when the constraint is a range
+func (c *Constraint) Pinned() string {
+	if len(c.terms) != 1 || c.terms[0].op != "" {
+		return ""
+	}
+	return c.terms[0].version
+}
+
+// Terms returns the constraint as comparisons with >=, >, <=, < and !=, a pinned
+// version becoming a range: 1.24 is >=1.24 and <1.25. Package managers accepting
+// version ranges, such as pip, npm, gem or cargo, can install from them.
+func (c *Constraint) Terms() []string {
+	var terms []string
+	for _, t := range c.terms {
+		if t.op != "" {
+			terms = append(terms, t.op+t.version)
+			continue
+		}
+		if t.version == "" {
+			continue // A wildcard alone matches any version
+		}
+		upper := bump(t.version, strings.Count(t.version, "."))
+		if upper == t.version {
+			terms = append(terms, ">="+t.version, "<="+t.version)
+		} else {
+			terms = append(terms, ">="+t.version, "<"+upper)
+		}
+	}
+	return terms
+}
+
+// Matches reports whether an installed version satisfies the constraint. Epochs, as
+// in 1:1.24.0-1ubuntu1, are ignored, and package revisions are extra components.
+func (c *Constraint) Matches(version string) bool {
+	version = strings.TrimPrefix(stripEpoch(strings.TrimSpace(version)), "v")
+	if version == "" {
+		return false
+	}
+	for _, t := range c.terms {
+		if !t.matches(version) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t term) matches(version string) bool {
+	switch t.op {
+	case "":
+		want := components(t.version)
+		have := components(version)
+		if len(have) < len(want) {
+			return false
+		}
+		for i, component := range want {
+			if component != have[i] {
+				return false
+			}
+		}
+		return true
+	case ">=":
+		return Compare(version, t.version) >= 0
+	case ">":
+		return Compare(version, t.version) > 0
+	case "<=":
+		return Compare(version, t.version) <= 0
+	case "<":
+		return Compare(version, t.version) < 0
+	case "!=":
+		return Compare(version, t.version) != 0
+	}
+	return false
+}
+
+// Compare compares two versions component by component, numerically when both
+// components are numbers, returning -1, 0 or 1. Missing components count as 0, so
+// 1.24 equals 1.24.0.
+func Compare(a, b string) int {
+	ac := components(stripEpoch(a))
+	bc := components(stripEpoch(b))
+	for i := 0; i < len(ac) || i < len(bc); i++ {
+		x, y := "0", "0"
+		if i < len(ac) {
+			x = ac[i]
+		}
+		if i < len(bc) {
+			y = bc[i]
+		}
+		if cmp := compareComponent(x, y); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// compareComponent compares version components, numbers before other strings
+func compareComponent(x, y string) int {
+	xn, xErr := strconv.ParseUint(x, 10, 64)
+	yn, yErr := strconv.ParseUint(y, 10, 64)
+	switch {
+	case xErr == nil && yErr == nil:
+		if xn < yn {
+			return -1
+		} else if xn > yn {
+			return 1
+		}
+		return 0
+	case xErr == nil:
+		return -1
+	case yErr == nil:
+		return 1
+	}
+	return strings.Compare(x, y)
+}
+
+// components splits a version into its components
+func components(version string) []string {
+	return strings.FieldsFunc(version, func(r rune) bool {
+		return r == '.' || r == '-' || r == '+' || r == '~' || r == '_'
+	})
+}
+
+// stripEpoch removes the epoch of a version, 1 in 1:1.24.0
+func stripEpoch(version string) string {
+	if _, rest, found := strings.Cut(version, ":"); found {
+		return rest
+	}
+	return version
+}
+
+// hasWildcard reports whether a version has a wildcard component
+func hasWildcard(version string) bool {
+	for _, component := range components(version) {
+		if component == "x" || component == "X" || component == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// trimWildcards returns the components of a version before its first wildcard
+func trimWildcards(version string) string {
+	var kept []string
+	for _, component := range strings.Split(version, ".") {
+		if component == "x" || component == "X" || component == "*" {
+			break
+		}
+		kept = append(kept, component)
+	}
+	return strings.Join(kept, ".")
+}
+
+// caretLevel returns the component ^ lets vary from: the first non-zero one
+func caretLevel(version string) int {
+	parts := strings.Split(version, ".")
+	for i, part := range parts[:len(parts)-1] {
+		if part != "0" {
+			return i
+		}
+	}
+	return len(parts) - 1
+}
+
+// bump returns the smallest version above all the versions whose components up to
+// level are those of version: bump("1.24.3", 1) is 1.25. The version is returned as
+// is when that component is not a number.
+func bump(version string, level int) string {
+	parts := strings.Split(version, ".")
+	if level < 0 || level >= len(parts) {
+		level = len(parts) - 1
+	}
+	n, err := strconv.Atoi(parts[level])
+	if err != nil {
+		return version
+	}
+	parts = append(parts[:level], strconv.Itoa(n+1))
+	return strings.Join(parts, ".")
+}
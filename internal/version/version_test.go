@@ -0,0 +1,115 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		arg      string
+		software string
+		version  string
+	}{
+		{arg: "nginx", software: "nginx"},
+		{arg: "nginx@1.24", software: "nginx", version: "1.24"},
+		{arg: "nginx@>=1.20 <1.25", software: "nginx", version: ">=1.20 <1.25"},
+		{arg: "@angular/cli", software: "@angular/cli"},
+		{arg: "@angular/cli@17", software: "@angular/cli", version: "17"},
+	}
+	for _, tt := range tests {
+		software, version := Split(tt.arg)
+		assert.Equal(t, tt.software, software, tt.arg)
+		assert.Equal(t, tt.version, version, tt.arg)
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		spec     string
+		pinned   string
+		terms    []string
+		matches  []string
+		rejected []string
+	}{
+		{
+			spec:     "1.24",
+			pinned:   "1.24",
+			terms:    []string{">=1.24", "<1.25"},
+			matches:  []string{"1.24", "1.24.0", "1:1.24.0-1ubuntu1", "v1.24.3"},
+			rejected: []string{"1.2", "1.240.0", "1.25.0", ""},
+		},
+		{
+			spec:     "1.x",
+			pinned:   "1",
+			terms:    []string{">=1", "<2"},
+			matches:  []string{"1.0", "1.99.2"},
+			rejected: []string{"2.0"},
+		},
+		{
+			spec:     "=1.24.0",
+			pinned:   "1.24.0",
+			terms:    []string{">=1.24.0", "<1.24.1"},
+			matches:  []string{"1.24.0-1"},
+			rejected: []string{"1.24.1"},
+		},
+		{
+			spec:     ">=1.20 <1.25",
+			terms:    []string{">=1.20", "<1.25"},
+			matches:  []string{"1.20", "1.24.9-2", "1.20.0"},
+			rejected: []string{"1.19.9", "1.25", "1.25.0"},
+		},
+		{
+			spec:     ">= 1.20, != 1.22",
+			terms:    []string{">=1.20", "!=1.22"},
+			matches:  []string{"1.21", "1.23"},
+			rejected: []string{"1.22.0"},
+		},
+		{
+			spec:     "~1.24.1",
+			terms:    []string{">=1.24.1", "<1.25"},
+			matches:  []string{"1.24.7"},
+			rejected: []string{"1.24.0", "1.25.0"},
+		},
+		{
+			spec:     "^0.2.3",
+			terms:    []string{">=0.2.3", "<0.3"},
+			matches:  []string{"0.2.9"},
+			rejected: []string{"0.3.0"},
+		},
+		{
+			spec:     "^1.2",
+			terms:    []string{">=1.2", "<2"},
+			matches:  []string{"1.9"},
+			rejected: []string{"2.0", "1.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.pinned, c.Pinned())
+			assert.Equal(t, tt.terms, c.Terms())
+			for _, v := range tt.matches {
+				assert.True(t, c.Matches(v), v)
+			}
+			for _, v := range tt.rejected {
+				assert.False(t, c.Matches(v), v)
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "1.24; rm -rf /", ">=1.x", "1.24 $(id)"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	assert.Equal(t, 0, Compare("1.24", "1.24.0"))
+	assert.Equal(t, -1, Compare("1.9", "1.10"))
+	assert.Equal(t, 0, Compare("2:1.0", "1.0"), "epochs are ignored")
+	assert.Equal(t, 1, Compare("1.24.0-1ubuntu1", "1.24.0"))
+}